@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	internaldb "github.com/koderover/zadig/pkg/cli/upgradeassistant/internal/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models/task"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/crypto"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+func init() {
+	rootCmd.AddCommand(reEncryptCmd)
+
+	reEncryptCmd.PersistentFlags().Bool("dry-run", false, "only count the values to re-encrypt")
+	_ = viper.BindPFlag("reEncryptDryRun", reEncryptCmd.PersistentFlags().Lookup("dry-run"))
+}
+
+var reEncryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "re-encrypt stored credentials with the primary aes key",
+	Long: `re-encrypt stored credentials with the primary aes key.
+
+Rotate the aes key by mounting the new key as etc/encryption/aes.<key id> and writing the key id
+to etc/encryption/aes.primary, the old keys must be kept for decryption until the re-encryption is done.
+Values which are already encrypted by the primary key are skipped and each value is updated on its own,
+so it is safe to run again if it is interrupted.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return preRun()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := reEncrypt(viper.GetBool("reEncryptDryRun")); err != nil {
+			log.Fatal(err)
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if err := postRun(); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// encryptedFields returns the fields stored in the database which are encrypted by crypto.AesEncrypt.
+// The destination storage urls in the stages of the pipeline tasks are not re-encrypted, they are only
+// read by the tasks which have finished already.
+func encryptedFields() []*internaldb.EncryptedFieldColl {
+	return []*internaldb.EncryptedFieldColl{
+		internaldb.NewEncryptedFieldColl(models.S3Storage{}.TableName(), "encryptedSk"),
+		internaldb.NewEncryptedFieldColl(task.Task{}.TableName(), "storage_uri"),
+		internaldb.NewEncryptedFieldColl(models.Queue{}.TableName(), "storage_uri"),
+		internaldb.NewEncryptedFieldColl(models.DeliveryDistribute{}.TableName(), "dest_storage_url"),
+	}
+}
+
+// checkAgentTokens warns about the clusters connected by hub agents. The token of a hub agent is the
+// encrypted cluster ID, it is not stored in the database but in the agent deployment of the cluster,
+// so the agent must be updated with a token encrypted by the primary key before the old keys are removed.
+func checkAgentTokens() error {
+	clusters, err := internaldb.NewK8SClusterColl().List()
+	if err != nil {
+		return fmt.Errorf("failed to list clusters, err: %s", err)
+	}
+	for _, cluster := range clusters {
+		if cluster.Local || cluster.Type == setting.KubeConfigClusterType {
+			continue
+		}
+		log.Warnf("The hub agent of cluster %s may use a token encrypted by an old aes key, update the agent with the token of the cluster before removing the old keys", cluster.Name)
+	}
+	return nil
+}
+
+func reEncrypt(dryRun bool) error {
+	log.Infof("Re-encrypting stored credentials with aes key %q", crypto.PrimaryAesKeyID())
+
+	var failed int
+	for _, coll := range encryptedFields() {
+		values, err := coll.List()
+		if err != nil {
+			return fmt.Errorf("failed to list %s.%s, err: %s", coll.GetCollectionName(), coll.Field(), err)
+		}
+
+		var updated, skipped int
+		for _, value := range values {
+			newValue, changed, err := crypto.AesReEncrypt(value.Value)
+			if err != nil {
+				log.Errorf("failed to re-encrypt %s.%s of %s, err: %s", coll.GetCollectionName(), coll.Field(), value.ID.Hex(), err)
+				failed++
+				continue
+			}
+			if !changed {
+				skipped++
+				continue
+			}
+			if dryRun {
+				updated++
+				continue
+			}
+
+			ok, err := coll.Replace(value.ID, value.Value, newValue)
+			if err != nil {
+				log.Errorf("failed to update %s.%s of %s, err: %s", coll.GetCollectionName(), coll.Field(), value.ID.Hex(), err)
+				failed++
+				continue
+			}
+			if !ok {
+				// the value is changed by others in the meantime, it is encrypted by the primary key already
+				skipped++
+				continue
+			}
+			updated++
+		}
+		if dryRun {
+			log.Infof("%s.%s: %d to re-encrypt, %d skipped", coll.GetCollectionName(), coll.Field(), updated, skipped)
+			continue
+		}
+		log.Infof("%s.%s: %d re-encrypted, %d skipped", coll.GetCollectionName(), coll.Field(), updated, skipped)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to re-encrypt %d values, run it again after fixing the errors", failed)
+	}
+	if err := checkAgentTokens(); err != nil {
+		return err
+	}
+	log.Info("Re-encryption finished")
+	return nil
+}
@@ -26,6 +26,7 @@ type K8SCluster struct {
 	Name   string                   `json:"name"                      bson:"name"`
 	Status setting.K8SClusterStatus `json:"status"                    bson:"status"`
 	Local  bool                     `json:"local"                     bson:"local"`
+	Type   string                   `json:"type,omitempty"            bson:"type,omitempty"`
 }
 
 func (K8SCluster) TableName() string {
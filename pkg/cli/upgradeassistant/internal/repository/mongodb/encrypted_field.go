@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+// EncryptedField is a field of a collection which is encrypted by the aes key
+type EncryptedField struct {
+	ID    primitive.ObjectID
+	Value string
+}
+
+type EncryptedFieldColl struct {
+	*mongo.Collection
+
+	coll  string
+	field string
+}
+
+func NewEncryptedFieldColl(collection, field string) *EncryptedFieldColl {
+	return &EncryptedFieldColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(collection),
+		coll:       collection,
+		field:      field,
+	}
+}
+
+func (c *EncryptedFieldColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *EncryptedFieldColl) Field() string {
+	return c.field
+}
+
+// List lists the non-empty values of the field
+func (c *EncryptedFieldColl) List() ([]*EncryptedField, error) {
+	query := bson.M{c.field: bson.M{"$exists": true, "$ne": ""}}
+	opts := options.Find().SetProjection(bson.M{c.field: 1}).SetSort(bson.M{"_id": 1})
+	cursor, err := c.Collection.Find(context.TODO(), query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []*EncryptedField
+	for cursor.Next(context.TODO()) {
+		doc := bson.M{}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		id, ok := doc["_id"].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		value, ok := doc[c.field].(string)
+		if !ok {
+			continue
+		}
+		resp = append(resp, &EncryptedField{ID: id, Value: value})
+	}
+	return resp, cursor.Err()
+}
+
+// Replace updates the field only if it is not changed since it is listed, it reports whether the field is updated
+func (c *EncryptedFieldColl) Replace(id primitive.ObjectID, oldValue, newValue string) (bool, error) {
+	query := bson.M{"_id": id, c.field: oldValue}
+	change := bson.M{"$set": bson.M{c.field: newValue}}
+	res, err := c.UpdateOne(context.TODO(), query, change)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
@@ -196,6 +196,19 @@ func GetJobScriptTmpDir(workDir string, job types.ZadigJobTask) (string, error)
 	return jobScriptDir, nil
 }
 
+func GetJobDebugTmpDir(workDir string, job types.ZadigJobTask) (string, error) {
+	jobDebugTmpDir := filepath.Join(workDir, common.JobDebugTmpDir)
+	if _, err := os.Stat(jobDebugTmpDir); os.IsNotExist(err) {
+		err := os.MkdirAll(jobDebugTmpDir, os.ModePerm)
+		if err != nil {
+			return "", fmt.Errorf("failed to create job debug tmp directory: %v", err)
+		}
+	}
+
+	jobDebugDir := filepath.Join(jobDebugTmpDir, fmt.Sprintf("%s-%s-%d-%s", job.ProjectName, job.WorkflowName, job.TaskID, job.JobName))
+	return jobDebugDir, nil
+}
+
 func GetCacheDir(workDir string) (string, error) {
 	cacheDir := filepath.Join(workDir, common.JobCacheTmpDir)
 	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
@@ -35,6 +35,8 @@ import (
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/network"
 	jobctl "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/workflowcontroller/jobcontroller"
+	reapermeta "github.com/koderover/zadig/pkg/microservice/reaper/core/service/meta"
+	"github.com/koderover/zadig/pkg/setting"
 	"github.com/koderover/zadig/pkg/types/job"
 )
 
@@ -117,6 +119,16 @@ func (e *JobExecutor) initJobContext() error {
 	if jobCtx == nil {
 		return fmt.Errorf("job context is nil")
 	}
+	// the secret references are resolved on the vm, the kubernetes provider is not available here and
+	// aslan rejects the references to kubernetes secrets of the jobs running on vms
+	if jobCtx.SecretProviders != nil {
+		resolver := jobCtx.SecretProviders.NewResolver(os.Getenv(setting.ENVSecretVaultToken), nil)
+		secretEnvs, err := resolver.ResolveEnvs(e.Ctx, jobCtx.SecretEnvs)
+		if err != nil {
+			return fmt.Errorf("resolve secret envs error: %v", err)
+		}
+		jobCtx.SecretEnvs = secretEnvs
+	}
 	e.JobCtx = jobCtx
 
 	return nil
@@ -214,6 +226,32 @@ func (e *JobExecutor) InitWorkDirectory() error {
 	}
 	e.Dirs.JobOutputsDir = outputDir
 
+	// --------------------------------------------- init job debug tmp dir ---------------------------------------------
+	debugDir, err := config.GetJobDebugTmpDir(workDir, *e.Job)
+	if err != nil {
+		return fmt.Errorf("failed to generate job debug tmp directory, error: %v", err)
+	}
+	if _, err := os.Stat(debugDir); err == nil {
+		if err := os.RemoveAll(debugDir); err != nil {
+			return fmt.Errorf("failed to delete job debug dir, error: %v", err)
+		}
+	}
+	if err := os.MkdirAll(debugDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create job debug tmp directory, error: %v", err)
+	}
+	e.Dirs.JobDebugDir = debugDir
+
+	// the debug step waits until the breakpoint file is removed by the user
+	breakpoints := map[string]bool{"before": e.JobCtx.BreakpointBefore, "after": e.JobCtx.BreakpointAfter}
+	for position, enabled := range breakpoints {
+		if !enabled {
+			continue
+		}
+		if err := config.CreateFileIfNotExist(filepath.Join(debugDir, fmt.Sprintf("breakpoint_%s", position))); err != nil {
+			return fmt.Errorf("failed to create breakpoint file, error: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -259,20 +297,16 @@ func (e *JobExecutor) Execute() {
 }
 
 func (e *JobExecutor) run() error {
-	hasFailed := false
 	var respErr error
 
 	for _, stepInfo := range e.JobCtx.Steps {
 		if e.CheckZadigCancel() {
 			return fmt.Errorf("user cancel job %s", e.Job.JobName)
 		}
-		if hasFailed && !stepInfo.Onfailure {
+		if respErr != nil && !stepInfo.Onfailure {
 			continue
 		}
-		if err := step.RunStep(e.Ctx, e.JobCtx, stepInfo, e.Dirs, e.getUserEnvs(), e.JobCtx.SecretEnvs, e.Logger); err != nil {
-			hasFailed = true
-			respErr = err
-		}
+		respErr = reapermeta.MergeStepError(respErr, step.RunStep(e.Ctx, e.JobCtx, stepInfo, e.Dirs, e.getUserEnvs(), e.JobCtx.SecretEnvs, e.Logger))
 	}
 	return respErr
 }
@@ -407,6 +441,12 @@ func (e *JobExecutor) deleteTempFileAndDir() error {
 		return err
 	}
 
+	// --------------------------------------------- delete job debug dir ----------------------------------------------
+	if err := os.RemoveAll(e.Dirs.JobDebugDir); err != nil {
+		log.Errorf("failed to delete job debug dir, error: %s", err)
+		return err
+	}
+
 	return nil
 }
 
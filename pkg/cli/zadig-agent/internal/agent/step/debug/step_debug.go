@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+)
+
+type DebugStep struct {
+	Type       string
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewDebugStep(_type string, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*DebugStep, error) {
+	return &DebugStep{
+		Type:       _type,
+		envs:       envs,
+		secretEnvs: secretEnvs,
+		logger:     logger,
+		dirs:       dirs,
+	}, nil
+}
+
+// Run blocks the job until the breakpoint file is removed, there is no debugger console for vm job,
+// so the user should log in to the vm and remove the file to continue the job.
+func (s *DebugStep) Run(ctx context.Context) error {
+	path := filepath.Join(s.dirs.JobDebugDir, fmt.Sprintf("breakpoint_%s", s.Type))
+	if _, err := os.Stat(path); err != nil {
+		if !os.IsNotExist(err) {
+			s.logger.Warnf(fmt.Sprintf("debug step unexpected stat error: %v", err))
+		}
+		return nil
+	}
+
+	s.logger.Infof(fmt.Sprintf("Running debugger %s job, remove the file %s on the vm to continue.", s.Type, path))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := os.Stat(path); err != nil {
+				s.logger.Infof(fmt.Sprintf("debug step %s done", s.Type))
+				return nil
+			}
+		}
+	}
+}
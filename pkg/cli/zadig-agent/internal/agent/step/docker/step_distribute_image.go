@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/regclient/regclient"
+	"github.com/regclient/regclient/config"
+	"github.com/regclient/regclient/types/ref"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+type DistributeImageStep struct {
+	spec       *step.StepImageDistributeSpec
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewDistributeImageStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*DistributeImageStep, error) {
+	distributeImageStep := &DistributeImageStep{dirs: dirs, envs: envs, secretEnvs: secretEnvs, logger: logger}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return distributeImageStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &distributeImageStep.spec); err != nil {
+		return distributeImageStep, fmt.Errorf("unmarshal spec %s to distribute image spec failed", yamlBytes)
+	}
+	return distributeImageStep, nil
+}
+
+func (s *DistributeImageStep) Run(ctx context.Context) error {
+	s.logger.Infof("Start distribute images.")
+	if s.spec.SourceRegistry == nil || s.spec.TargetRegistry == nil {
+		return errors.New("image registry infos are missing")
+	}
+	hostsOpt := regclient.WithConfigHosts([]config.Host{getDockerHost(s.spec.SourceRegistry), getDockerHost(s.spec.TargetRegistry)})
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	client := regclient.New(hostsOpt, regclient.WithLog(logger))
+
+	var mu sync.Mutex
+	errList := new(multierror.Error)
+	wg := sync.WaitGroup{}
+	for _, target := range s.spec.DistributeTarget {
+		wg.Add(1)
+		go func(target *step.DistributeTaskTarget) {
+			defer wg.Done()
+			if err := s.copyImage(ctx, target, client); err != nil {
+				mu.Lock()
+				errList = multierror.Append(errList, err)
+				mu.Unlock()
+			}
+		}(target)
+	}
+	wg.Wait()
+	if err := errList.ErrorOrNil(); err != nil {
+		return fmt.Errorf("copy images error: %v", err)
+	}
+	s.logger.Infof("Finish distribute images.")
+	return nil
+}
+
+func (s *DistributeImageStep) copyImage(ctx context.Context, target *step.DistributeTaskTarget, client *regclient.RegClient) error {
+	sourceRef, err := ref.New(target.SourceImage)
+	if err != nil {
+		return fmt.Errorf("parse source image: %s error: %v", target.SourceImage, err)
+	}
+	targetRef, err := ref.New(target.TargetImage)
+	if err != nil {
+		return fmt.Errorf("parse target image: %s error: %v", target.TargetImage, err)
+	}
+	// without platform options ImageCopy copies the whole manifest list of a multi-platform image
+	if err := client.ImageCopy(ctx, sourceRef, targetRef); err != nil {
+		return fmt.Errorf("copy image failed: %v", err)
+	}
+	s.logger.Infof(fmt.Sprintf("copy image from [%s] to [%s] succeed", target.SourceImage, target.TargetImage))
+	return nil
+}
+
+func getDockerHost(reg *step.RegistryNamespace) config.Host {
+	host := config.HostNewName(reg.RegAddr)
+	host.User = reg.AccessKey
+	host.Pass = reg.SecretKey
+	host.RegCert = reg.TLSCert
+	if !reg.TLSEnabled {
+		host.TLS = config.TLSInsecure
+	}
+	if strings.HasPrefix(reg.RegAddr, "http://") {
+		host.TLS = config.TLSDisabled
+	}
+	return *host
+}
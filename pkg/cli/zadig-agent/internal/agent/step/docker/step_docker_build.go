@@ -21,22 +21,29 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/helper"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
 	"github.com/koderover/zadig/pkg/setting"
+	zadigtypes "github.com/koderover/zadig/pkg/types"
 	"github.com/koderover/zadig/pkg/types/step"
 	"github.com/koderover/zadig/pkg/util/fs"
 )
 
-const dockerExe = "docker"
+const (
+	dockerExe     = "docker"
+	buildxBuilder = "zadig-builder"
+)
 
 type DockerBuildStep struct {
 	spec       *step.StepDockerBuildSpec
@@ -44,6 +51,11 @@ type DockerBuildStep struct {
 	secretEnvs []string
 	logger     *log.JobLogger
 	dirs       *types.AgentWorkDirs
+	// builder and buildkitdConfig are the buildx builder of the step and its config file
+	builder         string
+	buildkitdConfig string
+	// emulatedArchs are the archs of the platforms the docker daemon needs QEMU emulators for
+	emulatedArchs []string
 }
 
 func NewDockerBuildStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*DockerBuildStep, error) {
@@ -80,6 +92,13 @@ func (s DockerBuildStep) dockerLogin() error {
 	if s.spec.DockerRegistry == nil {
 		return nil
 	}
+	if s.spec.DockerRegistry.UserName != "" && s.spec.GetBuilder() != step.DockerBuilderDocker {
+		s.logger.Printf("Writing credentials of Docker Registry: %s.\n", s.spec.DockerRegistry.Host)
+		if err := writeDockerConfig(s.spec.DockerRegistry.Host, s.spec.DockerRegistry.UserName, s.spec.DockerRegistry.Password); err != nil {
+			return fmt.Errorf("failed to write docker config: %s", err)
+		}
+		return nil
+	}
 	if s.spec.DockerRegistry.UserName != "" {
 		s.logger.Printf("Logining Docker Registry: %s.\n", s.spec.DockerRegistry.Host)
 		startTimeDockerLogin := time.Now()
@@ -113,10 +132,44 @@ func (s *DockerBuildStep) runDockerBuild() error {
 		setProxy(s.spec)
 	}
 
-	s.logger.Printf("Runing Docker Build.\n")
+	if s.spec.UseBuildx() {
+		s.logger.Printf("Building with docker buildx, platforms: %v.\n", s.spec.Platforms)
+		// every step has its own builder since the builders of the jobs sharing the docker daemon may trust different registries
+		s.builder = fmt.Sprintf("%s-%s", buildxBuilder, uuid.NewString()[:8])
+		configDir := filepath.Join(os.TempDir(), s.builder)
+		defer removeBuildxBuilder(s.builder, configDir)
+		if s.buildkitdConfig, err = writeBuildkitdConfig(configDir, s.spec.Registries); err != nil {
+			return fmt.Errorf("failed to write buildkitd config: %s", err)
+		}
+		// the emulators are only installed if the job opts in, the binfmt image changes the binfmt_misc of the node
+		if s.spec.EmulatorImage != "" {
+			nativeArch, err := dockerDaemonArch(s.envs)
+			if err != nil {
+				return fmt.Errorf("failed to get the arch of the docker daemon: %s", err)
+			}
+			s.emulatedArchs = s.spec.EmulatedArchs(nativeArch)
+			s.logger.Printf("Installing the emulators of archs %v with %s.\n", s.emulatedArchs, s.spec.EmulatorImage)
+		}
+	}
+	s.logger.Printf("Runing Docker Build with builder: %s.\n", s.spec.GetBuilder())
 	startTimeDockerBuild := time.Now()
 	envs := s.envs
-	for _, c := range s.dockerCommands() {
+	var cmds []*exec.Cmd
+	if s.spec.GetBuilder() == step.DockerBuilderDocker {
+		cmds = s.dockerCommands()
+	} else {
+		if cmds, err = s.daemonlessCommands(); err != nil {
+			return err
+		}
+		configDir := filepath.Join(os.TempDir(), fmt.Sprintf("zadig-buildkitd-%s", uuid.NewString()[:8]))
+		defer os.RemoveAll(configDir)
+		buildkitdConfig, err := writeBuildkitdConfig(configDir, s.spec.Registries)
+		if err != nil {
+			return fmt.Errorf("failed to write buildkitd config: %s", err)
+		}
+		envs = append(append([]string{}, s.envs...), daemonlessEnvs(buildkitdConfig)...)
+	}
+	for _, c := range cmds {
 		c.Dir = s.dirs.Workspace
 		c.Env = envs
 
@@ -165,6 +218,27 @@ func (s *DockerBuildStep) runDockerBuild() error {
 
 func (s *DockerBuildStep) dockerCommands() []*exec.Cmd {
 	cmds := make([]*exec.Cmd, 0)
+	if s.spec.UseBuildx() {
+		// buildx pushes the image, or the manifest list of a multi-platform image, by itself
+		if len(s.emulatedArchs) > 0 {
+			cmds = append(cmds, binfmtInstallCmd(s.spec.EmulatorImage, s.emulatedArchs))
+		}
+		cmds = append(
+			cmds,
+			dockerBuildxBuilderCmd(s.builder, s.buildkitdConfig),
+			dockerBuildxCmd(
+				s.builder,
+				s.GetDockerFile(),
+				s.spec.ImageName,
+				s.spec.WorkDir,
+				s.spec.BuildArgs,
+				s.spec.IgnoreCache,
+				s.spec.Platforms,
+				s.spec.Cache,
+			),
+		)
+		return cmds
+	}
 	cmds = append(
 		cmds,
 		dockerBuildCmd(
@@ -199,6 +273,94 @@ func dockerBuildCmd(dockerfile, fullImage, ctx, buildArgs string, ignoreCache bo
 	return exec.Command("sh", args...)
 }
 
+// dockerBuildxBuilderCmd creates the docker-container builder used by buildx, the default docker driver
+// supports neither multi-platform images nor cache export. The buildkitd in the builder container does not
+// inherit the insecure registries and certificates of the docker daemon, so they are passed with its config.
+func dockerBuildxBuilderCmd(builder, config string) *exec.Cmd {
+	args := []string{"buildx", "create", "--name", builder, "--driver", "docker-container"}
+	if config != "" {
+		args = append(args, "--config", config)
+	}
+	return exec.Command(dockerExe, args...)
+}
+
+// removeBuildxBuilder removes the builder, its container in the docker daemon and its config, errors are only logged
+func removeBuildxBuilder(builder, configDir string) {
+	if out, err := exec.Command(dockerExe, "buildx", "rm", builder).CombinedOutput(); err != nil {
+		log.Warningf("failed to remove buildx builder %s: %s %s", builder, err, out)
+	}
+	if err := os.RemoveAll(configDir); err != nil {
+		log.Warningf("failed to remove buildkitd config %s: %s", configDir, err)
+	}
+}
+
+// binfmtInstallCmd registers the QEMU emulators of the archs in the kernel of the docker daemon,
+// the builder needs them to run the commands in the Dockerfile for a foreign platform.
+func binfmtInstallCmd(image string, archs []string) *exec.Cmd {
+	return exec.Command(dockerExe, "run", "--privileged", "--rm", image, "--install", strings.Join(archs, ","))
+}
+
+// dockerDaemonArch returns the arch of the docker daemon, which may differ from the arch of the node running the step
+func dockerDaemonArch(envs []string) (string, error) {
+	cmd := exec.Command(dockerExe, "info", "--format", "{{.Architecture}}")
+	cmd.Env = envs
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return step.NormalizeArch(string(out)), nil
+}
+
+// writeBuildkitdConfig writes buildkitd.toml and the CA certificates of the registries to dir,
+// it returns an empty path if no registry needs to be configured.
+func writeBuildkitdConfig(dir string, registries []*step.RegistryTLS) (string, error) {
+	certDir := filepath.Join(dir, "certs.d")
+	config := step.BuildKitdConfig(registries, certDir)
+	if config == "" {
+		return "", nil
+	}
+	for _, reg := range registries {
+		if !reg.TLSEnabled || reg.TLSCert == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(reg.CertPath(certDir)), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(reg.CertPath(certDir), []byte(reg.TLSCert), 0644); err != nil {
+			return "", err
+		}
+	}
+	configPath := filepath.Join(dir, "buildkitd.toml")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		return "", err
+	}
+	return configPath, nil
+}
+
+func dockerBuildxCmd(builder, dockerfile, fullImage, ctx, buildArgs string, ignoreCache bool, platforms []string, cache *zadigtypes.BuildKitCache) *exec.Cmd {
+	args := []string{"-c"}
+	dockerCommand := "docker buildx build --builder " + builder + " --push"
+	if len(platforms) > 0 {
+		dockerCommand += " --platform " + strings.Join(platforms, ",")
+	}
+	if ignoreCache {
+		dockerCommand += " --no-cache"
+	}
+	if cache != nil {
+		if !ignoreCache {
+			dockerCommand += " --cache-from " + cache.CacheFrom()
+		}
+		dockerCommand += " --cache-to " + cache.CacheTo()
+	}
+
+	for _, val := range strings.Fields(buildArgs) {
+		dockerCommand = dockerCommand + " " + val
+	}
+	dockerCommand = dockerCommand + " -t " + fullImage + " -f " + dockerfile + " " + ctx
+	args = append(args, dockerCommand)
+	return exec.Command("sh", args...)
+}
+
 func dockerPush(fullImage string) *exec.Cmd {
 	args := []string{"-c"}
 	dockerPushCommand := "docker push " + fullImage
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	zadigtypes "github.com/koderover/zadig/pkg/types"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+const (
+	// buildkitExe starts a rootless buildkitd for the build, BuildKit has to be installed on the vm
+	buildkitExe       = "buildctl-daemonless.sh"
+	buildkitdFlagsEnv = "BUILDKITD_FLAGS"
+	dockerConfigEnv   = "DOCKER_CONFIG"
+	// dockerHubAuthKey is the key of the docker hub credentials in the docker config, which is not the registry host
+	dockerHubAuthKey = "https://index.docker.io/v1/"
+)
+
+// daemonlessCommands returns the commands building and pushing the image without a docker daemon.
+// Only BuildKit runs on the vm, kaniko has to run in its own image which is only supported in kubernetes jobs.
+func (s *DockerBuildStep) daemonlessCommands() ([]*exec.Cmd, error) {
+	if s.spec.WorkDir == "" {
+		s.spec.WorkDir = "."
+	}
+	buildArgs, ignored := daemonlessBuildArgs(s.spec.BuildArgs)
+	if len(ignored) > 0 {
+		s.logger.Warnf(fmt.Sprintf("Build args other than --build-arg are not supported by %s and are ignored: %s", s.spec.GetBuilder(), strings.Join(ignored, " ")))
+	}
+
+	switch s.spec.GetBuilder() {
+	case step.DockerBuilderBuildKit:
+		return []*exec.Cmd{buildkitBuildCmd(s.GetDockerFile(), s.spec.ImageName, s.spec.WorkDir, buildArgs, s.spec.IgnoreCache, s.spec.Platforms, s.spec.Cache)}, nil
+	case step.DockerBuilderKaniko:
+		return nil, fmt.Errorf("docker builder %s is not supported by the vm agent", step.DockerBuilderKaniko)
+	default:
+		return nil, fmt.Errorf("unsupported docker builder: %s", s.spec.GetBuilder())
+	}
+}
+
+// daemonlessEnvs returns the envs the daemonless builders need on top of the job envs,
+// the buildkitd started by buildkitExe reads the registries of the build from buildkitdConfig.
+func daemonlessEnvs(buildkitdConfig string) []string {
+	envs := []string{fmt.Sprintf("%s=%s", dockerConfigEnv, dockerConfigDir())}
+	if buildkitdConfig != "" && os.Getenv(buildkitdFlagsEnv) == "" {
+		envs = append(envs, fmt.Sprintf("%s=--config %s", buildkitdFlagsEnv, buildkitdConfig))
+	}
+	return envs
+}
+
+func buildkitBuildCmd(dockerfile, fullImage, ctx string, buildArgs []string, ignoreCache bool, platforms []string, cache *zadigtypes.BuildKitCache) *exec.Cmd {
+	args := []string{
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + ctx,
+		"--local", "dockerfile=" + filepath.Dir(dockerfile),
+		"--opt", "filename=" + filepath.Base(dockerfile),
+		"--output", fmt.Sprintf("type=image,name=%s,push=true", fullImage),
+	}
+	for _, arg := range buildArgs {
+		args = append(args, "--opt", "build-arg:"+arg)
+	}
+	if len(platforms) > 0 {
+		args = append(args, "--opt", "platform="+strings.Join(platforms, ","))
+	}
+	if ignoreCache {
+		args = append(args, "--no-cache")
+	}
+	if cache != nil {
+		if !ignoreCache {
+			args = append(args, "--import-cache", cache.CacheFrom())
+		}
+		args = append(args, "--export-cache", cache.CacheTo())
+	}
+	return exec.Command(buildkitExe, args...)
+}
+
+// daemonlessBuildArgs picks the KEY=VALUE pairs of the --build-arg flags out of the docker build args,
+// the other flags only make sense to the docker daemon and are returned as ignored.
+func daemonlessBuildArgs(buildArgs string) ([]string, []string) {
+	args, ignored := make([]string, 0), make([]string, 0)
+	fields := strings.Fields(buildArgs)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "--build-arg" && i+1 < len(fields):
+			args = append(args, fields[i+1])
+			i++
+		case strings.HasPrefix(fields[i], "--build-arg="):
+			args = append(args, strings.TrimPrefix(fields[i], "--build-arg="))
+		default:
+			ignored = append(ignored, fields[i])
+		}
+	}
+	return args, ignored
+}
+
+func dockerConfigDir() string {
+	if dir := os.Getenv(dockerConfigEnv); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "/root"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// writeDockerConfig adds the registry credentials to the docker config file read by the daemonless builders,
+// which replaces docker login.
+func writeDockerConfig(host, user, password string) error {
+	configPath := filepath.Join(dockerConfigDir(), "config.json")
+	dockerConfig := map[string]interface{}{}
+	if content, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(content, &dockerConfig); err != nil {
+			return fmt.Errorf("failed to parse docker config %s: %s", configPath, err)
+		}
+	}
+	auths, ok := dockerConfig["auths"].(map[string]interface{})
+	if !ok {
+		auths = map[string]interface{}{}
+	}
+	auths[dockerAuthKey(host)] = map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(user + ":" + password)),
+	}
+	dockerConfig["auths"] = auths
+
+	content, err := json.Marshal(dockerConfig)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, content, 0600)
+}
+
+// dockerAuthKey returns the key of the registry in the auths of the docker config
+func dockerAuthKey(host string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://"), "/")
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io", "index.docker.io/v1":
+		return dockerHubAuthKey
+	}
+	return host
+}
@@ -52,7 +52,7 @@ func maskSecretEnvs(message string, secretEnvs []string) string {
 		if len(val) == 0 {
 			continue
 		}
-		sl := strings.Split(val, "=")
+		sl := strings.SplitN(val, "=", 2)
 
 		if len(sl) != 2 {
 			continue
@@ -105,7 +105,7 @@ func MaskSecretEnvs(message string, secretEnvs []string) string {
 		if len(val) == 0 {
 			continue
 		}
-		sl := strings.Split(val, "=")
+		sl := strings.SplitN(val, "=", 2)
 
 		if len(sl) != 2 {
 			continue
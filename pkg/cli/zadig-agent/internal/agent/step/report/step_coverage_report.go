@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/helper"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/coverage"
+	"github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+type CoverageReportStep struct {
+	spec       *step.StepCoverageReportSpec
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewCoverageReportStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*CoverageReportStep, error) {
+	coverageReportStep := &CoverageReportStep{dirs: dirs, envs: envs, secretEnvs: secretEnvs, logger: logger}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return coverageReportStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &coverageReportStep.spec); err != nil {
+		return coverageReportStep, fmt.Errorf("unmarshal spec %s to coverage report spec failed", yamlBytes)
+	}
+	return coverageReportStep, nil
+}
+
+func (s *CoverageReportStep) Run(ctx context.Context) error {
+	s.logger.Infof("Start parse coverage reports.")
+	envMap := helper.MakeEnvMap(s.envs, s.secretEnvs)
+	s.spec.ReportPath = helper.ReplaceEnvWithValue(s.spec.ReportPath, envMap)
+
+	profile, files, err := coverage.Collect(filepath.Join(s.dirs.Workspace, s.spec.ReportPath), s.spec.Format)
+	if err != nil {
+		return fmt.Errorf("failed to collect coverage reports: %s", err)
+	}
+	s.logger.Infof(fmt.Sprintf("Parsed %s coverage of %d source file(s) from %d report(s).", strings.Join(profile.Formats, ","), len(profile.Files), len(files)))
+
+	report := coverage.NewReport(profile, s.spec.ChangedFiles)
+	s.logger.Infof(fmt.Sprintf("line coverage: %.2f%% (%d/%d)", report.Summary.LineRate, report.Summary.LinesCovered, report.Summary.LinesTotal))
+	if report.Summary.BranchesTotal > 0 {
+		s.logger.Infof(fmt.Sprintf("branch coverage: %.2f%% (%d/%d)", report.Summary.BranchRate, report.Summary.BranchesCovered, report.Summary.BranchesTotal))
+	}
+	if report.Diff != nil {
+		s.logger.Infof(fmt.Sprintf("diff coverage of %d changed file(s): %.2f%% (%d/%d)", len(report.DiffFiles), report.Diff.LineRate, report.Diff.LinesCovered, report.Diff.LinesTotal))
+	}
+
+	if err := os.MkdirAll(s.spec.DestDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create dest dir: %s error: %s", s.spec.DestDir, err)
+	}
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage report: %s", err)
+	}
+	absFilePath := filepath.Join(s.spec.DestDir, s.spec.FileName)
+	if err := os.WriteFile(absFilePath, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write coverage report: %s", err)
+	}
+
+	if s.spec.S3Storage != nil && s.spec.S3DestDir != "" && s.spec.FileName != "" {
+		s.logger.Infof(fmt.Sprintf("Start archive %s.", s.spec.FileName))
+		forcedPathStyle := true
+		if s.spec.S3Storage.Provider == setting.ProviderSourceAli {
+			forcedPathStyle = false
+		}
+		client, err := s3.NewClient(s.spec.S3Storage.Endpoint, s.spec.S3Storage.Ak, s.spec.S3Storage.Sk, s.spec.S3Storage.Region, s.spec.S3Storage.Insecure, forcedPathStyle)
+		if err != nil {
+			return fmt.Errorf("failed to create s3 client to upload file, err: %s", err)
+		}
+		// s3 keys are always separated by slash, even on windows
+		key := path.Join(s.spec.S3DestDir, s.spec.FileName)
+		if len(s.spec.S3Storage.Subfolder) > 0 {
+			key = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, key), "/")
+		}
+		if err := client.Upload(s.spec.S3Storage.Bucket, absFilePath, key); err != nil {
+			return err
+		}
+		s.logger.Infof(fmt.Sprintf("Finish archive %s.", s.spec.FileName))
+	}
+	s.logger.Infof("Finish parse coverage reports.")
+
+	violations := report.Check(s.spec.MinLineCoverage, s.spec.MinBranchCoverage, s.spec.MinDiffCoverage)
+	if len(violations) > 0 {
+		return fmt.Errorf("coverage gate failed: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/helper"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+	"github.com/koderover/zadig/pkg/microservice/reaper/core/service/meta"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+const (
+	replaceTestSuite = "TestSuite"
+)
+
+type JunitReportStep struct {
+	spec       *step.StepJunitReportSpec
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewJunitReportStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*JunitReportStep, error) {
+	junitReportStep := &JunitReportStep{dirs: dirs, envs: envs, secretEnvs: secretEnvs, logger: logger}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return junitReportStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &junitReportStep.spec); err != nil {
+		return junitReportStep, fmt.Errorf("unmarshal spec %s to junit report spec failed", yamlBytes)
+	}
+	return junitReportStep, nil
+}
+
+func (s *JunitReportStep) Run(ctx context.Context) error {
+	s.logger.Infof("Start merge ginkgo test results.")
+	if err := os.MkdirAll(s.spec.DestDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create dest dir: %s error: %s", s.spec.DestDir, err)
+	}
+
+	envMap := helper.MakeEnvMap(s.envs, s.secretEnvs)
+	s.spec.ReportDir = helper.ReplaceEnvWithValue(s.spec.ReportDir, envMap)
+
+	reportDir := filepath.Join(s.dirs.Workspace, s.spec.ReportDir)
+	failedCaseCount, quarantinedCaseCount, err := s.mergeGinkgoTestResults(s.spec.FileName, reportDir, s.spec.DestDir, s.spec.Quarantined, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to merge test result: %s", err)
+	}
+	s.logger.Infof("Finish merge ginkgo test results.")
+
+	s.logger.Infof(fmt.Sprintf("Start archive %s.", s.spec.FileName))
+	if s.spec.S3DestDir == "" || s.spec.FileName == "" || s.spec.S3Storage == nil {
+		return nil
+	}
+	forcedPathStyle := true
+	if s.spec.S3Storage.Provider == setting.ProviderSourceAli {
+		forcedPathStyle = false
+	}
+	client, err := s3.NewClient(s.spec.S3Storage.Endpoint, s.spec.S3Storage.Ak, s.spec.S3Storage.Sk, s.spec.S3Storage.Region, s.spec.S3Storage.Insecure, forcedPathStyle)
+	if err != nil {
+		return fmt.Errorf("failed to create s3 client to upload file, err: %s", err)
+	}
+
+	absFilePath := filepath.Join(s.spec.DestDir, s.spec.FileName)
+
+	if len(s.spec.S3Storage.Subfolder) > 0 {
+		s.spec.S3DestDir = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, s.spec.S3DestDir), "/")
+	}
+
+	info, err := os.Stat(absFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to upload file path [%s] to destination [%s], the error is: %s", absFilePath, s.spec.S3DestDir, err)
+	}
+	// if the given path is a directory
+	if info.IsDir() {
+		err := client.UploadDir(s.spec.S3Storage.Bucket, absFilePath, s.spec.S3DestDir)
+		if err != nil {
+			return err
+		}
+	} else {
+		// s3 keys are always separated by slash, even on windows
+		key := path.Join(s.spec.S3DestDir, info.Name())
+		err := client.Upload(s.spec.S3Storage.Bucket, absFilePath, key)
+		if err != nil {
+			return err
+		}
+	}
+	s.logger.Infof(fmt.Sprintf("Finish archive %s.", s.spec.FileName))
+	return meta.FailedCasesError(failedCaseCount, quarantinedCaseCount)
+}
+
+func (s *JunitReportStep) mergeGinkgoTestResults(testResultFile, testResultPath, testUploadPath string, quarantined []string, startTime time.Time) (int, int, error) {
+	testCases := []meta.TestCase{}
+
+	if len(testResultPath) == 0 {
+		return 0, 0, nil
+	}
+
+	files, err := ioutil.ReadDir(testResultPath)
+	if err != nil || len(files) == 0 {
+		return 0, 0, fmt.Errorf("test result files not found in path %s", testResultPath)
+	}
+
+	// sort and process xml files by modified time
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".xml" {
+			continue
+		}
+		filePath := filepath.Join(testResultPath, file.Name())
+		s.logger.Infof(fmt.Sprintf("name %s mod time: %v", file.Name(), file.ModTime()))
+
+		xmlBytes, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			s.logger.Warnf(fmt.Sprintf("Read file [%s], error: %v", filePath, err))
+			continue
+		}
+
+		cases, err := meta.ParseJUnitCases(xmlBytes)
+		if err != nil {
+			s.logger.Warnf(fmt.Sprintf("Unmarshal xml file [%s], error: %v", filePath, err))
+			continue
+		}
+		testCases = append(testCases, cases...)
+	}
+	summaryResult := meta.NewTestSuite(testCases)
+	summaryResult.Time = getSecondSince(startTime)
+
+	failedCases, quarantinedCases := meta.SplitFailedCases(testCases, quarantined)
+	for _, tc := range quarantinedCases {
+		s.logger.Warnf(fmt.Sprintf("quarantined case %s %s, ignored", tc.Key(), tc.Status()))
+	}
+
+	newXMLBytes, err := xml.MarshalIndent(summaryResult, "  ", "    ")
+	if err != nil {
+		return 0, 0, err
+	}
+	newXMLBytes = append([]byte(xml.Header), newXMLBytes...)
+
+	newXMLStr := strings.Replace(string(newXMLBytes), replaceTestSuite, strings.ToLower(replaceTestSuite), -1)
+
+	err = ioutil.WriteFile(filepath.Join(testUploadPath, testResultFile), []byte(newXMLStr), 0644)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s.logger.Infof(fmt.Sprintf("merge test results files %s succeeded", testResultFile))
+	return len(failedCases), len(quarantinedCases), nil
+}
+
+func getSecondSince(startTime time.Time) float64 {
+	return float64(time.Since(startTime).Round(time.Millisecond).Nanoseconds()) / float64(time.Second)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/helper"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/tool/sarif"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+type SarifReportStep struct {
+	spec       *step.StepSarifReportSpec
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewSarifReportStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*SarifReportStep, error) {
+	sarifReportStep := &SarifReportStep{dirs: dirs, envs: envs, secretEnvs: secretEnvs, logger: logger}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return sarifReportStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &sarifReportStep.spec); err != nil {
+		return sarifReportStep, fmt.Errorf("unmarshal spec %s to sarif report spec failed", yamlBytes)
+	}
+	return sarifReportStep, nil
+}
+
+func (s *SarifReportStep) Run(ctx context.Context) error {
+	s.logger.Infof("Start parse sarif reports.")
+	envMap := helper.MakeEnvMap(s.envs, s.secretEnvs)
+	s.spec.ReportDir = helper.ReplaceEnvWithValue(s.spec.ReportDir, envMap)
+
+	findings, files, err := sarif.Collect(filepath.Join(s.dirs.Workspace, s.spec.ReportDir))
+	if err != nil {
+		return fmt.Errorf("failed to collect sarif reports: %s", err)
+	}
+	s.logger.Infof(fmt.Sprintf("Parsed %d finding(s) from %d sarif file(s).", len(findings), len(files)))
+
+	var client *s3.Client
+	if s.spec.S3Storage != nil {
+		forcedPathStyle := true
+		if s.spec.S3Storage.Provider == setting.ProviderSourceAli {
+			forcedPathStyle = false
+		}
+		client, err = s3.NewClient(s.spec.S3Storage.Endpoint, s.spec.S3Storage.Ak, s.spec.S3Storage.Sk, s.spec.S3Storage.Region, s.spec.S3Storage.Insecure, forcedPathStyle)
+		if err != nil {
+			return fmt.Errorf("failed to create s3 client, err: %s", err)
+		}
+	}
+
+	var baseline *sarif.Report
+	if client != nil && s.spec.BaselinePath != "" {
+		baseline, err = s.downloadBaseline(client)
+		if err != nil {
+			// findings are reported as new instead of failing the step when the baseline is gone
+			s.logger.Warnf(fmt.Sprintf("failed to download the baseline report %s: %s", s.spec.BaselinePath, err))
+		}
+	}
+	report := sarif.NewReport(findings, baseline)
+	for _, severity := range sarif.Severities {
+		s.logger.Infof(fmt.Sprintf("%s: %d, new: %d", severity, report.Summary[severity], report.NewSummary[severity]))
+	}
+
+	if err := os.MkdirAll(s.spec.DestDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create dest dir: %s error: %s", s.spec.DestDir, err)
+	}
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sarif report: %s", err)
+	}
+	absFilePath := filepath.Join(s.spec.DestDir, s.spec.FileName)
+	if err := os.WriteFile(absFilePath, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write sarif report: %s", err)
+	}
+
+	if client != nil && s.spec.S3DestDir != "" && s.spec.FileName != "" {
+		s.logger.Infof(fmt.Sprintf("Start archive %s.", s.spec.FileName))
+		// s3 keys are always separated by slash, even on windows
+		key := path.Join(s.spec.S3DestDir, s.spec.FileName)
+		if len(s.spec.S3Storage.Subfolder) > 0 {
+			key = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, key), "/")
+		}
+		if err := client.Upload(s.spec.S3Storage.Bucket, absFilePath, key); err != nil {
+			return err
+		}
+		s.logger.Infof(fmt.Sprintf("Finish archive %s.", s.spec.FileName))
+	}
+	s.logger.Infof("Finish parse sarif reports.")
+
+	if s.spec.SeverityThreshold == "" {
+		return nil
+	}
+	exceeding := report.Exceeding(s.spec.SeverityThreshold, s.spec.GateNewOnly && report.HasBaseline)
+	if len(exceeding) > 0 {
+		for _, finding := range exceeding {
+			s.logger.Errorf(fmt.Sprintf("[%s] %s %s:%d %s", finding.Severity, finding.RuleID, finding.File, finding.Line, finding.Message))
+		}
+		return fmt.Errorf("%d finding(s) at or above severity %s", len(exceeding), s.spec.SeverityThreshold)
+	}
+	return nil
+}
+
+func (s *SarifReportStep) downloadBaseline(client *s3.Client) (*sarif.Report, error) {
+	key := s.spec.BaselinePath
+	if len(s.spec.S3Storage.Subfolder) > 0 {
+		key = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, key), "/")
+	}
+	tmpFile, err := os.CreateTemp("", "sarif-baseline-")
+	if err != nil {
+		return nil, err
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	if err := client.Download(s.spec.S3Storage.Bucket, key, tmpFile.Name()); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	baseline := &sarif.Report{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
@@ -18,12 +18,17 @@ package step
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/archive"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/debug"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/docker"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/git"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/report"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/script"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/sonar"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/tool"
 	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	jobctl "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/workflowcontroller/jobcontroller"
@@ -71,16 +76,49 @@ func RunStep(ctx context.Context, jobCtx *jobctl.JobContext, step *commonmodels.
 			return err
 		}
 	case "tools":
-		return nil
+		stepInstance, err = tool.NewToolInstallStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
 	case "debug_before":
-		return nil
+		stepInstance, err = debug.NewDebugStep("before", dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
 	case "debug_after":
-		return nil
+		stepInstance, err = debug.NewDebugStep("after", dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
+	case "junit_report":
+		stepInstance, err = report.NewJunitReportStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
+	case "sonar_check":
+		stepInstance, err = sonar.NewSonarCheckStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
+	case "sarif_report":
+		stepInstance, err = report.NewSarifReportStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
+	case "coverage_report":
+		stepInstance, err = report.NewCoverageReportStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
+	case "distribute_image":
+		stepInstance, err = docker.NewDistributeImageStep(step.Spec, dirs, envs, secretEnvs, logger)
+		if err != nil {
+			return err
+		}
 	default:
-		//err := fmt.Errorf("step type: %s does not match any known type", step.StepType)
-		//log.Error(err)
-		//return err
-		logger.Infof("step type: %s does not match any known type", step.StepType)
+		err := fmt.Errorf("step type: %s does not match any known type", step.StepType)
+		logger.Errorf(err.Error())
+		return err
 	}
 	if err := stepInstance.Run(ctx); err != nil {
 		return err
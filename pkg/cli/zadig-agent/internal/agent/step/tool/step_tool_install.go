@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/config"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/helper/log"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/agent/step/helper"
+	"github.com/koderover/zadig/pkg/cli/zadig-agent/internal/common/types"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+	s3tool "github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+const (
+	// toolCachePath is the path under the cache dir and the s3 storage to cache the downloaded packages
+	toolCachePath = "cache"
+	// filepathParam in the install scripts is replaced with the path of the downloaded package
+	filepathParam = "${FILEPATH}"
+	// defaultShell runs the installation scripts, the agent host may not have bash installed
+	defaultShell = "sh"
+)
+
+type ToolInstallStep struct {
+	spec       *step.StepToolInstallSpec
+	envs       []string
+	secretEnvs []string
+	logger     *log.JobLogger
+	dirs       *types.AgentWorkDirs
+}
+
+func NewToolInstallStep(spec interface{}, dirs *types.AgentWorkDirs, envs, secretEnvs []string, logger *log.JobLogger) (*ToolInstallStep, error) {
+	toolInstallStep := &ToolInstallStep{dirs: dirs, envs: envs, secretEnvs: secretEnvs, logger: logger}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return toolInstallStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &toolInstallStep.spec); err != nil {
+		return toolInstallStep, fmt.Errorf("unmarshal spec %s to tool install spec failed", yamlBytes)
+	}
+	return toolInstallStep, nil
+}
+
+func (s *ToolInstallStep) Run(ctx context.Context) error {
+	start := time.Now()
+	s.logger.Infof("Installing tools.")
+	defer func() {
+		s.logger.Infof(fmt.Sprintf("Install tools ended. Duration: %.2f seconds.", time.Since(start).Seconds()))
+	}()
+
+	for _, tool := range s.spec.Installs {
+		if tool == nil {
+			continue
+		}
+		s.logger.Infof(fmt.Sprintf("Installing %s %s.", tool.Name, tool.Version))
+		if err := s.runInstallationScripts(tool); err != nil {
+			return fmt.Errorf("failed to install %s %s, error: %v", tool.Name, tool.Version, err)
+		}
+	}
+	return nil
+}
+
+func (s *ToolInstallStep) runInstallationScripts(tool *step.Tool) error {
+	s.envs = append(s.envs, environs(tool.Envs)...)
+
+	var packagePath string
+	if tool.Download != "" {
+		var err error
+		packagePath, err = s.downloadPackage(tool)
+		if err != nil {
+			return err
+		}
+	}
+
+	file := filepath.Join(s.dirs.JobScriptDir, fmt.Sprintf("install_%s_%s.sh", tool.Name, tool.Version))
+	if err := ioutil.WriteFile(file, []byte(installScript(tool, packagePath)), 0700); err != nil {
+		return fmt.Errorf("write script file error: %v", err)
+	}
+
+	cmd := exec.Command(installShell(s.spec), file)
+	cmd.Dir = s.dirs.Workspace
+	cmd.Env = s.envs
+
+	fileName := s.logger.GetLogfilePath()
+	var wg sync.WaitGroup
+
+	cmdStdoutReader, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		helper.HandleCmdOutput(cmdStdoutReader, true, fileName, s.secretEnvs, log.GetSimpleLogger())
+	}()
+
+	cmdStdErrReader, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		helper.HandleCmdOutput(cmdStdErrReader, true, fileName, s.secretEnvs, log.GetSimpleLogger())
+	}()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	wg.Wait()
+
+	return cmd.Wait()
+}
+
+// downloadPackage looks for the package in the local cache dir first, then the s3 storage,
+// and downloads it from the url of the tool at last. The downloaded package is cached in both places.
+func (s *ToolInstallStep) downloadPackage(tool *step.Tool) (string, error) {
+	subfolder := fmt.Sprintf("%s/%s-v%s", toolCachePath, tool.Name, tool.Version)
+	urlPaths := strings.Split(tool.Download, "/")
+	fileName := urlPaths[len(urlPaths)-1]
+
+	localPath := filepath.Join(s.dirs.CacheDir, subfolder, fileName)
+	if _, err := os.Stat(localPath); err == nil {
+		s.logger.Infof(fmt.Sprintf("Package loaded from local cache: %s", localPath))
+		return localPath, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create tool cache dir, error: %v", err)
+	}
+
+	var s3client *s3tool.Client
+	var objectKey string
+	if s.spec.S3Storage != nil {
+		forcedPathStyle := true
+		if s.spec.S3Storage.Provider == setting.ProviderSourceAli {
+			forcedPathStyle = false
+		}
+		client, err := s3tool.NewClient(s.spec.S3Storage.Endpoint, s.spec.S3Storage.Ak, s.spec.S3Storage.Sk, s.spec.S3Storage.Region, s.spec.S3Storage.Insecure, forcedPathStyle)
+		if err != nil {
+			s.logger.Warnf(fmt.Sprintf("failed to create s3 client to load package cache, error: %v", err))
+		} else {
+			s3client = client
+			objectKey = getObjectPath(fileName, subfolder)
+			if err := s3client.Download(s.spec.S3Storage.Bucket, objectKey, localPath); err == nil {
+				s.logger.Infof(fmt.Sprintf("Package loaded from s3 cache: %s", objectKey))
+				return localPath, nil
+			}
+		}
+	}
+
+	if err := httpclient.Download(tool.Download, localPath); err != nil {
+		os.Remove(localPath)
+		return "", err
+	}
+	s.logger.Infof(fmt.Sprintf("Package loaded from url: %s", tool.Download))
+	if s3client != nil {
+		if err := s3client.Upload(s.spec.S3Storage.Bucket, localPath, objectKey); err != nil {
+			s.logger.Warnf(fmt.Sprintf("failed to upload package to s3 cache, error: %v", err))
+		}
+	}
+	return localPath, nil
+}
+
+// installScript generates the installation script of the tool, the downloaded package path replaces the filepath param
+func installScript(tool *step.Tool, packagePath string) string {
+	scripts := []string{"set -ex"}
+	for _, command := range tool.Scripts {
+		scripts = append(scripts, strings.ReplaceAll(command, filepathParam, packagePath))
+	}
+	return strings.Join(scripts, "\n")
+}
+
+func installShell(spec *step.StepToolInstallSpec) string {
+	if spec == nil || spec.Shell == "" {
+		return defaultShell
+	}
+	return spec.Shell
+}
+
+func environs(envs []string) []string {
+	resp := []string{}
+	for _, val := range envs {
+		if val == "" {
+			continue
+		}
+
+		if len(strings.Split(val, "=")) != 2 {
+			continue
+		}
+
+		replaced := strings.Replace(val, "$HOME", config.Home(), -1)
+		resp = append(resp, replaced)
+	}
+	return resp
+}
+
+func getObjectPath(name, subFolder string) string {
+	// target should not be started with /
+	if subFolder != "" {
+		return strings.TrimLeft(filepath.Join(subFolder, name), "/")
+	}
+
+	return strings.TrimLeft(name, "/")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tool
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+func TestInstallShell(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *step.StepToolInstallSpec
+		want string
+	}{
+		{"nil spec", nil, defaultShell},
+		{"empty shell", &step.StepToolInstallSpec{}, defaultShell},
+		{"configured shell", &step.StepToolInstallSpec{Shell: "/bin/bash"}, "/bin/bash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, installShell(tt.spec))
+		})
+	}
+}
+
+func TestInstallScript(t *testing.T) {
+	tool := &step.Tool{
+		Scripts: []string{
+			"mkdir -p $HOME/go",
+			"tar -C $HOME/go -xzf ${FILEPATH}",
+		},
+	}
+
+	assert.Equal(t, "set -ex\nmkdir -p $HOME/go\ntar -C $HOME/go -xzf /cache/go.tar.gz", installScript(tool, "/cache/go.tar.gz"))
+	assert.Equal(t, "set -ex", installScript(&step.Tool{}, ""))
+}
+
+// the generated script must be runnable by a posix shell, the agent host may not have bash installed
+func TestInstallScriptRunsWithDefaultShell(t *testing.T) {
+	if _, err := exec.LookPath(defaultShell); err != nil {
+		t.Skipf("%s is not installed", defaultShell)
+	}
+
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "tool.tar.gz")
+	require.NoError(t, os.WriteFile(pkg, []byte("package"), 0644))
+
+	tool := &step.Tool{
+		Scripts: []string{
+			"mkdir -p bin",
+			"cp ${FILEPATH} bin/tool",
+			"if [ -f bin/tool ]; then echo installed > bin/status; fi",
+		},
+	}
+	file := filepath.Join(dir, "install.sh")
+	require.NoError(t, os.WriteFile(file, []byte(installScript(tool, pkg)), 0700))
+
+	cmd := exec.Command(installShell(&step.StepToolInstallSpec{}), file)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	status, err := os.ReadFile(filepath.Join(dir, "bin", "status"))
+	require.NoError(t, err)
+	assert.Equal(t, "installed\n", string(status))
+}
+
+func TestEnvirons(t *testing.T) {
+	envs := environs([]string{"", "GOPATH=/go", "INVALID", "A=B=C"})
+	assert.Equal(t, []string{"GOPATH=/go"}, envs)
+}
+
+func TestGetObjectPath(t *testing.T) {
+	assert.Equal(t, "cache/go-v1.20/go.tar.gz", getObjectPath("go.tar.gz", "/cache/go-v1.20"))
+	assert.Equal(t, "go.tar.gz", getObjectPath("/go.tar.gz", ""))
+}
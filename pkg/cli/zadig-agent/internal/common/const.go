@@ -94,6 +94,7 @@ const (
 	JobScriptTmpDir  = "/tmp/job-script/"
 	JobOutputsTmpDir = "/tmp/job-outputs/"
 	JobCacheTmpDir   = "/tmp/caches"
+	JobDebugTmpDir   = "/tmp/job-debug/"
 )
 
 const (
@@ -168,6 +168,12 @@ const (
 	// ProviderGiteeEE
 	ProviderGiteeEE = "gitee-enterprise"
 
+	// ProviderGitea
+	ProviderGitea = "gitea"
+
+	// ProviderBitbucketServer
+	ProviderBitbucketServer = "bitbucket-server"
+
 	// ProviderOther
 	ProviderOther = "other"
 )
@@ -177,11 +183,14 @@ const (
 //
 // e.g. github returns refs/pull/1/head
 // e.g. gitlab returns merge-requests/1/head
+// e.g. bitbucket server returns refs/pull-requests/1/from
 func (r *Repository) PRRef() string {
 	if strings.ToLower(r.Source) == ProviderGitlab || strings.ToLower(r.Source) == ProviderCodehub {
 		return fmt.Sprintf("merge-requests/%d/head", r.PR)
 	} else if strings.ToLower(r.Source) == ProviderGerrit {
 		return r.CheckoutRef
+	} else if strings.ToLower(r.Source) == ProviderBitbucketServer {
+		return fmt.Sprintf("refs/pull-requests/%d/from", r.PR)
 	}
 	return fmt.Sprintf("refs/pull/%d/head", r.PR)
 }
@@ -191,6 +200,8 @@ func (r *Repository) PRRefByPRID(pr int) string {
 		return fmt.Sprintf("merge-requests/%d/head", pr)
 	} else if strings.ToLower(r.Source) == ProviderGerrit {
 		return r.CheckoutRef
+	} else if strings.ToLower(r.Source) == ProviderBitbucketServer {
+		return fmt.Sprintf("refs/pull-requests/%d/from", pr)
 	}
 	return fmt.Sprintf("refs/pull/%d/head", pr)
 }
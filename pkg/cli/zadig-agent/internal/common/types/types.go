@@ -75,4 +75,5 @@ type AgentWorkDirs struct {
 	JobOutputsDir string
 	JobScriptDir  string
 	CacheDir      string
+	JobDebugDir   string
 }
@@ -98,6 +98,11 @@ func ExecutorImage() string {
 	return viper.GetString(setting.ENVExecutorImage)
 }
 
+// BinfmtImage is the digest pinned image installing the QEMU emulators for the multi-platform builds
+func BinfmtImage() string {
+	return viper.GetString(setting.ENVBinfmtImage)
+}
+
 func KodespaceVersion() string {
 	return viper.GetString(setting.ENVKodespaceVersion)
 }
@@ -310,3 +315,35 @@ func Features() string {
 func MysqlUserDB() string {
 	return viper.GetString(setting.ENVMysqlUserDB)
 }
+
+func SecretVaultAddr() string {
+	return viper.GetString(setting.ENVSecretVaultAddr)
+}
+
+func SecretVaultToken() string {
+	return viper.GetString(setting.ENVSecretVaultToken)
+}
+
+func SecretVaultTokenSecret() string {
+	return viper.GetString(setting.ENVSecretVaultTokenSecret)
+}
+
+func SecretVaultK8sRole() string {
+	return viper.GetString(setting.ENVSecretVaultK8sRole)
+}
+
+func SecretVaultK8sAuthPath() string {
+	return viper.GetString(setting.ENVSecretVaultK8sAuthPath)
+}
+
+func SecretFileRoot() string {
+	return viper.GetString(setting.ENVSecretFileRoot)
+}
+
+func SecretKubernetesEnabled() bool {
+	return viper.GetBool(setting.ENVSecretKubernetesEnabled)
+}
+
+func SecretAllowlist() string {
+	return viper.GetString(setting.ENVSecretAllowlist)
+}
@@ -189,6 +189,8 @@ const (
 	StepHtmlReport        StepType = "html_report"
 	StepTarArchive        StepType = "tar_archive"
 	StepSonarCheck        StepType = "sonar_check"
+	StepSarifReport       StepType = "sarif_report"
+	StepCoverageReport    StepType = "coverage_report"
 	StepDistributeImage   StepType = "distribute_image"
 	StepDebugBefore       StepType = "debug_before"
 	StepDebugAfter        StepType = "debug_after"
@@ -229,6 +231,7 @@ const (
 	JobMseGrayRelease       JobType = "mse-gray-release"
 	JobMseGrayOffline       JobType = "mse-gray-offline"
 	JobGuanceyunCheck       JobType = "guanceyun-check"
+	JobPrometheusCheck      JobType = "prometheus-check"
 )
 
 const (
@@ -240,8 +243,11 @@ const (
 type DBInstanceType string
 
 const (
-	DBInstanceTypeMySQL   DBInstanceType = "mysql"
-	DBInstanceTypeMariaDB DBInstanceType = "mariadb"
+	DBInstanceTypeMySQL      DBInstanceType = "mysql"
+	DBInstanceTypeMariaDB    DBInstanceType = "mariadb"
+	DBInstanceTypePostgreSQL DBInstanceType = "postgresql"
+	DBInstanceTypeSQLServer  DBInstanceType = "sqlserver"
+	DBInstanceTypeClickHouse DBInstanceType = "clickhouse"
 )
 
 type ApprovalType string
@@ -429,6 +435,7 @@ const (
 	TestJobHTMLReportStepName    = "html-report-step"
 	TestJobArchiveResultStepName = "archive-result-step"
 	TestJobObjectStorageStepName = "object-storage-step"
+	TestJobCoverageStepName      = "coverage-report-step"
 )
 
 type JobRunPolicy string
@@ -467,6 +474,11 @@ const (
 	DashboardDataTypeReleaseSuccessRate     = "release_success_rate"
 	DashboardDataTypeReleaseAverageDuration = "release_average_duration"
 	DashboardDataTypeReleaseFrequency       = "release_frequency"
+	// DORA metrics, calculated from the production deploy jobs
+	DashboardDataTypeDORADeploymentFrequency = "dora_deployment_frequency"
+	DashboardDataTypeDORALeadTime            = "dora_lead_time_for_changes"
+	DashboardDataTypeDORAChangeFailureRate   = "dora_change_failure_rate"
+	DashboardDataTypeDORAMeanTimeToRestore   = "dora_mean_time_to_restore"
 
 	DashboardDataSourceZadig = "zadig"
 	DashboardDataSourceApi   = "api"
@@ -482,6 +494,11 @@ const (
 	DashboardFunctionTestPassRate         = "(x**2)/80-x/4+1.25"
 	DashboardFunctionTestAverageDuration  = "90000/(x+900)"
 	DashboardFunctionReleaseFrequency     = "100-200/(x+2)"
+	// DORA lead time and mean time to restore are in seconds, change failure rate is in percentage
+	DashboardFunctionDORADeploymentFrequency = "100-200/(x+2)"
+	DashboardFunctionDORALeadTime            = "8640000/(x+86400)"
+	DashboardFunctionDORAChangeFailureRate   = "100-x"
+	DashboardFunctionDORAMeanTimeToRestore   = "360000/(x+3600)"
 )
 
 const (
@@ -496,13 +513,16 @@ const (
 	StatusExecuting      ReleasePlanStatus = "executing"
 	StatusSuccess        ReleasePlanStatus = "success"
 	StatusCancel         ReleasePlanStatus = "cancel"
+	// StatusPaused means the scheduled execution is stopped by a failed release job, it continues after the manager resumes it
+	StatusPaused ReleasePlanStatus = "paused"
 )
 
 // ReleasePlanStatusMap is a map of status and its available next status
 var ReleasePlanStatusMap = map[ReleasePlanStatus][]ReleasePlanStatus{
 	StatusPlanning:       {StatusWaitForApprove, StatusExecuting},
 	StatusWaitForApprove: {StatusPlanning, StatusExecuting},
-	StatusExecuting:      {StatusPlanning, StatusSuccess, StatusCancel},
+	StatusExecuting:      {StatusPlanning, StatusSuccess, StatusCancel, StatusPaused},
+	StatusPaused:         {StatusPlanning, StatusExecuting, StatusCancel},
 }
 
 type ReleasePlanJobType string
@@ -520,3 +540,29 @@ const (
 	ReleasePlanJobStatusFailed  ReleasePlanJobStatus = "failed"
 	ReleasePlanJobStatusRunning ReleasePlanJobStatus = "running"
 )
+
+type DeployFreezeWindowType string
+
+const (
+	// DeployFreezeWindowTypeCron is a recurring window which starts at every activation of a cron expression
+	DeployFreezeWindowTypeCron DeployFreezeWindowType = "cron"
+	// DeployFreezeWindowTypeRange is a one-off window between two points in time
+	DeployFreezeWindowTypeRange DeployFreezeWindowType = "range"
+)
+
+type WorkflowGitSyncDriftPolicy string
+
+const (
+	// WorkflowGitSyncDriftPolicyReject rejects the workflow edits made outside the repo
+	WorkflowGitSyncDriftPolicyReject WorkflowGitSyncDriftPolicy = "reject"
+	// WorkflowGitSyncDriftPolicyPullRequest opens a pull request to the repo with the workflow edits made outside the repo
+	WorkflowGitSyncDriftPolicyPullRequest WorkflowGitSyncDriftPolicy = "pull_request"
+)
+
+type WorkflowGitSyncStatus string
+
+const (
+	WorkflowGitSyncStatusSynced      WorkflowGitSyncStatus = "synced"
+	WorkflowGitSyncStatusFailed      WorkflowGitSyncStatus = "failed"
+	WorkflowGitSyncStatusPullRequest WorkflowGitSyncStatus = "pull_request"
+)
@@ -35,7 +35,9 @@ import (
 	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
 	e "github.com/koderover/zadig/pkg/tool/errors"
 	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/secret"
 	"github.com/koderover/zadig/pkg/types"
+	"github.com/koderover/zadig/pkg/types/step"
 )
 
 type BuildResp struct {
@@ -592,6 +594,17 @@ func correctFields(build *commonmodels.Build) error {
 	if build.PostBuild != nil && build.PostBuild.DockerBuild != nil {
 		build.PostBuild.DockerBuild.DockerFile = strings.Trim(build.PostBuild.DockerBuild.DockerFile, " ")
 		build.PostBuild.DockerBuild.WorkDir = strings.Trim(build.PostBuild.DockerBuild.WorkDir, " ")
+		if err := step.DockerBuilder(build.PostBuild.DockerBuild.Builder).Validate(); err != nil {
+			return err
+		}
+	}
+	// the vm agents resolve the secret references on the vms, which can't read the kubernetes secrets
+	if build.Infrastructure == setting.JobVMInfrastructure && build.PreBuild != nil {
+		for _, env := range build.PreBuild.Envs {
+			if err := secret.CheckVMReferences(env.Value); err != nil {
+				return fmt.Errorf("env %s: %s", env.Key, err)
+			}
+		}
 	}
 	if build.TemplateID == "" {
 		for _, repo := range build.Repos {
@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client"
+	"github.com/koderover/zadig/pkg/tool/bitbucket"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+type Config struct {
+	Address     string `json:"address"`
+	AccessToken string `json:"access_token"`
+	// the field determine whether the proxy is enabled
+	EnableProxy bool `json:"enable_proxy"`
+}
+
+type Client struct {
+	Client *bitbucket.Client
+}
+
+func (c *Config) Open(id int, logger *zap.SugaredLogger) (client.CodeHostClient, error) {
+	cli, err := bitbucket.NewClient(id, c.Address, c.AccessToken, config.ProxyHTTPSAddr(), c.EnableProxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: cli}, nil
+}
+
+// listOptions converts the page of the list options to the start of the paged apis, all the pages are fetched if no page is given
+func listOptions(opt client.ListOpt) *bitbucket.ListOptions {
+	if opt.Page <= 0 || opt.PerPage <= 0 {
+		return nil
+	}
+	return &bitbucket.ListOptions{
+		Start:       (opt.Page - 1) * opt.PerPage,
+		Limit:       opt.PerPage,
+		NoPaginated: true,
+	}
+}
+
+func matchKey(name, key string) bool {
+	return key == "" || strings.Contains(strings.ToLower(name), strings.ToLower(key))
+}
+
+func (c *Client) ListBranches(opt client.ListOpt) ([]*client.Branch, error) {
+	branches, err := c.Client.ListBranches(opt.Namespace, opt.ProjectName, opt.Key, listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Branch
+	for _, b := range branches {
+		res = append(res, &client.Branch{
+			Name: b.DisplayID,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListTags(opt client.ListOpt) ([]*client.Tag, error) {
+	tags, err := c.Client.ListTags(opt.Namespace, opt.ProjectName, opt.Key, listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Tag
+	for _, t := range tags {
+		res = append(res, &client.Tag{
+			Name: t.DisplayID,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListPrs(opt client.ListOpt) ([]*client.PullRequest, error) {
+	prs, err := c.Client.ListPullRequests(opt.Namespace, opt.ProjectName, "OPEN", listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.PullRequest
+	for _, pr := range prs {
+		if opt.TargetBranch != "" && pr.ToRef.DisplayID != opt.TargetBranch {
+			continue
+		}
+		if !matchKey(pr.Title, opt.Key) && !strings.Contains(strconv.Itoa(pr.ID), opt.Key) {
+			continue
+		}
+		item := &client.PullRequest{
+			ID:           pr.ID,
+			Number:       pr.ID,
+			TargetBranch: pr.ToRef.DisplayID,
+			SourceBranch: pr.FromRef.DisplayID,
+			Title:        pr.Title,
+			State:        pr.State,
+			// the timestamps of bitbucket are in milliseconds
+			CreatedAt: pr.CreatedDate / 1000,
+			UpdatedAt: pr.UpdatedDate / 1000,
+		}
+		if pr.Author != nil && pr.Author.User != nil {
+			item.User = pr.Author.User.Name
+			item.AuthorUsername = pr.Author.User.Name
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+// ListNamespaces lists the projects of bitbucket server as the namespaces
+func (c *Client) ListNamespaces(keyword string) ([]*client.Namespace, error) {
+	projects, err := c.Client.ListProjects(nil)
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Namespace
+	for _, p := range projects {
+		if !matchKey(p.Key, keyword) && !matchKey(p.Name, keyword) {
+			continue
+		}
+		res = append(res, &client.Namespace{
+			Name: p.Name,
+			Path: p.Key,
+			Kind: client.OrgKind,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListProjects(opt client.ListOpt) ([]*client.Project, error) {
+	repos, err := c.Client.ListRepositories(opt.Namespace, opt.Key, listOptions(opt))
+	if err != nil {
+		return nil, e.ErrCodehostListProjects.AddDesc(err.Error())
+	}
+
+	var res []*client.Project
+	for _, repo := range repos {
+		project := &client.Project{
+			ID:   repo.ID,
+			Name: repo.Slug,
+		}
+		if repo.Project != nil {
+			project.Namespace = repo.Project.Key
+			project.Description = repo.Project.Description
+		}
+		res = append(res, project)
+	}
+	return res, nil
+}
+
+func (c *Client) ListCommits(opt client.ListOpt) ([]*client.Commit, error) {
+	// only one page of commits is listed, there may be too many commits in the branch
+	listOpt := listOptions(opt)
+	if listOpt == nil {
+		listOpt = &bitbucket.ListOptions{NoPaginated: true}
+	}
+	commits, err := c.Client.ListCommits(opt.Namespace, opt.ProjectName, opt.TargetBranch, listOpt)
+	if err != nil {
+		return nil, e.ErrCodehostListCommits.AddDesc(err.Error())
+	}
+	var res []*client.Commit
+	for _, commit := range commits {
+		item := &client.Commit{
+			ID:        commit.ID,
+			Message:   commit.Message,
+			CreatedAt: commit.AuthorTimestamp / 1000,
+		}
+		if commit.Author != nil {
+			item.Author = commit.Author.Name
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
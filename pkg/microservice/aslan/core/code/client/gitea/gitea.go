@@ -0,0 +1,226 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+	"github.com/koderover/zadig/pkg/tool/gitea"
+)
+
+type Config struct {
+	Address     string `json:"address"`
+	AccessToken string `json:"access_token"`
+	// the field determine whether the proxy is enabled
+	EnableProxy bool `json:"enable_proxy"`
+}
+
+type Client struct {
+	Client *gitea.Client
+}
+
+func (c *Config) Open(id int, logger *zap.SugaredLogger) (client.CodeHostClient, error) {
+	cli, err := gitea.NewClient(id, c.Address, c.AccessToken, config.ProxyHTTPSAddr(), c.EnableProxy)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: cli}, nil
+}
+
+func listOptions(opt client.ListOpt) *gitea.ListOptions {
+	return &gitea.ListOptions{
+		Page:     opt.Page,
+		PageSize: opt.PerPage,
+	}
+}
+
+func matchKey(name, key string) bool {
+	return key == "" || strings.Contains(strings.ToLower(name), strings.ToLower(key))
+}
+
+func (c *Client) ListBranches(opt client.ListOpt) ([]*client.Branch, error) {
+	branches, err := c.Client.ListBranches(opt.Namespace, opt.ProjectName, listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Branch
+	for _, b := range branches {
+		if !matchKey(b.Name, opt.Key) {
+			continue
+		}
+		res = append(res, &client.Branch{
+			Name:      b.Name,
+			Protected: b.Protected,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListTags(opt client.ListOpt) ([]*client.Tag, error) {
+	tags, err := c.Client.ListTags(opt.Namespace, opt.ProjectName, listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Tag
+	for _, t := range tags {
+		if !matchKey(t.Name, opt.Key) {
+			continue
+		}
+		res = append(res, &client.Tag{
+			Name:       t.Name,
+			Message:    t.Message,
+			ZipballURL: t.ZipballURL,
+			TarballURL: t.TarballURL,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListPrs(opt client.ListOpt) ([]*client.PullRequest, error) {
+	prs, err := c.Client.ListPullRequests(opt.Namespace, opt.ProjectName, "open", listOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.PullRequest
+	for _, pr := range prs {
+		if opt.TargetBranch != "" && pr.Base.Ref != opt.TargetBranch {
+			continue
+		}
+		if !matchKey(pr.Title, opt.Key) && !strings.Contains(strconv.Itoa(pr.Number), opt.Key) {
+			continue
+		}
+		item := &client.PullRequest{
+			ID:           pr.Number,
+			Number:       pr.Number,
+			TargetBranch: pr.Base.Ref,
+			SourceBranch: pr.Head.Ref,
+			Title:        pr.Title,
+			State:        pr.State,
+		}
+		if pr.User != nil {
+			item.User = pr.User.Login
+			item.AuthorUsername = pr.User.Login
+		}
+		if pr.CreatedAt != nil {
+			item.CreatedAt = pr.CreatedAt.Unix()
+		}
+		if pr.UpdatedAt != nil {
+			item.UpdatedAt = pr.UpdatedAt.Unix()
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (c *Client) ListNamespaces(keyword string) ([]*client.Namespace, error) {
+	user, err := c.Client.GetAuthenticatedUser()
+	if err != nil {
+		return nil, err
+	}
+	var res []*client.Namespace
+	if matchKey(user.Login, keyword) {
+		res = append(res, &client.Namespace{
+			Name: user.Login,
+			Path: user.Login,
+			Kind: client.UserKind,
+		})
+	}
+
+	orgs, err := c.Client.ListOrganizations(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orgs {
+		if !matchKey(o.UserName, keyword) {
+			continue
+		}
+		res = append(res, &client.Namespace{
+			Name: o.UserName,
+			Path: o.UserName,
+			Kind: client.OrgKind,
+		})
+	}
+	return res, nil
+}
+
+func (c *Client) ListProjects(opt client.ListOpt) ([]*client.Project, error) {
+	var repos []*gitea.Repository
+	var err error
+	switch opt.NamespaceType {
+	case client.OrgKind:
+		repos, err = c.Client.ListOrgRepositories(opt.Namespace, opt.Key, listOptions(opt))
+	default:
+		repos, err = c.Client.ListUserRepositories(opt.Key, listOptions(opt))
+	}
+	if err != nil {
+		return nil, e.ErrCodehostListProjects.AddDesc(err.Error())
+	}
+
+	var res []*client.Project
+	for _, repo := range repos {
+		project := &client.Project{
+			ID:            int(repo.ID),
+			Name:          repo.Name,
+			Description:   repo.Description,
+			DefaultBranch: repo.DefaultBranch,
+		}
+		if repo.Owner != nil {
+			project.Namespace = repo.Owner.Login
+		}
+		res = append(res, project)
+	}
+	return res, nil
+}
+
+func (c *Client) ListCommits(opt client.ListOpt) ([]*client.Commit, error) {
+	// only one page of commits is listed, there may be too many commits in the branch
+	page := opt.Page
+	if page == 0 {
+		page = 1
+	}
+	commits, err := c.Client.ListCommits(opt.Namespace, opt.ProjectName, opt.TargetBranch, &gitea.ListOptions{
+		Page:     page,
+		PageSize: opt.PerPage,
+	})
+	if err != nil {
+		return nil, e.ErrCodehostListCommits.AddDesc(err.Error())
+	}
+	var res []*client.Commit
+	for _, commit := range commits {
+		item := &client.Commit{
+			ID: commit.SHA,
+		}
+		if commit.Commit != nil {
+			item.Message = commit.Commit.Message
+			if commit.Commit.Author != nil {
+				item.Author = commit.Commit.Author.Name
+				if t, err := time.Parse(time.RFC3339, commit.Commit.Author.Date); err == nil {
+					item.CreatedAt = t.Unix()
+				}
+			}
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
@@ -23,7 +23,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/bitbucket"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/gerrit"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/gitea"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/gitee"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/github"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/code/client/gitlab"
@@ -36,11 +38,13 @@ type ClientConfig interface {
 }
 
 var ClientsConfig = map[string]func() ClientConfig{
-	setting.SourceFromGitlab:  func() ClientConfig { return new(gitlab.Config) },
-	setting.SourceFromGithub:  func() ClientConfig { return new(github.Config) },
-	setting.SourceFromGerrit:  func() ClientConfig { return new(gerrit.Config) },
-	setting.SourceFromGitee:   func() ClientConfig { return new(gitee.Config) },
-	setting.SourceFromGiteeEE: func() ClientConfig { return new(gitee.EEConfig) },
+	setting.SourceFromGitlab:          func() ClientConfig { return new(gitlab.Config) },
+	setting.SourceFromGithub:          func() ClientConfig { return new(github.Config) },
+	setting.SourceFromGerrit:          func() ClientConfig { return new(gerrit.Config) },
+	setting.SourceFromGitee:           func() ClientConfig { return new(gitee.Config) },
+	setting.SourceFromGiteeEE:         func() ClientConfig { return new(gitee.EEConfig) },
+	setting.SourceFromGitea:           func() ClientConfig { return new(gitea.Config) },
+	setting.SourceFromBitbucketServer: func() ClientConfig { return new(bitbucket.Config) },
 }
 
 func OpenClient(ch *systemconfig.CodeHost, log *zap.SugaredLogger) (client.CodeHostClient, error) {
@@ -132,6 +132,10 @@ type DockerBuild struct {
 	TemplateID string `bson:"template_id"            json:"template_id"`
 	// TemplateName is the name of the template dockerfile
 	TemplateName string `bson:"template_name"        json:"template_name"`
+	// Builder is the image builder backend: docker, buildkit or kaniko, empty means docker
+	// buildkit and kaniko build in their own containers of the job pod without the docker daemon,
+	// kaniko is not supported by the vm agent
+	Builder string `bson:"builder,omitempty"         json:"builder,omitempty"`
 }
 
 type JenkinsBuild struct {
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/koderover/zadig/pkg/tool/coverage"
+)
+
+// CoverageRecord is the coverage summary of a testing job in a workflow task
+type CoverageRecord struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"   json:"id"`
+	ProjectName   string             `bson:"project_name"    json:"project_name"`
+	TestName      string             `bson:"test_name"       json:"test_name"`
+	ServiceName   string             `bson:"service_name"    json:"service_name"`
+	ServiceModule string             `bson:"service_module"  json:"service_module"`
+	WorkflowName  string             `bson:"workflow_name"   json:"workflow_name"`
+	TaskID        int64              `bson:"task_id"         json:"task_id"`
+	JobName       string             `bson:"job_name"        json:"job_name"`
+	Branch        string             `bson:"branch"          json:"branch"`
+	CommitID      string             `bson:"commit_id"       json:"commit_id"`
+	Formats       []string           `bson:"formats"         json:"formats"`
+	// ReportPath is the object key of the uploaded report which has the coverage of every file
+	ReportPath string            `bson:"report_path"     json:"report_path"`
+	Summary    *coverage.Summary `bson:"summary"         json:"summary"`
+	// Diff is the coverage of the files changed by the pull request which triggered the task
+	Diff       *coverage.Summary `bson:"diff"            json:"diff,omitempty"`
+	CreateTime int64             `bson:"create_time"     json:"create_time"`
+}
+
+func (CoverageRecord) TableName() string {
+	return "coverage_record"
+}
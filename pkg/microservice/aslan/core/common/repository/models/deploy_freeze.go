@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+)
+
+// DeployFreeze is a freeze calendar, production deploy jobs are blocked or need an override approval
+// while any of its windows is active. A freeze without project name applies to all projects.
+type DeployFreeze struct {
+	ID          primitive.ObjectID    `bson:"_id,omitempty"          json:"id,omitempty"`
+	Name        string                `bson:"name"                   json:"name"`
+	ProjectName string                `bson:"project_name"           json:"project_name"`
+	Description string                `bson:"description"            json:"description"`
+	Enabled     bool                  `bson:"enabled"                json:"enabled"`
+	Windows     []*DeployFreezeWindow `bson:"windows"                json:"windows"`
+	CreatedBy   string                `bson:"created_by"             json:"created_by"`
+	CreateTime  int64                 `bson:"create_time"            json:"create_time"`
+	UpdatedBy   string                `bson:"updated_by"             json:"updated_by"`
+	UpdateTime  int64                 `bson:"update_time"            json:"update_time"`
+}
+
+// DeployFreezeWindow is a period of time in which production deploys are frozen.
+// A cron window starts at every activation of Cron and lasts Duration minutes, a range window lasts from StartTime to EndTime.
+// Deploys in a window without approvers are blocked, otherwise they wait until NeededApprovers of the approvers
+// approve the override, Timeout is the minutes to wait for the override approval.
+type DeployFreezeWindow struct {
+	Type            config.DeployFreezeWindowType `bson:"type"                   json:"type"`
+	Cron            string                        `bson:"cron"                   json:"cron"`
+	Duration        int64                         `bson:"duration"               json:"duration"`
+	StartTime       int64                         `bson:"start_time"             json:"start_time"`
+	EndTime         int64                         `bson:"end_time"               json:"end_time"`
+	Reason          string                        `bson:"reason"                 json:"reason"`
+	Approvers       []*User                       `bson:"approvers"              json:"approvers"`
+	NeededApprovers int                           `bson:"needed_approvers"       json:"needed_approvers"`
+	Timeout         int                           `bson:"timeout"                json:"timeout"`
+}
+
+func (DeployFreeze) TableName() string {
+	return "deploy_freeze"
+}
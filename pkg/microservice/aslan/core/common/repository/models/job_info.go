@@ -25,6 +25,9 @@ type JobInfo struct {
 	Production bool `bson:"production" json:"production"`
 	// TargetEnv is the target environment for the deploy job
 	TargetEnv string `bson:"target_env" json:"target_env"`
+	// CommitTime is the earliest commit time of the repos built by the job
+	// for now, this is only used for build jobs to calculate the lead time for changes
+	CommitTime int64 `bson:"commit_time,omitempty" json:"commit_time,omitempty"`
 }
 
 func (JobInfo) TableName() string {
@@ -25,6 +25,8 @@ type LLMIntegration struct {
 	Name        string             `bson:"name"           json:"name"`
 	Token       string             `bson:"token"          json:"token"`
 	BaseURL     string             `bson:"base_url"       json:"base_url"`
+	Model       string             `bson:"model"          json:"model"`
+	TokenLimit  int                `bson:"token_limit"    json:"token_limit"`
 	EnableProxy bool               `bson:"enable_proxy"   json:"enable_proxy"`
 	UpdatedBy   string             `bson:"updated_by"     json:"updated_by"`
 	UpdateTime  int64              `bson:"update_time"    json:"update_time"`
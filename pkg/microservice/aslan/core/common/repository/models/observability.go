@@ -23,9 +23,10 @@ type Observability struct {
 	Type string             `json:"type" bson:"type" yaml:"type"`
 	Name string             `json:"name" bson:"name" yaml:"name"`
 	Host string             `json:"host" bson:"host" yaml:"host"`
-	// ConsoleHost is used for guanceyun console, Host is guanceyun OpenApi Addr
+	// ConsoleHost is used for guanceyun console, Host is guanceyun OpenApi Addr.
+	// For prometheus, Host is the query endpoint of the prometheus compatible backend like thanos, ConsoleHost is not used
 	ConsoleHost string `json:"console_host" bson:"console_host" yaml:"console_host"`
-	// ApiKey is used for guanceyun
+	// ApiKey is used for guanceyun, and as the optional bearer token for prometheus
 	ApiKey string `json:"api_key" bson:"api_key" yaml:"api_key"`
 
 	UpdateTime int64 `json:"update_time" bson:"update_time" yaml:"update_time"`
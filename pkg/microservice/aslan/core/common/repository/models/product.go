@@ -96,12 +96,53 @@ const (
 	WebHookTypeFeishu   WebHookType = "feishu"
 	WebHookTypeDingding WebHookType = "dingding"
 	WebHookTypeWeChat   WebHookType = "wechat"
+	WebHookTypeSlack    WebHookType = "slack"
+	WebHookTypeMSTeams  WebHookType = "msteams"
+	WebHookTypeGeneric  WebHookType = "webhook"
 )
 
 type NotificationConfig struct {
 	WebHookType WebHookType         `bson:"webhook_type" json:"webhook_type"`
 	WebHookURL  string              `bson:"webhook_url"  json:"webhook_url"`
 	Events      []NotificationEvent `bson:"events"       json:"events"`
+	// WebHookSecret signs the body of the generic webhook
+	WebHookSecret string `bson:"webhook_secret,omitempty" json:"webhook_secret,omitempty"`
+}
+
+// MaskNotificationConfigSecrets returns copies of the configs with the webhook secrets masked, they are returned
+// in the api responses instead of the stored ones
+func MaskNotificationConfigSecrets(configs []*NotificationConfig) []*NotificationConfig {
+	if configs == nil {
+		return nil
+	}
+	resp := make([]*NotificationConfig, 0, len(configs))
+	for _, config := range configs {
+		if config == nil || config.WebHookSecret == "" {
+			resp = append(resp, config)
+			continue
+		}
+		masked := *config
+		masked.WebHookSecret = setting.MaskValue
+		resp = append(resp, &masked)
+	}
+	return resp
+}
+
+// EnsureNotificationConfigSecrets restores the masked webhook secrets from the existed configs of the same webhook,
+// the secret is cleared if the webhook is changed
+func EnsureNotificationConfigSecrets(existed, configs []*NotificationConfig) {
+	for _, config := range configs {
+		if config == nil || config.WebHookSecret != setting.MaskValue {
+			continue
+		}
+		config.WebHookSecret = ""
+		for _, existedConfig := range existed {
+			if existedConfig != nil && existedConfig.WebHookType == config.WebHookType && existedConfig.WebHookURL == config.WebHookURL {
+				config.WebHookSecret = existedConfig.WebHookSecret
+				break
+			}
+		}
+	}
 }
 
 type ResourceType string
@@ -37,8 +37,14 @@ type ReleasePlan struct {
 	UpdatedBy   string `bson:"updated_by"       yaml:"updated_by"                   json:"updated_by"`
 	UpdateTime  int64  `bson:"update_time"       yaml:"update_time"                   json:"update_time"`
 
+	// AutoExecute runs the release jobs automatically in the order of their dependencies when the release window opens
+	AutoExecute bool `bson:"auto_execute"       yaml:"auto_execute"                   json:"auto_execute"`
+
 	Approval *Approval `bson:"approval"       yaml:"approval"                   json:"approval,omitempty"`
 
+	// NotifyCtls sends notifications when the status of the plan changes to one of their NotifyTypes
+	NotifyCtls []*NotifyCtl `bson:"notify_ctls"       yaml:"notify_ctls"                   json:"notify_ctls"`
+
 	Jobs []*ReleaseJob `bson:"jobs"       yaml:"jobs"                   json:"jobs"`
 
 	Status config.ReleasePlanStatus `bson:"status"       yaml:"status"                   json:"status"`
@@ -47,6 +53,7 @@ type ReleasePlan struct {
 	ApprovalTime  int64 `bson:"approval_time"       yaml:"approval_time"                   json:"approval_time"`
 	ExecutingTime int64 `bson:"executing_time"       yaml:"executing_time"                   json:"executing_time"`
 	SuccessTime   int64 `bson:"success_time"       yaml:"success_time"                   json:"success_time"`
+	PausedTime    int64 `bson:"paused_time"       yaml:"paused_time"                   json:"paused_time"`
 }
 
 func (ReleasePlan) TableName() string {
@@ -58,6 +65,8 @@ type ReleaseJob struct {
 	Name string                    `bson:"name"       yaml:"name"                   json:"name"`
 	Type config.ReleasePlanJobType `bson:"type"       yaml:"type"                   json:"type"`
 	Spec interface{}               `bson:"spec"       yaml:"spec"                   json:"spec"`
+	// DependsOn is the names of the release jobs which should be done before this job is executed
+	DependsOn []string `bson:"depends_on"       yaml:"depends_on"                   json:"depends_on"`
 
 	ReleaseJobRuntime `bson:",inline" yaml:",inline" json:",inline"`
 }
@@ -45,6 +45,7 @@ type Scanning struct {
 	AdvancedSetting  *ScanningAdvancedSetting `bson:"advanced_setting"      json:"advanced_setting"`
 	CheckQualityGate bool                     `bson:"check_quality_gate"    json:"check_quality_gate"`
 	Outputs          []*Output                `bson:"outputs"               json:"outputs"`
+	SarifReport      *ScanningSarifReport     `bson:"sarif_report"          json:"sarif_report"`
 
 	CreatedAt int64  `bson:"created_at" json:"created_at"`
 	UpdatedAt int64  `bson:"updated_at" json:"updated_at"`
@@ -66,6 +67,18 @@ type ScanningAdvancedSetting struct {
 	Cache      *ScanningCacheSetting `bson:"cache"        json:"cache"`
 }
 
+// ScanningSarifReport collects the sarif files written by the script of the other scanners
+// and gates the scanning on the severity of the findings.
+type ScanningSarifReport struct {
+	Enabled bool `bson:"enabled"            json:"enabled"`
+	// ReportDir is a sarif file or a directory of sarif files relative to the workspace
+	ReportDir string `bson:"report_dir"         json:"report_dir"`
+	// SeverityThreshold is one of critical, high, medium, low and info, empty means no gate
+	SeverityThreshold string `bson:"severity_threshold" json:"severity_threshold"`
+	// GateNewOnly only fails the scanning for the findings not in the last default branch run
+	GateNewOnly bool `bson:"gate_new_only"      json:"gate_new_only"`
+}
+
 type ScanningHookCtl struct {
 	Enabled bool            `bson:"enabled" json:"enabled"`
 	Items   []*ScanningHook `bson:"items"   json:"items"`
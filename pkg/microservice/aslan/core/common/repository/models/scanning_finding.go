@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/koderover/zadig/pkg/tool/sarif"
+)
+
+// MaxScanningFindings caps the findings saved in a document so that it stays far below the document size
+// limit of mongodb, the full report is kept in the object storage at ReportPath.
+const MaxScanningFindings = 1000
+
+// ScanningFinding is the summary and the normalised sarif findings of a scanning job in a workflow task
+type ScanningFinding struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"   json:"id"`
+	ProjectName  string             `bson:"project_name"    json:"project_name"`
+	ScanningName string             `bson:"scanning_name"   json:"scanning_name"`
+	WorkflowName string             `bson:"workflow_name"   json:"workflow_name"`
+	TaskID       int64              `bson:"task_id"         json:"task_id"`
+	JobName      string             `bson:"job_name"        json:"job_name"`
+	Branch       string             `bson:"branch"          json:"branch"`
+	// DefaultBranch marks the findings of a default branch run, the latest one is the baseline of the later runs
+	DefaultBranch bool `bson:"default_branch"  json:"default_branch"`
+	// ReportPath is the object key of the uploaded report
+	ReportPath  string         `bson:"report_path"     json:"report_path"`
+	Tools       []string       `bson:"tools"           json:"tools"`
+	Summary     map[string]int `bson:"summary"         json:"summary"`
+	NewSummary  map[string]int `bson:"new_summary"     json:"new_summary"`
+	HasBaseline bool           `bson:"has_baseline"    json:"has_baseline"`
+	// Findings are the most severe findings of the report with the new ones first, at most MaxScanningFindings
+	Findings []*sarif.Finding `bson:"findings"        json:"findings"`
+	// FindingTotal is the number of the findings in the report, it is larger than len(Findings) if they are capped
+	FindingTotal int   `bson:"finding_total"   json:"finding_total"`
+	CreateTime   int64 `bson:"create_time"     json:"create_time"`
+}
+
+func (ScanningFinding) TableName() string {
+	return "scanning_finding"
+}
@@ -30,6 +30,7 @@ import (
 // Service template config has 3 types mainly.
 // 1. Kubernetes service, and yaml+config is held in aslan: type == "k8s"; source == "spock"; yaml != ""
 // 2. Kubernetes service, and yaml+config is held in gitlab: type == "k8s"; source == "gitlab"; src_path != ""
+// 3. Kubernetes service, and kustomization is held in code host: type == "k8s"; source == "kustomize"; kustomize != nil
 type Service struct {
 	ServiceName        string                           `bson:"service_name"                   json:"service_name"`
 	Type               string                           `bson:"type"                           json:"type"`
@@ -61,6 +62,7 @@ type Service struct {
 	ServiceVariableKVs []*commontypes.ServiceVariableKV `bson:"service_variable_kvs"           json:"service_variable_kvs"` // New since 1.18.0, stores the variable kvs of k8s services
 	ServiceVars        []string                         `bson:"service_vars"                   json:"service_vars"`         // DEPRECATED, New since 1.16.0, stores keys in variables which can be set in env
 	HelmChart          *HelmChart                       `bson:"helm_chart,omitempty"           json:"helm_chart,omitempty"`
+	Kustomize          *Kustomize                       `bson:"kustomize,omitempty"            json:"kustomize,omitempty"`
 	EnvConfigs         []*EnvConfig                     `bson:"env_configs,omitempty"          json:"env_configs,omitempty"`
 	EnvStatuses        []*EnvStatus                     `bson:"env_statuses,omitempty"         json:"env_statuses,omitempty"`
 	ReleaseNaming      string                           `bson:"release_naming"                 json:"release_naming"`
@@ -198,6 +200,28 @@ type HelmChart struct {
 	ValuesYaml string `bson:"values_yaml"        json:"values_yaml"`
 }
 
+// Kustomize is the kustomization of the service, paths are relative to the load path of the service,
+// the service is built with the overlay of the environment when deploying, or the base if no overlay is set for the environment
+type Kustomize struct {
+	BasePath string              `bson:"base_path"          json:"base_path"`
+	Overlays []*KustomizeOverlay `bson:"overlays"           json:"overlays"`
+}
+
+type KustomizeOverlay struct {
+	EnvName string `bson:"env_name"           json:"env_name"`
+	Path    string `bson:"path"               json:"path"`
+}
+
+// GetPath returns the path of the kustomization used by the environment
+func (k *Kustomize) GetPath(envName string) string {
+	for _, overlay := range k.Overlays {
+		if overlay.EnvName == envName {
+			return overlay.Path
+		}
+	}
+	return k.BasePath
+}
+
 type HelmService struct {
 	ProductName string       `json:"product_name"`
 	Project     string       `json:"project"`
@@ -0,0 +1,36 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestCaseQuarantine is a test case of a testing module whose failures do not fail the testing job
+type TestCaseQuarantine struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"   json:"id"`
+	ProjectName string             `bson:"project_name"    json:"project_name"`
+	TestName    string             `bson:"test_name"       json:"test_name"`
+	CaseName    string             `bson:"case_name"       json:"case_name"`
+	Reason      string             `bson:"reason"          json:"reason"`
+	CreatedBy   string             `bson:"created_by"      json:"created_by"`
+	CreateTime  int64              `bson:"create_time"     json:"create_time"`
+}
+
+func (TestCaseQuarantine) TableName() string {
+	return "test_case_quarantine"
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TestCaseStatusPassed  = "passed"
+	TestCaseStatusFailed  = "failed"
+	TestCaseStatusError   = "error"
+	TestCaseStatusSkipped = "skipped"
+)
+
+// TestCaseResult is the result of a single test case in a testing job run, a job which is
+// retried in the same workflow task has a result for every run.
+type TestCaseResult struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"   json:"id"`
+	ProjectName  string             `bson:"project_name"    json:"project_name"`
+	TestName     string             `bson:"test_name"       json:"test_name"`
+	WorkflowName string             `bson:"workflow_name"   json:"workflow_name"`
+	TaskID       int64              `bson:"task_id"         json:"task_id"`
+	JobName      string             `bson:"job_name"        json:"job_name"`
+	CaseName     string             `bson:"case_name"       json:"case_name"`
+	Status       string             `bson:"status"          json:"status"`
+	Message      string             `bson:"message"         json:"message"`
+	Duration     float64            `bson:"duration"        json:"duration"`
+	CommitID     string             `bson:"commit_id"       json:"commit_id"`
+	Branch       string             `bson:"branch"          json:"branch"`
+	Quarantined  bool               `bson:"quarantined"     json:"quarantined"`
+	CreateTime   int64              `bson:"create_time"     json:"create_time"`
+}
+
+func (TestCaseResult) TableName() string {
+	return "test_case_result"
+}
+
+// Key identifies the test case across runs
+func (tc *TestCase) Key() string {
+	if tc.ClassName == "" {
+		return tc.Name
+	}
+	return tc.ClassName + "." + tc.Name
+}
+
+func (tc *TestCase) Status() string {
+	switch {
+	case tc.Error != nil:
+		return TestCaseStatusError
+	case tc.Failure != nil:
+		return TestCaseStatusFailed
+	case tc.Skipped != nil:
+		return TestCaseStatusSkipped
+	default:
+		return TestCaseStatusPassed
+	}
+}
+
+func (tc *TestCase) Message() string {
+	switch {
+	case tc.Error != nil:
+		return tc.Error.Message
+	case tc.Failure != nil:
+		return tc.Failure.Message
+	default:
+		return ""
+	}
+}
@@ -67,6 +67,22 @@ type Testing struct {
 	// New since V1.10.0. Only to tell the webpage should the advanced settings be displayed
 	AdvancedSettingsModified bool      `bson:"advanced_setting_modified" json:"advanced_setting_modified"`
 	Outputs                  []*Output `bson:"outputs"                   json:"outputs"`
+
+	Coverage *TestingCoverage `bson:"coverage"                  json:"coverage"`
+}
+
+// TestingCoverage collects the coverage reports written by the test script and gates the testing
+// on the minimum coverages in percent, a zero minimum means no gate.
+type TestingCoverage struct {
+	Enabled bool `bson:"enabled"             json:"enabled"`
+	// ReportPath is a coverage report or a directory of coverage reports relative to the workspace
+	ReportPath string `bson:"report_path"         json:"report_path"`
+	// Format is one of go, cobertura, jacoco and lcov, it is detected from the reports if empty
+	Format            string  `bson:"format"              json:"format"`
+	MinLineCoverage   float64 `bson:"min_line_coverage"   json:"min_line_coverage"`
+	MinBranchCoverage float64 `bson:"min_branch_coverage" json:"min_branch_coverage"`
+	// MinDiffCoverage is the minimum line coverage of the files changed by the pull request
+	MinDiffCoverage float64 `bson:"min_diff_coverage"   json:"min_diff_coverage"`
 }
 
 type TestingHookCtrl struct {
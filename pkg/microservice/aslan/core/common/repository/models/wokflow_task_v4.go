@@ -94,6 +94,26 @@ type JobTask struct {
 	ServiceModules   []*WorkflowServiceModule `bson:"service_modules"     json:"service_modules"`
 	Infrastructure   string                   `bson:"infrastructure"      json:"infrastructure"`
 	VMLabels         []string                 `bson:"vm_labels"           json:"vm_labels"`
+	// OriginName is the name of the workflow job which this job task is generated from
+	OriginName string `bson:"origin_name"         json:"origin_name"`
+	// DependsOn is the names of workflow jobs this job task waits for
+	DependsOn []string `bson:"depends_on"          json:"depends_on"`
+	// If is the condition expression of the job, SkipReason is set when the job is skipped by it
+	If         string `bson:"if"                  json:"if"`
+	SkipReason string `bson:"skip_reason"         json:"skip_reason"`
+	// VMJobID is the id of the vm job record, used to reattach to the vm job after aslan restarts
+	VMJobID string `bson:"vm_job_id"           json:"vm_job_id"`
+	// MatrixValues is the combination of the matrix values of the job task generated from a matrix job
+	MatrixValues map[string]string `bson:"matrix_values,omitempty" json:"matrix_values,omitempty"`
+	// MatrixMaxParallel is the max number of the job tasks from the same matrix job running at the same time
+	MatrixMaxParallel int `bson:"matrix_max_parallel" json:"matrix_max_parallel"`
+	// MatrixIndex is the 1-based index of the combination of the job task generated from a matrix job
+	MatrixIndex int `bson:"matrix_index,omitempty" json:"matrix_index,omitempty"`
+	// MatrixOriginKey is the key of the job task before the matrix expansion, the outputs of the first
+	// combination are also written to it so the references to the job without the matrix suffix still resolve
+	MatrixOriginKey string `bson:"matrix_origin_key,omitempty" json:"matrix_origin_key,omitempty"`
+	// FreezeApproval is the override approval of the deploy freeze window the production deploy job ran into
+	FreezeApproval *NativeApproval `bson:"freeze_approval,omitempty" json:"freeze_approval,omitempty"`
 }
 
 type TaskJobInfo struct {
@@ -442,6 +462,19 @@ type JobTaskSQLSpec struct {
 	ID   string                `bson:"id" json:"id" yaml:"id"`
 	Type config.DBInstanceType `bson:"type" json:"type" yaml:"type"`
 	SQL  string                `bson:"sql" json:"sql" yaml:"sql"`
+	// Transaction executes all statements in a transaction and rolls back if any of them failed
+	Transaction bool `bson:"transaction" json:"transaction" yaml:"transaction"`
+	// Results is the execution result of every statement in the SQL
+	Results []*SQLExecResult `bson:"results" json:"results" yaml:"results"`
+}
+
+type SQLExecResult struct {
+	SQL          string `bson:"sql"           json:"sql"           yaml:"sql"`
+	Status       string `bson:"status"        json:"status"        yaml:"status"`
+	RowsAffected int64  `bson:"rows_affected" json:"rows_affected" yaml:"rows_affected"`
+	Error        string `bson:"error"         json:"error"         yaml:"error"`
+	// Duration of the statement in milliseconds
+	Duration int64 `bson:"duration"      json:"duration"      yaml:"duration"`
 }
 
 type JobTaskApolloSpec struct {
@@ -511,6 +544,17 @@ type JobTaskGuanceyunCheckSpec struct {
 	Monitors  []*GuanceyunMonitor `bson:"monitors" json:"monitors" yaml:"monitors"`
 }
 
+type JobTaskPrometheusCheckSpec struct {
+	ID           string              `bson:"id"            json:"id"            yaml:"id"`
+	Name         string              `bson:"name"          json:"name"          yaml:"name"`
+	BakeTime     int64               `bson:"bake_time"     json:"bake_time"     yaml:"bake_time"`
+	Interval     int64               `bson:"interval"      json:"interval"      yaml:"interval"`
+	FailureLimit int                 `bson:"failure_limit" json:"failure_limit" yaml:"failure_limit"`
+	Metrics      []*PrometheusMetric `bson:"metrics"       json:"metrics"       yaml:"metrics"`
+	// RollbackJobs are the job tasks of the rollback job, they are run when the analysis fails
+	RollbackJobs []*JobTask `bson:"rollback_jobs" json:"rollback_jobs" yaml:"rollback_jobs"`
+}
+
 type JobTaskMseGrayReleaseSpec struct {
 	Production         bool                  `bson:"production" json:"production" yaml:"production"`
 	GrayTag            string                `bson:"gray_tag" json:"gray_tag" yaml:"gray_tag"`
@@ -599,10 +643,14 @@ type WorkflowTaskCtx struct {
 	WorkflowTaskCreatorEmail    string
 	WorkflowTaskCreatorMobile   string
 	WorkflowKeyVals             []*KeyVal
+	WorkflowParams              []*Param
 	GlobalContextGetAll         func() map[string]string
 	GlobalContextGet            func(key string) (string, bool)
 	GlobalContextSet            func(key, value string)
 	GlobalContextEach           func(f func(k, v string) bool)
 	ClusterIDAdd                func(clusterID string)
 	SetStatus                   func(status config.Status)
+	// Resumed means the workflow task was interrupted by an aslan restart and is being resumed,
+	// jobs which are still running should be reattached instead of being started again
+	Resumed bool
 }
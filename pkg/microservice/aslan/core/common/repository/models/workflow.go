@@ -330,6 +330,10 @@ type NotifyCtl struct {
 	WeChatWebHook   string   `bson:"weChat_webHook,omitempty"      yaml:"weChat_webHook,omitempty"      json:"weChat_webHook,omitempty"`
 	DingDingWebHook string   `bson:"dingding_webhook,omitempty"    yaml:"dingding_webhook,omitempty"    json:"dingding_webhook,omitempty"`
 	FeiShuWebHook   string   `bson:"feishu_webhook,omitempty"      yaml:"feishu_webhook,omitempty"      json:"feishu_webhook,omitempty"`
+	SlackWebHook    string   `bson:"slack_webhook,omitempty"       yaml:"slack_webhook,omitempty"       json:"slack_webhook,omitempty"`
+	MSTeamsWebHook  string   `bson:"msteams_webhook,omitempty"     yaml:"msteams_webhook,omitempty"     json:"msteams_webhook,omitempty"`
+	GenericWebHook  string   `bson:"generic_webhook,omitempty"     yaml:"generic_webhook,omitempty"     json:"generic_webhook,omitempty"`
+	WebHookSecret   string   `bson:"webhook_secret,omitempty"      yaml:"webhook_secret,omitempty"      json:"webhook_secret,omitempty"`
 	AtMobiles       []string `bson:"at_mobiles,omitempty"          yaml:"at_mobiles,omitempty"          json:"at_mobiles,omitempty"`
 	WechatUserIDs   []string `bson:"wechat_user_ids,omitempty"     yaml:"wechat_user_ids,omitempty"     json:"wechat_user_ids,omitempty"`
 	LarkUserIDs     []string `bson:"lark_user_ids,omitempty"       yaml:"lark_user_ids,omitempty"       json:"lark_user_ids,omitempty"`
@@ -337,6 +341,60 @@ type NotifyCtl struct {
 	NotifyTypes     []string `bson:"notify_type"                   yaml:"notify_type"                   json:"notify_type"`
 }
 
+// GetWebHook returns the webhook of the notification channel, WebHookSecret is used to sign the generic webhook
+func (n *NotifyCtl) GetWebHook() string {
+	switch WebHookType(n.WebHookType) {
+	case WebHookTypeDingding:
+		return n.DingDingWebHook
+	case WebHookTypeFeishu:
+		return n.FeiShuWebHook
+	case WebHookTypeSlack:
+		return n.SlackWebHook
+	case WebHookTypeMSTeams:
+		return n.MSTeamsWebHook
+	case WebHookTypeGeneric:
+		return n.GenericWebHook
+	default:
+		return n.WeChatWebHook
+	}
+}
+
+// MaskNotifyCtlSecrets returns copies of the notify ctls with the webhook secrets masked, they are returned
+// in the api responses instead of the stored ones
+func MaskNotifyCtlSecrets(ctls []*NotifyCtl) []*NotifyCtl {
+	if ctls == nil {
+		return nil
+	}
+	resp := make([]*NotifyCtl, 0, len(ctls))
+	for _, ctl := range ctls {
+		if ctl == nil || ctl.WebHookSecret == "" {
+			resp = append(resp, ctl)
+			continue
+		}
+		masked := *ctl
+		masked.WebHookSecret = setting.MaskValue
+		resp = append(resp, &masked)
+	}
+	return resp
+}
+
+// EnsureNotifyCtlSecrets restores the masked webhook secrets from the existed notify ctls of the same webhook,
+// the secret is cleared if the webhook is changed
+func EnsureNotifyCtlSecrets(existed, ctls []*NotifyCtl) {
+	for _, ctl := range ctls {
+		if ctl == nil || ctl.WebHookSecret != setting.MaskValue {
+			continue
+		}
+		ctl.WebHookSecret = ""
+		for _, existedCtl := range existed {
+			if existedCtl != nil && existedCtl.WebHookType == ctl.WebHookType && existedCtl.GetWebHook() == ctl.GetWebHook() {
+				ctl.WebHookSecret = existedCtl.WebHookSecret
+				break
+			}
+		}
+	}
+}
+
 type TaskInfo struct {
 	TaskID       int64         `bson:"task_id"               json:"task_id"`
 	PipelineName string        `bson:"pipeline_name"         json:"pipeline_name"`
@@ -377,6 +435,8 @@ type HookPayload struct {
 	DeliveryID     string `bson:"delivery_id"      json:"delivery_id,omitempty"`
 	CodehostID     int    `bson:"codehost_id"      json:"codehost_id"`
 	EventType      string `bson:"event_type"       json:"event_type"`
+	// ChangedFiles are the files changed by the pull request
+	ChangedFiles []string `bson:"changed_files"    json:"changed_files,omitempty"`
 }
 
 type TargetArgs struct {
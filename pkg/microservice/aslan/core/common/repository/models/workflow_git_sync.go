@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+)
+
+// WorkflowGitSync binds a project to a path in a code host repo, the workflow v4 yaml files under
+// the path are the source of truth of the workflows in the project.
+type WorkflowGitSync struct {
+	ID            primitive.ObjectID                `bson:"_id,omitempty"  json:"id"`
+	ProjectName   string                            `bson:"project_name"   json:"project_name"`
+	CodehostID    int                               `bson:"codehost_id"    json:"codehost_id"`
+	RepoOwner     string                            `bson:"repo_owner"     json:"repo_owner"`
+	RepoNamespace string                            `bson:"repo_namespace" json:"repo_namespace"`
+	RepoName      string                            `bson:"repo_name"      json:"repo_name"`
+	Branch        string                            `bson:"branch"         json:"branch"`
+	Path          string                            `bson:"path"           json:"path"`
+	DriftPolicy   config.WorkflowGitSyncDriftPolicy `bson:"drift_policy"   json:"drift_policy"`
+	// CommitID, SyncTime and Error are the result of the last sync of the whole path
+	CommitID   string `bson:"commit_id"   json:"commit_id"`
+	SyncTime   int64  `bson:"sync_time"   json:"sync_time"`
+	Error      string `bson:"error"       json:"error"`
+	UpdatedBy  string `bson:"updated_by"  json:"updated_by"`
+	UpdateTime int64  `bson:"update_time" json:"update_time"`
+}
+
+func (WorkflowGitSync) TableName() string {
+	return "workflow_git_sync"
+}
+
+func (s *WorkflowGitSync) GetRepoNamespace() string {
+	if s.RepoNamespace != "" {
+		return s.RepoNamespace
+	}
+	return s.RepoOwner
+}
+
+// WorkflowGitSyncStatus is the sync status of a workflow yaml file in the bound path
+type WorkflowGitSyncStatus struct {
+	ID           primitive.ObjectID           `bson:"_id,omitempty" json:"id"`
+	ProjectName  string                       `bson:"project_name"  json:"project_name"`
+	FilePath     string                       `bson:"file_path"     json:"file_path"`
+	WorkflowName string                       `bson:"workflow_name" json:"workflow_name"`
+	Status       config.WorkflowGitSyncStatus `bson:"status"        json:"status"`
+	Error        string                       `bson:"error"         json:"error"`
+	CommitID     string                       `bson:"commit_id"     json:"commit_id"`
+	// PullRequestURL is the pull request opened with the workflow edits made outside the repo
+	PullRequestURL string `bson:"pull_request_url" json:"pull_request_url"`
+	UpdateTime     int64  `bson:"update_time"      json:"update_time"`
+}
+
+func (WorkflowGitSyncStatus) TableName() string {
+	return "workflow_git_sync_status"
+}
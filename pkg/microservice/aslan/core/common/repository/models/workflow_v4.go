@@ -33,6 +33,7 @@ import (
 	"github.com/koderover/zadig/pkg/tool/dingtalk"
 	"github.com/koderover/zadig/pkg/tool/guanceyun"
 	"github.com/koderover/zadig/pkg/tool/lark"
+	"github.com/koderover/zadig/pkg/tool/prometheus"
 	"github.com/koderover/zadig/pkg/types"
 )
 
@@ -214,6 +215,11 @@ type Job struct {
 	Spec           interface{}              `bson:"spec"           yaml:"spec"       json:"spec"`
 	RunPolicy      config.JobRunPolicy      `bson:"run_policy"     yaml:"run_policy" json:"run_policy"`
 	ServiceModules []*WorkflowServiceModule `bson:"service_modules"                  json:"service_modules"`
+	// DependsOn is the names of jobs in the same or previous stages this job waits for,
+	// if not set, the job waits for the jobs before it by the stage order.
+	DependsOn []string `bson:"depends_on"     yaml:"depends_on,omitempty" json:"depends_on"`
+	// If is a govaluate expression evaluated before the job runs, the job is skipped if it is evaluated to false.
+	If string `bson:"if"             yaml:"if,omitempty"         json:"if"`
 }
 
 type WorkflowServiceModule struct {
@@ -250,11 +256,39 @@ type FreestyleJobSpec struct {
 	Properties *JobProperties `bson:"properties"     yaml:"properties"    json:"properties"`
 	Steps      []*Step        `bson:"steps"          yaml:"steps"         json:"steps"`
 	Outputs    []*Output      `bson:"outputs"        yaml:"outputs"       json:"outputs"`
+	Matrix     *JobMatrix     `bson:"matrix,omitempty" yaml:"matrix,omitempty" json:"matrix,omitempty"`
+}
+
+// JobMatrix fans a job out into one job task for every combination of the axis values,
+// the values of a combination are injected into the job task as envs.
+// The outputs of the Nth combination are referenced by {{.job.<key>-matrix-N.output.<name>}},
+// and {{.job.<key>.output.<name>}} resolves to the outputs of the first combination.
+type JobMatrix struct {
+	Axes []*MatrixAxis `bson:"axes"          yaml:"axes"          json:"axes"`
+	// Include adds the key/vals to every combination it does not conflict with,
+	// it is added as a new combination if no combination matches it.
+	Include []map[string]string `bson:"include"       yaml:"include"       json:"include"`
+	// Exclude removes the combinations matching all the key/vals of any of the rules.
+	Exclude []map[string]string `bson:"exclude"       yaml:"exclude"       json:"exclude"`
+	// MaxParallel is the max number of the job tasks of the matrix running at the same time, 0 means no limit.
+	MaxParallel int `bson:"max_parallel"  yaml:"max_parallel"  json:"max_parallel"`
+}
+
+type MatrixAxis struct {
+	Key    string   `bson:"key"       yaml:"key"       json:"key"`
+	Values []string `bson:"values"    yaml:"values"    json:"values"`
 }
 
 type ZadigBuildJobSpec struct {
 	DockerRegistryID string             `bson:"docker_registry_id"     yaml:"docker_registry_id"     json:"docker_registry_id"`
 	ServiceAndBuilds []*ServiceAndBuild `bson:"service_and_builds"     yaml:"service_and_builds"     json:"service_and_builds"`
+	Matrix           *JobMatrix         `bson:"matrix,omitempty"       yaml:"matrix,omitempty"       json:"matrix,omitempty"`
+	// Platforms and BuildCache are passed to the docker build step of every service build
+	Platforms  []string             `bson:"platforms,omitempty"    yaml:"platforms,omitempty"    json:"platforms,omitempty"`
+	BuildCache *types.BuildKitCache `bson:"build_cache,omitempty"  yaml:"build_cache,omitempty"  json:"build_cache,omitempty"`
+	// InstallEmulators installs the QEMU emulators of the foreign platforms on the docker daemon before the builds,
+	// it changes the binfmt_misc of the node running the daemon and is off unless the job opts in
+	InstallEmulators bool `bson:"install_emulators,omitempty" yaml:"install_emulators,omitempty" json:"install_emulators,omitempty"`
 }
 
 type ServiceAndBuild struct {
@@ -292,6 +326,8 @@ type ZadigHelmChartDeployJobSpec struct {
 	DeployHelmCharts   []*DeployHelmChart `bson:"deploy_helm_charts"       yaml:"deploy_helm_charts"          json:"deploy_helm_charts"`
 }
 
+// DeployHelmChart is the chart to deploy, ChartVersion can be a fixed version, a semver constraint like `~1.2` or `>=1.0.0 <2.0.0`,
+// or empty for the latest version, it is resolved to the latest matching version of the chart repo when the task is created.
 type DeployHelmChart struct {
 	ReleaseName  string `bson:"release_name"          yaml:"release_name"             json:"release_name"`
 	ChartRepo    string `bson:"chart_repo"            yaml:"chart_repo"               json:"chart_repo"`
@@ -375,6 +411,7 @@ type ZadigTestingJobSpec struct {
 	TargetServices  []*ServiceTestTarget    `bson:"target_services"  yaml:"target_services"  json:"target_services"`
 	TestModules     []*TestModule           `bson:"test_modules"     yaml:"test_modules"     json:"test_modules"`
 	ServiceAndTests []*ServiceAndTest       `bson:"service_and_tests" yaml:"service_and_tests" json:"service_and_tests"`
+	Matrix          *JobMatrix              `bson:"matrix,omitempty" yaml:"matrix,omitempty" json:"matrix,omitempty"`
 }
 
 type ServiceAndTest struct {
@@ -581,6 +618,8 @@ type SQLJobSpec struct {
 	Type   config.DBInstanceType `bson:"type" json:"type" yaml:"type"`
 	SQL    string                `bson:"sql" json:"sql" yaml:"sql"`
 	Source string                `bson:"source" json:"source" yaml:"source"`
+	// Transaction executes all statements in a transaction and rolls back if any of them failed
+	Transaction bool `bson:"transaction" json:"transaction" yaml:"transaction"`
 }
 
 type ApolloJobSpec struct {
@@ -647,6 +686,35 @@ type GuanceyunMonitor struct {
 	Url    string          `bson:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
+// PrometheusCheckJobSpec analyzes the metrics of a release by PromQL queries every Interval seconds during BakeTime minutes,
+// the job fails once a metric fails more than FailureLimit rounds. RollbackJobName is the name of an istio or gray rollback
+// job in the workflow, it is not run in its own stage but run by this job when the analysis fails.
+type PrometheusCheckJobSpec struct {
+	ID              string              `bson:"id"                json:"id"                yaml:"id"`
+	Name            string              `bson:"name"              json:"name"              yaml:"name"`
+	BakeTime        int64               `bson:"bake_time"         json:"bake_time"         yaml:"bake_time"`
+	Interval        int64               `bson:"interval"          json:"interval"          yaml:"interval"`
+	FailureLimit    int                 `bson:"failure_limit"     json:"failure_limit"     yaml:"failure_limit"`
+	Metrics         []*PrometheusMetric `bson:"metrics"           json:"metrics"           yaml:"metrics"`
+	RollbackJobName string              `bson:"rollback_job_name" json:"rollback_job_name" yaml:"rollback_job_name"`
+}
+
+// PrometheusMetric passes a round of analysis if "value Operator target" holds, the target is Threshold,
+// or the value of BaselineQuery loosened by Tolerance percent if BaselineQuery is set.
+type PrometheusMetric struct {
+	Name          string              `bson:"name"                     json:"name"                     yaml:"name"`
+	Query         string              `bson:"query"                    json:"query"                    yaml:"query"`
+	Operator      prometheus.Operator `bson:"operator"                 json:"operator"                 yaml:"operator"`
+	Threshold     float64             `bson:"threshold"                json:"threshold"                yaml:"threshold"`
+	BaselineQuery string              `bson:"baseline_query"           json:"baseline_query"           yaml:"baseline_query"`
+	Tolerance     float64             `bson:"tolerance"                json:"tolerance"                yaml:"tolerance"`
+	Status        string              `bson:"status,omitempty"         json:"status,omitempty"         yaml:"status,omitempty"`
+	Value         float64             `bson:"value,omitempty"          json:"value,omitempty"          yaml:"value,omitempty"`
+	Target        float64             `bson:"target,omitempty"         json:"target,omitempty"         yaml:"target,omitempty"`
+	Failures      int                 `bson:"failures,omitempty"       json:"failures,omitempty"       yaml:"failures,omitempty"`
+	Error         string              `bson:"error,omitempty"          json:"error,omitempty"          yaml:"error,omitempty"`
+}
+
 type JenkinsJobSpec struct {
 	ID   string            `bson:"id" json:"id" yaml:"id"`
 	Jobs []*JenkinsJobInfo `bson:"jobs" json:"jobs" yaml:"jobs"`
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type CoverageRecordColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+type CoverageRecordListOption struct {
+	ProjectNames []string
+	TestName     string
+	ServiceName  string
+	StartTime    int64
+	EndTime      int64
+	PageNum      int64
+	PageSize     int64
+}
+
+func NewCoverageRecordColl() *CoverageRecordColl {
+	name := models.CoverageRecord{}.TableName()
+	return &CoverageRecordColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *CoverageRecordColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *CoverageRecordColl) EnsureIndex(ctx context.Context) error {
+	mod := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				bson.E{Key: "workflow_name", Value: 1},
+				bson.E{Key: "task_id", Value: 1},
+				bson.E{Key: "job_name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				bson.E{Key: "project_name", Value: 1},
+				bson.E{Key: "test_name", Value: 1},
+				bson.E{Key: "create_time", Value: -1},
+			},
+		},
+	}
+	_, err := c.Indexes().CreateMany(ctx, mod)
+	return err
+}
+
+// Upsert replaces the coverage of the job, a job is summarized again when its task is retried
+func (c *CoverageRecordColl) Upsert(args *models.CoverageRecord) error {
+	if args == nil {
+		return errors.New("nil coverage record")
+	}
+
+	query := bson.M{"workflow_name": args.WorkflowName, "task_id": args.TaskID, "job_name": args.JobName}
+	args.ID = primitive.NilObjectID
+	_, err := c.ReplaceOne(context.TODO(), query, args, options.Replace().SetUpsert(true))
+	return err
+}
+
+// List returns the coverage records ordered by create time descending, with the total count when
+// paging is enabled
+func (c *CoverageRecordColl) List(opt *CoverageRecordListOption) ([]*models.CoverageRecord, int64, error) {
+	query := bson.M{}
+	if len(opt.ProjectNames) > 0 {
+		query["project_name"] = bson.M{"$in": opt.ProjectNames}
+	}
+	if opt.TestName != "" {
+		query["test_name"] = opt.TestName
+	}
+	if opt.ServiceName != "" {
+		query["service_name"] = opt.ServiceName
+	}
+	timeRange := bson.M{}
+	if opt.StartTime > 0 {
+		timeRange["$gte"] = opt.StartTime
+	}
+	if opt.EndTime > 0 {
+		timeRange["$lte"] = opt.EndTime
+	}
+	if len(timeRange) > 0 {
+		query["create_time"] = timeRange
+	}
+
+	ctx := context.Background()
+	var count int64
+	opts := options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	if opt.PageNum > 0 && opt.PageSize > 0 {
+		opts.SetSkip((opt.PageNum - 1) * opt.PageSize).SetLimit(opt.PageSize)
+		total, err := c.CountDocuments(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		count = total
+	}
+
+	resp := make([]*models.CoverageRecord, 0)
+	cursor, err := c.Collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, count, err
+}
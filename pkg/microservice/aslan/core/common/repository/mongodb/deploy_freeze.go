@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type DeployFreezeColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+type DeployFreezeListOption struct {
+	// ProjectName lists the freezes of the project, the system-wide freezes are included if WithSystem is set
+	ProjectName string
+	WithSystem  bool
+	EnabledOnly bool
+}
+
+func NewDeployFreezeColl() *DeployFreezeColl {
+	name := models.DeployFreeze{}.TableName()
+	return &DeployFreezeColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *DeployFreezeColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *DeployFreezeColl) EnsureIndex(ctx context.Context) error {
+	mod := mongo.IndexModel{
+		Keys: bson.D{
+			bson.E{Key: "project_name", Value: 1},
+			bson.E{Key: "name", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := c.Indexes().CreateOne(ctx, mod)
+	return err
+}
+
+func (c *DeployFreezeColl) List(opt *DeployFreezeListOption) ([]*models.DeployFreeze, error) {
+	query := bson.M{}
+	if opt != nil {
+		if opt.WithSystem {
+			query["project_name"] = bson.M{"$in": []string{opt.ProjectName, ""}}
+		} else {
+			query["project_name"] = opt.ProjectName
+		}
+		if opt.EnabledOnly {
+			query["enabled"] = true
+		}
+	}
+
+	resp := make([]*models.DeployFreeze, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
+
+func (c *DeployFreezeColl) GetByID(id string) (*models.DeployFreeze, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(models.DeployFreeze)
+	err = c.FindOne(context.TODO(), bson.M{"_id": oid}).Decode(resp)
+	return resp, err
+}
+
+func (c *DeployFreezeColl) Create(args *models.DeployFreeze) error {
+	if args == nil {
+		return errors.New("nil deploy freeze info")
+	}
+
+	args.CreateTime = time.Now().Unix()
+	args.UpdateTime = time.Now().Unix()
+
+	_, err := c.InsertOne(context.TODO(), args)
+	return err
+}
+
+func (c *DeployFreezeColl) Update(id string, args *models.DeployFreeze) error {
+	if args == nil {
+		return errors.New("nil deploy freeze info")
+	}
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	query := bson.M{"_id": oid}
+	change := bson.M{"$set": bson.M{
+		"name":        args.Name,
+		"description": args.Description,
+		"enabled":     args.Enabled,
+		"windows":     args.Windows,
+		"updated_by":  args.UpdatedBy,
+		"update_time": time.Now().Unix(),
+	}}
+
+	_, err = c.UpdateOne(context.TODO(), query, change)
+	return err
+}
+
+func (c *DeployFreezeColl) Delete(id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DeleteOne(context.TODO(), bson.M{"_id": oid})
+	return err
+}
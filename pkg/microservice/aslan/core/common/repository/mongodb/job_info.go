@@ -156,6 +156,87 @@ func (c *JobInfoColl) GetDeployJobs(startTime, endTime int64, projectName string
 	return resp, err
 }
 
+// GetProductionDeployJobsByService returns the production deploy jobs of the given service,
+// all services are returned if the serviceName is empty
+func (c *JobInfoColl) GetProductionDeployJobsByService(startTime, endTime int64, projectName, serviceName string) ([]*models.JobInfo, error) {
+	query := bson.M{}
+	query["start_time"] = bson.M{"$gte": startTime, "$lt": endTime}
+	query["production"] = true
+	query["type"] = bson.M{"$in": []string{
+		string(config.JobZadigDeploy),
+		string(config.JobZadigHelmDeploy),
+		string(config.JobZadigHelmChartDeploy),
+		string(config.JobDeploy),
+	}}
+	if len(projectName) != 0 {
+		query["product_name"] = projectName
+	}
+	if len(serviceName) != 0 {
+		query["service_name"] = serviceName
+	}
+
+	resp := make([]*models.JobInfo, 0)
+
+	cursor, err := c.Find(context.Background(), query, options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &resp)
+
+	return resp, err
+}
+
+// GetBuildJobsWithCommit returns the passed build jobs which have the commit time recorded
+func (c *JobInfoColl) GetBuildJobsWithCommit(startTime, endTime int64, projectName, serviceName string) ([]*models.JobInfo, error) {
+	query := bson.M{}
+	query["start_time"] = bson.M{"$gte": startTime, "$lt": endTime}
+	query["type"] = config.JobZadigBuild
+	query["status"] = string(config.StatusPassed)
+	query["commit_time"] = bson.M{"$gt": 0}
+	if len(projectName) != 0 {
+		query["product_name"] = projectName
+	}
+	if len(serviceName) != 0 {
+		query["service_name"] = serviceName
+	}
+
+	resp := make([]*models.JobInfo, 0)
+
+	cursor, err := c.Find(context.Background(), query, options.Find())
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &resp)
+
+	return resp, err
+}
+
+// GetRollbackJobs lists the rollback jobs, the jobs saved without a service are returned for every service
+func (c *JobInfoColl) GetRollbackJobs(startTime, endTime int64, projectName, serviceName string) ([]*models.JobInfo, error) {
+	query := bson.M{}
+	query["start_time"] = bson.M{"$gte": startTime, "$lt": endTime}
+	query["type"] = bson.M{"$in": []string{
+		string(config.JobIstioRollback),
+		string(config.JobK8sGrayRollback),
+	}}
+	if len(projectName) != 0 {
+		query["product_name"] = projectName
+	}
+	if len(serviceName) != 0 {
+		query["service_name"] = bson.M{"$in": []string{serviceName, ""}}
+	}
+
+	resp := make([]*models.JobInfo, 0)
+
+	cursor, err := c.Find(context.Background(), query, options.Find())
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &resp)
+
+	return resp, err
+}
+
 type JobInfoCoarseGrainedData struct {
 	StartTime   int64             `json:"start_time"`
 	EndTime     int64             `json:"end_time"`
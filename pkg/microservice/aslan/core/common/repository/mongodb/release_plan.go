@@ -152,3 +152,20 @@ func (c *ReleasePlanColl) ListByOptions(opt *ListReleasePlanOption) ([]*models.R
 
 	return resp, count, nil
 }
+
+// ListByExecutingTime lists the release plans which started executing in [startTime, endTime)
+func (c *ReleasePlanColl) ListByExecutingTime(startTime, endTime int64) ([]*models.ReleasePlan, error) {
+	query := bson.M{
+		"executing_time": bson.M{"$gte": startTime, "$lt": endTime},
+	}
+
+	resp := make([]*models.ReleasePlan, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
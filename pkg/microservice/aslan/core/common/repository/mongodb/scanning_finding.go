@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type ScanningFindingColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+func NewScanningFindingColl() *ScanningFindingColl {
+	name := models.ScanningFinding{}.TableName()
+	return &ScanningFindingColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *ScanningFindingColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *ScanningFindingColl) EnsureIndex(ctx context.Context) error {
+	mod := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				bson.E{Key: "workflow_name", Value: 1},
+				bson.E{Key: "task_id", Value: 1},
+				bson.E{Key: "job_name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				bson.E{Key: "project_name", Value: 1},
+				bson.E{Key: "scanning_name", Value: 1},
+				bson.E{Key: "default_branch", Value: 1},
+				bson.E{Key: "create_time", Value: -1},
+			},
+		},
+	}
+	_, err := c.Indexes().CreateMany(ctx, mod)
+	return err
+}
+
+// Upsert replaces the findings of the job, a job is summarized again when its task is retried
+func (c *ScanningFindingColl) Upsert(args *models.ScanningFinding) error {
+	if args == nil {
+		return errors.New("nil scanning finding")
+	}
+
+	query := bson.M{"workflow_name": args.WorkflowName, "task_id": args.TaskID, "job_name": args.JobName}
+	args.ID = primitive.NilObjectID
+	_, err := c.ReplaceOne(context.TODO(), query, args, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (c *ScanningFindingColl) Find(workflowName string, taskID int64, jobName string) (*models.ScanningFinding, error) {
+	resp := new(models.ScanningFinding)
+	query := bson.M{"workflow_name": workflowName, "task_id": taskID, "job_name": jobName}
+	err := c.FindOne(context.TODO(), query).Decode(resp)
+	return resp, err
+}
+
+// FindBaseline finds the findings of the latest default branch run of the scanning
+func (c *ScanningFindingColl) FindBaseline(projectName, scanningName string) (*models.ScanningFinding, error) {
+	resp := new(models.ScanningFinding)
+	query := bson.M{"project_name": projectName, "scanning_name": scanningName, "default_branch": true}
+	opts := options.FindOne().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	err := c.FindOne(context.TODO(), query, opts).Decode(resp)
+	return resp, err
+}
+
+func (c *ScanningFindingColl) ListByWorkflowTask(workflowName string, taskID int64) ([]*models.ScanningFinding, error) {
+	resp := make([]*models.ScanningFinding, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, bson.M{"workflow_name": workflowName, "task_id": taskID})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
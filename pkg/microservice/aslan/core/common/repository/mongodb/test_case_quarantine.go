@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type TestCaseQuarantineColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+func NewTestCaseQuarantineColl() *TestCaseQuarantineColl {
+	name := models.TestCaseQuarantine{}.TableName()
+	return &TestCaseQuarantineColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *TestCaseQuarantineColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *TestCaseQuarantineColl) EnsureIndex(ctx context.Context) error {
+	mod := mongo.IndexModel{
+		Keys: bson.D{
+			bson.E{Key: "project_name", Value: 1},
+			bson.E{Key: "test_name", Value: 1},
+			bson.E{Key: "case_name", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := c.Indexes().CreateOne(ctx, mod)
+	return err
+}
+
+func (c *TestCaseQuarantineColl) Upsert(args *models.TestCaseQuarantine) error {
+	if args == nil {
+		return errors.New("nil test case quarantine")
+	}
+
+	query := bson.M{"project_name": args.ProjectName, "test_name": args.TestName, "case_name": args.CaseName}
+	change := bson.M{"$set": args}
+	_, err := c.UpdateOne(context.TODO(), query, change, options.Update().SetUpsert(true))
+	return err
+}
+
+func (c *TestCaseQuarantineColl) List(projectName, testName string) ([]*models.TestCaseQuarantine, error) {
+	resp := make([]*models.TestCaseQuarantine, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, bson.M{"project_name": projectName, "test_name": testName})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
+
+func (c *TestCaseQuarantineColl) Delete(projectName, testName, caseName string) error {
+	query := bson.M{"project_name": projectName, "test_name": testName, "case_name": caseName}
+	_, err := c.DeleteOne(context.TODO(), query)
+	return err
+}
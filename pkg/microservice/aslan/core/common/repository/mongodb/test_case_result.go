@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type TestCaseResultColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+type TestCaseResultListOption struct {
+	ProjectName string
+	TestName    string
+	CaseName    string
+	Status      string
+	// StartTime limits the results to those created after it
+	StartTime int64
+	PageNum   int64
+	PageSize  int64
+}
+
+func NewTestCaseResultColl() *TestCaseResultColl {
+	name := models.TestCaseResult{}.TableName()
+	return &TestCaseResultColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *TestCaseResultColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *TestCaseResultColl) EnsureIndex(ctx context.Context) error {
+	mod := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				bson.E{Key: "project_name", Value: 1},
+				bson.E{Key: "test_name", Value: 1},
+				bson.E{Key: "case_name", Value: 1},
+				bson.E{Key: "create_time", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				bson.E{Key: "workflow_name", Value: 1},
+				bson.E{Key: "task_id", Value: 1},
+			},
+		},
+	}
+	_, err := c.Indexes().CreateMany(ctx, mod)
+	return err
+}
+
+func (c *TestCaseResultColl) BulkCreate(args []*models.TestCaseResult) error {
+	if len(args) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		docs = append(docs, arg)
+	}
+	_, err := c.InsertMany(context.TODO(), docs)
+	return err
+}
+
+// List returns the results of the test cases ordered by create time descending, with the
+// total count when paging is enabled
+func (c *TestCaseResultColl) List(opt *TestCaseResultListOption) ([]*models.TestCaseResult, int64, error) {
+	query := bson.M{"project_name": opt.ProjectName, "test_name": opt.TestName}
+	if opt.CaseName != "" {
+		query["case_name"] = opt.CaseName
+	}
+	if opt.Status != "" {
+		query["status"] = opt.Status
+	}
+	if opt.StartTime > 0 {
+		query["create_time"] = bson.M{"$gte": opt.StartTime}
+	}
+
+	ctx := context.Background()
+	var count int64
+	opts := options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	if opt.PageNum > 0 && opt.PageSize > 0 {
+		opts.SetSkip((opt.PageNum - 1) * opt.PageSize).SetLimit(opt.PageSize)
+		total, err := c.CountDocuments(ctx, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		count = total
+	}
+
+	resp := make([]*models.TestCaseResult, 0)
+	cursor, err := c.Collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, count, err
+}
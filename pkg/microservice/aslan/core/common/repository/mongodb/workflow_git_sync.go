@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type WorkflowGitSyncColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+func NewWorkflowGitSyncColl() *WorkflowGitSyncColl {
+	name := models.WorkflowGitSync{}.TableName()
+	return &WorkflowGitSyncColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *WorkflowGitSyncColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *WorkflowGitSyncColl) EnsureIndex(ctx context.Context) error {
+	mod := mongo.IndexModel{
+		Keys:    bson.M{"project_name": 1},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := c.Indexes().CreateOne(ctx, mod)
+	return err
+}
+
+func (c *WorkflowGitSyncColl) List() ([]*models.WorkflowGitSync, error) {
+	resp := make([]*models.WorkflowGitSync, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
+
+func (c *WorkflowGitSyncColl) GetByProject(projectName string) (*models.WorkflowGitSync, error) {
+	resp := new(models.WorkflowGitSync)
+	err := c.FindOne(context.TODO(), bson.M{"project_name": projectName}).Decode(resp)
+	return resp, err
+}
+
+// Upsert saves the binding of the project, the result of the last sync is kept
+func (c *WorkflowGitSyncColl) Upsert(args *models.WorkflowGitSync) error {
+	if args == nil {
+		return errors.New("nil workflow git sync info")
+	}
+
+	query := bson.M{"project_name": args.ProjectName}
+	change := bson.M{"$set": bson.M{
+		"codehost_id":    args.CodehostID,
+		"repo_owner":     args.RepoOwner,
+		"repo_namespace": args.RepoNamespace,
+		"repo_name":      args.RepoName,
+		"branch":         args.Branch,
+		"path":           args.Path,
+		"drift_policy":   args.DriftPolicy,
+		"updated_by":     args.UpdatedBy,
+		"update_time":    time.Now().Unix(),
+	}}
+
+	_, err := c.UpdateOne(context.TODO(), query, change, options.Update().SetUpsert(true))
+	return err
+}
+
+func (c *WorkflowGitSyncColl) UpdateSyncResult(projectName, commitID, syncErr string) error {
+	query := bson.M{"project_name": projectName}
+	change := bson.M{"$set": bson.M{
+		"commit_id": commitID,
+		"sync_time": time.Now().Unix(),
+		"error":     syncErr,
+	}}
+
+	_, err := c.UpdateOne(context.TODO(), query, change)
+	return err
+}
+
+func (c *WorkflowGitSyncColl) Delete(projectName string) error {
+	_, err := c.DeleteOne(context.TODO(), bson.M{"project_name": projectName})
+	return err
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	mongotool "github.com/koderover/zadig/pkg/tool/mongo"
+)
+
+type WorkflowGitSyncStatusColl struct {
+	*mongo.Collection
+
+	coll string
+}
+
+func NewWorkflowGitSyncStatusColl() *WorkflowGitSyncStatusColl {
+	name := models.WorkflowGitSyncStatus{}.TableName()
+	return &WorkflowGitSyncStatusColl{
+		Collection: mongotool.Database(config.MongoDatabase()).Collection(name),
+		coll:       name,
+	}
+}
+
+func (c *WorkflowGitSyncStatusColl) GetCollectionName() string {
+	return c.coll
+}
+
+func (c *WorkflowGitSyncStatusColl) EnsureIndex(ctx context.Context) error {
+	mod := mongo.IndexModel{
+		Keys: bson.D{
+			bson.E{Key: "project_name", Value: 1},
+			bson.E{Key: "file_path", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := c.Indexes().CreateOne(ctx, mod)
+	return err
+}
+
+func (c *WorkflowGitSyncStatusColl) List(projectName string) ([]*models.WorkflowGitSyncStatus, error) {
+	resp := make([]*models.WorkflowGitSyncStatus, 0)
+	ctx := context.Background()
+	cursor, err := c.Collection.Find(ctx, bson.M{"project_name": projectName}, options.Find().SetSort(bson.D{{Key: "file_path", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &resp)
+	return resp, err
+}
+
+func (c *WorkflowGitSyncStatusColl) FindByWorkflow(projectName, workflowName string) (*models.WorkflowGitSyncStatus, error) {
+	resp := new(models.WorkflowGitSyncStatus)
+	err := c.FindOne(context.TODO(), bson.M{"project_name": projectName, "workflow_name": workflowName}).Decode(resp)
+	return resp, err
+}
+
+func (c *WorkflowGitSyncStatusColl) Upsert(args *models.WorkflowGitSyncStatus) error {
+	if args == nil {
+		return errors.New("nil workflow git sync status")
+	}
+
+	query := bson.M{"project_name": args.ProjectName, "file_path": args.FilePath}
+	change := bson.M{"$set": bson.M{
+		"workflow_name":    args.WorkflowName,
+		"status":           args.Status,
+		"error":            args.Error,
+		"commit_id":        args.CommitID,
+		"pull_request_url": args.PullRequestURL,
+		"update_time":      time.Now().Unix(),
+	}}
+
+	_, err := c.UpdateOne(context.TODO(), query, change, options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteStale deletes the status of the files which are no longer in the bound path
+func (c *WorkflowGitSyncStatusColl) DeleteStale(projectName string, filePaths []string) error {
+	query := bson.M{"project_name": projectName, "file_path": bson.M{"$nin": filePaths}}
+	_, err := c.DeleteMany(context.TODO(), query)
+	return err
+}
+
+func (c *WorkflowGitSyncStatusColl) DeleteByProject(projectName string) error {
+	_, err := c.DeleteMany(context.TODO(), bson.M{"project_name": projectName})
+	return err
+}
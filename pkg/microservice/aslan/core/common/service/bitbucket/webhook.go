@@ -0,0 +1,34 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	gitservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	"github.com/koderover/zadig/pkg/tool/git"
+)
+
+func (c *Client) CreateWebHook(owner, repo string) (string, error) {
+	return c.CreateHook(owner, repo, &git.Hook{
+		URL:    config.WebHookURL(),
+		Secret: gitservice.GetHookSecret(),
+	})
+}
+
+func (c *Client) DeleteWebHook(owner, repo, hookID string) error {
+	return c.DeleteHook(owner, repo, hookID)
+}
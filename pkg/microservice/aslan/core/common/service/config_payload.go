@@ -67,6 +67,7 @@ func GetConfigPayload(codeHostID int) *models.ConfigPayload {
 			JenkinsBuildImage: config.JenkinsImage(),
 		},
 		AesKey:           crypto.GetAesKey(),
+		AesKeyID:         crypto.PrimaryAesKeyID(),
 		BuildConcurrency: 5,
 	}
 
@@ -17,10 +17,7 @@ limitations under the License.
 package service
 
 import (
-	"database/sql"
-	"fmt"
-
-	_ "github.com/go-sql-driver/mysql"
+	"context"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -29,6 +26,7 @@ import (
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/pkg/tool/crypto"
+	"github.com/koderover/zadig/pkg/tool/sqlclient"
 )
 
 func ListDBInstances(encryptedKey string, log *zap.SugaredLogger) ([]*commonmodels.DBInstance, error) {
@@ -91,24 +89,27 @@ func ValidateDBInstance(args *commonmodels.DBInstance) error {
 		return errors.New("nil DBInstance")
 	}
 	switch args.Type {
-	case config.DBInstanceTypeMySQL, config.DBInstanceTypeMariaDB:
-		return validateMySQLInstance(args)
+	case config.DBInstanceTypeMySQL, config.DBInstanceTypeMariaDB, config.DBInstanceTypePostgreSQL,
+		config.DBInstanceTypeSQLServer, config.DBInstanceTypeClickHouse:
 	default:
 		return errors.Errorf("invalid db type %s", args.Type)
 	}
-}
-
-func validateMySQLInstance(args *commonmodels.DBInstance) error {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8&parseTime=True&loc=Local", args.Username, args.Password, args.Host, args.Port)
 
-	db, err := sql.Open("mysql", dsn)
+	client, err := sqlclient.NewClient(&sqlclient.Config{
+		Type:     string(args.Type),
+		Host:     args.Host,
+		Port:     args.Port,
+		Username: args.Username,
+		Password: args.Password,
+		Database: args.Database,
+	})
 	if err != nil {
-		return errors.Errorf("connect mysql failed, err: %s", err)
+		return errors.Errorf("connect %s failed, err: %s", args.Type, err)
 	}
-	defer db.Close()
+	defer client.Close()
 
-	if err = db.Ping(); err != nil {
-		return errors.Errorf("ping mysql failed, err: %s", err)
+	if err = client.Ping(context.Background()); err != nil {
+		return errors.Errorf("ping %s failed, err: %s", args.Type, err)
 	}
 	return nil
 }
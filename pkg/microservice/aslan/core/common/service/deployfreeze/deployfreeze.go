@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployfreeze
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+)
+
+// ActiveWindow is a freeze window which is active at the time of the check
+type ActiveWindow struct {
+	Freeze *commonmodels.DeployFreeze
+	Window *commonmodels.DeployFreezeWindow
+	End    time.Time
+}
+
+// Blocking returns true if deploys in the window can not be overridden by approval
+func (w *ActiveWindow) Blocking() bool {
+	return len(w.Window.Approvers) == 0
+}
+
+func (w *ActiveWindow) String() string {
+	reason := ""
+	if w.Window.Reason != "" {
+		reason = fmt.Sprintf(": %s", w.Window.Reason)
+	}
+	return fmt.Sprintf("deploy freeze %s is active until %s%s", w.Freeze.Name, w.End.Format("2006-01-02 15:04:05"), reason)
+}
+
+// ListActiveWindows returns the active windows of the enabled freezes of the project and the system-wide freezes
+func ListActiveWindows(projectName string, now time.Time) ([]*ActiveWindow, error) {
+	freezes, err := commonrepo.NewDeployFreezeColl().List(&commonrepo.DeployFreezeListOption{
+		ProjectName: projectName,
+		WithSystem:  true,
+		EnabledOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deploy freezes of project %s: %s", projectName, err)
+	}
+
+	resp := make([]*ActiveWindow, 0)
+	for _, freeze := range freezes {
+		for _, window := range freeze.Windows {
+			end, active, err := windowActive(window, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid window in deploy freeze %s: %s", freeze.Name, err)
+			}
+			if active {
+				resp = append(resp, &ActiveWindow{Freeze: freeze, Window: window, End: end})
+			}
+		}
+	}
+	return resp, nil
+}
+
+// ValidateDeployFreeze checks the windows of the freeze
+func ValidateDeployFreeze(freeze *commonmodels.DeployFreeze) error {
+	if freeze.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(freeze.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+	for i, window := range freeze.Windows {
+		if err := validateWindow(window); err != nil {
+			return fmt.Errorf("window %d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateWindow(window *commonmodels.DeployFreezeWindow) error {
+	switch window.Type {
+	case config.DeployFreezeWindowTypeCron:
+		if _, err := cron.ParseStandard(window.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression %s: %s", window.Cron, err)
+		}
+		if window.Duration <= 0 {
+			return fmt.Errorf("duration must be greater than 0")
+		}
+	case config.DeployFreezeWindowTypeRange:
+		if window.EndTime <= window.StartTime {
+			return fmt.Errorf("end time must be after start time")
+		}
+	default:
+		return fmt.Errorf("unsupported window type %s", window.Type)
+	}
+
+	for _, approver := range window.Approvers {
+		if approver.Type != "" && approver.Type != "user" {
+			return fmt.Errorf("only users are supported as approvers")
+		}
+		if approver.UserID == "" {
+			return fmt.Errorf("approver user id is required")
+		}
+	}
+	if len(window.Approvers) > 0 && (window.NeededApprovers <= 0 || window.NeededApprovers > len(window.Approvers)) {
+		return fmt.Errorf("needed approvers must be between 1 and %d", len(window.Approvers))
+	}
+	return nil
+}
+
+// windowActive returns whether the window is active at the given time and when the window ends.
+// A cron window is active if its latest start within the last Duration minutes is not after now.
+func windowActive(window *commonmodels.DeployFreezeWindow, now time.Time) (time.Time, bool, error) {
+	switch window.Type {
+	case config.DeployFreezeWindowTypeCron:
+		schedule, err := cron.ParseStandard(window.Cron)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		duration := time.Duration(window.Duration) * time.Minute
+		// Next returns the first activation strictly after the given time
+		start := schedule.Next(now.Add(-duration - time.Second))
+		end := start.Add(duration)
+		return end, !start.After(now) && now.Before(end), nil
+	case config.DeployFreezeWindowTypeRange:
+		start, end := time.Unix(window.StartTime, 0), time.Unix(window.EndTime, 0)
+		return end, !now.Before(start) && now.Before(end), nil
+	default:
+		return time.Time{}, false, fmt.Errorf("unsupported window type %s", window.Type)
+	}
+}
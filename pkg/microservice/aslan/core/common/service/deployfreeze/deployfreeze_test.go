@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployfreeze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestWindowActive(t *testing.T) {
+	// 2023-12-23 is a Saturday
+	now := time.Date(2023, 12, 23, 10, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		window  *commonmodels.DeployFreezeWindow
+		active  bool
+		end     time.Time
+		wantErr bool
+	}{
+		{
+			name:   "weekend freeze",
+			window: &commonmodels.DeployFreezeWindow{Type: config.DeployFreezeWindowTypeCron, Cron: "0 0 * * 6", Duration: 48 * 60},
+			active: true,
+			end:    time.Date(2023, 12, 25, 0, 0, 0, 0, time.Local),
+		},
+		{
+			name:   "nightly freeze not started",
+			window: &commonmodels.DeployFreezeWindow{Type: config.DeployFreezeWindowTypeCron, Cron: "0 22 * * *", Duration: 8 * 60},
+			active: false,
+		},
+		{
+			name:   "window ends now",
+			window: &commonmodels.DeployFreezeWindow{Type: config.DeployFreezeWindowTypeCron, Cron: "30 9 * * *", Duration: 60},
+			active: false,
+		},
+		{
+			name: "holiday range",
+			window: &commonmodels.DeployFreezeWindow{
+				Type:      config.DeployFreezeWindowTypeRange,
+				StartTime: time.Date(2023, 12, 22, 0, 0, 0, 0, time.Local).Unix(),
+				EndTime:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local).Unix(),
+			},
+			active: true,
+			end:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+		},
+		{
+			name: "past range",
+			window: &commonmodels.DeployFreezeWindow{
+				Type:      config.DeployFreezeWindowTypeRange,
+				StartTime: time.Date(2023, 12, 1, 0, 0, 0, 0, time.Local).Unix(),
+				EndTime:   time.Date(2023, 12, 2, 0, 0, 0, 0, time.Local).Unix(),
+			},
+			active: false,
+		},
+		{
+			name:    "invalid cron",
+			window:  &commonmodels.DeployFreezeWindow{Type: config.DeployFreezeWindowTypeCron, Cron: "every day", Duration: 60},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, active, err := windowActive(tt.window, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.active, active)
+			if tt.active {
+				assert.True(t, tt.end.Equal(end))
+			}
+		})
+	}
+}
+
+func TestValidateDeployFreeze(t *testing.T) {
+	freeze := &commonmodels.DeployFreeze{
+		Name: "holiday",
+		Windows: []*commonmodels.DeployFreezeWindow{
+			{
+				Type:            config.DeployFreezeWindowTypeCron,
+				Cron:            "0 0 * * 6",
+				Duration:        60,
+				Approvers:       []*commonmodels.User{{Type: "user", UserID: "u1"}},
+				NeededApprovers: 1,
+			},
+		},
+	}
+	assert.NoError(t, ValidateDeployFreeze(freeze))
+
+	freeze.Windows[0].NeededApprovers = 2
+	assert.Error(t, ValidateDeployFreeze(freeze))
+
+	freeze.Windows[0].NeededApprovers = 1
+	freeze.Windows[0].Approvers[0].Type = "group"
+	assert.Error(t, ValidateDeployFreeze(freeze))
+}
@@ -64,3 +64,12 @@ func (c *Client) GetFileContent(owner, repo, path, branch string) ([]byte, error
 	res, err := fileContent.GetContent()
 	return []byte(res), err
 }
+
+// CreatePullRequestWithFile returns the html url of the created pull request
+func (c *Client) CreatePullRequestWithFile(owner, repo, base, head, path string, content []byte, title, body string) (string, error) {
+	pr, err := c.Client.CreatePullRequestWithFile(context.TODO(), owner, repo, base, head, path, content, title, body)
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHTMLURL(), nil
+}
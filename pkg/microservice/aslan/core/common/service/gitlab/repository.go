@@ -43,3 +43,12 @@ func (c *Client) GetLatestRepositoryCommit(owner, repo, path, branch string) (*g
 	res, err := c.Client.GetLatestRepositoryCommit(owner, repo, path, branch)
 	return git.ToRepositoryCommit(res), err
 }
+
+// CreatePullRequestWithFile returns the web url of the created merge request
+func (c *Client) CreatePullRequestWithFile(owner, repo, base, head, path string, content []byte, title, body string) (string, error) {
+	mr, err := c.Client.CreateMergeRequestWithFile(owner, repo, base, head, path, content, title, body)
+	if err != nil {
+		return "", err
+	}
+	return mr.WebURL, nil
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imnotify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+const (
+	IMNotifyTypeSlack   IMNotifyType = "slack"
+	IMNotifyTypeMSTeams IMNotifyType = "msteams"
+	IMNotifyTypeWebHook IMNotifyType = "webhook"
+
+	// WebHookSignatureHeader is the HMAC-SHA256 signature of the body of the generic webhook, signed with the webhook secret
+	WebHookSignatureHeader = "X-Zadig-Signature-256"
+)
+
+// Message is the channel independent content of a notification, it is rendered to the request body of the
+// webhook by the renderer of the channel.
+type Message struct {
+	// Event is the kind of the notification, e.g. workflow_task, env_analysis and release_plan
+	Event string
+	Title string
+	// Success decides the color of the message
+	Success bool
+	Fields  []*MessageField
+	// Text is the markdown content following the fields
+	Text    string
+	URL     string
+	URLText string
+	// Mentions are the users mentioned in the message, they are only supported by dingding, wechat and lark
+	Mentions *MessageMentions
+	// Data is the raw data of the event, it is only sent by the generic webhook
+	Data interface{}
+}
+
+type MessageMentions struct {
+	// Mobiles are the mobiles of the dingding users
+	Mobiles []string
+	// UserIDs are the IDs of the wechat or lark users
+	UserIDs []string
+	All     bool
+}
+
+type MessageField struct {
+	Name  string
+	Value string
+}
+
+// Renderer renders a message to the request body of the webhook of a channel
+type Renderer interface {
+	Render(msg *Message) (interface{}, error)
+}
+
+var renderers = map[IMNotifyType]Renderer{
+	IMNotifyTypeDingDing: &dingDingRenderer{},
+	IMNotifyTypeWeChat:   &weChatRenderer{},
+	IMNotifyTypeLark:     &larkRenderer{},
+	IMNotifyTypeSlack:    &slackRenderer{},
+	IMNotifyTypeMSTeams:  &msTeamsRenderer{},
+	IMNotifyTypeWebHook:  &webHookRenderer{},
+}
+
+// SendChannelMessage renders the message with the renderer of the channel and sends it to the webhook,
+// the secret is used to sign the generic webhook only. An empty channel is treated as wechat.
+func (w *IMNotifyService) SendChannelMessage(channel IMNotifyType, uri, secret string, msg *Message) error {
+	if channel == "" {
+		channel = IMNotifyTypeWeChat
+	}
+	renderer, ok := renderers[channel]
+	if !ok {
+		return fmt.Errorf("unsupported notification channel %s", channel)
+	}
+	if uri == "" {
+		return fmt.Errorf("webhook of notification channel %s is empty", channel)
+	}
+	message, err := renderer.Render(msg)
+	if err != nil {
+		return fmt.Errorf("failed to render %s message: %s", channel, err)
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if channel == IMNotifyTypeWebHook && secret != "" {
+		headers[WebHookSignatureHeader] = SignWebHookBody(secret, body)
+	}
+	_, err = w.sendRequest(uri, body, headers)
+	return err
+}
+
+// SignWebHookBody returns the signature of the body in the form of sha256=<hex encoded HMAC-SHA256>
+func SignWebHookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *IMNotifyService) sendRequest(uri string, body []byte, headers map[string]string) ([]byte, error) {
+	c := httpclient.New()
+
+	// 使用代理
+	proxies, _ := w.proxyColl.List(&mongodb.ProxyArgs{})
+	if len(proxies) != 0 && proxies[0].EnableApplicationProxy {
+		c.SetProxy(proxies[0].GetProxyURL())
+		log.Infof("send im notify message is using proxy:%s\n", proxies[0].GetProxyURL())
+	}
+
+	res, err := c.Post(uri, httpclient.SetBody(body), httpclient.SetHeaders(headers))
+	if err != nil {
+		return nil, err
+	}
+	return res.Body(), nil
+}
+
+// markdownLines renders the fields and the text of the message to markdown lines
+func (msg *Message) markdownLines(fieldPrefix string) []string {
+	lines := make([]string, 0, len(msg.Fields)+1)
+	for _, field := range msg.Fields {
+		lines = append(lines, fmt.Sprintf("%s**%s**：%s", fieldPrefix, field.Name, field.Value))
+	}
+	if msg.Text != "" {
+		lines = append(lines, msg.Text)
+	}
+	return lines
+}
+
+func mentionsLine(fieldPrefix string, mentions []string) []string {
+	if len(mentions) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s**相关人员**: %s", fieldPrefix, strings.Join(mentions, " "))}
+}
+
+func (msg *Message) icon() string {
+	if msg.Success {
+		return "👍"
+	}
+	return "⚠️"
+}
+
+type dingDingRenderer struct{}
+
+func (r *dingDingRenderer) Render(msg *Message) (interface{}, error) {
+	lines := msg.markdownLines("##### ")
+	at := &DingDingAt{}
+	if msg.Mentions != nil {
+		// the mobiles must be in the content to be mentioned
+		var mentions []string
+		for _, mobile := range msg.Mentions.Mobiles {
+			mentions = append(mentions, "@"+mobile)
+		}
+		lines = append(lines, mentionsLine("##### ", mentions)...)
+		at = &DingDingAt{AtMobiles: msg.Mentions.Mobiles, IsAtAll: msg.Mentions.All}
+	}
+	content := fmt.Sprintf("#### %s %s \n%s \n", msg.icon(), msg.Title, strings.Join(lines, " \n"))
+	if msg.URL != "" {
+		content += fmt.Sprintf("\n---\n\n[%s](%s)", msg.URLText, msg.URL)
+	}
+	return &DingDingMessage{
+		MsgType:  msgType,
+		MarkDown: &DingDingMarkDown{Title: msg.Title, Text: content},
+		At:       at,
+	}, nil
+}
+
+type weChatRenderer struct{}
+
+func (r *weChatRenderer) Render(msg *Message) (interface{}, error) {
+	color := MarkdownColorInfo
+	if !msg.Success {
+		color = MarkdownColorWarning
+	}
+	lines := msg.markdownLines("")
+	if msg.Mentions != nil {
+		var mentions []string
+		for _, userID := range msg.Mentions.UserIDs {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+		}
+		lines = append(lines, mentionsLine("", mentions)...)
+	}
+	content := fmt.Sprintf("#### %s<font color=\"%s\">%s</font> \n%s \n", msg.icon(), color, msg.Title, strings.Join(lines, " \n"))
+	if msg.URL != "" {
+		content += fmt.Sprintf("\n[%s](%s)", msg.URLText, msg.URL)
+	}
+	return &WeChatWorkCard{
+		MsgType:  msgType,
+		Markdown: Markdown{Content: content},
+	}, nil
+}
+
+type larkRenderer struct{}
+
+func (r *larkRenderer) Render(msg *Message) (interface{}, error) {
+	template := feishuHeaderTemplateGreen
+	if !msg.Success {
+		template = feishuHeaderTemplateRed
+	}
+	lc := NewLarkCard()
+	lc.SetConfig(true)
+	lc.SetHeader(template, fmt.Sprintf("%s %s", msg.icon(), msg.Title), feiShuTagText)
+	lines := msg.markdownLines("")
+	if msg.Mentions != nil {
+		var mentions []string
+		for _, userID := range msg.Mentions.UserIDs {
+			mentions = append(mentions, fmt.Sprintf("<at id=%s></at>", userID))
+		}
+		if msg.Mentions.All {
+			mentions = append(mentions, "<at id=all></at>")
+		}
+		lines = append(lines, mentionsLine("", mentions)...)
+	}
+	for idx, line := range lines {
+		lc.AddI18NElementsZhcnFeild(line, idx == 0 || idx == len(msg.Fields))
+	}
+	if msg.URL != "" {
+		lc.AddI18NElementsZhcnAction(msg.URLText, msg.URL)
+	}
+	return &LarkCardReq{
+		MsgType: feishuCardType,
+		Card:    lc,
+	}, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imnotify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() *Message {
+	return &Message{
+		Event:   "workflow_task",
+		Title:   "工作流 build #1 执行失败",
+		Success: false,
+		Fields: []*MessageField{
+			{Name: "执行用户", Value: "admin"},
+		},
+		Text:    "##### **构建**: build    **状态**: 执行失败 \n**代码信息**：main [1234abcd](https://example.com/commit/1234abcd)",
+		URL:     "https://zadig.example.com/detail",
+		URLText: "点击查看更多信息",
+	}
+}
+
+func TestToSlackMarkdown(t *testing.T) {
+	assert.Equal(t,
+		"*构建*: build    *状态*: 执行失败 \n*代码信息*：main <https://example.com/commit/1234abcd|1234abcd>",
+		toSlackMarkdown(testMessage().Text),
+	)
+}
+
+func TestSlackRenderer(t *testing.T) {
+	rendered, err := (&slackRenderer{}).Render(testMessage())
+	require.NoError(t, err)
+
+	msg := rendered.(*SlackMessage)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, slackColorDanger, msg.Attachments[0].Color)
+
+	blocks := msg.Attachments[0].Blocks
+	require.Len(t, blocks, 4)
+	assert.Equal(t, "header", blocks[0].Type)
+	assert.Equal(t, "*执行用户*\nadmin", blocks[1].Fields[0].Text)
+	assert.Equal(t, "actions", blocks[3].Type)
+	assert.Equal(t, "https://zadig.example.com/detail", blocks[3].Elements[0].URL)
+}
+
+func TestSlackRenderer_Truncate(t *testing.T) {
+	msg := testMessage()
+	msg.Text = strings.Repeat("构建", slackMaxTextLength)
+	rendered, err := (&slackRenderer{}).Render(msg)
+	require.NoError(t, err)
+
+	text := rendered.(*SlackMessage).Attachments[0].Blocks[2].Text.Text
+	assert.True(t, utf8.ValidString(text))
+	assert.Equal(t, slackMaxTextLength, utf8.RuneCountInString(text))
+	assert.True(t, strings.HasSuffix(text, "构..."))
+}
+
+func TestSlackRenderer_TruncateHeader(t *testing.T) {
+	msg := testMessage()
+	msg.Title = strings.Repeat("工作流", slackMaxHeaderLength)
+	rendered, err := (&slackRenderer{}).Render(msg)
+	require.NoError(t, err)
+
+	header := rendered.(*SlackMessage).Attachments[0].Blocks[0]
+	assert.Equal(t, "header", header.Type)
+	assert.True(t, utf8.ValidString(header.Text.Text))
+	assert.Equal(t, slackMaxHeaderLength, utf8.RuneCountInString(header.Text.Text))
+	assert.True(t, strings.HasSuffix(header.Text.Text, "..."))
+
+	msg.Title = "build"
+	rendered, err = (&slackRenderer{}).Render(msg)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%s build", msg.icon()), rendered.(*SlackMessage).Attachments[0].Blocks[0].Text.Text)
+}
+
+func TestMentions(t *testing.T) {
+	msg := testMessage()
+	msg.Mentions = &MessageMentions{Mobiles: []string{"13800000000"}, All: true}
+	rendered, err := (&dingDingRenderer{}).Render(msg)
+	require.NoError(t, err)
+	dingDingMsg := rendered.(*DingDingMessage)
+	assert.Equal(t, &DingDingAt{AtMobiles: []string{"13800000000"}, IsAtAll: true}, dingDingMsg.At)
+	assert.Contains(t, dingDingMsg.MarkDown.Text, "##### **相关人员**: @13800000000")
+
+	msg.Mentions = &MessageMentions{UserIDs: []string{"zhangsan", "lisi"}}
+	rendered, err = (&weChatRenderer{}).Render(msg)
+	require.NoError(t, err)
+	assert.Contains(t, rendered.(*WeChatWorkCard).Markdown.Content, "**相关人员**: <@zhangsan> <@lisi>")
+
+	// no mention line without users to mention
+	msg.Mentions = &MessageMentions{}
+	rendered, err = (&weChatRenderer{}).Render(msg)
+	require.NoError(t, err)
+	assert.NotContains(t, rendered.(*WeChatWorkCard).Markdown.Content, "相关人员")
+}
+
+func TestMSTeamsRenderer(t *testing.T) {
+	rendered, err := (&msTeamsRenderer{}).Render(testMessage())
+	require.NoError(t, err)
+
+	msg := rendered.(*MSTeamsMessage)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, msTeamsAdaptiveCardContentType, msg.Attachments[0].ContentType)
+
+	card := msg.Attachments[0].Content
+	require.Len(t, card.Body, 3)
+	assert.Equal(t, "Attention", card.Body[0].Color)
+	assert.Equal(t, []*MSTeamsFact{{Title: "执行用户", Value: "admin"}}, card.Body[1].Facts)
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "https://zadig.example.com/detail", card.Actions[0].URL)
+}
+
+func TestWebHookRenderer(t *testing.T) {
+	msg := testMessage()
+	msg.Data = map[string]string{"workflow_name": "build"}
+	rendered, err := (&webHookRenderer{}).Render(msg)
+	require.NoError(t, err)
+
+	webHookMsg := rendered.(*WebHookMessage)
+	assert.Equal(t, "workflow_task", webHookMsg.Event)
+	assert.False(t, webHookMsg.Success)
+	assert.Equal(t, []*WebHookField{{Name: "执行用户", Value: "admin"}}, webHookMsg.Fields)
+	assert.Equal(t, msg.Data, webHookMsg.Data)
+}
+
+func TestSignWebHookBody(t *testing.T) {
+	body := []byte(`{"event":"workflow_task"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), SignWebHookBody("secret", body))
+	assert.NotEqual(t, SignWebHookBody("secret", body), SignWebHookBody("other", body))
+}
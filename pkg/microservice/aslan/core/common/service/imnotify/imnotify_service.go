@@ -17,9 +17,9 @@ limitations under the License.
 package imnotify
 
 import (
+	"encoding/json"
+
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
-	"github.com/koderover/zadig/pkg/tool/httpclient"
-	"github.com/koderover/zadig/pkg/tool/log"
 )
 
 type IMNotifyType string
@@ -45,19 +45,9 @@ func NewIMNotifyClient() *IMNotifyService {
 }
 
 func (w *IMNotifyService) SendMessageRequest(uri string, message interface{}) ([]byte, error) {
-	c := httpclient.New()
-
-	// 使用代理
-	proxies, _ := w.proxyColl.List(&mongodb.ProxyArgs{})
-	if len(proxies) != 0 && proxies[0].EnableApplicationProxy {
-		c.SetProxy(proxies[0].GetProxyURL())
-		log.Infof("send im notify message is using proxy:%s\n", proxies[0].GetProxyURL())
-	}
-
-	res, err := c.Post(uri, httpclient.SetBody(message))
+	body, err := json.Marshal(message)
 	if err != nil {
 		return nil, err
 	}
-
-	return res.Body(), nil
+	return w.sendRequest(uri, body, map[string]string{"Content-Type": "application/json"})
 }
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imnotify
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	msTeamsAdaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+	msTeamsAdaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	msTeamsAdaptiveCardVersion     = "1.4"
+)
+
+type MSTeamsMessage struct {
+	Type        string               `json:"type"`
+	Attachments []*MSTeamsAttachment `json:"attachments"`
+}
+
+type MSTeamsAttachment struct {
+	ContentType string               `json:"contentType"`
+	Content     *MSTeamsAdaptiveCard `json:"content"`
+}
+
+type MSTeamsAdaptiveCard struct {
+	Schema  string                `json:"$schema"`
+	Type    string                `json:"type"`
+	Version string                `json:"version"`
+	MSTeams map[string]string     `json:"msteams,omitempty"`
+	Body    []*MSTeamsCardElement `json:"body"`
+	Actions []*MSTeamsCardAction  `json:"actions,omitempty"`
+}
+
+type MSTeamsCardElement struct {
+	Type   string         `json:"type"`
+	Text   string         `json:"text,omitempty"`
+	Weight string         `json:"weight,omitempty"`
+	Size   string         `json:"size,omitempty"`
+	Color  string         `json:"color,omitempty"`
+	Wrap   bool           `json:"wrap,omitempty"`
+	Facts  []*MSTeamsFact `json:"facts,omitempty"`
+}
+
+type MSTeamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type MSTeamsCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type msTeamsRenderer struct{}
+
+// Render renders the message to an Adaptive Card which is sent by the incoming webhook or workflow of Teams
+func (r *msTeamsRenderer) Render(msg *Message) (interface{}, error) {
+	color := "Good"
+	if !msg.Success {
+		color = "Attention"
+	}
+	body := []*MSTeamsCardElement{{
+		Type:   "TextBlock",
+		Text:   fmt.Sprintf("%s %s", msg.icon(), msg.Title),
+		Weight: "Bolder",
+		Size:   "Medium",
+		Color:  color,
+		Wrap:   true,
+	}}
+	if len(msg.Fields) > 0 {
+		factSet := &MSTeamsCardElement{Type: "FactSet"}
+		for _, field := range msg.Fields {
+			factSet.Facts = append(factSet.Facts, &MSTeamsFact{Title: field.Name, Value: field.Value})
+		}
+		body = append(body, factSet)
+	}
+	if text := strings.TrimSpace(markdownHeadingRegex.ReplaceAllString(msg.Text, "")); text != "" {
+		// a single line break is ignored by the TextBlock
+		body = append(body, &MSTeamsCardElement{
+			Type: "TextBlock",
+			Text: strings.ReplaceAll(text, "\n", "\n\n"),
+			Wrap: true,
+		})
+	}
+
+	card := &MSTeamsAdaptiveCard{
+		Schema:  msTeamsAdaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: msTeamsAdaptiveCardVersion,
+		MSTeams: map[string]string{"width": "Full"},
+		Body:    body,
+	}
+	if msg.URL != "" {
+		card.Actions = []*MSTeamsCardAction{{
+			Type:  "Action.OpenUrl",
+			Title: msg.URLText,
+			URL:   msg.URL,
+		}}
+	}
+	return &MSTeamsMessage{
+		Type: "message",
+		Attachments: []*MSTeamsAttachment{{
+			ContentType: msTeamsAdaptiveCardContentType,
+			Content:     card,
+		}},
+	}, nil
+}
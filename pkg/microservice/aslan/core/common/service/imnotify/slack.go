@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imnotify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	slackColorGood   = "#2EB67D"
+	slackColorDanger = "#E01E5A"
+	// slack allows at most 10 fields in a section, 3000 characters in a text and 150 characters in a header,
+	// the message is rejected as a whole if any block exceeds them
+	slackMaxSectionFields = 10
+	slackMaxTextLength    = 3000
+	slackMaxHeaderLength  = 150
+)
+
+var (
+	markdownBoldRegex    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	markdownLinkRegex    = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+	markdownHeadingRegex = regexp.MustCompile(`(?m)^#+\s*`)
+)
+
+type SlackMessage struct {
+	Text        string             `json:"text"`
+	Attachments []*SlackAttachment `json:"attachments"`
+}
+
+type SlackAttachment struct {
+	Color  string        `json:"color"`
+	Blocks []*SlackBlock `json:"blocks"`
+}
+
+type SlackBlock struct {
+	Type     string          `json:"type"`
+	Text     *SlackText      `json:"text,omitempty"`
+	Fields   []*SlackText    `json:"fields,omitempty"`
+	Elements []*SlackElement `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackElement struct {
+	Type  string     `json:"type"`
+	Text  *SlackText `json:"text"`
+	URL   string     `json:"url"`
+	Style string     `json:"style,omitempty"`
+}
+
+type slackRenderer struct{}
+
+// Render renders the message to blocks of Slack Block Kit in an attachment, so that it is colored by the status
+func (r *slackRenderer) Render(msg *Message) (interface{}, error) {
+	title := fmt.Sprintf("%s %s", msg.icon(), msg.Title)
+	blocks := []*SlackBlock{{
+		Type: "header",
+		Text: &SlackText{Type: "plain_text", Text: truncateSlackText(title, slackMaxHeaderLength)},
+	}}
+
+	var fields []*SlackText
+	for _, field := range msg.Fields {
+		fields = append(fields, &SlackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", field.Name, toSlackMarkdown(field.Value))})
+		if len(fields) == slackMaxSectionFields {
+			blocks = append(blocks, &SlackBlock{Type: "section", Fields: fields})
+			fields = nil
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, &SlackBlock{Type: "section", Fields: fields})
+	}
+	if text := strings.TrimSpace(toSlackMarkdown(msg.Text)); text != "" {
+		blocks = append(blocks, &SlackBlock{
+			Type: "section",
+			Text: &SlackText{Type: "mrkdwn", Text: truncateSlackText(text, slackMaxTextLength)},
+		})
+	}
+	if msg.URL != "" {
+		blocks = append(blocks, &SlackBlock{
+			Type: "actions",
+			Elements: []*SlackElement{{
+				Type:  "button",
+				Text:  &SlackText{Type: "plain_text", Text: msg.URLText},
+				URL:   msg.URL,
+				Style: "primary",
+			}},
+		})
+	}
+
+	color := slackColorGood
+	if !msg.Success {
+		color = slackColorDanger
+	}
+	return &SlackMessage{
+		Text:        title,
+		Attachments: []*SlackAttachment{{Color: color, Blocks: blocks}},
+	}, nil
+}
+
+// truncateSlackText truncates the text to at most max characters, the limits of slack are in characters,
+// so the text is truncated on the rune boundary and is still valid utf-8
+func truncateSlackText(text string, max int) string {
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max-3]) + "..."
+	}
+	return text
+}
+
+// toSlackMarkdown converts the markdown used by the notifications to the mrkdwn format of slack
+func toSlackMarkdown(text string) string {
+	text = markdownHeadingRegex.ReplaceAllString(text, "")
+	text = markdownBoldRegex.ReplaceAllString(text, "*$1*")
+	return markdownLinkRegex.ReplaceAllString(text, "<$2|$1>")
+}
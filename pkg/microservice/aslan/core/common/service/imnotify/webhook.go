@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imnotify
+
+import (
+	"time"
+)
+
+// WebHookMessage is the body of the generic webhook, it is signed in the X-Zadig-Signature-256 header if a secret is set
+type WebHookMessage struct {
+	Event     string          `json:"event"`
+	Title     string          `json:"title"`
+	Success   bool            `json:"success"`
+	Fields    []*WebHookField `json:"fields"`
+	Text      string          `json:"text,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+	Data      interface{}     `json:"data,omitempty"`
+}
+
+type WebHookField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type webHookRenderer struct{}
+
+func (r *webHookRenderer) Render(msg *Message) (interface{}, error) {
+	fields := make([]*WebHookField, 0, len(msg.Fields))
+	for _, field := range msg.Fields {
+		fields = append(fields, &WebHookField{Name: field.Name, Value: field.Value})
+	}
+	return &WebHookMessage{
+		Event:     msg.Event,
+		Title:     msg.Title,
+		Success:   msg.Success,
+		Fields:    fields,
+		Text:      msg.Text,
+		URL:       msg.URL,
+		Timestamp: time.Now().Unix(),
+		Data:      msg.Data,
+	}, nil
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models/task"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/base"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/imnotify"
 	"github.com/koderover/zadig/pkg/setting"
 	"github.com/koderover/zadig/pkg/tool/httpclient"
 	"github.com/koderover/zadig/pkg/tool/log"
@@ -751,6 +752,21 @@ func genTestCaseText(test string, subTask, testReports map[string]interface{}) s
 	return test
 }
 
+// getNotifyMentions returns the users to mention in the channel of the notify
+func getNotifyMentions(notify *models.NotifyCtl) *imnotify.MessageMentions {
+	isUser := func(s string, _ int) bool { return s != "All" }
+	switch notify.WebHookType {
+	case dingDingType:
+		return &imnotify.MessageMentions{Mobiles: lo.Filter(notify.AtMobiles, isUser), All: notify.IsAtAll}
+	case feiShuType:
+		return &imnotify.MessageMentions{UserIDs: lo.Filter(notify.LarkUserIDs, isUser), All: notify.IsAtAll}
+	case weChatWorkType, "":
+		return &imnotify.MessageMentions{UserIDs: lo.Filter(notify.WechatUserIDs, isUser), All: notify.IsAtAll}
+	default:
+		return nil
+	}
+}
+
 func getNotifyAtContent(notify *models.NotifyCtl) string {
 	resp := ""
 	if notify.WebHookType == dingDingType {
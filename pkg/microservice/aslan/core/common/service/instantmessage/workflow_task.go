@@ -32,6 +32,7 @@ import (
 	configbase "github.com/koderover/zadig/pkg/config"
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/imnotify"
 	"github.com/koderover/zadig/pkg/tool/log"
 	"github.com/koderover/zadig/pkg/types"
 	"github.com/koderover/zadig/pkg/types/step"
@@ -58,13 +59,7 @@ func (w *Service) SendWorkflowTaskAproveNotifications(workflowName string, taskI
 		if !notify.Enabled {
 			continue
 		}
-		title, content, larkCard, err := w.getApproveNotificationContent(notify, task)
-		if err != nil {
-			errMsg := fmt.Sprintf("failed to get notification content, err: %s", err)
-			log.Error(errMsg)
-			return errors.New(errMsg)
-		}
-		if err := w.sendNotification(title, content, notify, larkCard); err != nil {
+		if err := w.sendChannelNotification(notify, task, true); err != nil {
 			log.Errorf("failed to send notification, err: %s", err)
 		}
 	}
@@ -103,20 +98,27 @@ func (w *Service) SendWorkflowTaskNotifications(task *models.WorkflowTask) error
 		}
 		statusSets := sets.NewString(notify.NotifyTypes...)
 		if statusSets.Has(string(task.Status)) || (statusChanged && statusSets.Has(string(config.StatusChanged))) {
-			title, content, larkCard, err := w.getNotificationContent(notify, task)
-			if err != nil {
-				errMsg := fmt.Sprintf("failed to get notification content, err: %s", err)
-				log.Error(errMsg)
-				return errors.New(errMsg)
-			}
-			if err := w.sendNotification(title, content, notify, larkCard); err != nil {
+			if err := w.sendChannelNotification(notify, task, false); err != nil {
 				log.Errorf("failed to send notification, err: %s", err)
 			}
 		}
 	}
 	return nil
 }
-func (w *Service) getApproveNotificationContent(notify *models.NotifyCtl, task *models.WorkflowTask) (string, string, *LarkCard, error) {
+
+type workflowTaskEventData struct {
+	ProjectName         string        `json:"project_name"`
+	WorkflowName        string        `json:"workflow_name"`
+	WorkflowDisplayName string        `json:"workflow_display_name"`
+	TaskID              int64         `json:"task_id"`
+	Status              config.Status `json:"status"`
+	TaskCreator         string        `json:"task_creator"`
+	StartTime           int64         `json:"start_time"`
+	EndTime             int64         `json:"end_time"`
+}
+
+// sendChannelNotification sends the notification of the task by the renderer of the channel
+func (w *Service) sendChannelNotification(notify *models.NotifyCtl, task *models.WorkflowTask, waitingApprove bool) error {
 	workflowNotification := &workflowTaskNotification{
 		Task:               task,
 		EncodedDisplayName: url.PathEscape(task.WorkflowDisplayName),
@@ -125,57 +127,55 @@ func (w *Service) getApproveNotificationContent(notify *models.NotifyCtl, task *
 		TotalTime:          time.Now().Unix() - task.StartTime,
 	}
 
-	tplTitle := "{{if ne .WebHookType \"feishu\"}}#### {{end}}{{getIcon .Task.Status }}{{if eq .WebHookType \"wechat\"}}<font color=\"markdownColorInfo\">工作流{{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} 等待审批</font>{{else}}工作流 {{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} 等待审批{{end}} \n"
-	tplBaseInfo := []string{"{{if eq .WebHookType \"dingding\"}}##### {{end}}**执行用户**：{{.Task.TaskCreator}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**项目名称**：{{.Task.ProjectName}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**开始时间**：{{ getStartTime .Task.StartTime}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**持续时间**：{{ getDuration .TotalTime}} \n",
+	tplTitle := "工作流 {{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} {{ taskStatus .Task.Status }}"
+	if waitingApprove {
+		tplTitle = "工作流 {{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} 等待审批"
 	}
 	title, err := getWorkflowTaskTplExec(tplTitle, workflowNotification)
 	if err != nil {
-		return "", "", nil, err
+		return err
+	}
+	detailURL, err := getWorkflowTaskTplExec("{{.BaseURI}}/v1/projects/detail/{{.Task.ProjectName}}/pipelines/custom/{{.Task.WorkflowName}}/{{.Task.TaskID}}?display_name={{.EncodedDisplayName}}", workflowNotification)
+	if err != nil {
+		return err
 	}
-	buttonContent := "点击查看更多信息"
-	workflowDetailURL := "{{.BaseURI}}/v1/projects/detail/{{.Task.ProjectName}}/pipelines/custom/{{.Task.WorkflowName}}/{{.Task.TaskID}}?display_name={{.EncodedDisplayName}}"
-	moreInformation := fmt.Sprintf("[%s](%s)", buttonContent, workflowDetailURL)
-	if notify.WebHookType != feiShuType {
-		tplcontent := strings.Join(tplBaseInfo, "")
-		tplcontent = tplcontent + getNotifyAtContent(notify)
-		tplcontent = fmt.Sprintf("%s%s%s", title, tplcontent, moreInformation)
-		content, err := getWorkflowTaskTplExec(tplcontent, workflowNotification)
+
+	msg := &imnotify.Message{
+		Event:   "workflow_task",
+		Title:   title,
+		Success: waitingApprove || task.Status == config.StatusPassed || task.Status == config.StatusCreated,
+		Fields: []*imnotify.MessageField{
+			{Name: "执行用户", Value: task.TaskCreator},
+			{Name: "项目名称", Value: task.ProjectName},
+			{Name: "开始时间", Value: time.Unix(task.StartTime, 0).Format("2006-01-02 15:04:05")},
+			{Name: "持续时间", Value: (time.Duration(workflowNotification.TotalTime) * time.Second).String()},
+		},
+		URL:      detailURL,
+		URLText:  "点击查看更多信息",
+		Mentions: getNotifyMentions(notify),
+		Data: &workflowTaskEventData{
+			ProjectName:         task.ProjectName,
+			WorkflowName:        task.WorkflowName,
+			WorkflowDisplayName: task.WorkflowDisplayName,
+			TaskID:              task.TaskID,
+			Status:              task.Status,
+			TaskCreator:         task.TaskCreator,
+			StartTime:           task.StartTime,
+			EndTime:             task.EndTime,
+		},
+	}
+	if !waitingApprove {
+		jobContents, err := getJobTaskContents(notify, task)
 		if err != nil {
-			return "", "", nil, err
+			return err
 		}
-		return title, content, nil, nil
+		msg.Text = strings.TrimSpace(strings.Join(jobContents, ""))
 	}
 
-	lc := NewLarkCard()
-	lc.SetConfig(true)
-	lc.SetHeader(feishuHeaderTemplateGreen, title, feiShuTagText)
-	for idx, feildContent := range tplBaseInfo {
-		feildExecContent, _ := getWorkflowTaskTplExec(feildContent, workflowNotification)
-		lc.AddI18NElementsZhcnFeild(feildExecContent, idx == 0)
-	}
-	workflowDetailURL, _ = getWorkflowTaskTplExec(workflowDetailURL, workflowNotification)
-	lc.AddI18NElementsZhcnAction(buttonContent, workflowDetailURL)
-	return "", "", lc, nil
+	return imnotify.NewIMNotifyClient().SendChannelMessage(imnotify.IMNotifyType(notify.WebHookType), notify.GetWebHook(), notify.WebHookSecret, msg)
 }
 
-func (w *Service) getNotificationContent(notify *models.NotifyCtl, task *models.WorkflowTask) (string, string, *LarkCard, error) {
-	workflowNotification := &workflowTaskNotification{
-		Task:               task,
-		EncodedDisplayName: url.PathEscape(task.WorkflowDisplayName),
-		BaseURI:            configbase.SystemAddress(),
-		WebHookType:        notify.WebHookType,
-		TotalTime:          time.Now().Unix() - task.StartTime,
-	}
-
-	tplTitle := "{{if ne .WebHookType \"feishu\"}}#### {{end}}{{getIcon .Task.Status }}{{if eq .WebHookType \"wechat\"}}<font color=\"{{ getColor .Task.Status }}\">工作流{{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} {{ taskStatus .Task.Status }}</font>{{else}}工作流 {{.Task.WorkflowDisplayName}} #{{.Task.TaskID}} {{ taskStatus .Task.Status }}{{end}} \n"
-	tplBaseInfo := []string{"{{if eq .WebHookType \"dingding\"}}##### {{end}}**执行用户**：{{.Task.TaskCreator}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**项目名称**：{{.Task.ProjectName}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**开始时间**：{{ getStartTime .Task.StartTime}} \n",
-		"{{if eq .WebHookType \"dingding\"}}##### {{end}}**持续时间**：{{ getDuration .TotalTime}} \n",
-	}
+func getJobTaskContents(notify *models.NotifyCtl, task *models.WorkflowTask) ([]string, error) {
 	jobContents := []string{}
 	for _, stage := range task.Stages {
 		for _, job := range stage.Jobs {
@@ -284,44 +284,12 @@ func (w *Service) getNotificationContent(notify *models.NotifyCtl, task *models.
 
 			jobContent, err := getJobTaskTplExec(jobTplcontent, jobNotifaication)
 			if err != nil {
-				return "", "", nil, err
+				return nil, err
 			}
 			jobContents = append(jobContents, jobContent)
 		}
 	}
-	title, err := getWorkflowTaskTplExec(tplTitle, workflowNotification)
-	if err != nil {
-		return "", "", nil, err
-	}
-	buttonContent := "点击查看更多信息"
-	workflowDetailURL := "{{.BaseURI}}/v1/projects/detail/{{.Task.ProjectName}}/pipelines/custom/{{.Task.WorkflowName}}/{{.Task.TaskID}}?display_name={{.EncodedDisplayName}}"
-	moreInformation := fmt.Sprintf("\n\n{{if eq .WebHookType \"dingding\"}}---\n\n{{end}}[%s](%s)", buttonContent, workflowDetailURL)
-	if notify.WebHookType != feiShuType {
-		tplcontent := strings.Join(tplBaseInfo, "")
-		tplcontent += strings.Join(jobContents, "")
-		tplcontent = tplcontent + getNotifyAtContent(notify)
-		tplcontent = fmt.Sprintf("%s%s%s", title, tplcontent, moreInformation)
-		content, err := getWorkflowTaskTplExec(tplcontent, workflowNotification)
-		if err != nil {
-			return "", "", nil, err
-		}
-		return title, content, nil, nil
-	}
-
-	lc := NewLarkCard()
-	lc.SetConfig(true)
-	lc.SetHeader(getColorTemplateWithStatus(task.Status), title, feiShuTagText)
-	for idx, feildContent := range tplBaseInfo {
-		feildExecContent, _ := getWorkflowTaskTplExec(feildContent, workflowNotification)
-		lc.AddI18NElementsZhcnFeild(feildExecContent, idx == 0)
-	}
-	for _, feildContent := range jobContents {
-		feildExecContent, _ := getWorkflowTaskTplExec(feildContent, workflowNotification)
-		lc.AddI18NElementsZhcnFeild(feildExecContent, true)
-	}
-	workflowDetailURL, _ = getWorkflowTaskTplExec(workflowDetailURL, workflowNotification)
-	lc.AddI18NElementsZhcnAction(buttonContent, workflowDetailURL)
-	return "", "", lc, nil
+	return jobContents, nil
 }
 
 type workflowTaskNotification struct {
@@ -467,24 +435,3 @@ func getJobTaskTplExec(tplcontent string, args *jobTaskNotification) (string, er
 	}
 	return buffer.String(), nil
 }
-
-func (w *Service) sendNotification(title, content string, notify *models.NotifyCtl, card *LarkCard) error {
-	switch notify.WebHookType {
-	case dingDingType:
-		if err := w.sendDingDingMessage(notify.DingDingWebHook, title, content, notify.AtMobiles, notify.IsAtAll); err != nil {
-			return err
-		}
-	case feiShuType:
-		if err := w.sendFeishuMessage(notify.FeiShuWebHook, card); err != nil {
-			return err
-		}
-		if err := w.sendFeishuMessageOfSingleType("", notify.FeiShuWebHook, getNotifyAtContent(notify)); err != nil {
-			return err
-		}
-	default:
-		if err := w.SendWeChatWorkMessage(weChatTextTypeMarkdown, notify.WeChatWebHook, content); err != nil {
-			return err
-		}
-	}
-	return nil
-}
@@ -47,6 +47,7 @@ import (
 	"github.com/koderover/zadig/pkg/tool/kube/getter"
 	"github.com/koderover/zadig/pkg/tool/kube/serializer"
 	"github.com/koderover/zadig/pkg/tool/kube/updater"
+	"github.com/koderover/zadig/pkg/tool/secret"
 )
 
 type SharedEnvHandler func(context.Context, *commonmodels.Product, string, client.Client, versionedclient.Interface) error
@@ -259,6 +260,12 @@ func CreateOrPatchResource(applyParam *ResourceApplyParam, log *zap.SugaredLogge
 		return nil, nil
 	}
 
+	if secret.ContainsReference(applyParam.UpdateResourceYaml) {
+		if err = resolveSecretReferences(productName, clientSet, resources); err != nil {
+			return nil, err
+		}
+	}
+
 	err = removeResources(curResources, resources, namespace, applyParam.KubeClient, versionInfo, log)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to remove old resources")
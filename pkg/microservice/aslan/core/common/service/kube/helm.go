@@ -96,6 +96,11 @@ func InstallOrUpgradeHelmChartWithValues(param *ReleaseInstallParam, isRetry boo
 		return err
 	}
 
+	valuesYaml, err = resolveSecretValues(param.ProductName, helmClient.RestConfig, valuesYaml)
+	if err != nil {
+		return err
+	}
+
 	chartSpec := &helmclient.ChartSpec{
 		ReleaseName:   param.ReleaseName,
 		ChartName:     chartPath,
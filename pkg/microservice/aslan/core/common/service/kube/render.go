@@ -353,7 +353,7 @@ func FetchCurrentAppliedYaml(option *GeneSvcYamlOption) (string, int, error) {
 		return "", 0, errors.Wrapf(err, "failed to find service %s with revision %d", option.ServiceName, curProductSvc.Revision)
 	}
 
-	fullRenderedYaml, err := RenderServiceYaml(prodSvcTemplate.Yaml, option.ProductName, option.ServiceName, curProductSvc.GetServiceRender())
+	fullRenderedYaml, err := RenderServiceTemplate(prodSvcTemplate, productInfo.EnvName, productInfo.Production, curProductSvc.GetServiceRender())
 	if err != nil {
 		return "", 0, err
 	}
@@ -364,7 +364,7 @@ func FetchCurrentAppliedYaml(option *GeneSvcYamlOption) (string, int, error) {
 }
 
 func fetchImportedManifests(option *GeneSvcYamlOption, productInfo *models.Product, serviceTmp *models.Service, svcRender *template.ServiceRender) (string, []*WorkloadResource, error) {
-	fullRenderedYaml, err := RenderServiceYaml(serviceTmp.Yaml, option.ProductName, option.ServiceName, svcRender)
+	fullRenderedYaml, err := RenderServiceTemplate(serviceTmp, productInfo.EnvName, productInfo.Production, svcRender)
 	if err != nil {
 		return "", nil, err
 	}
@@ -524,7 +524,7 @@ func GenerateRenderedYaml(option *GeneSvcYamlOption) (string, int, []*WorkloadRe
 
 	serviceRender.OverrideYaml.YamlContent = mergedYaml
 
-	fullRenderedYaml, err := RenderServiceYaml(latestSvcTemplate.Yaml, option.ProductName, option.ServiceName, serviceRender)
+	fullRenderedYaml, err := RenderServiceTemplate(latestSvcTemplate, productInfo.EnvName, productInfo.Production, serviceRender)
 	if err != nil {
 		return "", 0, nil, err
 	}
@@ -549,6 +549,15 @@ func RenderServiceYaml(originYaml, productName, serviceName string, svcRender *t
 	return commonutil.RenderK8sSvcYamlStrict(originYaml, productName, serviceName, variableYaml)
 }
 
+// RenderServiceTemplate renders the service template in the environment, kustomize services are built
+// with the kustomization of the environment, and variables are not rendered for them
+func RenderServiceTemplate(svcTmpl *commonmodels.Service, envName string, production bool, svcRender *template.ServiceRender) (string, error) {
+	if commonutil.IsKustomizeService(svcTmpl) {
+		return commonutil.BuildKustomizeService(svcTmpl, envName, production)
+	}
+	return RenderServiceYaml(svcTmpl.Yaml, svcTmpl.ProductName, svcTmpl.ServiceName, svcRender)
+}
+
 // RenderEnvService renders service with particular revision and service vars in environment
 func RenderEnvService(prod *commonmodels.Product, serviceRender *template.ServiceRender, service *commonmodels.ProductService) (yaml string, err error) {
 	opt := &commonrepo.ServiceFindOption{
@@ -567,7 +576,7 @@ func RenderEnvService(prod *commonmodels.Product, serviceRender *template.Servic
 
 func RenderEnvServiceWithTempl(prod *commonmodels.Product, serviceRender *template.ServiceRender, service *commonmodels.ProductService, svcTmpl *commonmodels.Service) (yaml string, err error) {
 	// Note only the keys in TemplateService.ServiceVar can work
-	parsedYaml, err := RenderServiceYaml(svcTmpl.Yaml, prod.ProductName, svcTmpl.ServiceName, serviceRender)
+	parsedYaml, err := RenderServiceTemplate(svcTmpl, prod.EnvName, prod.Production, serviceRender)
 	if err != nil {
 		log.Errorf("failed to render service yaml, err: %s", err)
 		return "", err
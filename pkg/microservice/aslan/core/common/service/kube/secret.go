@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/tool/secret"
+)
+
+// SecretProviderConfig returns the providers and the allowlist of the project, the references out of
+// the allowlist of the project are rejected
+func SecretProviderConfig(projectName string) (*secret.Config, error) {
+	allowlists := make(map[string]secret.Allowlist)
+	if config.SecretAllowlist() != "" {
+		if err := json.Unmarshal([]byte(config.SecretAllowlist()), &allowlists); err != nil {
+			return nil, fmt.Errorf("invalid secret allowlist: %s", err)
+		}
+	}
+	return &secret.Config{
+		VaultAddr:         config.SecretVaultAddr(),
+		VaultK8sRole:      config.SecretVaultK8sRole(),
+		VaultK8sAuthPath:  config.SecretVaultK8sAuthPath(),
+		FileRoot:          config.SecretFileRoot(),
+		KubernetesEnabled: config.SecretKubernetesEnabled(),
+		Allowlist:         allowlists[projectName],
+	}, nil
+}
+
+// newSecretResolver returns the resolver of the secret references deployed to an env of the project, e.g. the
+// references in the variables from a variable set. The kubernetes provider reads the cluster of the env.
+func newSecretResolver(projectName string, clientset kubernetes.Interface) (*secret.Resolver, error) {
+	cfg, err := SecretProviderConfig(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewResolver(config.SecretVaultToken(), clientset), nil
+}
+
+// resolveSecretReferences replaces the secret references in the resources with the secrets right before they
+// are applied, the env and the workflow task keep the references.
+func resolveSecretReferences(projectName string, clientset kubernetes.Interface, resources []*unstructured.Unstructured) error {
+	resolver, err := newSecretResolver(projectName, clientset)
+	if err != nil {
+		return err
+	}
+	for _, u := range resources {
+		if _, err := resolver.ResolveObject(context.TODO(), u.Object); err != nil {
+			return fmt.Errorf("failed to resolve the secret references of %s/%s: %s", u.GetKind(), u.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// resolveSecretValues replaces the secret references in the values of a helm release with the secrets right before
+// the release is installed or upgraded, the env and the workflow task keep the references.
+func resolveSecretValues(projectName string, restConfig *rest.Config, valuesYaml string) (string, error) {
+	if !secret.ContainsReference(valuesYaml) {
+		return valuesYaml, nil
+	}
+	var clientset kubernetes.Interface
+	if restConfig != nil {
+		var err error
+		if clientset, err = kubernetes.NewForConfig(restConfig); err != nil {
+			return "", err
+		}
+	}
+	resolver, err := newSecretResolver(projectName, clientset)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolver.ResolveYaml(context.TODO(), valuesYaml)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the secret references of the values: %s", err)
+	}
+	return resolved, nil
+}
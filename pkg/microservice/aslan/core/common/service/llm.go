@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/sashabaranov/go-openai"
+
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/pkg/tool/llm"
 )
@@ -15,10 +18,42 @@ func GetLLMClient(ctx context.Context, name string) (llm.ILLM, error) {
 		return nil, fmt.Errorf("Could find the llm integration for %s: %w", name, err)
 	}
 
+	return newLLMClient(llmIntegration)
+}
+
+// GetDefaultLLMClient returns the client of the llm integration, there is at most one integration in the system
+func GetDefaultLLMClient(ctx context.Context) (llm.ILLM, error) {
+	llmIntegrations, err := commonrepo.NewLLMIntegrationColl().FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Could not list the llm integrations: %w", err)
+	}
+	if len(llmIntegrations) == 0 {
+		return nil, fmt.Errorf("No llm integration found")
+	}
+
+	return newLLMClient(llmIntegrations[0])
+}
+
+func newLLMClient(llmIntegration *models.LLMIntegration) (llm.ILLM, error) {
+	name := llmIntegration.Name
 	llmConfig := llm.LLMConfig{
-		Name:    llmIntegration.Name,
-		Token:   llmIntegration.Token,
-		BaseURL: llmIntegration.BaseURL,
+		Name:       llmIntegration.Name,
+		Token:      llmIntegration.Token,
+		BaseURL:    llmIntegration.BaseURL,
+		Model:      llmIntegration.Model,
+		TokenLimit: llmIntegration.TokenLimit,
+	}
+	switch name {
+	case llm.ProviderOpenAI:
+		llmConfig.APIType = "OPEN_AI"
+	case llm.ProviderAzureOpenAI:
+		llmConfig.APIType = "AZURE"
+	case llm.ProviderOpenAICompatible:
+		llmConfig.APIType = "OPEN_AI_COMPATIBLE"
+	}
+	// the ai features were built on gpt-3.5-turbo-16k, keep it as the default model of OpenAI
+	if llmConfig.Model == "" && (name == llm.ProviderOpenAI || name == llm.ProviderAzureOpenAI) {
+		llmConfig.Model = openai.GPT3Dot5Turbo16K
 	}
 	if llmIntegration.EnableProxy {
 		llmConfig.Proxy = config.ProxyHTTPSAddr()
@@ -36,7 +71,3 @@ func GetLLMClient(ctx context.Context, name string) (llm.ILLM, error) {
 
 	return llmClient, nil
 }
-
-func GetDefaultLLMClient(ctx context.Context) (llm.ILLM, error) {
-	return GetLLMClient(ctx, "openai")
-}
@@ -30,11 +30,15 @@ import (
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/bitbucket"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitea"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitee"
 	"github.com/koderover/zadig/pkg/setting"
 	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	bitbuckettool "github.com/koderover/zadig/pkg/tool/bitbucket"
 	"github.com/koderover/zadig/pkg/tool/gerrit"
 	gitlabtool "github.com/koderover/zadig/pkg/tool/git/gitlab"
+	giteatool "github.com/koderover/zadig/pkg/tool/gitea"
 	"github.com/koderover/zadig/pkg/tool/log"
 )
 
@@ -187,6 +191,54 @@ func (c *Client) Comment(notify *models.Notification) error {
 		if err != nil {
 			return fmt.Errorf("failed to comment gitee due to %s/%d %v", notify.ProjectID, notify.PrID, err)
 		}
+	} else if strings.ToLower(codeHostDetail.Type) == setting.SourceFromGitea {
+		cli, err := gitea.NewClient(codeHostDetail.ID, codeHostDetail.Address, codeHostDetail.AccessToken, config.ProxyHTTPSAddr(), codeHostDetail.EnableProxy)
+		if err != nil {
+			return fmt.Errorf("create gitea client failed err: %v", err)
+		}
+		if notify.CommentID == "" {
+			// create comment
+			var note *giteatool.Comment
+			note, err = cli.CreateIssueComment(notify.RepoOwner, notify.RepoName, notify.PrID, comment)
+			if err == nil {
+				notify.CommentID = strconv.FormatInt(note.ID, 10)
+			}
+		} else {
+			// update comment
+			commentID, convErr := strconv.ParseInt(notify.CommentID, 10, 64)
+			if convErr != nil {
+				return fmt.Errorf("failed to parse commentID %v,err: %s", notify.CommentID, convErr)
+			}
+			err = cli.EditIssueComment(notify.RepoOwner, notify.RepoName, commentID, comment)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to comment gitea due to %s/%d %v", notify.ProjectID, notify.PrID, err)
+		}
+	} else if strings.ToLower(codeHostDetail.Type) == setting.SourceFromBitbucketServer {
+		cli, err := bitbucket.NewClient(codeHostDetail.ID, codeHostDetail.Address, codeHostDetail.AccessToken, config.ProxyHTTPSAddr(), codeHostDetail.EnableProxy)
+		if err != nil {
+			return fmt.Errorf("create bitbucket client failed err: %v", err)
+		}
+		if notify.CommentID == "" {
+			// create comment
+			var note *bitbuckettool.Comment
+			note, err = cli.CreatePullRequestComment(notify.RepoOwner, notify.RepoName, notify.PrID, comment)
+			if err == nil {
+				notify.CommentID = strconv.Itoa(note.ID)
+			}
+		} else {
+			// update comment
+			commentID, convErr := strconv.Atoi(notify.CommentID)
+			if convErr != nil {
+				return fmt.Errorf("failed to atoi commentID %v,err: %s", notify.CommentID, convErr)
+			}
+			err = cli.UpdatePullRequestComment(notify.RepoOwner, notify.RepoName, notify.PrID, commentID, comment)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to comment bitbucket due to %s/%d %v", notify.ProjectID, notify.PrID, err)
+		}
 	} else {
 		return fmt.Errorf("non gitlab source not supported to comment")
 	}
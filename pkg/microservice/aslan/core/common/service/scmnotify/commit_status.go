@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scmnotify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+
+	configbase "github.com/koderover/zadig/pkg/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	"github.com/koderover/zadig/pkg/tool/bitbucket"
+	"github.com/koderover/zadig/pkg/tool/gitea"
+)
+
+// commitState is the state of the commit status for the code hosts without check runs
+type commitState string
+
+const (
+	commitStatePending commitState = "pending"
+	commitStateSuccess commitState = "success"
+	commitStateFailure commitState = "failure"
+	commitStateError   commitState = "error"
+)
+
+func getCommitStateFromCIStatus(status config.Status) commitState {
+	switch status {
+	case config.StatusCreated, config.StatusRunning:
+		return commitStatePending
+	case config.StatusPassed:
+		return commitStateSuccess
+	case config.StatusFailed, config.StatusTimeout, config.StatusReject:
+		return commitStateFailure
+	default:
+		return commitStateError
+	}
+}
+
+// setCommitStatusForWorkflowV4 sets the commit status of the pr for gitea and bitbucket server,
+// it returns false if the code host of the hook uses other ways to report the status.
+// It also returns false if the code host of the hook is not found, so the github and gitlab
+// hooks still report the status in their own ways.
+func setCommitStatusForWorkflowV4(workflowArgs *models.WorkflowV4, taskID int64, state commitState, description string, log *zap.SugaredLogger) (bool, error) {
+	hook := workflowArgs.HookPayload
+	ch, err := systemconfig.New().GetCodeHost(hook.CodehostID)
+	if err != nil {
+		log.Errorf("Failed to get codeHost %d, err:%v", hook.CodehostID, err)
+		return false, nil
+	}
+
+	targetURL := fmt.Sprintf("%s/v1/projects/detail/%s/pipelines/custom/%s/%d?display_name=%s",
+		configbase.SystemAddress(), workflowArgs.Project, workflowArgs.Name, taskID, url.QueryEscape(getDisplayName(workflowArgs)))
+	switch strings.ToLower(ch.Type) {
+	case setting.SourceFromGitea:
+		cli, err := gitea.NewClient(ch.ID, ch.Address, ch.AccessToken, config.ProxyHTTPSAddr(), ch.EnableProxy)
+		if err != nil {
+			return true, err
+		}
+		return true, cli.CreateCommitStatus(hook.Owner, hook.Repo, hook.Ref, &gitea.CommitStatus{
+			State:       gitea.CommitStatusState(state),
+			TargetURL:   targetURL,
+			Description: description,
+			Context:     fmt.Sprintf("zadig/%s", workflowArgs.Name),
+		})
+	case setting.SourceFromBitbucketServer:
+		cli, err := bitbucket.NewClient(ch.ID, ch.Address, ch.AccessToken, config.ProxyHTTPSAddr(), ch.EnableProxy)
+		if err != nil {
+			return true, err
+		}
+		buildState := bitbucket.BuildStateFailed
+		switch state {
+		case commitStatePending:
+			buildState = bitbucket.BuildStateInProgress
+		case commitStateSuccess:
+			buildState = bitbucket.BuildStateSuccessful
+		}
+		return true, cli.CreateBuildStatus(hook.Ref, &bitbucket.BuildStatus{
+			State:       buildState,
+			Key:         fmt.Sprintf("zadig-%s", workflowArgs.Name),
+			Name:        getDisplayName(workflowArgs),
+			URL:         targetURL,
+			Description: description,
+		})
+	default:
+		return false, nil
+	}
+}
@@ -542,6 +542,33 @@ func (s *Service) UpdateWebhookCommentForWorkflowV4(task *models.WorkflowTask, l
 	return nil
 }
 
+// CommentForWorkflowV4 posts a comment to the pull request which triggered the workflow task,
+// it is separated from the comment of the task status.
+func (s *Service) CommentForWorkflowV4(task *models.WorkflowTask, body string, logger *zap.SugaredLogger) error {
+	if task.WorkflowArgs == nil || task.WorkflowArgs.NotificationID == "" {
+		return nil
+	}
+
+	notification, err := s.Coll.Find(task.WorkflowArgs.NotificationID)
+	if err != nil {
+		logger.Errorf("can't find notification by id %s %s", task.WorkflowArgs.NotificationID, err)
+		return err
+	}
+	comment := &models.Notification{
+		CodehostID:   notification.CodehostID,
+		PrID:         notification.PrID,
+		ProjectID:    notification.ProjectID,
+		BaseURI:      notification.BaseURI,
+		IsWorkflowV4: true,
+		ErrInfo:      body,
+		Label:        notification.Label,
+		Revision:     notification.Revision,
+		RepoOwner:    notification.RepoOwner,
+		RepoName:     notification.RepoName,
+	}
+	return s.Client.Comment(comment)
+}
+
 func (s *Service) UpdatePipelineWebhookComment(task *task.Task, logger *zap.SugaredLogger) (err error) {
 	if task.TaskArgs == nil {
 		logger.Warnf("taskArgs of %s is nil", task.PipelineName)
@@ -658,6 +685,10 @@ func (s *Service) CreateGitCheckForWorkflowV4(workflowArgs *models.WorkflowV4, t
 		return nil
 	}
 
+	if handled, err := setCommitStatusForWorkflowV4(workflowArgs, taskID, commitStatePending, fmt.Sprintf("Workflow [%s] is queued.", workflowArgs.DisplayName), log); handled {
+		return err
+	}
+
 	ghApp, err := github.GetGithubAppClientByOwner(hook.Owner)
 	if err != nil {
 		log.Errorf("getGithubAppClient failed, err:%v", err)
@@ -717,6 +748,10 @@ func (s *Service) UpdateGitCheckForWorkflowV4(workflowArgs *models.WorkflowV4, t
 		return nil
 	}
 
+	if handled, err := setCommitStatusForWorkflowV4(workflowArgs, taskID, commitStatePending, fmt.Sprintf("Workflow [%s] is running.", workflowArgs.DisplayName), log); handled {
+		return err
+	}
+
 	ghApp, err := github.GetGithubAppClientByOwner(hook.Owner)
 	if err != nil {
 		log.Errorf("getGithubAppClient failed, err:%v", err)
@@ -777,6 +812,10 @@ func (s *Service) CompleteGitCheckForWorkflowV4(workflowArgs *models.WorkflowV4,
 		return nil
 	}
 
+	if handled, err := setCommitStatusForWorkflowV4(workflowArgs, taskID, getCommitStateFromCIStatus(status), fmt.Sprintf("Workflow [%s] is %s.", workflowArgs.DisplayName, status), log); handled {
+		return err
+	}
+
 	ghApp, err := github.GetGithubAppClientByOwner(hook.Owner)
 	if err != nil {
 		log.Errorf("getGithubAppClient failed, err:%v", err)
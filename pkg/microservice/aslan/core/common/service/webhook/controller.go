@@ -27,6 +27,8 @@ import (
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/bitbucket"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitea"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitee"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/github"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitlab"
@@ -166,6 +168,20 @@ func removeWebhook(t *task, logger *zap.Logger) {
 		}
 	case setting.SourceFromGitee, setting.SourceFromGiteeEE:
 		cl = gitee.NewClient(t.ID, t.token, config.ProxyHTTPSAddr(), t.enableProxy, t.address)
+	case setting.SourceFromGitea:
+		cl, err = gitea.NewClient(t.ID, t.address, t.token, config.ProxyHTTPSAddr(), t.enableProxy)
+		if err != nil {
+			t.err = err
+			t.doneCh <- struct{}{}
+			return
+		}
+	case setting.SourceFromBitbucketServer:
+		cl, err = bitbucket.NewClient(t.ID, t.address, t.token, config.ProxyHTTPSAddr(), t.enableProxy)
+		if err != nil {
+			t.err = err
+			t.doneCh <- struct{}{}
+			return
+		}
 	default:
 		t.err = fmt.Errorf("invaild source: %s", t.from)
 		t.doneCh <- struct{}{}
@@ -244,6 +260,20 @@ func addWebhook(t *task, logger *zap.Logger) {
 		}
 	case setting.SourceFromGitee, setting.SourceFromGiteeEE:
 		cl = gitee.NewClient(t.ID, t.token, config.ProxyHTTPSAddr(), t.enableProxy, t.address)
+	case setting.SourceFromGitea:
+		cl, err = gitea.NewClient(t.ID, t.address, t.token, config.ProxyHTTPSAddr(), t.enableProxy)
+		if err != nil {
+			t.err = err
+			t.doneCh <- struct{}{}
+			return
+		}
+	case setting.SourceFromBitbucketServer:
+		cl, err = bitbucket.NewClient(t.ID, t.address, t.token, config.ProxyHTTPSAddr(), t.enableProxy)
+		if err != nil {
+			t.err = err
+			t.doneCh <- struct{}{}
+			return
+		}
 	default:
 		t.err = fmt.Errorf("invaild source: %s", t.from)
 		t.doneCh <- struct{}{}
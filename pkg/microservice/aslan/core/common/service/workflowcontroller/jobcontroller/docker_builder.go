@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+const (
+	BuildKitImage = "moby/buildkit:v0.12.5-rootless"
+	// KanikoImage is the debug image of kaniko since a shell is needed to wait for the build script
+	KanikoImage = "gcr.io/kaniko-project/executor:v1.19.2-debug"
+
+	daemonlessBuilderVolumeName = "zadig-builder"
+	buildkitdStateVolumeName    = "buildkitd-state"
+	workspaceVolumeName         = "zadig-workspace"
+	buildkitdContainerName      = "buildkitd"
+	kanikoContainerName         = "kaniko"
+	// rootless buildkitd runs as the user in the buildkit image
+	buildkitdUserID    = 1000
+	buildkitdStatePath = "/home/user/.local/share/buildkit"
+	// builderDoneFile is written when the job container exits, the builder containers exit then so that the pod completes
+	builderDoneFile = step.DaemonlessBuilderDir + "/done"
+
+	podSecurityEnforceLabel    = "pod-security.kubernetes.io/enforce"
+	podSecurityLevelPrivileged = "privileged"
+)
+
+// getDockerBuilders returns the builders of the docker build steps of the job
+func getDockerBuilders(steps []*commonmodels.StepTask) map[step.DockerBuilder]bool {
+	resp := make(map[step.DockerBuilder]bool)
+	for _, stepTask := range steps {
+		if stepTask.StepType != config.StepDockerBuild {
+			continue
+		}
+		spec := &step.StepDockerBuildSpec{}
+		if err := commonmodels.IToi(stepTask.Spec, spec); err != nil {
+			continue
+		}
+		resp[spec.GetBuilder()] = true
+	}
+	return resp
+}
+
+// needDockerDaemon returns false if all the docker build steps of the job build without the docker daemon,
+// the jobs without any docker build step still get a docker daemon for the docker commands in their scripts.
+func needDockerDaemon(steps []*commonmodels.StepTask) bool {
+	builders := getDockerBuilders(steps)
+	return len(builders) == 0 || builders[step.DockerBuilderDocker]
+}
+
+// checkBuilderPodSecurity returns an error if the job builds with buildkit in a namespace enforcing the baseline or
+// restricted pod security standard. Rootless buildkitd needs the unconfined seccomp and apparmor profiles, which only
+// the privileged level allows, while kaniko complies with the baseline level and builds in any namespace.
+func checkBuilderPodSecurity(namespace *corev1.Namespace, steps []*commonmodels.StepTask) error {
+	if namespace == nil || !getDockerBuilders(steps)[step.DockerBuilderBuildKit] {
+		return nil
+	}
+	level := namespace.Labels[podSecurityEnforceLabel]
+	if level == "" || level == podSecurityLevelPrivileged {
+		return nil
+	}
+	return fmt.Errorf("the buildkit builder needs the privileged pod security level but namespace %s enforces the %s level, build with kaniko instead", namespace.Name, level)
+}
+
+// setDaemonlessBuilders adds the containers of the daemonless builders used by the job to the job pod.
+// The builders do not run in the job container since rootless buildkitd needs its own security context and
+// kaniko has to run in its own image, the job container drives them through the shared builder volume.
+// The job container writes builderDoneFile when it exits, even if the job executor crashes, and the builders
+// exit then, otherwise the pod would keep running until the job deadline.
+func setDaemonlessBuilders(job *batchv1.Job, steps []*commonmodels.StepTask, workspace string) {
+	builders := getDockerBuilders(steps)
+	if !builders[step.DockerBuilderBuildKit] && !builders[step.DockerBuilderKaniko] {
+		return
+	}
+
+	podSpec := &job.Spec.Template.Spec
+	builderMount := corev1.VolumeMount{
+		Name:      daemonlessBuilderVolumeName,
+		MountPath: step.DaemonlessBuilderDir,
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         daemonlessBuilderVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, builderMount)
+	if args := podSpec.Containers[0].Args; len(args) > 0 {
+		args[len(args)-1] = fmt.Sprintf("%s; code=$?; touch %s; exit $code", args[len(args)-1], builderDoneFile)
+	}
+
+	if builders[step.DockerBuilderBuildKit] {
+		setBuildKitContainers(job, builderMount)
+	}
+	if builders[step.DockerBuilderKaniko] {
+		setKanikoContainer(job, builderMount, workspace)
+	}
+}
+
+func setBuildKitContainers(job *batchv1.Job, builderMount corev1.VolumeMount) {
+	podSpec := &job.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         buildkitdStateVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	// buildctl is statically linked, so it runs in the job container whatever the build image is
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            "buildctl-init",
+		Image:           BuildKitImage,
+		Command:         []string{"cp", "/usr/bin/buildctl", step.BuildCtlFile},
+		VolumeMounts:    []corev1.VolumeMount{builderMount},
+	})
+
+	// buildkitd starts after the job container writes its config with the registries of the build,
+	// the shell exits when the job container is done and buildkitd is killed with the container.
+	script := fmt.Sprintf(
+		"until [ -f %[1]s ] || [ -f %[3]s ]; do sleep 1; done; "+
+			"if [ -f %[1]s ]; then rootlesskit buildkitd --config %[1]s --addr %[2]s --oci-worker-no-process-sandbox & fi; "+
+			"until [ -f %[3]s ]; do sleep 1; done",
+		step.BuildKitdConfigFile, step.BuildKitAddr, builderDoneFile,
+	)
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            buildkitdContainerName,
+		Image:           BuildKitImage,
+		Command:         []string{"/bin/sh", "-c"},
+		Args:            []string{script},
+		VolumeMounts: []corev1.VolumeMount{
+			builderMount,
+			{
+				Name:      buildkitdStateVolumeName,
+				MountPath: buildkitdStatePath,
+			},
+		},
+		// rootless buildkitd creates user namespaces and mounts, which the default seccomp and apparmor profiles deny,
+		// so the pod needs the privileged pod security level, see checkBuilderPodSecurity
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:  int64Ptr(buildkitdUserID),
+			RunAsGroup: int64Ptr(buildkitdUserID),
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeUnconfined,
+			},
+		},
+	})
+	if job.Spec.Template.Annotations == nil {
+		job.Spec.Template.Annotations = make(map[string]string)
+	}
+	job.Spec.Template.Annotations[corev1.AppArmorBetaContainerAnnotationKeyPrefix+buildkitdContainerName] = corev1.AppArmorBetaProfileNameUnconfined
+}
+
+func setKanikoContainer(job *batchv1.Job, builderMount corev1.VolumeMount, workspace string) {
+	podSpec := &job.Spec.Template.Spec
+	workspace = filepath.Clean(workspace)
+
+	// kaniko reads the build context from the workspace, so the workspace has to be a volume shared with the job container
+	mounts := []corev1.VolumeMount{builderMount}
+	workspaceMounted := false
+	for _, mount := range podSpec.Containers[0].VolumeMounts {
+		mountPath := filepath.Clean(mount.MountPath)
+		if mountPath == workspace {
+			workspaceMounted = true
+		}
+		if mountPath == workspace || strings.HasPrefix(mountPath, workspace+"/") {
+			mounts = append(mounts, mount)
+		}
+	}
+	if !workspaceMounted {
+		workspaceMount := corev1.VolumeMount{
+			Name:      workspaceVolumeName,
+			MountPath: workspace,
+		}
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         workspaceVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, workspaceMount)
+		mounts = append([]corev1.VolumeMount{builderMount, workspaceMount}, mounts[1:]...)
+	}
+
+	// the kaniko container runs every build script written by the job container until the job container is done
+	script := fmt.Sprintf(
+		"while [ ! -f %[4]s ]; do if [ -f %[1]s ]; then mv %[1]s %[1]s.running; sh %[1]s.running > %[2]s 2>&1; echo $? > %[3]s.tmp; mv %[3]s.tmp %[3]s; fi; sleep 1; done",
+		step.KanikoScriptFile, step.KanikoLogFile, step.KanikoExitCodeFile, builderDoneFile,
+	)
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            kanikoContainerName,
+		Image:           KanikoImage,
+		Command:         []string{"/busybox/sh", "-c"},
+		Args:            []string{script},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DOCKER_CONFIG",
+				Value: step.DaemonlessDockerConfigDir,
+			},
+		},
+		VolumeMounts: mounts,
+	})
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+func dockerBuildSteps(builders ...step.DockerBuilder) []*commonmodels.StepTask {
+	steps := []*commonmodels.StepTask{{Name: "shell", StepType: config.StepShell}}
+	for _, builder := range builders {
+		steps = append(steps, &commonmodels.StepTask{
+			Name:     "docker-build",
+			StepType: config.StepDockerBuild,
+			Spec:     &step.StepDockerBuildSpec{Builder: builder},
+		})
+	}
+	return steps
+}
+
+func TestNeedDockerDaemon(t *testing.T) {
+	assert.True(t, needDockerDaemon(dockerBuildSteps()))
+	assert.True(t, needDockerDaemon(dockerBuildSteps("")))
+	assert.True(t, needDockerDaemon(dockerBuildSteps(step.DockerBuilderDocker)))
+	assert.True(t, needDockerDaemon(dockerBuildSteps(step.DockerBuilderKaniko, step.DockerBuilderDocker)))
+	assert.False(t, needDockerDaemon(dockerBuildSteps(step.DockerBuilderBuildKit)))
+	assert.False(t, needDockerDaemon(dockerBuildSteps(step.DockerBuilderKaniko, step.DockerBuilderBuildKit)))
+}
+
+func newTestJob(mounts ...corev1.VolumeMount) *batchv1.Job {
+	return &batchv1.Job{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         "build",
+						Command:      []string{"/bin/sh", "-c"},
+						Args:         []string{"/executor/jobexecutor"},
+						VolumeMounts: mounts,
+					}},
+				},
+			},
+		},
+	}
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func TestSetDaemonlessBuilders(t *testing.T) {
+	t.Run("docker builder", func(t *testing.T) {
+		job := newTestJob()
+		setDaemonlessBuilders(job, dockerBuildSteps(step.DockerBuilderDocker), "/workspace")
+		assert.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Empty(t, job.Spec.Template.Spec.InitContainers)
+		assert.Empty(t, job.Spec.Template.Spec.Volumes)
+		assert.Equal(t, []string{"/executor/jobexecutor"}, job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("builders exit when the job container is done", func(t *testing.T) {
+		ast := require.New(t)
+		job := newTestJob()
+		setDaemonlessBuilders(job, dockerBuildSteps(step.DockerBuilderBuildKit, step.DockerBuilderKaniko), "/workspace")
+
+		podSpec := job.Spec.Template.Spec
+		ast.Equal([]string{"/executor/jobexecutor; code=$?; touch " + builderDoneFile + "; exit $code"}, podSpec.Containers[0].Args)
+		for _, name := range []string{buildkitdContainerName, kanikoContainerName} {
+			builder := findContainer(podSpec.Containers, name)
+			ast.NotNil(builder)
+			ast.Len(builder.Args, 1)
+			ast.NotContains(builder.Args[0], "while true")
+			ast.Contains(builder.Args[0], builderDoneFile)
+			ast.NotContains(builder.Args[0], "exec ")
+		}
+	})
+
+	t.Run("buildkit", func(t *testing.T) {
+		ast := require.New(t)
+		job := newTestJob()
+		setDaemonlessBuilders(job, dockerBuildSteps(step.DockerBuilderBuildKit), "/workspace")
+
+		podSpec := job.Spec.Template.Spec
+		ast.Len(podSpec.Containers, 2)
+		ast.Contains(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: daemonlessBuilderVolumeName, MountPath: step.DaemonlessBuilderDir})
+
+		buildkitd := findContainer(podSpec.Containers, buildkitdContainerName)
+		ast.NotNil(buildkitd)
+		ast.Equal(BuildKitImage, buildkitd.Image)
+		ast.Equal(int64(buildkitdUserID), *buildkitd.SecurityContext.RunAsUser)
+		ast.Equal(corev1.SeccompProfileTypeUnconfined, buildkitd.SecurityContext.SeccompProfile.Type)
+		ast.Nil(buildkitd.SecurityContext.Privileged)
+		ast.Contains(buildkitd.Args[0], "--addr "+step.BuildKitAddr)
+		ast.Contains(buildkitd.Args[0], "--config "+step.BuildKitdConfigFile)
+		ast.Equal(corev1.AppArmorBetaProfileNameUnconfined, job.Spec.Template.Annotations[corev1.AppArmorBetaContainerAnnotationKeyPrefix+buildkitdContainerName])
+
+		ast.Len(podSpec.InitContainers, 1)
+		ast.Equal([]string{"cp", "/usr/bin/buildctl", step.BuildCtlFile}, podSpec.InitContainers[0].Command)
+		ast.Nil(findContainer(podSpec.Containers, kanikoContainerName))
+	})
+
+	t.Run("kaniko shares the workspace", func(t *testing.T) {
+		ast := require.New(t)
+		cacheMount := corev1.VolumeMount{Name: "build-cache", MountPath: "/workspace/cache"}
+		job := newTestJob(corev1.VolumeMount{Name: "zadig-context", MountPath: "/zadig/"}, cacheMount)
+		setDaemonlessBuilders(job, dockerBuildSteps(step.DockerBuilderKaniko), "/workspace/")
+
+		podSpec := job.Spec.Template.Spec
+		workspaceMount := corev1.VolumeMount{Name: workspaceVolumeName, MountPath: "/workspace"}
+		ast.Contains(podSpec.Containers[0].VolumeMounts, workspaceMount)
+
+		kaniko := findContainer(podSpec.Containers, kanikoContainerName)
+		ast.NotNil(kaniko)
+		ast.Equal(KanikoImage, kaniko.Image)
+		ast.Equal([]corev1.VolumeMount{
+			{Name: daemonlessBuilderVolumeName, MountPath: step.DaemonlessBuilderDir},
+			workspaceMount,
+			cacheMount,
+		}, kaniko.VolumeMounts)
+		ast.Equal([]corev1.EnvVar{{Name: "DOCKER_CONFIG", Value: step.DaemonlessDockerConfigDir}}, kaniko.Env)
+		ast.Empty(podSpec.InitContainers)
+		ast.Nil(findContainer(podSpec.Containers, buildkitdContainerName))
+	})
+
+	t.Run("kaniko with a mounted workspace", func(t *testing.T) {
+		job := newTestJob(corev1.VolumeMount{Name: "build-cache", MountPath: "/workspace"})
+		setDaemonlessBuilders(job, dockerBuildSteps(step.DockerBuilderKaniko), "/workspace")
+
+		kaniko := findContainer(job.Spec.Template.Spec.Containers, kanikoContainerName)
+		assert.NotNil(t, kaniko)
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: daemonlessBuilderVolumeName, MountPath: step.DaemonlessBuilderDir},
+			{Name: "build-cache", MountPath: "/workspace"},
+		}, kaniko.VolumeMounts)
+		for _, volume := range job.Spec.Template.Spec.Volumes {
+			assert.NotEqual(t, workspaceVolumeName, volume.Name)
+		}
+	})
+}
+
+func TestCheckBuilderPodSecurity(t *testing.T) {
+	newNamespace := func(level string) *corev1.Namespace {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "zadig"}}
+		if level != "" {
+			namespace.Labels = map[string]string{podSecurityEnforceLabel: level}
+		}
+		return namespace
+	}
+
+	tests := []struct {
+		name      string
+		namespace *corev1.Namespace
+		builder   step.DockerBuilder
+		wantErr   bool
+	}{
+		{
+			name:    "namespace not found",
+			builder: step.DockerBuilderBuildKit,
+		},
+		{
+			name:      "buildkit without pod security",
+			namespace: newNamespace(""),
+			builder:   step.DockerBuilderBuildKit,
+		},
+		{
+			name:      "buildkit in a privileged namespace",
+			namespace: newNamespace(podSecurityLevelPrivileged),
+			builder:   step.DockerBuilderBuildKit,
+		},
+		{
+			name:      "buildkit in a baseline namespace",
+			namespace: newNamespace("baseline"),
+			builder:   step.DockerBuilderBuildKit,
+			wantErr:   true,
+		},
+		{
+			name:      "buildkit in a restricted namespace",
+			namespace: newNamespace("restricted"),
+			builder:   step.DockerBuilderBuildKit,
+			wantErr:   true,
+		},
+		{
+			name:      "kaniko in a baseline namespace",
+			namespace: newNamespace("baseline"),
+			builder:   step.DockerBuilderKaniko,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBuilderPodSecurity(tt.namespace, dockerBuildSteps(tt.builder))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	approvalservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/approval"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/deployfreeze"
+)
+
+func freezeApproveKey(workflowName string, taskID int64, jobName string) string {
+	return fmt.Sprintf("%s-%d-%s-freeze", workflowName, taskID, jobName)
+}
+
+// ApproveDeployFreezeOverride approves or rejects the override of the deploy freeze window the job is waiting in
+func ApproveDeployFreezeOverride(workflowName, jobName, userName, userID, comment string, taskID int64, approve bool) error {
+	approveWithL, ok := approvalservice.GlobalApproveMap.GetApproval(freezeApproveKey(workflowName, taskID, jobName))
+	if !ok {
+		return fmt.Errorf("workflow %s ID %d job %s is not waiting for deploy freeze override", workflowName, taskID, jobName)
+	}
+	return approveWithL.DoApproval(userName, userID, comment, approve)
+}
+
+// checkDeployFreeze checks the deploy freeze calendars before a production deploy job runs, it returns false if the job
+// must not go on. The job is blocked by an active window without approvers, otherwise every active window must be overridden
+// by its approvers.
+func checkDeployFreeze(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, production bool, logger *zap.SugaredLogger, ack func()) bool {
+	if !production {
+		return true
+	}
+
+	windows, err := deployfreeze.ListActiveWindows(workflowCtx.ProjectName, time.Now())
+	if err != nil {
+		logError(job, err.Error(), logger)
+		return false
+	}
+	for _, window := range windows {
+		if window.Blocking() {
+			job.Status = config.StatusBlocked
+			job.Error = fmt.Sprintf("production deploy is blocked, %s", window)
+			logger.Error(job.Error)
+			return false
+		}
+	}
+
+	for _, window := range windows {
+		if err := waitForFreezeOverride(ctx, job, workflowCtx, window, logger, ack); err != nil {
+			job.Error = err.Error()
+			logger.Error(job.Error)
+			return false
+		}
+		logger.Infof("deploy freeze %s is overridden for job %s", window.Freeze.Name, job.Name)
+	}
+	if len(windows) > 0 {
+		job.Status = config.StatusRunning
+		job.Error = ""
+		ack()
+	}
+	return true
+}
+
+// checkClusterDeployFreeze checks the deploy freeze calendars for the jobs which deploy to a cluster instead of an environment,
+// the cluster is production if it is marked as a production cluster.
+func checkClusterDeployFreeze(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, clusterID string, logger *zap.SugaredLogger, ack func()) bool {
+	cluster, err := commonrepo.NewK8SClusterColl().Get(clusterID)
+	if err != nil {
+		logError(job, fmt.Sprintf("failed to find cluster %s: %s", clusterID, err), logger)
+		return false
+	}
+	return checkDeployFreeze(ctx, job, workflowCtx, cluster.Production, logger, ack)
+}
+
+func waitForFreezeOverride(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, window *deployfreeze.ActiveWindow, logger *zap.SugaredLogger, ack func()) error {
+	approvers := make([]*commonmodels.User, 0, len(window.Window.Approvers))
+	for _, approver := range window.Window.Approvers {
+		approvers = append(approvers, &commonmodels.User{
+			Type:     approver.Type,
+			UserID:   approver.UserID,
+			UserName: approver.UserName,
+		})
+	}
+	timeoutMinutes := window.Window.Timeout
+	if timeoutMinutes == 0 {
+		timeoutMinutes = 60
+	}
+	approveKey := freezeApproveKey(workflowCtx.WorkflowName, workflowCtx.TaskID, job.Name)
+	approval := &commonmodels.NativeApproval{
+		Timeout:         timeoutMinutes,
+		ApproveUsers:    approvers,
+		NeededApprovers: window.Window.NeededApprovers,
+		InstanceCode:    approveKey,
+	}
+	approveWithL := &approvalservice.ApproveWithLock{Approval: approval}
+	approvalservice.GlobalApproveMap.SetApproval(approveKey, approveWithL)
+	defer approvalservice.GlobalApproveMap.DeleteApproval(approveKey)
+
+	job.FreezeApproval = approval
+	job.Status = config.StatusWaitingApprove
+	job.Error = fmt.Sprintf("%s, waiting for override approval", window)
+	// workflowCtx.SetStatus contain ack() function
+	workflowCtx.SetStatus(config.StatusWaitingApprove)
+	defer workflowCtx.SetStatus(config.StatusRunning)
+
+	timeout := time.After(time.Duration(timeoutMinutes) * time.Minute)
+	latestApproveCount := 0
+	for {
+		time.Sleep(1 * time.Second)
+		select {
+		case <-ctx.Done():
+			job.Status = config.StatusCancelled
+			return fmt.Errorf("workflow was canceled")
+		case <-timeout:
+			job.Status = config.StatusTimeout
+			return fmt.Errorf("deploy freeze %s override approval timeout", window.Freeze.Name)
+		default:
+			approved, approveCount, err := approveWithL.IsApproval()
+			if err != nil {
+				job.Status = config.StatusReject
+				return fmt.Errorf("deploy freeze %s override rejected: %s", window.Freeze.Name, err)
+			}
+			if approved {
+				return nil
+			}
+			if approveCount > latestApproveCount {
+				ack()
+				latestApproveCount = approveCount
+			}
+		}
+	}
+}
@@ -42,6 +42,12 @@ type JobCtl interface {
 	SaveInfo(ctx context.Context) error
 }
 
+// ResumableJobCtl is implemented by the job controllers which can reattach to
+// a job that was still running when aslan restarted.
+type ResumableJobCtl interface {
+	Resume(ctx context.Context)
+}
+
 func initJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) JobCtl {
 	var jobCtl JobCtl
 	switch job.JobType {
@@ -91,6 +97,8 @@ func initJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTas
 		jobCtl = NewMseGrayOfflineJobCtl(job, workflowCtx, ack, logger)
 	case string(config.JobGuanceyunCheck):
 		jobCtl = NewGuanceyunCheckJobCtl(job, workflowCtx, ack, logger)
+	case string(config.JobPrometheusCheck):
+		jobCtl = NewPrometheusCheckJobCtl(job, workflowCtx, ack, logger)
 	case string(config.JobJenkins):
 		jobCtl = NewJenkinsJobCtl(job, workflowCtx, ack, logger)
 	case string(config.JobSQL):
@@ -101,12 +109,8 @@ func initJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTas
 	return jobCtl
 }
 
-func runJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
-	// should skip passed job when workflow task be restarted
-	if job.Status == config.StatusPassed {
-		return
-	}
-	// render global variables for every job.
+// renderGlobalVariables renders the global variables for every job.
+func renderGlobalVariables(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger) {
 	workflowCtx.GlobalContextEach(func(k, v string) bool {
 		b, _ := json.Marshal(job)
 		v = strings.Trim(v, "\n")
@@ -116,6 +120,32 @@ func runJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonm
 		}
 		return true
 	})
+}
+
+func runJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+	// should skip passed job when workflow task be restarted
+	if job.Status == config.StatusPassed {
+		return
+	}
+	if workflowCtx.Resumed && jobInterrupted(job.Status) {
+		resumeJob(ctx, job, workflowCtx, logger, ack)
+		return
+	}
+	renderGlobalVariables(job, workflowCtx, logger)
+	run, reason, err := evaluateJobCondition(job, workflowCtx)
+	if err != nil {
+		job.StartTime = time.Now().Unix()
+		job.EndTime = job.StartTime
+		logError(job, err.Error(), logger)
+		workflowCtx.GlobalContextSet(getJobStatusKey(job.Key), string(job.Status))
+		ack()
+		return
+	}
+	if !run {
+		logger.Infof("skip job: %s, reason: %s", job.Name, reason)
+		skipJob(job, workflowCtx, reason, ack)
+		return
+	}
 	job.Status = config.StatusPrepare
 	job.StartTime = time.Now().Unix()
 	job.K8sJobName = getJobName(workflowCtx.WorkflowName, workflowCtx.TaskID)
@@ -132,7 +162,9 @@ func runJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonm
 			job.Error = errMsg
 		}
 		job.EndTime = time.Now().Unix()
+		workflowCtx.GlobalContextSet(getJobStatusKey(job.Key), string(job.Status))
 		logger.Infof("finish job: %s,status: %s", job.Name, job.Status)
+		observeJobDuration(job, workflowCtx)
 		ack()
 		logger.Infof("updating job info into db...")
 		err := jobCtl.SaveInfo(ctx)
@@ -144,6 +176,79 @@ func runJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonm
 	jobCtl.Run(ctx)
 }
 
+// RunJob runs a single job task, it is used when jobs are scheduled by their dependencies instead of stages.
+func RunJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+	runJob(ctx, job, workflowCtx, logger, ack)
+}
+
+// resumeJob reattaches to a job which was interrupted by an aslan restart, idempotent jobs
+// are run again and the other jobs are marked as failed instead of being run for a second time.
+func resumeJob(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+	logger.Infof("resume job: %s,status: %s", job.Name, job.Status)
+	jobCtl := initJobCtl(job, workflowCtx, logger, ack)
+	defer func() {
+		if err := recover(); err != nil {
+			errMsg := fmt.Sprintf("job: %s panic: %v", job.Name, err)
+			logger.Errorf(errMsg)
+			debug.PrintStack()
+			job.Status = config.StatusFailed
+			job.Error = errMsg
+		}
+		job.EndTime = time.Now().Unix()
+		workflowCtx.GlobalContextSet(getJobStatusKey(job.Key), string(job.Status))
+		logger.Infof("finish resumed job: %s,status: %s", job.Name, job.Status)
+		observeJobDuration(job, workflowCtx)
+		ack()
+		if err := jobCtl.SaveInfo(ctx); err != nil {
+			logger.Errorf("update job info: %s into db error: %v", job.Name, err)
+		}
+	}()
+
+	switch resumePolicy(job.JobType) {
+	case jobResumeReattach:
+		if resumableJobCtl, ok := jobCtl.(ResumableJobCtl); ok {
+			resumableJobCtl.Resume(ctx)
+			return
+		}
+	case jobResumeRerun:
+		logger.Infof("job: %s is idempotent, run it again", job.Name)
+		job.Status = config.StatusPrepare
+		ack()
+		jobCtl.Run(ctx)
+		return
+	}
+	logError(job, fmt.Sprintf("job %s of type %s was interrupted by aslan restart and can not be resumed", job.Name, job.JobType), logger)
+}
+
+type jobResumePolicy int
+
+const (
+	// jobResumeFail marks the interrupted job as failed, it is used for the jobs which are not safe to run twice
+	jobResumeFail jobResumePolicy = iota
+	// jobResumeReattach reattaches to the kubernetes or vm job which keeps running without aslan
+	jobResumeReattach
+	// jobResumeRerun runs the job again, it is used for the jobs which converge to the same result when run twice
+	jobResumeRerun
+)
+
+func resumePolicy(jobType string) jobResumePolicy {
+	switch jobType {
+	case string(config.JobZadigDeploy), string(config.JobZadigHelmDeploy), string(config.JobZadigHelmChartDeploy),
+		string(config.JobCustomDeploy), string(config.JobK8sPatch), string(config.JobNacos), string(config.JobApollo),
+		string(config.JobOfflineService), string(config.JobGuanceyunCheck), string(config.JobPrometheusCheck):
+		return jobResumeRerun
+	case string(config.JobK8sCanaryDeploy), string(config.JobK8sCanaryRelease), string(config.JobK8sBlueGreenDeploy),
+		string(config.JobK8sBlueGreenRelease), string(config.JobK8sGrayRelease), string(config.JobK8sGrayRollback),
+		string(config.JobIstioRelease), string(config.JobIstioRollback), string(config.JobJira), string(config.JobMeegoTransition),
+		string(config.JobWorkflowTrigger), string(config.JobMseGrayRelease), string(config.JobMseGrayOffline),
+		string(config.JobJenkins), string(config.JobSQL):
+		return jobResumeFail
+	default:
+		// freestyle, build, testing, scanning and plugin jobs run in kubernetes or vm jobs
+		return jobResumeReattach
+	}
+}
+
 func RunJobs(ctx context.Context, jobs []*commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, concurrency int, logger *zap.SugaredLogger, ack func()) {
 	if concurrency == 1 {
 		for _, job := range jobs {
@@ -167,6 +272,9 @@ func CleanWorkflowJobs(ctx context.Context, workflowTask *commonmodels.WorkflowT
 	}
 }
 
+// runPoolJob runs a job task in the pool, it is replaced in tests
+var runPoolJob = runJob
+
 // Pool is a worker group that runs a number of tasks at a
 // configured concurrency.
 type Pool struct {
@@ -178,19 +286,30 @@ type Pool struct {
 	ack         func()
 	ctx         context.Context
 	wg          sync.WaitGroup
+	// matrixLimits limits the running job tasks of every matrix job with max parallel set
+	matrixLimits map[string]chan struct{}
 }
 
 // NewPool initializes a new pool with the given tasks and
 // at the given concurrency.
 func NewPool(ctx context.Context, jobs []*commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, concurrency int, logger *zap.SugaredLogger, ack func()) *Pool {
+	matrixLimits := make(map[string]chan struct{})
+	for _, job := range jobs {
+		if job.MatrixMaxParallel > 0 {
+			if _, ok := matrixLimits[job.OriginName]; !ok {
+				matrixLimits[job.OriginName] = make(chan struct{}, job.MatrixMaxParallel)
+			}
+		}
+	}
 	return &Pool{
-		Jobs:        jobs,
-		concurrency: concurrency,
-		workflowCtx: workflowCtx,
-		jobsChan:    make(chan *commonmodels.JobTask),
-		logger:      logger,
-		ack:         ack,
-		ctx:         ctx,
+		Jobs:         jobs,
+		concurrency:  concurrency,
+		workflowCtx:  workflowCtx,
+		jobsChan:     make(chan *commonmodels.JobTask),
+		logger:       logger,
+		ack:          ack,
+		ctx:          ctx,
+		matrixLimits: matrixLimits,
 	}
 }
 
@@ -202,9 +321,23 @@ func (p *Pool) Run() {
 	}
 
 	p.wg.Add(len(p.Jobs))
+	var dispatching sync.WaitGroup
 	for _, task := range p.Jobs {
-		p.jobsChan <- task
+		limit, limited := p.matrixLimits[task.OriginName]
+		if !limited {
+			p.jobsChan <- task
+			continue
+		}
+		// the matrix limit is acquired before a worker is taken, so the job tasks waiting for
+		// the other tasks of the same matrix do not hold any worker of the pool
+		dispatching.Add(1)
+		go func(task *commonmodels.JobTask, limit chan struct{}) {
+			defer dispatching.Done()
+			limit <- struct{}{}
+			p.jobsChan <- task
+		}(task, limit)
 	}
+	dispatching.Wait()
 
 	// all workers return
 	close(p.jobsChan)
@@ -215,7 +348,10 @@ func (p *Pool) Run() {
 // The work loop for any single goroutine.
 func (p *Pool) work() {
 	for job := range p.jobsChan {
-		runJob(p.ctx, job, p.workflowCtx, p.logger, p.ack)
+		runPoolJob(p.ctx, job, p.workflowCtx, p.logger, p.ack)
+		if limit, limited := p.matrixLimits[job.OriginName]; limited {
+			<-limit
+		}
 		p.wg.Done()
 	}
 }
@@ -248,7 +384,16 @@ func getJobName(workflowName string, taskID int64) string {
 }
 
 func jobStatusFailed(status config.Status) bool {
-	if status == config.StatusCancelled || status == config.StatusFailed || status == config.StatusTimeout || status == config.StatusReject {
+	if status == config.StatusCancelled || status == config.StatusFailed || status == config.StatusTimeout || status == config.StatusReject || status == config.StatusBlocked {
+		return true
+	}
+	return false
+}
+
+// jobInterrupted returns true if the job had been started but not finished.
+func jobInterrupted(status config.Status) bool {
+	switch status {
+	case config.StatusPrepare, config.StatusRunning, config.StatusCreated, config.StatusWaitingApprove:
 		return true
 	}
 	return false
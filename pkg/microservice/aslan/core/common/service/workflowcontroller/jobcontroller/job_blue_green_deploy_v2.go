@@ -72,6 +72,9 @@ func NewBlueGreenDeployV2JobCtl(job *commonmodels.JobTask, workflowCtx *commonmo
 func (c *BlueGreenDeployV2JobCtl) Clean(ctx context.Context) {}
 
 func (c *BlueGreenDeployV2JobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.Production, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 	if err := c.run(ctx); err != nil {
@@ -138,6 +138,9 @@ func (c *BlueGreenReleaseV2JobCtl) Clean(ctx context.Context) {
 }
 
 func (c *BlueGreenReleaseV2JobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.Production, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 	if err := c.run(ctx); err != nil {
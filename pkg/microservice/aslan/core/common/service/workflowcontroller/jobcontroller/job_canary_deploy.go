@@ -70,6 +70,9 @@ func NewCanaryDeployJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.
 func (c *CanaryDeployJobCtl) Clean(ctx context.Context) {}
 
 func (c *CanaryDeployJobCtl) Run(ctx context.Context) {
+	if !checkClusterDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.ClusterID, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 	if err := c.run(ctx); err != nil {
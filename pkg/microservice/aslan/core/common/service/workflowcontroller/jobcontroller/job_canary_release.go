@@ -76,6 +76,9 @@ func (c *CanaryReleaseJobCtl) Clean(ctx context.Context) {
 }
 
 func (c *CanaryReleaseJobCtl) Run(ctx context.Context) {
+	if !checkClusterDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.ClusterID, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 	if err := c.run(ctx); err != nil {
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/setting"
+)
+
+// getJobStatusKey returns the global context key of the job status, other jobs can use it like {{.job.jobKey.status}}
+func getJobStatusKey(key string) string {
+	return fmt.Sprintf(setting.RenderValueTemplate, strings.Join([]string{"job", key, "status"}, "."))
+}
+
+// evaluateJobCondition evaluates the if expression of the job.
+// The variables in the expression are named like the render variables without the braces, e.g.
+// [workflow.params.branch] == "main" && [job.test.status] == "passed" && [job.build.output.IMAGE] != "".
+// Workflow params and key values can also be used by their names directly, e.g. branch == "main".
+// If the job should not run, the reason contains the expression and the values it was evaluated with.
+func evaluateJobCondition(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx) (bool, string, error) {
+	if strings.TrimSpace(job.If) == "" {
+		return true, "", nil
+	}
+	expression, err := govaluate.NewEvaluableExpression(job.If)
+	if err != nil {
+		return false, "", fmt.Errorf("invalid if expression %s: %v", job.If, err)
+	}
+
+	params, credentials := jobConditionParams(workflowCtx)
+	vars := expression.Vars()
+	for _, v := range vars {
+		// variables not set yet, like outputs of skipped jobs, are evaluated as empty string.
+		if _, ok := params[v]; !ok {
+			params[v] = ""
+		}
+	}
+	result, err := expression.Evaluate(params)
+	if err != nil {
+		return false, "", fmt.Errorf("evaluate if expression %s error: %v", job.If, err)
+	}
+	run, ok := result.(bool)
+	if !ok {
+		return false, "", fmt.Errorf("if expression %s should be evaluated to bool, got: %v", job.If, result)
+	}
+	if run {
+		return true, "", nil
+	}
+
+	sort.Strings(vars)
+	values := make([]string, 0, len(vars))
+	for _, v := range vars {
+		if credentials.Has(v) {
+			values = append(values, fmt.Sprintf("%s=%s", v, setting.MaskValue))
+			continue
+		}
+		values = append(values, fmt.Sprintf("%s=%v", v, params[v]))
+	}
+	reason := fmt.Sprintf("if expression %s was evaluated to false", job.If)
+	if len(values) > 0 {
+		reason = fmt.Sprintf("%s with %s", reason, strings.Join(values, ", "))
+	}
+	return false, reason, nil
+}
+
+// jobConditionParams returns the variables can be used in the if expression and names of the credential ones.
+func jobConditionParams(workflowCtx *commonmodels.WorkflowTaskCtx) (map[string]interface{}, sets.String) {
+	params := make(map[string]interface{})
+	credentials := sets.NewString()
+	for _, kv := range workflowCtx.WorkflowKeyVals {
+		params[kv.Key] = kv.Value
+		if kv.IsCredential {
+			credentials.Insert(kv.Key)
+		}
+	}
+	for _, param := range workflowCtx.WorkflowParams {
+		paramsKey := strings.Join([]string{"workflow", "params", param.Name}, ".")
+		params[param.Name] = param.Value
+		params[paramsKey] = param.Value
+		if param.IsCredential {
+			credentials.Insert(param.Name, paramsKey)
+		}
+	}
+	params["project"] = workflowCtx.ProjectName
+	params["workflow.name"] = workflowCtx.WorkflowName
+	params["workflow.task.id"] = fmt.Sprintf("%d", workflowCtx.TaskID)
+	params["workflow.task.creator"] = workflowCtx.WorkflowTaskCreatorUsername
+	// job outputs and statuses are stored in the global context like {{.job.jobKey.output.outputName}}
+	if workflowCtx.GlobalContextGetAll != nil {
+		for k, v := range workflowCtx.GlobalContextGetAll() {
+			k = strings.TrimSuffix(strings.TrimPrefix(k, "{{."), "}}")
+			params[k] = strings.Trim(v, "\n")
+		}
+	}
+	return params, credentials
+}
+
+// skipJob marks the job as skipped without running it.
+func skipJob(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, reason string, ack func()) {
+	now := time.Now().Unix()
+	job.Status = config.StatusSkipped
+	job.SkipReason = reason
+	job.StartTime = now
+	job.EndTime = now
+	workflowCtx.GlobalContextSet(getJobStatusKey(job.Key), string(job.Status))
+	ack()
+}
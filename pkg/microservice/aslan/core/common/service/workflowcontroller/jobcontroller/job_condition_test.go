@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/setting"
+)
+
+func testConditionCtx() *commonmodels.WorkflowTaskCtx {
+	globalContext := map[string]string{
+		"{{.job.build.status}}":          "passed",
+		"{{.job.build.output.IMAGE}}":    "koderover/demo:v1\n",
+		"{{.job.scan.output.SEVERITY}}":  "",
+		"{{.job.test.output.COVERAGE}}":  "85",
+		"{{.job.deploy.output.REPLICA}}": "3",
+	}
+	return &commonmodels.WorkflowTaskCtx{
+		ProjectName:  "demo",
+		WorkflowName: "deploy-demo",
+		TaskID:       12,
+		WorkflowKeyVals: []*commonmodels.KeyVal{
+			{Key: "ENV", Value: "prod"},
+			{Key: "TOKEN", Value: "secret-token", IsCredential: true},
+		},
+		WorkflowParams: []*commonmodels.Param{
+			{Name: "branch", Value: "main"},
+			{Name: "replicas", Value: "3"},
+			{Name: "password", Value: "p@ss", IsCredential: true},
+			{Name: "empty", Value: ""},
+		},
+		GlobalContextGetAll: func() map[string]string { return globalContext },
+	}
+}
+
+func TestEvaluateJobCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "no expression", expr: "", want: true},
+		{name: "blank expression", expr: "  ", want: true},
+		{name: "equal", expr: `branch == "main"`, want: true},
+		{name: "equal false", expr: `branch == "dev"`, want: false},
+		{name: "not equal", expr: `[workflow.params.branch] != "dev"`, want: true},
+		{name: "and", expr: `branch == "main" && ENV == "prod"`, want: true},
+		{name: "and false", expr: `branch == "main" && ENV == "dev"`, want: false},
+		{name: "or", expr: `branch == "dev" || ENV == "prod"`, want: true},
+		{name: "not", expr: `!(branch == "dev")`, want: true},
+		{name: "regex match", expr: `branch =~ "^ma"`, want: true},
+		{name: "regex not match", expr: `branch !~ "^release/"`, want: true},
+		{name: "in", expr: `ENV IN ("prod", "staging")`, want: true},
+		{name: "in false", expr: `ENV IN ("dev", "staging")`, want: false},
+		{name: "job status", expr: `[job.build.status] == "passed"`, want: true},
+		{name: "job output trimmed", expr: `[job.build.output.IMAGE] == "koderover/demo:v1"`, want: true},
+		{name: "job output not empty", expr: `[job.build.output.IMAGE] != ""`, want: true},
+		{name: "project and workflow", expr: `project == "demo" && [workflow.name] == "deploy-demo" && [workflow.task.id] == "12"`, want: true},
+		{name: "string compare", expr: `[job.test.output.COVERAGE] >= "80"`, want: true},
+		{name: "string less", expr: `[job.deploy.output.REPLICA] < "2"`, want: false},
+		{name: "missing variable is empty", expr: `[job.missing.output.IMAGE] == ""`, want: true},
+		{name: "missing variable not equal", expr: `[job.missing.status] == "passed"`, want: false},
+		{name: "empty param", expr: `empty == ""`, want: true},
+		{name: "empty output", expr: `[job.scan.output.SEVERITY] != ""`, want: false},
+		{name: "invalid expression", expr: `branch == `, wantErr: true},
+		{name: "not bool", expr: `branch`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, reason, err := evaluateJobCondition(&commonmodels.JobTask{If: tt.expr}, testConditionCtx())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, run)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, run)
+			if run {
+				assert.Empty(t, reason)
+			} else {
+				assert.Contains(t, reason, tt.expr)
+			}
+		})
+	}
+}
+
+func TestEvaluateJobConditionMaskCredentials(t *testing.T) {
+	run, reason, err := evaluateJobCondition(&commonmodels.JobTask{If: `TOKEN == "x" || [workflow.params.password] == "y" || branch == "dev"`}, testConditionCtx())
+	assert.NoError(t, err)
+	assert.False(t, run)
+	assert.NotContains(t, reason, "secret-token")
+	assert.NotContains(t, reason, "p@ss")
+	assert.Contains(t, reason, "TOKEN="+setting.MaskValue)
+	assert.Contains(t, reason, "workflow.params.password="+setting.MaskValue)
+	assert.Contains(t, reason, "branch=main")
+}
+
+func TestEvaluateJobConditionWithoutGlobalContext(t *testing.T) {
+	run, _, err := evaluateJobCondition(&commonmodels.JobTask{If: `[job.build.status] == ""`}, &commonmodels.WorkflowTaskCtx{})
+	assert.NoError(t, err)
+	assert.True(t, run)
+}
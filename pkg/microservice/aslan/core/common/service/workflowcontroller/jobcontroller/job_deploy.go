@@ -88,6 +88,9 @@ func NewDeployJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.Workfl
 func (c *DeployJobCtl) Clean(ctx context.Context) {}
 
 func (c *DeployJobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.Production, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 	c.preRun()
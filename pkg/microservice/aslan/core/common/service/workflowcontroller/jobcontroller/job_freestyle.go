@@ -26,6 +26,7 @@ import (
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -42,8 +43,10 @@ import (
 	kubeclient "github.com/koderover/zadig/pkg/shared/kube/client"
 	"github.com/koderover/zadig/pkg/tool/dockerhost"
 	krkubeclient "github.com/koderover/zadig/pkg/tool/kube/client"
+	"github.com/koderover/zadig/pkg/tool/kube/getter"
 	"github.com/koderover/zadig/pkg/tool/kube/informer"
 	"github.com/koderover/zadig/pkg/tool/kube/updater"
+	"github.com/koderover/zadig/pkg/types/step"
 )
 
 const (
@@ -134,9 +137,55 @@ func (c *FreestyleJobCtl) prepare(ctx context.Context) error {
 	return nil
 }
 
-func (c *FreestyleJobCtl) run(ctx context.Context) error {
-	// get kube client
-	hubServerAddr := config.HubServerAddress()
+// Resume reattaches to a job which was still running when aslan restarted,
+// kubernetes jobs are found by the job label and vm jobs by the saved vm job record.
+func (c *FreestyleJobCtl) Resume(ctx context.Context) {
+	if c.job.Infrastructure == setting.JobVMInfrastructure {
+		if c.job.VMJobID == "" {
+			logError(c.job, "vm job id not found, cannot resume the job", c.logger)
+			return
+		}
+		vmJob, err := vmmongodb.NewVMJobColl().FindByID(c.job.VMJobID)
+		if err != nil || vmJob == nil {
+			logError(c.job, fmt.Sprintf("vm job %s not found, cannot resume the job", c.job.VMJobID), c.logger)
+			return
+		}
+		c.logger.Infof("resume vm job %s, vm job id: %s", c.job.Name, c.job.VMJobID)
+		c.vmJobWait(ctx, c.job.VMJobID)
+		c.vmComplete(ctx, c.job.VMJobID)
+		return
+	}
+
+	if c.job.K8sJobName == "" {
+		logError(c.job, "k8s job name not found, cannot resume the job", c.logger)
+		return
+	}
+	if err := c.initKubeClients(); err != nil {
+		return
+	}
+	jobLabel := &JobLabel{
+		JobType: string(c.job.JobType),
+		JobName: c.job.K8sJobName,
+	}
+	jobs, err := getter.ListJobs(c.jobTaskSpec.Properties.Namespace, labels.Set(getJobLabels(jobLabel)).AsSelector(), c.kubeclient)
+	if err != nil {
+		logError(c.job, fmt.Sprintf("list job %s error: %v", c.job.K8sJobName, err), c.logger)
+		return
+	}
+	if len(jobs) == 0 {
+		logError(c.job, fmt.Sprintf("job %s not found, cannot resume the job", c.job.K8sJobName), c.logger)
+		return
+	}
+	if err := c.initInformer(); err != nil {
+		logError(c.job, err.Error(), c.logger)
+		return
+	}
+	c.logger.Infof("resume job %s, k8s job name: %s", c.job.Name, c.job.K8sJobName)
+	c.wait(ctx)
+	c.complete(ctx)
+}
+
+func (c *FreestyleJobCtl) initKubeClients() error {
 	switch c.jobTaskSpec.Properties.ClusterID {
 	case setting.LocalClusterID:
 		c.jobTaskSpec.Properties.Namespace = zadigconfig.Namespace()
@@ -147,7 +196,7 @@ func (c *FreestyleJobCtl) run(ctx context.Context) error {
 	default:
 		c.jobTaskSpec.Properties.Namespace = setting.AttachedClusterNamespace
 
-		crClient, clientset, restConfig, apiServer, err := GetK8sClients(hubServerAddr, c.jobTaskSpec.Properties.ClusterID)
+		crClient, clientset, restConfig, apiServer, err := GetK8sClients(config.HubServerAddress(), c.jobTaskSpec.Properties.ClusterID)
 		if err != nil {
 			logError(c.job, err.Error(), c.logger)
 			return err
@@ -157,11 +206,37 @@ func (c *FreestyleJobCtl) run(ctx context.Context) error {
 		c.restConfig = restConfig
 		c.apiServer = apiServer
 	}
+	return nil
+}
+
+func (c *FreestyleJobCtl) initInformer() error {
+	clientSet, err := kubeclient.GetKubeClientSet(config.HubServerAddress(), c.jobTaskSpec.Properties.ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "get kube client set")
+	}
+	informer, err := informer.NewInformer(c.jobTaskSpec.Properties.ClusterID, c.jobTaskSpec.Properties.Namespace, clientSet)
+	if err != nil {
+		return errors.Wrap(err, "get informer")
+	}
+	c.informer = informer
+	return nil
+}
+
+func (c *FreestyleJobCtl) run(ctx context.Context) error {
+	// get kube client
+	hubServerAddr := config.HubServerAddress()
+	if err := c.initKubeClients(); err != nil {
+		return err
+	}
 
 	// decide which docker host to use.
 	// TODO: do not use code in warpdrive moudule, should move to a public place
-	dockerhosts := dockerhost.NewDockerHosts(hubServerAddr, c.logger)
-	c.jobTaskSpec.Properties.DockerHost = dockerhosts.GetBestHost(dockerhost.ClusterID(c.jobTaskSpec.Properties.ClusterID), "")
+	// the jobs building with the daemonless builders only do not use dind.
+	c.jobTaskSpec.Properties.DockerHost = ""
+	if needDockerDaemon(c.jobTaskSpec.Steps) {
+		dockerhosts := dockerhost.NewDockerHosts(hubServerAddr, c.logger)
+		c.jobTaskSpec.Properties.DockerHost = dockerhosts.GetBestHost(dockerhost.ClusterID(c.jobTaskSpec.Properties.ClusterID), "")
+	}
 
 	// not local cluster
 	var (
@@ -186,7 +261,21 @@ func (c *FreestyleJobCtl) run(ctx context.Context) error {
 
 	c.jobTaskSpec.Properties.DockerHost = dockerHost
 
-	jobCtxBytes, err := yaml.Marshal(BuildJobExcutorContext(c.jobTaskSpec, c.job, c.workflowCtx, c.logger))
+	namespace, _, err := getter.GetNamespace(c.jobTaskSpec.Properties.Namespace, c.kubeclient)
+	if err != nil {
+		c.logger.Warnf("failed to get namespace %s to check the pod security level: %v", c.jobTaskSpec.Properties.Namespace, err)
+	}
+	if err := checkBuilderPodSecurity(namespace, c.jobTaskSpec.Steps); err != nil {
+		logError(c.job, err.Error(), c.logger)
+		return err
+	}
+
+	jobCtx, err := BuildJobExcutorContext(ctx, c.jobTaskSpec, c.job, c.workflowCtx, c.logger)
+	if err != nil {
+		logError(c.job, err.Error(), c.logger)
+		return err
+	}
+	jobCtxBytes, err := yaml.Marshal(jobCtx)
 	if err != nil {
 		msg := fmt.Sprintf("cannot Jobexcutor.Context data: %v", err)
 		logError(c.job, msg, c.logger)
@@ -243,21 +332,20 @@ func (c *FreestyleJobCtl) run(ctx context.Context) error {
 	}
 
 	// set informer when job and cm have been created
-	clientSet, err := kubeclient.GetKubeClientSet(config.HubServerAddress(), c.jobTaskSpec.Properties.ClusterID)
-	if err != nil {
-		return errors.Wrap(err, "get kube client set")
-	}
-	informer, err := informer.NewInformer(c.jobTaskSpec.Properties.ClusterID, c.jobTaskSpec.Properties.Namespace, clientSet)
-	if err != nil {
-		return errors.Wrap(err, "get informer")
+	if err := c.initInformer(); err != nil {
+		return err
 	}
-	c.informer = informer
 	c.logger.Infof("succeed to create job %s", c.job.K8sJobName)
 	return nil
 }
 
 func (c *FreestyleJobCtl) runVMJob(ctx context.Context) (string, error) {
-	jobCtxBytes, err := yaml.Marshal(BuildJobExcutorContext(c.jobTaskSpec, c.job, c.workflowCtx, c.logger))
+	jobCtx, err := BuildJobExcutorContext(ctx, c.jobTaskSpec, c.job, c.workflowCtx, c.logger)
+	if err != nil {
+		logError(c.job, err.Error(), c.logger)
+		return "", err
+	}
+	jobCtxBytes, err := yaml.Marshal(jobCtx)
 	if err != nil {
 		msg := fmt.Sprintf("cannot Jobexcutor.Context data: %v", err)
 		logError(c.job, msg, c.logger)
@@ -287,13 +375,15 @@ func (c *FreestyleJobCtl) runVMJob(ctx context.Context) (string, error) {
 		logError(c.job, msg, c.logger)
 		return "", errors.New(msg)
 	}
+	c.job.VMJobID = vmJob.ID.Hex()
+	c.ack()
 	return vmJob.ID.Hex(), nil
 }
 
 func (c *FreestyleJobCtl) wait(ctx context.Context) {
 	var err error
 	taskTimeout := time.After(time.Duration(c.jobTaskSpec.Properties.Timeout) * time.Minute)
-	c.job.Status, err = waitJobStart(ctx, c.jobTaskSpec.Properties.Namespace, c.job.K8sJobName, c.kubeclient, c.apiServer, taskTimeout, c.logger)
+	c.job.Status, err = waitJobStart(ctx, c.jobTaskSpec.Properties.Namespace, c.job.K8sJobName, c.kubeclient, c.apiServer, taskTimeout, podScheduledObserver(c.job, c.workflowCtx), c.logger)
 	if err != nil {
 		c.job.Error = err.Error()
 	}
@@ -371,7 +461,10 @@ func (c *FreestyleJobCtl) complete(ctx context.Context) {
 	}
 	if err := stepcontroller.SummarizeSteps(ctx, c.workflowCtx, &c.jobTaskSpec.Properties.Paths, c.job.Name, c.jobTaskSpec.Steps, c.logger); err != nil {
 		c.logger.Error(err)
-		c.job.Error = err.Error()
+		// keep the error of the job itself, summarizing the steps fails after it
+		if c.job.Error == "" {
+			c.job.Error = err.Error()
+		}
 		return
 	}
 }
@@ -394,7 +487,10 @@ func (c *FreestyleJobCtl) vmComplete(ctx context.Context, jobID string) {
 	// summarize steps
 	if err := stepcontroller.SummarizeSteps(ctx, c.workflowCtx, &c.jobTaskSpec.Properties.Paths, c.job.Name, c.jobTaskSpec.Steps, c.logger); err != nil {
 		c.logger.Error(err)
-		c.job.Error = err.Error()
+		// keep the error of the job itself, summarizing the steps fails after it
+		if c.job.Error == "" {
+			c.job.Error = err.Error()
+		}
 		return
 	}
 }
@@ -408,14 +504,19 @@ func getVMJobOutputFromJobDB(jobID, jobName string, job *commonmodels.JobTask, w
 		return errors.New("vm job not found")
 	}
 	outputs := vmJob.Outputs
-	writeOutputs(outputs, job.Key, workflowCtx)
+	writeOutputs(outputs, job, workflowCtx)
 
 	return nil
 }
 
-func BuildJobExcutorContext(jobTaskSpec *commonmodels.JobTaskFreestyleSpec, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger) *JobContext {
+func BuildJobExcutorContext(ctx context.Context, jobTaskSpec *commonmodels.JobTaskFreestyleSpec, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger) (*JobContext, error) {
+	envs, secretProviders, err := checkSecretEnvs(workflowCtx.ProjectName, job.Infrastructure, jobTaskSpec.Properties.Envs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret envs: %s", err)
+	}
+
 	var envVars, secretEnvVars []string
-	for _, env := range jobTaskSpec.Properties.Envs {
+	for _, env := range envs {
 		if env.IsCredential {
 			secretEnvVars = append(secretEnvVars, strings.Join([]string{env.Key, env.Value}, "="))
 			continue
@@ -429,16 +530,17 @@ func BuildJobExcutorContext(jobTaskSpec *commonmodels.JobTaskFreestyleSpec, job
 	}
 
 	jobContext := &JobContext{
-		Name:          job.Name,
-		Envs:          envVars,
-		SecretEnvs:    secretEnvVars,
-		WorkflowName:  workflowCtx.WorkflowName,
-		Workspace:     workflowCtx.Workspace,
-		TaskID:        workflowCtx.TaskID,
-		Outputs:       outputs,
-		Steps:         jobTaskSpec.Steps,
-		Paths:         jobTaskSpec.Properties.Paths,
-		ConfigMapName: job.K8sJobName,
+		Name:            job.Name,
+		Envs:            envVars,
+		SecretEnvs:      secretEnvVars,
+		WorkflowName:    workflowCtx.WorkflowName,
+		Workspace:       workflowCtx.Workspace,
+		TaskID:          workflowCtx.TaskID,
+		Outputs:         outputs,
+		Steps:           jobTaskSpec.Steps,
+		Paths:           jobTaskSpec.Properties.Paths,
+		ConfigMapName:   job.K8sJobName,
+		SecretProviders: secretProviders,
 	}
 
 	if job.Infrastructure == setting.JobVMInfrastructure {
@@ -447,13 +549,15 @@ func BuildJobExcutorContext(jobTaskSpec *commonmodels.JobTaskFreestyleSpec, job
 			CacheDirType: jobTaskSpec.Properties.CacheDirType,
 			CacheUserDir: jobTaskSpec.Properties.CacheUserDir,
 		}
+		jobContext.BreakpointBefore = job.BreakpointBefore
+		jobContext.BreakpointAfter = job.BreakpointAfter
 	}
 
-	return jobContext
+	return jobContext, nil
 }
 
 func (c *FreestyleJobCtl) SaveInfo(ctx context.Context) error {
-	return mongodb.NewJobInfoColl().Create(context.TODO(), &commonmodels.JobInfo{
+	info := &commonmodels.JobInfo{
 		Type:                c.job.JobType,
 		WorkflowName:        c.workflowCtx.WorkflowName,
 		WorkflowDisplayName: c.workflowCtx.WorkflowDisplayName,
@@ -463,5 +567,41 @@ func (c *FreestyleJobCtl) SaveInfo(ctx context.Context) error {
 		EndTime:             c.job.EndTime,
 		Duration:            c.job.EndTime - c.job.StartTime,
 		Status:              string(c.job.Status),
-	})
+	}
+	// build jobs record the service and the commit time for the lead time for changes
+	if c.job.JobType == string(config.JobZadigBuild) {
+		for _, env := range c.jobTaskSpec.Properties.Envs {
+			switch env.Key {
+			case "SERVICE_NAME":
+				info.ServiceName = env.Value
+			case "SERVICE_MODULE":
+				info.ServiceModule = env.Value
+			}
+		}
+		info.CommitTime = getEarliestCommitTime(c.jobTaskSpec.Steps)
+	}
+	return mongodb.NewJobInfoColl().Create(context.TODO(), info)
+}
+
+func getEarliestCommitTime(steps []*commonmodels.StepTask) int64 {
+	var commitTime int64
+	for _, stepTask := range steps {
+		if stepTask.StepType != config.StepGit {
+			continue
+		}
+		yamlString, err := yaml.Marshal(stepTask.Spec)
+		if err != nil {
+			continue
+		}
+		gitSpec := &step.StepGitSpec{}
+		if err := yaml.Unmarshal(yamlString, gitSpec); err != nil {
+			continue
+		}
+		for _, repo := range gitSpec.Repos {
+			if repo.CommitTime > 0 && (commitTime == 0 || repo.CommitTime < commitTime) {
+				commitTime = repo.CommitTime
+			}
+		}
+	}
+	return commitTime
 }
@@ -140,5 +140,7 @@ func (c *GrayRollbackJobCtl) SaveInfo(ctx context.Context) error {
 		EndTime:             c.job.EndTime,
 		Duration:            c.job.EndTime - c.job.StartTime,
 		Status:              string(c.job.Status),
+		// the rolled back workload is recorded as the service to match the deploys in DORA metrics
+		ServiceName: c.jobTaskSpec.WorkloadName,
 	})
 }
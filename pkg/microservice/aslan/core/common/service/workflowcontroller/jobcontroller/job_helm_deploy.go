@@ -67,6 +67,9 @@ func NewHelmDeployJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.Wo
 func (c *HelmDeployJobCtl) Clean(ctx context.Context) {}
 
 func (c *HelmDeployJobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.IsProduction, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 
@@ -87,6 +87,9 @@ func (c *IstioReleaseJobCtl) Clean(ctx context.Context) {
 }
 
 func (c *IstioReleaseJobCtl) Run(ctx context.Context) {
+	if !checkClusterDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.ClusterID, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 
@@ -337,12 +340,12 @@ func (c *IstioReleaseJobCtl) Run(ctx context.Context) {
 				},
 			}
 
-			c.Infof("Creating virtual service: %s")
+			c.Infof("Creating virtual service: %s", vsName)
 			c.ack()
 
 			_, err := istioClient.VirtualServices(c.jobTaskSpec.Namespace).Create(context.TODO(), zadigVirtualService, v1.CreateOptions{})
 			if err != nil {
-				c.Errorf("failed to create virtual service: %s, err: %", vsName, err)
+				c.Errorf("failed to create virtual service: %s, err: %v", vsName, err)
 				return
 			}
 		}
@@ -215,5 +215,7 @@ func (c *IstioRollbackJobCtl) SaveInfo(ctx context.Context) error {
 		EndTime:             c.job.EndTime,
 		Duration:            c.job.EndTime - c.job.StartTime,
 		Status:              string(c.job.Status),
+		// the rolled back workload is recorded as the service to match the deploys in DORA metrics
+		ServiceName: c.jobTaskSpec.Targets.WorkloadName,
 	})
 }
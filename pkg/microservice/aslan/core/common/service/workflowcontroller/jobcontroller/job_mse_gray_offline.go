@@ -72,6 +72,9 @@ func NewMseGrayOfflineJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodel
 func (c *MseGrayOfflineJobCtl) Clean(ctx context.Context) {}
 
 func (c *MseGrayOfflineJobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.Production, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 
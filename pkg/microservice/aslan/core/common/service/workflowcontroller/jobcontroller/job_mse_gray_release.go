@@ -76,6 +76,9 @@ func NewMseGrayReleaseJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodel
 func (c *MseGrayReleaseJobCtl) Clean(ctx context.Context) {}
 
 func (c *MseGrayReleaseJobCtl) Run(ctx context.Context) {
+	if !checkDeployFreeze(ctx, c.job, c.workflowCtx, c.jobTaskSpec.Production, c.logger, c.ack) {
+		return
+	}
 	c.job.Status = config.StatusRunning
 	c.ack()
 
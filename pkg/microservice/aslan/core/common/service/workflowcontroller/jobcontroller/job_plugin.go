@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	crClient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +34,7 @@ import (
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/pkg/setting"
 	krkubeclient "github.com/koderover/zadig/pkg/tool/kube/client"
+	"github.com/koderover/zadig/pkg/tool/kube/getter"
 	"github.com/koderover/zadig/pkg/tool/kube/updater"
 )
 
@@ -89,9 +91,35 @@ func (c *PluginJobCtl) Run(ctx context.Context) {
 	c.complete(ctx)
 }
 
-func (c *PluginJobCtl) run(ctx context.Context) error {
-	// get kube client
-	hubServerAddr := config.HubServerAddress()
+// Resume reattaches to the kubernetes job of the plugin which was still running when aslan restarted.
+func (c *PluginJobCtl) Resume(ctx context.Context) {
+	c.prepare(ctx)
+	if c.job.K8sJobName == "" {
+		logError(c.job, "k8s job name not found, cannot resume the job", c.logger)
+		return
+	}
+	if err := c.initKubeClients(); err != nil {
+		return
+	}
+	jobLabel := &JobLabel{
+		JobType: string(c.job.JobType),
+		JobName: c.job.K8sJobName,
+	}
+	jobs, err := getter.ListJobs(c.jobTaskSpec.Properties.Namespace, labels.Set(getJobLabels(jobLabel)).AsSelector(), c.kubeclient)
+	if err != nil {
+		logError(c.job, fmt.Sprintf("list job %s error: %v", c.job.K8sJobName, err), c.logger)
+		return
+	}
+	if len(jobs) == 0 {
+		logError(c.job, fmt.Sprintf("job %s not found, cannot resume the job", c.job.K8sJobName), c.logger)
+		return
+	}
+	c.logger.Infof("resume job %s, k8s job name: %s", c.job.Name, c.job.K8sJobName)
+	c.wait(ctx)
+	c.complete(ctx)
+}
+
+func (c *PluginJobCtl) initKubeClients() error {
 	switch c.jobTaskSpec.Properties.ClusterID {
 	case setting.LocalClusterID:
 		c.jobTaskSpec.Properties.Namespace = zadigconfig.Namespace()
@@ -102,7 +130,7 @@ func (c *PluginJobCtl) run(ctx context.Context) error {
 	default:
 		c.jobTaskSpec.Properties.Namespace = setting.AttachedClusterNamespace
 
-		crClient, clientset, restConfig, apiServer, err := GetK8sClients(hubServerAddr, c.jobTaskSpec.Properties.ClusterID)
+		crClient, clientset, restConfig, apiServer, err := GetK8sClients(config.HubServerAddress(), c.jobTaskSpec.Properties.ClusterID)
 		if err != nil {
 			logError(c.job, err.Error(), c.logger)
 			return err
@@ -112,6 +140,13 @@ func (c *PluginJobCtl) run(ctx context.Context) error {
 		c.restConfig = restConfig
 		c.apiServer = apiServer
 	}
+	return nil
+}
+
+func (c *PluginJobCtl) run(ctx context.Context) error {
+	if err := c.initKubeClients(); err != nil {
+		return err
+	}
 
 	jobLabel := &JobLabel{
 		JobType: string(c.job.JobType),
@@ -151,7 +186,7 @@ func (c *PluginJobCtl) run(ctx context.Context) error {
 func (c *PluginJobCtl) wait(ctx context.Context) {
 	var err error
 	timeout := time.After(time.Duration(c.jobTaskSpec.Properties.Timeout) * time.Minute)
-	c.job.Status, err = waitJobStart(ctx, c.jobTaskSpec.Properties.Namespace, c.job.K8sJobName, c.kubeclient, c.apiServer, timeout, c.logger)
+	c.job.Status, err = waitJobStart(ctx, c.jobTaskSpec.Properties.Namespace, c.job.K8sJobName, c.kubeclient, c.apiServer, timeout, podScheduledObserver(c.job, c.workflowCtx), c.logger)
 	if err != nil {
 		c.logger.Errorf("wait job start error: %v", err)
 	}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/tool/prometheus"
+)
+
+type PrometheusCheckJobCtl struct {
+	job         *commonmodels.JobTask
+	workflowCtx *commonmodels.WorkflowTaskCtx
+	logger      *zap.SugaredLogger
+	jobTaskSpec *commonmodels.JobTaskPrometheusCheckSpec
+	ack         func()
+}
+
+func NewPrometheusCheckJobCtl(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, ack func(), logger *zap.SugaredLogger) *PrometheusCheckJobCtl {
+	jobTaskSpec := &commonmodels.JobTaskPrometheusCheckSpec{}
+	if err := commonmodels.IToi(job.Spec, jobTaskSpec); err != nil {
+		logger.Error(err)
+	}
+	job.Spec = jobTaskSpec
+	return &PrometheusCheckJobCtl{
+		job:         job,
+		workflowCtx: workflowCtx,
+		logger:      logger,
+		ack:         ack,
+		jobTaskSpec: jobTaskSpec,
+	}
+}
+
+func (c *PrometheusCheckJobCtl) Clean(ctx context.Context) {}
+
+func (c *PrometheusCheckJobCtl) Run(ctx context.Context) {
+	c.job.Status = config.StatusRunning
+	c.ack()
+
+	info, err := mongodb.NewObservabilityColl().GetByID(context.Background(), c.jobTaskSpec.ID)
+	if err != nil {
+		logError(c.job, fmt.Sprintf("get observability info error: %v", err), c.logger)
+		return
+	}
+	client := prometheus.NewClient(info.Host, info.ApiKey)
+
+	interval := time.Duration(c.jobTaskSpec.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	timeout := time.After(time.Duration(c.jobTaskSpec.BakeTime) * time.Minute)
+	for _, metric := range c.jobTaskSpec.Metrics {
+		metric.Status = StatusChecking
+	}
+	c.ack()
+
+	for {
+		failedMetric := c.analyze(client)
+		c.ack()
+		if failedMetric != nil {
+			c.job.Status = config.StatusFailed
+			c.job.Error = fmt.Sprintf("metric %s failed %d rounds of analysis", failedMetric.Name, failedMetric.Failures)
+			c.logger.Error(c.job.Error)
+			c.rollback(ctx)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			c.job.Status = config.StatusCancelled
+			return
+		case <-timeout:
+			for _, metric := range c.jobTaskSpec.Metrics {
+				metric.Status = StatusNormal
+			}
+			c.job.Status = config.StatusPassed
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// analyze runs a round of queries of all metrics, it returns the first metric which fails more than the failure limit
+func (c *PrometheusCheckJobCtl) analyze(client *prometheus.Client) *commonmodels.PrometheusMetric {
+	var failedMetric *commonmodels.PrometheusMetric
+	now := time.Now()
+	for _, metric := range c.jobTaskSpec.Metrics {
+		passed, err := checkPrometheusMetric(client, metric, now)
+		if err != nil {
+			metric.Error = err.Error()
+		} else {
+			metric.Error = ""
+		}
+		if !passed {
+			metric.Failures++
+			c.logger.Warnf("metric %s failed, value: %v, target: %v, error: %v", metric.Name, metric.Value, metric.Target, err)
+		}
+		if metric.Failures > c.jobTaskSpec.FailureLimit {
+			metric.Status = StatusAbnormal
+			if failedMetric == nil {
+				failedMetric = metric
+			}
+		}
+	}
+	return failedMetric
+}
+
+// checkPrometheusMetric queries the metric and its baseline, a metric without data fails the round
+func checkPrometheusMetric(client *prometheus.Client, metric *commonmodels.PrometheusMetric, t time.Time) (bool, error) {
+	value, err := client.QueryValue(metric.Query, t)
+	if err != nil {
+		return false, fmt.Errorf("query %s error: %v", metric.Query, err)
+	}
+	metric.Value = value
+
+	target := metric.Threshold
+	if metric.BaselineQuery != "" {
+		baseline, err := client.QueryValue(metric.BaselineQuery, t)
+		if err != nil {
+			return false, fmt.Errorf("query baseline %s error: %v", metric.BaselineQuery, err)
+		}
+		switch metric.Operator {
+		case prometheus.OperatorLessThan, prometheus.OperatorLessOrEqual:
+			target = baseline * (1 + metric.Tolerance/100)
+		default:
+			target = baseline * (1 - metric.Tolerance/100)
+		}
+	}
+	metric.Target = target
+
+	return prometheus.Compare(value, metric.Operator, target)
+}
+
+// rollback runs the rollback job tasks after the analysis failed, the job stays failed whatever the rollback result is.
+func (c *PrometheusCheckJobCtl) rollback(ctx context.Context) {
+	for _, rollbackJob := range c.jobTaskSpec.RollbackJobs {
+		c.logger.Infof("analysis failed, start rollback job: %s", rollbackJob.Name)
+		// the rollback jobs are not run by runJob, render the global variables as it does
+		renderGlobalVariables(rollbackJob, c.workflowCtx, c.logger)
+		rollbackJob.Status = config.StatusPrepare
+		rollbackJob.StartTime = time.Now().Unix()
+		c.ack()
+
+		jobCtl := initJobCtl(rollbackJob, c.workflowCtx, c.logger, c.ack)
+		jobCtl.Run(ctx)
+		rollbackJob.EndTime = time.Now().Unix()
+		c.ack()
+		if err := jobCtl.SaveInfo(ctx); err != nil {
+			c.logger.Errorf("update rollback job info: %s into db error: %v", rollbackJob.Name, err)
+		}
+
+		c.job.Error = fmt.Sprintf("%s, rollback job %s %s", c.job.Error, rollbackJob.Name, rollbackJob.Status)
+		if rollbackJob.Status != config.StatusPassed {
+			return
+		}
+	}
+}
+
+func (c *PrometheusCheckJobCtl) SaveInfo(ctx context.Context) error {
+	return mongodb.NewJobInfoColl().Create(context.TODO(), &commonmodels.JobInfo{
+		Type:                c.job.JobType,
+		WorkflowName:        c.workflowCtx.WorkflowName,
+		WorkflowDisplayName: c.workflowCtx.WorkflowDisplayName,
+		TaskID:              c.workflowCtx.TaskID,
+		ProductName:         c.workflowCtx.ProjectName,
+		StartTime:           c.job.StartTime,
+		EndTime:             c.job.EndTime,
+		Duration:            c.job.EndTime - c.job.StartTime,
+		Status:              string(c.job.Status),
+	})
+}
@@ -18,17 +18,13 @@ package jobcontroller
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
-	"net/url"
 
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/tool/sqlclient"
 )
 
 type SQLJobCtl struct {
@@ -68,14 +64,8 @@ func (c *SQLJobCtl) Run(ctx context.Context) {
 	}
 	c.dbInfo = info
 
-	switch info.Type {
-	case config.DBInstanceTypeMySQL, config.DBInstanceTypeMariaDB:
-		if err := c.ExecMySQLStatement(); err != nil {
-			logError(c.job, err.Error(), c.logger)
-			return
-		}
-	default:
-		logError(c.job, "invalid db type", c.logger)
+	if err := c.execStatements(ctx); err != nil {
+		logError(c.job, err.Error(), c.logger)
 		return
 	}
 
@@ -83,20 +73,30 @@ func (c *SQLJobCtl) Run(ctx context.Context) {
 	return
 }
 
-func (c *SQLJobCtl) ExecMySQLStatement() error {
+// execStatements executes the SQL statement by statement, the result of every statement is saved in the job spec.
+func (c *SQLJobCtl) execStatements(ctx context.Context) error {
 	info := c.dbInfo
-	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8&multiStatements=true", info.Username, url.QueryEscape(info.Password), info.Host, info.Port))
-	if err != nil {
-		return errors.Errorf("connect db error: %v", err)
-	}
-	defer db.Close()
+	results, err := sqlclient.ExecScript(ctx, &sqlclient.Config{
+		Type:     string(info.Type),
+		Host:     info.Host,
+		Port:     info.Port,
+		Username: info.Username,
+		Password: info.Password,
+		Database: info.Database,
+	}, c.jobTaskSpec.SQL, c.jobTaskSpec.Transaction)
 
-	// 插入示例
-	_, err = db.Exec(c.jobTaskSpec.SQL)
-	if err != nil {
-		return errors.Errorf("exec SQL error: %v", err)
+	c.jobTaskSpec.Results = make([]*commonmodels.SQLExecResult, 0, len(results))
+	for _, result := range results {
+		c.jobTaskSpec.Results = append(c.jobTaskSpec.Results, &commonmodels.SQLExecResult{
+			SQL:          result.SQL,
+			Status:       string(result.Status),
+			RowsAffected: result.RowsAffected,
+			Error:        result.Error,
+			Duration:     result.Duration,
+		})
 	}
-	return nil
+	c.ack()
+	return err
 }
 
 func (c *SQLJobCtl) SaveInfo(ctx context.Context) error {
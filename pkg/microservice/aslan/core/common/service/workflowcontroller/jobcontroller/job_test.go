@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestRenderGlobalVariables(t *testing.T) {
+	globalContext := map[string]string{"{{.job.deploy.envName}}": "dev\n"}
+	workflowCtx := &commonmodels.WorkflowTaskCtx{
+		GlobalContextEach: func(f func(k, v string) bool) {
+			for k, v := range globalContext {
+				if !f(k, v) {
+					return
+				}
+			}
+		},
+	}
+	job := &commonmodels.JobTask{Name: "rollback", Spec: map[string]interface{}{"env": "{{.job.deploy.envName}}"}}
+	renderGlobalVariables(job, workflowCtx, nil)
+	assert.Equal(t, map[string]interface{}{"env": "dev"}, job.Spec)
+}
+
+func TestJobInterrupted(t *testing.T) {
+	tests := []struct {
+		status config.Status
+		want   bool
+	}{
+		{config.StatusPrepare, true},
+		{config.StatusRunning, true},
+		{config.StatusCreated, true},
+		{config.StatusWaitingApprove, true},
+		{config.StatusPassed, false},
+		{config.StatusFailed, false},
+		{config.StatusCancelled, false},
+		{config.StatusSkipped, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, jobInterrupted(tt.status), string(tt.status))
+	}
+}
+
+func TestResumePolicy(t *testing.T) {
+	tests := []struct {
+		jobType config.JobType
+		want    jobResumePolicy
+	}{
+		{config.JobZadigBuild, jobResumeReattach},
+		{config.JobZadigTesting, jobResumeReattach},
+		{config.JobZadigScanning, jobResumeReattach},
+		{config.JobFreestyle, jobResumeReattach},
+		{config.JobPlugin, jobResumeReattach},
+		{config.JobZadigDeploy, jobResumeRerun},
+		{config.JobZadigHelmDeploy, jobResumeRerun},
+		{config.JobCustomDeploy, jobResumeRerun},
+		{config.JobK8sPatch, jobResumeRerun},
+		{config.JobSQL, jobResumeFail},
+		{config.JobJenkins, jobResumeFail},
+		{config.JobWorkflowTrigger, jobResumeFail},
+		{config.JobK8sBlueGreenRelease, jobResumeFail},
+		{config.JobIstioRelease, jobResumeFail},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, resumePolicy(string(tt.jobType)), string(tt.jobType))
+	}
+}
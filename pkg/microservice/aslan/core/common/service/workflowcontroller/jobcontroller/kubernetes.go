@@ -503,6 +503,7 @@ func buildJob(jobType, jobImage, jobName, clusterID, currentNamespace string, re
 			SubPath:   jobTaskSpec.Properties.Cache.NFSProperties.Subpath,
 		})
 	}
+	setDaemonlessBuilders(job, jobTaskSpec.Steps, workflowCtx.Workspace)
 	ensureVolumeMounts(job)
 	return job, nil
 }
@@ -641,12 +642,29 @@ func getEnvs(configMapMountDir string, jobTaskSpec *commonmodels.JobTaskFreestyl
 		Value: path.Join(configMapMountDir, "job-config.xml"),
 	})
 
-	if !jobTaskSpec.Properties.UseHostDockerDaemon {
+	// the jobs building without the docker daemon have no docker host
+	if !jobTaskSpec.Properties.UseHostDockerDaemon && jobTaskSpec.Properties.DockerHost != "" {
 		ret = append(ret, corev1.EnvVar{
 			Name:  setting.DockerHost,
 			Value: jobTaskSpec.Properties.DockerHost,
 		})
 	}
+
+	// the vault token resolving the secret references comes from a secret in the namespace of the job,
+	// the job executor logs in vault with the kubernetes auth method if the secret does not exist
+	if config.SecretVaultTokenSecret() != "" && hasSecretReferences(jobTaskSpec.Properties.Envs) {
+		optional := true
+		ret = append(ret, corev1.EnvVar{
+			Name: setting.ENVSecretVaultToken,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: config.SecretVaultTokenSecret()},
+					Key:                  "token",
+					Optional:             &optional,
+				},
+			},
+		})
+	}
 	return ret
 }
 
@@ -799,7 +817,7 @@ func int64Ptr(i int64) *int64 { return &i }
 
 func WaitPlainJobEnd(ctx context.Context, taskTimeout int, namespace, jobName string, kubeClient crClient.Client, apiServer crClient.Reader, xl *zap.SugaredLogger) config.Status {
 	timeout := time.After(time.Duration(taskTimeout) * time.Minute)
-	status, err := waitJobStart(ctx, namespace, jobName, kubeClient, apiServer, timeout, xl)
+	status, err := waitJobStart(ctx, namespace, jobName, kubeClient, apiServer, timeout, nil, xl)
 	if err != nil {
 		xl.Errorf("wait job start error: %v", err)
 	}
@@ -839,7 +857,9 @@ func waitPlainJobEnd(ctx context.Context, taskTimeout int, timeout <-chan time.T
 	}
 }
 
-func waitJobStart(ctx context.Context, namespace, jobName string, kubeClient crClient.Client, apiReader client.Reader, timeout <-chan time.Time, xl *zap.SugaredLogger) (config.Status, error) {
+// waitJobStart waits for the pod of the job to leave the pending phase, scheduled is called with
+// the scheduling latency of the pod once it starts, it can be nil.
+func waitJobStart(ctx context.Context, namespace, jobName string, kubeClient crClient.Client, apiReader client.Reader, timeout <-chan time.Time, scheduled func(latency time.Duration), xl *zap.SugaredLogger) (config.Status, error) {
 	xl.Infof("wait job to start: %s/%s", namespace, jobName)
 	xl.Infof("Timeout of preparing Pod: %s.", 120*time.Second)
 	waitPodReadyTimeout := time.After(120 * time.Second)
@@ -878,6 +898,9 @@ func waitJobStart(ctx context.Context, namespace, jobName string, kubeClient crC
 					}
 					if pod.Status.Phase != corev1.PodPending {
 						xl.Infof("waitJobStart: pod status %s namespace:%s, jobName:%s podList num %d", pod.Status.Phase, namespace, jobName, len(podList))
+						if latency, ok := podSchedulingLatency(pod); ok && scheduled != nil {
+							scheduled(latency)
+						}
 						return config.StatusRunning, nil
 					}
 					// if pod is still pending afer 2 minutes, check pod events if is failed already
@@ -1027,7 +1050,7 @@ func getJobOutputFromTerminalMsg(namespace, containerName string, jobTask *commo
 			}
 		}
 	}
-	writeOutputs(outputs, jobTask.Key, workflowCtx)
+	writeOutputs(outputs, jobTask, workflowCtx)
 	return nil
 }
 
@@ -1046,11 +1069,11 @@ func getJobOutputFromConfigMap(namespace, containerName string, jobTask *commonm
 		return errors.Wrap(err, "unmarshal outputs")
 	}
 
-	writeOutputs(outputs, jobTask.Key, workflowCtx)
+	writeOutputs(outputs, jobTask, workflowCtx)
 	return nil
 }
 
-func writeOutputs(outputs []*job.JobOutput, outputKey string, workflowCtx *commonmodels.WorkflowTaskCtx) {
+func writeOutputs(outputs []*job.JobOutput, jobTask *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx) {
 	// write jobs output info to globalcontext so other job can use like this {{.job.jobKey.output.outputName}}
 	outputsMap := make(map[string]*job.JobOutput)
 	for _, output := range outputs {
@@ -1061,9 +1084,20 @@ func writeOutputs(outputs []*job.JobOutput, outputKey string, workflowCtx *commo
 			tag.Value = getTagFromImageName(image.Value)
 		}
 	}
-	for _, output := range outputsMap {
-		workflowCtx.GlobalContextSet(job.GetJobOutputKey(outputKey, output.Name), output.Value)
+	for _, outputKey := range jobOutputKeys(jobTask) {
+		for _, output := range outputsMap {
+			workflowCtx.GlobalContextSet(job.GetJobOutputKey(outputKey, output.Name), output.Value)
+		}
+	}
+}
+
+// jobOutputKeys returns the keys the outputs of the job task are written to, the first combination
+// of a matrix job also writes its outputs to the key of the job before the matrix expansion.
+func jobOutputKeys(jobTask *commonmodels.JobTask) []string {
+	if jobTask.MatrixIndex == 1 && jobTask.MatrixOriginKey != "" {
+		return []string{jobTask.Key, jobTask.MatrixOriginKey}
 	}
+	return []string{jobTask.Key}
 }
 
 func getTagFromImageName(imageName string) string {
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/tool/metrics"
+)
+
+func observeJobDuration(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx) {
+	if job.StartTime == 0 {
+		return
+	}
+	metrics.ObserveWorkflowJobDuration(workflowCtx.ProjectName, workflowCtx.WorkflowName, job.JobType, string(job.Status), job.EndTime-job.StartTime)
+}
+
+// podScheduledObserver returns the callback of waitJobStart which records the scheduling latency of the job pod
+func podScheduledObserver(job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx) func(latency time.Duration) {
+	return func(latency time.Duration) {
+		metrics.ObserveWorkflowJobPodScheduling(workflowCtx.ProjectName, workflowCtx.WorkflowName, job.JobType, latency.Seconds())
+	}
+}
+
+// podSchedulingLatency returns the time from the creation of the pod to the transition of its PodScheduled condition
+func podSchedulingLatency(pod *corev1.Pod) (time.Duration, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Sub(pod.CreationTimestamp.Time), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/tool/metrics"
+)
+
+func TestPodSchedulingLatency(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod := func(conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+			Status:     corev1.PodStatus{Conditions: conditions},
+		}
+	}
+	condition := func(conditionType corev1.PodConditionType, status corev1.ConditionStatus, after time.Duration) corev1.PodCondition {
+		return corev1.PodCondition{Type: conditionType, Status: status, LastTransitionTime: metav1.NewTime(created.Add(after))}
+	}
+
+	tests := []struct {
+		name      string
+		pod       *corev1.Pod
+		latency   time.Duration
+		scheduled bool
+	}{
+		{
+			name: "scheduled",
+			pod: pod(
+				condition(corev1.PodInitialized, corev1.ConditionTrue, 5*time.Second),
+				condition(corev1.PodScheduled, corev1.ConditionTrue, 3*time.Second),
+			),
+			latency:   3 * time.Second,
+			scheduled: true,
+		},
+		{
+			name:      "not scheduled yet",
+			pod:       pod(condition(corev1.PodScheduled, corev1.ConditionFalse, 10*time.Second)),
+			scheduled: false,
+		},
+		{
+			name:      "no conditions",
+			pod:       pod(),
+			scheduled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latency, scheduled := podSchedulingLatency(tt.pod)
+			assert.Equal(t, tt.scheduled, scheduled)
+			assert.Equal(t, tt.latency, latency)
+		})
+	}
+}
+
+func TestPodScheduledObserver(t *testing.T) {
+	job := &commonmodels.JobTask{JobType: "freestyle"}
+	workflowCtx := &commonmodels.WorkflowTaskCtx{ProjectName: "project", WorkflowName: "test-pod-scheduled-observer"}
+
+	before := testutil.CollectAndCount(metrics.WorkflowJobPodScheduling)
+	podScheduledObserver(job, workflowCtx)(2 * time.Second)
+	assert.Equal(t, before+1, testutil.CollectAndCount(metrics.WorkflowJobPodScheduling))
+}
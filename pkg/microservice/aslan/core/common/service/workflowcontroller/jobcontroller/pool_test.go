@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/types/job"
+)
+
+func TestPoolMatrixLimit(t *testing.T) {
+	jobs := []*commonmodels.JobTask{
+		{Name: "matrix-1", OriginName: "matrix", MatrixMaxParallel: 1},
+		{Name: "matrix-2", OriginName: "matrix", MatrixMaxParallel: 1},
+		{Name: "matrix-3", OriginName: "matrix", MatrixMaxParallel: 1},
+		{Name: "other", OriginName: "other"},
+	}
+
+	otherStarted := make(chan struct{})
+	var mu sync.Mutex
+	running, maxRunning, matrixRuns := 0, 0, 0
+	defer func(origin func(context.Context, *commonmodels.JobTask, *commonmodels.WorkflowTaskCtx, *zap.SugaredLogger, func())) {
+		runPoolJob = origin
+	}(runPoolJob)
+	runPoolJob = func(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+		if job.OriginName == "other" {
+			close(otherStarted)
+			return
+		}
+		mu.Lock()
+		running++
+		matrixRuns++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		first := matrixRuns == 1
+		mu.Unlock()
+
+		// the job outside the matrix must get a worker while the matrix waits for its limit,
+		// the first matrix job task only finishes after it started
+		if first {
+			select {
+			case <-otherStarted:
+			case <-time.After(5 * time.Second):
+				t.Error("the job outside the matrix is blocked by the matrix limit")
+			}
+		}
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}
+
+	NewPool(context.Background(), jobs, &commonmodels.WorkflowTaskCtx{}, 2, zap.NewNop().Sugar(), func() {}).Run()
+
+	assert.Equal(t, 3, matrixRuns)
+	assert.Equal(t, 1, maxRunning)
+}
+
+func TestWriteOutputs(t *testing.T) {
+	outputs := []*job.JobOutput{{Name: "VERSION", Value: "1.0"}}
+
+	tests := []struct {
+		name    string
+		jobTask *commonmodels.JobTask
+		want    map[string]string
+	}{
+		{
+			name:    "job without matrix",
+			jobTask: &commonmodels.JobTask{Key: "build.svc.module"},
+			want: map[string]string{
+				"{{.job.build.svc.module.output.VERSION}}": "1.0",
+			},
+		},
+		{
+			name:    "first combination of the matrix",
+			jobTask: &commonmodels.JobTask{Key: "build.svc.module-matrix-1", MatrixIndex: 1, MatrixOriginKey: "build.svc.module"},
+			want: map[string]string{
+				"{{.job.build.svc.module-matrix-1.output.VERSION}}": "1.0",
+				"{{.job.build.svc.module.output.VERSION}}":          "1.0",
+			},
+		},
+		{
+			name:    "other combinations of the matrix",
+			jobTask: &commonmodels.JobTask{Key: "build.svc.module-matrix-2", MatrixIndex: 2, MatrixOriginKey: "build.svc.module"},
+			want: map[string]string{
+				"{{.job.build.svc.module-matrix-2.output.VERSION}}": "1.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			workflowCtx := &commonmodels.WorkflowTaskCtx{GlobalContextSet: func(key, value string) { got[key] = value }}
+			writeOutputs(outputs, tt.jobTask, workflowCtx)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
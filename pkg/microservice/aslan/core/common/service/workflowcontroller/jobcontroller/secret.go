@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"fmt"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/kube"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/secret"
+)
+
+// checkSecretEnvs returns a copy of the envs with the secret references marked as credentials so that
+// the resolved secrets are masked in the job log, and the provider config if any reference is found.
+// Only the references are saved in the job context, they are resolved by the job executor or the vm agent
+// at run time. The envs of the task are not modified.
+func checkSecretEnvs(projectName, infrastructure string, envs []*commonmodels.KeyVal) ([]*commonmodels.KeyVal, *secret.Config, error) {
+	var cfg *secret.Config
+	resp := make([]*commonmodels.KeyVal, 0, len(envs))
+	for _, env := range envs {
+		if env == nil || !secret.IsReference(env.Value) {
+			resp = append(resp, env)
+			continue
+		}
+		if cfg == nil {
+			var err error
+			if cfg, err = kube.SecretProviderConfig(projectName); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := cfg.Check(env.Value); err != nil {
+			return nil, nil, fmt.Errorf("env %s: %s", env.Key, err)
+		}
+		if infrastructure == setting.JobVMInfrastructure {
+			if err := secret.CheckVMReferences(env.Value); err != nil {
+				return nil, nil, fmt.Errorf("env %s: %s", env.Key, err)
+			}
+		}
+		reference := *env
+		reference.IsCredential = true
+		resp = append(resp, &reference)
+	}
+	return resp, cfg, nil
+}
+
+// hasSecretReferences returns whether any of the envs is a secret reference
+func hasSecretReferences(envs []*commonmodels.KeyVal) bool {
+	for _, env := range envs {
+		if env != nil && secret.IsReference(env.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/setting"
+)
+
+func setSecretProviders(t *testing.T) {
+	viper.Set(setting.ENVSecretFileRoot, "/secrets")
+	viper.Set(setting.ENVSecretKubernetesEnabled, true)
+	viper.Set(setting.ENVSecretAllowlist, `{"demo": {"file": ["app"], "kubernetes": ["zadig"]}}`)
+	t.Cleanup(func() {
+		viper.Set(setting.ENVSecretFileRoot, "")
+		viper.Set(setting.ENVSecretKubernetesEnabled, false)
+		viper.Set(setting.ENVSecretAllowlist, "")
+		viper.Set(setting.ENVSecretVaultTokenSecret, "")
+	})
+}
+
+func TestCheckSecretEnvs(t *testing.T) {
+	setSecretProviders(t)
+
+	envs := []*commonmodels.KeyVal{
+		{Key: "PLAIN", Value: "value"},
+		{Key: "TOKEN", Value: "secret://file/app/token"},
+		{Key: "DB_PASSWORD", Value: "secret://kubernetes/zadig/db#password"},
+	}
+
+	t.Run("kubernetes job", func(t *testing.T) {
+		ast := require.New(t)
+		resp, cfg, err := checkSecretEnvs("demo", setting.JobK8sInfrastructure, envs)
+		ast.NoError(err)
+		ast.NotNil(cfg)
+		ast.Equal("/secrets", cfg.FileRoot)
+		ast.Len(resp, 3)
+		ast.False(resp[0].IsCredential)
+		ast.True(resp[1].IsCredential)
+		ast.True(resp[2].IsCredential)
+		// the references are resolved by the job executor, the envs of the task are kept
+		ast.Equal("secret://file/app/token", resp[1].Value)
+		ast.False(envs[1].IsCredential)
+	})
+
+	t.Run("vm job can't read kubernetes secrets", func(t *testing.T) {
+		_, _, err := checkSecretEnvs("demo", setting.JobVMInfrastructure, envs)
+		assert.ErrorContains(t, err, "env DB_PASSWORD")
+		_, _, err = checkSecretEnvs("demo", setting.JobVMInfrastructure, envs[:2])
+		assert.NoError(t, err)
+	})
+
+	t.Run("reference out of the allowlist of the project", func(t *testing.T) {
+		_, _, err := checkSecretEnvs("other", setting.JobK8sInfrastructure, envs)
+		assert.Error(t, err)
+	})
+
+	t.Run("no reference", func(t *testing.T) {
+		_, cfg, err := checkSecretEnvs("demo", setting.JobVMInfrastructure, envs[:1])
+		assert.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestGetEnvsVaultToken(t *testing.T) {
+	setSecretProviders(t)
+	spec := &commonmodels.JobTaskFreestyleSpec{
+		Properties: commonmodels.JobProperties{
+			Envs: []*commonmodels.KeyVal{{Key: "TOKEN", Value: "secret://vault/kv/app#token"}},
+		},
+	}
+
+	for _, env := range getEnvs("/zadig", spec) {
+		assert.NotEqual(t, setting.ENVSecretVaultToken, env.Name)
+	}
+
+	viper.Set(setting.ENVSecretVaultTokenSecret, "vault-token")
+	var found bool
+	for _, env := range getEnvs("/zadig", spec) {
+		if env.Name != setting.ENVSecretVaultToken {
+			continue
+		}
+		found = true
+		assert.Empty(t, env.Value)
+		assert.Equal(t, "vault-token", env.ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, "token", env.ValueFrom.SecretKeyRef.Key)
+	}
+	assert.True(t, found)
+
+	spec.Properties.Envs = []*commonmodels.KeyVal{{Key: "PLAIN", Value: "value"}}
+	for _, env := range getEnvs("/zadig", spec) {
+		assert.NotEqual(t, setting.ENVSecretVaultToken, env.Name)
+	}
+}
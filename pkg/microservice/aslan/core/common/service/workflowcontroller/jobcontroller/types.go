@@ -20,6 +20,7 @@ import (
 	"gopkg.in/yaml.v2"
 	
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/tool/secret"
 	"github.com/koderover/zadig/pkg/types"
 )
 
@@ -45,6 +46,11 @@ type JobContext struct {
 	Outputs []string                 `yaml:"outputs"`
 	// used to vm job
 	Cache *JobCacheConfig `yaml:"cache"`
+	// BreakpointBefore and BreakpointAfter are used to debug vm job, the breakpoints of kubernetes job are set by the booting script
+	BreakpointBefore bool `yaml:"breakpoint_before"`
+	BreakpointAfter  bool `yaml:"breakpoint_after"`
+	// SecretProviders resolves the secret references in SecretEnvs at run time, it is nil if there is no reference
+	SecretProviders *secret.Config `yaml:"secret_providers,omitempty"`
 }
 
 func (j *JobContext) Decode(job string) error {
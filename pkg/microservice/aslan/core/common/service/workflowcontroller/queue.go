@@ -117,8 +117,26 @@ func InitQueue() error {
 		return err
 	}
 
+	jobConcurrency := 1
+	sysSetting, err := commonrepo.NewSystemSettingColl().Get()
+	if err != nil {
+		log.Errorf("get system stettings error: %v", err)
+	} else {
+		jobConcurrency = int(sysSetting.BuildConcurrency)
+	}
+
 	for _, task := range tasks {
-		// 如果 Queue 重新初始化, 取消所有 running tasks
+		switch task.Status {
+		// tasks still waiting in the queue will be sent by WorfklowTaskSender
+		case config.StatusWaiting, config.StatusBlocked:
+			continue
+		// tasks already started are resumed from the last checkpoint saved in the task
+		case config.StatusQueued, config.StatusPrepare, config.StatusRunning, config.StatusWaitingApprove:
+			log.Infof("resume workflow task %s:%d, status: %s", task.WorkflowName, task.TaskID, task.Status)
+			go NewWorkflowController(task, log).Resume(context.Background(), jobConcurrency)
+			continue
+		}
+		// 如果 Queue 重新初始化, 取消其它未完成的 tasks
 		if err := CancelWorkflowTask(setting.DefaultTaskRevoker, task.WorkflowName, task.TaskID, log); err != nil {
 			log.Errorf("[CancelRunningTask] error: %v", err)
 			continue
@@ -36,6 +36,7 @@ import (
 	"github.com/koderover/zadig/pkg/tool/dingtalk"
 	"github.com/koderover/zadig/pkg/tool/lark"
 	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/metrics"
 )
 
 type StageCtl interface {
@@ -58,7 +59,10 @@ func runStage(ctx context.Context, stage *commonmodels.StageTask, workflowCtx *c
 		logger.Infof("finish stage: %s,status: %s", stage.Name, stage.Status)
 		ack()
 	}()
-	stage.StartTime = time.Now().Unix()
+	// keep the start time of the stage when workflow task be resumed
+	if stage.StartTime == 0 {
+		stage.StartTime = time.Now().Unix()
+	}
 	ack()
 	stageCtl := NewCustomStageCtl(stage, workflowCtx, logger, ack)
 
@@ -99,7 +103,11 @@ func waitForApprove(ctx context.Context, stage *commonmodels.StageTask, workflow
 	if stage.Approval.Status == config.StatusPassed {
 		return nil
 	}
-	stage.Approval.StartTime = time.Now().Unix()
+	resumed := approvalResumed(stage, workflowCtx)
+	// keep the start time of the approval when workflow task be resumed, the timeout is counted from it
+	if !resumed {
+		stage.Approval.StartTime = time.Now().Unix()
+	}
 	defer func() {
 		stage.Approval.EndTime = time.Now().Unix()
 
@@ -109,6 +117,7 @@ func waitForApprove(ctx context.Context, stage *commonmodels.StageTask, workflow
 		} else {
 			stage.Approval.Status = stage.Status
 		}
+		metrics.ObserveWorkflowApprovalWait(workflowCtx.ProjectName, workflowCtx.WorkflowName, string(stage.Approval.Type), string(stage.Approval.Status), stage.Approval.EndTime-stage.Approval.StartTime)
 	}()
 	// workflowCtx.SetStatus contain ack() function, so we don't need to call ack() here
 	stage.Status = config.StatusWaitingApprove
@@ -118,7 +127,7 @@ func waitForApprove(ctx context.Context, stage *commonmodels.StageTask, workflow
 
 	switch stage.Approval.Type {
 	case config.NativeApproval:
-		err = waitForNativeApprove(ctx, stage, workflowCtx, logger, ack)
+		err = waitForNativeApprove(ctx, stage, workflowCtx, resumed, logger, ack)
 	case config.LarkApproval:
 		err = waitForLarkApprove(ctx, stage, workflowCtx, logger, ack)
 	case config.DingTalkApproval:
@@ -129,7 +138,7 @@ func waitForApprove(ctx context.Context, stage *commonmodels.StageTask, workflow
 	return err
 }
 
-func waitForNativeApprove(ctx context.Context, stage *commonmodels.StageTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) error {
+func waitForNativeApprove(ctx context.Context, stage *commonmodels.StageTask, workflowCtx *commonmodels.WorkflowTaskCtx, resumed bool, logger *zap.SugaredLogger, ack func()) error {
 	approval := stage.Approval.NativeApproval
 	if approval == nil {
 		return errors.New("waitForApprove: native approval data not found")
@@ -145,11 +154,14 @@ func waitForNativeApprove(ctx context.Context, stage *commonmodels.StageTask, wo
 		approvalservice.GlobalApproveMap.DeleteApproval(approveKey)
 		ack()
 	}()
-	if err := instantmessage.NewWeChatClient().SendWorkflowTaskAproveNotifications(workflowCtx.WorkflowName, workflowCtx.TaskID); err != nil {
-		logger.Errorf("send approve notification failed, error: %v", err)
+	// the approval results are saved in approve users, so a resumed approval only needs to be put back into the approve map
+	if !resumed {
+		if err := instantmessage.NewWeChatClient().SendWorkflowTaskAproveNotifications(workflowCtx.WorkflowName, workflowCtx.TaskID); err != nil {
+			logger.Errorf("send approve notification failed, error: %v", err)
+		}
 	}
 
-	timeout := time.After(time.Duration(approval.Timeout) * time.Minute)
+	timeout := approvalTimeout(stage.Approval.StartTime, approval.Timeout)
 	latestApproveCount := 0
 	for {
 		time.Sleep(1 * time.Second)
@@ -229,21 +241,28 @@ func waitForLarkApprove(ctx context.Context, stage *commonmodels.StageTask, work
 		formContent = fmt.Sprintf("审批发起人: %s\n%s", workflowCtx.WorkflowTaskCreatorUsername, formContent)
 	}
 	log.Infof("waitForLarkApprove: ApproveNodes num %d", len(approval.ApprovalNodes))
-	instance, err := client.CreateApprovalInstance(&lark.CreateApprovalInstanceArgs{
-		ApprovalCode: approvalCode,
-		UserOpenID:   userID,
-		Nodes:        approval.GetLarkApprovalNode(),
-		FormContent:  formContent,
-	})
-	if err != nil {
-		log.Errorf("waitForLarkApprove: create instance failed: %v", err)
-		stage.Status = config.StatusFailed
-		return errors.Wrap(err, "create approval instance")
-	}
-	log.Infof("waitForLarkApprove: create instance success, id %s", instance)
+	instance := approval.InstanceCode
+	if workflowCtx.Resumed && instance != "" {
+		log.Infof("waitForLarkApprove: resume instance %s", instance)
+	} else {
+		instance, err = client.CreateApprovalInstance(&lark.CreateApprovalInstanceArgs{
+			ApprovalCode: approvalCode,
+			UserOpenID:   userID,
+			Nodes:        approval.GetLarkApprovalNode(),
+			FormContent:  formContent,
+		})
+		if err != nil {
+			log.Errorf("waitForLarkApprove: create instance failed: %v", err)
+			stage.Status = config.StatusFailed
+			return errors.Wrap(err, "create approval instance")
+		}
+		log.Infof("waitForLarkApprove: create instance success, id %s", instance)
+		approval.InstanceCode = instance
+		ack()
 
-	if err := instantmessage.NewWeChatClient().SendWorkflowTaskAproveNotifications(workflowCtx.WorkflowName, workflowCtx.TaskID); err != nil {
-		logger.Errorf("send approve notification failed, error: %v", err)
+		if err := instantmessage.NewWeChatClient().SendWorkflowTaskAproveNotifications(workflowCtx.WorkflowName, workflowCtx.TaskID); err != nil {
+			logger.Errorf("send approve notification failed, error: %v", err)
+		}
 	}
 
 	cancelApproval := func() {
@@ -336,7 +355,7 @@ func waitForLarkApprove(ctx context.Context, stage *commonmodels.StageTask, work
 	defer func() {
 		larkservice.RemoveLarkApprovalInstanceManager(instance)
 	}()
-	timeout := time.After(time.Duration(approval.Timeout) * time.Minute)
+	timeout := approvalTimeout(stage.Approval.StartTime, approval.Timeout)
 	for {
 		time.Sleep(1 * time.Second)
 		select {
@@ -422,6 +441,10 @@ func waitForDingTalkApprove(ctx context.Context, stage *commonmodels.StageTask,
 	}
 
 	log.Infof("waitForDingTalkApprove: ApproveNode num %d", len(approval.ApprovalNodes))
+	if workflowCtx.Resumed && approval.InstanceCode != "" {
+		log.Infof("waitForDingTalkApprove: resume instance %s", approval.InstanceCode)
+		return waitForDingTalkApproveResult(ctx, stage, approval, client, approval.InstanceCode, ack)
+	}
 	instanceResp, err := client.CreateApprovalInstance(&dingtalk.CreateApprovalInstanceArgs{
 		ProcessCode:      data.DingTalkDefaultApprovalFormCode,
 		OriginatorUserID: userID,
@@ -447,10 +470,16 @@ func waitForDingTalkApprove(ctx context.Context, stage *commonmodels.StageTask,
 	}
 	instanceID := instanceResp.InstanceID
 	log.Infof("waitForDingTalkApprove: create instance success, id %s", instanceID)
+	approval.InstanceCode = instanceID
+	ack()
 
 	if err := instantmessage.NewWeChatClient().SendWorkflowTaskAproveNotifications(workflowCtx.WorkflowName, workflowCtx.TaskID); err != nil {
 		logger.Errorf("send approve notification failed, error: %v", err)
 	}
+	return waitForDingTalkApproveResult(ctx, stage, approval, client, instanceID, ack)
+}
+
+func waitForDingTalkApproveResult(ctx context.Context, stage *commonmodels.StageTask, approval *commonmodels.DingTalkApproval, client *dingtalk.Client, instanceID string, ack func()) (err error) {
 	defer func() {
 		dingservice.RemoveDingTalkApprovalManager(instanceID)
 	}()
@@ -486,7 +515,7 @@ func waitForDingTalkApprove(ctx context.Context, stage *commonmodels.StageTask,
 		}
 	}
 
-	timeout := time.After(time.Duration(approval.Timeout) * time.Minute)
+	timeout := approvalTimeout(stage.Approval.StartTime, approval.Timeout)
 	for {
 		time.Sleep(1 * time.Second)
 		select {
@@ -549,6 +578,21 @@ func waitForDingTalkApprove(ctx context.Context, stage *commonmodels.StageTask,
 	}
 }
 
+// approvalResumed returns true if the approval of the stage had been started before aslan restarted.
+func approvalResumed(stage *commonmodels.StageTask, workflowCtx *commonmodels.WorkflowTaskCtx) bool {
+	return workflowCtx.Resumed && stage.Approval.StartTime != 0 && stage.Status == config.StatusWaitingApprove
+}
+
+// approvalTimeout returns a channel which fires when the approval started at startTime times out,
+// so a resumed approval will not get a new full timeout window.
+func approvalTimeout(startTime int64, timeoutMinutes int) <-chan time.Time {
+	remaining := time.Duration(timeoutMinutes)*time.Minute - time.Since(time.Unix(startTime, 0))
+	if startTime == 0 || remaining > time.Duration(timeoutMinutes)*time.Minute {
+		remaining = time.Duration(timeoutMinutes) * time.Minute
+	}
+	return time.After(remaining)
+}
+
 func statusFailed(status config.Status) bool {
 	if status == config.StatusCancelled || status == config.StatusFailed || status == config.StatusTimeout || status == config.StatusReject {
 		return true
@@ -564,6 +608,7 @@ func updateStageStatus(ctx context.Context, stage *commonmodels.StageTask) {
 	default:
 	}
 	statusMap := map[config.Status]int{
+		config.StatusReject:    5,
 		config.StatusCancelled: 4,
 		config.StatusTimeout:   3,
 		config.StatusFailed:    2,
@@ -577,7 +622,12 @@ func updateStageStatus(ctx context.Context, stage *commonmodels.StageTask) {
 	jobStatus := make([]int, len(stage.Jobs))
 
 	for i, j := range stage.Jobs {
-		statusCode, ok := statusMap[j.Status]
+		status := j.Status
+		// the blocked status is only kept on the job blocked by a deploy freeze, the stage is failed
+		if status == config.StatusBlocked {
+			status = config.StatusFailed
+		}
+		statusCode, ok := statusMap[status]
 		if !ok {
 			statusCode = -1
 		}
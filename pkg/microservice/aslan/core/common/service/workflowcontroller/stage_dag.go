@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowcontroller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/workflowcontroller/jobcontroller"
+)
+
+// runDAGJob runs a single job task of the dependency graph, it is replaced in tests.
+var runDAGJob = jobcontroller.RunJob
+
+type dagJob struct {
+	job      *commonmodels.JobTask
+	stageIdx int
+	deps     []*dagJob
+	started  bool
+	finished bool
+}
+
+type dagStage struct {
+	stage            *commonmodels.StageTask
+	jobs             []*dagJob
+	started          bool
+	finished         bool
+	approvalStarted  bool
+	approvalFinished bool
+	approvalErr      error
+}
+
+// hasJobDependencies returns true if any job in the stages declares depends_on,
+// workflows without it keep running stage by stage.
+func hasJobDependencies(stages []*commonmodels.StageTask) bool {
+	for _, stage := range stages {
+		for _, job := range stage.Jobs {
+			if len(job.DependsOn) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildDAG builds the job dependency graph of the stages. A job task with depends_on waits for all job tasks
+// generated from the jobs it depends on, otherwise it waits for the previous job in a serial stage,
+// or all jobs in the previous stage, which keeps the stage order as the implicit default.
+func buildDAG(stages []*commonmodels.StageTask) []*dagStage {
+	resp := make([]*dagStage, 0, len(stages))
+	originJobs := make(map[string][]*dagJob)
+	for i, stage := range stages {
+		ds := &dagStage{stage: stage}
+		for _, job := range stage.Jobs {
+			dj := &dagJob{job: job, stageIdx: i}
+			ds.jobs = append(ds.jobs, dj)
+			originJobs[job.OriginName] = append(originJobs[job.OriginName], dj)
+		}
+		resp = append(resp, ds)
+	}
+
+	for i, ds := range resp {
+		for j, dj := range ds.jobs {
+			switch {
+			case len(dj.job.DependsOn) > 0:
+				// jobs skipped when creating the task have no job task, the dependency on them is ignored.
+				for _, dep := range dj.job.DependsOn {
+					dj.deps = append(dj.deps, originJobs[dep]...)
+				}
+			case !ds.stage.Parallel && j > 0:
+				dj.deps = []*dagJob{ds.jobs[j-1]}
+			case i > 0:
+				dj.deps = resp[i-1].jobs
+			}
+		}
+	}
+	return resp
+}
+
+// RunStagesDAG runs the jobs of all stages by their dependencies, a job is scheduled as soon as
+// all the jobs it depends on are passed. Stage approvals are still required before the jobs in the stage start.
+func RunStagesDAG(ctx context.Context, stages []*commonmodels.StageTask, workflowCtx *commonmodels.WorkflowTaskCtx, concurrency int, logger *zap.SugaredLogger, ack func()) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	dagStages := buildDAG(stages)
+	jobDone := make(chan *dagJob)
+	approvalDone := make(chan *dagStage)
+	running, approving := 0, 0
+	// the running job tasks of every matrix job, which are limited by the max parallel of the matrix
+	matrixRunning := make(map[string]int)
+
+	for {
+		cancelled := false
+		select {
+		case <-ctx.Done():
+			cancelled = true
+		default:
+		}
+
+		progress := true
+		for progress {
+			progress = false
+			for _, ds := range dagStages {
+				for _, dj := range ds.jobs {
+					if dj.started || dj.finished {
+						continue
+					}
+					// should skip passed job when workflow task be restarted
+					if dj.job.Status == config.StatusPassed {
+						dj.started, dj.finished = true, true
+						progress = true
+						continue
+					}
+					ready, blocked := dagJobReady(dj)
+					if blocked || cancelled || (ds.approvalFinished && ds.approvalErr != nil) {
+						// the job will never run, leave its status unchanged like jobs in stages not reached.
+						dj.finished = true
+						progress = true
+						continue
+					}
+					if !ready {
+						continue
+					}
+					if !ds.started {
+						ds.started = true
+						ds.stage.Status = config.StatusRunning
+						if ds.stage.StartTime == 0 {
+							ds.stage.StartTime = time.Now().Unix()
+						}
+						logger.Infof("start stage: %s,status: %s", ds.stage.Name, ds.stage.Status)
+						ack()
+					}
+					if !ds.approvalFinished {
+						if !ds.approvalStarted {
+							ds.approvalStarted = true
+							approving++
+							go func(ds *dagStage) {
+								ds.approvalErr = waitForApprove(ctx, ds.stage, workflowCtx, logger, ack)
+								approvalDone <- ds
+							}(ds)
+						}
+						continue
+					}
+					if running >= concurrency {
+						continue
+					}
+					if dj.job.MatrixMaxParallel > 0 && matrixRunning[dj.job.OriginName] >= dj.job.MatrixMaxParallel {
+						continue
+					}
+					dj.started = true
+					running++
+					matrixRunning[dj.job.OriginName]++
+					go func(dj *dagJob) {
+						runDAGJob(ctx, dj.job, workflowCtx, logger, ack)
+						jobDone <- dj
+					}(dj)
+				}
+				finishDAGStage(ctx, ds, workflowCtx, logger, ack)
+			}
+		}
+
+		if running == 0 && approving == 0 {
+			return
+		}
+		select {
+		case dj := <-jobDone:
+			running--
+			matrixRunning[dj.job.OriginName]--
+			dj.finished = true
+		case ds := <-approvalDone:
+			approving--
+			ds.approvalFinished = true
+			if ds.approvalErr != nil {
+				ds.stage.Error = ds.approvalErr.Error()
+				logger.Errorf("stage: %s approval failed, status: %s error: %s", ds.stage.Name, ds.stage.Status, ds.stage.Error)
+				ack()
+			}
+		}
+	}
+}
+
+// dagJobReady returns whether all dependencies of the job are passed,
+// or whether the job is blocked by a dependency which will never pass.
+func dagJobReady(dj *dagJob) (ready, blocked bool) {
+	ready = true
+	for _, dep := range dj.deps {
+		if !dep.finished {
+			ready = false
+			continue
+		}
+		if dep.job.Status != config.StatusPassed && dep.job.Status != config.StatusSkipped {
+			return false, true
+		}
+	}
+	return ready, false
+}
+
+func finishDAGStage(ctx context.Context, ds *dagStage, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+	if ds.finished || !ds.started {
+		return
+	}
+	for _, dj := range ds.jobs {
+		if !dj.finished {
+			return
+		}
+	}
+	if ds.approvalStarted && !ds.approvalFinished {
+		return
+	}
+	ds.finished = true
+	if ds.approvalErr == nil {
+		updateStageStatus(ctx, ds.stage)
+		NewCustomStageCtl(ds.stage, workflowCtx, logger, ack).AfterRun()
+	}
+	ds.stage.EndTime = time.Now().Unix()
+	logger.Infof("finish stage: %s,status: %s", ds.stage.Name, ds.stage.Status)
+	ack()
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowcontroller
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+// fakeDAGRunner records the order the jobs are run in and sets their status from the given results.
+type fakeDAGRunner struct {
+	mu      sync.Mutex
+	order   []string
+	results map[string]config.Status
+}
+
+func (r *fakeDAGRunner) run(ctx context.Context, job *commonmodels.JobTask, workflowCtx *commonmodels.WorkflowTaskCtx, logger *zap.SugaredLogger, ack func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, job.Name)
+	job.Status = config.StatusPassed
+	if status, ok := r.results[job.Name]; ok {
+		job.Status = status
+	}
+}
+
+func (r *fakeDAGRunner) index(name string) int {
+	for i, n := range r.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func runTestDAG(t *testing.T, stages []*commonmodels.StageTask, results map[string]config.Status) *fakeDAGRunner {
+	runner := &fakeDAGRunner{results: results}
+	origin := runDAGJob
+	runDAGJob = runner.run
+	defer func() { runDAGJob = origin }()
+
+	globalContext := map[string]string{}
+	workflowCtx := &commonmodels.WorkflowTaskCtx{
+		GlobalContextGetAll: func() map[string]string { return globalContext },
+		GlobalContextGet: func(key string) (string, bool) {
+			v, ok := globalContext[key]
+			return v, ok
+		},
+		GlobalContextSet: func(key, value string) { globalContext[key] = value },
+	}
+	RunStagesDAG(context.Background(), stages, workflowCtx, 4, zap.NewNop().Sugar(), func() {})
+	return runner
+}
+
+func testDAGJob(name string, dependsOn ...string) *commonmodels.JobTask {
+	return &commonmodels.JobTask{Name: name, OriginName: name, DependsOn: dependsOn}
+}
+
+func TestRunStagesDAGOrder(t *testing.T) {
+	stages := []*commonmodels.StageTask{
+		{Name: "build", Parallel: true, Jobs: []*commonmodels.JobTask{testDAGJob("build-a"), testDAGJob("build-b")}},
+		{Name: "deploy", Parallel: true, Jobs: []*commonmodels.JobTask{testDAGJob("deploy-a", "build-a"), testDAGJob("deploy-b", "build-b")}},
+		{Name: "test", Jobs: []*commonmodels.JobTask{testDAGJob("test-1"), testDAGJob("test-2")}},
+	}
+	runner := runTestDAG(t, stages, nil)
+
+	assert.Len(t, runner.order, 6)
+	assert.Less(t, runner.index("build-a"), runner.index("deploy-a"))
+	assert.Less(t, runner.index("build-b"), runner.index("deploy-b"))
+	// jobs without depends_on wait for the previous stage, and for the previous job in a serial stage
+	assert.Less(t, runner.index("deploy-a"), runner.index("test-1"))
+	assert.Less(t, runner.index("deploy-b"), runner.index("test-1"))
+	assert.Less(t, runner.index("test-1"), runner.index("test-2"))
+	for _, stage := range stages {
+		assert.Equal(t, config.StatusPassed, stage.Status, stage.Name)
+	}
+}
+
+func TestRunStagesDAGFailure(t *testing.T) {
+	stages := []*commonmodels.StageTask{
+		{Name: "build", Parallel: true, Jobs: []*commonmodels.JobTask{testDAGJob("build-a"), testDAGJob("build-b")}},
+		{Name: "deploy", Parallel: true, Jobs: []*commonmodels.JobTask{testDAGJob("deploy-a", "build-a"), testDAGJob("deploy-b", "build-b")}},
+		{Name: "test", Jobs: []*commonmodels.JobTask{testDAGJob("test")}},
+	}
+	runner := runTestDAG(t, stages, map[string]config.Status{"build-a": config.StatusFailed})
+
+	// the failure only blocks the jobs depending on the failed job, directly or by stage order
+	assert.Equal(t, -1, runner.index("deploy-a"))
+	assert.NotEqual(t, -1, runner.index("deploy-b"))
+	assert.Equal(t, -1, runner.index("test"))
+	assert.Equal(t, config.Status(""), stages[1].Jobs[0].Status)
+	assert.Equal(t, config.StatusFailed, stages[0].Status)
+	assert.Equal(t, config.Status(""), stages[2].Status)
+}
+
+func TestRunStagesDAGSkippedDependency(t *testing.T) {
+	stages := []*commonmodels.StageTask{
+		{Name: "build", Jobs: []*commonmodels.JobTask{testDAGJob("build")}},
+		{Name: "deploy", Jobs: []*commonmodels.JobTask{testDAGJob("deploy", "build")}},
+	}
+	runner := runTestDAG(t, stages, map[string]config.Status{"build": config.StatusSkipped})
+
+	assert.NotEqual(t, -1, runner.index("deploy"))
+}
+
+func TestRunStagesDAGCycle(t *testing.T) {
+	// cycles are rejected by the workflow lint, the runtime must still return instead of waiting forever
+	stages := []*commonmodels.StageTask{
+		{Name: "stage", Parallel: true, Jobs: []*commonmodels.JobTask{testDAGJob("a", "b"), testDAGJob("b", "a"), testDAGJob("c")}},
+	}
+	runner := runTestDAG(t, stages, nil)
+
+	assert.Equal(t, []string{"c"}, runner.order)
+}
+
+func TestRunStagesDAGResumedPassedJob(t *testing.T) {
+	build := testDAGJob("build")
+	build.Status = config.StatusPassed
+	stages := []*commonmodels.StageTask{
+		{Name: "build", Jobs: []*commonmodels.JobTask{build}},
+		{Name: "deploy", Jobs: []*commonmodels.JobTask{testDAGJob("deploy", "build")}},
+	}
+	runner := runTestDAG(t, stages, nil)
+
+	assert.Equal(t, []string{"deploy"}, runner.order)
+}
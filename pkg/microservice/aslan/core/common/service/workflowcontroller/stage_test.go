@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowcontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestApprovalResumed(t *testing.T) {
+	tests := []struct {
+		name      string
+		resumed   bool
+		status    config.Status
+		startTime int64
+		want      bool
+	}{
+		{name: "not resumed", resumed: false, status: config.StatusWaitingApprove, startTime: 100, want: false},
+		{name: "resumed while waiting for approval", resumed: true, status: config.StatusWaitingApprove, startTime: 100, want: true},
+		{name: "resumed before approval started", resumed: true, status: "", startTime: 0, want: false},
+		{name: "resumed after approval finished", resumed: true, status: config.StatusRunning, startTime: 100, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := &commonmodels.StageTask{
+				Status:   tt.status,
+				Approval: &commonmodels.Approval{StartTime: tt.startTime},
+			}
+			assert.Equal(t, tt.want, approvalResumed(stage, &commonmodels.WorkflowTaskCtx{Resumed: tt.resumed}))
+		})
+	}
+}
+
+func TestApprovalTimeout(t *testing.T) {
+	// the approval started long before the restart has already timed out
+	select {
+	case <-approvalTimeout(time.Now().Add(-2*time.Minute).Unix(), 1):
+	case <-time.After(time.Second):
+		t.Fatal("resumed approval should time out at once")
+	}
+
+	// a new approval gets the full timeout window
+	select {
+	case <-approvalTimeout(time.Now().Unix(), 1):
+		t.Fatal("new approval should not time out at once")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -82,11 +82,15 @@ func instantiateStepCtl(step *commonmodels.StepTask, workflowCtx *commonmodels.W
 	case config.StepArchive:
 		stepCtl, err = NewArchiveCtl(step, logger)
 	case config.StepJunitReport:
-		stepCtl, err = NewJunitReportCtl(step, logger)
+		stepCtl, err = NewJunitReportCtl(step, workflowCtx, jobName, logger)
 	case config.StepTarArchive:
 		stepCtl, err = NewTarArchiveCtl(step, logger)
 	case config.StepSonarCheck:
 		stepCtl, err = NewSonarCheckCtl(step, logger)
+	case config.StepSarifReport:
+		stepCtl, err = NewSarifReportCtl(step, workflowCtx, jobName, logger)
+	case config.StepCoverageReport:
+		stepCtl, err = NewCoverageReportCtl(step, workflowCtx, jobName, logger)
 	case config.StepDistributeImage:
 		stepCtl, err = NewDistributeCtl(step, workflowCtx, jobName, logger)
 	case config.StepDebugBefore, config.StepDebugAfter:
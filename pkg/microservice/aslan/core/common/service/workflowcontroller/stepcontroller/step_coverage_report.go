@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stepcontroller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/scmnotify"
+	"github.com/koderover/zadig/pkg/tool/coverage"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+// maxCommentFiles is the max number of changed files listed in the pull request comment
+const maxCommentFiles = 20
+
+type coverageReportCtl struct {
+	step               *commonmodels.StepTask
+	coverageReportSpec *step.StepCoverageReportSpec
+	workflowCtx        *commonmodels.WorkflowTaskCtx
+	jobName            string
+	log                *zap.SugaredLogger
+}
+
+func NewCoverageReportCtl(stepTask *commonmodels.StepTask, workflowCtx *commonmodels.WorkflowTaskCtx, jobName string, log *zap.SugaredLogger) (*coverageReportCtl, error) {
+	yamlString, err := yaml.Marshal(stepTask.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal coverage report spec error: %v", err)
+	}
+	coverageReportSpec := &step.StepCoverageReportSpec{}
+	if err := yaml.Unmarshal(yamlString, &coverageReportSpec); err != nil {
+		return nil, fmt.Errorf("unmarshal coverage report spec error: %v", err)
+	}
+	stepTask.Spec = coverageReportSpec
+	return &coverageReportCtl{coverageReportSpec: coverageReportSpec, workflowCtx: workflowCtx, jobName: jobName, log: log, step: stepTask}, nil
+}
+
+func (s *coverageReportCtl) PreRun(ctx context.Context) error {
+	if s.coverageReportSpec.Format != "" && !coverage.ValidFormat(s.coverageReportSpec.Format) {
+		return fmt.Errorf("invalid coverage format: %s", s.coverageReportSpec.Format)
+	}
+	if s.coverageReportSpec.S3Storage == nil {
+		modelS3, err := commonrepo.NewS3StorageColl().FindDefault()
+		if err != nil {
+			return err
+		}
+		s.coverageReportSpec.S3Storage = modelS3toS3(modelS3)
+	}
+	s.step.Spec = s.coverageReportSpec
+	return nil
+}
+
+func (s *coverageReportCtl) AfterRun(ctx context.Context) error {
+	if s.coverageReportSpec.TestName == "" || s.coverageReportSpec.S3DestDir == "" || s.coverageReportSpec.FileName == "" {
+		return nil
+	}
+
+	reportPath := path.Join(s.coverageReportSpec.S3DestDir, s.coverageReportSpec.FileName)
+	report := &coverage.Report{}
+	if err := downloadJSONReport(reportPath, report); err != nil {
+		s.log.Errorf("download coverage report %s error: %v", reportPath, err)
+		return err
+	}
+
+	record := &commonmodels.CoverageRecord{
+		ProjectName:   s.coverageReportSpec.ProjectName,
+		TestName:      s.coverageReportSpec.TestName,
+		ServiceName:   s.coverageReportSpec.ServiceName,
+		ServiceModule: s.coverageReportSpec.ServiceModule,
+		WorkflowName:  s.workflowCtx.WorkflowName,
+		TaskID:        s.workflowCtx.TaskID,
+		JobName:       s.jobName,
+		Branch:        s.coverageReportSpec.Branch,
+		CommitID:      s.coverageReportSpec.CommitID,
+		Formats:       report.Formats,
+		ReportPath:    reportPath,
+		Summary:       report.Summary,
+		Diff:          report.Diff,
+		CreateTime:    time.Now().Unix(),
+	}
+	if err := commonrepo.NewCoverageRecordColl().Upsert(record); err != nil {
+		s.log.Errorf("save coverage of job %s error: %v", s.jobName, err)
+		return err
+	}
+
+	if report.Diff == nil {
+		return nil
+	}
+	task, err := commonrepo.NewworkflowTaskv4Coll().Find(s.workflowCtx.WorkflowName, s.workflowCtx.TaskID)
+	if err != nil {
+		s.log.Errorf("find workflow task %s:%d error: %v", s.workflowCtx.WorkflowName, s.workflowCtx.TaskID, err)
+		return err
+	}
+	if err := scmnotify.NewService().CommentForWorkflowV4(task, coverageComment(s.coverageReportSpec, report), s.log); err != nil {
+		s.log.Warnf("failed to comment coverage of job %s, error: %v", s.jobName, err)
+	}
+	return nil
+}
+
+func coverageComment(spec *step.StepCoverageReportSpec, report *coverage.Report) string {
+	var b strings.Builder
+	name := spec.TestName
+	if spec.ServiceName != "" {
+		name = fmt.Sprintf("%s(%s)", spec.ServiceModule, spec.ServiceName)
+	}
+	fmt.Fprintf(&b, "**%s** coverage\n\n", name)
+	b.WriteString("| | Covered | Total | Coverage | Minimum |\n|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| Line | %d | %d | %.2f%% | %s |\n", report.Summary.LinesCovered, report.Summary.LinesTotal, report.Summary.LineRate, coverageMinimum(spec.MinLineCoverage))
+	if report.Summary.BranchesTotal > 0 {
+		fmt.Fprintf(&b, "| Branch | %d | %d | %.2f%% | %s |\n", report.Summary.BranchesCovered, report.Summary.BranchesTotal, report.Summary.BranchRate, coverageMinimum(spec.MinBranchCoverage))
+	}
+	fmt.Fprintf(&b, "| Diff | %d | %d | %.2f%% | %s |\n", report.Diff.LinesCovered, report.Diff.LinesTotal, report.Diff.LineRate, coverageMinimum(spec.MinDiffCoverage))
+	if len(report.DiffFiles) == 0 {
+		b.WriteString("\nNone of the changed files are in the coverage reports.\n")
+		return b.String()
+	}
+
+	b.WriteString("\n| Changed file | Covered | Total | Coverage |\n|---|---|---|---|\n")
+	for i, file := range report.DiffFiles {
+		if i == maxCommentFiles {
+			fmt.Fprintf(&b, "\n%d more changed file(s) are not listed.\n", len(report.DiffFiles)-maxCommentFiles)
+			break
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %.2f%% |\n", file.Path, file.Summary.LinesCovered, file.Summary.LinesTotal, file.Summary.LineRate)
+	}
+	return b.String()
+}
+
+func coverageMinimum(rate float64) string {
+	if rate <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", rate)
+}
@@ -27,6 +27,7 @@ import (
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
@@ -41,10 +42,12 @@ import (
 type junitReportCtl struct {
 	step            *commonmodels.StepTask
 	junitReportSpec *step.StepJunitReportSpec
+	workflowCtx     *commonmodels.WorkflowTaskCtx
+	jobName         string
 	log             *zap.SugaredLogger
 }
 
-func NewJunitReportCtl(stepTask *commonmodels.StepTask, log *zap.SugaredLogger) (*junitReportCtl, error) {
+func NewJunitReportCtl(stepTask *commonmodels.StepTask, workflowCtx *commonmodels.WorkflowTaskCtx, jobName string, log *zap.SugaredLogger) (*junitReportCtl, error) {
 	yamlString, err := yaml.Marshal(stepTask.Spec)
 	if err != nil {
 		return nil, fmt.Errorf("marshal junit report spec error: %v", err)
@@ -54,7 +57,7 @@ func NewJunitReportCtl(stepTask *commonmodels.StepTask, log *zap.SugaredLogger)
 		return nil, fmt.Errorf("unmarshal junit report spec error: %v", err)
 	}
 	stepTask.Spec = junitReportSpec
-	return &junitReportCtl{junitReportSpec: junitReportSpec, log: log, step: stepTask}, nil
+	return &junitReportCtl{junitReportSpec: junitReportSpec, workflowCtx: workflowCtx, jobName: jobName, log: log, step: stepTask}, nil
 }
 
 func (s *junitReportCtl) PreRun(ctx context.Context) error {
@@ -65,6 +68,16 @@ func (s *junitReportCtl) PreRun(ctx context.Context) error {
 		}
 		s.junitReportSpec.S3Storage = modelS3toS3(modelS3)
 	}
+	if s.junitReportSpec.TestName != "" {
+		quarantined, err := commonrepo.NewTestCaseQuarantineColl().List(s.workflowCtx.ProjectName, s.junitReportSpec.TestName)
+		if err != nil {
+			return fmt.Errorf("list quarantined test cases of %s error: %v", s.junitReportSpec.TestName, err)
+		}
+		s.junitReportSpec.Quarantined = make([]string, 0, len(quarantined))
+		for _, tc := range quarantined {
+			s.junitReportSpec.Quarantined = append(s.junitReportSpec.Quarantined, tc.CaseName)
+		}
+	}
 	s.step.Spec = s.junitReportSpec
 	return nil
 }
@@ -131,5 +144,34 @@ func (s *junitReportCtl) AfterRun(ctx context.Context) error {
 		testTaskStat.UpdateTime = time.Now().Unix()
 		_ = commonrepo.NewTestTaskStatColl().Update(testTaskStat)
 	}
+
+	if err := commonrepo.NewTestCaseResultColl().BulkCreate(s.testCaseResults(testReport)); err != nil {
+		log.Errorf("save test case results of job %s error: %v", s.jobName, err)
+		return err
+	}
 	return nil
 }
+
+func (s *junitReportCtl) testCaseResults(testReport *commonmodels.TestSuite) []*commonmodels.TestCaseResult {
+	quarantined := sets.NewString(s.junitReportSpec.Quarantined...)
+	now := time.Now().Unix()
+	resp := make([]*commonmodels.TestCaseResult, 0, len(testReport.TestCases))
+	for _, tc := range testReport.TestCases {
+		resp = append(resp, &commonmodels.TestCaseResult{
+			ProjectName:  s.workflowCtx.ProjectName,
+			TestName:     s.junitReportSpec.TestName,
+			WorkflowName: s.workflowCtx.WorkflowName,
+			TaskID:       s.workflowCtx.TaskID,
+			JobName:      s.jobName,
+			CaseName:     tc.Key(),
+			Status:       tc.Status(),
+			Message:      tc.Message(),
+			Duration:     tc.Time,
+			CommitID:     s.junitReportSpec.CommitID,
+			Branch:       s.junitReportSpec.Branch,
+			Quarantined:  quarantined.Has(tc.Key()) || quarantined.Has(tc.Name),
+			CreateTime:   now,
+		})
+	}
+	return resp
+}
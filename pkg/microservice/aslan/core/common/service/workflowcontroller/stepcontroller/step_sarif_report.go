@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stepcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/s3"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/scmnotify"
+	"github.com/koderover/zadig/pkg/setting"
+	s3tool "github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/tool/sarif"
+	"github.com/koderover/zadig/pkg/types/step"
+	"github.com/koderover/zadig/pkg/util"
+)
+
+// maxCommentFindings is the max number of findings listed in the pull request comment
+const maxCommentFindings = 20
+
+type sarifReportCtl struct {
+	step            *commonmodels.StepTask
+	sarifReportSpec *step.StepSarifReportSpec
+	workflowCtx     *commonmodels.WorkflowTaskCtx
+	jobName         string
+	log             *zap.SugaredLogger
+}
+
+func NewSarifReportCtl(stepTask *commonmodels.StepTask, workflowCtx *commonmodels.WorkflowTaskCtx, jobName string, log *zap.SugaredLogger) (*sarifReportCtl, error) {
+	yamlString, err := yaml.Marshal(stepTask.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sarif report spec error: %v", err)
+	}
+	sarifReportSpec := &step.StepSarifReportSpec{}
+	if err := yaml.Unmarshal(yamlString, &sarifReportSpec); err != nil {
+		return nil, fmt.Errorf("unmarshal sarif report spec error: %v", err)
+	}
+	stepTask.Spec = sarifReportSpec
+	return &sarifReportCtl{sarifReportSpec: sarifReportSpec, workflowCtx: workflowCtx, jobName: jobName, log: log, step: stepTask}, nil
+}
+
+func (s *sarifReportCtl) PreRun(ctx context.Context) error {
+	if s.sarifReportSpec.SeverityThreshold != "" && !sarif.ValidSeverity(s.sarifReportSpec.SeverityThreshold) {
+		return fmt.Errorf("invalid sarif severity threshold: %s", s.sarifReportSpec.SeverityThreshold)
+	}
+	if s.sarifReportSpec.S3Storage == nil {
+		modelS3, err := commonrepo.NewS3StorageColl().FindDefault()
+		if err != nil {
+			return err
+		}
+		s.sarifReportSpec.S3Storage = modelS3toS3(modelS3)
+	}
+	if s.sarifReportSpec.ScanningName != "" {
+		baseline, err := commonrepo.NewScanningFindingColl().FindBaseline(s.sarifReportSpec.ProjectName, s.sarifReportSpec.ScanningName)
+		if err == nil && !(baseline.WorkflowName == s.workflowCtx.WorkflowName && baseline.TaskID == s.workflowCtx.TaskID) {
+			s.sarifReportSpec.BaselinePath = baseline.ReportPath
+		}
+	}
+	s.step.Spec = s.sarifReportSpec
+	return nil
+}
+
+func (s *sarifReportCtl) AfterRun(ctx context.Context) error {
+	if s.sarifReportSpec.ScanningName == "" || s.sarifReportSpec.S3DestDir == "" || s.sarifReportSpec.FileName == "" {
+		return nil
+	}
+
+	reportPath := path.Join(s.sarifReportSpec.S3DestDir, s.sarifReportSpec.FileName)
+	report, err := downloadSarifReport(reportPath)
+	if errors.Is(err, errReportNotFound) {
+		// the scanning step did not run or failed before the report was uploaded, there are no findings to save
+		s.log.Infof("sarif report %s of job %s not found, skip saving the findings", reportPath, s.jobName)
+		return nil
+	}
+	if err != nil {
+		s.log.Errorf("download sarif report %s error: %v", reportPath, err)
+		return err
+	}
+
+	finding := &commonmodels.ScanningFinding{
+		ProjectName:   s.sarifReportSpec.ProjectName,
+		ScanningName:  s.sarifReportSpec.ScanningName,
+		WorkflowName:  s.workflowCtx.WorkflowName,
+		TaskID:        s.workflowCtx.TaskID,
+		JobName:       s.jobName,
+		Branch:        s.sarifReportSpec.Branch,
+		DefaultBranch: s.sarifReportSpec.DefaultBranch,
+		ReportPath:    reportPath,
+		Tools:         report.Tools,
+		Summary:       report.Summary,
+		NewSummary:    report.NewSummary,
+		HasBaseline:   report.HasBaseline,
+		Findings:      capFindings(report.Findings, commonmodels.MaxScanningFindings),
+		FindingTotal:  len(report.Findings),
+		CreateTime:    time.Now().Unix(),
+	}
+	if err := commonrepo.NewScanningFindingColl().Upsert(finding); err != nil {
+		s.log.Errorf("save scanning findings of job %s error: %v", s.jobName, err)
+		return err
+	}
+
+	if s.sarifReportSpec.DefaultBranch {
+		return nil
+	}
+	task, err := commonrepo.NewworkflowTaskv4Coll().Find(s.workflowCtx.WorkflowName, s.workflowCtx.TaskID)
+	if err != nil {
+		s.log.Errorf("find workflow task %s:%d error: %v", s.workflowCtx.WorkflowName, s.workflowCtx.TaskID, err)
+		return err
+	}
+	if err := scmnotify.NewService().CommentForWorkflowV4(task, sarifFindingsComment(s.sarifReportSpec, report), s.log); err != nil {
+		s.log.Warnf("failed to comment scanning findings of job %s, error: %v", s.jobName, err)
+	}
+	return nil
+}
+
+// maxFindingMessageLength caps the message of a saved finding, the messages of some scanners embed code snippets
+const maxFindingMessageLength = 1024
+
+// capFindings returns at most max findings with the new ones first, the findings of the report are sorted by
+// severity already. The messages are truncated, the full ones are in the report in the object storage.
+func capFindings(findings []*sarif.Finding, max int) []*sarif.Finding {
+	resp := make([]*sarif.Finding, 0)
+	for _, isNew := range []bool{true, false} {
+		for _, finding := range findings {
+			if len(resp) == max {
+				return resp
+			}
+			if finding.New != isNew {
+				continue
+			}
+			capped := *finding
+			if message := []rune(capped.Message); len(message) > maxFindingMessageLength {
+				capped.Message = string(message[:maxFindingMessageLength]) + "..."
+			}
+			resp = append(resp, &capped)
+		}
+	}
+	return resp
+}
+
+func downloadSarifReport(reportPath string) (*sarif.Report, error) {
+	report := &sarif.Report{}
+	if err := downloadJSONReport(reportPath, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// errReportNotFound is returned if the report was not uploaded to the default storage
+var errReportNotFound = errors.New("report not found")
+
+// downloadJSONReport downloads the report uploaded by a step to the default storage and decodes it
+func downloadJSONReport(reportPath string, report interface{}) error {
+	filename, err := util.GenerateTmpFile()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(filename)
+
+	storage, err := s3.FindDefaultS3()
+	if err != nil {
+		return err
+	}
+	forcedPathStyle := true
+	if storage.Provider == setting.ProviderSourceAli {
+		forcedPathStyle = false
+	}
+	client, err := s3tool.NewClient(storage.Endpoint, storage.Ak, storage.Sk, storage.Region, storage.Insecure, forcedPathStyle)
+	if err != nil {
+		return err
+	}
+	option := &s3tool.DownloadOption{IgnoreNotExistError: true, RetryNum: 3}
+	if err := client.DownloadWithOption(storage.Bucket, storage.GetObjectPath(reportPath), filename, option); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return errReportNotFound
+	}
+	return json.Unmarshal(b, report)
+}
+
+func sarifFindingsComment(spec *step.StepSarifReportSpec, report *sarif.Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** scanning findings\n\n", spec.ScanningName)
+	b.WriteString("| Severity | Total | New |\n|---|---|---|\n")
+	for _, severity := range sarif.Severities {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", severity, report.Summary[severity], report.NewSummary[severity])
+	}
+	if !report.HasBaseline {
+		b.WriteString("\nNo baseline from the default branch yet, all findings are counted as new.\n")
+	}
+
+	newFindings := make([]*sarif.Finding, 0)
+	for _, finding := range report.Findings {
+		if finding.New {
+			newFindings = append(newFindings, finding)
+		}
+	}
+	if len(newFindings) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n| Severity | Rule | Location | Message |\n|---|---|---|---|\n")
+	for i, finding := range newFindings {
+		if i == maxCommentFindings {
+			fmt.Fprintf(&b, "\n%d more new finding(s) are not listed.\n", len(newFindings)-maxCommentFindings)
+			break
+		}
+		message := strings.ReplaceAll(strings.ReplaceAll(finding.Message, "\n", " "), "|", "\\|")
+		fmt.Fprintf(&b, "| %s | %s | %s:%d | %s |\n", finding.Severity, finding.RuleID, finding.File, finding.Line, message)
+	}
+	return b.String()
+}
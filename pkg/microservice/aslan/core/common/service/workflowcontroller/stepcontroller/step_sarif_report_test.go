@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stepcontroller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/tool/sarif"
+)
+
+func TestCapFindings(t *testing.T) {
+	findings := []*sarif.Finding{
+		{RuleID: "a", Severity: sarif.SeverityCritical},
+		{RuleID: "b", Severity: sarif.SeverityHigh, New: true},
+		{RuleID: "c", Severity: sarif.SeverityMedium},
+		{RuleID: "d", Severity: sarif.SeverityLow, New: true, Message: strings.Repeat("扫", maxFindingMessageLength+1)},
+	}
+
+	capped := capFindings(findings, 3)
+	ruleIDs := make([]string, 0, len(capped))
+	for _, finding := range capped {
+		ruleIDs = append(ruleIDs, finding.RuleID)
+	}
+	assert.Equal(t, []string{"b", "d", "a"}, ruleIDs)
+	assert.Equal(t, strings.Repeat("扫", maxFindingMessageLength)+"...", capped[1].Message)
+	// the findings of the report are not modified
+	assert.Len(t, []rune(findings[3].Message), maxFindingMessageLength+1)
+
+	assert.Len(t, capFindings(findings, 10), 4)
+	assert.Empty(t, capFindings(nil, 10))
+}
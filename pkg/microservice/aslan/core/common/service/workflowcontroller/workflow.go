@@ -39,6 +39,7 @@ import (
 	kubeclient "github.com/koderover/zadig/pkg/shared/kube/client"
 	"github.com/koderover/zadig/pkg/tool/kube/updater"
 	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/metrics"
 )
 
 var cancelChannelMap sync.Map
@@ -114,6 +115,16 @@ func (c *workflowCtl) setWorkflowStatus(status config.Status) {
 }
 
 func (c *workflowCtl) Run(ctx context.Context, concurrency int) {
+	c.run(ctx, concurrency, false)
+}
+
+// Resume continues a workflow task which was interrupted by an aslan restart,
+// finished stages and jobs are skipped and running jobs are reattached.
+func (c *workflowCtl) Resume(ctx context.Context, concurrency int) {
+	c.run(ctx, concurrency, true)
+}
+
+func (c *workflowCtl) run(ctx context.Context, concurrency int, resumed bool) {
 	if c.workflowTask.GlobalContext == nil {
 		c.workflowTask.GlobalContext = make(map[string]string)
 	}
@@ -124,13 +135,22 @@ func (c *workflowCtl) Run(ctx context.Context, concurrency int) {
 	addWorkflowTaskInMap(c.workflowTask.WorkflowName, c.workflowTask.TaskID, c.workflowTask, c.ack)
 	defer removeWorkflowTaskInMap(c.workflowTask.WorkflowName, c.workflowTask.TaskID)
 
-	c.workflowTask.Status = config.StatusRunning
-	c.workflowTask.StartTime = time.Now().Unix()
+	if c.workflowTask.Status != config.StatusWaitingApprove || !resumed {
+		c.workflowTask.Status = config.StatusRunning
+	}
+	if !resumed || c.workflowTask.StartTime == 0 {
+		c.workflowTask.StartTime = time.Now().Unix()
+		// a retried task reuses the create time of the first run, its queue wait can not be measured
+		if !c.workflowTask.IsRestart && c.workflowTask.CreateTime > 0 {
+			metrics.ObserveWorkflowTaskQueueWait(c.workflowTask.ProjectName, c.workflowTask.WorkflowName, c.workflowTask.StartTime-c.workflowTask.CreateTime)
+		}
+	}
 	c.ack()
-	c.logger.Infof("start workflow: %s,status: %s", c.workflowTask.WorkflowName, c.workflowTask.Status)
+	c.logger.Infof("start workflow: %s,status: %s,resumed: %v", c.workflowTask.WorkflowName, c.workflowTask.Status, resumed)
 	defer func() {
 		c.workflowTask.EndTime = time.Now().Unix()
 		c.logger.Infof("finish workflow: %s,status: %s", c.workflowTask.WorkflowName, c.workflowTask.Status)
+		metrics.ObserveWorkflowTaskDuration(c.workflowTask.ProjectName, c.workflowTask.WorkflowName, string(c.workflowTask.Status), c.workflowTask.EndTime-c.workflowTask.StartTime)
 		c.ack()
 		// clean share storage after workflow finished
 		go c.CleanShareStorage()
@@ -154,12 +174,14 @@ func (c *workflowCtl) Run(ctx context.Context, concurrency int) {
 		DockerMountDir:              fmt.Sprintf("/tmp/%s/docker/%d", uuid.NewString(), time.Now().Unix()),
 		ConfigMapMountDir:           fmt.Sprintf("/tmp/%s/cm/%d", uuid.NewString(), time.Now().Unix()),
 		WorkflowKeyVals:             c.workflowTask.KeyVals,
+		WorkflowParams:              c.workflowTask.Params,
 		GlobalContextGetAll:         c.getGlobalContextAll,
 		GlobalContextGet:            c.getGlobalContext,
 		GlobalContextSet:            c.setGlobalContext,
 		GlobalContextEach:           c.globalContextEach,
 		ClusterIDAdd:                c.addCluterID,
 		SetStatus:                   c.setWorkflowStatus,
+		Resumed:                     resumed,
 	}
 	defer jobcontroller.CleanWorkflowJobs(ctx, c.workflowTask, workflowCtx, c.logger, c.ack)
 	if err := scmnotify.NewService().UpdateWebhookCommentForWorkflowV4(c.workflowTask, c.logger); err != nil {
@@ -168,7 +190,11 @@ func (c *workflowCtl) Run(ctx context.Context, concurrency int) {
 	if err := scmnotify.NewService().UpdateGitCheckForWorkflowV4(c.workflowTask.WorkflowArgs, c.workflowTask.TaskID, c.logger); err != nil {
 		log.Warnf("Failed to update github check status for custom workflow %s, taskID: %d the error is: %s", c.workflowTask.WorkflowName, c.workflowTask.TaskID, err)
 	}
-	RunStages(ctx, c.workflowTask.Stages, workflowCtx, concurrency, c.logger, c.ack)
+	if hasJobDependencies(c.workflowTask.Stages) {
+		RunStagesDAG(ctx, c.workflowTask.Stages, workflowCtx, concurrency, c.logger, c.ack)
+	} else {
+		RunStages(ctx, c.workflowTask.Stages, workflowCtx, concurrency, c.logger, c.ack)
+	}
 	updateworkflowStatus(c.workflowTask)
 }
 
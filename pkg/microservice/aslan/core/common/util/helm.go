@@ -31,6 +31,7 @@ import (
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	templatemodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models/template"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/command"
 	fsservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/fs"
 	"github.com/koderover/zadig/pkg/setting"
@@ -305,13 +306,46 @@ func ReplaceImage(sourceYaml string, imageValuesMap ...map[string]interface{}) (
 	return string(mergedBs), nil
 }
 
+// GeneHelmRepo generates the repo entry of the chart repo, the credential of the image registry is used
+// if the chart repo is an OCI registry bound to an image registry
 func GeneHelmRepo(chartRepo *commonmodels.HelmRepo) *repo.Entry {
-	return &repo.Entry{
+	entry := &repo.Entry{
 		Name:     chartRepo.RepoName,
 		URL:      chartRepo.URL,
 		Username: chartRepo.Username,
 		Password: chartRepo.Password,
 	}
+	if chartRepo.RegistryID == "" || !helmtool.IsOCIRepo(chartRepo.URL) {
+		return entry
+	}
+
+	reg, err := commonrepo.NewRegistryNamespaceColl().Find(&commonrepo.FindRegOps{ID: chartRepo.RegistryID})
+	if err != nil {
+		log.Warnf("failed to find registry %s of chart repo %s, err: %s", chartRepo.RegistryID, chartRepo.RepoName, err)
+		return entry
+	}
+	if err := SetRegistryRealCredential(reg); err != nil {
+		log.Warnf("failed to get credential of registry %s for chart repo %s, err: %s", reg.RegAddr, chartRepo.RepoName, err)
+		return entry
+	}
+	entry.Username = reg.AccessKey
+	entry.Password = reg.SecretKey
+	// the tls verification is disabled for the registries without tls, the certificate of the others is trusted if set
+	entry.InsecureSkipTLSverify = reg.AdvancedSetting != nil && !reg.AdvancedSetting.TLSEnabled
+	if reg.AdvancedSetting != nil && reg.AdvancedSetting.TLSEnabled && reg.AdvancedSetting.TLSCert != "" {
+		caDir := path.Join(os.TempDir(), "registry-certs")
+		caFile := path.Join(caDir, reg.ID.Hex()+".crt")
+		if err := os.MkdirAll(caDir, 0755); err != nil {
+			log.Warnf("failed to create certificate dir of registry %s for chart repo %s, err: %s", reg.RegAddr, chartRepo.RepoName, err)
+			return entry
+		}
+		if err := os.WriteFile(caFile, []byte(reg.AdvancedSetting.TLSCert), 0644); err != nil {
+			log.Warnf("failed to write certificate of registry %s for chart repo %s, err: %s", reg.RegAddr, chartRepo.RepoName, err)
+			return entry
+		}
+		entry.CAFile = caFile
+	}
+	return entry
 }
 
 func GetValidMatchData(spec *commonmodels.ImagePathSpec) map[string]string {
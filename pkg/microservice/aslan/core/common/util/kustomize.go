@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/kustomize"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+// IsKustomizeService returns true if the service is built from a kustomization
+func IsKustomizeService(svc *commonmodels.Service) bool {
+	return svc.Source == setting.SourceFromKustomize && svc.Kustomize != nil
+}
+
+// BuildKustomizeService builds the kustomization of the service with the overlay of the environment,
+// the files of the service revision are downloaded from object storage if they are not cached locally.
+func BuildKustomizeService(svc *commonmodels.Service, envName string, production bool) (string, error) {
+	if !IsKustomizeService(svc) {
+		return "", fmt.Errorf("service %s is not a kustomize service", svc.ServiceName)
+	}
+
+	base := config.LocalServicePathWithRevision(svc.ProductName, svc.ServiceName, fmt.Sprint(svc.Revision), production)
+	if err := PreloadServiceManifestsByRevision(base, svc, production); err != nil {
+		log.Warnf("failed to get files of revision: %d for service: %s, use latest version", svc.Revision, svc.ServiceName)
+		// use the latest version when it fails to download the specific version
+		base = config.LocalServicePath(svc.ProductName, svc.ServiceName, production)
+		if err = PreLoadServiceManifests(base, svc, production); err != nil {
+			return "", fmt.Errorf("failed to load files of service %s: %w", svc.ServiceName, err)
+		}
+	}
+
+	return kustomize.Build(filepath.Join(base, svc.ServiceName, svc.Kustomize.GetPath(envName)))
+}
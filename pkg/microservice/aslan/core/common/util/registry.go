@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/util"
+)
+
+var expirationTime = 10 * time.Hour
+
+var awsKeyMap sync.Map
+
+type awsKeyWithExpiration struct {
+	AccessKey  string
+	SecretKey  string
+	Expiration int64
+}
+
+func (k *awsKeyWithExpiration) IsExpired() bool {
+	return time.Now().Unix() > k.Expiration
+}
+
+// SetRegistryRealCredential replaces the access key and secret key of the registry with the credential
+// which can be used to login to the registry directly, e.g. the authorization token of AWS ECR
+func SetRegistryRealCredential(reg *commonmodels.RegistryNamespace) error {
+	switch reg.RegProvider {
+	case config.RegistryTypeSWR:
+		reg.SecretKey = util.ComputeHmacSha256(reg.AccessKey, reg.SecretKey)
+		reg.AccessKey = fmt.Sprintf("%s@%s", reg.Region, reg.AccessKey)
+	case config.RegistryTypeAWS:
+		realAK, realSK, err := getAWSRegistryCredential(reg.ID.Hex(), reg.AccessKey, reg.SecretKey, reg.Region)
+		if err != nil {
+			return fmt.Errorf("failed to get keypair from aws, the error is: %s", err)
+		}
+		reg.AccessKey = realAK
+		reg.SecretKey = realSK
+	}
+	return nil
+}
+
+func getAWSRegistryCredential(id, ak, sk, region string) (realAK string, realSK string, err error) {
+	// first we try to get ak/sk from our memory cache
+	obj, ok := awsKeyMap.Load(id)
+	if ok {
+		keypair, ok := obj.(awsKeyWithExpiration)
+		if ok {
+			if !keypair.IsExpired() {
+				return keypair.AccessKey, keypair.SecretKey, nil
+			}
+		}
+	}
+	creds := credentials.NewStaticCredentials(ak, sk, "")
+	config := &aws.Config{
+		Region:      aws.String(region),
+		Credentials: creds,
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return "", "", err
+	}
+	svc := ecr.New(sess)
+	input := &ecr.GetAuthorizationTokenInput{}
+
+	result, err := svc.GetAuthorizationToken(input)
+	if err != nil {
+		return "", "", err
+	}
+	// since the new AWS ECR will give a token that has access to ALL the repository, we use the first token
+	encodedToken := *result.AuthorizationData[0].AuthorizationToken
+	rawDecodedText, err := base64.StdEncoding.DecodeString(encodedToken)
+	if err != nil {
+		return "", "", err
+	}
+	keypair := strings.Split(string(rawDecodedText), ":")
+	if len(keypair) != 2 {
+		return "", "", errors.New("format of keypair is invalid")
+	}
+	// cache the aws ak/sk
+	awsKeyMap.Store(id, awsKeyWithExpiration{
+		AccessKey:  keypair[0],
+		SecretKey:  keypair[1],
+		Expiration: time.Now().Add(expirationTime).Unix(),
+	})
+	return keypair[0], keypair[1], nil
+}
@@ -1486,7 +1486,7 @@ func preDownloadChart(projectName, versionName, chartName string, log *zap.Sugar
 	return filePath, err
 }
 
-func getIndexInfoFromChartRepo(chartRepoName string) (*repo.IndexFile, error) {
+func getIndexInfoFromChartRepo(chartRepoName string, chartNames []string) (*repo.IndexFile, error) {
 	chartRepo, err := getChartRepoData(chartRepoName)
 	if err != nil {
 		return nil, err
@@ -1495,17 +1495,19 @@ func getIndexInfoFromChartRepo(chartRepoName string) (*repo.IndexFile, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create chart repo client")
 	}
-	return hClient.FetchIndexYaml(commonutil.GeneHelmRepo(chartRepo))
+	return hClient.FetchChartsIndex(commonutil.GeneHelmRepo(chartRepo), chartNames)
 }
 
 func fillChartUrl(charts []*DeliveryVersionPayloadChart, chartRepoName string) error {
-	index, err := getIndexInfoFromChartRepo(chartRepoName)
-	if err != nil {
-		return err
-	}
 	chartMap := make(map[string]*DeliveryVersionPayloadChart)
+	chartNames := make([]string, 0, len(charts))
 	for _, chart := range charts {
 		chartMap[chart.ChartName] = chart
+		chartNames = append(chartNames, chart.ChartName)
+	}
+	index, err := getIndexInfoFromChartRepo(chartRepoName, chartNames)
+	if err != nil {
+		return err
 	}
 
 	for name, entries := range index.Entries {
@@ -1531,12 +1533,12 @@ func fillChartUrl(charts []*DeliveryVersionPayloadChart, chartRepoName string) e
 
 func GetChartVersion(chartName, chartRepoName string) ([]*ChartVersionResp, error) {
 
-	index, err := getIndexInfoFromChartRepo(chartRepoName)
+	chartNameList := strings.Split(chartName, ",")
+	index, err := getIndexInfoFromChartRepo(chartRepoName, chartNameList)
 	if err != nil {
 		return nil, err
 	}
 
-	chartNameList := strings.Split(chartName, ",")
 	chartNameSet := sets.NewString(chartNameList...)
 	existedChartSet := sets.NewString()
 
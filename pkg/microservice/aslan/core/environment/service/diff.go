@@ -87,7 +87,7 @@ func GetServiceDiff(envName, productName, serviceName string, log *zap.SugaredLo
 
 	svcRender := serviceInfo.GetServiceRender()
 
-	resp.Current.Yaml, err = kube.RenderServiceYaml(oldService.Yaml, productName, serviceName, svcRender)
+	resp.Current.Yaml, err = kube.RenderServiceTemplate(oldService, envName, productInfo.Production, svcRender)
 	if err != nil {
 		log.Error("failed to RenderServiceYaml, err: %s", err)
 		return nil, err
@@ -104,7 +104,7 @@ func GetServiceDiff(envName, productName, serviceName string, log *zap.SugaredLo
 	svcRender.OverrideYaml.YamlContent = mergedYaml
 	svcRender.OverrideYaml.RenderVariableKVs = mergedServiceVariableKVs
 
-	resp.Latest.Yaml, err = kube.RenderServiceYaml(newService.Yaml, productName, serviceName, svcRender)
+	resp.Latest.Yaml, err = kube.RenderServiceTemplate(newService, envName, productInfo.Production, svcRender)
 	if err != nil {
 		log.Error("failed to RenderServiceYaml, err: %s", err)
 		return nil, err
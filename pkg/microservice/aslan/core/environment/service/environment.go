@@ -17,11 +17,9 @@ limitations under the License.
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"os"
 	"sort"
 	"strings"
@@ -3175,7 +3173,7 @@ func GetEnvConfigs(projectName, envName string, production *bool, logger *zap.Su
 
 	configs := &EnvConfigsArgs{
 		AnalysisConfig:      analysisConfig,
-		NotificationConfigs: notificationConfigs,
+		NotificationConfigs: models.MaskNotificationConfigSecrets(notificationConfigs),
 	}
 	return configs, nil
 }
@@ -3186,10 +3184,11 @@ func UpdateEnvConfigs(projectName, envName string, arg *EnvConfigsArgs, producti
 		Name:       projectName,
 		Production: production,
 	}
-	_, err := commonrepo.NewProductColl().Find(opt)
+	env, err := commonrepo.NewProductColl().Find(opt)
 	if err != nil {
 		return e.ErrUpdateEnvConfigs.AddErr(fmt.Errorf("failed to get environment %s/%s, err: %w", projectName, envName, err))
 	}
+	models.EnsureNotificationConfigSecrets(env.NotificationConfigs, arg.NotificationConfigs)
 
 	_, analyzerMap := analysis.GetAnalyzerMap()
 	for _, resourceType := range arg.AnalysisConfig.ResourceTypes {
@@ -3492,42 +3491,15 @@ func EnvAnalysisNotification(projectName, envName, result string, configs []*com
 			return nil
 		}
 
-		title, content, larkCard, err := getNotificationContent(projectName, envName, result, imnotify.IMNotifyType(config.WebHookType))
-		if err != nil {
-			return fmt.Errorf("failed to get notification content, err: %w", err)
-		}
-
-		imnotifyClient := imnotify.NewIMNotifyClient()
-
-		switch imnotify.IMNotifyType(config.WebHookType) {
-		case imnotify.IMNotifyTypeDingDing:
-			if err := imnotifyClient.SendDingDingMessage(config.WebHookURL, title, content, nil, false); err != nil {
-				return err
-			}
-		case imnotify.IMNotifyTypeLark:
-			if err := imnotifyClient.SendFeishuMessage(config.WebHookURL, larkCard); err != nil {
-				return err
-			}
-		case imnotify.IMNotifyTypeWeChat:
-			if err := imnotifyClient.SendWeChatWorkMessage(imnotify.WeChatTextTypeMarkdown, config.WebHookURL, content); err != nil {
-				return err
-			}
+		msg := getNotificationMessage(projectName, envName, result)
+		if err := imnotify.NewIMNotifyClient().SendChannelMessage(imnotify.IMNotifyType(config.WebHookType), config.WebHookURL, config.WebHookSecret, msg); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-type envAnalysisNotification struct {
-	BaseURI     string                   `json:"base_uri"`
-	WebHookType imnotify.IMNotifyType    `json:"web_hook_type"`
-	Time        int64                    `json:"time"`
-	ProjectName string                   `json:"project_name"`
-	EnvName     string                   `json:"env_name"`
-	Status      envAnalysisNotifiyStatus `json:"status"`
-	Result      string                   `json:"result"`
-}
-
 type envAnalysisNotifiyStatus string
 
 const (
@@ -3535,94 +3507,37 @@ const (
 	envAnalysisNotifiyStatusAbnormal envAnalysisNotifiyStatus = "abnormal"
 )
 
-func getNotificationContent(projectName, envName, result string, webHookType imnotify.IMNotifyType) (string, string, *imnotify.LarkCard, error) {
-	tplTitle := "{{if ne .WebHookType \"feishu\"}}### {{end}}{{getIcon .Status }}{{if eq .WebHookType \"wechat\"}}<font color=\"{{ getColor .Status }}\">{{.ProjectName}}/{{.EnvName}} 环境巡检{{ getStatus .Status }}</font>{{else}} {{.ProjectName}} / {{.EnvName}} 环境巡检{{ getStatus .Status }}{{end}} \n"
-	tplContent := []string{"{{if eq .WebHookType \"dingding\"}}##### {{end}}**巡检时间：{{getTime}}** \n",
-		"{{.Result}} \n",
-	}
-
-	buttonContent := "点击查看更多信息"
-	envDetailURL := "{{.BaseURI}}/v1/projects/detail/{{.ProjectName}}/envs/detail?envName={{.EnvName}}"
-	moreInformation := fmt.Sprintf("\n\n{{if eq .WebHookType \"dingding\"}}---\n\n{{end}}[%s](%s)", buttonContent, envDetailURL)
+type envAnalysisEventData struct {
+	ProjectName string                   `json:"project_name"`
+	EnvName     string                   `json:"env_name"`
+	Status      envAnalysisNotifiyStatus `json:"status"`
+	Result      string                   `json:"result"`
+}
 
-	status := envAnalysisNotifiyStatusAbnormal
+// getNotificationMessage returns the message of the analysis result rendered by the notification channel
+func getNotificationMessage(projectName, envName, result string) *imnotify.Message {
+	status, statusText := envAnalysisNotifiyStatusAbnormal, "异常"
 	if strings.Contains(result, analysis.NormalResultOutput) {
-		status = envAnalysisNotifiyStatusNormal
-	}
-
-	envAnalysisNotifyArg := &envAnalysisNotification{
-		BaseURI:     configbase.SystemAddress(),
-		WebHookType: webHookType,
-		Time:        time.Now().Unix(),
-		ProjectName: projectName,
-		EnvName:     envName,
-		Status:      status,
-		Result:      result,
+		status, statusText = envAnalysisNotifiyStatusNormal, "正常"
 	}
 
-	title, err := getEnvAnalysisTplExec(tplTitle, envAnalysisNotifyArg)
-	if err != nil {
-		return "", "", nil, err
-	}
-
-	if webHookType != imnotify.IMNotifyTypeLark {
-		tplContent := strings.Join(tplContent, "")
-		tplContent = fmt.Sprintf("%s%s%s", title, tplContent, moreInformation)
-		content, err := getEnvAnalysisTplExec(tplContent, envAnalysisNotifyArg)
-		if err != nil {
-			return "", "", nil, err
-		}
-		return title, content, nil, nil
-	} else {
-		lc := imnotify.NewLarkCard()
-		lc.SetConfig(true)
-		lc.SetHeader(imnotify.GetColorTemplateWithStatus(config.Status(envAnalysisNotifyArg.Status)), title, "plain_text")
-		for idx, feildContent := range tplContent {
-			feildExecContent, _ := getEnvAnalysisTplExec(feildContent, envAnalysisNotifyArg)
-			lc.AddI18NElementsZhcnFeild(feildExecContent, idx == 0)
-		}
-		envDetailURL, _ = getEnvAnalysisTplExec(envDetailURL, envAnalysisNotifyArg)
-		lc.AddI18NElementsZhcnAction(buttonContent, envDetailURL)
-		return "", "", lc, nil
-	}
-}
-
-func getEnvAnalysisTplExec(tplcontent string, args *envAnalysisNotification) (string, error) {
-	tmpl := template.Must(template.New("notify").Funcs(template.FuncMap{
-		"getColor": func(status envAnalysisNotifiyStatus) string {
-			if status == envAnalysisNotifiyStatusNormal {
-				return "info"
-			} else if status == envAnalysisNotifiyStatusAbnormal {
-				return "warning"
-			}
-			return "warning"
+	return &imnotify.Message{
+		Event:   "env_analysis",
+		Title:   fmt.Sprintf("%s / %s 环境巡检%s", projectName, envName, statusText),
+		Success: status == envAnalysisNotifiyStatusNormal,
+		Fields: []*imnotify.MessageField{
+			{Name: "巡检时间", Value: time.Now().Format("2006-01-02 15:04:05")},
 		},
-		"getStatus": func(status envAnalysisNotifiyStatus) string {
-			if status == envAnalysisNotifiyStatusNormal {
-				return "正常"
-			} else if status == envAnalysisNotifiyStatusAbnormal {
-				return "异常"
-			}
-			return "异常"
+		Text:    result,
+		URL:     fmt.Sprintf("%s/v1/projects/detail/%s/envs/detail?envName=%s", configbase.SystemAddress(), projectName, envName),
+		URLText: "点击查看更多信息",
+		Data: &envAnalysisEventData{
+			ProjectName: projectName,
+			EnvName:     envName,
+			Status:      status,
+			Result:      result,
 		},
-		"getIcon": func(status envAnalysisNotifiyStatus) string {
-			if status == envAnalysisNotifiyStatusNormal {
-				return "👍"
-			}
-			return "⚠️"
-		},
-		"getTime": func() string {
-			return time.Now().Format("2006-01-02 15:04:05")
-		},
-	}).Parse(tplcontent))
-
-	buffer := bytes.NewBufferString("")
-	if err := tmpl.Execute(buffer, args); err != nil {
-		log.Errorf("getTplExec Execute err:%s", err)
-		return "", fmt.Errorf("getTplExec Execute err:%s", err)
-
 	}
-	return buffer.String(), nil
 }
 
 func PreviewProductGlobalVariablesWithRender(product *commonmodels.Product, args []*commontypes.GlobalVariableKV, log *zap.SugaredLogger) ([]*SvcDiffResult, error) {
@@ -240,7 +240,7 @@ func GetServiceWorkloads(svcTmpl *commonmodels.Service, env *commonmodels.Produc
 	envName, productName, namespace := env.EnvName, env.ProductName, env.Namespace
 
 	svcRender := env.GetSvcRender(svcTmpl.ServiceName)
-	parsedYaml, err := kube.RenderServiceYaml(svcTmpl.Yaml, productName, svcTmpl.ServiceName, svcRender)
+	parsedYaml, err := kube.RenderServiceTemplate(svcTmpl, envName, env.Production, svcRender)
 	if err != nil {
 		log.Errorf("failed to render service yaml, err: %s", err)
 		return nil, err
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	openapi "github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service"
@@ -25,10 +24,7 @@ func AnalyzeBuildLog(args *BuildLogAnalysisArgs, project, pipeline, job string,
 		return "", err
 	}
 
-	log := args.Log
-	prompt := fmt.Sprintf("%s; 构建日志数据: \"\"\"%s\"\"\"", BuildLogAnalysisPrompt, util.RemoveExtraSpaces(splitBuildLogByRowNum(log, 500)))
-
-	answer, err := client.GetCompletion(ctx, prompt, llm.WithModel(openapi.GPT3Dot5Turbo16K))
+	answer, err := client.GetCompletion(ctx, buildLogAnalysisPrompt(args))
 	if err != nil {
 		logger.Errorf("failed to get answer from ai: %v, the error is: %+v", client.GetName(), err)
 		return "", err
@@ -37,6 +33,42 @@ func AnalyzeBuildLog(args *BuildLogAnalysisArgs, project, pipeline, job string,
 	return answer, nil
 }
 
+// BuildLogAnalysisStreamMessage is a piece of the streamed analysis, the error is set if the analysis failed
+type BuildLogAnalysisStreamMessage struct {
+	Content string `json:"content"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AnalyzeBuildLogStream sends the answer to the stream chan piece by piece as soon as the llm generates it
+func AnalyzeBuildLogStream(ctx context.Context, streamChan chan interface{}, args *BuildLogAnalysisArgs, logger *zap.SugaredLogger) {
+	client, err := service.GetDefaultLLMClient(ctx)
+	if err != nil {
+		logger.Errorf("failed to get llm client, the error is: %+v", err)
+		streamChan <- &BuildLogAnalysisStreamMessage{Error: err.Error()}
+		return
+	}
+	streamBuildLogAnalysis(ctx, client, streamChan, args, logger)
+}
+
+func streamBuildLogAnalysis(ctx context.Context, client llm.ILLM, streamChan chan interface{}, args *BuildLogAnalysisArgs, logger *zap.SugaredLogger) {
+	err := client.GetCompletionStream(ctx, buildLogAnalysisPrompt(args), func(delta string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case streamChan <- &BuildLogAnalysisStreamMessage{Content: delta}:
+			return nil
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		logger.Errorf("failed to get answer from ai: %v, the error is: %+v", client.GetName(), err)
+		streamChan <- &BuildLogAnalysisStreamMessage{Error: err.Error()}
+	}
+}
+
+func buildLogAnalysisPrompt(args *BuildLogAnalysisArgs) string {
+	return fmt.Sprintf("%s; 构建日志数据: \"\"\"%s\"\"\"", BuildLogAnalysisPrompt, util.RemoveExtraSpaces(splitBuildLogByRowNum(args.Log, 500)))
+}
+
 func calculateTokenNum(msg string) (int, error) {
 	num, err := llm.NumTokensFromPrompt(msg, "")
 	if err != nil {
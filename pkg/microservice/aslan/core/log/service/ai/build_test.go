@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/tool/llm"
+)
+
+type fakeStreamLLM struct {
+	llm.ILLM
+	deltas []string
+	err    error
+	prompt string
+}
+
+func (f *fakeStreamLLM) GetCompletionStream(ctx context.Context, prompt string, handler llm.StreamHandler, options ...llm.ParamOption) error {
+	f.prompt = prompt
+	for _, delta := range f.deltas {
+		if err := handler(delta); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func (f *fakeStreamLLM) GetName() string {
+	return "fake"
+}
+
+func collectStream(client llm.ILLM) []*BuildLogAnalysisStreamMessage {
+	streamChan := make(chan interface{}, 10)
+	streamBuildLogAnalysis(context.Background(), client, streamChan, &BuildLogAnalysisArgs{Log: "step 1\nerror: exit 1\n"}, zap.NewNop().Sugar())
+	close(streamChan)
+	resp := make([]*BuildLogAnalysisStreamMessage, 0)
+	for msg := range streamChan {
+		resp = append(resp, msg.(*BuildLogAnalysisStreamMessage))
+	}
+	return resp
+}
+
+func TestStreamBuildLogAnalysis(t *testing.T) {
+	client := &fakeStreamLLM{deltas: []string{"the build ", "failed"}}
+	msgs := collectStream(client)
+
+	assert.Equal(t, []*BuildLogAnalysisStreamMessage{{Content: "the build "}, {Content: "failed"}}, msgs)
+	assert.Contains(t, client.prompt, "error: exit 1")
+}
+
+func TestStreamBuildLogAnalysisError(t *testing.T) {
+	msgs := collectStream(&fakeStreamLLM{deltas: []string{"the build "}, err: errors.New("connection reset")})
+
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "connection reset", msgs[1].Error)
+}
+
+func TestStreamBuildLogAnalysisCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// nobody reads the stream after the client disconnected, the analysis must not block
+	streamChan := make(chan interface{})
+	streamBuildLogAnalysis(ctx, &fakeStreamLLM{deltas: []string{"a", "b"}}, streamChan, &BuildLogAnalysisArgs{}, zap.NewNop().Sugar())
+}
@@ -56,6 +56,66 @@ func lintReleaseTimeRange(start, end int64) error {
 	return nil
 }
 
+// lintReleaseJobDependencies checks the release job names are unique and the dependencies between them form a DAG
+func lintReleaseJobDependencies(jobs []*models.ReleaseJob) error {
+	jobMap := make(map[string]*models.ReleaseJob, len(jobs))
+	for _, job := range jobs {
+		if _, ok := jobMap[job.Name]; ok {
+			return errors.Errorf("duplicate release job name %s", job.Name)
+		}
+		jobMap[job.Name] = job
+	}
+
+	inDegree := make(map[string]int, len(jobs))
+	children := make(map[string][]string, len(jobs))
+	for _, job := range jobs {
+		for _, dep := range sets.NewString(job.DependsOn...).List() {
+			if dep == job.Name {
+				return errors.Errorf("release job %s can not depend on itself", job.Name)
+			}
+			if _, ok := jobMap[dep]; !ok {
+				return errors.Errorf("release job %s depends on unknown job %s", job.Name, dep)
+			}
+			inDegree[job.Name]++
+			children[dep] = append(children[dep], job.Name)
+		}
+	}
+
+	queue := make([]string, 0)
+	for _, job := range jobs {
+		if inDegree[job.Name] == 0 {
+			queue = append(queue, job.Name)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, child := range children[name] {
+			inDegree[child]--
+			if inDegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	if visited != len(jobs) {
+		return errors.New("circular dependency found in release jobs")
+	}
+	return nil
+}
+
+// lintReleaseWindow checks a plan executed automatically has a release window
+func lintReleaseWindow(autoExecute bool, start, end int64) error {
+	if err := lintReleaseTimeRange(start, end); err != nil {
+		return err
+	}
+	if autoExecute && (start == 0 || end == 0) {
+		return errors.New("release window is required to execute the plan automatically")
+	}
+	return nil
+}
+
 func lintWorkflow(workflow *models.WorkflowV4) error {
 	if workflow == nil {
 		return fmt.Errorf("workflow cannot be empty")
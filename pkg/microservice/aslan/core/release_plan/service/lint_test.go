@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func newReleaseJob(name string, status config.ReleasePlanJobStatus, dependsOn ...string) *models.ReleaseJob {
+	return &models.ReleaseJob{
+		ID:                name,
+		Name:              name,
+		Type:              config.JobWorkflow,
+		DependsOn:         dependsOn,
+		ReleaseJobRuntime: models.ReleaseJobRuntime{Status: status},
+	}
+}
+
+func TestLintReleaseJobDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobs    []*models.ReleaseJob
+		wantErr bool
+	}{
+		{
+			name: "no dependencies",
+			jobs: []*models.ReleaseJob{newReleaseJob("a", ""), newReleaseJob("b", "")},
+		},
+		{
+			name: "valid dag",
+			jobs: []*models.ReleaseJob{newReleaseJob("a", ""), newReleaseJob("b", "", "a"), newReleaseJob("c", "", "a", "b")},
+		},
+		{
+			name:    "duplicate name",
+			jobs:    []*models.ReleaseJob{newReleaseJob("a", ""), newReleaseJob("a", "")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown dependency",
+			jobs:    []*models.ReleaseJob{newReleaseJob("a", "", "b")},
+			wantErr: true,
+		},
+		{
+			name:    "self dependency",
+			jobs:    []*models.ReleaseJob{newReleaseJob("a", "", "a")},
+			wantErr: true,
+		},
+		{
+			name:    "circular dependency",
+			jobs:    []*models.ReleaseJob{newReleaseJob("a", "", "c"), newReleaseJob("b", "", "a"), newReleaseJob("c", "", "b")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lintReleaseJobDependencies(tt.jobs)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetExecutableReleaseJobs(t *testing.T) {
+	plan := &models.ReleasePlan{
+		Jobs: []*models.ReleaseJob{
+			newReleaseJob("a", config.ReleasePlanJobStatusDone),
+			newReleaseJob("b", config.ReleasePlanJobStatusTodo, "a"),
+			newReleaseJob("c", config.ReleasePlanJobStatusTodo, "a", "b"),
+			newReleaseJob("d", config.ReleasePlanJobStatusTodo),
+			newReleaseJob("e", config.ReleasePlanJobStatusRunning),
+		},
+	}
+
+	names := make([]string, 0)
+	for _, job := range getExecutableReleaseJobs(plan) {
+		names = append(names, job.Name)
+	}
+	assert.Equal(t, []string{"b", "d"}, names)
+
+	assert.NoError(t, checkReleaseJobDependencies(plan, "b"))
+	assert.Error(t, checkReleaseJobDependencies(plan, "c"))
+}
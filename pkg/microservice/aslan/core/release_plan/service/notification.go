@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configbase "github.com/koderover/zadig/pkg/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/imnotify"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+var releasePlanStatusText = map[config.ReleasePlanStatus]string{
+	config.StatusPlanning:       "规划中",
+	config.StatusWaitForApprove: "等待审批",
+	config.StatusExecuting:      "开始执行",
+	config.StatusSuccess:        "发布完成",
+	config.StatusCancel:         "已取消",
+	config.StatusPaused:         "已暂停",
+}
+
+type releasePlanEventData struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Manager     string                   `json:"manager"`
+	Status      config.ReleasePlanStatus `json:"status"`
+	StartTime   int64                    `json:"start_time"`
+	EndTime     int64                    `json:"end_time"`
+	TotalJobs   int                      `json:"total_jobs"`
+	DoneJobs    int                      `json:"done_jobs"`
+	FailedJobs  int                      `json:"failed_jobs"`
+	Description string                   `json:"description"`
+}
+
+// sendReleasePlanNotifications sends the notifications of the current status of the plan to the channels subscribing it
+func sendReleasePlanNotifications(plan *models.ReleasePlan, detail string) {
+	var notifyCtls []*models.NotifyCtl
+	for _, notify := range plan.NotifyCtls {
+		if notify.Enabled && sets.NewString(notify.NotifyTypes...).Has(string(plan.Status)) {
+			notifyCtls = append(notifyCtls, notify)
+		}
+	}
+	if len(notifyCtls) == 0 {
+		return
+	}
+
+	msg := getReleasePlanNotificationMessage(plan, detail)
+	client := imnotify.NewIMNotifyClient()
+	for _, notify := range notifyCtls {
+		if err := client.SendChannelMessage(imnotify.IMNotifyType(notify.WebHookType), notify.GetWebHook(), notify.WebHookSecret, msg); err != nil {
+			log.Errorf("failed to send %s notification of release plan %s, err: %s", notify.WebHookType, plan.Name, err)
+		}
+	}
+}
+
+func getReleasePlanNotificationMessage(plan *models.ReleasePlan, detail string) *imnotify.Message {
+	data := &releasePlanEventData{
+		ID:          plan.ID.Hex(),
+		Name:        plan.Name,
+		Manager:     plan.Manager,
+		Status:      plan.Status,
+		StartTime:   plan.StartTime,
+		EndTime:     plan.EndTime,
+		TotalJobs:   len(plan.Jobs),
+		Description: detail,
+	}
+	for _, job := range plan.Jobs {
+		switch job.Status {
+		case config.ReleasePlanJobStatusDone:
+			data.DoneJobs++
+		case config.ReleasePlanJobStatusFailed:
+			data.FailedJobs++
+		}
+	}
+
+	fields := []*imnotify.MessageField{
+		{Name: "发布负责人", Value: plan.Manager},
+		{Name: "发布内容", Value: fmt.Sprintf("共 %d 项, 已完成 %d 项, 失败 %d 项", data.TotalJobs, data.DoneJobs, data.FailedJobs)},
+	}
+	if plan.StartTime != 0 && plan.EndTime != 0 {
+		fields = append(fields, &imnotify.MessageField{
+			Name:  "发布窗口期",
+			Value: time.Unix(plan.StartTime, 0).Format("2006-01-02 15:04:05") + "-" + time.Unix(plan.EndTime, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &imnotify.Message{
+		Event:   "release_plan",
+		Title:   fmt.Sprintf("发布计划 %s %s", plan.Name, releasePlanStatusText[plan.Status]),
+		Success: plan.Status != config.StatusPaused && plan.Status != config.StatusCancel,
+		Fields:  fields,
+		Text:    detail,
+		URL:     fmt.Sprintf("%s/v1/releasePlan/detail?id=%s", configbase.SystemAddress(), url.QueryEscape(plan.ID.Hex())),
+		URLText: "点击查看更多信息",
+		Data:    data,
+	}
+}
+
+func lintNotifyCtls(notifyCtls []*models.NotifyCtl) error {
+	statuses := sets.NewString()
+	for status := range releasePlanStatusText {
+		statuses.Insert(string(status))
+	}
+	for _, notify := range notifyCtls {
+		if notify == nil {
+			return errors.New("notification cannot be empty")
+		}
+		switch models.WebHookType(notify.WebHookType) {
+		case models.WebHookTypeDingding, models.WebHookTypeFeishu, models.WebHookTypeWeChat,
+			models.WebHookTypeSlack, models.WebHookTypeMSTeams, models.WebHookTypeGeneric:
+		default:
+			return errors.Errorf("invalid webhook type %s", notify.WebHookType)
+		}
+		if notify.GetWebHook() == "" {
+			return errors.Errorf("webhook of %s notification cannot be empty", notify.WebHookType)
+		}
+		for _, notifyType := range notify.NotifyTypes {
+			if !statuses.Has(notifyType) {
+				return errors.Errorf("invalid notify type %s", notifyType)
+			}
+		}
+	}
+	return nil
+}
@@ -86,6 +86,7 @@ type OpenAPICreateReleasePlanArgs struct {
 	ManagerIdentityType string           `bson:"manager_identity_type"       yaml:"manager_identity_type"                   json:"manager_identity_type"`
 	StartTime           int64            `bson:"start_time"       yaml:"start_time"                   json:"start_time"`
 	EndTime             int64            `bson:"end_time"       yaml:"end_time"                   json:"end_time"`
+	AutoExecute         bool             `bson:"auto_execute"       yaml:"auto_execute"                   json:"auto_execute"`
 	Description         string           `bson:"description"       yaml:"description"                   json:"description"`
 	Approval            *models.Approval `bson:"approval"       yaml:"approval"                   json:"approval,omitempty"`
 }
@@ -96,13 +97,14 @@ func OpenAPICreateReleasePlan(c *handler.Context, rawArgs *OpenAPICreateReleaseP
 		Manager:     rawArgs.Manager,
 		StartTime:   rawArgs.StartTime,
 		EndTime:     rawArgs.EndTime,
+		AutoExecute: rawArgs.AutoExecute,
 		Description: rawArgs.Description,
 		Approval:    rawArgs.Approval,
 	}
 	if args.Name == "" || args.Manager == "" {
 		return errors.New("Required parameters are missing")
 	}
-	if err := lintReleaseTimeRange(args.StartTime, args.EndTime); err != nil {
+	if err := lintReleaseWindow(args.AutoExecute, args.StartTime, args.EndTime); err != nil {
 		return errors.Wrap(err, "lint release time range error")
 	}
 	searchUserResp, err := user.New().SearchUser(&user.SearchUserArgs{
@@ -45,9 +45,12 @@ func CreateReleasePlan(c *handler.Context, args *models.ReleasePlan) error {
 	if args.Name == "" || args.ManagerID == "" {
 		return errors.New("Required parameters are missing")
 	}
-	if err := lintReleaseTimeRange(args.StartTime, args.EndTime); err != nil {
+	if err := lintReleaseWindow(args.AutoExecute, args.StartTime, args.EndTime); err != nil {
 		return errors.Wrap(err, "lint release time range error")
 	}
+	if err := lintNotifyCtls(args.NotifyCtls); err != nil {
+		return errors.Wrap(err, "lint notification error")
+	}
 	userInfo, err := user.New().GetUserByID(args.ManagerID)
 	if err != nil {
 		return errors.Errorf("Failed to get user by id %s, error: %v", args.ManagerID, err)
@@ -63,6 +66,9 @@ func CreateReleasePlan(c *handler.Context, args *models.ReleasePlan) error {
 		job.ReleaseJobRuntime = models.ReleaseJobRuntime{}
 		job.ID = uuid.New().String()
 	}
+	if err := lintReleaseJobDependencies(args.Jobs); err != nil {
+		return errors.Wrap(err, "lint release job dependencies error")
+	}
 
 	if args.Approval != nil {
 		if err := lintApproval(args.Approval); err != nil {
@@ -131,7 +137,12 @@ func ListReleasePlans(pageNum, pageSize int64) (*ListReleasePlanResp, error) {
 }
 
 func GetReleasePlan(id string) (*models.ReleasePlan, error) {
-	return mongodb.NewReleasePlanColl().GetByID(context.Background(), id)
+	plan, err := mongodb.NewReleasePlanColl().GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	plan.NotifyCtls = models.MaskNotifyCtlSecrets(plan.NotifyCtls)
+	return plan, nil
 }
 
 func GetReleasePlanLogs(id string) ([]*models.ReleasePlanLog, error) {
@@ -181,6 +192,13 @@ func UpdateReleasePlan(c *handler.Context, planID string, args *UpdateReleasePla
 	if err != nil {
 		return errors.Wrap(err, "update")
 	}
+	// only the updates of the jobs can change the dependencies between them
+	switch updater.(type) {
+	case *CreateReleaseJobUpdater, *UpdateReleaseJobUpdater:
+		if err = lintReleaseJobDependencies(plan.Jobs); err != nil {
+			return errors.Wrap(err, "lint release job dependencies")
+		}
+	}
 
 	plan.UpdatedBy = c.UserName
 	plan.UpdateTime = time.Now().Unix()
@@ -230,17 +248,18 @@ func ExecuteReleaseJob(c *handler.Context, planID string, args *ExecuteReleaseJo
 		return errors.Errorf("plan status is %s, can not execute", plan.Status)
 	}
 
-	if !(plan.StartTime == 0 && plan.EndTime == 0) {
-		now := time.Now().Unix()
-		if now < plan.StartTime || now > plan.EndTime {
-			return errors.Errorf("plan is not in the release time range")
-		}
+	if !inReleaseWindow(plan, time.Now().Unix()) {
+		return errors.Errorf("plan is not in the release time range")
 	}
 
 	if plan.ManagerID != c.UserID {
 		return errors.Errorf("only manager can execute")
 	}
 
+	if err = checkReleaseJobDependencies(plan, args.ID); err != nil {
+		return err
+	}
+
 	executor, err := NewReleaseJobExecutor(&ExecuteReleaseJobContext{
 		AuthResources: c.Resources,
 		UserID:        c.UserID,
@@ -266,6 +285,9 @@ func ExecuteReleaseJob(c *handler.Context, planID string, args *ExecuteReleaseJo
 	if err = mongodb.NewReleasePlanColl().UpdateByID(ctx, planID, plan); err != nil {
 		return errors.Wrap(err, "update plan")
 	}
+	if plan.Status == config.StatusSuccess {
+		go sendReleasePlanNotifications(plan, "")
+	}
 
 	go func() {
 		if err := mongodb.NewReleasePlanLogColl().Create(&models.ReleasePlanLog{
@@ -319,6 +341,10 @@ func UpdateReleasePlanStatus(c *handler.Context, planID, status string) error {
 			job.Updated = false
 		}
 	case config.StatusExecuting:
+		if plan.Status == config.StatusPaused {
+			resumeReleaseJobs(plan)
+			break
+		}
 		if plan.Approval != nil && plan.Approval.Status != config.StatusPassed {
 			detail = "跳过审批"
 		}
@@ -333,6 +359,8 @@ func UpdateReleasePlanStatus(c *handler.Context, planID, status string) error {
 	case config.StatusCancel:
 		// set executing status final time
 		plan.ExecutingTime = time.Now().Unix()
+	case config.StatusPaused:
+		plan.PausedTime = time.Now().Unix()
 	}
 
 	// original status check and update
@@ -349,6 +377,7 @@ func UpdateReleasePlanStatus(c *handler.Context, planID, status string) error {
 	if err = mongodb.NewReleasePlanColl().UpdateByID(ctx, planID, plan); err != nil {
 		return errors.Wrap(err, "update plan")
 	}
+	go sendReleasePlanNotifications(plan, detail)
 
 	go func() {
 		if err := mongodb.NewReleasePlanLogColl().Create(&models.ReleasePlanLog{
@@ -439,6 +468,9 @@ func ApproveReleasePlan(c *handler.Context, planID string, req *ApproveRequest)
 	if err = mongodb.NewReleasePlanColl().UpdateByID(ctx, planID, plan); err != nil {
 		return errors.Wrap(err, "update plan")
 	}
+	if plan.Status == config.StatusExecuting {
+		go sendReleasePlanNotifications(plan, "审批通过")
+	}
 
 	go func() {
 		if planLog == nil {
@@ -515,3 +547,53 @@ func setReleaseJobsForExecuting(plan *models.ReleasePlan) {
 		job.ExecutedTime = 0
 	}
 }
+
+// resumeReleaseJobs resets the failed jobs of a paused plan, so that they can be executed again
+func resumeReleaseJobs(plan *models.ReleasePlan) {
+	for _, job := range plan.Jobs {
+		if job.Status == config.ReleasePlanJobStatusFailed {
+			job.Status = config.ReleasePlanJobStatusTodo
+		}
+	}
+}
+
+// checkReleaseJobDependencies checks all the dependencies of the job are done
+func checkReleaseJobDependencies(plan *models.ReleasePlan, jobID string) error {
+	statusMap := make(map[string]config.ReleasePlanJobStatus, len(plan.Jobs))
+	for _, job := range plan.Jobs {
+		statusMap[job.Name] = job.Status
+	}
+	for _, job := range plan.Jobs {
+		if job.ID != jobID {
+			continue
+		}
+		for _, dep := range job.DependsOn {
+			if statusMap[dep] != config.ReleasePlanJobStatusDone {
+				return errors.Errorf("job %s depends on job %s which is not done", job.Name, dep)
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("job %s not found", jobID)
+}
+
+// getExecutableReleaseJobs returns the todo jobs whose dependencies are all done
+func getExecutableReleaseJobs(plan *models.ReleasePlan) []*models.ReleaseJob {
+	resp := make([]*models.ReleaseJob, 0)
+	for _, job := range plan.Jobs {
+		if job.Status != config.ReleasePlanJobStatusTodo {
+			continue
+		}
+		if checkReleaseJobDependencies(plan, job.ID) == nil {
+			resp = append(resp, job)
+		}
+	}
+	return resp
+}
+
+func inReleaseWindow(plan *models.ReleasePlan, now int64) bool {
+	if plan.StartTime == 0 && plan.EndTime == 0 {
+		return true
+	}
+	return now >= plan.StartTime && now <= plan.EndTime
+}
@@ -46,12 +46,15 @@ const (
 	VerbUpdateApproval = "update_approval"
 	VerbDeleteApproval = "delete_approval"
 
+	VerbUpdateNotification = "update_notification"
+
 	TargetTypeReleasePlan       = "发布计划"
 	TargetTypeReleasePlanStatus = "发布计划状态"
 	TargetTypeMetadata          = "元数据"
 	TargetTypeReleaseJob        = "发布内容"
 	TargetTypeApproval          = "审批"
 	TargetTypeDescription       = "需求关联"
+	TargetTypeNotification      = "通知"
 
 	VerbCreate  = "新建"
 	VerbUpdate  = "更新"
@@ -88,6 +91,8 @@ func NewPlanUpdater(args *UpdateReleasePlanArgs) (PlanUpdater, error) {
 		return NewUpdateApprovalUpdater(args)
 	case VerbDeleteApproval:
 		return NewDeleteApprovalUpdater(args)
+	case VerbUpdateNotification:
+		return NewNotificationUpdater(args)
 	default:
 		return nil, fmt.Errorf("invalid verb: %s", args.Verb)
 	}
@@ -165,8 +170,9 @@ func (u *DescUpdater) Verb() string {
 }
 
 type TimeRangeUpdater struct {
-	StartTime int64 `json:"start_time"`
-	EndTime   int64 `json:"end_time"`
+	StartTime   int64 `json:"start_time"`
+	EndTime     int64 `json:"end_time"`
+	AutoExecute bool  `json:"auto_execute"`
 }
 
 func NewTimeRangeUpdater(args *UpdateReleasePlanArgs) (*TimeRangeUpdater, error) {
@@ -183,13 +189,20 @@ func (u *TimeRangeUpdater) Update(plan *models.ReleasePlan) (before interface{},
 		time.Unix(plan.EndTime, 0).Format(format))
 	after = fmt.Sprintf("%s-%s", time.Unix(u.StartTime, 0).Format(format),
 		time.Unix(u.EndTime, 0).Format(format))
+	if plan.AutoExecute {
+		before = fmt.Sprintf("%s(自动执行)", before)
+	}
+	if u.AutoExecute {
+		after = fmt.Sprintf("%s(自动执行)", after)
+	}
 	plan.StartTime = u.StartTime
 	plan.EndTime = u.EndTime
+	plan.AutoExecute = u.AutoExecute
 	return
 }
 
 func (u *TimeRangeUpdater) Lint() error {
-	return lintReleaseTimeRange(u.StartTime, u.EndTime)
+	return lintReleaseWindow(u.AutoExecute, u.StartTime, u.EndTime)
 }
 
 func (u *TimeRangeUpdater) TargetName() string {
@@ -251,9 +264,10 @@ func (u *ManagerUpdater) Verb() string {
 }
 
 type CreateReleaseJobUpdater struct {
-	Name string                    `json:"name"`
-	Type config.ReleasePlanJobType `json:"type"`
-	Spec interface{}               `json:"spec"`
+	Name      string                    `json:"name"`
+	Type      config.ReleasePlanJobType `json:"type"`
+	Spec      interface{}               `json:"spec"`
+	DependsOn []string                  `json:"depends_on"`
 }
 
 func NewCreateReleaseJobUpdater(args *UpdateReleasePlanArgs) (*CreateReleaseJobUpdater, error) {
@@ -267,10 +281,11 @@ func NewCreateReleaseJobUpdater(args *UpdateReleasePlanArgs) (*CreateReleaseJobU
 func (u *CreateReleaseJobUpdater) Update(plan *models.ReleasePlan) (before interface{}, after interface{}, err error) {
 	before, after = nil, u
 	job := &models.ReleaseJob{
-		ID:   uuid.New().String(),
-		Name: u.Name,
-		Type: u.Type,
-		Spec: u.Spec,
+		ID:        uuid.New().String(),
+		Name:      u.Name,
+		Type:      u.Type,
+		Spec:      u.Spec,
+		DependsOn: u.DependsOn,
 	}
 	plan.Jobs = append(plan.Jobs, job)
 	return
@@ -297,10 +312,11 @@ func (u *CreateReleaseJobUpdater) Verb() string {
 }
 
 type UpdateReleaseJobUpdater struct {
-	ID   string                    `json:"id"`
-	Name string                    `json:"name"`
-	Type config.ReleasePlanJobType `json:"type"`
-	Spec interface{}               `json:"spec"`
+	ID        string                    `json:"id"`
+	Name      string                    `json:"name"`
+	Type      config.ReleasePlanJobType `json:"type"`
+	Spec      interface{}               `json:"spec"`
+	DependsOn []string                  `json:"depends_on"`
 }
 
 func NewUpdateReleaseJobUpdater(args *UpdateReleasePlanArgs) (*UpdateReleaseJobUpdater, error) {
@@ -318,8 +334,10 @@ func (u *UpdateReleaseJobUpdater) Update(plan *models.ReleasePlan) (before inter
 				return nil, nil, fmt.Errorf("job type cannot be changed")
 			}
 			before, after = job, u
+			renameReleaseJobDependency(plan, job.Name, u.Name)
 			job.Name = u.Name
 			job.Spec = u.Spec
+			job.DependsOn = u.DependsOn
 			job.Updated = true
 			return
 		}
@@ -327,6 +345,20 @@ func (u *UpdateReleaseJobUpdater) Update(plan *models.ReleasePlan) (before inter
 	return nil, nil, fmt.Errorf("job %s-%s not found", u.Name, u.ID)
 }
 
+// renameReleaseJobDependency keeps the dependencies of the other jobs when a job is renamed
+func renameReleaseJobDependency(plan *models.ReleasePlan, oldName, newName string) {
+	if oldName == newName {
+		return
+	}
+	for _, job := range plan.Jobs {
+		for i, dep := range job.DependsOn {
+			if dep == oldName {
+				job.DependsOn[i] = newName
+			}
+		}
+	}
+}
+
 func (u *UpdateReleaseJobUpdater) Lint() error {
 	if u.ID == "" {
 		return fmt.Errorf("id cannot be empty")
@@ -367,12 +399,26 @@ func (u *DeleteReleaseJobUpdater) Update(plan *models.ReleasePlan) (before inter
 		if job.ID == u.ID {
 			u.name = job.Name
 			plan.Jobs = append(plan.Jobs[:i], plan.Jobs[i+1:]...)
+			removeReleaseJobDependency(plan, job.Name)
 			return
 		}
 	}
 	return nil, nil, fmt.Errorf("job %s not found", u.ID)
 }
 
+// removeReleaseJobDependency prunes the dependencies of the other jobs on the deleted job
+func removeReleaseJobDependency(plan *models.ReleasePlan, name string) {
+	for _, job := range plan.Jobs {
+		dependsOn := make([]string, 0, len(job.DependsOn))
+		for _, dep := range job.DependsOn {
+			if dep != name {
+				dependsOn = append(dependsOn, dep)
+			}
+		}
+		job.DependsOn = dependsOn
+	}
+}
+
 func (u *DeleteReleaseJobUpdater) Lint() error {
 	if u.ID == "" {
 		return fmt.Errorf("id cannot be empty")
@@ -469,6 +515,42 @@ func (u *DeleteApprovalUpdater) Verb() string {
 	return VerbDelete
 }
 
+type NotificationUpdater struct {
+	NotifyCtls []*models.NotifyCtl `json:"notify_ctls"`
+}
+
+func NewNotificationUpdater(args *UpdateReleasePlanArgs) (*NotificationUpdater, error) {
+	var updater NotificationUpdater
+	if err := models.IToi(args.Spec, &updater); err != nil {
+		return nil, errors.Wrap(err, "invalid spec")
+	}
+	return &updater, nil
+}
+
+func (u *NotificationUpdater) Update(plan *models.ReleasePlan) (before interface{}, after interface{}, err error) {
+	models.EnsureNotifyCtlSecrets(plan.NotifyCtls, u.NotifyCtls)
+	// the secrets are not saved in the plan logs
+	before, after = models.MaskNotifyCtlSecrets(plan.NotifyCtls), models.MaskNotifyCtlSecrets(u.NotifyCtls)
+	plan.NotifyCtls = u.NotifyCtls
+	return
+}
+
+func (u *NotificationUpdater) Lint() error {
+	return lintNotifyCtls(u.NotifyCtls)
+}
+
+func (u *NotificationUpdater) TargetName() string {
+	return "通知"
+}
+
+func (u *NotificationUpdater) TargetType() string {
+	return TargetTypeNotification
+}
+
+func (u *NotificationUpdater) Verb() string {
+	return VerbUpdate
+}
+
 func createLarkApprovalDefinition(approval *models.LarkApproval) error {
 	if approval == nil {
 		return errors.Errorf("lark approval is nil")
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestDeleteReleaseJobUpdater(t *testing.T) {
+	plan := &models.ReleasePlan{
+		Jobs: []*models.ReleaseJob{
+			newReleaseJob("a", ""),
+			newReleaseJob("b", "", "a"),
+			newReleaseJob("c", "", "a", "b"),
+		},
+	}
+
+	updater := &DeleteReleaseJobUpdater{ID: "a"}
+	_, _, err := updater.Update(plan)
+	assert.NoError(t, err)
+
+	assert.Len(t, plan.Jobs, 2)
+	assert.Empty(t, plan.Jobs[0].DependsOn)
+	assert.Equal(t, []string{"b"}, plan.Jobs[1].DependsOn)
+	// the remaining jobs must still be a valid dag after the deletion
+	assert.NoError(t, lintReleaseJobDependencies(plan.Jobs))
+
+	_, _, err = (&DeleteReleaseJobUpdater{ID: "a"}).Update(plan)
+	assert.Error(t, err)
+}
+
+func TestUpdateReleaseJobUpdaterRename(t *testing.T) {
+	plan := &models.ReleasePlan{
+		Jobs: []*models.ReleaseJob{
+			newReleaseJob("a", ""),
+			newReleaseJob("b", "", "a"),
+		},
+	}
+
+	updater := &UpdateReleaseJobUpdater{ID: "a", Name: "renamed", Type: plan.Jobs[0].Type}
+	_, _, err := updater.Update(plan)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "renamed", plan.Jobs[0].Name)
+	assert.Equal(t, []string{"renamed"}, plan.Jobs[1].DependsOn)
+	assert.NoError(t, lintReleaseJobDependencies(plan.Jobs))
+}
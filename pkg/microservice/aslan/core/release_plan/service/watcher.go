@@ -18,6 +18,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,6 +28,8 @@ import (
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/notify"
+	"github.com/koderover/zadig/pkg/shared/client/user"
 	"github.com/koderover/zadig/pkg/tool/log"
 )
 
@@ -44,6 +47,7 @@ func WatchExecutingWorkflow() {
 		}
 		for _, plan := range list {
 			updatePlanWorkflowReleaseJob(plan, log)
+			executeScheduledReleaseJobs(plan, log)
 		}
 		if time.Since(t) > time.Millisecond*200 {
 			log.Warnf("watch executing workflow cost %s", time.Since(t))
@@ -66,6 +70,7 @@ func updatePlanWorkflowReleaseJob(plan *models.ReleasePlan, log *zap.SugaredLogg
 	if plan.Status != config.StatusExecuting {
 		return
 	}
+	var failedJob *models.ReleaseJob
 	for _, job := range plan.Jobs {
 		if job.Status == config.ReleasePlanJobStatusRunning && job.Type == config.JobWorkflow {
 			spec := new(models.WorkflowReleaseJobSpec)
@@ -81,6 +86,7 @@ func updatePlanWorkflowReleaseJob(plan *models.ReleasePlan, log *zap.SugaredLogg
 			spec.Status = task.Status
 			if lo.Contains(config.FailedStatus(), task.Status) {
 				job.Status = config.ReleasePlanJobStatusFailed
+				failedJob = job
 			}
 			if task.Status == config.StatusPassed {
 				job.Status = config.ReleasePlanJobStatusDone
@@ -92,12 +98,150 @@ func updatePlanWorkflowReleaseJob(plan *models.ReleasePlan, log *zap.SugaredLogg
 			}
 		}
 	}
+	// the scheduled execution stops at the failed job and waits for the manager
+	if failedJob != nil && plan.AutoExecute {
+		plan.Status = config.StatusPaused
+		plan.PausedTime = time.Now().Unix()
+	}
 	if err := mongodb.NewReleasePlanColl().UpdateByID(ctx, plan.ID.Hex(), plan); err != nil {
 		log.Errorf("update plan %s error: %v", plan.ID.Hex(), err)
+		return
+	}
+	if plan.Status == config.StatusSuccess {
+		go sendReleasePlanNotifications(plan, "")
+	}
+	if plan.Status == config.StatusPaused {
+		pauseDetail := fmt.Sprintf("发布内容 %s 执行失败, 发布计划已暂停", failedJob.Name)
+		go func() {
+			if err := mongodb.NewReleasePlanLogColl().Create(&models.ReleasePlanLog{
+				PlanID:     plan.ID.Hex(),
+				Username:   "系统",
+				Verb:       VerbUpdate,
+				TargetName: TargetTypeReleasePlanStatus,
+				TargetType: TargetTypeReleasePlanStatus,
+				Detail:     pauseDetail,
+				Before:     config.StatusExecuting,
+				After:      config.StatusPaused,
+				CreatedAt:  time.Now().Unix(),
+			}); err != nil {
+				log.Errorf("create release plan log error: %v", err)
+			}
+		}()
+		notify.SendMessage(plan.Manager, "发布计划已暂停", fmt.Sprintf("发布计划: %s, %s, 请处理后继续执行", plan.Name, pauseDetail), "", log)
+		go sendReleasePlanNotifications(plan, pauseDetail)
 	}
 	return
 }
 
+// executeScheduledReleaseJobs runs the workflow jobs of an automatically executed plan in the order of their dependencies
+// when the release window is open. The text jobs are still done by the manager, the jobs depending on them wait until then.
+func executeScheduledReleaseJobs(plan *models.ReleasePlan, log *zap.SugaredLogger) {
+	if !plan.AutoExecute || !inReleaseWindow(plan, time.Now().Unix()) {
+		return
+	}
+
+	getLock(plan.ID.Hex()).Lock()
+	defer getLock(plan.ID.Hex()).Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	plan, err := mongodb.NewReleasePlanColl().GetByID(ctx, plan.ID.Hex())
+	if err != nil {
+		log.Errorf("get plan %s error: %v", plan.ID.Hex(), err)
+		return
+	}
+	// plan status maybe changed during no lock time
+	if plan.Status != config.StatusExecuting || !plan.AutoExecute {
+		return
+	}
+
+	jobs := make([]*models.ReleaseJob, 0)
+	for _, job := range getExecutableReleaseJobs(plan) {
+		if job.Type == config.JobWorkflow {
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	// the jobs are executed on behalf of the manager
+	userInfo, err := user.New().GetUserByID(plan.ManagerID)
+	if err != nil {
+		log.Errorf("get manager %s of plan %s error: %v", plan.ManagerID, plan.Name, err)
+		return
+	}
+	authResources, err := user.New().GetUserAuthInfo(plan.ManagerID)
+	if err != nil {
+		log.Errorf("get auth info of manager %s of plan %s error: %v", plan.ManagerID, plan.Name, err)
+		return
+	}
+	executeCtx := &ExecuteReleaseJobContext{
+		AuthResources: authResources,
+		UserID:        userInfo.Uid,
+		Account:       userInfo.Account,
+		UserName:      userInfo.Name,
+	}
+
+	planLogs := make([]*models.ReleasePlanLog, 0)
+	var failedJob *models.ReleaseJob
+	var executeErr error
+	for _, job := range jobs {
+		executor, err := NewReleaseJobExecutor(executeCtx, &ExecuteReleaseJobArgs{ID: job.ID, Name: job.Name, Type: string(job.Type)})
+		if err == nil {
+			err = executor.Execute(plan)
+		}
+		if err != nil {
+			log.Errorf("execute release job %s of plan %s error: %v", job.Name, plan.Name, err)
+			job.Status = config.ReleasePlanJobStatusFailed
+			failedJob, executeErr = job, err
+			break
+		}
+		planLogs = append(planLogs, &models.ReleasePlanLog{
+			PlanID:     plan.ID.Hex(),
+			Username:   "系统",
+			Verb:       VerbExecute,
+			TargetName: job.Name,
+			TargetType: TargetTypeReleaseJob,
+			Detail:     "自动执行",
+			CreatedAt:  time.Now().Unix(),
+		})
+	}
+
+	if failedJob != nil {
+		plan.Status = config.StatusPaused
+		plan.PausedTime = time.Now().Unix()
+		planLogs = append(planLogs, &models.ReleasePlanLog{
+			PlanID:     plan.ID.Hex(),
+			Username:   "系统",
+			Verb:       VerbUpdate,
+			TargetName: TargetTypeReleasePlanStatus,
+			TargetType: TargetTypeReleasePlanStatus,
+			Detail:     fmt.Sprintf("发布内容 %s 自动执行失败, 发布计划已暂停", failedJob.Name),
+			Before:     config.StatusExecuting,
+			After:      config.StatusPaused,
+			CreatedAt:  time.Now().Unix(),
+		})
+	}
+	plan.UpdateTime = time.Now().Unix()
+	if err := mongodb.NewReleasePlanColl().UpdateByID(ctx, plan.ID.Hex(), plan); err != nil {
+		log.Errorf("update plan %s error: %v", plan.ID.Hex(), err)
+		return
+	}
+
+	go func() {
+		for _, planLog := range planLogs {
+			if err := mongodb.NewReleasePlanLogColl().Create(planLog); err != nil {
+				log.Errorf("create release plan log error: %v", err)
+			}
+		}
+	}()
+	if failedJob != nil {
+		notify.SendMessage(plan.Manager, "发布计划已暂停", fmt.Sprintf("发布计划: %s, 发布内容 %s 自动执行失败: %v, 请处理后继续执行", plan.Name, failedJob.Name, executeErr), "", log)
+		go sendReleasePlanNotifications(plan, fmt.Sprintf("发布内容 %s 自动执行失败: %v", failedJob.Name, executeErr))
+	}
+}
+
 func WatchApproval() {
 	log := log.SugaredLogger().With("service", "WatchApproval")
 	for {
@@ -182,6 +326,9 @@ func updatePlanApproval(plan *models.ReleasePlan) error {
 	if err := mongodb.NewReleasePlanColl().UpdateByID(ctx, plan.ID.Hex(), plan); err != nil {
 		return errors.Errorf("update plan %s error: %v", plan.ID.Hex(), err)
 	}
+	if plan.Status == config.StatusExecuting {
+		go sendReleasePlanNotifications(plan, "审批通过")
+	}
 
 	go func() {
 		if planLog == nil {
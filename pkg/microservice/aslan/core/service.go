@@ -59,7 +59,9 @@ import (
 	userdb "github.com/koderover/zadig/pkg/microservice/user/core/repository/mongodb"
 	"github.com/koderover/zadig/pkg/setting"
 	kubeclient "github.com/koderover/zadig/pkg/shared/kube/client"
+	"github.com/koderover/zadig/pkg/tool/bitbucket"
 	"github.com/koderover/zadig/pkg/tool/git/gitlab"
+	"github.com/koderover/zadig/pkg/tool/gitea"
 	gormtool "github.com/koderover/zadig/pkg/tool/gorm"
 	"github.com/koderover/zadig/pkg/tool/klock"
 	"github.com/koderover/zadig/pkg/tool/kube/multicluster"
@@ -194,6 +196,30 @@ func initCron() {
 		log.Infof("[CRONJOB] gitlab token updated....")
 	})
 
+	// the oauth tokens of gitea and bitbucket server expire in an hour, they are refreshed before the clone uses them
+	Scheduler.Every(5).Minutes().Do(func() {
+		tokenUpdaters := map[string]func(id int, accessToken string) (string, error){
+			setting.SourceFromGitea:           gitea.UpdateGiteaToken,
+			setting.SourceFromBitbucketServer: bitbucket.UpdateBitbucketToken,
+		}
+		for source, updateToken := range tokenUpdaters {
+			log.Infof("[CRONJOB] updating tokens for %s....", source)
+			codehostList, err := mongodb2.NewCodehostColl().List(&mongodb2.ListArgs{
+				Source: source,
+			})
+			if err != nil {
+				log.Errorf("failed to list %s codehost err:%v", source, err)
+				continue
+			}
+			for _, codehost := range codehostList {
+				if _, err := updateToken(codehost.ID, codehost.AccessToken); err != nil {
+					log.Errorf("failed to update %s token for host: %d, error: %s", source, codehost.ID, err)
+				}
+			}
+			log.Infof("[CRONJOB] %s token updated....", source)
+		}
+	})
+
 	Scheduler.StartAsync()
 }
 
@@ -379,6 +405,13 @@ func initDatabase() {
 		commonrepo.NewDeliverySecurityColl(),
 		commonrepo.NewDeliveryTestColl(),
 		commonrepo.NewDeliveryVersionColl(),
+		commonrepo.NewDeployFreezeColl(),
+		commonrepo.NewWorkflowGitSyncColl(),
+		commonrepo.NewWorkflowGitSyncStatusColl(),
+		commonrepo.NewScanningFindingColl(),
+		commonrepo.NewTestCaseResultColl(),
+		commonrepo.NewTestCaseQuarantineColl(),
+		commonrepo.NewCoverageRecordColl(),
 		commonrepo.NewDiffNoteColl(),
 		commonrepo.NewDindCleanColl(),
 		commonrepo.NewIMAppColl(),
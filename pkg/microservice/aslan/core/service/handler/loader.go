@@ -209,3 +209,54 @@ func ValidateServiceUpdate(c *gin.Context) {
 
 	ctx.Err = svcservice.ValidateServiceUpdate(codehostID, serviceName, repoOwner, repoName, repoUUID, branchName, remoteName, path, isDir, ctx.Logger)
 }
+
+func LoadKustomizeService(c *gin.Context) {
+	loadKustomizeService(c, false)
+}
+
+func SyncKustomizeService(c *gin.Context) {
+	loadKustomizeService(c, true)
+}
+
+func loadKustomizeService(c *gin.Context, force bool) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	args := new(svcservice.LoadKustomizeServiceReq)
+	if err := c.BindJSON(args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddDesc("invalid LoadKustomizeServiceReq json args")
+		return
+	}
+
+	operation := "新增"
+	if force {
+		operation = "更新"
+	}
+	bs, _ := json.Marshal(args)
+	internalhandler.InsertOperationLog(c, ctx.UserName, args.ProductName, operation, "项目管理-服务", args.ServiceName, string(bs), ctx.Logger)
+
+	// authorization checks
+	if !ctx.Resources.IsSystemAdmin {
+		projectAuthInfo, ok := ctx.Resources.ProjectAuthInfo[args.ProductName]
+		if !ok {
+			ctx.UnAuthorized = true
+			return
+		}
+		create, edit := projectAuthInfo.Service.Create, projectAuthInfo.Service.Edit
+		if args.Production {
+			create, edit = projectAuthInfo.ProductionService.Create, projectAuthInfo.ProductionService.Edit
+		}
+		if !projectAuthInfo.IsProjectAdmin && ((force && !edit) || (!force && !create)) {
+			ctx.UnAuthorized = true
+			return
+		}
+	}
+
+	ctx.Err = svcservice.LoadKustomizeService(ctx.UserName, args, force, ctx.Logger)
+}
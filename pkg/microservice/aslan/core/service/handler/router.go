@@ -97,6 +97,8 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		loader.POST("/load/:codehostId", LoadServiceTemplate)
 		loader.PUT("/load/:codehostId", SyncServiceTemplate)
 		loader.GET("/validateUpdate/:codehostId", ValidateServiceUpdate)
+		loader.POST("/kustomize", LoadKustomizeService)
+		loader.PUT("/kustomize", SyncKustomizeService)
 	}
 
 	pm := router.Group("pm")
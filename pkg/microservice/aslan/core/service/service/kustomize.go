@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/27149chen/afero"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service"
+	fsservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/fs"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+	"github.com/koderover/zadig/pkg/tool/kustomize"
+)
+
+type LoadKustomizeServiceReq struct {
+	ProductName   string                           `json:"product_name"`
+	ServiceName   string                           `json:"service_name"`
+	CodehostID    int                              `json:"codehost_id"`
+	RepoOwner     string                           `json:"repo_owner"`
+	RepoNamespace string                           `json:"repo_namespace"`
+	RepoName      string                           `json:"repo_name"`
+	BranchName    string                           `json:"branch_name"`
+	LoadPath      string                           `json:"load_path"`
+	BasePath      string                           `json:"base_path"`
+	Overlays      []*commonmodels.KustomizeOverlay `json:"overlays"`
+	Production    bool                             `json:"production"`
+}
+
+func (args *LoadKustomizeServiceReq) validate() error {
+	if args.ProductName == "" || args.ServiceName == "" {
+		return fmt.Errorf("product name and service name can not be empty")
+	}
+	if !config.ServiceNameRegex.MatchString(args.ServiceName) {
+		return fmt.Errorf("service name must match %s", config.ServiceNameRegexString)
+	}
+	if args.RepoName == "" || args.BranchName == "" {
+		return fmt.Errorf("repo name and branch name can not be empty")
+	}
+	if !isLocalKustomizePath(args.BasePath) {
+		return fmt.Errorf("invalid base path: %s", args.BasePath)
+	}
+	envs := make(map[string]bool)
+	for _, overlay := range args.Overlays {
+		if overlay.EnvName == "" || envs[overlay.EnvName] {
+			return fmt.Errorf("env name of overlays can not be empty or duplicated")
+		}
+		if !isLocalKustomizePath(overlay.Path) {
+			return fmt.Errorf("invalid overlay path: %s", overlay.Path)
+		}
+		envs[overlay.EnvName] = true
+	}
+	return nil
+}
+
+// isLocalKustomizePath returns true if the path is inside the load path of the service
+func isLocalKustomizePath(path string) bool {
+	return path == "" || filepath.IsLocal(path)
+}
+
+// LoadKustomizeService creates a k8s service from the kustomization in the code host, the files are saved to the
+// object storage so that the service can be built with the overlay of the environment when deploying
+func LoadKustomizeService(username string, args *LoadKustomizeServiceReq, force bool, log *zap.SugaredLogger) error {
+	if err := args.validate(); err != nil {
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+
+	ch, err := systemconfig.New().GetCodeHost(args.CodehostID)
+	if err != nil {
+		log.Errorf("Failed to get codehost %d, err: %s", args.CodehostID, err)
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+	namespace := args.RepoNamespace
+	if namespace == "" {
+		namespace = args.RepoOwner
+	}
+
+	fsTree, err := fsservice.DownloadFilesFromSource(
+		&fsservice.DownloadFromSourceArgs{CodehostID: args.CodehostID, Owner: args.RepoOwner, Namespace: namespace, Repo: args.RepoName, Path: args.LoadPath, Branch: args.BranchName},
+		func(afero.Fs) (string, error) {
+			return args.ServiceName, nil
+		})
+	if err != nil {
+		log.Errorf("Failed to download files from source, err: %s", err)
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+
+	// save files to disk and upload them to s3 as the latest version of the service
+	localBase := config.LocalServicePath(args.ProductName, args.ServiceName, args.Production)
+	if err := os.RemoveAll(localBase); err != nil {
+		log.Warnf("Failed to remove dir %s, err: %s", localBase, err)
+	}
+	if err = commonservice.SaveAndUploadService(args.ProductName, args.ServiceName, nil, fsTree, args.Production); err != nil {
+		log.Errorf("Failed to save or upload files for service %s in project %s, error: %s", args.ServiceName, args.ProductName, err)
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+
+	// make sure the base and all overlays can be built
+	kustomizeRoot := filepath.Join(localBase, args.ServiceName)
+	baseYaml, err := kustomize.Build(filepath.Join(kustomizeRoot, args.BasePath))
+	if err != nil {
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+	for _, overlay := range args.Overlays {
+		if _, err := kustomize.Build(filepath.Join(kustomizeRoot, overlay.Path)); err != nil {
+			return e.ErrLoadServiceTemplate.AddErr(fmt.Errorf("failed to build overlay of env %s: %w", overlay.EnvName, err))
+		}
+	}
+
+	svc := &commonmodels.Service{
+		ServiceName:   args.ServiceName,
+		Type:          setting.K8SDeployType,
+		ProductName:   args.ProductName,
+		Source:        setting.SourceFromKustomize,
+		Yaml:          baseYaml,
+		CodehostID:    args.CodehostID,
+		RepoOwner:     args.RepoOwner,
+		RepoNamespace: namespace,
+		RepoName:      args.RepoName,
+		BranchName:    args.BranchName,
+		LoadPath:      args.LoadPath,
+		LoadFromDir:   true,
+		SrcPath:       fmt.Sprintf("%s/%s/%s/%s/%s/%s", ch.Address, namespace, args.RepoName, "tree", args.BranchName, args.LoadPath),
+		Kustomize: &commonmodels.Kustomize{
+			BasePath: args.BasePath,
+			Overlays: args.Overlays,
+		},
+	}
+	if args.Production {
+		_, err = CreateProductionServiceTemplate(username, svc, force, log)
+	} else {
+		_, err = CreateServiceTemplate(username, svc, force, log)
+	}
+	if err != nil {
+		log.Errorf("Failed to create service template, err: %s", err)
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+
+	// keep the files of this revision, so that the environments can be built with the revision they deployed
+	s3Base := config.ObjectStorageServicePath(args.ProductName, args.ServiceName, args.Production)
+	if err = fsservice.ArchiveAndUploadFilesToS3(fsTree, []string{config.ServiceNameWithRevision(args.ServiceName, svc.Revision)}, s3Base, log); err != nil {
+		log.Errorf("Failed to upload files of service %s revision %d, err: %s", args.ServiceName, svc.Revision, err)
+		return e.ErrLoadServiceTemplate.AddErr(err)
+	}
+	return nil
+}
@@ -66,3 +66,31 @@ func GetReleaseStatOpenAPI(c *gin.Context) {
 
 	ctx.Resp, ctx.Err = service.GetReleaseStatOpenAPI(args.StartTime, args.EndTime, args.ProjectName, ctx.Logger)
 }
+
+type getDORAStatReq struct {
+	getStatReqV2
+	ServiceName string `form:"serviceName"`
+}
+
+func GetDORAStatOpenAPI(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	args := new(getDORAStatReq)
+	if err := c.ShouldBindQuery(args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddErr(err)
+		return
+	}
+
+	if err := args.Validate(); err != nil {
+		ctx.Err = err
+		return
+	}
+
+	if args.ServiceName != "" && args.ProjectName == "" {
+		ctx.Err = e.ErrInvalidParam.AddDesc("projectKey is required when serviceName is set")
+		return
+	}
+
+	ctx.Resp, ctx.Err = service.GetDORAMetricsOpenAPI(args.StartTime, args.EndTime, args.ProjectName, args.ServiceName, ctx.Logger)
+}
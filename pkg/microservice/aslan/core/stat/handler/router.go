@@ -48,6 +48,7 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		quality.POST("/testDeliveryDeploy", GetTestDeliveryDeployMeasure)
 		quality.POST("/testHealthMeasure", GetTestHealthMeasure)
 		quality.POST("/testTrend", GetTestTrendMeasure)
+		quality.POST("/testCoverageTrend", GetTestCoverageTrend)
 		//deployStat
 		quality.POST("/initDeployStat", InitDeployStat)
 		quality.POST("/pipelineHealthMeasure", GetPipelineHealthMeasure)
@@ -92,5 +93,6 @@ func (*OpenAPIRouter) Inject(router *gin.RouterGroup) {
 	v2 := router.Group("/v2")
 	{
 		v2.GET("/release", GetReleaseStatOpenAPI)
+		v2.GET("/dora", GetDORAStatOpenAPI)
 	}
 }
@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+)
+
+// doraBuildLookback is how far before the start time we look for the builds of the deployed changes
+const doraBuildLookback = 30 * 24 * 60 * 60
+
+type DORAMetrics struct {
+	ProjectName string `json:"project_name"`
+	ServiceName string `json:"service_name,omitempty"`
+	// DeploymentFrequency is the number of successful production deploys per week
+	DeploymentFrequency float64 `json:"deployment_frequency"`
+	// LeadTimeForChanges is the average seconds from the commit to the production deploy
+	LeadTimeForChanges float64 `json:"lead_time_for_changes"`
+	// ChangeFailureRate is the percentage of production deploys that failed or were rolled back,
+	// the release plans of the project are counted as changes too when no service is given
+	ChangeFailureRate float64 `json:"change_failure_rate"`
+	// MeanTimeToRestore is the average seconds from a failed change to its recovery
+	MeanTimeToRestore float64 `json:"mean_time_to_restore"`
+
+	DeployTotal   int `json:"deploy_total"`
+	DeploySuccess int `json:"deploy_success"`
+	FailedChanges int `json:"failed_changes"`
+	LeadTimeCount int `json:"lead_time_count"`
+	RestoredCount int `json:"restored_count"`
+
+	ReleasePlanTotal   int `json:"release_plan_total"`
+	FailedReleasePlans int `json:"failed_release_plans"`
+}
+
+func GetDORAMetricsOpenAPI(startTime, endTime int64, projectName, serviceName string, log *zap.SugaredLogger) (*DORAMetrics, error) {
+	resp, err := GetDORAMetrics(startTime, endTime, projectName, serviceName)
+	if err != nil {
+		log.Errorf("failed to get dora metrics for project: %s, service: %s, error: %s", projectName, serviceName, err)
+		return nil, errors.New("db error when getting dora metrics")
+	}
+	return resp, nil
+}
+
+// doraMetricsCache gets the DORA metrics of each project once for all the DORA calculators of a dashboard
+type doraMetricsCache struct {
+	getter  func(startTime, endTime int64, projectName, serviceName string) (*DORAMetrics, error)
+	metrics map[string]*doraMetricsResult
+}
+
+type doraMetricsResult struct {
+	metrics *DORAMetrics
+	err     error
+}
+
+func newDORAMetricsCache() *doraMetricsCache {
+	return &doraMetricsCache{
+		getter:  GetDORAMetrics,
+		metrics: make(map[string]*doraMetricsResult),
+	}
+}
+
+func (c *doraMetricsCache) Get(startTime, endTime int64, projectName string) (*DORAMetrics, error) {
+	key := fmt.Sprintf("%d-%d-%s", startTime, endTime, projectName)
+	if result, ok := c.metrics[key]; ok {
+		return result.metrics, result.err
+	}
+	metrics, err := c.getter(startTime, endTime, projectName, "")
+	c.metrics[key] = &doraMetricsResult{metrics: metrics, err: err}
+	return metrics, err
+}
+
+// GetDORAMetrics calculates the DORA metrics of the given project, and of the given service if serviceName is not empty
+func GetDORAMetrics(startTime, endTime int64, projectName, serviceName string) (*DORAMetrics, error) {
+	deployJobs, err := commonrepo.NewJobInfoColl().GetProductionDeployJobsByService(startTime, endTime, projectName, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list production deploy jobs: %s", err)
+	}
+	buildJobs, err := commonrepo.NewJobInfoColl().GetBuildJobsWithCommit(startTime-doraBuildLookback, endTime, projectName, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list build jobs: %s", err)
+	}
+	rollbackJobs, err := commonrepo.NewJobInfoColl().GetRollbackJobs(startTime, endTime, projectName, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rollback jobs: %s", err)
+	}
+
+	resp := calculateDORAMetrics(startTime, endTime, deployJobs, buildJobs, rollbackJobs)
+	// a release plan is not bound to a single service, so it only counts for the metrics of the project
+	if serviceName == "" {
+		releasePlans, err := commonrepo.NewReleasePlanColl().ListByExecutingTime(startTime, endTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list release plans: %s", err)
+		}
+		addReleasePlanChanges(resp, releasePlans, projectName)
+	}
+	resp.ProjectName = projectName
+	resp.ServiceName = serviceName
+	return resp, nil
+}
+
+// calculateDORAMetrics requires the deploy jobs to be sorted by start time.
+// A rollback job of the same service in the same workflow task after the deploy marks the deploy as a failed change,
+// and the change is restored when the rollback passes. A failed deploy is restored by the next
+// successful deploy of the same service to the same env.
+func calculateDORAMetrics(startTime, endTime int64, deployJobs, buildJobs, rollbackJobs []*commonmodels.JobInfo) *DORAMetrics {
+	resp := new(DORAMetrics)
+
+	buildByTask := make(map[string][]*commonmodels.JobInfo)
+	buildByService := make(map[string][]*commonmodels.JobInfo)
+	for _, build := range buildJobs {
+		taskKey := doraTaskKey(build.WorkflowName, build.TaskID)
+		buildByTask[taskKey] = append(buildByTask[taskKey], build)
+		serviceKey := build.ProductName + "/" + build.ServiceName
+		buildByService[serviceKey] = append(buildByService[serviceKey], build)
+	}
+	rollbackByTask := make(map[string][]*commonmodels.JobInfo)
+	for _, rollback := range rollbackJobs {
+		taskKey := doraTaskKey(rollback.WorkflowName, rollback.TaskID)
+		rollbackByTask[taskKey] = append(rollbackByTask[taskKey], rollback)
+	}
+
+	var leadTimeTotal, restoreTimeTotal int64
+	for i, deploy := range deployJobs {
+		failed := false
+		switch deploy.Status {
+		case string(config.StatusPassed):
+			resp.DeploySuccess++
+		case string(config.StatusFailed), string(config.StatusTimeout):
+			failed = true
+		default:
+			// cancelled or unfinished deploys are not changes
+			continue
+		}
+		resp.DeployTotal++
+
+		rollback := findRollback(deploy, rollbackByTask)
+		rolledBack := rollback != nil
+		if rolledBack {
+			failed = true
+		}
+
+		if !failed {
+			if build := findDeployedBuild(deploy, buildByTask, buildByService); build != nil && deploy.EndTime > build.CommitTime {
+				leadTimeTotal += deploy.EndTime - build.CommitTime
+				resp.LeadTimeCount++
+			}
+			continue
+		}
+
+		resp.FailedChanges++
+		if rolledBack {
+			if rollback.Status == string(config.StatusPassed) {
+				restoreTimeTotal += rollback.EndTime - deploy.EndTime
+				resp.RestoredCount++
+			}
+			continue
+		}
+		for _, next := range deployJobs[i+1:] {
+			if next.Status != string(config.StatusPassed) || next.ServiceName != deploy.ServiceName ||
+				next.TargetEnv != deploy.TargetEnv || next.ProductName != deploy.ProductName {
+				continue
+			}
+			restoreTimeTotal += next.EndTime - deploy.EndTime
+			resp.RestoredCount++
+			break
+		}
+	}
+
+	if days := (endTime - startTime) / 86400; days > 0 {
+		resp.DeploymentFrequency = float64(resp.DeploySuccess) * 7 / float64(days)
+	}
+	if resp.LeadTimeCount > 0 {
+		resp.LeadTimeForChanges = float64(leadTimeTotal) / float64(resp.LeadTimeCount)
+	}
+	if resp.RestoredCount > 0 {
+		resp.MeanTimeToRestore = float64(restoreTimeTotal) / float64(resp.RestoredCount)
+	}
+	resp.calculateChangeFailureRate()
+	return resp
+}
+
+// addReleasePlanChanges counts the executed release plans containing a workflow of the project as changes.
+// A plan paused by a failed release job, or with a failed release job, is a failed change, and
+// the plans still executing without a failure are not counted yet.
+func addReleasePlanChanges(metrics *DORAMetrics, plans []*commonmodels.ReleasePlan, projectName string) {
+	for _, plan := range plans {
+		inProject, failed := false, plan.Status == config.StatusPaused
+		for _, job := range plan.Jobs {
+			if job.Status == config.ReleasePlanJobStatusFailed || job.LastStatus == config.ReleasePlanJobStatusFailed {
+				failed = true
+			}
+			if job.Type != config.JobWorkflow {
+				continue
+			}
+			spec := new(commonmodels.WorkflowReleaseJobSpec)
+			if err := commonmodels.IToi(job.Spec, spec); err != nil || spec.Workflow == nil {
+				continue
+			}
+			if projectName == "" || spec.Workflow.Project == projectName {
+				inProject = true
+			}
+		}
+		if !inProject || (!failed && plan.Status != config.StatusSuccess) {
+			continue
+		}
+		metrics.ReleasePlanTotal++
+		if failed {
+			metrics.FailedReleasePlans++
+		}
+	}
+	metrics.calculateChangeFailureRate()
+}
+
+func (m *DORAMetrics) calculateChangeFailureRate() {
+	m.ChangeFailureRate = 0
+	if total := m.DeployTotal + m.ReleasePlanTotal; total > 0 {
+		m.ChangeFailureRate = float64(m.FailedChanges+m.FailedReleasePlans) * 100 / float64(total)
+	}
+}
+
+// findRollback returns the rollback after the deploy in the same workflow task, the rollbacks
+// saved without a service are matched with every deploy in the task
+func findRollback(deploy *commonmodels.JobInfo, rollbackByTask map[string][]*commonmodels.JobInfo) *commonmodels.JobInfo {
+	for _, rollback := range rollbackByTask[doraTaskKey(deploy.WorkflowName, deploy.TaskID)] {
+		if rollback.StartTime < deploy.StartTime {
+			continue
+		}
+		if rollback.ServiceName == "" || rollback.ServiceName == deploy.ServiceName {
+			return rollback
+		}
+	}
+	return nil
+}
+
+// findDeployedBuild returns the build of the deployed service in the same workflow task,
+// or the latest build of the service finished before the deploy
+func findDeployedBuild(deploy *commonmodels.JobInfo, buildByTask, buildByService map[string][]*commonmodels.JobInfo) *commonmodels.JobInfo {
+	for _, build := range buildByTask[doraTaskKey(deploy.WorkflowName, deploy.TaskID)] {
+		if build.ServiceName == deploy.ServiceName {
+			return build
+		}
+	}
+
+	var latest *commonmodels.JobInfo
+	for _, build := range buildByService[deploy.ProductName+"/"+deploy.ServiceName] {
+		if build.EndTime > deploy.StartTime {
+			continue
+		}
+		if latest == nil || build.EndTime > latest.EndTime {
+			latest = build
+		}
+	}
+	return latest
+}
+
+func doraTaskKey(workflowName string, taskID int64) string {
+	return fmt.Sprintf("%s-%d", workflowName, taskID)
+}
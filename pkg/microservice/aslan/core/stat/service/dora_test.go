@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func newDORAJob(jobType config.JobType, taskID int64, service string, status config.Status, start, end int64) *models.JobInfo {
+	return &models.JobInfo{
+		Type:         string(jobType),
+		WorkflowName: "release",
+		TaskID:       taskID,
+		ProductName:  "demo",
+		ServiceName:  service,
+		TargetEnv:    "prod",
+		Status:       string(status),
+		StartTime:    start,
+		EndTime:      end,
+		Production:   true,
+	}
+}
+
+func TestCalculateDORAMetrics(t *testing.T) {
+	const day = 86400
+	deploys := []*models.JobInfo{
+		// passed, built in the same task
+		newDORAJob(config.JobZadigDeploy, 1, "api", config.StatusPassed, 1000, 1100),
+		// passed, but rolled back by the analysis in the same task
+		newDORAJob(config.JobZadigDeploy, 2, "api", config.StatusPassed, 2000, 2100),
+		// failed, restored by the next successful deploy
+		newDORAJob(config.JobZadigDeploy, 3, "web", config.StatusFailed, 3000, 3100),
+		newDORAJob(config.JobZadigDeploy, 4, "web", config.StatusPassed, 4000, 4300),
+		// cancelled deploys are ignored
+		newDORAJob(config.JobZadigDeploy, 5, "web", config.StatusCancelled, 5000, 5100),
+	}
+	api := newDORAJob(config.JobZadigBuild, 1, "api", config.StatusPassed, 500, 900)
+	api.CommitTime = 100
+	web := newDORAJob(config.JobZadigBuild, 0, "web", config.StatusPassed, 3500, 3900)
+	web.CommitTime = 3300
+	rollback := newDORAJob(config.JobIstioRollback, 2, "", config.StatusPassed, 2200, 2600)
+
+	metrics := calculateDORAMetrics(0, 7*day, deploys, []*models.JobInfo{api, web}, []*models.JobInfo{rollback})
+
+	assert.Equal(t, 4, metrics.DeployTotal)
+	assert.Equal(t, 3, metrics.DeploySuccess)
+	assert.Equal(t, 2, metrics.FailedChanges)
+	assert.InDelta(t, 3.0, metrics.DeploymentFrequency, 0.001)
+	assert.InDelta(t, 50.0, metrics.ChangeFailureRate, 0.001)
+	// (1100-100) and (4300-3300)
+	assert.Equal(t, 2, metrics.LeadTimeCount)
+	assert.InDelta(t, 1000.0, metrics.LeadTimeForChanges, 0.001)
+	// (2600-2100) and (4300-3100)
+	assert.Equal(t, 2, metrics.RestoredCount)
+	assert.InDelta(t, 850.0, metrics.MeanTimeToRestore, 0.001)
+}
+
+func TestCalculateDORAMetricsEmpty(t *testing.T) {
+	metrics := calculateDORAMetrics(0, 86400, nil, nil, nil)
+	assert.Zero(t, metrics.DeployTotal)
+	assert.Zero(t, metrics.DeploymentFrequency)
+	assert.Zero(t, metrics.ChangeFailureRate)
+}
+
+func TestCalculateDORAMetricsRollbackService(t *testing.T) {
+	deploys := []*models.JobInfo{
+		newDORAJob(config.JobZadigDeploy, 1, "api", config.StatusPassed, 1000, 1100),
+		newDORAJob(config.JobZadigDeploy, 1, "web", config.StatusPassed, 1000, 1200),
+	}
+	rollback := newDORAJob(config.JobK8sGrayRollback, 1, "web", config.StatusPassed, 1300, 1500)
+
+	metrics := calculateDORAMetrics(0, 86400, deploys, nil, []*models.JobInfo{rollback})
+
+	// only the deploy of the rolled back service is a failed change
+	assert.Equal(t, 2, metrics.DeployTotal)
+	assert.Equal(t, 1, metrics.FailedChanges)
+	assert.Equal(t, 1, metrics.RestoredCount)
+	assert.InDelta(t, 300.0, metrics.MeanTimeToRestore, 0.001)
+}
+
+func newDORAReleasePlan(status config.ReleasePlanStatus, project string, jobStatus config.ReleasePlanJobStatus) *models.ReleasePlan {
+	return &models.ReleasePlan{
+		Status: status,
+		Jobs: []*models.ReleaseJob{
+			{
+				Type:              config.JobText,
+				Spec:              &models.TextReleaseJobSpec{Content: "check the db migration"},
+				ReleaseJobRuntime: models.ReleaseJobRuntime{Status: config.ReleasePlanJobStatusDone},
+			},
+			{
+				Type: config.JobWorkflow,
+				Spec: &models.WorkflowReleaseJobSpec{
+					Workflow: &models.WorkflowV4{Name: "release", Project: project},
+				},
+				ReleaseJobRuntime: models.ReleaseJobRuntime{Status: jobStatus},
+			},
+		},
+	}
+}
+
+func TestAddReleasePlanChanges(t *testing.T) {
+	plans := []*models.ReleasePlan{
+		newDORAReleasePlan(config.StatusSuccess, "demo", config.ReleasePlanJobStatusDone),
+		// paused by the failed workflow
+		newDORAReleasePlan(config.StatusPaused, "demo", config.ReleasePlanJobStatusFailed),
+		// a job failed, and the plan went back to planning
+		newDORAReleasePlan(config.StatusPlanning, "demo", config.ReleasePlanJobStatusTodo),
+		// still executing without a failure
+		newDORAReleasePlan(config.StatusExecuting, "demo", config.ReleasePlanJobStatusRunning),
+		// another project
+		newDORAReleasePlan(config.StatusPaused, "other", config.ReleasePlanJobStatusFailed),
+	}
+	plans[2].Jobs[1].LastStatus = config.ReleasePlanJobStatusFailed
+
+	resp := &DORAMetrics{DeployTotal: 2}
+	addReleasePlanChanges(resp, plans, "demo")
+
+	assert.Equal(t, 3, resp.ReleasePlanTotal)
+	assert.Equal(t, 2, resp.FailedReleasePlans)
+	// 2 failed release plans in 2 deploys and 3 release plans
+	assert.InDelta(t, 40.0, resp.ChangeFailureRate, 0.001)
+}
+
+func TestDORAMetricsCache(t *testing.T) {
+	calls := 0
+	cache := newDORAMetricsCache()
+	cache.getter = func(startTime, endTime int64, projectName, serviceName string) (*DORAMetrics, error) {
+		calls++
+		assert.Empty(t, serviceName)
+		return &DORAMetrics{ProjectName: projectName}, nil
+	}
+
+	for i := 0; i < 4; i++ {
+		metrics, err := cache.Get(0, 86400, "demo")
+		assert.NoError(t, err)
+		assert.Equal(t, "demo", metrics.ProjectName)
+	}
+	assert.Equal(t, 1, calls)
+
+	_, err := cache.Get(0, 86400, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
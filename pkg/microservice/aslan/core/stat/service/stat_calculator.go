@@ -35,7 +35,8 @@ type StatCalculator interface {
 	GetFact(startTime int64, endTime int64, projectKey string) (float64, bool, error)
 }
 
-func CreateCalculatorFromConfig(cfg *StatDashboardConfig) (StatCalculator, error) {
+// CreateCalculatorFromConfig creates the calculator of the config, the DORA calculators share the doraMetrics of the dashboard
+func CreateCalculatorFromConfig(cfg *StatDashboardConfig, doraMetrics *doraMetricsCache) (StatCalculator, error) {
 	// if the data source of the calculator is from API, then we find the external system and return a generalCalculator
 	if cfg.Source == "api" {
 		externalSystem, err := commonrepo.NewExternalSystemColl().GetByID(cfg.APIConfig.ExternalSystemId)
@@ -107,6 +108,30 @@ func CreateCalculatorFromConfig(cfg *StatDashboardConfig) (StatCalculator, error
 			Weight:   cfg.Weight,
 			Function: cfg.Function,
 		}, nil
+	case config.DashboardDataTypeDORADeploymentFrequency:
+		return &DORADeploymentFrequencyCalculator{
+			Metrics:  doraMetrics,
+			Weight:   cfg.Weight,
+			Function: cfg.Function,
+		}, nil
+	case config.DashboardDataTypeDORALeadTime:
+		return &DORALeadTimeCalculator{
+			Metrics:  doraMetrics,
+			Weight:   cfg.Weight,
+			Function: cfg.Function,
+		}, nil
+	case config.DashboardDataTypeDORAChangeFailureRate:
+		return &DORAChangeFailureRateCalculator{
+			Metrics:  doraMetrics,
+			Weight:   cfg.Weight,
+			Function: cfg.Function,
+		}, nil
+	case config.DashboardDataTypeDORAMeanTimeToRestore:
+		return &DORAMeanTimeToRestoreCalculator{
+			Metrics:  doraMetrics,
+			Weight:   cfg.Weight,
+			Function: cfg.Function,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported config id: %s", cfg.ID)
 	}
@@ -432,6 +457,94 @@ func (c *ReleaseFrequencyCalculator) GetWeightedScore(fact float64) (float64, er
 	return calculateWeightedScore(fact, c.Function, c.Weight)
 }
 
+// DORADeploymentFrequencyCalculator is used when the data ID is "dora_deployment_frequency", the fact is the successful production deploys per week
+type DORADeploymentFrequencyCalculator struct {
+	Metrics  *doraMetricsCache
+	Weight   int64
+	Function string
+}
+
+func (c *DORADeploymentFrequencyCalculator) GetFact(startTime, endTime int64, project string) (float64, bool, error) {
+	metrics, err := c.Metrics.Get(startTime, endTime, project)
+	if err != nil {
+		return 0, false, err
+	}
+	if metrics.DeployTotal == 0 {
+		return 0, false, nil
+	}
+	return metrics.DeploymentFrequency, true, nil
+}
+
+func (c *DORADeploymentFrequencyCalculator) GetWeightedScore(fact float64) (float64, error) {
+	return calculateWeightedScore(fact, c.Function, c.Weight)
+}
+
+// DORALeadTimeCalculator is used when the data ID is "dora_lead_time_for_changes", the fact is in seconds
+type DORALeadTimeCalculator struct {
+	Metrics  *doraMetricsCache
+	Weight   int64
+	Function string
+}
+
+func (c *DORALeadTimeCalculator) GetFact(startTime, endTime int64, project string) (float64, bool, error) {
+	metrics, err := c.Metrics.Get(startTime, endTime, project)
+	if err != nil {
+		return 0, false, err
+	}
+	if metrics.LeadTimeCount == 0 {
+		return 0, false, nil
+	}
+	return metrics.LeadTimeForChanges, true, nil
+}
+
+func (c *DORALeadTimeCalculator) GetWeightedScore(fact float64) (float64, error) {
+	return calculateWeightedScore(fact, c.Function, c.Weight)
+}
+
+// DORAChangeFailureRateCalculator is used when the data ID is "dora_change_failure_rate", the fact is in percentage
+type DORAChangeFailureRateCalculator struct {
+	Metrics  *doraMetricsCache
+	Weight   int64
+	Function string
+}
+
+func (c *DORAChangeFailureRateCalculator) GetFact(startTime, endTime int64, project string) (float64, bool, error) {
+	metrics, err := c.Metrics.Get(startTime, endTime, project)
+	if err != nil {
+		return 0, false, err
+	}
+	if metrics.DeployTotal+metrics.ReleasePlanTotal == 0 {
+		return 0, false, nil
+	}
+	return metrics.ChangeFailureRate, true, nil
+}
+
+func (c *DORAChangeFailureRateCalculator) GetWeightedScore(fact float64) (float64, error) {
+	return calculateWeightedScore(fact, c.Function, c.Weight)
+}
+
+// DORAMeanTimeToRestoreCalculator is used when the data ID is "dora_mean_time_to_restore", the fact is in seconds
+type DORAMeanTimeToRestoreCalculator struct {
+	Metrics  *doraMetricsCache
+	Weight   int64
+	Function string
+}
+
+func (c *DORAMeanTimeToRestoreCalculator) GetFact(startTime, endTime int64, project string) (float64, bool, error) {
+	metrics, err := c.Metrics.Get(startTime, endTime, project)
+	if err != nil {
+		return 0, false, err
+	}
+	if metrics.RestoredCount == 0 {
+		return 0, false, nil
+	}
+	return metrics.MeanTimeToRestore, true, nil
+}
+
+func (c *DORAMeanTimeToRestoreCalculator) GetWeightedScore(fact float64) (float64, error) {
+	return calculateWeightedScore(fact, c.Function, c.Weight)
+}
+
 func calculateWeightedScore(fact float64, function string, weight int64) (float64, error) {
 	expression, err := govaluate.NewEvaluableExpression(function)
 	if err != nil {
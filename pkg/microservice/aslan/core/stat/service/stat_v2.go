@@ -164,6 +164,7 @@ func GetStatsDashboard(startTime, endTime int64, projectList []string, logger *z
 		}
 	}
 
+	doraMetrics := newDORAMetricsCache()
 	for _, project := range projects {
 		facts := make([]*StatDashboardItem, 0)
 
@@ -183,7 +184,7 @@ func GetStatsDashboard(startTime, endTime int64, projectList []string, logger *z
 					Queries:          config.APIConfig.Queries,
 				}
 			}
-			calculator, err := CreateCalculatorFromConfig(cfg)
+			calculator, err := CreateCalculatorFromConfig(cfg, doraMetrics)
 			if err != nil {
 				logger.Errorf("failed to create calculator for project: %s, fact key: %s, error: %s", project.Name, config.ItemKey, err)
 				// if for some reason we failed to create the calculator, we append a fact with value 0, and error along with it
@@ -334,6 +335,38 @@ var defaultStatDashboardConfigMap = map[string]*commonmodels.StatDashboardConfig
 		Function: config.DashboardFunctionReleaseFrequency,
 		Weight:   0,
 	},
+	config.DashboardDataTypeDORADeploymentFrequency: {
+		Type:     config.DashboardDataCategoryEfficiency,
+		Name:     "DORA 部署频率(周）",
+		ItemKey:  config.DashboardDataTypeDORADeploymentFrequency,
+		Source:   config.DashboardDataSourceZadig,
+		Function: config.DashboardFunctionDORADeploymentFrequency,
+		Weight:   0,
+	},
+	config.DashboardDataTypeDORALeadTime: {
+		Type:     config.DashboardDataCategoryEfficiency,
+		Name:     "DORA 变更前置时间",
+		ItemKey:  config.DashboardDataTypeDORALeadTime,
+		Source:   config.DashboardDataSourceZadig,
+		Function: config.DashboardFunctionDORALeadTime,
+		Weight:   0,
+	},
+	config.DashboardDataTypeDORAChangeFailureRate: {
+		Type:     config.DashboardDataCategoryQuality,
+		Name:     "DORA 变更失败率",
+		ItemKey:  config.DashboardDataTypeDORAChangeFailureRate,
+		Source:   config.DashboardDataSourceZadig,
+		Function: config.DashboardFunctionDORAChangeFailureRate,
+		Weight:   0,
+	},
+	config.DashboardDataTypeDORAMeanTimeToRestore: {
+		Type:     config.DashboardDataCategoryQuality,
+		Name:     "DORA 平均恢复时间",
+		ItemKey:  config.DashboardDataTypeDORAMeanTimeToRestore,
+		Source:   config.DashboardDataSourceZadig,
+		Function: config.DashboardFunctionDORAMeanTimeToRestore,
+		Weight:   0,
+	},
 }
 
 func createDefaultStatDashboardConfig() []*commonmodels.StatDashboardConfig {
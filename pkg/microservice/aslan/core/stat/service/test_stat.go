@@ -40,6 +40,7 @@ import (
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/stat/repository/models"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/stat/repository/mongodb"
 	"github.com/koderover/zadig/pkg/setting"
+	e "github.com/koderover/zadig/pkg/tool/errors"
 	s3tool "github.com/koderover/zadig/pkg/tool/s3"
 	"github.com/koderover/zadig/pkg/types/step"
 	"github.com/koderover/zadig/pkg/util"
@@ -581,3 +582,69 @@ func GetTestTrendMeasure(startDate, endDate int64, productNames []string, log *z
 
 	return testTrend, nil
 }
+
+type testCoverageTrend struct {
+	Date       string  `json:"date"`
+	LineRate   float64 `json:"lineRate"`
+	BranchRate float64 `json:"branchRate"`
+	Tests      int     `json:"tests"`
+}
+
+// GetTestCoverageTrend returns the daily average coverage of the testing modules, only the last
+// run of a testing module (and service for service testings) on a day is counted.
+func GetTestCoverageTrend(startDate, endDate int64, productNames []string, log *zap.SugaredLogger) ([]*testCoverageTrend, error) {
+	records, _, err := commonmongodb.NewCoverageRecordColl().List(&commonmongodb.CoverageRecordListOption{
+		ProjectNames: productNames,
+		StartTime:    startDate,
+		EndTime:      endDate,
+	})
+	if err != nil {
+		log.Errorf("ListCoverageRecord err:%v", err)
+		return nil, e.ErrGetCoverageTrend.AddErr(err)
+	}
+
+	type dailyCoverage struct {
+		lineRate   float64
+		branchRate float64
+		branches   int
+		tests      int
+	}
+	dailyCoverages := make(map[string]*dailyCoverage)
+	counted := make(map[string]bool)
+	for _, record := range records {
+		date := time.Unix(record.CreateTime, 0).Format("2006-01-02")
+		key := strings.Join([]string{date, record.ProjectName, record.TestName, record.ServiceName, record.ServiceModule}, "/")
+		if counted[key] || record.Summary == nil {
+			continue
+		}
+		counted[key] = true
+		daily, ok := dailyCoverages[date]
+		if !ok {
+			daily = &dailyCoverage{}
+			dailyCoverages[date] = daily
+		}
+		daily.tests++
+		daily.lineRate += record.Summary.LineRate
+		if record.Summary.BranchesTotal > 0 {
+			daily.branches++
+			daily.branchRate += record.Summary.BranchRate
+		}
+	}
+
+	resp := make([]*testCoverageTrend, 0, len(dailyCoverages))
+	for date, daily := range dailyCoverages {
+		trend := &testCoverageTrend{
+			Date:     date,
+			LineRate: math.Round(daily.lineRate*100/float64(daily.tests)) / 100,
+			Tests:    daily.tests,
+		}
+		if daily.branches > 0 {
+			trend.BranchRate = math.Round(daily.branchRate*100/float64(daily.branches)) / 100
+		}
+		resp = append(resp, trend)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Date < resp[j].Date
+	})
+	return resp, nil
+}
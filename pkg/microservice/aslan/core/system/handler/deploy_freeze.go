@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/system/service"
+	internalhandler "github.com/koderover/zadig/pkg/shared/handler"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+// canManageDeployFreeze returns whether the user can manage the freezes of the project,
+// system-wide freezes are managed by system admins only.
+func canManageDeployFreeze(ctx *internalhandler.Context, projectName string) bool {
+	if ctx.Resources.IsSystemAdmin {
+		return true
+	}
+	if projectName == "" {
+		return false
+	}
+	authInfo, ok := ctx.Resources.ProjectAuthInfo[projectName]
+	return ok && authInfo.IsProjectAdmin
+}
+
+func ListDeployFreezes(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	projectName := c.Query("projectName")
+
+	// authorization checks
+	if !ctx.Resources.IsSystemAdmin {
+		if _, ok := ctx.Resources.ProjectAuthInfo[projectName]; !ok {
+			ctx.UnAuthorized = true
+			return
+		}
+	}
+
+	ctx.Resp, ctx.Err = service.ListDeployFreezes(projectName, ctx.Logger)
+}
+
+func GetDeployFreeze(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	freeze, err := service.GetDeployFreeze(c.Param("id"), ctx.Logger)
+	if err != nil {
+		ctx.Err = err
+		return
+	}
+
+	// authorization checks
+	if !ctx.Resources.IsSystemAdmin {
+		if _, ok := ctx.Resources.ProjectAuthInfo[freeze.ProjectName]; !ok {
+			ctx.UnAuthorized = true
+			return
+		}
+	}
+
+	ctx.Resp = freeze
+}
+
+func CreateDeployFreeze(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	args := new(commonmodels.DeployFreeze)
+	data, err := c.GetRawData()
+	if err != nil {
+		log.Errorf("CreateDeployFreeze c.GetRawData() err : %s", err)
+	}
+	if err = json.Unmarshal(data, args); err != nil {
+		log.Errorf("CreateDeployFreeze json.Unmarshal err : %s", err)
+	}
+	internalhandler.InsertOperationLog(c, ctx.UserName, args.ProjectName, "新增", "封网日历", args.Name, string(data), ctx.Logger)
+
+	// authorization checks
+	if !canManageDeployFreeze(ctx, args.ProjectName) {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+
+	if err := c.ShouldBindJSON(&args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddDesc("invalid deploy freeze args")
+		return
+	}
+	args.CreatedBy = ctx.UserName
+	args.UpdatedBy = ctx.UserName
+
+	ctx.Err = service.CreateDeployFreeze(args, ctx.Logger)
+}
+
+func UpdateDeployFreeze(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	args := new(commonmodels.DeployFreeze)
+	data, err := c.GetRawData()
+	if err != nil {
+		log.Errorf("UpdateDeployFreeze c.GetRawData() err : %s", err)
+	}
+	if err = json.Unmarshal(data, args); err != nil {
+		log.Errorf("UpdateDeployFreeze json.Unmarshal err : %s", err)
+	}
+
+	freeze, err := service.GetDeployFreeze(c.Param("id"), ctx.Logger)
+	if err != nil {
+		ctx.Err = err
+		return
+	}
+	internalhandler.InsertOperationLog(c, ctx.UserName, freeze.ProjectName, "更新", "封网日历", freeze.Name, string(data), ctx.Logger)
+
+	// authorization checks
+	if !canManageDeployFreeze(ctx, freeze.ProjectName) {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+
+	if err := c.ShouldBindJSON(&args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddDesc("invalid deploy freeze args")
+		return
+	}
+	args.UpdatedBy = ctx.UserName
+
+	ctx.Err = service.UpdateDeployFreeze(c.Param("id"), args, ctx.Logger)
+}
+
+func DeleteDeployFreeze(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	freeze, err := service.GetDeployFreeze(c.Param("id"), ctx.Logger)
+	if err != nil {
+		ctx.Err = err
+		return
+	}
+	internalhandler.InsertOperationLog(c, ctx.UserName, freeze.ProjectName, "删除", "封网日历", freeze.Name, "", ctx.Logger)
+
+	// authorization checks
+	if !canManageDeployFreeze(ctx, freeze.ProjectName) {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	ctx.Err = service.DeleteDeployFreeze(c.Param("id"), ctx.Logger)
+}
@@ -187,3 +187,10 @@ func ListCharts(c *gin.Context) {
 
 	ctx.Resp, ctx.Err = service.ListCharts(c.Param("name"), ctx.Logger)
 }
+
+func ListChartVersions(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	ctx.Resp, ctx.Err = service.ListChartVersions(c.Param("name"), c.Param("chart"), ctx.Logger)
+}
@@ -31,6 +31,8 @@ type CreateLLMIntegrationRequest struct {
 	Name        string `json:"name"`
 	Token       string `json:"token"`
 	BaseURL     string `json:"base_url"`
+	Model       string `json:"model"`
+	TokenLimit  int    `json:"token_limit"`
 	EnableProxy bool   `json:"enable_proxy"`
 }
 
@@ -174,6 +176,8 @@ func convertLLMArgToModel(args *CreateLLMIntegrationRequest) *commonmodels.LLMIn
 		Name:        args.Name,
 		Token:       args.Token,
 		BaseURL:     args.BaseURL,
+		Model:       args.Model,
+		TokenLimit:  args.TokenLimit,
 		EnableProxy: args.EnableProxy,
 	}
 }
@@ -159,6 +159,7 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		integration.PUT("/:id", UpdateHelmRepo)
 		integration.DELETE("/:id", DeleteHelmRepo)
 		integration.GET("/:name/index", ListCharts)
+		integration.GET("/:name/charts/:chart/versions", ListChartVersions)
 	}
 
 	// ---------------------------------------------------------------------------------------
@@ -220,6 +221,18 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		externalLink.DELETE("/:id", DeleteExternalLink)
 	}
 
+	// ---------------------------------------------------------------------------------------
+	// deploy freeze calendar
+	// ---------------------------------------------------------------------------------------
+	deployFreeze := router.Group("deployFreeze")
+	{
+		deployFreeze.GET("", ListDeployFreezes)
+		deployFreeze.GET("/:id", GetDeployFreeze)
+		deployFreeze.POST("", CreateDeployFreeze)
+		deployFreeze.PUT("/:id", UpdateDeployFreeze)
+		deployFreeze.DELETE("/:id", DeleteDeployFreeze)
+	}
+
 	// ---------------------------------------------------------------------------------------
 	// system custom theme
 	// ---------------------------------------------------------------------------------------
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"go.uber.org/zap"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/deployfreeze"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+func ListDeployFreezes(projectName string, log *zap.SugaredLogger) ([]*commonmodels.DeployFreeze, error) {
+	resp, err := commonrepo.NewDeployFreezeColl().List(&commonrepo.DeployFreezeListOption{ProjectName: projectName})
+	if err != nil {
+		log.Errorf("DeployFreeze.List error: %s", err)
+		return nil, e.ErrListDeployFreeze.AddErr(err)
+	}
+	return resp, nil
+}
+
+func GetDeployFreeze(id string, log *zap.SugaredLogger) (*commonmodels.DeployFreeze, error) {
+	resp, err := commonrepo.NewDeployFreezeColl().GetByID(id)
+	if err != nil {
+		log.Errorf("DeployFreeze.Get %s error: %s", id, err)
+		return nil, e.ErrGetDeployFreeze.AddErr(err)
+	}
+	return resp, nil
+}
+
+func CreateDeployFreeze(args *commonmodels.DeployFreeze, log *zap.SugaredLogger) error {
+	if err := deployfreeze.ValidateDeployFreeze(args); err != nil {
+		return e.ErrCreateDeployFreeze.AddErr(err)
+	}
+	if err := commonrepo.NewDeployFreezeColl().Create(args); err != nil {
+		log.Errorf("DeployFreeze.Create error: %s", err)
+		return e.ErrCreateDeployFreeze.AddErr(err)
+	}
+	return nil
+}
+
+func UpdateDeployFreeze(id string, args *commonmodels.DeployFreeze, log *zap.SugaredLogger) error {
+	if err := deployfreeze.ValidateDeployFreeze(args); err != nil {
+		return e.ErrUpdateDeployFreeze.AddErr(err)
+	}
+	if err := commonrepo.NewDeployFreezeColl().Update(id, args); err != nil {
+		log.Errorf("DeployFreeze.Update %s error: %s", id, err)
+		return e.ErrUpdateDeployFreeze.AddErr(err)
+	}
+	return nil
+}
+
+func DeleteDeployFreeze(id string, log *zap.SugaredLogger) error {
+	if err := commonrepo.NewDeployFreezeColl().Delete(id); err != nil {
+		log.Errorf("DeployFreeze.Delete %s error: %s", id, err)
+		return e.ErrDeleteDeployFreeze.AddErr(err)
+	}
+	return nil
+}
@@ -98,3 +98,36 @@ func ListCharts(name string, log *zap.SugaredLogger) (*IndexFileResp, error) {
 	}
 	return indexResp, nil
 }
+
+// ListChartVersions lists the versions of the chart in the chart repo, tags of the chart are listed for OCI registries
+func ListChartVersions(repoName, chartName string, log *zap.SugaredLogger) ([]string, error) {
+	chartRepo, err := commonrepo.NewHelmRepoColl().Find(&commonrepo.HelmRepoFindOption{RepoName: repoName})
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := helmclient.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	repoEntry := commonutil.GeneHelmRepo(chartRepo)
+	if helmclient.IsOCIRepo(repoEntry.URL) {
+		versions, err := client.ListOCIChartVersions(repoEntry, chartName)
+		if err != nil {
+			log.Errorf("failed to list versions of chart %s in repo %s, err: %s", chartName, repoName, err)
+			return nil, err
+		}
+		return versions, nil
+	}
+
+	indexInfo, err := client.FetchIndexYaml(repoEntry)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0)
+	for _, chart := range indexInfo.Entries[chartName] {
+		versions = append(versions, chart.Version)
+	}
+	return versions, nil
+}
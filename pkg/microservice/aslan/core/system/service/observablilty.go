@@ -19,11 +19,13 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	e "github.com/koderover/zadig/pkg/tool/errors"
 	"github.com/koderover/zadig/pkg/tool/guanceyun"
+	"github.com/koderover/zadig/pkg/tool/prometheus"
 )
 
 func ListObservability(_type string, isAdmin bool) ([]*models.Observability, error) {
@@ -64,6 +66,8 @@ func ValidateObservability(args *models.Observability) error {
 	switch args.Type {
 	case "guanceyun":
 		return validateGuanceyun(args)
+	case "prometheus":
+		return validatePrometheus(args)
 	default:
 		return errors.New("invalid observability type")
 	}
@@ -73,3 +77,8 @@ func validateGuanceyun(args *models.Observability) error {
 	_, _, err := guanceyun.NewClient(args.Host, args.ApiKey).ListMonitor("", 1, 1)
 	return err
 }
+
+func validatePrometheus(args *models.Observability) error {
+	_, err := prometheus.NewClient(args.Host, args.ApiKey).QueryValue("vector(1)", time.Now())
+	return err
+}
@@ -191,7 +191,12 @@ func OpenAPIDeleteCustomWorkflowV4(c *gin.Context) {
 	}
 	internalhandler.InsertOperationLog(c, ctx.UserName, projectKey, "(OpenAPI)"+"删除", "自定义工作流", workflowKey, "", ctx.Logger)
 
-	ctx.Err = workflowservice.OpenAPIDeleteCustomWorkflowV4(workflowKey, projectKey, ctx.Logger)
+	pr, err := workflowservice.OpenAPIDeleteCustomWorkflowV4(ctx.UserName, workflowKey, projectKey, ctx.Logger)
+	if pr != nil {
+		// the workflow is managed in a repo, the deletion is sent to the repo as a pull request
+		ctx.Resp = pr
+	}
+	ctx.Err = err
 }
 
 func OpenAPIGetCustomWorkflowV4(c *gin.Context) {
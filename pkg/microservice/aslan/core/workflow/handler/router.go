@@ -202,6 +202,10 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		workflowV4.PUT("/generalhook/:workflowName", UpdateGeneralHookForWorkflowV4)
 		workflowV4.DELETE("/generalhook/:workflowName/:hookName", DeleteGeneralHookForWorkflowV4)
 		workflowV4.POST("/generalhook/:workflowName/:hookName/webhook", GeneralHookEventHandler)
+		workflowV4.GET("/gitsync", GetWorkflowGitSync)
+		workflowV4.PUT("/gitsync", UpsertWorkflowGitSync)
+		workflowV4.DELETE("/gitsync", DeleteWorkflowGitSync)
+		workflowV4.POST("/gitsync/sync", SyncWorkflowsFromGit)
 		workflowV4.GET("/cron/preset", GetCronForWorkflowV4Preset)
 		workflowV4.GET("/cron", ListCronForWorkflowV4)
 		workflowV4.POST("/cron/:workflowName", CreateCronForWorkflowV4)
@@ -236,6 +240,7 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		taskV4.POST("/debug/:workflowName/task/:taskID", EnableDebugWorkflowTaskV4)
 		taskV4.DELETE("/debug/:workflowName/:jobName/task/:taskID/:position", StopDebugWorkflowTaskJobV4)
 		taskV4.POST("/approve", ApproveStage)
+		taskV4.POST("/freeze/approve", ApproveDeployFreezeOverride)
 		taskV4.GET("/workflow/:workflowName/taskId/:taskId/job/:jobName", GetWorkflowV4ArtifactFileContent)
 		taskV4.POST("/trigger", CreateWorkflowTaskV4ByBuildInTrigger)
 	}
@@ -55,6 +55,14 @@ type ApproveRequest struct {
 	Comment      string `json:"comment"`
 }
 
+type ApproveDeployFreezeOverrideRequest struct {
+	JobName      string `json:"job_name"`
+	WorkflowName string `json:"workflow_name"`
+	TaskID       int64  `json:"task_id"`
+	Approve      bool   `json:"approve"`
+	Comment      string `json:"comment"`
+}
+
 func CreateWorkflowTaskV4(c *gin.Context) {
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
@@ -522,6 +530,34 @@ func ApproveStage(c *gin.Context) {
 	ctx.Err = workflow.ApproveStage(args.WorkflowName, args.StageName, ctx.UserName, ctx.UserID, args.Comment, args.TaskID, args.Approve, ctx.Logger)
 }
 
+func ApproveDeployFreezeOverride(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+	args := &ApproveDeployFreezeOverrideRequest{}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		log.Errorf("ApproveDeployFreezeOverride c.GetRawData() err : %s", err)
+	}
+	if err = json.Unmarshal(data, args); err != nil {
+		log.Errorf("ApproveDeployFreezeOverride json.Unmarshal err : %s", err)
+	}
+	operation := "拒绝"
+	if args.Approve {
+		operation = "批准"
+	}
+	internalhandler.InsertOperationLog(c, ctx.UserName, c.Query("projectName"), operation, "自定义工作流任务-封网例外", fmt.Sprintf("workflow:%s task:%d job:%s", args.WorkflowName, args.TaskID, args.JobName), string(data), ctx.Logger)
+
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+
+	if err := c.ShouldBindJSON(&args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddDesc(err.Error())
+		return
+	}
+
+	ctx.Err = workflow.ApproveDeployFreezeOverride(args.WorkflowName, args.JobName, ctx.UserName, ctx.UserID, args.Comment, args.TaskID, args.Approve, ctx.Logger)
+}
+
 func GetWorkflowV4ArtifactFileContent(c *gin.Context) {
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
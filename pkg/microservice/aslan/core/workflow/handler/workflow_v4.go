@@ -106,6 +106,11 @@ func CreateWorkflowV4(c *gin.Context) {
 		}
 	}
 
+	if intercepted, pr, err := workflow.InterceptWorkflowV4Edit(ctx.UserName, args, ctx.Logger); intercepted {
+		ctx.Resp, ctx.Err = pr, err
+		return
+	}
+
 	if err := workflow.CreateWorkflowV4(ctx.UserName, args, ctx.Logger); err != nil {
 		ctx.Err = err
 		return
@@ -314,6 +319,11 @@ func UpdateWorkflowV4(c *gin.Context) {
 		}
 	}
 
+	if intercepted, pr, err := workflow.InterceptWorkflowV4Edit(ctx.UserName, args, ctx.Logger); intercepted {
+		ctx.Resp, ctx.Err = pr, err
+		return
+	}
+
 	ctx.Err = workflow.UpdateWorkflowV4(c.Param("name"), ctx.UserName, args, ctx.Logger)
 }
 
@@ -350,6 +360,11 @@ func DeleteWorkflowV4(c *gin.Context) {
 		}
 	}
 
+	if intercepted, pr, err := workflow.InterceptWorkflowV4Delete(ctx.UserName, c.Param("name"), ctx.Logger); intercepted {
+		ctx.Resp, ctx.Err = pr, err
+		return
+	}
+
 	ctx.Err = workflow.DeleteWorkflowV4(c.Param("name"), ctx.Logger)
 }
 
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/config"
+	gitservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	"github.com/koderover/zadig/pkg/tool/bitbucket"
+)
+
+// bitbucketRefChangeEvent is one of the changes in a refs changed event of bitbucket server,
+// a push of several branches and tags is triggered separately.
+type bitbucketRefChangeEvent struct {
+	Repository *bitbucket.Repository
+	Actor      *bitbucket.User
+	Change     *bitbucket.RefChange
+}
+
+func ProcessBitbucketHook(payload []byte, req *http.Request, requestID string, log *zap.SugaredLogger) error {
+	eventType := bitbucket.HookEventType(req)
+	// the test connection of the webhook
+	if eventType == bitbucket.EventTypeDiagnosticsPing {
+		return nil
+	}
+
+	if err := bitbucket.ValidatePayload(req, payload, gitservice.GetHookSecret()); err != nil {
+		return err
+	}
+
+	event, err := bitbucket.ParseHook(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	baseURI := config.SystemAddress()
+	switch event := event.(type) {
+	case *bitbucket.RefsChangedEvent:
+		var errorList = &multierror.Error{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, change := range event.Changes {
+			if change.Type == bitbucket.RefChangeTypeDelete || change.Ref == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(change *bitbucket.RefChange) {
+				defer wg.Done()
+				err := TriggerWorkflowV4ByBitbucketEvent(&bitbucketRefChangeEvent{
+					Repository: event.Repository,
+					Actor:      event.Actor,
+					Change:     change,
+				}, baseURI, requestID, log)
+				if err != nil {
+					mu.Lock()
+					errorList = multierror.Append(errorList, err)
+					mu.Unlock()
+				}
+			}(change)
+		}
+		wg.Wait()
+		return errorList.ErrorOrNil()
+	default:
+		return TriggerWorkflowV4ByBitbucketEvent(event, baseURI, requestID, log)
+	}
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/scmnotify"
+	workflowservice "github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow/job"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	"github.com/koderover/zadig/pkg/tool/bitbucket"
+	"github.com/koderover/zadig/pkg/types"
+)
+
+// bitbucketEmptyCommit is the from hash of a newly created branch
+const bitbucketEmptyCommit = "0000000000000000000000000000000000000000"
+
+// bitbucketDiffFunc returns the changed files of the event
+type bitbucketDiffFunc func(event interface{}, codehostID int) ([]string, error)
+
+type bitbucketEventMatcherForWorkflowV4 interface {
+	Match(*commonmodels.MainHookRepo) (bool, error)
+	GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository
+}
+
+// matchBitbucketRepo checks if the hook repo is the repository in the event, the owner of a bitbucket repository is the key of the project
+func matchBitbucketRepo(hookRepo *commonmodels.MainHookRepo, repo *bitbucket.Repository) bool {
+	if hookRepo.Source != setting.SourceFromBitbucketServer || repo == nil || repo.Project == nil {
+		return false
+	}
+	return strings.EqualFold(hookRepo.RepoOwner, repo.Project.Key) && hookRepo.RepoName == repo.Slug
+}
+
+type bitbucketPushEventMatcherForWorkflowV4 struct {
+	diffFunc bitbucketDiffFunc
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *bitbucketRefChangeEvent
+}
+
+func (bpem *bitbucketPushEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	ev := bpem.event
+	if !matchBitbucketRepo(hookRepo, ev.Repository) {
+		return false, nil
+	}
+	if !EventConfigured(hookRepo, config.HookEventPush) {
+		return false, nil
+	}
+
+	branch := ev.Change.Ref.DisplayID
+	isRegular := hookRepo.IsRegular
+	if !isRegular && hookRepo.Branch != branch {
+		return false, nil
+	}
+	if isRegular {
+		// Do not use regexp.MustCompile to avoid panic
+		matched, err := regexp.MatchString(hookRepo.Branch, branch)
+		if err != nil || !matched {
+			return false, nil
+		}
+	}
+	hookRepo.Branch = branch
+	if ev.Actor != nil {
+		hookRepo.Committer = ev.Actor.Name
+	}
+
+	changedFiles, err := bpem.diffFunc(ev, hookRepo.CodehostID)
+	if err != nil {
+		bpem.log.Warnf("failed to get changes of event %v", ev)
+		return false, err
+	}
+	return MatchChanges(hookRepo, changedFiles), nil
+}
+
+func (bpem *bitbucketPushEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		RepoOwner:     hookRepo.RepoOwner,
+		Branch:        hookRepo.Branch,
+		Source:        hookRepo.Source,
+	}
+}
+
+type bitbucketMergeEventMatcherForWorkflowV4 struct {
+	diffFunc bitbucketDiffFunc
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *bitbucket.PullRequestEvent
+}
+
+func (bmem *bitbucketMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	pr := bmem.event.PullRequest
+	if pr == nil || pr.ToRef == nil || !matchBitbucketRepo(hookRepo, pr.ToRef.Repository) {
+		return false, nil
+	}
+	if !EventConfigured(hookRepo, config.HookEventPr) {
+		return false, nil
+	}
+
+	isRegular := hookRepo.IsRegular
+	if !isRegular && hookRepo.Branch != pr.ToRef.DisplayID {
+		return false, nil
+	}
+	if isRegular {
+		matched, err := regexp.MatchString(hookRepo.Branch, pr.ToRef.DisplayID)
+		if err != nil || !matched {
+			return false, nil
+		}
+	}
+	hookRepo.Branch = pr.ToRef.DisplayID
+	if pr.Author != nil && pr.Author.User != nil {
+		hookRepo.Committer = pr.Author.User.Name
+	}
+	if !pr.Open {
+		return false, nil
+	}
+
+	changedFiles, err := bmem.diffFunc(bmem.event, hookRepo.CodehostID)
+	if err != nil {
+		bmem.log.Warnf("failed to get changes of event %v", bmem.event)
+		return false, err
+	}
+	bmem.log.Debugf("succeed to get %d changes in merge event", len(changedFiles))
+
+	return MatchChanges(hookRepo, changedFiles), nil
+}
+
+func (bmem *bitbucketMergeEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoOwner:     hookRepo.RepoOwner,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		Branch:        hookRepo.Branch,
+		PR:            bmem.event.PullRequest.ID,
+		Source:        hookRepo.Source,
+	}
+}
+
+type bitbucketTagEventMatcherForWorkflowV4 struct {
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *bitbucketRefChangeEvent
+}
+
+func (btem *bitbucketTagEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	ev := btem.event
+	if !matchBitbucketRepo(hookRepo, ev.Repository) || !EventConfigured(hookRepo, config.HookEventTag) {
+		return false, nil
+	}
+
+	hookRepo.Tag = ev.Change.Ref.DisplayID
+	if ev.Actor != nil {
+		hookRepo.Committer = ev.Actor.Name
+	}
+	return true, nil
+}
+
+func (btem *bitbucketTagEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoOwner:     hookRepo.RepoOwner,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		Branch:        hookRepo.Branch,
+		Tag:           hookRepo.Tag,
+		Source:        hookRepo.Source,
+	}
+}
+
+func createBitbucketEventMatcherForWorkflowV4(
+	event interface{}, diffSrv bitbucketDiffFunc, workflow *commonmodels.WorkflowV4, log *zap.SugaredLogger,
+) bitbucketEventMatcherForWorkflowV4 {
+	switch evt := event.(type) {
+	case *bitbucketRefChangeEvent:
+		if evt.Change.Ref.Type == bitbucket.RefTypeTag {
+			return &bitbucketTagEventMatcherForWorkflowV4{
+				workflow: workflow,
+				log:      log,
+				event:    evt,
+			}
+		}
+		return &bitbucketPushEventMatcherForWorkflowV4{
+			diffFunc: diffSrv,
+			workflow: workflow,
+			log:      log,
+			event:    evt,
+		}
+	case *bitbucket.PullRequestEvent:
+		return &bitbucketMergeEventMatcherForWorkflowV4{
+			diffFunc: diffSrv,
+			log:      log,
+			event:    evt,
+			workflow: workflow,
+		}
+	}
+
+	return nil
+}
+
+func findChangedFilesOfBitbucketEvent(event interface{}, codehostID int) ([]string, error) {
+	detail, err := systemconfig.New().GetCodeHost(codehostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find codehost %d: %v", codehostID, err)
+	}
+	cli, err := bitbucket.NewClient(detail.ID, detail.Address, detail.AccessToken, config.ProxyHTTPSAddr(), detail.EnableProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*bitbucket.Change
+	switch ev := event.(type) {
+	case *bitbucketRefChangeEvent:
+		// all the files of a new branch are regarded as unchanged
+		if ev.Change.FromHash == "" || ev.Change.FromHash == bitbucketEmptyCommit {
+			return nil, nil
+		}
+		changes, err = cli.CompareChanges(ev.Repository.Project.Key, ev.Repository.Slug, ev.Change.ToHash, ev.Change.FromHash)
+	case *bitbucket.PullRequestEvent:
+		repo := ev.PullRequest.ToRef.Repository
+		changes, err = cli.ListPullRequestChanges(repo.Project.Key, repo.Slug, ev.PullRequest.ID)
+	default:
+		return nil, fmt.Errorf("unsupported bitbucket event %T", event)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes from bitbucket, err: %v", err)
+	}
+
+	var changedFiles []string
+	for _, change := range changes {
+		if change.Path != nil {
+			changedFiles = append(changedFiles, change.Path.ToString)
+		}
+		if change.SrcPath != nil {
+			changedFiles = append(changedFiles, change.SrcPath.ToString)
+		}
+	}
+	return changedFiles, nil
+}
+
+func TriggerWorkflowV4ByBitbucketEvent(event interface{}, baseURI, requestID string, log *zap.SugaredLogger) error {
+	workflows, _, err := commonrepo.NewWorkflowV4Coll().List(&commonrepo.ListWorkflowV4Option{}, 0, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("list workflow v4 error: %v", err)
+		log.Error(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	mErr := &multierror.Error{}
+	var notification *commonmodels.Notification
+
+	for _, workflow := range workflows {
+		if workflow.HookCtls == nil {
+			continue
+		}
+		for _, item := range workflow.HookCtls {
+			if !item.Enabled {
+				continue
+			}
+			matcher := createBitbucketEventMatcherForWorkflowV4(event, findChangedFilesOfBitbucketEvent, workflow, log)
+			if matcher == nil {
+				errMsg := fmt.Sprintf("unsupported bitbucket event %T", event)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			matches, err := matcher.Match(item.MainRepo)
+			if err != nil {
+				mErr = multierror.Append(mErr, err)
+			}
+			if !matches {
+				continue
+			}
+
+			log.Infof("event match hook %v of %s", item.MainRepo, workflow.Name)
+			eventRepo := matcher.GetHookRepo(item.MainRepo)
+
+			autoCancelOpt := &AutoCancelOpt{
+				TaskType:     config.WorkflowType,
+				MainRepo:     item.MainRepo,
+				AutoCancel:   item.AutoCancel,
+				WorkflowName: workflow.Name,
+			}
+			var hookPayload *commonmodels.HookPayload
+			var prID int
+			switch ev := event.(type) {
+			case *bitbucket.PullRequestEvent:
+				prID = ev.PullRequest.ID
+				commitID := ev.PullRequest.FromRef.LatestCommit
+				autoCancelOpt.Type = EventTypePR
+				autoCancelOpt.CommitID = commitID
+				autoCancelOpt.MergeRequestID = strconv.Itoa(prID)
+				hookPayload = &commonmodels.HookPayload{
+					Owner:          eventRepo.RepoOwner,
+					Repo:           eventRepo.RepoName,
+					CodehostID:     item.MainRepo.CodehostID,
+					Branch:         eventRepo.Branch,
+					Ref:            commitID,
+					IsPr:           true,
+					MergeRequestID: autoCancelOpt.MergeRequestID,
+					CommitID:       commitID,
+					EventType:      EventTypePR,
+				}
+			case *bitbucketRefChangeEvent:
+				if ev.Change.Ref.Type == bitbucket.RefTypeTag {
+					hookPayload = &commonmodels.HookPayload{
+						EventType: EventTypeTag,
+					}
+					break
+				}
+				autoCancelOpt.Type = EventTypePush
+				autoCancelOpt.CommitID = ev.Change.ToHash
+				autoCancelOpt.Ref = ev.Change.RefID
+				hookPayload = &commonmodels.HookPayload{
+					Owner:      eventRepo.RepoOwner,
+					Repo:       eventRepo.RepoName,
+					CodehostID: item.MainRepo.CodehostID,
+					Branch:     eventRepo.Branch,
+					Ref:        ev.Change.RefID,
+					IsPr:       false,
+					CommitID:   ev.Change.ToHash,
+					EventType:  EventTypePush,
+				}
+			}
+			if autoCancelOpt.Type != "" {
+				err := AutoCancelWorkflowV4Task(autoCancelOpt, log)
+				if err != nil {
+					log.Errorf("failed to auto cancel workflowV4 task when receive event %v due to %v ", event, err)
+					mErr = multierror.Append(mErr, err)
+				}
+
+				if autoCancelOpt.Type == EventTypePR && notification == nil {
+					notification, err = scmnotify.NewService().SendInitWebhookComment(
+						item.MainRepo, prID, baseURI, false, false, false, true, log,
+					)
+					if err != nil {
+						log.Errorf("failed to init webhook comment due to %s", err)
+						mErr = multierror.Append(mErr, err)
+					}
+				}
+			}
+			if err := job.MergeArgs(workflow, item.WorkflowArg); err != nil {
+				errMsg := fmt.Sprintf("merge workflow args error: %v", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			if err := job.MergeWebhookRepo(workflow, eventRepo); err != nil {
+				errMsg := fmt.Sprintf("merge webhook repo info to workflowargs error: %v", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			if notification != nil {
+				workflow.NotificationID = notification.ID.Hex()
+			}
+			workflow.HookPayload = hookPayload
+			if resp, err := workflowservice.CreateWorkflowTaskV4(&workflowservice.CreateWorkflowTaskV4Args{
+				Name: setting.WebhookTaskCreator,
+			}, workflow, log); err != nil {
+				errMsg := fmt.Sprintf("failed to create workflow task when receive bitbucket event due to %v ", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+			} else {
+				if hookPayload.IsPr {
+					// the build status is only used to show the result, this will not cause the function to return error if it fails
+					if err := scmnotify.NewService().CreateGitCheckForWorkflowV4(workflow, resp.TaskID, log); err != nil {
+						log.Warnf("Failed to create bitbucket build status for custom workflow %s, taskID: %d the error is: %s", workflow.Name, resp.TaskID, err)
+					}
+				}
+				log.Infof("succeed to create task %v", resp)
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
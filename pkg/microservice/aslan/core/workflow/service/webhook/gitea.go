@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	gitservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/gitea"
+)
+
+func ProcessGiteaHook(payload []byte, req *http.Request, requestID string, log *zap.SugaredLogger) error {
+	if err := gitea.ValidatePayload(req, payload, gitservice.GetHookSecret()); err != nil {
+		return err
+	}
+
+	eventType := gitea.HookEventType(req)
+	event, err := gitea.ParseHook(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	switch event := event.(type) {
+	case *gitea.PushEvent:
+		//add webhook user
+		if len(event.Commits) > 0 && event.Commits[0].Author != nil {
+			webhookUser := &commonmodels.WebHookUser{
+				Domain:    req.Header.Get("X-Forwarded-Host"),
+				UserName:  event.Commits[0].Author.Name,
+				Email:     event.Commits[0].Author.Email,
+				Source:    setting.SourceFromGitea,
+				CreatedAt: time.Now().Unix(),
+			}
+			commonrepo.NewWebHookUserColl().Upsert(webhookUser)
+		}
+	case *gitea.PullRequestEvent:
+		if event.Action != gitea.PullRequestActionOpened && event.Action != gitea.PullRequestActionReopened && event.Action != gitea.PullRequestActionSynchronized {
+			return fmt.Errorf("action %s is skipped", event.Action)
+		}
+	}
+
+	return TriggerWorkflowV4ByGiteaEvent(event, config.SystemAddress(), requestID, log)
+}
@@ -0,0 +1,382 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/scmnotify"
+	workflowservice "github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow/job"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	"github.com/koderover/zadig/pkg/tool/gitea"
+	"github.com/koderover/zadig/pkg/types"
+)
+
+type giteaPullRequestDiffFunc func(event *gitea.PullRequestEvent, codehostID int) ([]string, error)
+
+type giteaEventMatcherForWorkflowV4 interface {
+	Match(*commonmodels.MainHookRepo) (bool, error)
+	GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository
+}
+
+type giteaPushEventMatcherForWorkflowV4 struct {
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *gitea.PushEvent
+}
+
+func (gpem *giteaPushEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	ev := gpem.event
+	if hookRepo.Source != setting.SourceFromGitea || ev.Repo == nil {
+		return false, nil
+	}
+	if (hookRepo.RepoOwner + "/" + hookRepo.RepoName) == ev.Repo.FullName {
+		if !EventConfigured(hookRepo, config.HookEventPush) {
+			return false, nil
+		}
+
+		branch := getBranchFromRef(ev.Ref)
+		isRegular := hookRepo.IsRegular
+		if !isRegular && hookRepo.Branch != branch {
+			return false, nil
+		}
+		if isRegular {
+			// Do not use regexp.MustCompile to avoid panic
+			matched, err := regexp.MatchString(hookRepo.Branch, branch)
+			if err != nil || !matched {
+				return false, nil
+			}
+		}
+		hookRepo.Branch = branch
+		if ev.Pusher != nil {
+			hookRepo.Committer = ev.Pusher.Login
+		}
+		var changedFiles []string
+		for _, commit := range ev.Commits {
+			changedFiles = append(changedFiles, commit.Added...)
+			changedFiles = append(changedFiles, commit.Removed...)
+			changedFiles = append(changedFiles, commit.Modified...)
+		}
+		return MatchChanges(hookRepo, changedFiles), nil
+	}
+
+	return false, nil
+}
+
+func (gpem *giteaPushEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		RepoOwner:     hookRepo.RepoOwner,
+		Branch:        hookRepo.Branch,
+		Source:        hookRepo.Source,
+	}
+}
+
+type giteaMergeEventMatcherForWorkflowV4 struct {
+	diffFunc giteaPullRequestDiffFunc
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *gitea.PullRequestEvent
+}
+
+func (gmem *giteaMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	ev := gmem.event
+	if hookRepo.Source != setting.SourceFromGitea || ev.PullRequest == nil || ev.PullRequest.Base == nil || ev.PullRequest.Base.Repository == nil {
+		return false, nil
+	}
+	if (hookRepo.RepoOwner + "/" + hookRepo.RepoName) == ev.PullRequest.Base.Repository.FullName {
+		if !EventConfigured(hookRepo, config.HookEventPr) {
+			return false, nil
+		}
+
+		isRegular := hookRepo.IsRegular
+		if !isRegular && hookRepo.Branch != ev.PullRequest.Base.Ref {
+			return false, nil
+		}
+		if isRegular {
+			matched, err := regexp.MatchString(hookRepo.Branch, ev.PullRequest.Base.Ref)
+			if err != nil || !matched {
+				return false, nil
+			}
+		}
+		hookRepo.Branch = ev.PullRequest.Base.Ref
+		if ev.PullRequest.User != nil {
+			hookRepo.Committer = ev.PullRequest.User.Login
+		}
+		if ev.PullRequest.State == "open" {
+			changedFiles, err := gmem.diffFunc(ev, hookRepo.CodehostID)
+			if err != nil {
+				gmem.log.Warnf("failed to get changes of event %v", ev)
+				return false, err
+			}
+			gmem.log.Debugf("succeed to get %d changes in merge event", len(changedFiles))
+
+			return MatchChanges(hookRepo, changedFiles), nil
+		}
+	}
+	return false, nil
+}
+
+func (gmem *giteaMergeEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoOwner:     hookRepo.RepoOwner,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		Branch:        hookRepo.Branch,
+		PR:            gmem.event.PullRequest.Number,
+		Source:        hookRepo.Source,
+	}
+}
+
+type giteaTagEventMatcherForWorkflowV4 struct {
+	log      *zap.SugaredLogger
+	workflow *commonmodels.WorkflowV4
+	event    *gitea.TagPushEvent
+}
+
+func (gtem *giteaTagEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
+	ev := gtem.event
+	if hookRepo.Source != setting.SourceFromGitea || ev.Repo == nil {
+		return false, nil
+	}
+	if (hookRepo.RepoOwner + "/" + hookRepo.RepoName) == ev.Repo.FullName {
+		if !EventConfigured(hookRepo, config.HookEventTag) {
+			return false, nil
+		}
+
+		hookRepo.Tag = ev.Tag()
+		if ev.Sender != nil {
+			hookRepo.Committer = ev.Sender.Login
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (gtem *giteaTagEventMatcherForWorkflowV4) GetHookRepo(hookRepo *commonmodels.MainHookRepo) *types.Repository {
+	return &types.Repository{
+		CodehostID:    hookRepo.CodehostID,
+		RepoName:      hookRepo.RepoName,
+		RepoOwner:     hookRepo.RepoOwner,
+		RepoNamespace: hookRepo.GetRepoNamespace(),
+		Branch:        hookRepo.Branch,
+		Tag:           hookRepo.Tag,
+		Source:        hookRepo.Source,
+	}
+}
+
+func createGiteaEventMatcherForWorkflowV4(
+	event interface{}, diffSrv giteaPullRequestDiffFunc, workflow *commonmodels.WorkflowV4, log *zap.SugaredLogger,
+) giteaEventMatcherForWorkflowV4 {
+	switch evt := event.(type) {
+	case *gitea.PushEvent:
+		return &giteaPushEventMatcherForWorkflowV4{
+			workflow: workflow,
+			log:      log,
+			event:    evt,
+		}
+	case *gitea.PullRequestEvent:
+		return &giteaMergeEventMatcherForWorkflowV4{
+			diffFunc: diffSrv,
+			log:      log,
+			event:    evt,
+			workflow: workflow,
+		}
+	case *gitea.TagPushEvent:
+		return &giteaTagEventMatcherForWorkflowV4{
+			workflow: workflow,
+			log:      log,
+			event:    evt,
+		}
+	}
+
+	return nil
+}
+
+func findChangedFilesOfGiteaPullRequest(event *gitea.PullRequestEvent, codehostID int) ([]string, error) {
+	detail, err := systemconfig.New().GetCodeHost(codehostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find codehost %d: %v", codehostID, err)
+	}
+	cli, err := gitea.NewClient(detail.ID, detail.Address, detail.AccessToken, config.ProxyHTTPSAddr(), detail.EnableProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := event.PullRequest.Base.Repository
+	files, err := cli.ListPullRequestFiles(repo.Owner.Login, repo.Name, event.PullRequest.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes from gitea, err: %v", err)
+	}
+	var changedFiles []string
+	for _, file := range files {
+		changedFiles = append(changedFiles, file.Filename)
+		if file.PreviousFilename != "" {
+			changedFiles = append(changedFiles, file.PreviousFilename)
+		}
+	}
+	return changedFiles, nil
+}
+
+func TriggerWorkflowV4ByGiteaEvent(event interface{}, baseURI, requestID string, log *zap.SugaredLogger) error {
+	workflows, _, err := commonrepo.NewWorkflowV4Coll().List(&commonrepo.ListWorkflowV4Option{}, 0, 0)
+	if err != nil {
+		errMsg := fmt.Sprintf("list workflow v4 error: %v", err)
+		log.Error(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	mErr := &multierror.Error{}
+	var notification *commonmodels.Notification
+
+	for _, workflow := range workflows {
+		if workflow.HookCtls == nil {
+			continue
+		}
+		for _, item := range workflow.HookCtls {
+			if !item.Enabled {
+				continue
+			}
+			matcher := createGiteaEventMatcherForWorkflowV4(event, findChangedFilesOfGiteaPullRequest, workflow, log)
+			if matcher == nil {
+				errMsg := fmt.Sprintf("unsupported gitea event %T", event)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			matches, err := matcher.Match(item.MainRepo)
+			if err != nil {
+				mErr = multierror.Append(mErr, err)
+			}
+			if !matches {
+				continue
+			}
+
+			log.Infof("event match hook %v of %s", item.MainRepo, workflow.Name)
+			eventRepo := matcher.GetHookRepo(item.MainRepo)
+
+			autoCancelOpt := &AutoCancelOpt{
+				TaskType:     config.WorkflowType,
+				MainRepo:     item.MainRepo,
+				AutoCancel:   item.AutoCancel,
+				WorkflowName: workflow.Name,
+			}
+			var hookPayload *commonmodels.HookPayload
+			var prID int
+			switch ev := event.(type) {
+			case *gitea.PullRequestEvent:
+				prID = ev.PullRequest.Number
+				autoCancelOpt.Type = EventTypePR
+				autoCancelOpt.CommitID = ev.PullRequest.Head.Sha
+				autoCancelOpt.MergeRequestID = strconv.Itoa(prID)
+				hookPayload = &commonmodels.HookPayload{
+					Owner:          eventRepo.RepoOwner,
+					Repo:           eventRepo.RepoName,
+					CodehostID:     item.MainRepo.CodehostID,
+					Branch:         eventRepo.Branch,
+					Ref:            ev.PullRequest.Head.Sha,
+					IsPr:           true,
+					MergeRequestID: autoCancelOpt.MergeRequestID,
+					CommitID:       ev.PullRequest.Head.Sha,
+					EventType:      EventTypePR,
+				}
+			case *gitea.PushEvent:
+				autoCancelOpt.Type = EventTypePush
+				autoCancelOpt.CommitID = ev.After
+				autoCancelOpt.Ref = ev.Ref
+				hookPayload = &commonmodels.HookPayload{
+					Owner:      eventRepo.RepoOwner,
+					Repo:       eventRepo.RepoName,
+					CodehostID: item.MainRepo.CodehostID,
+					Branch:     eventRepo.Branch,
+					Ref:        ev.Ref,
+					IsPr:       false,
+					CommitID:   ev.After,
+					EventType:  EventTypePush,
+				}
+			case *gitea.TagPushEvent:
+				hookPayload = &commonmodels.HookPayload{
+					EventType: EventTypeTag,
+				}
+			}
+			if autoCancelOpt.Type != "" {
+				err := AutoCancelWorkflowV4Task(autoCancelOpt, log)
+				if err != nil {
+					log.Errorf("failed to auto cancel workflowV4 task when receive event %v due to %v ", event, err)
+					mErr = multierror.Append(mErr, err)
+				}
+
+				if autoCancelOpt.Type == EventTypePR && notification == nil {
+					notification, err = scmnotify.NewService().SendInitWebhookComment(
+						item.MainRepo, prID, baseURI, false, false, false, true, log,
+					)
+					if err != nil {
+						log.Errorf("failed to init webhook comment due to %s", err)
+						mErr = multierror.Append(mErr, err)
+					}
+				}
+			}
+			if err := job.MergeArgs(workflow, item.WorkflowArg); err != nil {
+				errMsg := fmt.Sprintf("merge workflow args error: %v", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			if err := job.MergeWebhookRepo(workflow, eventRepo); err != nil {
+				errMsg := fmt.Sprintf("merge webhook repo info to workflowargs error: %v", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+				continue
+			}
+			if notification != nil {
+				workflow.NotificationID = notification.ID.Hex()
+			}
+			workflow.HookPayload = hookPayload
+			if resp, err := workflowservice.CreateWorkflowTaskV4(&workflowservice.CreateWorkflowTaskV4Args{
+				Name: setting.WebhookTaskCreator,
+			}, workflow, log); err != nil {
+				errMsg := fmt.Sprintf("failed to create workflow task when receive gitea event due to %v ", err)
+				log.Error(errMsg)
+				mErr = multierror.Append(mErr, fmt.Errorf(errMsg))
+			} else {
+				if hookPayload.IsPr {
+					// the commit status is only used to show the result, this will not cause the function to return error if it fails
+					if err := scmnotify.NewService().CreateGitCheckForWorkflowV4(workflow, resp.TaskID, log); err != nil {
+						log.Warnf("Failed to create gitea commit status for custom workflow %s, taskID: %d the error is: %s", workflow.Name, resp.TaskID, err)
+					}
+				}
+				log.Infof("succeed to create task %v", resp)
+			}
+		}
+	}
+	return mErr.ErrorOrNil()
+}
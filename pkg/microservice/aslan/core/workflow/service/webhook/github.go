@@ -485,6 +485,10 @@ func ProcessGithubWebHook(payload []byte, req *http.Request, requestID string, l
 		if err = updateServiceTemplateByGithubPush(et, log); err != nil {
 			log.Errorf("updateServiceTemplateByGithubPush failed, error:%v", err)
 		}
+		// sync workflows managed in the repo
+		if err = syncWorkflowsByGithubPush(et, log); err != nil {
+			log.Errorf("syncWorkflowsByGithubPush failed, error:%v", err)
+		}
 
 		//add webhook user
 		if et.Pusher != nil {
@@ -636,6 +640,16 @@ func updateServiceTemplateByGithubPush(pushEvent *github.PushEvent, log *zap.Sug
 	return errs.ErrorOrNil()
 }
 
+func syncWorkflowsByGithubPush(pushEvent *github.PushEvent, log *zap.SugaredLogger) error {
+	changeFiles := make([]string, 0)
+	for _, commit := range pushEvent.Commits {
+		changeFiles = append(changeFiles, commit.Added...)
+		changeFiles = append(changeFiles, commit.Removed...)
+		changeFiles = append(changeFiles, commit.Modified...)
+	}
+	return workflowservice.SyncWorkflowsByPushEvent(pushEvent.GetRepo().GetFullName(), getBranchFromRef(pushEvent.GetRef()), changeFiles, log)
+}
+
 func GetGithubServiceTemplates() ([]*commonmodels.Service, error) {
 	opt := &commonrepo.ServiceListOption{
 		Source: setting.SourceFromGithub,
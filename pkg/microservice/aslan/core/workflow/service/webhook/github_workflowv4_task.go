@@ -96,6 +96,8 @@ type githubMergeEventMatcherForWorkflowV4 struct {
 	log      *zap.SugaredLogger
 	workflow *commonmodels.WorkflowV4
 	event    *github.PullRequestEvent
+	// changedFiles are kept for the diff coverage of the triggered task
+	changedFiles []string
 }
 
 func (gmem *githubMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
@@ -128,6 +130,7 @@ func (gmem *githubMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.M
 			return false, err
 		}
 		gmem.log.Debugf("succeed to get %d changes in merge event", len(changedFiles))
+		gmem.changedFiles = changedFiles
 
 		return MatchChanges(hookRepo, changedFiles), nil
 	}
@@ -278,6 +281,9 @@ func TriggerWorkflowV4ByGithubEvent(event interface{}, baseURI, deliveryID, requ
 					CommitID:       commitID,
 					EventType:      eventType,
 				}
+				if mergeMatcher, ok := matcher.(*githubMergeEventMatcherForWorkflowV4); ok {
+					hookPayload.ChangedFiles = mergeMatcher.changedFiles
+				}
 			case *github.PushEvent:
 				if ev.GetRef() != "" && ev.GetHeadCommit().GetID() != "" {
 					eventType = EventTypePush
@@ -31,6 +31,7 @@ import (
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	gitservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	workflowservice "github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow"
 	"github.com/koderover/zadig/pkg/setting"
 	e "github.com/koderover/zadig/pkg/tool/errors"
 )
@@ -93,6 +94,10 @@ func ProcessGitlabHook(payload []byte, req *http.Request, requestID string, log
 		if err = updateServiceTemplateByPushEvent(changeFiles, pathWithNamespace, log); err != nil {
 			errorList = multierror.Append(errorList, err)
 		}
+		// trigger workflows managed in the repo to re-sync
+		if err = workflowservice.SyncWorkflowsByPushEvent(pathWithNamespace, getBranchFromRef(pushEvent.Ref), changeFiles, log); err != nil {
+			errorList = multierror.Append(errorList, err)
+		}
 	case *gitlab.MergeEvent:
 		mergeEvent = event
 	case *gitlab.TagEvent:
@@ -46,6 +46,8 @@ type gitlabMergeEventMatcherForWorkflowV4 struct {
 	trigger            *TriggerYaml
 	isYaml             bool
 	yamlServiceChanged []BuildServices
+	// changedFiles are kept for the diff coverage of the triggered task
+	changedFiles []string
 }
 
 func (gmem *gitlabMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.MainHookRepo) (bool, error) {
@@ -89,6 +91,7 @@ func (gmem *gitlabMergeEventMatcherForWorkflowV4) Match(hookRepo *commonmodels.M
 			return false, err
 		}
 		gmem.log.Debugf("succeed to get %d changes in merge event", len(changedFiles))
+		gmem.changedFiles = changedFiles
 		if gmem.isYaml {
 			serviceChangeds := ServicesMatchChangesFiles(gmem.trigger.Rules.MatchFolders, changedFiles)
 			gmem.yamlServiceChanged = serviceChangeds
@@ -363,6 +366,9 @@ func TriggerWorkflowV4ByGitlabEvent(event interface{}, baseURI, requestID string
 					CodehostID:     eventRepo.CodehostID,
 					EventType:      eventType,
 				}
+				if mergeMatcher, ok := matcher.(*gitlabMergeEventMatcherForWorkflowV4); ok {
+					hookPayload.ChangedFiles = mergeMatcher.changedFiles
+				}
 			case *gitlab.PushEvent:
 				eventType = EventTypePush
 				ref = ev.Ref
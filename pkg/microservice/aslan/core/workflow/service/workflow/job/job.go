@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Knetic/govaluate"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -111,6 +112,8 @@ func InitJobCtl(job *commonmodels.Job, workflow *commonmodels.WorkflowV4) (JobCt
 		resp = &MseGrayOfflineJob{job: job, workflow: workflow}
 	case config.JobGuanceyunCheck:
 		resp = &GuanceyunCheckJob{job: job, workflow: workflow}
+	case config.JobPrometheusCheck:
+		resp = &PrometheusCheckJob{job: job, workflow: workflow}
 	case config.JobJenkins:
 		resp = &JenkinsJob{job: job, workflow: workflow}
 	case config.JobSQL:
@@ -166,14 +169,44 @@ func ToJobs(job *commonmodels.Job, workflow *commonmodels.WorkflowV4, taskID int
 	if err != nil {
 		return []*commonmodels.JobTask{}, warpJobError(job.Name, err)
 	}
-	return jobCtl.ToJobs(taskID)
+	jobTasks, err := jobCtl.ToJobs(taskID)
+	if err != nil {
+		return jobTasks, err
+	}
+	matrix, err := getJobMatrix(job)
+	if err != nil {
+		return jobTasks, warpJobError(job.Name, err)
+	}
+	if matrix != nil {
+		if jobTasks, err = expandMatrixJobTasks(jobTasks, matrix); err != nil {
+			return jobTasks, warpJobError(job.Name, err)
+		}
+	}
+	for _, jobTask := range jobTasks {
+		jobTask.OriginName = job.Name
+		jobTask.DependsOn = job.DependsOn
+		jobTask.If = job.If
+	}
+	return jobTasks, nil
 }
 
 func LintJob(job *commonmodels.Job, workflow *commonmodels.WorkflowV4) error {
+	if strings.TrimSpace(job.If) != "" {
+		if _, err := govaluate.NewEvaluableExpression(job.If); err != nil {
+			return warpJobError(job.Name, fmt.Errorf("invalid if expression %s: %v", job.If, err))
+		}
+	}
 	jobCtl, err := InitJobCtl(job, workflow)
 	if err != nil {
 		return warpJobError(job.Name, err)
 	}
+	matrix, err := getJobMatrix(job)
+	if err != nil {
+		return warpJobError(job.Name, err)
+	}
+	if err := lintJobMatrix(matrix); err != nil {
+		return warpJobError(job.Name, err)
+	}
 	return jobCtl.LintJob()
 }
 
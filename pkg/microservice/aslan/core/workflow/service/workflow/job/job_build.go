@@ -201,6 +201,10 @@ func (j *BuildJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, error) {
 	if err != nil {
 		return resp, fmt.Errorf("find default s3 storage error: %v", err)
 	}
+	registriesTLS, err := getRegistriesTLS()
+	if err != nil {
+		return resp, fmt.Errorf("find docker registries error: %v", err)
+	}
 
 	for _, build := range j.spec.ServiceAndBuilds {
 		imageTag := commonservice.ReleaseCandidate(build.Repos, taskID, j.workflow.Project, build.ServiceModule, "", build.ImageName, "image")
@@ -367,6 +371,11 @@ func (j *BuildJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, error) {
 					ImageReleaseTag:       imageTag,
 					BuildArgs:             buildInfo.PostBuild.DockerBuild.BuildArgs,
 					DockerTemplateContent: dockefileContent,
+					Platforms:             j.spec.Platforms,
+					EmulatorImage:         j.emulatorImage(),
+					Cache:                 j.spec.BuildCache.ForServiceModule(build.ServiceName, build.ServiceModule),
+					Builder:               step.DockerBuilder(buildInfo.PostBuild.DockerBuild.Builder),
+					Registries:            registriesTLS,
 					DockerRegistry: &step.DockerRegistry{
 						DockerRegistryID: j.spec.DockerRegistryID,
 						Host:             registry.RegAddr,
@@ -603,10 +612,58 @@ func mergeRepos(templateRepos []*types.Repository, customRepos []*types.Reposito
 	return templateRepos
 }
 
+// getRegistriesTLS returns the TLS settings of the registries, the same settings are applied to the dind daemon
+func getRegistriesTLS() ([]*step.RegistryTLS, error) {
+	registries, err := commonrepo.NewRegistryNamespaceColl().FindAll(&commonrepo.FindRegOps{})
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*step.RegistryTLS, 0)
+	for _, reg := range registries {
+		// compatibility changes before 1.11
+		if reg.AdvancedSetting == nil {
+			continue
+		}
+		resp = append(resp, &step.RegistryTLS{
+			RegAddr:    reg.RegAddr,
+			TLSEnabled: reg.AdvancedSetting.TLSEnabled,
+			TLSCert:    reg.AdvancedSetting.TLSCert,
+		})
+	}
+	return resp, nil
+}
+
 func (j *BuildJob) LintJob() error {
+	j.spec = &commonmodels.ZadigBuildJobSpec{}
+	if err := commonmodels.IToiYaml(j.job.Spec, j.spec); err != nil {
+		return err
+	}
+	for _, platform := range j.spec.Platforms {
+		if len(strings.Split(platform, "/")) < 2 {
+			return fmt.Errorf("invalid platform %s in job %s, should be in the form of os/arch[/variant]", platform, j.job.Name)
+		}
+	}
+	if j.spec.BuildCache != nil {
+		if err := j.spec.BuildCache.Validate(); err != nil {
+			return fmt.Errorf("invalid build cache in job %s: %v", j.job.Name, err)
+		}
+	}
+	if j.spec.InstallEmulators {
+		if err := step.ValidateEmulatorImage(config.BinfmtImage()); err != nil {
+			return fmt.Errorf("failed to install the emulators in job %s: %v", j.job.Name, err)
+		}
+	}
 	return nil
 }
 
+// emulatorImage returns the binfmt image of the docker build steps, it is empty unless the job opts in
+func (j *BuildJob) emulatorImage() string {
+	if !j.spec.InstallEmulators {
+		return ""
+	}
+	return config.BinfmtImage()
+}
+
 func (j *BuildJob) GetOutPuts(log *zap.SugaredLogger) []string {
 	resp := []string{}
 	j.spec = &commonmodels.ZadigBuildJobSpec{}
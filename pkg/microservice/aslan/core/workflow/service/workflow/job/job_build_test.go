@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/types"
+)
+
+func TestBuildJobLintJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *commonmodels.ZadigBuildJobSpec
+		wantErr bool
+	}{
+		{
+			name: "no platforms and cache",
+			spec: &commonmodels.ZadigBuildJobSpec{},
+		},
+		{
+			name: "valid platforms and cache",
+			spec: &commonmodels.ZadigBuildJobSpec{
+				Platforms:  []string{"linux/amd64", "linux/arm/v7"},
+				BuildCache: &types.BuildKitCache{Type: types.BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache"},
+			},
+		},
+		{
+			name:    "platform without arch",
+			spec:    &commonmodels.ZadigBuildJobSpec{Platforms: []string{"linux/amd64", "arm64"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid build cache",
+			spec:    &commonmodels.ZadigBuildJobSpec{BuildCache: &types.BuildKitCache{Type: types.BuildKitLocalCache}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &BuildJob{job: &commonmodels.Job{Name: "build", Spec: tt.spec}}
+			err := j.LintJob()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -19,11 +19,15 @@ package job
 import (
 	"fmt"
 
+	"github.com/blang/semver/v4"
+
 	"github.com/koderover/zadig/pkg/microservice/aslan/config"
 	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
 	templaterepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb/template"
+	commonutil "github.com/koderover/zadig/pkg/microservice/aslan/core/common/util"
 	"github.com/koderover/zadig/pkg/setting"
+	helmtool "github.com/koderover/zadig/pkg/tool/helmclient"
 )
 
 type HelmChartDeployJob struct {
@@ -120,6 +124,17 @@ func (j *HelmChartDeployJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, erro
 	}
 
 	for _, deploy := range j.spec.DeployHelmCharts {
+		chartVersion, err := resolveHelmChartVersion(deploy)
+		if err != nil {
+			return resp, err
+		}
+		deploy = &commonmodels.DeployHelmChart{
+			ReleaseName:  deploy.ReleaseName,
+			ChartRepo:    deploy.ChartRepo,
+			ChartName:    deploy.ChartName,
+			ChartVersion: chartVersion,
+			ValuesYaml:   deploy.ValuesYaml,
+		}
 		jobTaskSpec := &commonmodels.JobTaskHelmChartDeploySpec{
 			Env:                envName,
 			DeployHelmChart:    deploy,
@@ -150,3 +165,23 @@ func (j *HelmChartDeployJob) LintJob() error {
 	}
 	return nil
 }
+
+// resolveHelmChartVersion resolves the version of the chart to deploy, ChartVersion of the deploy may be a semver constraint or empty
+func resolveHelmChartVersion(deploy *commonmodels.DeployHelmChart) (string, error) {
+	if _, err := semver.Parse(deploy.ChartVersion); err == nil {
+		return deploy.ChartVersion, nil
+	}
+	chartRepo, err := commonrepo.NewHelmRepoColl().Find(&commonrepo.HelmRepoFindOption{RepoName: deploy.ChartRepo})
+	if err != nil {
+		return "", fmt.Errorf("failed to find chart repo %s: %w", deploy.ChartRepo, err)
+	}
+	client, err := helmtool.NewClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to create helm client: %w", err)
+	}
+	chartVersion, err := client.GetChartVersion(commonutil.GeneHelmRepo(chartRepo), deploy.ChartName, deploy.ChartVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve version %s of chart %s/%s: %w", deploy.ChartVersion, deploy.ChartRepo, deploy.ChartName, err)
+	}
+	return chartVersion, nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/tool/prometheus"
+)
+
+type PrometheusCheckJob struct {
+	job      *commonmodels.Job
+	workflow *commonmodels.WorkflowV4
+	spec     *commonmodels.PrometheusCheckJobSpec
+}
+
+func (j *PrometheusCheckJob) Instantiate() error {
+	j.spec = &commonmodels.PrometheusCheckJobSpec{}
+	if err := commonmodels.IToiYaml(j.job.Spec, j.spec); err != nil {
+		return err
+	}
+	j.job.Spec = j.spec
+	return nil
+}
+
+func (j *PrometheusCheckJob) SetPreset() error {
+	j.spec = &commonmodels.PrometheusCheckJobSpec{}
+	if err := commonmodels.IToi(j.job.Spec, j.spec); err != nil {
+		return err
+	}
+	j.job.Spec = j.spec
+	return nil
+}
+
+func (j *PrometheusCheckJob) MergeArgs(args *commonmodels.Job) error {
+	j.spec = &commonmodels.PrometheusCheckJobSpec{}
+	if err := commonmodels.IToi(args.Spec, j.spec); err != nil {
+		return err
+	}
+	j.job.Spec = j.spec
+	return nil
+}
+
+func (j *PrometheusCheckJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, error) {
+	j.spec = &commonmodels.PrometheusCheckJobSpec{}
+	if err := commonmodels.IToi(j.job.Spec, j.spec); err != nil {
+		return nil, err
+	}
+	j.job.Spec = j.spec
+
+	nameSet := sets.NewString()
+	for _, metric := range j.spec.Metrics {
+		if nameSet.Has(metric.Name) {
+			return nil, errors.Errorf("duplicate metric name %s", metric.Name)
+		}
+		nameSet.Insert(metric.Name)
+		metric.Status = "checking"
+	}
+
+	rollbackJobs := make([]*commonmodels.JobTask, 0)
+	if j.spec.RollbackJobName != "" {
+		rollbackJob, err := j.getRollbackJob()
+		if err != nil {
+			return nil, err
+		}
+		if rollbackJobs, err = ToJobs(rollbackJob, j.workflow, taskID); err != nil {
+			return nil, errors.Wrapf(err, "failed to generate rollback job %s", rollbackJob.Name)
+		}
+	}
+
+	jobTask := &commonmodels.JobTask{
+		Name: j.job.Name,
+		Key:  j.job.Name,
+		JobInfo: map[string]string{
+			JobNameKey: j.job.Name,
+		},
+		JobType: string(config.JobPrometheusCheck),
+		Spec: &commonmodels.JobTaskPrometheusCheckSpec{
+			ID:           j.spec.ID,
+			Name:         j.spec.Name,
+			BakeTime:     j.spec.BakeTime,
+			Interval:     j.spec.Interval,
+			FailureLimit: j.spec.FailureLimit,
+			Metrics:      j.spec.Metrics,
+			RollbackJobs: rollbackJobs,
+		},
+	}
+	return []*commonmodels.JobTask{jobTask}, nil
+}
+
+func (j *PrometheusCheckJob) LintJob() error {
+	j.spec = &commonmodels.PrometheusCheckJobSpec{}
+	if err := commonmodels.IToi(j.job.Spec, j.spec); err != nil {
+		return err
+	}
+	if j.spec.BakeTime <= 0 {
+		return errors.Errorf("bake time must be greater than 0")
+	}
+	if j.spec.Interval < 0 {
+		return errors.Errorf("interval must not be less than 0")
+	}
+	if j.spec.FailureLimit < 0 {
+		return errors.Errorf("failure limit must not be less than 0")
+	}
+	if len(j.spec.Metrics) == 0 {
+		return errors.Errorf("num of metrics must be greater than 0")
+	}
+	for _, metric := range j.spec.Metrics {
+		if metric.Query == "" {
+			return errors.Errorf("query of metric %s is empty", metric.Name)
+		}
+		if _, err := prometheus.Compare(0, metric.Operator, 0); err != nil {
+			return errors.Wrapf(err, "metric %s", metric.Name)
+		}
+		if metric.Tolerance < 0 {
+			return errors.Errorf("tolerance of metric %s must not be less than 0", metric.Name)
+		}
+	}
+	if j.spec.RollbackJobName != "" {
+		if _, err := j.getRollbackJob(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *PrometheusCheckJob) getRollbackJob() (*commonmodels.Job, error) {
+	for _, stage := range j.workflow.Stages {
+		for _, job := range stage.Jobs {
+			if job.Name != j.spec.RollbackJobName {
+				continue
+			}
+			if job.JobType != config.JobIstioRollback && job.JobType != config.JobK8sGrayRollback {
+				return nil, errors.Errorf("rollback job %s must be an istio rollback or gray rollback job", job.Name)
+			}
+			return job, nil
+		}
+	}
+	return nil, errors.Errorf("rollback job %s not found", j.spec.RollbackJobName)
+}
+
+// AnalysisRollbackJobs returns the names of the rollback jobs run by the prometheus check jobs of the workflow,
+// they are not run in their own stages.
+func AnalysisRollbackJobs(workflow *commonmodels.WorkflowV4) sets.String {
+	resp := sets.NewString()
+	for _, stage := range workflow.Stages {
+		for _, job := range stage.Jobs {
+			if job.JobType != config.JobPrometheusCheck || JobSkiped(job) {
+				continue
+			}
+			spec := &commonmodels.PrometheusCheckJobSpec{}
+			if err := commonmodels.IToi(job.Spec, spec); err != nil {
+				continue
+			}
+			if spec.RollbackJobName != "" {
+				resp.Insert(spec.RollbackJobName)
+			}
+		}
+	}
+	return resp
+}
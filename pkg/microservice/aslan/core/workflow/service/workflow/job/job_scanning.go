@@ -19,6 +19,7 @@ package job
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 
 	"go.uber.org/zap"
@@ -316,6 +317,32 @@ func (j *ScanningJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, error) {
 				},
 			}
 			jobTaskSpec.Steps = append(jobTaskSpec.Steps, shellStep)
+
+			if scanningInfo.SarifReport != nil && scanningInfo.SarifReport.Enabled {
+				sarifSpec := &step.StepSarifReportSpec{
+					ReportDir:         scanningInfo.SarifReport.ReportDir,
+					DestDir:           "/tmp",
+					S3DestDir:         path.Join(j.workflow.Name, fmt.Sprint(taskID), jobTask.Name, "sarif"),
+					FileName:          "findings.json",
+					SeverityThreshold: scanningInfo.SarifReport.SeverityThreshold,
+					GateNewOnly:       scanningInfo.SarifReport.GateNewOnly,
+					ProjectName:       j.workflow.Project,
+					ScanningName:      scanning.Name,
+				}
+				// the branch configured in the scanning is the default branch, its runs are the baseline of the pull requests
+				if len(scanning.Repos) > 0 {
+					sarifSpec.Branch = scanning.Repos[0].Branch
+					sarifSpec.DefaultBranch = scanning.Repos[0].PR == 0 && len(scanning.Repos[0].PRs) == 0 && scanning.Repos[0].Branch == branch
+				}
+				sarifStep := &commonmodels.StepTask{
+					Name:      scanning.Name + "-sarif-report",
+					JobName:   jobTask.Name,
+					StepType:  config.StepSarifReport,
+					Onfailure: true,
+					Spec:      sarifSpec,
+				}
+				jobTaskSpec.Steps = append(jobTaskSpec.Steps, sarifStep)
+			}
 		}
 		// init debug after step
 		debugAfterStep := &commonmodels.StepTask{
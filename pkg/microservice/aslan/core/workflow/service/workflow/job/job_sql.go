@@ -72,9 +72,10 @@ func (j *SQLJob) ToJobs(taskID int64) ([]*commonmodels.JobTask, error) {
 		Key:     j.job.Name,
 		JobType: string(config.JobSQL),
 		Spec: &commonmodels.JobTaskSQLSpec{
-			ID:   j.spec.ID,
-			Type: j.spec.Type,
-			SQL:  j.spec.SQL,
+			ID:          j.spec.ID,
+			Type:        j.spec.Type,
+			SQL:         j.spec.SQL,
+			Transaction: j.spec.Transaction,
 		},
 		Timeout: 0,
 	}
@@ -86,8 +87,12 @@ func (j *SQLJob) LintJob() error {
 	if err := commonmodels.IToiYaml(j.job.Spec, j.spec); err != nil {
 		return err
 	}
-	if _, err := mongodb.NewDBInstanceColl().Find(&mongodb.DBInstanceCollFindOption{Id: j.spec.ID}); err != nil {
+	info, err := mongodb.NewDBInstanceColl().Find(&mongodb.DBInstanceCollFindOption{Id: j.spec.ID})
+	if err != nil {
 		return errors.Errorf("not found db instance in mongo, err: %v", err)
 	}
+	if j.spec.Transaction && info.Type == config.DBInstanceTypeClickHouse {
+		return errors.Errorf("transaction is not supported by %s", info.Type)
+	}
 	return nil
 }
@@ -455,22 +455,61 @@ func (j *TestingJob) toJobtask(testing *commonmodels.TestModule, defaultS3 *comm
 
 	// init junit report step
 	if len(testingInfo.TestResultPath) > 0 {
+		junitSpec := &step.StepJunitReportSpec{
+			ReportDir: testingInfo.TestResultPath,
+			S3DestDir: path.Join(j.workflow.Name, fmt.Sprint(taskID), jobTask.Name, "junit"),
+			TestName:  testing.Name,
+			DestDir:   "/tmp",
+			FileName:  "merged.xml",
+		}
+		if len(testing.Repos) > 0 {
+			junitSpec.CommitID = testing.Repos[0].CommitID
+			junitSpec.Branch = testing.Repos[0].Branch
+		}
 		junitStep := &commonmodels.StepTask{
 			Name:      config.TestJobJunitReportStepName,
 			JobName:   jobTask.Name,
 			StepType:  config.StepJunitReport,
 			Onfailure: true,
-			Spec: &step.StepJunitReportSpec{
-				ReportDir: testingInfo.TestResultPath,
-				S3DestDir: path.Join(j.workflow.Name, fmt.Sprint(taskID), jobTask.Name, "junit"),
-				TestName:  testing.Name,
-				DestDir:   "/tmp",
-				FileName:  "merged.xml",
-			},
+			Spec:      junitSpec,
 		}
 		jobTaskSpec.Steps = append(jobTaskSpec.Steps, junitStep)
 	}
 
+	// init coverage report step
+	if testingInfo.Coverage != nil && testingInfo.Coverage.Enabled {
+		coverageSpec := &step.StepCoverageReportSpec{
+			ReportPath:        testingInfo.Coverage.ReportPath,
+			Format:            testingInfo.Coverage.Format,
+			DestDir:           "/tmp",
+			S3DestDir:         path.Join(j.workflow.Name, fmt.Sprint(taskID), jobTask.Name, "coverage"),
+			FileName:          "coverage.json",
+			MinLineCoverage:   testingInfo.Coverage.MinLineCoverage,
+			MinBranchCoverage: testingInfo.Coverage.MinBranchCoverage,
+			MinDiffCoverage:   testingInfo.Coverage.MinDiffCoverage,
+			ProjectName:       testing.ProjectName,
+			TestName:          testing.Name,
+			ServiceName:       serviceName,
+			ServiceModule:     serviceModule,
+		}
+		if len(testing.Repos) > 0 {
+			coverageSpec.CommitID = testing.Repos[0].CommitID
+			coverageSpec.Branch = testing.Repos[0].Branch
+		}
+		// diff coverage is only calculated for the tasks triggered by pull requests
+		if j.workflow.HookPayload != nil && j.workflow.HookPayload.IsPr {
+			coverageSpec.ChangedFiles = j.workflow.HookPayload.ChangedFiles
+		}
+		coverageStep := &commonmodels.StepTask{
+			Name:      config.TestJobCoverageStepName,
+			JobName:   jobTask.Name,
+			StepType:  config.StepCoverageReport,
+			Onfailure: true,
+			Spec:      coverageSpec,
+		}
+		jobTaskSpec.Steps = append(jobTaskSpec.Steps, coverageStep)
+	}
+
 	// init object storage step
 	if testingInfo.PostTest != nil && testingInfo.PostTest.ObjectStorageUpload != nil && testingInfo.PostTest.ObjectStorageUpload.Enabled {
 		modelS3, err := commonrepo.NewS3StorageColl().Find(testingInfo.PostTest.ObjectStorageUpload.ObjectStorageID)
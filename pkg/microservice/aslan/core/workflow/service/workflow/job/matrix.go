@@ -0,0 +1,251 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+const (
+	// maxMatrixCombinations limits the number of job tasks a matrix job can be expanded into
+	maxMatrixCombinations  = 256
+	matrixJobNameMaxLength = 63
+)
+
+var matrixKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// getJobMatrix returns the matrix of the job, only freestyle, build and testing jobs support matrix.
+func getJobMatrix(job *commonmodels.Job) (*commonmodels.JobMatrix, error) {
+	switch job.JobType {
+	case config.JobFreestyle:
+		spec := &commonmodels.FreestyleJobSpec{}
+		if err := commonmodels.IToi(job.Spec, spec); err != nil {
+			return nil, err
+		}
+		return spec.Matrix, nil
+	case config.JobZadigBuild:
+		spec := &commonmodels.ZadigBuildJobSpec{}
+		if err := commonmodels.IToi(job.Spec, spec); err != nil {
+			return nil, err
+		}
+		return spec.Matrix, nil
+	case config.JobZadigTesting:
+		spec := &commonmodels.ZadigTestingJobSpec{}
+		if err := commonmodels.IToi(job.Spec, spec); err != nil {
+			return nil, err
+		}
+		return spec.Matrix, nil
+	}
+	return nil, nil
+}
+
+func lintJobMatrix(matrix *commonmodels.JobMatrix) error {
+	if matrix == nil {
+		return nil
+	}
+	if len(matrix.Axes) == 0 && len(matrix.Include) == 0 {
+		return fmt.Errorf("matrix must have at least one axis or include rule")
+	}
+	if matrix.MaxParallel < 0 {
+		return fmt.Errorf("matrix max_parallel can not be negative")
+	}
+	keys := map[string]bool{}
+	for _, axis := range matrix.Axes {
+		if !matrixKeyRegex.MatchString(axis.Key) {
+			return fmt.Errorf("invalid matrix key %q, it must be a valid env name", axis.Key)
+		}
+		if keys[axis.Key] {
+			return fmt.Errorf("duplicated matrix key %s", axis.Key)
+		}
+		keys[axis.Key] = true
+		if len(axis.Values) == 0 {
+			return fmt.Errorf("matrix key %s has no values", axis.Key)
+		}
+	}
+	for _, rule := range append(append([]map[string]string{}, matrix.Include...), matrix.Exclude...) {
+		if len(rule) == 0 {
+			return fmt.Errorf("empty matrix include or exclude rule")
+		}
+		for key := range rule {
+			if !matrixKeyRegex.MatchString(key) {
+				return fmt.Errorf("invalid matrix key %q, it must be a valid env name", key)
+			}
+		}
+	}
+	for _, rule := range matrix.Exclude {
+		for key := range rule {
+			if !keys[key] {
+				return fmt.Errorf("matrix exclude rule uses key %s which is not an axis", key)
+			}
+		}
+	}
+
+	combinations := matrixCombinations(matrix)
+	if len(combinations) == 0 {
+		return fmt.Errorf("all the combinations of the matrix are excluded")
+	}
+	if len(combinations) > maxMatrixCombinations {
+		return fmt.Errorf("matrix has %d combinations, which exceeds the limit %d", len(combinations), maxMatrixCombinations)
+	}
+	return nil
+}
+
+// matrixCombinations returns the combinations of the matrix in the order of the axes,
+// the exclude rules are applied to the cartesian product of the axes before the include rules.
+func matrixCombinations(matrix *commonmodels.JobMatrix) []map[string]string {
+	if matrix == nil {
+		return nil
+	}
+	combinations := []map[string]string{}
+	if len(matrix.Axes) > 0 {
+		combinations = append(combinations, map[string]string{})
+	}
+	axisKeys := map[string]bool{}
+	for _, axis := range matrix.Axes {
+		axisKeys[axis.Key] = true
+		next := make([]map[string]string, 0, len(combinations)*len(axis.Values))
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				c := copyMatrixValues(combination)
+				c[axis.Key] = value
+				next = append(next, c)
+			}
+		}
+		combinations = next
+	}
+
+	resp := make([]map[string]string, 0, len(combinations))
+	for _, combination := range combinations {
+		excluded := false
+		for _, rule := range matrix.Exclude {
+			if matrixValuesMatch(combination, rule) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			resp = append(resp, combination)
+		}
+	}
+
+	originCount := len(resp)
+	for _, rule := range matrix.Include {
+		added := false
+		for _, combination := range resp[:originCount] {
+			conflicted := false
+			for key, value := range rule {
+				if axisKeys[key] && combination[key] != value {
+					conflicted = true
+					break
+				}
+			}
+			if conflicted {
+				continue
+			}
+			for key, value := range rule {
+				combination[key] = value
+			}
+			added = true
+		}
+		if !added {
+			resp = append(resp, copyMatrixValues(rule))
+		}
+	}
+	return resp
+}
+
+// expandMatrixJobTasks copies every job task once for each combination of the matrix.
+func expandMatrixJobTasks(jobTasks []*commonmodels.JobTask, matrix *commonmodels.JobMatrix) ([]*commonmodels.JobTask, error) {
+	combinations := matrixCombinations(matrix)
+	if len(combinations) == 0 {
+		return jobTasks, nil
+	}
+
+	resp := make([]*commonmodels.JobTask, 0, len(jobTasks)*len(combinations))
+	for _, jobTask := range jobTasks {
+		for i, combination := range combinations {
+			spec := &commonmodels.JobTaskFreestyleSpec{}
+			if err := commonmodels.IToi(jobTask.Spec, spec); err != nil {
+				return nil, fmt.Errorf("job %s does not support matrix: %v", jobTask.Name, err)
+			}
+			keyVals := matrixKeyVals(combination)
+			spec.Properties.Envs = append(spec.Properties.Envs, keyVals...)
+			spec.Properties.CustomEnvs = append(spec.Properties.CustomEnvs, keyVals...)
+
+			suffix := fmt.Sprintf("-matrix-%d", i+1)
+			matrixJobTask := *jobTask
+			matrixJobTask.Name = matrixJobName(jobTask.Name, suffix)
+			matrixJobTask.Key = jobTask.Key + suffix
+			matrixJobTask.Spec = spec
+			matrixJobTask.MatrixValues = combination
+			matrixJobTask.MatrixMaxParallel = matrix.MaxParallel
+			matrixJobTask.MatrixIndex = i + 1
+			matrixJobTask.MatrixOriginKey = jobTask.Key
+			if jobInfo, ok := jobTask.JobInfo.(map[string]string); ok {
+				info := copyMatrixValues(jobInfo)
+				info["matrix_index"] = fmt.Sprintf("%d", i+1)
+				matrixJobTask.JobInfo = info
+			}
+			resp = append(resp, &matrixJobTask)
+		}
+	}
+	return resp, nil
+}
+
+func matrixJobName(name, suffix string) string {
+	name = jobNameFormat(name)
+	if len(name)+len(suffix) > matrixJobNameMaxLength {
+		name = strings.Trim(name[:matrixJobNameMaxLength-len(suffix)], "-")
+	}
+	return name + suffix
+}
+
+func matrixKeyVals(values map[string]string) []*commonmodels.KeyVal {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	resp := make([]*commonmodels.KeyVal, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, &commonmodels.KeyVal{Key: key, Value: values[key], Type: commonmodels.StringType})
+	}
+	return resp
+}
+
+func matrixValuesMatch(values, rule map[string]string) bool {
+	for key, value := range rule {
+		if values[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func copyMatrixValues(values map[string]string) map[string]string {
+	resp := make(map[string]string, len(values))
+	for key, value := range values {
+		resp[key] = value
+	}
+	return resp
+}
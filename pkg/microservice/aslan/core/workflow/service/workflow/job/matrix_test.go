@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix *commonmodels.JobMatrix
+		want   []map[string]string
+	}{
+		{
+			name: "cartesian product",
+			matrix: &commonmodels.JobMatrix{Axes: []*commonmodels.MatrixAxis{
+				{Key: "GO_VERSION", Values: []string{"1.19", "1.20"}},
+				{Key: "ARCH", Values: []string{"amd64", "arm64"}},
+			}},
+			want: []map[string]string{
+				{"GO_VERSION": "1.19", "ARCH": "amd64"},
+				{"GO_VERSION": "1.19", "ARCH": "arm64"},
+				{"GO_VERSION": "1.20", "ARCH": "amd64"},
+				{"GO_VERSION": "1.20", "ARCH": "arm64"},
+			},
+		},
+		{
+			name: "exclude and include",
+			matrix: &commonmodels.JobMatrix{
+				Axes: []*commonmodels.MatrixAxis{
+					{Key: "GO_VERSION", Values: []string{"1.19", "1.20"}},
+					{Key: "ARCH", Values: []string{"amd64", "arm64"}},
+				},
+				Exclude: []map[string]string{{"GO_VERSION": "1.19", "ARCH": "arm64"}},
+				Include: []map[string]string{
+					{"ARCH": "arm64", "DB": "mysql"},
+					{"GO_VERSION": "1.21", "ARCH": "riscv64"},
+				},
+			},
+			want: []map[string]string{
+				{"GO_VERSION": "1.19", "ARCH": "amd64"},
+				{"GO_VERSION": "1.20", "ARCH": "amd64"},
+				{"GO_VERSION": "1.20", "ARCH": "arm64", "DB": "mysql"},
+				{"GO_VERSION": "1.21", "ARCH": "riscv64"},
+			},
+		},
+		{
+			name:   "include only",
+			matrix: &commonmodels.JobMatrix{Include: []map[string]string{{"DB": "mysql"}, {"DB": "postgres"}}},
+			want:   []map[string]string{{"DB": "mysql"}, {"DB": "postgres"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matrixCombinations(tt.matrix))
+		})
+	}
+}
+
+func TestLintJobMatrix(t *testing.T) {
+	axes := []*commonmodels.MatrixAxis{{Key: "ARCH", Values: []string{"amd64"}}}
+	assert.NoError(t, lintJobMatrix(nil))
+	assert.NoError(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: axes, MaxParallel: 1}))
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{}))
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: []*commonmodels.MatrixAxis{{Key: "GO-VERSION", Values: []string{"1.20"}}}}))
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: []*commonmodels.MatrixAxis{{Key: "ARCH"}}}))
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: axes, Exclude: []map[string]string{{"ARCH": "amd64"}}}))
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: axes, Exclude: []map[string]string{{"DB": "mysql"}}}))
+
+	values := make([]string, 20)
+	for i := range values {
+		values[i] = string(rune('a' + i))
+	}
+	assert.Error(t, lintJobMatrix(&commonmodels.JobMatrix{Axes: []*commonmodels.MatrixAxis{{Key: "A", Values: values}, {Key: "B", Values: values}}}))
+}
+
+func TestExpandMatrixJobTasks(t *testing.T) {
+	jobTask := &commonmodels.JobTask{
+		Name:    "build-svc-module-build",
+		Key:     "build.svc.module",
+		JobInfo: map[string]string{JobNameKey: "build"},
+		JobType: string(config.JobZadigBuild),
+		Spec: &commonmodels.JobTaskFreestyleSpec{Properties: commonmodels.JobProperties{
+			Envs: []*commonmodels.KeyVal{{Key: "SERVICE_NAME", Value: "svc"}},
+		}},
+	}
+	matrix := &commonmodels.JobMatrix{
+		Axes:        []*commonmodels.MatrixAxis{{Key: "ARCH", Values: []string{"amd64", "arm64"}}},
+		MaxParallel: 1,
+	}
+
+	jobTasks, err := expandMatrixJobTasks([]*commonmodels.JobTask{jobTask}, matrix)
+	assert.NoError(t, err)
+	assert.Len(t, jobTasks, 2)
+	for i, arch := range []string{"amd64", "arm64"} {
+		spec := jobTasks[i].Spec.(*commonmodels.JobTaskFreestyleSpec)
+		assert.Equal(t, []*commonmodels.KeyVal{
+			{Key: "SERVICE_NAME", Value: "svc"},
+			{Key: "ARCH", Value: arch, Type: commonmodels.StringType},
+		}, spec.Properties.Envs)
+		assert.Equal(t, map[string]string{"ARCH": arch}, jobTasks[i].MatrixValues)
+		assert.Equal(t, 1, jobTasks[i].MatrixMaxParallel)
+		assert.Equal(t, i+1, jobTasks[i].MatrixIndex)
+		assert.Equal(t, "build.svc.module", jobTasks[i].MatrixOriginKey)
+	}
+	assert.Equal(t, "build-svc-module-build-matrix-1", jobTasks[0].Name)
+	assert.Equal(t, "build.svc.module-matrix-2", jobTasks[1].Key)
+	// the original job task is not changed
+	assert.Len(t, jobTask.Spec.(*commonmodels.JobTaskFreestyleSpec).Properties.Envs, 1)
+}
+
+func TestMatrixJobName(t *testing.T) {
+	name := matrixJobName("a-very-long-service-name-with-a-very-long-module-name-and-job-name", "-matrix-12")
+	assert.Len(t, name, matrixJobNameMaxLength)
+	assert.Equal(t, "a-very-long-service-name-with-a-very-long-module-name-matrix-12", name)
+}
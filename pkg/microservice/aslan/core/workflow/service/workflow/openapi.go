@@ -412,8 +412,12 @@ func getInputUpdater(job *commonmodels.Job, input interface{}) (CustomJobInput,
 	}
 }
 
-func OpenAPIDeleteCustomWorkflowV4(workflowName, projectName string, logger *zap.SugaredLogger) error {
-	return DeleteWorkflowV4(workflowName, logger)
+// OpenAPIDeleteCustomWorkflowV4 returns the pull request deleting the workflow file if the workflow is managed in a repo
+func OpenAPIDeleteCustomWorkflowV4(user, workflowName, projectName string, logger *zap.SugaredLogger) (*WorkflowGitSyncPullRequest, error) {
+	if intercepted, pr, err := InterceptWorkflowV4Delete(user, workflowName, logger); intercepted {
+		return pr, err
+	}
+	return nil, DeleteWorkflowV4(workflowName, logger)
 }
 
 func OpenAPIGetCustomWorkflowV4(workflowName, projectName string, logger *zap.SugaredLogger) (*OpenAPIWorkflowV4Detail, error) {
@@ -431,7 +435,7 @@ func OpenAPIGetCustomWorkflowV4(workflowName, projectName string, logger *zap.Su
 		CreateTime:       workflow.CreateTime,
 		UpdateTime:       workflow.UpdateTime,
 		Params:           workflow.Params,
-		NotifyCtls:       workflow.NotifyCtls,
+		NotifyCtls:       commonmodels.MaskNotifyCtlSecrets(workflow.NotifyCtls),
 		ShareStorages:    workflow.ShareStorages,
 		ConcurrencyLimit: workflow.ConcurrencyLimit,
 	}
@@ -341,6 +341,9 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 			build.CommitID = commit.ID
 			build.CommitMessage = commit.Message
 			build.AuthorName = commit.AuthorName
+			if commit.CreatedAt != nil {
+				build.CommitTime = commit.CreatedAt.Unix()
+			}
 		}
 		// get gerrit submission_id
 		if codeHostInfo.Type == systemconfig.GerritProvider {
@@ -370,6 +373,7 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 						}
 						build.CommitMessage = commitInfo.Commit.Message
 						build.AuthorName = commitInfo.Commit.Author.Name
+						build.CommitTime = commitInfo.Commit.Committer.Date.Unix()
 						return
 					}
 				}
@@ -382,6 +386,7 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 				build.CommitID = branch.Commit.Sha
 				build.CommitMessage = branch.Commit.Commit.Message
 				build.AuthorName = branch.Commit.Commit.Author.Name
+				build.CommitTime = branch.Commit.Commit.Committer.Date.Unix()
 			} else if len(build.PRs) > 0 {
 				prCommits, err := gitCli.ListCommitsForPR(context.Background(), build.RepoOwner, build.RepoName, getlatestPrNum(build), nil)
 				sort.SliceStable(prCommits, func(i, j int) bool {
@@ -392,6 +397,7 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 						build.CommitID = commit.Sha
 						build.CommitMessage = commit.Commit.Message
 						build.AuthorName = commit.Commit.Author.Name
+						build.CommitTime = commit.Commit.Committer.Date.Unix()
 						return
 					}
 				}
@@ -428,6 +434,7 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 					build.CommitID = *branch.Commit.SHA
 					build.CommitMessage = *branch.Commit.Commit.Message
 					build.AuthorName = *branch.Commit.Commit.Author.Name
+					build.CommitTime = branch.Commit.Commit.GetCommitter().GetDate().Unix()
 				}
 			} else if len(build.PRs) > 0 {
 				opt := &github.ListOptions{Page: 1, PerPage: 100}
@@ -440,6 +447,7 @@ func setBuildInfo(build *types.Repository, buildArgs []*types.Repository, log *z
 						build.CommitID = *commit.SHA
 						build.CommitMessage = *commit.Commit.Message
 						build.AuthorName = *commit.Commit.Author.Name
+						build.CommitTime = commit.Commit.GetCommitter().GetDate().Unix()
 						return
 					}
 				}
@@ -0,0 +1,448 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	githubservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/github"
+	gitlabservice "github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/gitlab"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+type WorkflowGitSyncDetail struct {
+	*commonmodels.WorkflowGitSync
+	Workflows []*commonmodels.WorkflowGitSyncStatus `json:"workflows"`
+}
+
+// WorkflowGitSyncPullRequest is returned when a workflow change is sent to the bound repo as a pull request
+type WorkflowGitSyncPullRequest struct {
+	PullRequestURL string `json:"pull_request_url"`
+}
+
+// workflowGitSyncClient is implemented by the github and gitlab clients
+type workflowGitSyncClient interface {
+	GetTree(owner, repo, path, branch string) ([]*git.TreeNode, error)
+	GetFileContent(owner, repo, path, branch string) ([]byte, error)
+	GetLatestRepositoryCommit(owner, repo, path, branch string) (*git.RepositoryCommit, error)
+	// CreatePullRequestWithFile deletes the file in the pull request if the content is nil
+	CreatePullRequestWithFile(owner, repo, base, head, path string, content []byte, title, body string) (string, error)
+}
+
+func getWorkflowGitSyncClient(codehostID int) (workflowGitSyncClient, error) {
+	ch, err := systemconfig.New().GetCodeHost(codehostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codehost %d: %s", codehostID, err)
+	}
+	return newWorkflowGitSyncClient(ch)
+}
+
+// newWorkflowGitSyncClient only supports github and gitlab, the push events of the other code hosts
+// do not trigger the sync, so binding them is rejected
+func newWorkflowGitSyncClient(ch *systemconfig.CodeHost) (workflowGitSyncClient, error) {
+	switch ch.Type {
+	case setting.SourceFromGithub:
+		return githubservice.NewClient(ch.AccessToken, config.ProxyHTTPSAddr(), ch.EnableProxy), nil
+	case setting.SourceFromGitlab:
+		return gitlabservice.NewClient(ch.ID, ch.Address, ch.AccessToken, config.ProxyHTTPSAddr(), ch.EnableProxy)
+	default:
+		return nil, fmt.Errorf("workflow git sync does not support codehost type: %s", ch.Type)
+	}
+}
+
+func GetWorkflowGitSync(projectName string, logger *zap.SugaredLogger) (*WorkflowGitSyncDetail, error) {
+	gitSync, err := commonrepo.NewWorkflowGitSyncColl().GetByProject(projectName)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		logger.Errorf("failed to get workflow git sync of project %s, error: %s", projectName, err)
+		return nil, e.ErrGetWorkflowGitSync.AddErr(err)
+	}
+	statuses, err := commonrepo.NewWorkflowGitSyncStatusColl().List(projectName)
+	if err != nil {
+		logger.Errorf("failed to list workflow git sync status of project %s, error: %s", projectName, err)
+		return nil, e.ErrGetWorkflowGitSync.AddErr(err)
+	}
+	return &WorkflowGitSyncDetail{
+		WorkflowGitSync: gitSync,
+		Workflows:       statuses,
+	}, nil
+}
+
+// UpsertWorkflowGitSync binds the project to the repo path and imports the workflows in it immediately
+func UpsertWorkflowGitSync(projectName, user string, args *commonmodels.WorkflowGitSync, logger *zap.SugaredLogger) error {
+	args.ProjectName = projectName
+	args.UpdatedBy = user
+	args.Path = strings.Trim(args.Path, "/")
+	if args.RepoName == "" || args.Branch == "" || args.Path == "" {
+		return e.ErrUpsertWorkflowGitSync.AddDesc("repo, branch and path are required")
+	}
+	switch args.DriftPolicy {
+	case "":
+		args.DriftPolicy = config.WorkflowGitSyncDriftPolicyReject
+	case config.WorkflowGitSyncDriftPolicyReject, config.WorkflowGitSyncDriftPolicyPullRequest:
+	default:
+		return e.ErrUpsertWorkflowGitSync.AddDesc(fmt.Sprintf("invalid drift policy: %s", args.DriftPolicy))
+	}
+	if _, err := getWorkflowGitSyncClient(args.CodehostID); err != nil {
+		return e.ErrUpsertWorkflowGitSync.AddErr(err)
+	}
+
+	if err := commonrepo.NewWorkflowGitSyncColl().Upsert(args); err != nil {
+		logger.Errorf("failed to save workflow git sync of project %s, error: %s", projectName, err)
+		return e.ErrUpsertWorkflowGitSync.AddErr(err)
+	}
+	return SyncWorkflowsFromGit(projectName, user, logger)
+}
+
+func DeleteWorkflowGitSync(projectName string, logger *zap.SugaredLogger) error {
+	if err := commonrepo.NewWorkflowGitSyncColl().Delete(projectName); err != nil {
+		logger.Errorf("failed to delete workflow git sync of project %s, error: %s", projectName, err)
+		return e.ErrDeleteWorkflowGitSync.AddErr(err)
+	}
+	if err := commonrepo.NewWorkflowGitSyncStatusColl().DeleteByProject(projectName); err != nil {
+		logger.Errorf("failed to delete workflow git sync status of project %s, error: %s", projectName, err)
+		return e.ErrDeleteWorkflowGitSync.AddErr(err)
+	}
+	return nil
+}
+
+// SyncWorkflowsFromGit imports all the workflow yaml files in the bound path of the project
+func SyncWorkflowsFromGit(projectName, user string, logger *zap.SugaredLogger) error {
+	gitSync, err := commonrepo.NewWorkflowGitSyncColl().GetByProject(projectName)
+	if err != nil {
+		logger.Errorf("failed to get workflow git sync of project %s, error: %s", projectName, err)
+		return e.ErrSyncWorkflowFromGit.AddErr(err)
+	}
+	if err := syncWorkflowsFromGit(gitSync, user, logger); err != nil {
+		return e.ErrSyncWorkflowFromGit.AddErr(err)
+	}
+	return nil
+}
+
+// SyncWorkflowsByPushEvent imports the workflows of the projects bound to the pushed repo and branch,
+// if any of the changed files is under the bound path
+func SyncWorkflowsByPushEvent(pathWithNamespace, branch string, changedFiles []string, logger *zap.SugaredLogger) error {
+	gitSyncs, err := commonrepo.NewWorkflowGitSyncColl().List()
+	if err != nil {
+		logger.Errorf("failed to list workflow git syncs, error: %s", err)
+		return err
+	}
+
+	errs := &multierror.Error{}
+	for _, gitSync := range gitSyncs {
+		if !isWorkflowGitSyncAffected(gitSync, pathWithNamespace, branch, changedFiles) {
+			continue
+		}
+
+		logger.Infof("syncing workflows of project %s from %s:%s/%s", gitSync.ProjectName, pathWithNamespace, branch, gitSync.Path)
+		if err := syncWorkflowsFromGit(gitSync, setting.WebhookTaskCreator, logger); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func isWorkflowGitSyncAffected(gitSync *commonmodels.WorkflowGitSync, pathWithNamespace, branch string, changedFiles []string) bool {
+	if gitSync.GetRepoNamespace()+"/"+gitSync.RepoName != pathWithNamespace || gitSync.Branch != branch {
+		return false
+	}
+	for _, file := range changedFiles {
+		if strings.HasPrefix(file, gitSync.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func syncWorkflowsFromGit(gitSync *commonmodels.WorkflowGitSync, user string, logger *zap.SugaredLogger) error {
+	commitID, err := importWorkflowsFromGit(gitSync, user, logger)
+	syncErr := ""
+	if err != nil {
+		logger.Errorf("failed to sync workflows of project %s from git, error: %s", gitSync.ProjectName, err)
+		syncErr = err.Error()
+	}
+	if updateErr := commonrepo.NewWorkflowGitSyncColl().UpdateSyncResult(gitSync.ProjectName, commitID, syncErr); updateErr != nil {
+		logger.Errorf("failed to update the sync result of project %s, error: %s", gitSync.ProjectName, updateErr)
+	}
+	return err
+}
+
+// importWorkflowsFromGit returns the synced commit, the sync status of each file is saved separately
+// so that a broken file does not block the others. The workflows imported from the files removed from
+// the path are deleted, an empty path is treated as an error to avoid deleting all the workflows by a wrong path.
+func importWorkflowsFromGit(gitSync *commonmodels.WorkflowGitSync, user string, logger *zap.SugaredLogger) (string, error) {
+	client, err := getWorkflowGitSyncClient(gitSync.CodehostID)
+	if err != nil {
+		return "", err
+	}
+	owner := gitSync.GetRepoNamespace()
+	nodes, err := client.GetTree(owner, gitSync.RepoName, gitSync.Path, gitSync.Branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to list files under %s: %s", gitSync.Path, err)
+	}
+
+	filePaths := workflowYamlFilePaths(nodes)
+	if len(filePaths) == 0 {
+		return "", fmt.Errorf("no workflow yaml found under %s", gitSync.Path)
+	}
+	lastStatuses, err := commonrepo.NewWorkflowGitSyncStatusColl().List(gitSync.ProjectName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the sync status: %s", err)
+	}
+
+	commitID := ""
+	if commit, err := client.GetLatestRepositoryCommit(owner, gitSync.RepoName, gitSync.Path, gitSync.Branch); err == nil && commit != nil {
+		commitID = commit.SHA
+	}
+
+	failed := 0
+	syncedWorkflows := make(map[string]bool)
+	for _, filePath := range filePaths {
+		status := &commonmodels.WorkflowGitSyncStatus{
+			ProjectName: gitSync.ProjectName,
+			FilePath:    filePath,
+			Status:      config.WorkflowGitSyncStatusSynced,
+			CommitID:    commitID,
+		}
+		workflowName, err := importWorkflowFile(client, gitSync, filePath, user, logger)
+		status.WorkflowName = workflowName
+		syncedWorkflows[workflowName] = true
+		if err != nil {
+			failed++
+			status.Status = config.WorkflowGitSyncStatusFailed
+			status.Error = err.Error()
+		}
+		if err := commonrepo.NewWorkflowGitSyncStatusColl().Upsert(status); err != nil {
+			logger.Errorf("failed to save the sync status of %s, error: %s", filePath, err)
+		}
+	}
+	for _, workflowName := range removedWorkflows(lastStatuses, filePaths, syncedWorkflows) {
+		if err := deleteRemovedWorkflow(gitSync.ProjectName, workflowName, logger); err != nil {
+			failed++
+			logger.Errorf("failed to delete workflow %s removed from the repo, error: %s", workflowName, err)
+		}
+	}
+	if err := commonrepo.NewWorkflowGitSyncStatusColl().DeleteStale(gitSync.ProjectName, filePaths); err != nil {
+		logger.Errorf("failed to delete stale sync status of project %s, error: %s", gitSync.ProjectName, err)
+	}
+
+	if failed > 0 {
+		return commitID, fmt.Errorf("%d of %d workflow files failed to sync", failed, len(filePaths))
+	}
+	return commitID, nil
+}
+
+func workflowYamlFilePaths(nodes []*git.TreeNode) []string {
+	filePaths := make([]string, 0)
+	for _, node := range nodes {
+		if node.IsDir || (path.Ext(node.Name) != ".yaml" && path.Ext(node.Name) != ".yml") {
+			continue
+		}
+		filePaths = append(filePaths, node.FullPath)
+	}
+	return filePaths
+}
+
+// removedWorkflows returns the workflows imported by the last sync from the files which are no longer in the path,
+// the workflows moved to another file and the files with a pending pull request are kept
+func removedWorkflows(lastStatuses []*commonmodels.WorkflowGitSyncStatus, filePaths []string, syncedWorkflows map[string]bool) []string {
+	files := sets.NewString(filePaths...)
+	resp := make([]string, 0)
+	for _, status := range lastStatuses {
+		if files.Has(status.FilePath) || status.WorkflowName == "" || syncedWorkflows[status.WorkflowName] ||
+			status.Status == config.WorkflowGitSyncStatusPullRequest {
+			continue
+		}
+		resp = append(resp, status.WorkflowName)
+	}
+	return resp
+}
+
+func deleteRemovedWorkflow(projectName, workflowName string, logger *zap.SugaredLogger) error {
+	workflow, err := commonrepo.NewWorkflowV4Coll().Find(workflowName)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	if workflow.Project != projectName {
+		return nil
+	}
+	logger.Infof("deleting workflow %s of project %s removed from the repo", workflowName, projectName)
+	return DeleteWorkflowV4(workflowName, logger)
+}
+
+func parseWorkflowFile(content []byte, projectName string) (*commonmodels.WorkflowV4, error) {
+	workflow := new(commonmodels.WorkflowV4)
+	if err := yaml.Unmarshal(content, workflow); err != nil {
+		return nil, fmt.Errorf("invalid workflow yaml: %s", err)
+	}
+	if workflow.Name == "" {
+		return nil, fmt.Errorf("workflow name is required")
+	}
+	if workflow.Project == "" {
+		workflow.Project = projectName
+	}
+	if workflow.Project != projectName {
+		return workflow, fmt.Errorf("workflow belongs to project %s instead of %s", workflow.Project, projectName)
+	}
+	return workflow, nil
+}
+
+func importWorkflowFile(client workflowGitSyncClient, gitSync *commonmodels.WorkflowGitSync, filePath, user string, logger *zap.SugaredLogger) (string, error) {
+	content, err := client.GetFileContent(gitSync.GetRepoNamespace(), gitSync.RepoName, filePath, gitSync.Branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %s", err)
+	}
+	workflow, err := parseWorkflowFile(content, gitSync.ProjectName)
+	if err != nil {
+		if workflow != nil {
+			return workflow.Name, err
+		}
+		return "", err
+	}
+	if err := LintWorkflowV4(workflow, logger); err != nil {
+		return workflow.Name, err
+	}
+
+	existed, err := commonrepo.NewWorkflowV4Coll().Find(workflow.Name)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			return workflow.Name, err
+		}
+		// there is no stored secret to restore the masked ones from
+		commonmodels.EnsureNotifyCtlSecrets(nil, workflow.NotifyCtls)
+		return workflow.Name, CreateWorkflowV4(user, workflow, logger)
+	}
+	if existed.Project != gitSync.ProjectName {
+		return workflow.Name, fmt.Errorf("workflow %s already exists in project %s", workflow.Name, existed.Project)
+	}
+	return workflow.Name, UpdateWorkflowV4(workflow.Name, user, workflow, logger)
+}
+
+// InterceptWorkflowV4Edit intercepts the workflow creations and updates made through the API of a project bound to a repo path.
+// The change is rejected, or sent to the repo as a pull request according to the drift policy, intercepted is true
+// if the change should not be saved. The triggers, crons and custom fields are not in the workflow yaml and are not intercepted.
+func InterceptWorkflowV4Edit(user string, workflow *commonmodels.WorkflowV4, logger *zap.SugaredLogger) (bool, *WorkflowGitSyncPullRequest, error) {
+	content, err := workflowV4GitContent(workflow)
+	if err != nil {
+		return true, nil, e.ErrWorkflowGitSyncDrift.AddErr(err)
+	}
+	return interceptWorkflowV4Change(user, workflow.Project, workflow.Name, content, logger)
+}
+
+// workflowV4GitContent returns the yaml of the workflow written to the repo, the webhook secrets are masked.
+// The masked secrets are restored from the stored workflow when the file is synced back, so the changes
+// of the secrets are not sent through the repo.
+func workflowV4GitContent(workflow *commonmodels.WorkflowV4) ([]byte, error) {
+	masked := *workflow
+	masked.NotifyCtls = commonmodels.MaskNotifyCtlSecrets(workflow.NotifyCtls)
+	return yaml.Marshal(&masked)
+}
+
+// InterceptWorkflowV4Delete intercepts the workflow deletions made through the API like InterceptWorkflowV4Edit,
+// the pull request deletes the workflow file.
+func InterceptWorkflowV4Delete(user, workflowName string, logger *zap.SugaredLogger) (bool, *WorkflowGitSyncPullRequest, error) {
+	workflow, err := commonrepo.NewWorkflowV4Coll().Find(workflowName)
+	if err != nil {
+		// let the deletion report the missing workflow
+		return false, nil, nil
+	}
+	return interceptWorkflowV4Change(user, workflow.Project, workflowName, nil, logger)
+}
+
+func interceptWorkflowV4Change(user, projectName, workflowName string, content []byte, logger *zap.SugaredLogger) (bool, *WorkflowGitSyncPullRequest, error) {
+	gitSync, err := commonrepo.NewWorkflowGitSyncColl().GetByProject(projectName)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil, nil
+		}
+		logger.Errorf("failed to get workflow git sync of project %s, error: %s", projectName, err)
+		return true, nil, e.ErrGetWorkflowGitSync.AddErr(err)
+	}
+	if err := checkWorkflowGitSyncDrift(gitSync); err != nil {
+		return true, nil, err
+	}
+
+	filePath := path.Join(gitSync.Path, workflowName+".yaml")
+	if status, err := commonrepo.NewWorkflowGitSyncStatusColl().FindByWorkflow(projectName, workflowName); err == nil {
+		filePath = status.FilePath
+	}
+	client, err := getWorkflowGitSyncClient(gitSync.CodehostID)
+	if err != nil {
+		return true, nil, e.ErrWorkflowGitSyncDrift.AddErr(err)
+	}
+	url, err := createWorkflowGitSyncPullRequest(client, gitSync, filePath, user, workflowName, content)
+	if err != nil {
+		logger.Errorf("failed to open pull request to %s for workflow %s, error: %s", workflowGitSyncRepo(gitSync), workflowName, err)
+		return true, nil, e.ErrWorkflowGitSyncDrift.AddDesc(fmt.Sprintf("failed to open pull request to %s: %s", workflowGitSyncRepo(gitSync), err))
+	}
+
+	if err := commonrepo.NewWorkflowGitSyncStatusColl().Upsert(&commonmodels.WorkflowGitSyncStatus{
+		ProjectName:    projectName,
+		FilePath:       filePath,
+		WorkflowName:   workflowName,
+		Status:         config.WorkflowGitSyncStatusPullRequest,
+		CommitID:       gitSync.CommitID,
+		PullRequestURL: url,
+	}); err != nil {
+		logger.Errorf("failed to save the sync status of %s, error: %s", filePath, err)
+	}
+	return true, &WorkflowGitSyncPullRequest{PullRequestURL: url}, nil
+}
+
+func workflowGitSyncRepo(gitSync *commonmodels.WorkflowGitSync) string {
+	return fmt.Sprintf("%s/%s:%s/%s", gitSync.GetRepoNamespace(), gitSync.RepoName, gitSync.Branch, gitSync.Path)
+}
+
+// checkWorkflowGitSyncDrift returns an error if the changes made outside the repo are rejected by the drift policy
+func checkWorkflowGitSyncDrift(gitSync *commonmodels.WorkflowGitSync) error {
+	if gitSync.DriftPolicy == config.WorkflowGitSyncDriftPolicyPullRequest {
+		return nil
+	}
+	return e.ErrWorkflowGitSyncDrift.AddDesc(fmt.Sprintf("workflows of project %s are managed in %s, please edit them in the repo", gitSync.ProjectName, workflowGitSyncRepo(gitSync)))
+}
+
+// createWorkflowGitSyncPullRequest opens a pull request with the workflow file, the file is deleted if content is nil
+func createWorkflowGitSyncPullRequest(client workflowGitSyncClient, gitSync *commonmodels.WorkflowGitSync, filePath, user, workflowName string, content []byte) (string, error) {
+	head := fmt.Sprintf("zadig/%s-%d", workflowName, time.Now().Unix())
+	title := fmt.Sprintf("Update workflow %s", workflowName)
+	body := fmt.Sprintf("The workflow %s of project %s was edited by %s in Zadig.", workflowName, gitSync.ProjectName, user)
+	if content == nil {
+		title = fmt.Sprintf("Delete workflow %s", workflowName)
+		body = fmt.Sprintf("The workflow %s of project %s was deleted by %s in Zadig.", workflowName, gitSync.ProjectName, user)
+	}
+	return client.CreatePullRequestWithFile(gitSync.GetRepoNamespace(), gitSync.RepoName, gitSync.Branch, head, filePath, content, title, body)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/git"
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+)
+
+type fakeWorkflowGitSyncClient struct {
+	base, head, path, title string
+	content                 []byte
+}
+
+func (c *fakeWorkflowGitSyncClient) GetTree(owner, repo, path, branch string) ([]*git.TreeNode, error) {
+	return nil, nil
+}
+
+func (c *fakeWorkflowGitSyncClient) GetFileContent(owner, repo, path, branch string) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *fakeWorkflowGitSyncClient) GetLatestRepositoryCommit(owner, repo, path, branch string) (*git.RepositoryCommit, error) {
+	return nil, nil
+}
+
+func (c *fakeWorkflowGitSyncClient) CreatePullRequestWithFile(owner, repo, base, head, path string, content []byte, title, body string) (string, error) {
+	c.base, c.head, c.path, c.content, c.title = base, head, path, content, title
+	return "https://github.com/koderover/demo/pull/1", nil
+}
+
+var _ = Describe("Testing workflow git sync", func() {
+	gitSync := &commonmodels.WorkflowGitSync{
+		ProjectName: "demo",
+		RepoOwner:   "koderover",
+		RepoName:    "demo",
+		Branch:      "main",
+		Path:        "zadig/workflows",
+	}
+
+	Context("sync", func() {
+		It("should only sync the yaml files in the path", func() {
+			Expect(workflowYamlFilePaths([]*git.TreeNode{
+				{Name: "build.yaml", FullPath: "zadig/workflows/build.yaml"},
+				{Name: "deploy.yml", FullPath: "zadig/workflows/deploy.yml"},
+				{Name: "README.md", FullPath: "zadig/workflows/README.md"},
+				{Name: "archived.yaml", FullPath: "zadig/workflows/archived.yaml", IsDir: true},
+			})).To(Equal([]string{"zadig/workflows/build.yaml", "zadig/workflows/deploy.yml"}))
+		})
+		It("should sync on the pushes changing the path of the bound branch", func() {
+			Expect(isWorkflowGitSyncAffected(gitSync, "koderover/demo", "main", []string{"main.go", "zadig/workflows/build.yaml"})).To(BeTrue())
+			Expect(isWorkflowGitSyncAffected(gitSync, "koderover/demo", "main", []string{"zadig/workflows.yaml"})).To(BeFalse())
+			Expect(isWorkflowGitSyncAffected(gitSync, "koderover/demo", "dev", []string{"zadig/workflows/build.yaml"})).To(BeFalse())
+			Expect(isWorkflowGitSyncAffected(gitSync, "koderover/other", "main", []string{"zadig/workflows/build.yaml"})).To(BeFalse())
+		})
+		It("should set the project of the workflow and reject the workflows of other projects", func() {
+			workflow, err := parseWorkflowFile([]byte("name: build\ndisplay_name: build\n"), "demo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workflow.Project).To(Equal("demo"))
+
+			workflow, err = parseWorkflowFile([]byte("name: build\nproject: other\n"), "demo")
+			Expect(err).To(HaveOccurred())
+			Expect(workflow.Name).To(Equal("build"))
+
+			_, err = parseWorkflowFile([]byte("display_name: build\n"), "demo")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should delete the workflows whose files are removed", func() {
+			lastStatuses := []*commonmodels.WorkflowGitSyncStatus{
+				{FilePath: "zadig/workflows/build.yaml", WorkflowName: "build", Status: config.WorkflowGitSyncStatusSynced},
+				// removed
+				{FilePath: "zadig/workflows/deploy.yaml", WorkflowName: "deploy", Status: config.WorkflowGitSyncStatusSynced},
+				{FilePath: "zadig/workflows/test.yaml", WorkflowName: "test", Status: config.WorkflowGitSyncStatusFailed},
+				// moved to another file
+				{FilePath: "zadig/workflows/scan.yaml", WorkflowName: "scan", Status: config.WorkflowGitSyncStatusSynced},
+				// the pull request creating the file is not merged yet
+				{FilePath: "zadig/workflows/release.yaml", WorkflowName: "release", Status: config.WorkflowGitSyncStatusPullRequest},
+				{FilePath: "zadig/workflows/broken.yaml", Status: config.WorkflowGitSyncStatusFailed},
+			}
+			filePaths := []string{"zadig/workflows/build.yaml", "zadig/workflows/security/scan.yaml"}
+			synced := map[string]bool{"build": true, "scan": true}
+			Expect(removedWorkflows(lastStatuses, filePaths, synced)).To(Equal([]string{"deploy", "test"}))
+		})
+	})
+
+	Context("drift detection", func() {
+		It("should reject the changes made outside the repo by default", func() {
+			err := checkWorkflowGitSyncDrift(gitSync)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("koderover/demo:main/zadig/workflows"))
+		})
+		It("should allow the changes to be sent as pull requests", func() {
+			prSync := *gitSync
+			prSync.DriftPolicy = config.WorkflowGitSyncDriftPolicyPullRequest
+			Expect(checkWorkflowGitSyncDrift(&prSync)).NotTo(HaveOccurred())
+		})
+		It("should reject binding the code hosts whose pushes are not synced", func() {
+			for _, source := range []string{setting.SourceFromGitee, setting.SourceFromGiteeEE, setting.SourceFromGitea, setting.SourceFromBitbucketServer, setting.SourceFromGerrit, setting.SourceFromOther} {
+				_, err := newWorkflowGitSyncClient(&systemconfig.CodeHost{Type: source})
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("interception", func() {
+		It("should open a pull request with the edited workflow", func() {
+			client := &fakeWorkflowGitSyncClient{}
+			url, err := createWorkflowGitSyncPullRequest(client, gitSync, "zadig/workflows/build.yaml", "admin", "build", []byte("name: build\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal("https://github.com/koderover/demo/pull/1"))
+			Expect(client.base).To(Equal("main"))
+			Expect(client.head).To(HavePrefix("zadig/build-"))
+			Expect(client.path).To(Equal("zadig/workflows/build.yaml"))
+			Expect(client.content).To(Equal([]byte("name: build\n")))
+			Expect(client.title).To(Equal("Update workflow build"))
+		})
+		It("should open a pull request deleting the workflow file", func() {
+			client := &fakeWorkflowGitSyncClient{}
+			_, err := createWorkflowGitSyncPullRequest(client, gitSync, "zadig/workflows/build.yaml", "admin", "build", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.content).To(BeNil())
+			Expect(client.title).To(Equal("Delete workflow build"))
+		})
+		It("should mask the webhook secrets in the pull request and restore them when synced back", func() {
+			stored := []*commonmodels.NotifyCtl{{WebHookType: "generic", GenericWebHook: "https://hook.koderover.io", WebHookSecret: "secret"}}
+			workflow := &commonmodels.WorkflowV4{Name: "build", NotifyCtls: stored}
+			content, err := workflowV4GitContent(workflow)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).NotTo(ContainSubstring("webhook_secret: secret"))
+			Expect(workflow.NotifyCtls[0].WebHookSecret).To(Equal("secret"))
+
+			synced, err := parseWorkflowFile(content, "demo")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(synced.NotifyCtls[0].WebHookSecret).To(Equal(setting.MaskValue))
+			commonmodels.EnsureNotifyCtlSecrets(stored, synced.NotifyCtls)
+			Expect(synced.NotifyCtls[0].WebHookSecret).To(Equal("secret"))
+		})
+	})
+})
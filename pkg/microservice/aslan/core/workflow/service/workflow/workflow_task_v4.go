@@ -44,6 +44,7 @@ import (
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/s3"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/scmnotify"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/workflowcontroller"
+	"github.com/koderover/zadig/pkg/microservice/aslan/core/common/service/workflowcontroller/jobcontroller"
 	commontypes "github.com/koderover/zadig/pkg/microservice/aslan/core/common/types"
 	"github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow/job"
 	jobctl "github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow/job"
@@ -54,6 +55,7 @@ import (
 	"github.com/koderover/zadig/pkg/tool/kube/podexec"
 	larktool "github.com/koderover/zadig/pkg/tool/lark"
 	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/metrics"
 	s3tool "github.com/koderover/zadig/pkg/tool/s3"
 	"github.com/koderover/zadig/pkg/types"
 	jobspec "github.com/koderover/zadig/pkg/types/job"
@@ -100,6 +102,18 @@ type StageTaskPreview struct {
 	Approval  *commonmodels.Approval `bson:"approval"      json:"approval"`
 	Jobs      []*JobTaskPreview      `bson:"jobs"          json:"jobs"`
 	Error     string                 `bson:"error" json:"error""`
+	// Matrices rolls the job tasks expanded from the matrix jobs in the stage up by the workflow job
+	Matrices []*MatrixJobPreview `bson:"matrices"      json:"matrices,omitempty"`
+}
+
+type MatrixJobPreview struct {
+	Name        string                `bson:"name"           json:"name"`
+	Status      config.Status         `bson:"status"         json:"status"`
+	StartTime   int64                 `bson:"start_time"     json:"start_time,omitempty"`
+	EndTime     int64                 `bson:"end_time"       json:"end_time,omitempty"`
+	Total       int                   `bson:"total"          json:"total"`
+	StatusCount map[config.Status]int `bson:"status_count"   json:"status_count"`
+	Jobs        []string              `bson:"jobs"           json:"jobs"`
 }
 
 type JobTaskPreview struct {
@@ -114,7 +128,11 @@ type JobTaskPreview struct {
 	BreakpointAfter  bool          `bson:"breakpoint_after"  json:"breakpoint_after"`
 	Spec             interface{}   `bson:"spec"           json:"spec"`
 	// JobInfo contains the fields that make up the job task name, for frontend display
-	JobInfo interface{} `bson:"job_info" json:"job_info"`
+	JobInfo      interface{}       `bson:"job_info" json:"job_info"`
+	OriginName   string            `bson:"origin_name"    json:"origin_name"`
+	MatrixValues map[string]string `bson:"matrix_values"  json:"matrix_values,omitempty"`
+	// FreezeApproval is the deploy freeze override approval of a production deploy job
+	FreezeApproval *commonmodels.NativeApproval `bson:"freeze_approval" json:"freeze_approval,omitempty"`
 }
 
 type ZadigBuildJobSpec struct {
@@ -406,6 +424,7 @@ func CreateWorkflowTaskV4(args *CreateWorkflowTaskV4Args, workflow *commonmodels
 	workflowTask.WorkflowHash = fmt.Sprintf("%x", dbWorkflow.CalculateHash())
 	// set workflow params repo info, like commitid, branch etc.
 	setZadigParamRepos(workflow, log)
+	analysisRollbackJobs := jobctl.AnalysisRollbackJobs(workflow)
 	for _, stage := range workflow.Stages {
 		stageTask := &commonmodels.StageTask{
 			Name:     stage.Name,
@@ -450,7 +469,7 @@ func CreateWorkflowTaskV4(args *CreateWorkflowTaskV4Args, workflow *commonmodels
 		}
 
 		for _, job := range stage.Jobs {
-			if jobctl.JobSkiped(job) {
+			if jobctl.JobSkiped(job) || analysisRollbackJobs.Has(job.Name) {
 				continue
 			}
 			jobs, err := jobctl.ToJobs(job, workflow, nextTaskID)
@@ -470,6 +489,7 @@ func CreateWorkflowTaskV4(args *CreateWorkflowTaskV4Args, workflow *commonmodels
 
 			stageTask.Jobs = append(stageTask.Jobs, jobs...)
 		}
+		// stages left empty, e.g. the stage of the rollback job run by a prometheus check job, are dropped
 		if len(stageTask.Jobs) > 0 {
 			workflowTask.Stages = append(workflowTask.Stages, stageTask)
 		}
@@ -518,6 +538,9 @@ func CloneWorkflowTaskV4(workflowName string, taskID int64, logger *zap.SugaredL
 		logger.Errorf("find workflowTaskV4 error: %s", err)
 		return nil, e.ErrGetTask.AddErr(err)
 	}
+	if task.OriginWorkflowArgs != nil {
+		task.OriginWorkflowArgs.NotifyCtls = commonmodels.MaskNotifyCtlSecrets(task.OriginWorkflowArgs.NotifyCtls)
+	}
 	return task.OriginWorkflowArgs, nil
 }
 
@@ -557,7 +580,7 @@ func RetryWorkflowTaskV4(workflowName string, taskID int64, logger *zap.SugaredL
 		}
 	}
 
-	for i, stage := range task.Stages {
+	for _, stage := range task.Stages {
 		if stage.Status == config.StatusPassed {
 			continue
 		}
@@ -568,13 +591,19 @@ func RetryWorkflowTaskV4(workflowName string, taskID int64, logger *zap.SugaredL
 
 		if stage.Approval != nil && stage.Approval.Enabled &&
 			stage.Approval.Status != config.StatusPassed && stage.Approval.Status != "" {
-			stage.Approval = task.OriginWorkflowArgs.Stages[i].Approval
+			if approval, ok := originStageApproval(task.OriginWorkflowArgs, stage.Name); ok {
+				stage.Approval = approval
+			}
 		}
 
 		for _, jobTask := range stage.Jobs {
 			if jobTask.Status == config.StatusPassed {
 				continue
 			}
+			// jobs which never ran or were skipped are not counted as retried
+			if jobTask.Status != "" && jobTask.Status != config.StatusSkipped {
+				metrics.IncWorkflowJobRetries(task.ProjectName, task.WorkflowName, jobTask.JobType, string(jobTask.Status))
+			}
 			jobTask.Status = ""
 			jobTask.StartTime = 0
 			jobTask.EndTime = 0
@@ -601,6 +630,17 @@ func RetryWorkflowTaskV4(workflowName string, taskID int64, logger *zap.SugaredL
 	return nil
 }
 
+// originStageApproval returns the approval of the stage in the workflow, the stages of the task are found by name
+// since the stages left empty when the task is created are dropped from the task.
+func originStageApproval(workflow *commonmodels.WorkflowV4, stageName string) (*commonmodels.Approval, bool) {
+	for _, stage := range workflow.Stages {
+		if stage.Name == stageName {
+			return stage.Approval, true
+		}
+	}
+	return nil, false
+}
+
 func SetWorkflowTaskV4Breakpoint(workflowName, jobName string, taskID int64, set bool, position string, logger *zap.SugaredLogger) error {
 	w := workflowcontroller.GetWorkflowTaskInMap(workflowName, taskID)
 	if w == nil {
@@ -1182,6 +1222,7 @@ func GetWorkflowTaskV4(workflowName string, taskID int64, logger *zap.SugaredLog
 			Approval:  stage.Approval,
 			Jobs:      jobsToJobPreviews(stage.Jobs, task.GlobalContext, timeNow, task.ProjectName),
 			Error:     stage.Error,
+			Matrices:  jobsToMatrixPreviews(stage.Jobs),
 		})
 	}
 	return resp, nil
@@ -1200,6 +1241,19 @@ func ApproveStage(workflowName, stageName, userName, userID, comment string, tas
 	return nil
 }
 
+func ApproveDeployFreezeOverride(workflowName, jobName, userName, userID, comment string, taskID int64, approve bool, logger *zap.SugaredLogger) error {
+	if workflowName == "" || jobName == "" || taskID == 0 {
+		errMsg := fmt.Sprintf("can not find workflow: %s, taskID: %d, job: %s waiting for deploy freeze override", workflowName, taskID, jobName)
+		logger.Error(errMsg)
+		return e.ErrApproveTask.AddDesc(errMsg)
+	}
+	if err := jobcontroller.ApproveDeployFreezeOverride(workflowName, jobName, userName, userID, comment, taskID, approve); err != nil {
+		logger.Error(err)
+		return e.ErrApproveTask.AddErr(err)
+	}
+	return nil
+}
+
 func jobsToJobPreviews(jobs []*commonmodels.JobTask, context map[string]string, now int64, projectName string) []*JobTaskPreview {
 	resp := []*JobTaskPreview{}
 
@@ -1235,7 +1289,10 @@ func jobsToJobPreviews(jobs []*commonmodels.JobTask, context map[string]string,
 			BreakpointBefore: job.BreakpointBefore,
 			BreakpointAfter:  job.BreakpointAfter,
 			CostSeconds:      costSeconds,
+			FreezeApproval:   job.FreezeApproval,
 			JobInfo:          job.JobInfo,
+			OriginName:       job.OriginName,
+			MatrixValues:     job.MatrixValues,
 		}
 		switch job.JobType {
 		case string(config.JobFreestyle):
@@ -1816,3 +1873,74 @@ func ListWorkflowFilterInfo(project, workflow, typeName string, jobName string,
 		return nil, fmt.Errorf("queryType parameter is invalid")
 	}
 }
+
+// jobsToMatrixPreviews aggregates the job tasks expanded from the same matrix job into one preview.
+func jobsToMatrixPreviews(jobs []*commonmodels.JobTask) []*MatrixJobPreview {
+	resp := []*MatrixJobPreview{}
+	previews := make(map[string]*MatrixJobPreview)
+	statuses := make(map[string][]config.Status)
+	for _, job := range jobs {
+		if job.MatrixValues == nil {
+			continue
+		}
+		preview, ok := previews[job.OriginName]
+		if !ok {
+			preview = &MatrixJobPreview{Name: job.OriginName, StatusCount: map[config.Status]int{}}
+			previews[job.OriginName] = preview
+			resp = append(resp, preview)
+		}
+		preview.Total++
+		preview.Jobs = append(preview.Jobs, job.Name)
+		preview.StatusCount[job.Status]++
+		if job.StartTime != 0 && (preview.StartTime == 0 || job.StartTime < preview.StartTime) {
+			preview.StartTime = job.StartTime
+		}
+		if job.EndTime > preview.EndTime {
+			preview.EndTime = job.EndTime
+		}
+		statuses[job.OriginName] = append(statuses[job.OriginName], job.Status)
+	}
+	for _, preview := range resp {
+		preview.Status = aggregateMatrixStatus(statuses[preview.Name])
+		if preview.Status == config.StatusRunning {
+			preview.EndTime = 0
+		}
+	}
+	return resp
+}
+
+// aggregateMatrixStatus returns the status of a matrix job by the status of its job tasks,
+// the matrix is running while any of its job tasks is running, otherwise the worst status of the job tasks wins.
+func aggregateMatrixStatus(statuses []config.Status) config.Status {
+	var failed config.Status
+	passed, skipped := 0, 0
+	for _, status := range statuses {
+		switch status {
+		case config.StatusPassed:
+			passed++
+		case config.StatusSkipped:
+			skipped++
+		case config.StatusFailed, config.StatusTimeout, config.StatusCancelled, config.StatusReject:
+			if failed == "" || status == config.StatusFailed {
+				failed = status
+			}
+		case "", config.StatusCreated:
+		default:
+			return config.StatusRunning
+		}
+	}
+	switch {
+	case failed != "":
+		return failed
+	case passed+skipped < len(statuses):
+		// some job tasks are waiting for the others of the matrix to finish
+		if passed+skipped > 0 {
+			return config.StatusRunning
+		}
+		return config.StatusCreated
+	case passed == 0:
+		return config.StatusSkipped
+	default:
+		return config.StatusPassed
+	}
+}
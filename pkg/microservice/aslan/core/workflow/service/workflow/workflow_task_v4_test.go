@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/koderover/zadig/pkg/microservice/aslan/config"
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+var _ = Describe("Testing workflow task v4", func() {
+
+	Context("aggregateMatrixStatus", func() {
+		It("should be running while any job task is running", func() {
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusPassed, config.StatusFailed, config.StatusRunning})).To(Equal(config.StatusRunning))
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusPassed, ""})).To(Equal(config.StatusRunning))
+		})
+		It("should be failed if any job task is failed", func() {
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusPassed, config.StatusCancelled, config.StatusFailed, ""})).To(Equal(config.StatusFailed))
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusPassed, config.StatusTimeout})).To(Equal(config.StatusTimeout))
+		})
+		It("should be passed if all job tasks are passed or skipped", func() {
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusPassed, config.StatusSkipped})).To(Equal(config.StatusPassed))
+			Expect(aggregateMatrixStatus([]config.Status{config.StatusSkipped, config.StatusSkipped})).To(Equal(config.StatusSkipped))
+			Expect(aggregateMatrixStatus([]config.Status{"", config.StatusCreated})).To(Equal(config.StatusCreated))
+		})
+	})
+
+	Context("jobsToMatrixPreviews", func() {
+		It("should roll the matrix job tasks up by the workflow job", func() {
+			previews := jobsToMatrixPreviews([]*commonmodels.JobTask{
+				{Name: "build-matrix-1", OriginName: "build", Status: config.StatusPassed, StartTime: 10, EndTime: 20, MatrixValues: map[string]string{"ARCH": "amd64"}},
+				{Name: "build-matrix-2", OriginName: "build", Status: config.StatusPassed, StartTime: 5, EndTime: 30, MatrixValues: map[string]string{"ARCH": "arm64"}},
+				{Name: "deploy", OriginName: "deploy", Status: config.StatusPassed},
+			})
+			Expect(previews).To(HaveLen(1))
+			Expect(previews[0].Name).To(Equal("build"))
+			Expect(previews[0].Status).To(Equal(config.StatusPassed))
+			Expect(previews[0].Total).To(Equal(2))
+			Expect(previews[0].StartTime).To(Equal(int64(5)))
+			Expect(previews[0].EndTime).To(Equal(int64(30)))
+			Expect(previews[0].Jobs).To(Equal([]string{"build-matrix-1", "build-matrix-2"}))
+		})
+	})
+
+	Context("originStageApproval", func() {
+		It("should find the approval of the stage by name after the empty stages are dropped", func() {
+			approval := &commonmodels.Approval{Enabled: true}
+			workflow := &commonmodels.WorkflowV4{Stages: []*commonmodels.WorkflowStage{
+				{Name: "build"},
+				{Name: "rollback"},
+				{Name: "deploy", Approval: approval},
+			}}
+			got, ok := originStageApproval(workflow, "deploy")
+			Expect(ok).To(BeTrue())
+			Expect(got).To(Equal(approval))
+
+			_, ok = originStageApproval(workflow, "unknown")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -78,6 +78,7 @@ import (
 	"github.com/koderover/zadig/pkg/tool/kube/serializer"
 	"github.com/koderover/zadig/pkg/tool/lark"
 	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/sqlclient"
 	"github.com/koderover/zadig/pkg/types"
 )
 
@@ -268,6 +269,7 @@ func UpdateWorkflowV4(name, user string, inputWorkflow *commonmodels.WorkflowV4,
 	inputWorkflow.GeneralHookCtls = workflow.GeneralHookCtls
 	inputWorkflow.MeegoHookCtls = workflow.MeegoHookCtls
 	inputWorkflow.CustomField = workflow.CustomField
+	commonmodels.EnsureNotifyCtlSecrets(workflow.NotifyCtls, inputWorkflow.NotifyCtls)
 
 	for _, stage := range inputWorkflow.Stages {
 		for _, job := range stage.Jobs {
@@ -706,6 +708,7 @@ func clearWorkflowV4Triggers(workflow *commonmodels.WorkflowV4) {
 }
 
 func ensureWorkflowV4Resp(encryptedKey string, workflow *commonmodels.WorkflowV4, logger *zap.SugaredLogger) error {
+	workflow.NotifyCtls = commonmodels.MaskNotifyCtlSecrets(workflow.NotifyCtls)
 	for _, stage := range workflow.Stages {
 		for _, job := range stage.Jobs {
 			if job.JobType == config.JobZadigBuild {
@@ -909,6 +912,86 @@ func LintWorkflowV4(workflow *commonmodels.WorkflowV4, logger *zap.SugaredLogger
 			}
 		}
 	}
+	if err := lintJobDependencies(workflow.Stages); err != nil {
+		logger.Errorf("lint job dependencies failed: %v", err)
+		return e.ErrUpsertWorkflow.AddErr(err)
+	}
+	return nil
+}
+
+// lintJobDependencies checks the depends_on of jobs, a job can only depend on jobs in the same or previous stages,
+// and the dependency graph, including the implicit dependencies by stage order, must be acyclic.
+func lintJobDependencies(stages []*commonmodels.WorkflowStage) error {
+	jobStageIndex := make(map[string]int)
+	for i, stage := range stages {
+		for _, job := range stage.Jobs {
+			jobStageIndex[job.Name] = i
+		}
+	}
+
+	hasDependency := false
+	graph := make(map[string][]string)
+	for i, stage := range stages {
+		for j, job := range stage.Jobs {
+			for _, dep := range job.DependsOn {
+				hasDependency = true
+				if dep == job.Name {
+					return fmt.Errorf("job %s can not depend on itself", job.Name)
+				}
+				depStageIndex, ok := jobStageIndex[dep]
+				if !ok {
+					return fmt.Errorf("job %s depends on job %s which does not exist", job.Name, dep)
+				}
+				if depStageIndex > i {
+					return fmt.Errorf("job %s can not depend on job %s in a later stage", job.Name, dep)
+				}
+			}
+			switch {
+			case len(job.DependsOn) > 0:
+				graph[job.Name] = job.DependsOn
+			case !stage.Parallel && j > 0:
+				graph[job.Name] = []string{stage.Jobs[j-1].Name}
+			case i > 0:
+				for _, prevJob := range stages[i-1].Jobs {
+					graph[job.Name] = append(graph[job.Name], prevJob.Name)
+				}
+			}
+		}
+	}
+	if !hasDependency {
+		return nil
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("circular job dependency found: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range graph[name] {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, stage := range stages {
+		for _, job := range stage.Jobs {
+			if err := visit(job.Name, nil); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -2721,6 +2804,16 @@ func ValidateSQL(_type config.DBInstanceType, sql string) error {
 	switch _type {
 	case config.DBInstanceTypeMySQL, config.DBInstanceTypeMariaDB:
 		return ValidateMySQL(sql)
+	case config.DBInstanceTypePostgreSQL, config.DBInstanceTypeSQLServer, config.DBInstanceTypeClickHouse:
+		// there is no parser for these dialects, only make sure the statements can be split from the script
+		statements, err := sqlclient.SplitScript(string(_type), sql)
+		if err != nil {
+			return err
+		}
+		if len(statements) == 0 {
+			return errors.New("no sql statement found")
+		}
+		return nil
 	default:
 		return errors.Errorf("not supported db type: %s", _type)
 	}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+var _ = Describe("Testing workflow v4", func() {
+
+	Context("lintJobDependencies", func() {
+		newStage := func(name string, parallel bool, jobs ...*commonmodels.Job) *commonmodels.WorkflowStage {
+			return &commonmodels.WorkflowStage{Name: name, Parallel: parallel, Jobs: jobs}
+		}
+		newJob := func(name string, dependsOn ...string) *commonmodels.Job {
+			return &commonmodels.Job{Name: name, DependsOn: dependsOn}
+		}
+
+		It("should be passed for workflows without dependencies", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", true, newJob("build-a"), newJob("build-b")),
+				newStage("deploy", false, newJob("deploy-a"), newJob("deploy-b")),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should be passed for dependencies across stages", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", true, newJob("build-a"), newJob("build-b")),
+				newStage("deploy", true, newJob("deploy-a", "build-a"), newJob("deploy-b", "build-b")),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should raise error for dependencies on jobs in later stages", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", true, newJob("build-a", "deploy-a")),
+				newStage("deploy", true, newJob("deploy-a")),
+			})
+			Expect(err).Should(HaveOccurred())
+		})
+		It("should raise error for dependencies on non-existent jobs", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", true, newJob("build-a", "build-c")),
+			})
+			Expect(err).Should(HaveOccurred())
+		})
+		It("should raise error for circular dependencies", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", true, newJob("build-a", "build-b"), newJob("build-b", "build-a")),
+			})
+			Expect(err).Should(HaveOccurred())
+		})
+		It("should raise error for circular dependencies with the implicit serial order", func() {
+			err := lintJobDependencies([]*commonmodels.WorkflowStage{
+				newStage("build", false, newJob("build-a", "build-b"), newJob("build-b")),
+			})
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -51,6 +51,16 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		tester.GET("", ListTestModules)
 		tester.GET("/:name", GetTestModule)
 		tester.DELETE("/:name", DeleteTestModule)
+
+		// per test case history, flaky test cases and quarantine
+		tester.GET("/:name/cases", ListTestCaseResults)
+		tester.GET("/:name/cases/flaky", ListFlakyTestCases)
+		tester.GET("/:name/quarantine", ListTestCaseQuarantine)
+		tester.POST("/:name/quarantine", CreateTestCaseQuarantine)
+		tester.DELETE("/:name/quarantine", DeleteTestCaseQuarantine)
+
+		// coverage of the runs of the testing module
+		tester.GET("/:name/coverage", ListTestingCoverage)
 	}
 
 	// ---------------------------------------------------------------------------------------
@@ -71,6 +81,9 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		scanner.GET("/:id/task/:scan_id", FindScanningProjectNameFromID, GetScanningTask)
 		scanner.DELETE("/:id/task/:scan_id", FindScanningProjectNameFromID, CancelScanningTask)
 		scanner.GET("/:id/task/:scan_id/sse", FindScanningProjectNameFromID, GetScanningTaskSSE)
+
+		// sarif findings of the scanning jobs in workflow tasks
+		scanner.GET("/findings", ListScanningFindings)
 	}
 
 	//testStat := router.Group("teststat")
@@ -0,0 +1,40 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"go.uber.org/zap"
+
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+// ListTestingCoverage lists the coverage of the runs of the testing module, newest first
+func ListTestingCoverage(projectName, testName, serviceName string, pageNum, pageSize int64, log *zap.SugaredLogger) (*ListTestingCoverageResp, error) {
+	records, total, err := commonrepo.NewCoverageRecordColl().List(&commonrepo.CoverageRecordListOption{
+		ProjectNames: []string{projectName},
+		TestName:     testName,
+		ServiceName:  serviceName,
+		PageNum:      pageNum,
+		PageSize:     pageSize,
+	})
+	if err != nil {
+		log.Errorf("failed to list coverage of %s/%s, error: %s", projectName, testName, err)
+		return nil, e.ErrListCoverageRecords.AddErr(err)
+	}
+	return &ListTestingCoverageResp{Records: records, Total: total}, nil
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/koderover/zadig/pkg/setting"
 	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
 	e "github.com/koderover/zadig/pkg/tool/errors"
+	"github.com/koderover/zadig/pkg/tool/sarif"
 	"github.com/koderover/zadig/pkg/tool/sonar"
 	"github.com/koderover/zadig/pkg/types"
 )
@@ -54,6 +55,10 @@ func CreateScanningModule(username string, args *Scanning, log *zap.SugaredLogge
 		return e.ErrCreateScanningModule.AddErr(err)
 	}
 
+	if err := lintSarifReport(args); err != nil {
+		return e.ErrCreateScanningModule.AddErr(err)
+	}
+
 	err = commonservice.ProcessWebhook(args.AdvancedSetting.HookCtl.Items, nil, webhook.ScannerPrefix+args.Name, log)
 	if err != nil {
 		return e.ErrCreateScanningModule.AddErr(err)
@@ -88,6 +93,10 @@ func UpdateScanningModule(id, username string, args *Scanning, log *zap.SugaredL
 		return e.ErrUpdateScanningModule.AddErr(err)
 	}
 
+	if err := lintSarifReport(args); err != nil {
+		return e.ErrUpdateScanningModule.AddErr(err)
+	}
+
 	if scanning.AdvancedSetting.HookCtl.Enabled {
 		err = commonservice.ProcessWebhook(args.AdvancedSetting.HookCtl.Items, scanning.AdvancedSetting.HookCtl.Items, webhook.ScannerPrefix+args.Name, log)
 		if err != nil {
@@ -115,6 +124,22 @@ func UpdateScanningModule(id, username string, args *Scanning, log *zap.SugaredL
 	return nil
 }
 
+func lintSarifReport(args *Scanning) error {
+	if args.SarifReport == nil || !args.SarifReport.Enabled {
+		return nil
+	}
+	if args.ScannerType == types.ScanningTypeSonar {
+		return fmt.Errorf("sarif report is only supported by the other scanners")
+	}
+	if args.SarifReport.ReportDir == "" {
+		return fmt.Errorf("sarif report dir is required")
+	}
+	if args.SarifReport.SeverityThreshold != "" && !sarif.ValidSeverity(args.SarifReport.SeverityThreshold) {
+		return fmt.Errorf("invalid sarif severity threshold: %s", args.SarifReport.SeverityThreshold)
+	}
+	return nil
+}
+
 func ListScanningModule(projectName string, log *zap.SugaredLogger) ([]*ListScanningRespItem, int64, error) {
 	scanningList, total, err := commonrepo.NewScanningColl().List(&commonrepo.ScanningListOption{ProjectName: projectName}, 0, 0)
 	if err != nil {
@@ -521,6 +546,21 @@ func GetScanningTaskInfo(scanningID string, taskID int64, log *zap.SugaredLogger
 	}, nil
 }
 
+func ListScanningFindings(projectName, workflowName string, taskID int64, log *zap.SugaredLogger) ([]*commonmodels.ScanningFinding, error) {
+	findings, err := commonrepo.NewScanningFindingColl().ListByWorkflowTask(workflowName, taskID)
+	if err != nil {
+		log.Errorf("failed to list scanning findings of workflow %s task %d, error: %s", workflowName, taskID, err)
+		return nil, e.ErrListScanningFindings.AddErr(err)
+	}
+	resp := make([]*commonmodels.ScanningFinding, 0)
+	for _, finding := range findings {
+		if finding.ProjectName == projectName {
+			resp = append(resp, finding)
+		}
+	}
+	return resp, nil
+}
+
 func CancelScanningTask(userName, scanningID string, taskID int64, typeString config.PipelineType, requestID string, log *zap.SugaredLogger) error {
 	scanningInfo, err := commonrepo.NewScanningColl().GetByID(scanningID)
 	if err != nil {
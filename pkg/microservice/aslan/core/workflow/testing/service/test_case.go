@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+	commonrepo "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/mongodb"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+func ListTestCaseResults(projectName, testName, caseName, status string, pageNum, pageSize int64, log *zap.SugaredLogger) (*ListTestCaseResultsResp, error) {
+	results, total, err := commonrepo.NewTestCaseResultColl().List(&commonrepo.TestCaseResultListOption{
+		ProjectName: projectName,
+		TestName:    testName,
+		CaseName:    caseName,
+		Status:      status,
+		PageNum:     pageNum,
+		PageSize:    pageSize,
+	})
+	if err != nil {
+		log.Errorf("failed to list test case results of %s/%s, error: %s", projectName, testName, err)
+		return nil, e.ErrListTestCaseResults.AddErr(err)
+	}
+	return &ListTestCaseResultsResp{Results: results, Total: total}, nil
+}
+
+// ListFlakyTestCases scores the test cases of the testing module by the results in the last days.
+// The results of a case are grouped by commit, or by workflow task if the commit is unknown, a group
+// with both passed and failed results is flaky since the code under test did not change.
+func ListFlakyTestCases(projectName, testName string, days int64, log *zap.SugaredLogger) ([]*FlakyTestCase, error) {
+	results, _, err := commonrepo.NewTestCaseResultColl().List(&commonrepo.TestCaseResultListOption{
+		ProjectName: projectName,
+		TestName:    testName,
+		StartTime:   time.Now().AddDate(0, 0, -int(days)).Unix(),
+	})
+	if err != nil {
+		log.Errorf("failed to list test case results of %s/%s, error: %s", projectName, testName, err)
+		return nil, e.ErrListFlakyTestCases.AddErr(err)
+	}
+	quarantined, err := commonrepo.NewTestCaseQuarantineColl().List(projectName, testName)
+	if err != nil {
+		log.Errorf("failed to list quarantined test cases of %s/%s, error: %s", projectName, testName, err)
+		return nil, e.ErrListFlakyTestCases.AddErr(err)
+	}
+	quarantinedCases := sets.NewString()
+	for _, tc := range quarantined {
+		quarantinedCases.Insert(tc.CaseName)
+	}
+
+	return scoreFlakyTestCases(results, quarantinedCases), nil
+}
+
+func scoreFlakyTestCases(results []*commonmodels.TestCaseResult, quarantined sets.String) []*FlakyTestCase {
+	type runResult struct {
+		passed bool
+		failed bool
+	}
+	caseRuns := make(map[string]map[string]*runResult)
+	cases := make(map[string]*FlakyTestCase)
+	for _, result := range results {
+		if result.Status == commonmodels.TestCaseStatusSkipped {
+			continue
+		}
+		tc, ok := cases[result.CaseName]
+		if !ok {
+			tc = &FlakyTestCase{CaseName: result.CaseName, Quarantined: quarantined.Has(result.CaseName)}
+			cases[result.CaseName] = tc
+			caseRuns[result.CaseName] = make(map[string]*runResult)
+		}
+		tc.Executions++
+
+		runKey := result.CommitID
+		if runKey == "" {
+			runKey = fmt.Sprintf("%s/%d", result.WorkflowName, result.TaskID)
+		}
+		run, ok := caseRuns[result.CaseName][runKey]
+		if !ok {
+			run = &runResult{}
+			caseRuns[result.CaseName][runKey] = run
+		}
+		if result.Status == commonmodels.TestCaseStatusPassed {
+			run.passed = true
+			continue
+		}
+		run.failed = true
+		tc.Failures++
+		if result.CreateTime > tc.LastFailedTime {
+			tc.LastFailedTime = result.CreateTime
+		}
+	}
+
+	resp := make([]*FlakyTestCase, 0)
+	for name, tc := range cases {
+		for _, run := range caseRuns[name] {
+			tc.Runs++
+			if run.passed && run.failed {
+				tc.FlakyRuns++
+			}
+		}
+		if tc.FlakyRuns == 0 {
+			continue
+		}
+		tc.FlakyScore = float64(tc.FlakyRuns) / float64(tc.Runs)
+		resp = append(resp, tc)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].FlakyScore != resp[j].FlakyScore {
+			return resp[i].FlakyScore > resp[j].FlakyScore
+		}
+		return resp[i].CaseName < resp[j].CaseName
+	})
+	return resp
+}
+
+func ListTestCaseQuarantine(projectName, testName string, log *zap.SugaredLogger) ([]*commonmodels.TestCaseQuarantine, error) {
+	resp, err := commonrepo.NewTestCaseQuarantineColl().List(projectName, testName)
+	if err != nil {
+		log.Errorf("failed to list quarantined test cases of %s/%s, error: %s", projectName, testName, err)
+		return nil, e.ErrListTestCaseQuarantine.AddErr(err)
+	}
+	return resp, nil
+}
+
+func CreateTestCaseQuarantine(projectName, testName, username string, args *CreateTestCaseQuarantineArgs, log *zap.SugaredLogger) error {
+	if args.CaseName == "" {
+		return e.ErrInvalidParam.AddDesc("case_name must be provided")
+	}
+	if _, err := commonrepo.NewTestingColl().Find(testName, projectName); err != nil {
+		return e.ErrCreateTestCaseQuarantine.AddDesc(fmt.Sprintf("testing module %s not found: %s", testName, err))
+	}
+	err := commonrepo.NewTestCaseQuarantineColl().Upsert(&commonmodels.TestCaseQuarantine{
+		ProjectName: projectName,
+		TestName:    testName,
+		CaseName:    args.CaseName,
+		Reason:      args.Reason,
+		CreatedBy:   username,
+		CreateTime:  time.Now().Unix(),
+	})
+	if err != nil {
+		log.Errorf("failed to quarantine test case %s of %s/%s, error: %s", args.CaseName, projectName, testName, err)
+		return e.ErrCreateTestCaseQuarantine.AddErr(err)
+	}
+	return nil
+}
+
+func DeleteTestCaseQuarantine(projectName, testName, caseName string, log *zap.SugaredLogger) error {
+	if err := commonrepo.NewTestCaseQuarantineColl().Delete(projectName, testName, caseName); err != nil {
+		log.Errorf("failed to delete quarantined test case %s of %s/%s, error: %s", caseName, projectName, testName, err)
+		return e.ErrDeleteTestCaseQuarantine.AddErr(err)
+	}
+	return nil
+}
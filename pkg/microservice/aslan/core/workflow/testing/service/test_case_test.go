@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	commonmodels "github.com/koderover/zadig/pkg/microservice/aslan/core/common/repository/models"
+)
+
+func TestScoreFlakyTestCases(t *testing.T) {
+	result := func(caseName, status, commitID string, taskID, createTime int64) *commonmodels.TestCaseResult {
+		return &commonmodels.TestCaseResult{
+			WorkflowName: "workflow",
+			TaskID:       taskID,
+			CaseName:     caseName,
+			Status:       status,
+			CommitID:     commitID,
+			CreateTime:   createTime,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		results     []*commonmodels.TestCaseResult
+		quarantined sets.String
+		expect      []*FlakyTestCase
+	}{
+		{
+			name:    "no results",
+			results: []*commonmodels.TestCaseResult{},
+			expect:  []*FlakyTestCase{},
+		},
+		{
+			name: "stable cases are not flaky",
+			results: []*commonmodels.TestCaseResult{
+				result("passed", commonmodels.TestCaseStatusPassed, "c1", 1, 1),
+				result("passed", commonmodels.TestCaseStatusPassed, "c1", 2, 2),
+				result("broken", commonmodels.TestCaseStatusFailed, "c1", 1, 1),
+				result("broken", commonmodels.TestCaseStatusError, "c1", 2, 2),
+				result("fixed", commonmodels.TestCaseStatusFailed, "c1", 1, 1),
+				result("fixed", commonmodels.TestCaseStatusPassed, "c2", 2, 2),
+			},
+			expect: []*FlakyTestCase{},
+		},
+		{
+			name: "skipped results are ignored",
+			results: []*commonmodels.TestCaseResult{
+				result("skipped", commonmodels.TestCaseStatusPassed, "c1", 1, 1),
+				result("skipped", commonmodels.TestCaseStatusSkipped, "c1", 2, 2),
+			},
+			expect: []*FlakyTestCase{},
+		},
+		{
+			name: "runs grouped by commit and by task without a commit",
+			results: []*commonmodels.TestCaseResult{
+				result("a", commonmodels.TestCaseStatusPassed, "c1", 1, 1),
+				result("a", commonmodels.TestCaseStatusFailed, "c1", 2, 5),
+				result("a", commonmodels.TestCaseStatusFailed, "c1", 3, 3),
+				result("a", commonmodels.TestCaseStatusPassed, "c2", 4, 4),
+				result("b", commonmodels.TestCaseStatusPassed, "", 1, 1),
+				result("b", commonmodels.TestCaseStatusError, "", 1, 2),
+				result("b", commonmodels.TestCaseStatusFailed, "", 2, 3),
+				result("c", commonmodels.TestCaseStatusFailed, "", 1, 1),
+				result("c", commonmodels.TestCaseStatusPassed, "", 1, 1),
+			},
+			quarantined: sets.NewString("c"),
+			expect: []*FlakyTestCase{
+				{CaseName: "c", Runs: 1, FlakyRuns: 1, Executions: 2, Failures: 1, FlakyScore: 1, Quarantined: true, LastFailedTime: 1},
+				{CaseName: "a", Runs: 2, FlakyRuns: 1, Executions: 4, Failures: 2, FlakyScore: 0.5, LastFailedTime: 5},
+				{CaseName: "b", Runs: 2, FlakyRuns: 1, Executions: 3, Failures: 2, FlakyScore: 0.5, LastFailedTime: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quarantined := tt.quarantined
+			if quarantined == nil {
+				quarantined = sets.NewString()
+			}
+			assert.Equal(t, tt.expect, scoreFlakyTestCases(tt.results, quarantined))
+		})
+	}
+}
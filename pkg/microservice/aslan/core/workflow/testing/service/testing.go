@@ -40,6 +40,7 @@ import (
 	commonutil "github.com/koderover/zadig/pkg/microservice/aslan/core/common/util"
 	workflowservice "github.com/koderover/zadig/pkg/microservice/aslan/core/workflow/service/workflow"
 	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/coverage"
 	e "github.com/koderover/zadig/pkg/tool/errors"
 	s3tool "github.com/koderover/zadig/pkg/tool/s3"
 	"github.com/koderover/zadig/pkg/types"
@@ -54,6 +55,9 @@ func CreateTesting(username string, testing *commonmodels.Testing, log *zap.Suga
 	if err := commonutil.CheckDefineResourceParam(testing.PreTest.ResReq, testing.PreTest.ResReqSpec); err != nil {
 		return e.ErrCreateTestModule.AddDesc(err.Error())
 	}
+	if err := lintCoverage(testing.Coverage); err != nil {
+		return e.ErrCreateTestModule.AddErr(err)
+	}
 	err := HandleCronjob(testing, log)
 	if err != nil {
 		return e.ErrCreateTestModule.AddErr(err)
@@ -74,6 +78,24 @@ func CreateTesting(username string, testing *commonmodels.Testing, log *zap.Suga
 	return nil
 }
 
+func lintCoverage(args *commonmodels.TestingCoverage) error {
+	if args == nil || !args.Enabled {
+		return nil
+	}
+	if args.ReportPath == "" {
+		return fmt.Errorf("coverage report path is required")
+	}
+	if args.Format != "" && !coverage.ValidFormat(args.Format) {
+		return fmt.Errorf("invalid coverage format: %s", args.Format)
+	}
+	for _, rate := range []float64{args.MinLineCoverage, args.MinBranchCoverage, args.MinDiffCoverage} {
+		if rate < 0 || rate > 100 {
+			return fmt.Errorf("minimum coverage must be between 0 and 100")
+		}
+	}
+	return nil
+}
+
 func HandleCronjob(testing *commonmodels.Testing, log *zap.SugaredLogger) error {
 	testSchedule := testing.Schedules
 
@@ -117,6 +139,9 @@ func UpdateTesting(username string, testing *commonmodels.Testing, log *zap.Suga
 	if err := commonutil.CheckDefineResourceParam(testing.PreTest.ResReq, testing.PreTest.ResReqSpec); err != nil {
 		return e.ErrUpdateTestModule.AddDesc(err.Error())
 	}
+	if err := lintCoverage(testing.Coverage); err != nil {
+		return e.ErrUpdateTestModule.AddErr(err)
+	}
 	err := HandleCronjob(testing, log)
 	if err != nil {
 		return e.ErrUpdateTestModule.AddErr(err)
@@ -45,6 +45,7 @@ type Scanning struct {
 	CheckQualityGate bool                                  `json:"check_quality_gate"`
 	Outputs          []*commonmodels.Output                `json:"outputs"`
 	NotifyCtls       []*commonmodels.NotifyCtl             `json:"notify_ctls"`
+	SarifReport      *commonmodels.ScanningSarifReport     `json:"sarif_report"`
 }
 
 // TODO: change the logic of create scanning
@@ -181,6 +182,7 @@ func ConvertToDBScanningModule(args *Scanning) *commonmodels.Scanning {
 		CheckQualityGate: args.CheckQualityGate,
 		Outputs:          args.Outputs,
 		Envs:             args.Envs,
+		SarifReport:      args.SarifReport,
 	}
 }
 
@@ -205,6 +207,7 @@ func ConvertDBScanningModule(scanning *commonmodels.Scanning) *Scanning {
 		CheckQualityGate: scanning.CheckQualityGate,
 		Outputs:          scanning.Outputs,
 		Envs:             scanning.Envs,
+		SarifReport:      scanning.SarifReport,
 	}
 }
 
@@ -287,3 +290,31 @@ const (
 	VerbDeleteTest = "delete_test"
 	VerbRunTest    = "run_test"
 )
+
+type ListTestCaseResultsResp struct {
+	Results []*commonmodels.TestCaseResult `json:"results"`
+	Total   int64                          `json:"total"`
+}
+
+type FlakyTestCase struct {
+	CaseName string `json:"case_name"`
+	// Runs is the number of the commits, or the workflow tasks for the runs without a commit, the case ran in
+	Runs       int `json:"runs"`
+	FlakyRuns  int `json:"flaky_runs"`
+	Executions int `json:"executions"`
+	Failures   int `json:"failures"`
+	// FlakyScore is the ratio of the runs in which the case both passed and failed
+	FlakyScore     float64 `json:"flaky_score"`
+	Quarantined    bool    `json:"quarantined"`
+	LastFailedTime int64   `json:"last_failed_time"`
+}
+
+type CreateTestCaseQuarantineArgs struct {
+	CaseName string `json:"case_name"`
+	Reason   string `json:"reason"`
+}
+
+type ListTestingCoverageResp struct {
+	Records []*commonmodels.CoverageRecord `json:"records"`
+	Total   int64                          `json:"total"`
+}
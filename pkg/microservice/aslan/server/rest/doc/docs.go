@@ -5386,6 +5386,9 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.NotificationEvent"
                     }
                 },
+                "webhook_secret": {
+                    "type": "string"
+                },
                 "webhook_type": {
                     "$ref": "#/definitions/models.WebHookType"
                 },
@@ -5978,12 +5981,18 @@ const docTemplate = `{
             "enum": [
                 "feishu",
                 "dingding",
-                "wechat"
+                "wechat",
+                "slack",
+                "msteams",
+                "webhook"
             ],
             "x-enum-varnames": [
                 "WebHookTypeFeishu",
                 "WebHookTypeDingding",
-                "WebHookTypeWeChat"
+                "WebHookTypeWeChat",
+                "WebHookTypeSlack",
+                "WebHookTypeMSTeams",
+                "WebHookTypeGeneric"
             ]
         },
         "models.ZadigDeployJobSpec": {
@@ -69,6 +69,12 @@ func init() {
 	metrics.Metrics.MustRegister(metrics.CPU)
 	metrics.Metrics.MustRegister(metrics.Memory)
 	metrics.Metrics.MustRegister(metrics.ResponseTime)
+	metrics.Metrics.MustRegister(metrics.WorkflowTaskQueueWait)
+	metrics.Metrics.MustRegister(metrics.WorkflowTaskDuration)
+	metrics.Metrics.MustRegister(metrics.WorkflowJobDuration)
+	metrics.Metrics.MustRegister(metrics.WorkflowJobRetries)
+	metrics.Metrics.MustRegister(metrics.WorkflowApprovalWait)
+	metrics.Metrics.MustRegister(metrics.WorkflowJobPodScheduling)
 
 	metrics.UpdatePodMetrics()
 }
@@ -149,6 +149,18 @@ func SetConfig(key, value string) *exec.Cmd {
 	)
 }
 
+// SetLocalConfig returns command: git config --local $KEY $VALUE
+// e.g. git config --local http.extraHeader "Authorization: Bearer TOKEN"
+func SetLocalConfig(key, value string) *exec.Cmd {
+	return exec.Command(
+		"git",
+		"config",
+		"--local",
+		key,
+		value,
+	)
+}
+
 // SetConfig returns command: git config --global $KEY $VA
 // e.g. git config --global user.name username
 func Gc() *exec.Cmd {
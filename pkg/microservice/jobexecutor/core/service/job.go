@@ -27,11 +27,14 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/koderover/zadig/pkg/microservice/jobexecutor/config"
 	"github.com/koderover/zadig/pkg/microservice/jobexecutor/core/service/configmap"
 	"github.com/koderover/zadig/pkg/microservice/jobexecutor/core/service/meta"
 	"github.com/koderover/zadig/pkg/microservice/jobexecutor/core/service/step"
+	reapermeta "github.com/koderover/zadig/pkg/microservice/reaper/core/service/meta"
+	"github.com/koderover/zadig/pkg/setting"
 	"github.com/koderover/zadig/pkg/tool/log"
 	"github.com/koderover/zadig/pkg/types/job"
 )
@@ -80,7 +83,7 @@ func NewJob() (*Job, error) {
 	userEnvs := job.getUserEnvs()
 	job.UserEnvs = make(map[string]string, len(userEnvs))
 	for _, env := range userEnvs {
-		items := strings.Split(env, "=")
+		items := strings.SplitN(env, "=", 2)
 		if len(items) != 2 {
 			continue
 		}
@@ -91,6 +94,30 @@ func NewJob() (*Job, error) {
 	return job, nil
 }
 
+// ResolveSecretEnvs replaces the secret references in the secret envs with the secrets, the kubernetes
+// provider reads the secrets with the service account of the job
+func (j *Job) ResolveSecretEnvs(ctx context.Context, clientset kubernetes.Interface) error {
+	// the steps inherit the environment of the job executor, the vault token must not leak to the scripts
+	vaultToken := os.Getenv(setting.ENVSecretVaultToken)
+	os.Unsetenv(setting.ENVSecretVaultToken)
+	delete(j.UserEnvs, setting.ENVSecretVaultToken)
+	if j.Ctx.SecretProviders == nil {
+		return nil
+	}
+	resolver := j.Ctx.SecretProviders.NewResolver(vaultToken, clientset)
+	secretEnvs, err := resolver.ResolveEnvs(ctx, j.Ctx.SecretEnvs)
+	if err != nil {
+		return err
+	}
+	j.Ctx.SecretEnvs = secretEnvs
+	for _, env := range secretEnvs {
+		if key, value, found := strings.Cut(env, "="); found {
+			j.UserEnvs[key] = value
+		}
+	}
+	return nil
+}
+
 func (j *Job) EnsureActiveWorkspace(workspace string) error {
 	if workspace == "" {
 		tempWorkspace, err := ioutil.TempDir(os.TempDir(), "jobexecutor")
@@ -140,16 +167,12 @@ func (j *Job) Run(ctx context.Context) error {
 	if err := os.MkdirAll(job.JobOutputDir, os.ModePerm); err != nil {
 		return err
 	}
-	hasFailed := false
 	var respErr error
 	for _, stepInfo := range j.Ctx.Steps {
-		if hasFailed && !stepInfo.Onfailure {
+		if respErr != nil && !stepInfo.Onfailure {
 			continue
 		}
-		if err := step.RunStep(ctx, stepInfo, j.ActiveWorkspace, j.Ctx.Paths, j.getUserEnvs(), j.Ctx.SecretEnvs, j.ConfigMapUpdater); err != nil {
-			hasFailed = true
-			respErr = err
-		}
+		respErr = reapermeta.MergeStepError(respErr, step.RunStep(ctx, stepInfo, j.ActiveWorkspace, j.Ctx.Paths, j.getUserEnvs(), j.Ctx.SecretEnvs, j.ConfigMapUpdater))
 	}
 	return respErr
 }
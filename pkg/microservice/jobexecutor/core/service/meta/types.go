@@ -16,6 +16,8 @@ limitations under the License.
 
 package meta
 
+import "github.com/koderover/zadig/pkg/tool/secret"
+
 type JobContext struct {
 	Name string `yaml:"name"`
 	// Workspace 容器工作目录 [必填]
@@ -35,6 +37,8 @@ type JobContext struct {
 
 	Steps   []*Step  `yaml:"steps"`
 	Outputs []string `yaml:"outputs"`
+	// SecretProviders resolves the secret references in SecretEnvs, it is nil if there is no reference
+	SecretProviders *secret.Config `yaml:"secret_providers,omitempty"`
 }
 
 type Step struct {
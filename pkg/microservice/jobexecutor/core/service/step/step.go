@@ -81,6 +81,16 @@ func RunStep(ctx context.Context, step *meta.Step, workspace, paths string, envs
 		if err != nil {
 			return err
 		}
+	case "sarif_report":
+		stepInstance, err = NewSarifReportStep(step.Spec, workspace, envs, secretEnvs)
+		if err != nil {
+			return err
+		}
+	case "coverage_report":
+		stepInstance, err = NewCoverageReportStep(step.Spec, workspace, envs, secretEnvs)
+		if err != nil {
+			return err
+		}
 	case "distribute_image":
 		stepInstance, err = NewDistributeImageStep(step.Spec, workspace, envs, secretEnvs)
 		if err != nil {
@@ -169,7 +179,7 @@ func maskSecretEnvs(message string, secretEnvs []string) string {
 		if len(val) == 0 {
 			continue
 		}
-		sl := strings.Split(val, "=")
+		sl := strings.SplitN(val, "=", 2)
 
 		if len(sl) != 2 {
 			continue
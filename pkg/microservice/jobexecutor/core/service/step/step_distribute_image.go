@@ -93,6 +93,7 @@ func copyImage(target *step.DistributeTaskTarget, client *regclient.RegClient) e
 		errMsg := fmt.Sprintf("parse target image: %s error: %v", target.TargetImage, err)
 		return errors.New(errMsg)
 	}
+	// without platform options ImageCopy copies the whole manifest list of a multi-platform image
 	if err := client.ImageCopy(context.Background(), sourceRef, targetRef); err != nil {
 		errMsg := fmt.Sprintf("copy image failed: %v", err)
 		return errors.New(errMsg)
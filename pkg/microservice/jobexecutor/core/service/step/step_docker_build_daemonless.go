@@ -0,0 +1,328 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/types"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+const (
+	kanikoExe       = "/kaniko/executor"
+	dockerConfigEnv = "DOCKER_CONFIG"
+	// dockerHubAuthKey is the key of the docker hub credentials in the docker config, which is not the registry host
+	dockerHubAuthKey = "https://index.docker.io/v1/"
+
+	// the builder containers are checked by the time they take to start, so that a job pod without them fails fast
+	builderStartTimeout  = 2 * time.Minute
+	builderCheckInterval = time.Second
+)
+
+// runDaemonlessBuild builds and pushes the image with the builder container of the job pod
+func (s *DockerBuildStep) runDaemonlessBuild(ctx context.Context) error {
+	buildArgs, ignored := daemonlessBuildArgs(s.spec.BuildArgs)
+	if len(ignored) > 0 {
+		log.Warningf("Build args other than --build-arg are not supported by %s and are ignored: %s", s.spec.GetBuilder(), strings.Join(ignored, " "))
+	}
+	workDir := s.spec.WorkDir
+	if workDir == "" {
+		workDir = "."
+	}
+	buildCtx := absPath(s.workspace, workDir)
+	dockerfile := absPath(s.workspace, s.spec.GetDockerFile())
+
+	switch s.spec.GetBuilder() {
+	case step.DockerBuilderBuildKit:
+		return s.runBuildKit(ctx, dockerfile, buildCtx, buildArgs)
+	case step.DockerBuilderKaniko:
+		return s.runKaniko(ctx, dockerfile, buildCtx, buildArgs)
+	default:
+		return fmt.Errorf("unsupported docker builder: %s", s.spec.GetBuilder())
+	}
+}
+
+// runBuildKit starts buildkitd with the registries of the build and runs buildctl in the job container,
+// buildctl sends the build context and the registry credentials of the job container to buildkitd.
+func (s *DockerBuildStep) runBuildKit(ctx context.Context, dockerfile, buildCtx string, buildArgs []string) error {
+	configPath, err := writeBuildkitdConfig(step.DaemonlessBuilderDir, s.spec.Registries)
+	if err != nil {
+		return fmt.Errorf("failed to write buildkitd config: %s", err)
+	}
+	if configPath == "" {
+		if err := os.WriteFile(step.BuildKitdConfigFile, []byte{}, 0644); err != nil {
+			return fmt.Errorf("failed to write buildkitd config: %s", err)
+		}
+	}
+	if err := waitBuildkitd(ctx); err != nil {
+		return err
+	}
+
+	cmd := buildkitBuildCmd(ctx, dockerfile, s.spec.ImageName, buildCtx, buildArgs, s.spec.IgnoreCache, s.spec.Platforms, s.spec.Cache)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Dir = s.workspace
+	cmd.Env = append(append([]string{}, s.envs...), fmt.Sprintf("%s=%s", dockerConfigEnv, step.DaemonlessDockerConfigDir))
+	return cmd.Run()
+}
+
+// runKaniko writes the build script for the kaniko container and waits for its exit code, the output of
+// kaniko is copied to the job log.
+func (s *DockerBuildStep) runKaniko(ctx context.Context, dockerfile, buildCtx string, buildArgs []string) error {
+	// the kaniko container only shares the workspace and the builder directory with the job container
+	if !isSubPath(s.workspace, dockerfile) {
+		content, err := os.ReadFile(dockerfile)
+		if err != nil {
+			return fmt.Errorf("failed to read dockerfile: %s", err)
+		}
+		dockerfile = filepath.Join(step.DaemonlessBuilderDir, "Dockerfile")
+		if err := os.WriteFile(dockerfile, content, 0644); err != nil {
+			return fmt.Errorf("failed to write dockerfile: %s", err)
+		}
+	}
+	certDir := filepath.Join(step.DaemonlessBuilderDir, "certs.d")
+	if err := writeRegistryCerts(certDir, s.spec.Registries); err != nil {
+		return fmt.Errorf("failed to write registry certificates: %s", err)
+	}
+	args, err := kanikoArgs(dockerfile, s.spec.ImageName, buildCtx, buildArgs, s.spec.IgnoreCache, s.spec.Platforms, s.spec.Cache, s.spec.Registries, certDir)
+	if err != nil {
+		return err
+	}
+
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return runKanikoScript(ctx, fmt.Sprintf("exec %s %s\n", kanikoExe, strings.Join(quoted, " ")), os.Stdout)
+}
+
+func waitBuildkitd(ctx context.Context) error {
+	deadline := time.Now().Add(builderStartTimeout)
+	for {
+		if err := exec.CommandContext(ctx, step.BuildCtlFile, "--addr", step.BuildKitAddr, "debug", "workers").Run(); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("buildkitd is not ready in %s, the job pod has no running buildkitd container", builderStartTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(builderCheckInterval):
+		}
+	}
+}
+
+func runKanikoScript(ctx context.Context, script string, out io.Writer) error {
+	for _, file := range []string{step.KanikoLogFile, step.KanikoExitCodeFile} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// the script is renamed into place so that the kaniko container never runs a partial one
+	if err := os.WriteFile(step.KanikoScriptFile+".tmp", []byte(script), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(step.KanikoScriptFile+".tmp", step.KanikoScriptFile); err != nil {
+		return err
+	}
+
+	var logFile *os.File
+	defer func() {
+		if logFile != nil {
+			logFile.Close()
+		}
+	}()
+	deadline := time.Now().Add(builderStartTimeout)
+	for {
+		if logFile == nil {
+			logFile, _ = os.Open(step.KanikoLogFile)
+		}
+		// read the exit code before the log, so that all the output is copied once it exists
+		exitCode, err := os.ReadFile(step.KanikoExitCodeFile)
+		if logFile != nil {
+			if _, err := io.Copy(out, logFile); err != nil {
+				return err
+			}
+		}
+		if err == nil {
+			if code := strings.TrimSpace(string(exitCode)); code != "0" {
+				return fmt.Errorf("kaniko exited with code %s", code)
+			}
+			return nil
+		}
+		if _, err := os.Stat(step.KanikoScriptFile); err == nil && time.Now().After(deadline) {
+			return fmt.Errorf("the build is not started in %s, the job pod has no running kaniko container", builderStartTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(builderCheckInterval):
+		}
+	}
+}
+
+func buildkitBuildCmd(ctx context.Context, dockerfile, fullImage, buildCtx string, buildArgs []string, ignoreCache bool, platforms []string, cache *types.BuildKitCache) *exec.Cmd {
+	args := []string{
+		"--addr", step.BuildKitAddr,
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + buildCtx,
+		"--local", "dockerfile=" + filepath.Dir(dockerfile),
+		"--opt", "filename=" + filepath.Base(dockerfile),
+		"--output", fmt.Sprintf("type=image,name=%s,push=true", fullImage),
+	}
+	for _, arg := range buildArgs {
+		args = append(args, "--opt", "build-arg:"+arg)
+	}
+	if len(platforms) > 0 {
+		args = append(args, "--opt", "platform="+strings.Join(platforms, ","))
+	}
+	if ignoreCache {
+		args = append(args, "--no-cache")
+	}
+	if cache != nil {
+		if !ignoreCache {
+			args = append(args, "--import-cache", cache.CacheFrom())
+		}
+		args = append(args, "--export-cache", cache.CacheTo())
+	}
+	return exec.CommandContext(ctx, step.BuildCtlFile, args...)
+}
+
+// kanikoArgs returns the arguments of the kaniko executor, the registries without TLS are insecure
+// and the certificates of the registries with TLS are read from certDir.
+func kanikoArgs(dockerfile, fullImage, buildCtx string, buildArgs []string, ignoreCache bool, platforms []string, cache *types.BuildKitCache, registries []*step.RegistryTLS, certDir string) ([]string, error) {
+	if len(platforms) > 1 {
+		return nil, fmt.Errorf("kaniko can not build an image for multiple platforms: %s", strings.Join(platforms, ","))
+	}
+	args := []string{
+		"--context", "dir://" + buildCtx,
+		"--dockerfile", dockerfile,
+		"--destination", fullImage,
+		// the kaniko container runs every build of the job
+		"--cleanup",
+	}
+	for _, arg := range buildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	if len(platforms) == 1 {
+		args = append(args, "--custom-platform", platforms[0])
+	}
+	if cache != nil && !ignoreCache {
+		if cache.Type != types.BuildKitRegistryCache {
+			return nil, fmt.Errorf("kaniko only supports registry build cache")
+		}
+		args = append(args, "--cache=true", "--cache-repo", cache.Ref)
+	}
+	for _, reg := range registries {
+		switch {
+		case !reg.TLSEnabled:
+			args = append(args, "--insecure-registry", reg.Host(), "--skip-tls-verify-registry", reg.Host())
+		case strings.HasPrefix(reg.RegAddr, "http://"):
+			args = append(args, "--insecure-registry", reg.Host())
+		case reg.TLSCert != "":
+			args = append(args, "--registry-certificate", fmt.Sprintf("%s=%s", reg.Host(), reg.CertPath(certDir)))
+		}
+	}
+	return args, nil
+}
+
+// daemonlessBuildArgs picks the KEY=VALUE pairs of the --build-arg flags out of the docker build args,
+// the other flags only make sense to the docker daemon and are returned as ignored.
+func daemonlessBuildArgs(buildArgs string) ([]string, []string) {
+	args, ignored := make([]string, 0), make([]string, 0)
+	fields := strings.Fields(buildArgs)
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "--build-arg" && i+1 < len(fields):
+			args = append(args, fields[i+1])
+			i++
+		case strings.HasPrefix(fields[i], "--build-arg="):
+			args = append(args, strings.TrimPrefix(fields[i], "--build-arg="))
+		default:
+			ignored = append(ignored, fields[i])
+		}
+	}
+	return args, ignored
+}
+
+// writeDockerConfig adds the registry credentials to the docker config file in configDir, which is read by
+// buildctl and kaniko instead of docker login.
+func writeDockerConfig(configDir, host, user, password string) error {
+	configPath := filepath.Join(configDir, "config.json")
+	dockerConfig := map[string]interface{}{}
+	if content, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(content, &dockerConfig); err != nil {
+			return fmt.Errorf("failed to parse docker config %s: %s", configPath, err)
+		}
+	}
+	auths, ok := dockerConfig["auths"].(map[string]interface{})
+	if !ok {
+		auths = map[string]interface{}{}
+	}
+	auths[dockerAuthKey(host)] = map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(user + ":" + password)),
+	}
+	dockerConfig["auths"] = auths
+
+	content, err := json.Marshal(dockerConfig)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, content, 0600)
+}
+
+// dockerAuthKey returns the key of the registry in the auths of the docker config
+func dockerAuthKey(host string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://"), "/")
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io", "index.docker.io/v1":
+		return dockerHubAuthKey
+	}
+	return host
+}
+
+func absPath(workspace, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(workspace, path)
+}
+
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
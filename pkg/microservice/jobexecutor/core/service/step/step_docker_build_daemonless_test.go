@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/koderover/zadig/pkg/types"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+func TestDaemonlessBuildArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		buildArgs string
+		args      []string
+		ignored   []string
+	}{
+		{
+			name:    "empty",
+			args:    []string{},
+			ignored: []string{},
+		},
+		{
+			name:      "both forms of build args",
+			buildArgs: "--build-arg GO_VERSION=1.20  --build-arg=ARCH=arm64",
+			args:      []string{"GO_VERSION=1.20", "ARCH=arm64"},
+			ignored:   []string{},
+		},
+		{
+			name:      "daemon flags are ignored",
+			buildArgs: "--network host --build-arg A=1 --squash --build-arg",
+			args:      []string{"A=1"},
+			ignored:   []string{"--network", "host", "--squash", "--build-arg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, ignored := daemonlessBuildArgs(tt.buildArgs)
+			assert.Equal(t, tt.args, args)
+			assert.Equal(t, tt.ignored, ignored)
+		})
+	}
+}
+
+func TestWriteDockerConfig(t *testing.T) {
+	ast := require.New(t)
+	configDir := filepath.Join(t.TempDir(), ".docker")
+
+	readAuths := func() map[string]map[string]string {
+		content, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+		ast.NoError(err)
+		dockerConfig := struct {
+			Auths map[string]map[string]string `json:"auths"`
+			Proxy string                       `json:"proxy"`
+		}{}
+		ast.NoError(json.Unmarshal(content, &dockerConfig))
+		return dockerConfig.Auths
+	}
+	auth := func(user, password string) map[string]string {
+		return map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte(user + ":" + password))}
+	}
+
+	ast.NoError(writeDockerConfig(configDir, "https://harbor.koderover.io/", "admin", "pass:word"))
+	ast.Equal(map[string]map[string]string{"harbor.koderover.io": auth("admin", "pass:word")}, readAuths())
+
+	// docker hub credentials are saved with the key docker looks up, which is not the registry host
+	for _, host := range []string{"docker.io", "https://index.docker.io/v1/", ""} {
+		ast.NoError(writeDockerConfig(configDir, host, "koderover", host))
+		ast.Equal(map[string]map[string]string{
+			"harbor.koderover.io": auth("admin", "pass:word"),
+			dockerHubAuthKey:      auth("koderover", host),
+		}, readAuths())
+	}
+
+	// the other settings in the config are kept
+	ast.NoError(os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"proxy":"http://proxy"}`), 0600))
+	ast.NoError(writeDockerConfig(configDir, "http://insecure.koderover.io:5000", "admin", "password"))
+	ast.Equal(map[string]map[string]string{"insecure.koderover.io:5000": auth("admin", "password")}, readAuths())
+	content, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	ast.NoError(err)
+	ast.Contains(string(content), `"proxy":"http://proxy"`)
+
+	ast.NoError(os.WriteFile(filepath.Join(configDir, "config.json"), []byte("{"), 0600))
+	ast.Error(writeDockerConfig(configDir, "harbor.koderover.io", "admin", "password"))
+}
+
+func TestKanikoArgs(t *testing.T) {
+	registries := []*step.RegistryTLS{
+		{RegAddr: "https://registry.koderover.io", TLSEnabled: true},
+		{RegAddr: "https://insecure.koderover.io"},
+		{RegAddr: "http://http.koderover.io", TLSEnabled: true},
+		{RegAddr: "https://harbor.koderover.io", TLSEnabled: true, TLSCert: "cert"},
+	}
+	args, err := kanikoArgs("/workspace/Dockerfile", "harbor.koderover.io/zadig/aslan:v1", "/workspace/app", []string{"A=1"}, false,
+		[]string{"linux/arm64"}, &types.BuildKitCache{Type: types.BuildKitRegistryCache, Ref: "harbor.koderover.io/zadig/cache"}, registries, "/zadig/builder/certs.d")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--context", "dir:///workspace/app",
+		"--dockerfile", "/workspace/Dockerfile",
+		"--destination", "harbor.koderover.io/zadig/aslan:v1",
+		"--cleanup",
+		"--build-arg", "A=1",
+		"--custom-platform", "linux/arm64",
+		"--cache=true", "--cache-repo", "harbor.koderover.io/zadig/cache",
+		"--insecure-registry", "insecure.koderover.io", "--skip-tls-verify-registry", "insecure.koderover.io",
+		"--insecure-registry", "http.koderover.io",
+		"--registry-certificate", "harbor.koderover.io=/zadig/builder/certs.d/harbor.koderover.io/ca.crt",
+	}, args)
+
+	_, err = kanikoArgs("Dockerfile", "image", ".", nil, false, []string{"linux/amd64", "linux/arm64"}, nil, nil, "")
+	assert.Error(t, err)
+	_, err = kanikoArgs("Dockerfile", "image", ".", nil, false, nil, &types.BuildKitCache{Type: types.BuildKitLocalCache, Path: "/cache"}, nil, "")
+	assert.Error(t, err)
+	// the cache is not used at all if it is ignored
+	_, err = kanikoArgs("Dockerfile", "image", ".", nil, true, nil, &types.BuildKitCache{Type: types.BuildKitLocalCache, Path: "/cache"}, nil, "")
+	assert.NoError(t, err)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'--build-arg'`, shellQuote("--build-arg"))
+	assert.Equal(t, `'A=it'\''s $HOME'`, shellQuote("A=it's $HOME"))
+}
@@ -38,8 +38,7 @@ import (
 )
 
 const (
-	ReploaceTestSuite  = "TestSuite"
-	ReploaceTestSuites = "TestSuites"
+	ReploaceTestSuite = "TestSuite"
 )
 
 type JunitReportStep struct {
@@ -71,7 +70,7 @@ func (s *JunitReportStep) Run(ctx context.Context) error {
 	s.spec.ReportDir = replaceEnvWithValue(s.spec.ReportDir, envMap)
 
 	reportDir := filepath.Join(s.workspace, s.spec.ReportDir)
-	failedCaseCount, err := mergeGinkgoTestResults(s.spec.FileName, reportDir, s.spec.DestDir, time.Now())
+	failedCaseCount, quarantinedCaseCount, err := mergeGinkgoTestResults(s.spec.FileName, reportDir, s.spec.DestDir, s.spec.Quarantined, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to merge test result: %s", err)
 	}
@@ -114,29 +113,23 @@ func (s *JunitReportStep) Run(ctx context.Context) error {
 		}
 	}
 	log.Infof("Finish archive %s.", s.spec.FileName)
-	if failedCaseCount > 0 {
-		return fmt.Errorf("%d case(s) failed", failedCaseCount)
-	}
-	return nil
+	return meta.FailedCasesError(failedCaseCount, quarantinedCaseCount)
 }
 
-func mergeGinkgoTestResults(testResultFile, testResultPath, testUploadPath string, startTime time.Time) (int, error) {
+func mergeGinkgoTestResults(testResultFile, testResultPath, testUploadPath string, quarantined []string, startTime time.Time) (int, int, error) {
 	var (
-		err           error
-		newXMLBytes   []byte
-		summaryResult = &meta.TestSuite{
-			TestCases: []meta.TestCase{},
-		}
-		failedCaseCount int
+		err         error
+		newXMLBytes []byte
+		testCases   = []meta.TestCase{}
 	)
 
 	if len(testResultPath) == 0 {
-		return failedCaseCount, nil
+		return 0, 0, nil
 	}
 
 	files, err := ioutil.ReadDir(testResultPath)
 	if err != nil || len(files) == 0 {
-		return failedCaseCount, fmt.Errorf("test result files not found in path %s", testResultPath)
+		return 0, 0, fmt.Errorf("test result files not found in path %s", testResultPath)
 	}
 
 	// sort and process xml files by modified time
@@ -155,61 +148,27 @@ func mergeGinkgoTestResults(testResultFile, testResultPath, testUploadPath strin
 				continue
 			}
 
-			// 2. xml unmarshal
-			xmlContent := string(xmlBytes)
-			if strings.Contains(xmlContent, ReploaceTestSuites) || strings.Contains(xmlContent, strings.ToLower(ReploaceTestSuites)) {
-				var results *meta.TestSuites
-				err2 = xml.Unmarshal(xmlBytes, &results)
-				if err2 != nil {
-					log.Warningf("Unmarshal xml file [%s], error: %v\n", filePath, err2)
-					continue
-				}
-				for _, testSuite := range results.TestSuites {
-					summaryResult.Tests += testSuite.Tests
-					summaryResult.Failures += testSuite.Failures
-					summaryResult.Errors += testSuite.Errors
-
-					for _, tc := range testSuite.TestCases {
-						if tc.Skipped != nil {
-							summaryResult.Skips++
-						}
-					}
-					summaryResult.TestCases = append(summaryResult.TestCases, testSuite.TestCases...)
-				}
-				summaryResult.SuiteType = ReploaceTestSuites
-			} else {
-				var result *meta.TestSuite
-				err2 = xml.Unmarshal(xmlBytes, &result)
-				if err2 != nil {
-					log.Warningf("Unmarshal xml file [%s], error: %v\n", filePath, err2)
-					continue
-				}
-				// 4. process summary result attribute
-				summaryResult.Tests += result.Tests
-				summaryResult.Failures += result.Failures
-				summaryResult.Errors += result.Errors
-
-				for _, tc := range result.TestCases {
-					if tc.Skipped != nil {
-						summaryResult.Skips++
-					}
-				}
-				summaryResult.TestCases = append(summaryResult.TestCases, result.TestCases...)
-				summaryResult.SuiteType = ReploaceTestSuite
+			// 2. collect the test cases of any nested test suites
+			cases, err2 := meta.ParseJUnitCases(xmlBytes)
+			if err2 != nil {
+				log.Warningf("Unmarshal xml file [%s], error: %v\n", filePath, err2)
+				continue
 			}
+			testCases = append(testCases, cases...)
 		}
 	}
+	summaryResult := meta.NewTestSuite(testCases)
 	summaryResult.Time = getSecondSince(startTime)
-	if summaryResult.SuiteType == ReploaceTestSuite {
-		summaryResult.Successes = summaryResult.Tests - summaryResult.Failures - summaryResult.Errors
-		summaryResult.Tests = summaryResult.Tests + summaryResult.Skips
-	} else {
-		summaryResult.Successes = summaryResult.Tests - summaryResult.Failures - summaryResult.Errors - summaryResult.Skips
+
+	failedCases, quarantinedCases := meta.SplitFailedCases(testCases, quarantined)
+	for _, tc := range quarantinedCases {
+		log.Warningf("quarantined case %s %s, ignored", tc.Key(), tc.Status())
 	}
+
 	// 1. xml marshal indent
 	newXMLBytes, err = xml.MarshalIndent(summaryResult, "  ", "    ")
 	if err != nil {
-		return failedCaseCount, err
+		return 0, 0, err
 	}
 	// 2. append header
 	newXMLBytes = append([]byte(xml.Header), newXMLBytes...)
@@ -220,11 +179,11 @@ func mergeGinkgoTestResults(testResultFile, testResultPath, testUploadPath strin
 	//4. write xml bytes into file
 	err = ioutil.WriteFile(path.Join(testUploadPath, testResultFile), []byte(newXMLStr), 0644)
 	if err != nil {
-		return failedCaseCount, err
+		return 0, 0, err
 	}
 
 	log.Infof("merge test results files %s succeeded", testResultFile)
-	return summaryResult.Failures, nil
+	return len(failedCases), len(quarantinedCases), nil
 }
 
 func getSecondSince(startTime time.Time) float64 {
@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/tool/log"
+	"github.com/koderover/zadig/pkg/tool/s3"
+	"github.com/koderover/zadig/pkg/tool/sarif"
+	"github.com/koderover/zadig/pkg/types/step"
+)
+
+type SarifReportStep struct {
+	spec       *step.StepSarifReportSpec
+	envs       []string
+	secretEnvs []string
+	workspace  string
+}
+
+func NewSarifReportStep(spec interface{}, workspace string, envs, secretEnvs []string) (*SarifReportStep, error) {
+	sarifReportStep := &SarifReportStep{workspace: workspace, envs: envs, secretEnvs: secretEnvs}
+	yamlBytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return sarifReportStep, fmt.Errorf("marshal spec %+v failed", spec)
+	}
+	if err := yaml.Unmarshal(yamlBytes, &sarifReportStep.spec); err != nil {
+		return sarifReportStep, fmt.Errorf("unmarshal spec %s to sarif report spec failed", yamlBytes)
+	}
+	return sarifReportStep, nil
+}
+
+func (s *SarifReportStep) Run(ctx context.Context) error {
+	log.Info("Start parse sarif reports.")
+	envMap := makeEnvMap(s.envs, s.secretEnvs)
+	s.spec.ReportDir = replaceEnvWithValue(s.spec.ReportDir, envMap)
+
+	findings, files, err := sarif.Collect(filepath.Join(s.workspace, s.spec.ReportDir))
+	if err != nil {
+		return fmt.Errorf("failed to collect sarif reports: %s", err)
+	}
+	log.Infof("Parsed %d finding(s) from %d sarif file(s).", len(findings), len(files))
+
+	var client *s3.Client
+	if s.spec.S3Storage != nil {
+		forcedPathStyle := true
+		if s.spec.S3Storage.Provider == setting.ProviderSourceAli {
+			forcedPathStyle = false
+		}
+		client, err = s3.NewClient(s.spec.S3Storage.Endpoint, s.spec.S3Storage.Ak, s.spec.S3Storage.Sk, s.spec.S3Storage.Region, s.spec.S3Storage.Insecure, forcedPathStyle)
+		if err != nil {
+			return fmt.Errorf("failed to create s3 client, err: %s", err)
+		}
+	}
+
+	var baseline *sarif.Report
+	if client != nil && s.spec.BaselinePath != "" {
+		baseline, err = s.downloadBaseline(client)
+		if err != nil {
+			// findings are reported as new instead of failing the step when the baseline is gone
+			log.Warningf("failed to download the baseline report %s: %s", s.spec.BaselinePath, err)
+		}
+	}
+	report := sarif.NewReport(findings, baseline)
+	for _, severity := range sarif.Severities {
+		log.Infof("%s: %d, new: %d", severity, report.Summary[severity], report.NewSummary[severity])
+	}
+
+	if err := os.MkdirAll(s.spec.DestDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create dest dir: %s error: %s", s.spec.DestDir, err)
+	}
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sarif report: %s", err)
+	}
+	absFilePath := filepath.Join(s.spec.DestDir, s.spec.FileName)
+	if err := os.WriteFile(absFilePath, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write sarif report: %s", err)
+	}
+
+	if client != nil && s.spec.S3DestDir != "" && s.spec.FileName != "" {
+		log.Infof("Start archive %s.", s.spec.FileName)
+		key := path.Join(s.spec.S3DestDir, s.spec.FileName)
+		if len(s.spec.S3Storage.Subfolder) > 0 {
+			key = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, key), "/")
+		}
+		if err := client.Upload(s.spec.S3Storage.Bucket, absFilePath, key); err != nil {
+			return err
+		}
+		log.Infof("Finish archive %s.", s.spec.FileName)
+	}
+	log.Info("Finish parse sarif reports.")
+
+	if s.spec.SeverityThreshold == "" {
+		return nil
+	}
+	exceeding := report.Exceeding(s.spec.SeverityThreshold, s.spec.GateNewOnly && report.HasBaseline)
+	if len(exceeding) > 0 {
+		for _, finding := range exceeding {
+			log.Errorf("[%s] %s %s:%d %s", finding.Severity, finding.RuleID, finding.File, finding.Line, finding.Message)
+		}
+		return fmt.Errorf("%d finding(s) at or above severity %s", len(exceeding), s.spec.SeverityThreshold)
+	}
+	return nil
+}
+
+func (s *SarifReportStep) downloadBaseline(client *s3.Client) (*sarif.Report, error) {
+	key := s.spec.BaselinePath
+	if len(s.spec.S3Storage.Subfolder) > 0 {
+		key = strings.TrimLeft(path.Join(s.spec.S3Storage.Subfolder, key), "/")
+	}
+	tmpFile, err := os.CreateTemp("", "sarif-baseline-")
+	if err != nil {
+		return nil, err
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	if err := client.Download(s.spec.S3Storage.Bucket, key, tmpFile.Name()); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	baseline := &sarif.Report{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
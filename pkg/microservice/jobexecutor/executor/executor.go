@@ -100,6 +100,9 @@ func Execute(ctx context.Context) error {
 	}()
 
 	fmt.Printf("====================== %s Start ======================\n", excutor)
+	if err = j.ResolveSecretEnvs(ctx, clientset); err != nil {
+		return err
+	}
 	if err = j.Run(ctx); err != nil {
 		return err
 	}
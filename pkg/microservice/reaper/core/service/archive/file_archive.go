@@ -42,13 +42,15 @@ type WorkspaceAchiever struct {
 	PipelineName string
 	ServiceName  string
 	Envs         []string
+	aesKeyID     string
 	aesKey       string
 }
 
-func NewWorkspaceAchiever(storageURI, pipelineName, serviceName, wd, aesKey string, caches, gitFolders, envs []string) *WorkspaceAchiever {
+func NewWorkspaceAchiever(storageURI, pipelineName, serviceName, wd, aesKeyID, aesKey string, caches, gitFolders, envs []string) *WorkspaceAchiever {
 	return &WorkspaceAchiever{
 		paths:        caches,
 		wd:           wd,
+		aesKeyID:     aesKeyID,
 		aesKey:       aesKey,
 		gitFolders:   gitFolders,
 		StorageURI:   storageURI,
@@ -226,7 +228,7 @@ func (c *WorkspaceAchiever) Achieve(target string) ([]string, error) {
 	//	return err
 	//}
 
-	if store, err := s3.UnmarshalNewS3StorageFromEncrypted(c.StorageURI, c.aesKey); err == nil {
+	if store, err := s3.UnmarshalNewS3StorageFromEncrypted(c.StorageURI, c.aesKeyID, c.aesKey); err == nil {
 		forcedPathStyle := true
 		if store.Provider == setting.ProviderSourceAli {
 			forcedPathStyle = false
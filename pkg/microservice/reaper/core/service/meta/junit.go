@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	TestCaseStatusPassed  = "passed"
+	TestCaseStatusFailed  = "failed"
+	TestCaseStatusError   = "error"
+	TestCaseStatusSkipped = "skipped"
+)
+
+// ErrQuarantinedFailures is returned by the junit report step when cases failed and all of them are quarantined
+var ErrQuarantinedFailures = errors.New("all the failed cases are quarantined")
+
+// ParseJUnitCases returns all the test cases in a junit xml report, the test suites can be
+// nested in any depth so that the reports of tools other than ginkgo are supported.
+func ParseJUnitCases(data []byte) ([]TestCase, error) {
+	cases := make([]TestCase, 0)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "testcase" {
+			continue
+		}
+		tc := TestCase{}
+		if err := decoder.DecodeElement(&tc, &element); err != nil {
+			return nil, err
+		}
+		cases = append(cases, tc)
+	}
+	return cases, nil
+}
+
+// Key identifies the test case across runs
+func (tc *TestCase) Key() string {
+	if tc.ClassName == "" {
+		return tc.Name
+	}
+	return tc.ClassName + "." + tc.Name
+}
+
+// IsQuarantined returns whether the test case is in the quarantine list, the list
+// can have either the key or the name of the test case.
+func (tc *TestCase) IsQuarantined(quarantined []string) bool {
+	for _, name := range quarantined {
+		if name == tc.Key() || name == tc.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the result of the test case
+func (tc *TestCase) Status() string {
+	switch {
+	case tc.Error != nil:
+		return TestCaseStatusError
+	case tc.Failure != nil:
+		return TestCaseStatusFailed
+	case tc.Skipped != nil:
+		return TestCaseStatusSkipped
+	default:
+		return TestCaseStatusPassed
+	}
+}
+
+// NewTestSuite merges the test cases into one test suite, the totals are counted from the
+// test cases since not every junit reporter fills the attributes of the test suites.
+// Tests is always len(cases) and every case is counted in exactly one of Errors, Failures,
+// Skips and Successes, a case with both an error and a failure is counted as an error.
+func NewTestSuite(cases []TestCase) *TestSuite {
+	suite := &TestSuite{
+		TestCases: cases,
+		Tests:     len(cases),
+	}
+	for _, tc := range cases {
+		switch tc.Status() {
+		case TestCaseStatusError:
+			suite.Errors++
+		case TestCaseStatusFailed:
+			suite.Failures++
+		case TestCaseStatusSkipped:
+			suite.Skips++
+		}
+	}
+	suite.Successes = suite.Tests - suite.Failures - suite.Errors - suite.Skips
+	return suite
+}
+
+// SplitFailedCases returns the failed test cases which are not quarantined and those which are.
+// Only the cases counted in the Failures of the merged test suite fail the step, errored cases don't.
+func SplitFailedCases(cases []TestCase, quarantined []string) ([]TestCase, []TestCase) {
+	failed, quarantinedFailed := make([]TestCase, 0), make([]TestCase, 0)
+	for _, tc := range cases {
+		if tc.Status() != TestCaseStatusFailed {
+			continue
+		}
+		if tc.IsQuarantined(quarantined) {
+			quarantinedFailed = append(quarantinedFailed, tc)
+		} else {
+			failed = append(failed, tc)
+		}
+	}
+	return failed, quarantinedFailed
+}
+
+// FailedCasesError returns the result of the junit report step for the counts of the failed cases
+// which are not quarantined and those which are.
+func FailedCasesError(failed, quarantined int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d case(s) failed", failed)
+	}
+	if quarantined > 0 {
+		return ErrQuarantinedFailures
+	}
+	return nil
+}
+
+// MergeStepError returns the error of the job after a step returns err, respErr is the error of the job
+// before the step. The test script exits with an error when any case fails, so a junit report step whose
+// failed cases are all quarantined clears the error of the job.
+func MergeStepError(respErr, err error) error {
+	if errors.Is(err, ErrQuarantinedFailures) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return respErr
+}
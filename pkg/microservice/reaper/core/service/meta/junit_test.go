@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnitCases(t *testing.T) {
+	ast := require.New(t)
+
+	// phpunit style nested test suites without the failures attribute
+	report := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="all">
+    <testsuite name="UserTest">
+      <testcase name="testLogin" classname="UserTest" time="0.5"/>
+      <testcase name="testLogout" classname="UserTest" time="0.1">
+        <failure message="expected true" type="assert">trace</failure>
+      </testcase>
+    </testsuite>
+    <testcase name="testSkip" classname="OrderTest"><skipped/></testcase>
+    <testcase name="testPanic" classname="OrderTest"><error message="panic"/></testcase>
+  </testsuite>
+</testsuites>`
+
+	cases, err := ParseJUnitCases([]byte(report))
+	ast.Nil(err)
+	ast.Len(cases, 4)
+	ast.Equal("UserTest.testLogin", cases[0].Key())
+	ast.Equal(0.5, cases[0].Time)
+	ast.NotNil(cases[1].Failure)
+	ast.Equal("expected true", cases[1].Failure.Message)
+	ast.NotNil(cases[2].Skipped)
+	ast.NotNil(cases[3].Error)
+
+	ast.True(cases[1].IsQuarantined([]string{"UserTest.testLogout"}))
+	ast.True(cases[1].IsQuarantined([]string{"testLogout"}))
+	ast.False(cases[1].IsQuarantined([]string{"UserTest.testLogin"}))
+
+	suite := NewTestSuite(cases)
+	ast.Equal(4, suite.Tests)
+	ast.Equal(1, suite.Failures)
+	ast.Equal(1, suite.Errors)
+	ast.Equal(1, suite.Skips)
+	ast.Equal(1, suite.Successes)
+
+	failed, quarantined := SplitFailedCases(cases, []string{"testLogout"})
+	ast.Len(failed, 0)
+	ast.Len(quarantined, 1)
+	ast.Equal("testLogout", quarantined[0].Name)
+
+	_, err = ParseJUnitCases([]byte("<testsuite><testcase"))
+	ast.NotNil(err)
+}
+
+func TestNewTestSuite(t *testing.T) {
+	tests := []struct {
+		name      string
+		cases     []TestCase
+		tests     int
+		failures  int
+		errors    int
+		skips     int
+		successes int
+	}{
+		{
+			name:  "no cases",
+			cases: []TestCase{},
+		},
+		{
+			name: "every case counted once",
+			cases: []TestCase{
+				{Name: "pass"},
+				{Name: "fail", Failure: &Failure{}},
+				{Name: "error", Error: &Error{}},
+				{Name: "skip", Skipped: &Skipped{}},
+			},
+			tests: 4, failures: 1, errors: 1, skips: 1, successes: 1,
+		},
+		{
+			name: "error takes precedence over failure",
+			cases: []TestCase{
+				{Name: "both", Failure: &Failure{}, Error: &Error{}},
+				{Name: "failed and skipped", Failure: &Failure{}, Skipped: &Skipped{}},
+			},
+			tests: 2, failures: 1, errors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast := require.New(t)
+			suite := NewTestSuite(tt.cases)
+			ast.Equal(len(tt.cases), suite.Tests)
+			ast.Equal(tt.tests, suite.Tests)
+			ast.Equal(tt.failures, suite.Failures)
+			ast.Equal(tt.errors, suite.Errors)
+			ast.Equal(tt.skips, suite.Skips)
+			ast.Equal(tt.successes, suite.Successes)
+		})
+	}
+}
+
+func TestSplitFailedCases(t *testing.T) {
+	cases := []TestCase{
+		{Name: "pass", ClassName: "A"},
+		{Name: "fail", ClassName: "A", Failure: &Failure{}},
+		{Name: "error", ClassName: "A", Error: &Error{}},
+		{Name: "skip", ClassName: "A", Skipped: &Skipped{}},
+		{Name: "flaky", ClassName: "B", Failure: &Failure{}},
+	}
+
+	tests := []struct {
+		name        string
+		quarantined []string
+		failed      []string
+		ignored     []string
+	}{
+		{
+			name:    "errored cases do not fail the step",
+			failed:  []string{"fail", "flaky"},
+			ignored: []string{},
+		},
+		{
+			name:        "quarantined by key and by name",
+			quarantined: []string{"B.flaky", "fail"},
+			failed:      []string{},
+			ignored:     []string{"fail", "flaky"},
+		},
+		{
+			name:        "passed, errored and skipped cases are never returned",
+			quarantined: []string{"pass", "error", "skip"},
+			failed:      []string{"fail", "flaky"},
+			ignored:     []string{},
+		},
+	}
+
+	names := func(cases []TestCase) []string {
+		resp := make([]string, 0, len(cases))
+		for _, tc := range cases {
+			resp = append(resp, tc.Name)
+		}
+		return resp
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast := require.New(t)
+			failed, ignored := SplitFailedCases(cases, tt.quarantined)
+			ast.Equal(tt.failed, names(failed))
+			ast.Equal(tt.ignored, names(ignored))
+		})
+	}
+}
+
+func TestFailedCasesError(t *testing.T) {
+	ast := require.New(t)
+
+	ast.Nil(FailedCasesError(0, 0))
+	ast.EqualError(FailedCasesError(2, 1), "2 case(s) failed")
+	ast.ErrorIs(FailedCasesError(0, 1), ErrQuarantinedFailures)
+}
+
+func TestMergeStepError(t *testing.T) {
+	scriptErr := errors.New("exit status 1")
+	stepErr := errors.New("upload failed")
+
+	tests := []struct {
+		name    string
+		respErr error
+		err     error
+		expect  error
+	}{
+		{
+			name: "steps succeed",
+		},
+		{
+			name:   "step fails",
+			err:    stepErr,
+			expect: stepErr,
+		},
+		{
+			name:    "later step succeeds after a failure",
+			respErr: scriptErr,
+			expect:  scriptErr,
+		},
+		{
+			name:    "later step fails after a failure",
+			respErr: scriptErr,
+			err:     stepErr,
+			expect:  stepErr,
+		},
+		{
+			name:    "quarantined failures clear the failure of the test script",
+			respErr: scriptErr,
+			err:     FailedCasesError(0, 1),
+		},
+		{
+			name:    "failures which are not quarantined keep failing the job",
+			respErr: scriptErr,
+			err:     FailedCasesError(1, 1),
+			expect:  errors.New("1 case(s) failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast := require.New(t)
+			ast.Equal(tt.expect, MergeStepError(tt.respErr, tt.err))
+		})
+	}
+}
@@ -38,7 +38,7 @@ func artifactsUpload(ctx *meta.Context, activeWorkspace string, artifactPaths []
 	)
 
 	if ctx.StorageURI != "" {
-		if store, err = s3.UnmarshalNewS3StorageFromEncrypted(ctx.StorageURI, ctx.AesKey); err != nil {
+		if store, err = s3.UnmarshalNewS3StorageFromEncrypted(ctx.StorageURI, ctx.AesKeyID, ctx.AesKey); err != nil {
 			log.Errorf("artifactsUpload failed to create s3 storage err:%v", err)
 			return err
 		}
@@ -80,14 +80,16 @@ type TarCacheManager struct {
 	StorageURI   string
 	PipelineName string
 	ServiceName  string
+	aesKeyID     string
 	aesKey       string
 }
 
-func NewTarCacheManager(storageURI, pipelineName, serviceName, aesKey string) *TarCacheManager {
+func NewTarCacheManager(storageURI, pipelineName, serviceName, aesKeyID, aesKey string) *TarCacheManager {
 	return &TarCacheManager{
 		StorageURI:   storageURI,
 		PipelineName: pipelineName,
 		ServiceName:  serviceName,
+		aesKeyID:     aesKeyID,
 		aesKey:       aesKey,
 	}
 }
@@ -182,7 +184,7 @@ func (gcm *TarCacheManager) Unarchive(source, dest string) error {
 func (gcm *TarCacheManager) getS3Storage() (*s3.S3, error) {
 	var err error
 	var store *s3.S3
-	if store, err = s3.UnmarshalNewS3StorageFromEncrypted(gcm.StorageURI, gcm.aesKey); err != nil {
+	if store, err = s3.UnmarshalNewS3StorageFromEncrypted(gcm.StorageURI, gcm.aesKeyID, gcm.aesKey); err != nil {
 		log.Errorf("Archive failed to create s3 storage %s", gcm.StorageURI)
 		return nil, err
 	}
@@ -72,7 +72,7 @@ func NewReaper() (*Reaper, error) {
 
 	reaper := &Reaper{
 		Ctx: ctx,
-		cm:  NewTarCacheManager(ctx.StorageURI, ctx.PipelineName, ctx.ServiceName, ctx.AesKey),
+		cm:  NewTarCacheManager(ctx.StorageURI, ctx.PipelineName, ctx.ServiceName, ctx.AesKeyID, ctx.AesKey),
 	}
 
 	if ctx.TestType != "" {
@@ -447,7 +447,7 @@ func (r *Reaper) RunPMDeployScripts() error {
 func (r *Reaper) downloadArtifactFile() error {
 	var err error
 	var store *s3.S3
-	if store, err = s3.UnmarshalNewS3StorageFromEncrypted(r.Ctx.ArtifactInfo.URL, r.Ctx.AesKey); err != nil {
+	if store, err = s3.UnmarshalNewS3StorageFromEncrypted(r.Ctx.ArtifactInfo.URL, r.Ctx.AesKeyID, r.Ctx.AesKey); err != nil {
 		log.Errorf("Archive failed to create s3 storage %s", r.Ctx.ArtifactInfo.URL)
 		return err
 	}
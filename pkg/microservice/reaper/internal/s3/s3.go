@@ -53,8 +53,8 @@ func UnmarshalNewS3Storage(str string) (*S3, error) {
 	return s3, nil
 }
 
-func UnmarshalNewS3StorageFromEncrypted(encrypted, aesKey string) (*S3, error) {
-	uri, err := crypto.AesDecrypt(encrypted, aesKey)
+func UnmarshalNewS3StorageFromEncrypted(encrypted, aesKeyID, aesKey string) (*S3, error) {
+	uri, err := crypto.AesDecryptByKey(encrypted, aesKeyID, aesKey)
 	if err != nil {
 		return nil, err
 	}
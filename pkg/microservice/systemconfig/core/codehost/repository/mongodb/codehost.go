@@ -263,8 +263,8 @@ func (c *CodehostColl) deleteCodeHost(query bson.M) error {
 
 func (c *CodehostColl) UpdateCodeHost(host *models.CodeHost) (*models.CodeHost, error) {
 	query := bson.M{
-		"id":                host.ID,
-		"deleted_at":        0,
+		"id":         host.ID,
+		"deleted_at": 0,
 	}
 
 	return c.updateCodeHost(query, host)
@@ -297,7 +297,8 @@ func (c *CodehostColl) updateCodeHost(query bson.M, host *models.CodeHost) (*mod
 	}
 	if host.Type == setting.SourceFromGerrit {
 		modifyValue["access_token"] = host.AccessToken
-	} else if host.Type == setting.SourceFromGitee || host.Type == setting.SourceFromGitlab || host.Type == setting.SourceFromGiteeEE {
+	} else if host.Type == setting.SourceFromGitee || host.Type == setting.SourceFromGitlab || host.Type == setting.SourceFromGiteeEE ||
+		host.Type == setting.SourceFromGitea || host.Type == setting.SourceFromBitbucketServer {
 		modifyValue["access_token"] = host.AccessToken
 		modifyValue["refresh_token"] = host.RefreshToken
 		modifyValue["updated_at"] = host.UpdatedAt
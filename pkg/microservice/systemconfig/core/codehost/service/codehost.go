@@ -262,6 +262,17 @@ func newOAuth(provider, callbackURL, clientID, clientSecret, address string) (*o
 			AuthURL:  address + "/oauth/authorize",
 			TokenURL: address + "/oauth/token",
 		}), nil
+	case setting.SourceFromGitea:
+		return oauth.New(callbackURL, clientID, clientSecret, []string{"read:user", "read:organization", "write:repository", "write:issue"}, oauth2.Endpoint{
+			AuthURL:  address + "/login/oauth/authorize",
+			TokenURL: address + "/login/oauth/access_token",
+		}), nil
+	case setting.SourceFromBitbucketServer:
+		// REPO_ADMIN is required to manage the webhooks of the repositories
+		return oauth.New(callbackURL, clientID, clientSecret, []string{"REPO_ADMIN"}, oauth2.Endpoint{
+			AuthURL:  address + "/rest/oauth2/latest/authorize",
+			TokenURL: address + "/rest/oauth2/latest/token",
+		}), nil
 	}
 	return nil, errors.New("illegal provider")
 }
@@ -38,6 +38,9 @@ func (*Router) Inject(router *gin.RouterGroup) {
 		users.DELETE("/:uid", user.DeleteUser)
 		users.GET("/:uid/personal", user.GetPersonalUser)
 		users.GET("/:uid/setting", user.GetUserSetting)
+		users.GET("/:uid/tokens", user.ListAccessTokens)
+		users.POST("/:uid/tokens", user.CreateAccessToken)
+		users.DELETE("/:uid/tokens/:id", user.RevokeAccessToken)
 		users.POST("/search", user.ListUsers)
 		users.GET("/count", user.CountSystemUsers)
 	}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/koderover/zadig/pkg/microservice/user/core/service/user"
+	internalhandler "github.com/koderover/zadig/pkg/shared/handler"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+// checkAccessTokenOwner only allows the user to manage their own tokens, and a personal access token
+// can not be used to manage tokens, otherwise a token can create another one with more permissions.
+func checkAccessTokenOwner(ctx *internalhandler.Context, uid string) error {
+	if ctx.UserID != uid || ctx.TokenID != "" {
+		return e.ErrForbidden
+	}
+	return nil
+}
+
+func CreateAccessToken(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	uid := c.Param("uid")
+	if err := checkAccessTokenOwner(ctx, uid); err != nil {
+		ctx.Err = err
+		return
+	}
+
+	args := &user.CreateAccessTokenArgs{}
+	if err := c.ShouldBindJSON(args); err != nil {
+		ctx.Err = e.ErrInvalidParam.AddErr(err)
+		return
+	}
+
+	ctx.Resp, ctx.Err = user.CreateAccessToken(uid, args, ctx.Logger)
+}
+
+func ListAccessTokens(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	uid := c.Param("uid")
+	if err := checkAccessTokenOwner(ctx, uid); err != nil {
+		ctx.Err = err
+		return
+	}
+
+	ctx.Resp, ctx.Err = user.ListAccessTokens(uid, ctx.Logger)
+}
+
+func RevokeAccessToken(c *gin.Context) {
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	uid := c.Param("uid")
+	if err := checkAccessTokenOwner(ctx, uid); err != nil {
+		ctx.Err = err
+		return
+	}
+
+	ctx.Err = user.RevokeAccessToken(uid, c.Param("id"), ctx.Logger)
+}
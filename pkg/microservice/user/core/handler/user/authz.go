@@ -32,6 +32,10 @@ func GetUserAuthInfo(c *gin.Context) {
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
 
 	uid := c.Query("uid")
+	if tokenID := c.Query("token_id"); tokenID != "" {
+		ctx.Resp, ctx.Err = userservice.GetUserAuthInfoByToken(uid, tokenID, ctx.Logger)
+		return
+	}
 
 	ctx.Resp, ctx.Err = userservice.GetUserAuthInfo(uid, ctx.Logger)
 }
@@ -190,7 +194,13 @@ func ListAuthorizedEnvs(c *gin.Context) {
 }
 
 func GenerateUserAuthInfo(ctx *internalhandler.Context) error {
-	resourceAuthInfo, err := userservice.GetUserAuthInfo(ctx.UserID, ctx.Logger)
+	var resourceAuthInfo *userservice.AuthorizedResources
+	var err error
+	if ctx.TokenID != "" {
+		resourceAuthInfo, err = userservice.GetUserAuthInfoByToken(ctx.UserID, ctx.TokenID, ctx.Logger)
+	} else {
+		resourceAuthInfo, err = userservice.GetUserAuthInfo(ctx.UserID, ctx.Logger)
+	}
 	if err != nil {
 		ctx.Logger.Errorf("Failed to generate user auth info for userID: %s, error is: %s", ctx.UserID, err)
 		return err
@@ -94,3 +94,19 @@ CREATE TABLE IF NOT EXISTS `group_role_binding` (
     FOREIGN KEY (`role_id`) REFERENCES role(`id`) ON DELETE CASCADE
 ) ENGINE = InnoDB CHARACTER SET = utf8 COLLATE = utf8_general_ci COMMENT = '角色组/角色绑定信息' ROW_FORMAT = Compact;
 
+CREATE TABLE IF NOT EXISTS `user_access_token` (
+    `id`           bigint(20) NOT NULL AUTO_INCREMENT,
+    `token_id`     varchar(64) NOT NULL COMMENT '令牌ID',
+    `uid`          varchar(64) NOT NULL COMMENT '用户ID',
+    `name`         varchar(64) NOT NULL COMMENT '令牌名称',
+    `projects`     varchar(2048) NOT NULL DEFAULT '' COMMENT '可访问的项目，为空表示不限制',
+    `verbs`        varchar(2048) NOT NULL DEFAULT '' COMMENT '可使用的权限项，为空表示不限制',
+    `expires_at`   int(11) unsigned NOT NULL DEFAULT '0' COMMENT '过期时间',
+    `last_used_at` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '最后使用时间',
+    `created_at`   int(11) unsigned NOT NULL DEFAULT '0' COMMENT '创建时间',
+    `updated_at`   int(11) unsigned NOT NULL DEFAULT '0' COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `token_id` (`token_id`),
+    UNIQUE KEY `user_token_name` (`uid`, `name`),
+    FOREIGN KEY (`uid`) REFERENCES user(`uid`) ON DELETE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8 COLLATE = utf8_general_ci COMMENT = '用户访问令牌表' ROW_FORMAT = Compact;
@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+// UserAccessToken is a personal access token of the user for the OpenAPI, the token itself is not stored,
+// only the id of the token is kept in the jwt.
+type UserAccessToken struct {
+	Model
+	ID      uint   `gorm:"primarykey"       json:"-"`
+	TokenID string `gorm:"column:token_id"  json:"token_id"`
+	UID     string `gorm:"column:uid"       json:"uid"`
+	Name    string `gorm:"column:name"      json:"name"`
+	// Projects limits the projects the token can access, empty means no limit
+	Projects []string `gorm:"column:projects;serializer:json" json:"projects"`
+	// Verbs limits the actions the token can perform, empty means no limit
+	Verbs      []string `gorm:"column:verbs;serializer:json" json:"verbs"`
+	ExpiresAt  int64    `gorm:"column:expires_at"            json:"expires_at"`
+	LastUsedAt int64    `gorm:"column:last_used_at"          json:"last_used_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (UserAccessToken) TableName() string {
+	return "user_access_token"
+}
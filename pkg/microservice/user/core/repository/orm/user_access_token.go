@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/models"
+)
+
+func CreateUserAccessToken(token *models.UserAccessToken, db *gorm.DB) error {
+	token.CreatedAt = time.Now().Unix()
+	token.UpdatedAt = time.Now().Unix()
+
+	if err := db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUserAccessToken returns the token by token id, nil is returned if the token does not exist
+func GetUserAccessToken(tokenID string, db *gorm.DB) (*models.UserAccessToken, error) {
+	var token models.UserAccessToken
+	err := db.Where("token_id = ?", tokenID).First(&token).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func ListUserAccessTokens(uid string, db *gorm.DB) ([]*models.UserAccessToken, error) {
+	resp := make([]*models.UserAccessToken, 0)
+
+	err := db.Where("uid = ?", uid).Order("created_at Desc").Find(&resp).Error
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func CountUserAccessTokens(uid string, db *gorm.DB) (int64, error) {
+	var count int64
+
+	err := db.Model(&models.UserAccessToken{}).Where("uid = ?", uid).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func UpdateUserAccessTokenLastUsedAt(tokenID string, lastUsedAt int64, db *gorm.DB) error {
+	return db.Model(&models.UserAccessToken{}).Where("token_id = ?", tokenID).Update("last_used_at", lastUsedAt).Error
+}
+
+func DeleteUserAccessToken(uid, tokenID string, db *gorm.DB) error {
+	var token models.UserAccessToken
+	return db.Where("uid = ? AND token_id = ?", uid, tokenID).Delete(&token).Error
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/golang-jwt/jwt"
 
 	"github.com/koderover/zadig/pkg/config"
+	"github.com/koderover/zadig/pkg/setting"
 )
 
 type Claims struct {
@@ -28,9 +29,14 @@ type Claims struct {
 	UID               string          `json:"uid"`
 	PreferredUsername string          `json:"preferred_username"`
 	FederatedClaims   FederatedClaims `json:"federated_claims"`
+	Type              string          `json:"token_type,omitempty"`
 	jwt.StandardClaims
 }
 
+func (c *Claims) IsPersonalAccessToken() bool {
+	return c.Type == setting.PersonalAccessTokenType
+}
+
 type FederatedClaims struct {
 	ConnectorId string `json:"connector_id"`
 	UserId      string `json:"user_id"`
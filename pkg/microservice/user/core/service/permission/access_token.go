@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permission
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository"
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/mongodb"
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/orm"
+	"github.com/koderover/zadig/pkg/microservice/user/core/service/login"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+// the last used time of the access token is updated at most once a minute to avoid writing the database on every request
+const accessTokenLastUsedInterval = 60
+
+// the query parameters used by the apis to specify the project
+var projectQueryKeys = []string{"projectName", "projectKey", "project"}
+
+// accessTokenScope limits the projects and verbs a personal access token can use, a nil scope means no limit.
+type accessTokenScope struct {
+	projects sets.String
+	verbs    sets.String
+}
+
+func newAccessTokenScope(token *models.UserAccessToken) *accessTokenScope {
+	return &accessTokenScope{
+		projects: sets.NewString(token.Projects...),
+		verbs:    sets.NewString(token.Verbs...),
+	}
+}
+
+func (s *accessTokenScope) isUnrestricted() bool {
+	return s == nil || (s.projects.Len() == 0 && s.verbs.Len() == 0)
+}
+
+func (s *accessTokenScope) allowsProject(project string) bool {
+	return s == nil || s.projects.Len() == 0 || s.projects.Has(project)
+}
+
+// allowsNamespace checks the namespace of a role, the roles in the general namespace are limited by verbs only
+func (s *accessTokenScope) allowsNamespace(namespace string) bool {
+	return namespace == GeneralNamespace || s.allowsProject(namespace)
+}
+
+func (s *accessTokenScope) allowsVerb(verb string) bool {
+	return s == nil || s.verbs.Len() == 0 || s.verbs.Has(verb)
+}
+
+// allowsSystemVerb checks the system level actions, a token limited to some projects has no system level action.
+func (s *accessTokenScope) allowsSystemVerb(verb string) bool {
+	if s != nil && s.projects.Len() > 0 {
+		return false
+	}
+	return s.allowsVerb(verb)
+}
+
+// validateAccessToken checks if the personal access token in the claims is still valid and records the usage of it
+func validateAccessToken(claims *login.Claims) (*models.UserAccessToken, error) {
+	token, err := orm.GetUserAccessToken(claims.Id, repository.DB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find access token, error: %s", err)
+	}
+	now := time.Now().Unix()
+	if err := checkAccessToken(token, claims.UID, now); err != nil {
+		return nil, err
+	}
+	if now-token.LastUsedAt >= accessTokenLastUsedInterval {
+		if err := orm.UpdateUserAccessTokenLastUsedAt(token.TokenID, now, repository.DB); err != nil {
+			log.Warnf("failed to update the last used time of access token %s, error: %s", token.TokenID, err)
+		}
+		token.LastUsedAt = now
+	}
+	return token, nil
+}
+
+// checkAccessToken checks if the token found in the database is still usable by the user at the given time,
+// a revoked token is deleted from the database so it is not found.
+func checkAccessToken(token *models.UserAccessToken, uid string, now int64) error {
+	if token == nil || token.UID != uid {
+		return fmt.Errorf("access token has been revoked")
+	}
+	if token.ExpiresAt <= now {
+		return fmt.Errorf("access token has expired")
+	}
+	return nil
+}
+
+// checkRequest checks if the projects specified in the query of the request are in the scope
+func (s *accessTokenScope) checkRequest(reqPath string) error {
+	if s == nil || s.projects.Len() == 0 {
+		return nil
+	}
+	u, err := url.Parse(reqPath)
+	if err != nil {
+		return err
+	}
+	for _, key := range projectQueryKeys {
+		project := u.Query().Get(key)
+		if project != "" && !s.allowsProject(project) {
+			return fmt.Errorf("project %s is not in the scope of the access token", project)
+		}
+	}
+	return nil
+}
+
+// CheckAccessTokenRequest checks if the project in the request is in the scope of the personal access token
+func CheckAccessTokenRequest(claims *login.Claims, reqPath string) error {
+	token, err := orm.GetUserAccessToken(claims.Id, repository.DB)
+	if err != nil {
+		return fmt.Errorf("failed to find access token, error: %s", err)
+	}
+	if err := checkAccessToken(token, claims.UID, time.Now().Unix()); err != nil {
+		return err
+	}
+	return newAccessTokenScope(token).checkRequest(reqPath)
+}
+
+// GetUserAuthInfoByToken generates the authorized resources of the user limited by the scope of the personal access token
+func GetUserAuthInfoByToken(uid, tokenID string, logger *zap.SugaredLogger) (*AuthorizedResources, error) {
+	token, err := orm.GetUserAccessToken(tokenID, repository.DB)
+	if err != nil {
+		logger.Errorf("failed to find access token: %s, error: %s", tokenID, err)
+		return nil, fmt.Errorf("failed to find access token: %s, error: %s", tokenID, err)
+	}
+	if err := checkAccessToken(token, uid, time.Now().Unix()); err != nil {
+		return nil, err
+	}
+
+	return getUserAuthInfo(uid, newAccessTokenScope(token), logger)
+}
+
+// grantProjectAdmin grants the project admin to the project, only the verbs in the scope are granted if the scope limits verbs
+func grantProjectAdmin(actions *ProjectActions, scope *accessTokenScope) {
+	if scope == nil || scope.verbs.Len() == 0 {
+		actions.IsProjectAdmin = true
+		return
+	}
+	for _, verb := range scope.verbs.List() {
+		modifyUserProjectAuth(actions, verb)
+	}
+}
+
+// generateScopedAdminResource generates the resources of the system admin limited by the scope of the access token
+func generateScopedAdminResource(scope *accessTokenScope, logger *zap.SugaredLogger) (*AuthorizedResources, error) {
+	projects := scope.projects.List()
+	if len(projects) == 0 {
+		projectList, err := mongodb.NewProjectColl().List()
+		if err != nil {
+			logger.Errorf("failed to list project for system admin, error: %s", err)
+			return nil, fmt.Errorf("failed to list project for system admin, error: %s", err)
+		}
+		for _, project := range projectList {
+			projects = append(projects, project.ProductName)
+		}
+	}
+
+	projectInfo := make(map[string]ProjectActions)
+	for _, project := range projects {
+		actions := generateDefaultProjectActions()
+		grantProjectAdmin(actions, scope)
+		projectInfo[project] = *actions
+	}
+
+	systemActions := generateDefaultSystemActions()
+	for _, verb := range scope.verbs.List() {
+		if scope.allowsSystemVerb(verb) {
+			modifySystemAction(systemActions, verb)
+		}
+	}
+
+	return &AuthorizedResources{
+		IsSystemAdmin:   false,
+		ProjectAuthInfo: projectInfo,
+		SystemActions:   systemActions,
+	}, nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/models"
+)
+
+func TestCheckAccessToken(t *testing.T) {
+	const now = int64(1700000000)
+
+	tests := []struct {
+		name    string
+		token   *models.UserAccessToken
+		uid     string
+		wantErr string
+	}{
+		{
+			name:  "valid token",
+			token: &models.UserAccessToken{UID: "u1", ExpiresAt: now + 60},
+			uid:   "u1",
+		},
+		{
+			name:    "revoked token is not found",
+			token:   nil,
+			uid:     "u1",
+			wantErr: "revoked",
+		},
+		{
+			name:    "token of another user",
+			token:   &models.UserAccessToken{UID: "u2", ExpiresAt: now + 60},
+			uid:     "u1",
+			wantErr: "revoked",
+		},
+		{
+			name:    "expired token",
+			token:   &models.UserAccessToken{UID: "u1", ExpiresAt: now - 1},
+			uid:     "u1",
+			wantErr: "expired",
+		},
+		{
+			name:    "token expiring right now",
+			token:   &models.UserAccessToken{UID: "u1", ExpiresAt: now},
+			uid:     "u1",
+			wantErr: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAccessToken(tt.token, tt.uid, now)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccessTokenScopeCheckRequest(t *testing.T) {
+	scoped := newAccessTokenScope(&models.UserAccessToken{Projects: []string{"demo"}})
+	verbOnly := newAccessTokenScope(&models.UserAccessToken{Verbs: []string{VerbGetWorkflow}})
+
+	tests := []struct {
+		name    string
+		scope   *accessTokenScope
+		path    string
+		allowed bool
+	}{
+		{"project in scope", scoped, "/openapi/workflows/custom?projectName=demo", true},
+		{"project key in scope", scoped, "/openapi/environments?projectKey=demo", true},
+		{"project out of scope", scoped, "/openapi/workflows/custom?projectName=other", false},
+		{"project key out of scope", scoped, "/openapi/environments?projectKey=other", false},
+		{"project param out of scope", scoped, "/api/aslan/delivery/releases?project=other", false},
+		{"any project key out of scope", scoped, "/openapi/builds?projectName=demo&projectKey=other", false},
+		{"route without project", scoped, "/openapi/system/operation", true},
+		{"no project limit", verbOnly, "/openapi/workflows/custom?projectName=other", true},
+		{"nil scope", nil, "/openapi/workflows/custom?projectName=other", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scope.checkRequest(tt.path)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestAccessTokenScopeVerbs(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         *models.UserAccessToken
+		verb          string
+		namespace     string
+		allowsVerb    bool
+		allowsSystem  bool
+		allowsNSCheck bool
+	}{
+		{
+			name:          "unrestricted token",
+			token:         &models.UserAccessToken{},
+			verb:          VerbEditWorkflow,
+			namespace:     "demo",
+			allowsVerb:    true,
+			allowsSystem:  true,
+			allowsNSCheck: true,
+		},
+		{
+			name:          "verb in scope",
+			token:         &models.UserAccessToken{Verbs: []string{VerbGetWorkflow, VerbCreateProject}},
+			verb:          VerbCreateProject,
+			namespace:     "demo",
+			allowsVerb:    true,
+			allowsSystem:  true,
+			allowsNSCheck: true,
+		},
+		{
+			name:          "verb out of scope",
+			token:         &models.UserAccessToken{Verbs: []string{VerbGetWorkflow}},
+			verb:          VerbEditWorkflow,
+			namespace:     "demo",
+			allowsVerb:    false,
+			allowsSystem:  false,
+			allowsNSCheck: true,
+		},
+		{
+			name:          "project scoped token has no system verbs",
+			token:         &models.UserAccessToken{Projects: []string{"demo"}},
+			verb:          VerbCreateProject,
+			namespace:     "other",
+			allowsVerb:    true,
+			allowsSystem:  false,
+			allowsNSCheck: false,
+		},
+		{
+			name:          "general namespace is limited by verbs only",
+			token:         &models.UserAccessToken{Projects: []string{"demo"}},
+			verb:          VerbGetTemplate,
+			namespace:     GeneralNamespace,
+			allowsVerb:    true,
+			allowsSystem:  false,
+			allowsNSCheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := newAccessTokenScope(tt.token)
+			assert.Equal(t, tt.allowsVerb, scope.allowsVerb(tt.verb))
+			assert.Equal(t, tt.allowsSystem, scope.allowsSystemVerb(tt.verb))
+			assert.Equal(t, tt.allowsNSCheck, scope.allowsNamespace(tt.namespace))
+		})
+	}
+}
+
+func TestGrantProjectAdmin(t *testing.T) {
+	actions := generateDefaultProjectActions()
+	grantProjectAdmin(actions, nil)
+	assert.True(t, actions.IsProjectAdmin)
+
+	actions = generateDefaultProjectActions()
+	grantProjectAdmin(actions, newAccessTokenScope(&models.UserAccessToken{Verbs: []string{VerbGetWorkflow, VerbRunWorkflow}}))
+	assert.False(t, actions.IsProjectAdmin)
+	assert.True(t, actions.Workflow.View)
+	assert.True(t, actions.Workflow.Execute)
+	assert.False(t, actions.Workflow.Edit)
+	assert.False(t, actions.Workflow.Delete)
+}
+
+func TestGenerateScopedAdminResource(t *testing.T) {
+	scope := newAccessTokenScope(&models.UserAccessToken{
+		Projects: []string{"demo"},
+		Verbs:    []string{VerbGetWorkflow, VerbCreateProject},
+	})
+
+	resources, err := generateScopedAdminResource(scope, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	assert.False(t, resources.IsSystemAdmin)
+	assert.Len(t, resources.ProjectAuthInfo, 1)
+
+	demo, ok := resources.ProjectAuthInfo["demo"]
+	assert.True(t, ok)
+	assert.False(t, demo.IsProjectAdmin)
+	assert.True(t, demo.Workflow.View)
+	assert.False(t, demo.Workflow.Edit)
+	// the token is limited to some projects, so the system level verb is not granted
+	assert.False(t, resources.SystemActions.Project.Create)
+}
@@ -272,10 +272,18 @@ func ValidateToken(tokenString string) (*login.Claims, bool, error) {
 		return nil, false, err
 	}
 
-	if claims, ok := token.Claims.(*login.Claims); ok && token.Valid {
-		return claims, true, nil
-	} else {
+	claims, ok := token.Claims.(*login.Claims)
+	if !ok || !token.Valid {
 		log.Errorf("invalid token detected")
 		return nil, false, fmt.Errorf("invalid token")
 	}
+
+	// personal access tokens can be revoked before they expire, so they need to be checked in the database
+	if claims.IsPersonalAccessToken() {
+		if _, err := validateAccessToken(claims); err != nil {
+			log.Errorf("invalid access token detected, err: %s", err)
+			return nil, false, err
+		}
+	}
+	return claims, true, nil
 }
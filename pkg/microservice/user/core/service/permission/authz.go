@@ -32,6 +32,12 @@ import (
 )
 
 func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResources, error) {
+	return getUserAuthInfo(uid, nil, logger)
+}
+
+// getUserAuthInfo generates the authorized resources of the user, the resources are limited by the scope
+// of the personal access token if there is one.
+func getUserAuthInfo(uid string, scope *accessTokenScope, logger *zap.SugaredLogger) (*AuthorizedResources, error) {
 	tx := repository.DB.Begin(&sql.TxOptions{ReadOnly: true})
 	// system calls
 	if uid == "" {
@@ -48,7 +54,10 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 
 	if isSystemAdmin {
 		tx.Commit()
-		return generateAdminRoleResource(), nil
+		if scope.isUnrestricted() {
+			return generateAdminRoleResource(), nil
+		}
+		return generateScopedAdminResource(scope, logger)
 	}
 
 	groupIDList := make([]string, 0)
@@ -89,6 +98,9 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 	}
 
 	for _, role := range roles {
+		if !scope.allowsNamespace(role.Namespace) {
+			continue
+		}
 		if role.Namespace != GeneralNamespace {
 			if _, ok := projectActionMap[role.Namespace]; !ok {
 				projectActionMap[role.Namespace] = generateDefaultProjectActions()
@@ -97,7 +109,7 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 
 		// project admin does not have any bindings, it is special
 		if role.Name == ProjectAdminRole {
-			projectActionMap[role.Namespace].IsProjectAdmin = true
+			grantProjectAdmin(projectActionMap[role.Namespace], scope)
 			continue
 		}
 
@@ -117,9 +129,13 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 			roleActionMap[role.ID].Insert(action.Action)
 			switch action.Scope {
 			case setting.ActionTypeSystem:
-				modifySystemAction(systemActions, action.Action)
+				if scope.allowsSystemVerb(action.Action) {
+					modifySystemAction(systemActions, action.Action)
+				}
 			case setting.ActionTypeProject:
-				modifyUserProjectAuth(projectActionMap[role.Namespace], action.Action)
+				if scope.allowsVerb(action.Action) {
+					modifyUserProjectAuth(projectActionMap[role.Namespace], action.Action)
+				}
 			}
 		}
 	}
@@ -132,6 +148,9 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 	}
 
 	for _, role := range groupRoles {
+		if !scope.allowsNamespace(role.Namespace) {
+			continue
+		}
 		if role.Namespace != GeneralNamespace {
 			if _, ok := projectActionMap[role.Namespace]; !ok {
 				projectActionMap[role.Namespace] = generateDefaultProjectActions()
@@ -144,7 +163,7 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 		}
 
 		if role.Name == ProjectAdminRole {
-			projectActionMap[role.Namespace].IsProjectAdmin = true
+			grantProjectAdmin(projectActionMap[role.Namespace], scope)
 		}
 
 		// first get actions from the roles
@@ -163,9 +182,13 @@ func GetUserAuthInfo(uid string, logger *zap.SugaredLogger) (*AuthorizedResource
 			roleActionMap[role.ID].Insert(action.Action)
 			switch action.Scope {
 			case setting.ActionTypeSystem:
-				modifySystemAction(systemActions, action.Action)
+				if scope.allowsSystemVerb(action.Action) {
+					modifySystemAction(systemActions, action.Action)
+				}
 			case setting.ActionTypeProject:
-				modifyUserProjectAuth(projectActionMap[role.Namespace], action.Action)
+				if scope.allowsVerb(action.Action) {
+					modifyUserProjectAuth(projectActionMap[role.Namespace], action.Action)
+				}
 			}
 		}
 	}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository"
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/models"
+	"github.com/koderover/zadig/pkg/microservice/user/core/repository/orm"
+	"github.com/koderover/zadig/pkg/microservice/user/core/service/login"
+	"github.com/koderover/zadig/pkg/setting"
+	e "github.com/koderover/zadig/pkg/tool/errors"
+)
+
+// MaxAccessTokensPerUser is the max number of personal access tokens a user can have
+const MaxAccessTokensPerUser = 20
+
+type CreateAccessTokenArgs struct {
+	Name string `json:"name"`
+	// Projects limits the projects the token can access, empty means all the projects the user can access
+	Projects []string `json:"projects"`
+	// Verbs limits the actions the token can perform, empty means all the actions the user can perform
+	Verbs []string `json:"verbs"`
+	// ExpiresAt is the unix timestamp the token expires at, it is required
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+type CreateAccessTokenResp struct {
+	*models.UserAccessToken
+	// Token is only returned once when it is created
+	Token string `json:"token"`
+}
+
+func CreateAccessToken(uid string, args *CreateAccessTokenArgs, logger *zap.SugaredLogger) (*CreateAccessTokenResp, error) {
+	if args.Name == "" {
+		return nil, e.ErrCreateAccessToken.AddDesc("token name is required")
+	}
+	if args.ExpiresAt <= time.Now().Unix() {
+		return nil, e.ErrCreateAccessToken.AddDesc("token must expire in the future")
+	}
+	for _, verb := range args.Verbs {
+		action, err := orm.GetActionByVerb(verb, repository.DB)
+		if err != nil {
+			logger.Errorf("failed to find action %s, error: %s", verb, err)
+			return nil, e.ErrCreateAccessToken.AddErr(err)
+		}
+		if action.ID == 0 {
+			return nil, e.ErrCreateAccessToken.AddDesc(fmt.Sprintf("unknown verb: %s", verb))
+		}
+	}
+
+	user, err := orm.GetUserByUid(uid, repository.DB)
+	if err != nil {
+		logger.Errorf("CreateAccessToken getUserByUid:%s error, error msg:%s", uid, err)
+		return nil, e.ErrCreateAccessToken.AddErr(err)
+	}
+	if user == nil {
+		return nil, e.ErrCreateAccessToken.AddDesc("user not found")
+	}
+
+	tokens, err := orm.ListUserAccessTokens(uid, repository.DB)
+	if err != nil {
+		logger.Errorf("failed to list access tokens of user: %s, error: %s", uid, err)
+		return nil, e.ErrCreateAccessToken.AddErr(err)
+	}
+	if len(tokens) >= MaxAccessTokensPerUser {
+		return nil, e.ErrCreateAccessToken.AddDesc(fmt.Sprintf("a user can have at most %d access tokens", MaxAccessTokensPerUser))
+	}
+	for _, token := range tokens {
+		if token.Name == args.Name {
+			return nil, e.ErrCreateAccessToken.AddDesc(fmt.Sprintf("token %s already exists", args.Name))
+		}
+	}
+
+	accessToken := &models.UserAccessToken{
+		TokenID:   uuid.New().String(),
+		UID:       uid,
+		Name:      args.Name,
+		Projects:  args.Projects,
+		Verbs:     args.Verbs,
+		ExpiresAt: args.ExpiresAt,
+	}
+	if accessToken.Projects == nil {
+		accessToken.Projects = []string{}
+	}
+	if accessToken.Verbs == nil {
+		accessToken.Verbs = []string{}
+	}
+
+	token, err := login.CreateToken(&login.Claims{
+		Name:              user.Name,
+		UID:               user.UID,
+		Email:             user.Email,
+		PreferredUsername: user.Account,
+		Type:              setting.PersonalAccessTokenType,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  setting.ProductName,
+			Id:        accessToken.TokenID,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: accessToken.ExpiresAt,
+		},
+		FederatedClaims: login.FederatedClaims{
+			ConnectorId: user.IdentityType,
+			UserId:      user.Account,
+		},
+	})
+	if err != nil {
+		logger.Errorf("user:%s create access token error, error msg:%s", user.Account, err)
+		return nil, e.ErrCreateAccessToken.AddErr(err)
+	}
+
+	if err := orm.CreateUserAccessToken(accessToken, repository.DB); err != nil {
+		logger.Errorf("user:%s save access token error, error msg:%s", user.Account, err)
+		return nil, e.ErrCreateAccessToken.AddErr(err)
+	}
+
+	return &CreateAccessTokenResp{
+		UserAccessToken: accessToken,
+		Token:           token,
+	}, nil
+}
+
+func ListAccessTokens(uid string, logger *zap.SugaredLogger) ([]*models.UserAccessToken, error) {
+	tokens, err := orm.ListUserAccessTokens(uid, repository.DB)
+	if err != nil {
+		logger.Errorf("failed to list access tokens of user: %s, error: %s", uid, err)
+		return nil, e.ErrListAccessTokens.AddErr(err)
+	}
+	return tokens, nil
+}
+
+// RevokeAccessToken deletes the token, the requests with the token are rejected immediately after that
+func RevokeAccessToken(uid, tokenID string, logger *zap.SugaredLogger) error {
+	if err := orm.DeleteUserAccessToken(uid, tokenID, repository.DB); err != nil {
+		logger.Errorf("failed to revoke access token: %s of user: %s, error: %s", tokenID, uid, err)
+		return e.ErrDeleteAccessToken.AddErr(err)
+	}
+	return nil
+}
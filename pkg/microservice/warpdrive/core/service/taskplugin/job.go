@@ -231,6 +231,7 @@ func (b *JobCtxBuilder) BuildReaperContext(pipelineTask *task.Task, serviceName
 		ServiceName:     serviceName,
 		StorageEndpoint: pipelineTask.StorageEndpoint,
 		AesKey:          pipelineTask.ConfigPayload.AesKey,
+		AesKeyID:        pipelineTask.ConfigPayload.AesKeyID,
 		UploadEnabled:   b.JobCtx.UploadEnabled,
 		UploadInfo:      b.JobCtx.UploadInfo,
 	}
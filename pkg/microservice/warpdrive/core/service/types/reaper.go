@@ -118,6 +118,7 @@ type Context struct {
 	ArtifactInfo    *ArtifactInfo `yaml:"artifact_info"`
 	ArtifactPath    string        `yaml:"artifact_path"`
 	AesKey          string        `yaml:"aes_key"`
+	AesKeyID        string        `yaml:"aes_key_id"`
 
 	// New since V1.10.0.
 	CacheEnable  bool               `yaml:"cache_enable"`
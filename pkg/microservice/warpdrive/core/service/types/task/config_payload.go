@@ -43,6 +43,7 @@ type ConfigPayload struct {
 	HubServerAddr      string
 	DeployClusterID    string
 	AesKey             string `json:"aes_key"`
+	AesKeyID           string `json:"aes_key_id"`
 
 	RepoConfigs map[string]*RegistryNamespace
 
@@ -30,6 +30,7 @@ const (
 	ENVAslanDBName             = "ASLAN_DB"
 	ENVHubAgentImage           = "HUB_AGENT_IMAGE"
 	ENVExecutorImage           = "EXECUTOR_IMAGE"
+	ENVBinfmtImage             = "BINFMT_IMAGE"
 	ENVMysqlUser               = "MYSQL_USER"
 	ENVMysqlPassword           = "MYSQL_PASSWORD"
 	ENVMysqlHost               = "MYSQL_HOST"
@@ -75,6 +76,20 @@ const (
 	ENVS3StoragePath     = "S3STORAGE_PATH"
 	ENVKubeServerAddr    = "KUBE_SERVER_ADDR"
 
+	// external secret providers for the secret references of job envs
+	ENVSecretVaultAddr         = "SECRET_VAULT_ADDR"
+	ENVSecretVaultK8sRole      = "SECRET_VAULT_K8S_ROLE"
+	ENVSecretVaultK8sAuthPath  = "SECRET_VAULT_K8S_AUTH_PATH"
+	ENVSecretFileRoot          = "SECRET_FILE_ROOT"
+	ENVSecretKubernetesEnabled = "SECRET_KUBERNETES_ENABLED"
+	// ENVSecretAllowlist is a json map of project name to the paths each provider is allowed to read for the project
+	ENVSecretAllowlist = "SECRET_ALLOWLIST"
+	// ENVSecretVaultToken is read by aslan and the job executors resolving the secret references, it is never sent in the job context
+	ENVSecretVaultToken = "SECRET_VAULT_TOKEN"
+	// ENVSecretVaultTokenSecret is the kubernetes secret in the namespace of the jobs whose token key is given to the job executors
+	// as ENVSecretVaultToken
+	ENVSecretVaultTokenSecret = "SECRET_VAULT_TOKEN_SECRET"
+
 	// cron
 	ENVRootToken = "ROOT_TOKEN"
 
@@ -267,6 +282,12 @@ const (
 	SourceFromGitee = "gitee"
 	// SourceFromGiteeEE Configure the source as gitee-enterprise
 	SourceFromGiteeEE = "gitee-enterprise"
+	// SourceFromGitea Configure the source as gitea
+	SourceFromGitea = "gitea"
+	// SourceFromBitbucketServer Configure the source as bitbucket server
+	SourceFromBitbucketServer = "bitbucket-server"
+	// SourceFromKustomize The configuration source is a kustomization in code host
+	SourceFromKustomize = "kustomize"
 	// SourceFromOther Configure the source as other
 	SourceFromOther = "other"
 	// SourceFromChartTemplate The configuration source is helmTemplate
@@ -333,6 +354,10 @@ const (
 	AuthorizationHeader = "Authorization"
 )
 
+// PersonalAccessTokenType is the type claim of the personal access tokens for the OpenAPI,
+// the id of the token is stored in the jti claim.
+const PersonalAccessTokenType = "personal_access_token"
+
 // install script constants
 const (
 	StandardScriptName   = "install.sh"
@@ -49,6 +49,7 @@ type Context struct {
 	UserID       string
 	IdentityType string
 	RequestID    string
+	TokenID      string
 	Resources    *user.AuthorizedResources
 }
 
@@ -58,6 +59,7 @@ type jwtClaims struct {
 	UID             string          `json:"uid"`
 	Account         string          `json:"preferred_username"`
 	FederatedClaims FederatedClaims `json:"federated_claims"`
+	Type            string          `json:"token_type"`
 	jwt.StandardClaims
 }
 
@@ -85,7 +87,13 @@ func NewContext(c *gin.Context) *Context {
 		claims.Name = "system"
 	}
 
+	tokenID := ""
+	if claims.Type == setting.PersonalAccessTokenType {
+		tokenID = claims.Id
+	}
+
 	return &Context{
+		TokenID:      tokenID,
 		UserName:     claims.Name,
 		UserID:       claims.UID,
 		Account:      claims.Account,
@@ -103,7 +111,12 @@ func NewContextWithAuthorization(c *gin.Context) (*Context, error) {
 	var err error
 	resp := NewContext(c)
 	// there is a case where the request does not have token (system call), in this case we will have admin access
-	resourceAuthInfo, err = user.New().GetUserAuthInfo(resp.UserID)
+	if resp.TokenID != "" {
+		// the resources of the personal access token are limited by its scope
+		resourceAuthInfo, err = user.New().GetUserAuthInfoByToken(resp.UserID, resp.TokenID)
+	} else {
+		resourceAuthInfo, err = user.New().GetUserAuthInfo(resp.UserID)
+	}
 	if err != nil {
 		logger.Errorf("failed to generate user authorization info, error: %s", err)
 		return resp, err
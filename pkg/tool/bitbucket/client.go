@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket is a client of the REST api of Bitbucket Server (Bitbucket Data Center)
+package bitbucket
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+// defaultPageSize is the default page size of the paged apis
+const defaultPageSize = 100
+
+type Client struct {
+	*httpclient.Client
+	Address string
+}
+
+// NewClient creates a client of the bitbucket server api, the oauth token of the codehost is refreshed if it is about to expire.
+func NewClient(id int, address, accessToken, proxyAddr string, enableProxy bool) (*Client, error) {
+	token, err := UpdateBitbucketToken(id, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh bitbucket token, err: %s", err)
+	}
+
+	opts := []httpclient.ClientFunc{
+		httpclient.SetHostURL(address),
+	}
+	if token != "" {
+		opts = append(opts, httpclient.SetAuthToken(token))
+	}
+	if enableProxy {
+		opts = append(opts, httpclient.SetProxy(proxyAddr))
+	}
+
+	return &Client{
+		Client:  httpclient.New(opts...),
+		Address: address,
+	}, nil
+}
+
+// ListOptions specifies the page of the list apis, all the pages are fetched if Start is less than 0
+type ListOptions struct {
+	Start int
+	Limit int
+	// NoPaginated indicates if we need to fetch all result or just one page. True means fetching just one page
+	NoPaginated bool
+}
+
+// page is the common structure of the paged apis of bitbucket server
+type page struct {
+	Size          int  `json:"size"`
+	Limit         int  `json:"limit"`
+	IsLastPage    bool `json:"isLastPage"`
+	Start         int  `json:"start"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// paginated fetches the pages by f until the last page
+func paginated(opts *ListOptions, f func(params map[string]string) (*page, error)) error {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	limit := opts.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	start := opts.Start
+	for {
+		p, err := f(map[string]string{
+			"start": strconv.Itoa(start),
+			"limit": strconv.Itoa(limit),
+		})
+		if err != nil {
+			return err
+		}
+		if opts.NoPaginated || p.IsLastPage || p.NextPageStart <= start {
+			return nil
+		}
+		start = p.NextPageStart
+	}
+}
+
+func apiPath(format string, args ...interface{}) string {
+	return "/rest/api/1.0" + fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EventType represents a bitbucket server event type.
+type EventType string
+
+// List of available event types.
+const (
+	EventTypeRefsChanged      EventType = "repo:refs_changed"
+	EventTypePROpened         EventType = "pr:opened"
+	EventTypePRFromRefUpdated EventType = "pr:from_ref_updated"
+	EventTypeDiagnosticsPing  EventType = "diagnostics:ping"
+)
+
+// List of the types of a ref and the types of a ref change.
+const (
+	RefTypeBranch = "BRANCH"
+	RefTypeTag    = "TAG"
+
+	RefChangeTypeAdd    = "ADD"
+	RefChangeTypeUpdate = "UPDATE"
+	RefChangeTypeDelete = "DELETE"
+)
+
+const (
+	eventTypeHeader = "X-Event-Key"
+	signatureHeader = "X-Hub-Signature"
+	signaturePrefix = "sha256="
+)
+
+// HookEventType returns the event type for the given request.
+func HookEventType(r *http.Request) EventType {
+	return EventType(r.Header.Get(eventTypeHeader))
+}
+
+// ValidatePayload checks the hmac-sha256 signature of the payload signed by the secret of the webhook.
+func ValidatePayload(r *http.Request, payload []byte, secret string) error {
+	if secret == "" {
+		return nil
+	}
+
+	signature := r.Header.Get(signatureHeader)
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("invalid signature: %s", signature)
+	}
+	sum, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func ParseHook(eventType EventType, payload []byte) (interface{}, error) {
+	var event interface{}
+	switch eventType {
+	case EventTypeRefsChanged:
+		event = &RefsChangedEvent{}
+	case EventTypePROpened, EventTypePRFromRefUpdated:
+		event = &PullRequestEvent{}
+	default:
+		return nil, fmt.Errorf("unexpected event type: %s", eventType)
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+type RefsChangedEvent struct {
+	EventKey   string       `json:"eventKey"`
+	Date       string       `json:"date"`
+	Actor      *User        `json:"actor"`
+	Repository *Repository  `json:"repository"`
+	Changes    []*RefChange `json:"changes"`
+}
+
+type RefChange struct {
+	Ref      *Ref   `json:"ref"`
+	RefID    string `json:"refId"`
+	FromHash string `json:"fromHash"`
+	ToHash   string `json:"toHash"`
+	Type     string `json:"type"`
+}
+
+type PullRequestEvent struct {
+	EventKey         string       `json:"eventKey"`
+	Date             string       `json:"date"`
+	Actor            *User        `json:"actor"`
+	PullRequest      *PullRequest `json:"pullRequest"`
+	PreviousFromHash string       `json:"previousFromHash"`
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+func TestValidatePayload(t *testing.T) {
+	payload := []byte(`{"eventKey":"repo:refs_changed"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(signatureHeader, signaturePrefix+hex.EncodeToString(mac.Sum(nil)))
+
+	assert.NoError(t, ValidatePayload(req, payload, "secret"))
+	assert.Error(t, ValidatePayload(req, payload, "another"))
+
+	req.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	assert.Error(t, ValidatePayload(req, payload, "secret"))
+}
+
+func TestParseHook(t *testing.T) {
+	event, err := ParseHook(EventTypeRefsChanged, []byte(`{"repository":{"slug":"repo","project":{"key":"PRJ"}},"changes":[{"ref":{"id":"refs/tags/v1","displayId":"v1","type":"TAG"},"toHash":"abc","type":"ADD"}]}`))
+	require.NoError(t, err)
+	refs, ok := event.(*RefsChangedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "PRJ", refs.Repository.Project.Key)
+	require.Len(t, refs.Changes, 1)
+	assert.Equal(t, RefTypeTag, refs.Changes[0].Ref.Type)
+
+	event, err = ParseHook(EventTypePRFromRefUpdated, []byte(`{"pullRequest":{"id":2,"fromRef":{"latestCommit":"abc"},"toRef":{"displayId":"main"}}}`))
+	require.NoError(t, err)
+	pr, ok := event.(*PullRequestEvent)
+	require.True(t, ok)
+	assert.Equal(t, 2, pr.PullRequest.ID)
+	assert.Equal(t, "main", pr.PullRequest.ToRef.DisplayID)
+
+	_, err = ParseHook(EventTypeDiagnosticsPing, []byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestListBranchesPaginated(t *testing.T) {
+	log.Init(&log.Config{Level: "info"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/1.0/projects/PRJ/repos/repo/branches", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("start") == "0" {
+			fmt.Fprint(w, `{"isLastPage":false,"nextPageStart":1,"values":[{"id":"refs/heads/main","displayId":"main"}]}`)
+			return
+		}
+		assert.Equal(t, "1", r.URL.Query().Get("start"))
+		fmt.Fprint(w, `{"isLastPage":true,"values":[{"id":"refs/heads/dev","displayId":"dev"}]}`)
+	}))
+	defer server.Close()
+
+	cli := &Client{Client: httpclient.New(httpclient.SetHostURL(server.URL), httpclient.SetAuthToken("token")), Address: server.URL}
+	branches, err := cli.ListBranches("PRJ", "repo", "", &ListOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, branches, 2)
+	assert.Equal(t, "main", branches[0].DisplayID)
+	assert.Equal(t, "dev", branches[1].DisplayID)
+
+	branches, err = cli.ListBranches("PRJ", "repo", "", &ListOptions{Limit: 1, NoPaginated: true})
+	require.NoError(t, err)
+	assert.Len(t, branches, 1)
+}
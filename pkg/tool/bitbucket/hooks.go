@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/koderover/zadig/pkg/tool/git"
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type webhook struct {
+	ID            int               `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Active        bool              `json:"active"`
+	Events        []string          `json:"events"`
+	Configuration map[string]string `json:"configuration"`
+}
+
+// CreateHook creates a webhook for the push and pull request events, the id of the hook is returned
+func (c *Client) CreateHook(projectKey, repo string, hook *git.Hook) (string, error) {
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{string(EventTypeRefsChanged), string(EventTypePROpened), string(EventTypePRFromRefUpdated)}
+	}
+
+	resp := &webhook{}
+	_, err := c.Post(apiPath("/projects/%s/repos/%s/webhooks", url.PathEscape(projectKey), url.PathEscape(repo)), httpclient.SetBody(&webhook{
+		Name:   "zadig",
+		URL:    hook.URL,
+		Active: true,
+		Events: events,
+		Configuration: map[string]string{
+			"secret": hook.Secret,
+		},
+	}), httpclient.SetResult(resp))
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(resp.ID), nil
+}
+
+func (c *Client) DeleteHook(projectKey, repo, hookID string) error {
+	_, err := c.Delete(apiPath("/projects/%s/repos/%s/webhooks/%s", url.PathEscape(projectKey), url.PathEscape(repo), hookID))
+	if httpclient.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
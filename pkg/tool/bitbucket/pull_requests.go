@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"net/url"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type PullRequest struct {
+	ID          int                `json:"id"`
+	Version     int                `json:"version"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	State       string             `json:"state"`
+	Open        bool               `json:"open"`
+	CreatedDate int64              `json:"createdDate"`
+	UpdatedDate int64              `json:"updatedDate"`
+	FromRef     *PullRequestRef    `json:"fromRef"`
+	ToRef       *PullRequestRef    `json:"toRef"`
+	Author      *PullRequestAuthor `json:"author"`
+	Links       map[string][]*Link `json:"links"`
+}
+
+type PullRequestRef struct {
+	ID           string      `json:"id"`
+	DisplayID    string      `json:"displayId"`
+	LatestCommit string      `json:"latestCommit"`
+	Repository   *Repository `json:"repository"`
+}
+
+type PullRequestAuthor struct {
+	User *User `json:"user"`
+}
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+type pullRequestPage struct {
+	page
+	Values []*PullRequest `json:"values"`
+}
+
+type Comment struct {
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// BuildState is the state of a build status
+type BuildState string
+
+const (
+	BuildStateInProgress BuildState = "INPROGRESS"
+	BuildStateSuccessful BuildState = "SUCCESSFUL"
+	BuildStateFailed     BuildState = "FAILED"
+)
+
+type BuildStatus struct {
+	State       BuildState `json:"state"`
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+}
+
+// HTMLURL returns the link of the pull request page
+func (pr *PullRequest) HTMLURL() string {
+	if links := pr.Links["self"]; len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// ListPullRequests lists the pull requests of the repository, state can be OPEN, DECLINED, MERGED or ALL
+func (c *Client) ListPullRequests(projectKey, repo, state string, opts *ListOptions) ([]*PullRequest, error) {
+	var res []*PullRequest
+	err := paginated(opts, func(params map[string]string) (*page, error) {
+		if state != "" {
+			params["state"] = state
+		}
+		p := &pullRequestPage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/pull-requests", url.PathEscape(projectKey), url.PathEscape(repo)), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+func (c *Client) ListPullRequestChanges(projectKey, repo string, id int) ([]*Change, error) {
+	var res []*Change
+	err := paginated(nil, func(params map[string]string) (*page, error) {
+		p := &changePage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/pull-requests/%d/changes", url.PathEscape(projectKey), url.PathEscape(repo), id), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+func (c *Client) CreatePullRequestComment(projectKey, repo string, id int, text string) (*Comment, error) {
+	comment := &Comment{}
+	_, err := c.Post(apiPath("/projects/%s/repos/%s/pull-requests/%d/comments", url.PathEscape(projectKey), url.PathEscape(repo), id), httpclient.SetBody(map[string]string{"text": text}), httpclient.SetResult(comment))
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// UpdatePullRequestComment updates the text of the comment, the latest version of the comment is required by bitbucket
+func (c *Client) UpdatePullRequestComment(projectKey, repo string, id, commentID int, text string) error {
+	path := apiPath("/projects/%s/repos/%s/pull-requests/%d/comments/%d", url.PathEscape(projectKey), url.PathEscape(repo), id, commentID)
+	comment := &Comment{}
+	if _, err := c.Get(path, httpclient.SetResult(comment)); err != nil {
+		return err
+	}
+
+	_, err := c.Put(path, httpclient.SetBody(&Comment{Version: comment.Version, Text: text}))
+	return err
+}
+
+// CreateBuildStatus sets the build status of the commit, the status with the same key is overwritten
+func (c *Client) CreateBuildStatus(commitID string, status *BuildStatus) error {
+	_, err := c.Post("/rest/build-status/1.0/commits/"+url.PathEscape(commitID), httpclient.SetBody(status))
+	return err
+}
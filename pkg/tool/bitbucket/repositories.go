@@ -0,0 +1,216 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type User struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	ID           int    `json:"id"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug"`
+}
+
+type Project struct {
+	ID          int    `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Type        string `json:"type"`
+}
+
+type Repository struct {
+	ID       int      `json:"id"`
+	Slug     string   `json:"slug"`
+	Name     string   `json:"name"`
+	Project  *Project `json:"project"`
+	Public   bool     `json:"public"`
+	ScmID    string   `json:"scmId"`
+	Archived bool     `json:"archived"`
+}
+
+// Ref is a branch or a tag
+type Ref struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	Type         string `json:"type"`
+	LatestCommit string `json:"latestCommit"`
+	IsDefault    bool   `json:"isDefault"`
+}
+
+type Commit struct {
+	ID                 string `json:"id"`
+	DisplayID          string `json:"displayId"`
+	Message            string `json:"message"`
+	Author             *User  `json:"author"`
+	AuthorTimestamp    int64  `json:"authorTimestamp"`
+	Committer          *User  `json:"committer"`
+	CommitterTimestamp int64  `json:"committerTimestamp"`
+}
+
+type Change struct {
+	Path    *Path  `json:"path"`
+	SrcPath *Path  `json:"srcPath"`
+	Type    string `json:"type"`
+}
+
+type Path struct {
+	ToString string `json:"toString"`
+}
+
+type projectPage struct {
+	page
+	Values []*Project `json:"values"`
+}
+
+type repositoryPage struct {
+	page
+	Values []*Repository `json:"values"`
+}
+
+type refPage struct {
+	page
+	Values []*Ref `json:"values"`
+}
+
+type commitPage struct {
+	page
+	Values []*Commit `json:"values"`
+}
+
+type changePage struct {
+	page
+	Values []*Change `json:"values"`
+}
+
+type filePage struct {
+	page
+	Values []string `json:"values"`
+}
+
+// ListProjects lists the projects visible to the user, the personal projects are not included
+func (c *Client) ListProjects(opts *ListOptions) ([]*Project, error) {
+	var res []*Project
+	err := paginated(opts, func(params map[string]string) (*page, error) {
+		p := &projectPage{}
+		_, err := c.Get(apiPath("/projects"), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+// ListRepositories lists the repositories of the project whose name contains the keyword,
+// the key of a personal project is ~USERNAME
+func (c *Client) ListRepositories(projectKey, keyword string, opts *ListOptions) ([]*Repository, error) {
+	var res []*Repository
+	err := paginated(opts, func(params map[string]string) (*page, error) {
+		p := &repositoryPage{}
+		_, err := c.Get(apiPath("/projects/%s/repos", url.PathEscape(projectKey)), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		for _, repo := range p.Values {
+			if keyword == "" || strings.Contains(strings.ToLower(repo.Name), strings.ToLower(keyword)) {
+				res = append(res, repo)
+			}
+		}
+		return &p.page, err
+	})
+	return res, err
+}
+
+func (c *Client) listRefs(projectKey, repo, kind, filter string, opts *ListOptions) ([]*Ref, error) {
+	var res []*Ref
+	err := paginated(opts, func(params map[string]string) (*page, error) {
+		if filter != "" {
+			params["filterText"] = filter
+		}
+		p := &refPage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/%s", url.PathEscape(projectKey), url.PathEscape(repo), kind), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+func (c *Client) ListBranches(projectKey, repo, filter string, opts *ListOptions) ([]*Ref, error) {
+	return c.listRefs(projectKey, repo, "branches", filter, opts)
+}
+
+func (c *Client) ListTags(projectKey, repo, filter string, opts *ListOptions) ([]*Ref, error) {
+	return c.listRefs(projectKey, repo, "tags", filter, opts)
+}
+
+// ListCommits lists the commits reachable from the ref, the latest commit comes first
+func (c *Client) ListCommits(projectKey, repo, ref string, opts *ListOptions) ([]*Commit, error) {
+	var res []*Commit
+	err := paginated(opts, func(params map[string]string) (*page, error) {
+		params["until"] = ref
+		p := &commitPage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/commits", url.PathEscape(projectKey), url.PathEscape(repo)), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+// CompareChanges lists the files changed between the two commits
+func (c *Client) CompareChanges(projectKey, repo, from, to string) ([]*Change, error) {
+	var res []*Change
+	err := paginated(nil, func(params map[string]string) (*page, error) {
+		params["from"] = from
+		params["to"] = to
+		p := &changePage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/compare/changes", url.PathEscape(projectKey), url.PathEscape(repo)), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+// ListFiles lists the files under the path recursively
+func (c *Client) ListFiles(projectKey, repo, path, ref string) ([]string, error) {
+	var res []string
+	err := paginated(nil, func(params map[string]string) (*page, error) {
+		if ref != "" {
+			params["at"] = ref
+		}
+		p := &filePage{}
+		_, err := c.Get(apiPath("/projects/%s/repos/%s/files/%s", url.PathEscape(projectKey), url.PathEscape(repo), path), httpclient.SetQueryParams(params), httpclient.SetResult(p))
+		res = append(res, p.Values...)
+		return &p.page, err
+	})
+	return res, err
+}
+
+// GetRawFile returns the raw content of the file at the ref
+func (c *Client) GetRawFile(projectKey, repo, path, ref string) ([]byte, error) {
+	params := map[string]string{}
+	if ref != "" {
+		params["at"] = ref
+	}
+	resp, err := c.Get(apiPath("/projects/%s/repos/%s/raw/%s", url.PathEscape(projectKey), url.PathEscape(repo), path), httpclient.SetQueryParams(params))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body(), nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatGo        = "go"
+	FormatCobertura = "cobertura"
+	FormatJaCoCo    = "jacoco"
+	FormatLCOV      = "lcov"
+)
+
+// Formats lists the supported coverage report formats.
+var Formats = []string{FormatGo, FormatCobertura, FormatJaCoCo, FormatLCOV}
+
+// Profile is the line and branch coverage of the source files, normalised from the reports of
+// any supported format.
+type Profile struct {
+	Formats []string
+	Files   map[string]*FileProfile
+}
+
+type FileProfile struct {
+	// Lines maps the instrumented line numbers to their hit counts
+	Lines map[int]int64
+	// Branches maps the line numbers to the covered and total number of branches on them
+	Branches map[int][2]int
+}
+
+type Summary struct {
+	LinesCovered    int     `bson:"lines_covered"    json:"lines_covered"`
+	LinesTotal      int     `bson:"lines_total"      json:"lines_total"`
+	LineRate        float64 `bson:"line_rate"        json:"line_rate"`
+	BranchesCovered int     `bson:"branches_covered" json:"branches_covered"`
+	BranchesTotal   int     `bson:"branches_total"   json:"branches_total"`
+	BranchRate      float64 `bson:"branch_rate"      json:"branch_rate"`
+}
+
+type FileSummary struct {
+	Path    string   `bson:"path"    json:"path"`
+	Summary *Summary `bson:"summary" json:"summary"`
+}
+
+// Report is the result of a coverage step, rates are in percent.
+type Report struct {
+	Formats []string       `bson:"formats"    json:"formats"`
+	Summary *Summary       `bson:"summary"    json:"summary"`
+	Files   []*FileSummary `bson:"files"      json:"files,omitempty"`
+	// Diff is the coverage of the files changed by the pull request, it is nil if the task is not triggered by one
+	Diff      *Summary       `bson:"diff"       json:"diff,omitempty"`
+	DiffFiles []*FileSummary `bson:"diff_files" json:"diff_files,omitempty"`
+}
+
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect guesses the format of a coverage report by its content, it returns an empty string if the
+// content is not a supported report.
+func Detect(data []byte) string {
+	content := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(content, []byte("mode:")):
+		return FormatGo
+	case bytes.HasPrefix(content, []byte("TN:")) || bytes.HasPrefix(content, []byte("SF:")):
+		return FormatLCOV
+	case bytes.HasPrefix(content, []byte("<")):
+		head := content
+		if len(head) > 2048 {
+			head = head[:2048]
+		}
+		if bytes.Contains(head, []byte("<report")) {
+			return FormatJaCoCo
+		}
+		if bytes.Contains(head, []byte("<coverage")) {
+			return FormatCobertura
+		}
+	}
+	return ""
+}
+
+// Parse adds the coverage in the report of the given format to the profile, the format is detected
+// if it is empty.
+func (p *Profile) Parse(data []byte, format string) error {
+	if format == "" {
+		format = Detect(data)
+	}
+	var err error
+	switch format {
+	case FormatGo:
+		err = parseGo(p, data)
+	case FormatCobertura:
+		err = parseCobertura(p, data)
+	case FormatJaCoCo:
+		err = parseJaCoCo(p, data)
+	case FormatLCOV:
+		err = parseLCOV(p, data)
+	default:
+		return fmt.Errorf("unsupported coverage format")
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range p.Formats {
+		if f == format {
+			return nil
+		}
+	}
+	p.Formats = append(p.Formats, format)
+	return nil
+}
+
+func NewProfile() *Profile {
+	return &Profile{Files: make(map[string]*FileProfile)}
+}
+
+// Collect parses the coverage report at path, or all the reports in it if it is a directory. Files
+// in a directory which are not coverage reports are skipped.
+func Collect(reportPath, format string) (*Profile, []string, error) {
+	info, err := os.Stat(reportPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile := NewProfile()
+	if !info.IsDir() {
+		data, err := os.ReadFile(reportPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := profile.Parse(data, format); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %s", reportPath, err)
+		}
+		return profile, []string{reportPath}, nil
+	}
+
+	files := make([]string, 0)
+	err = filepath.WalkDir(reportPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fileFormat := Detect(data)
+		if fileFormat == "" || (format != "" && fileFormat != format) {
+			return nil
+		}
+		if err := profile.Parse(data, fileFormat); err != nil {
+			return fmt.Errorf("failed to parse %s: %s", p, err)
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no coverage report found in %s", reportPath)
+	}
+	return profile, files, nil
+}
+
+func (p *Profile) file(name string) *FileProfile {
+	name = path.Clean(filepath.ToSlash(name))
+	f, ok := p.Files[name]
+	if !ok {
+		f = &FileProfile{Lines: make(map[int]int64), Branches: make(map[int][2]int)}
+		p.Files[name] = f
+	}
+	return f
+}
+
+// addLine merges the hits of a line, the same line may be reported by several reports or blocks
+func (f *FileProfile) addLine(line int, hits int64) {
+	if current, ok := f.Lines[line]; !ok || hits > current {
+		f.Lines[line] = hits
+	}
+}
+
+func (f *FileProfile) addBranches(line, covered, total int) {
+	if total == 0 {
+		return
+	}
+	current := f.Branches[line]
+	if covered > current[0] {
+		current[0] = covered
+	}
+	if total > current[1] {
+		current[1] = total
+	}
+	f.Branches[line] = current
+}
+
+func (f *FileProfile) summary() *Summary {
+	s := &Summary{}
+	for _, hits := range f.Lines {
+		s.LinesTotal++
+		if hits > 0 {
+			s.LinesCovered++
+		}
+	}
+	for _, branches := range f.Branches {
+		s.BranchesCovered += branches[0]
+		s.BranchesTotal += branches[1]
+	}
+	s.calculate()
+	return s
+}
+
+func (s *Summary) add(other *Summary) {
+	s.LinesCovered += other.LinesCovered
+	s.LinesTotal += other.LinesTotal
+	s.BranchesCovered += other.BranchesCovered
+	s.BranchesTotal += other.BranchesTotal
+}
+
+func (s *Summary) calculate() {
+	s.LineRate = rate(s.LinesCovered, s.LinesTotal)
+	s.BranchRate = rate(s.BranchesCovered, s.BranchesTotal)
+}
+
+func rate(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(covered)*10000/float64(total)) / 100
+}
+
+// NewReport summarises the profile, the diff coverage is calculated over the changed files if there
+// are any. The paths of the changed files are relative to the repository while those in the reports
+// can be import paths or relative to a source root, so they are matched by suffix.
+func NewReport(profile *Profile, changedFiles []string) *Report {
+	report := &Report{
+		Formats: profile.Formats,
+		Summary: &Summary{},
+		Files:   make([]*FileSummary, 0, len(profile.Files)),
+	}
+	if len(changedFiles) > 0 {
+		report.Diff = &Summary{}
+		report.DiffFiles = make([]*FileSummary, 0)
+	}
+	for name, f := range profile.Files {
+		fileSummary := &FileSummary{Path: name, Summary: f.summary()}
+		report.Files = append(report.Files, fileSummary)
+		report.Summary.add(fileSummary.Summary)
+		if report.Diff != nil && changed(name, changedFiles) {
+			report.DiffFiles = append(report.DiffFiles, fileSummary)
+			report.Diff.add(fileSummary.Summary)
+		}
+	}
+	report.Summary.calculate()
+	sortFiles(report.Files)
+	if report.Diff != nil {
+		report.Diff.calculate()
+		sortFiles(report.DiffFiles)
+	}
+	return report
+}
+
+func sortFiles(files []*FileSummary) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+}
+
+func changed(name string, changedFiles []string) bool {
+	for _, file := range changedFiles {
+		file = path.Clean(filepath.ToSlash(file))
+		if name == file || strings.HasSuffix(name, "/"+file) || strings.HasSuffix(file, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the violations of the minimum coverages, a zero minimum is not checked. Branch
+// coverage is checked only if the reports have branches, and diff coverage only if the changed
+// files have instrumented lines.
+func (r *Report) Check(minLine, minBranch, minDiff float64) []string {
+	violations := make([]string, 0)
+	if minLine > 0 && r.Summary.LineRate < minLine {
+		violations = append(violations, fmt.Sprintf("line coverage %.2f%% is below %.2f%%", r.Summary.LineRate, minLine))
+	}
+	if minBranch > 0 && r.Summary.BranchesTotal > 0 && r.Summary.BranchRate < minBranch {
+		violations = append(violations, fmt.Sprintf("branch coverage %.2f%% is below %.2f%%", r.Summary.BranchRate, minBranch))
+	}
+	if minDiff > 0 && r.Diff != nil && r.Diff.LinesTotal > 0 && r.Diff.LineRate < minDiff {
+		violations = append(violations, fmt.Sprintf("diff coverage %.2f%% is below %.2f%%", r.Diff.LineRate, minDiff))
+	}
+	return violations
+}
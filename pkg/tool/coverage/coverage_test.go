@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testGoProfile = `mode: set
+github.com/koderover/zadig/pkg/util/a.go:3.10,5.2 2 1
+github.com/koderover/zadig/pkg/util/a.go:7.10,8.2 1 0
+github.com/koderover/zadig/pkg/util/b.go:1.10,2.2 1 1
+`
+
+const testCoberturaReport = `<?xml version="1.0" ?>
+<coverage line-rate="0.5" branch-rate="0.5" version="1.9">
+  <sources><source>/src</source></sources>
+  <packages>
+    <package name="app">
+      <classes>
+        <class name="main.py" filename="app/main.py">
+          <methods/>
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+            <line number="3" hits="2" branch="true" condition-coverage="50% (1/2)"/>
+            <line number="4" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+const testJaCoCoReport = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="demo">
+  <group name="module">
+    <package name="com/example">
+      <class name="com/example/Foo" sourcefilename="Foo.java"/>
+      <sourcefile name="Foo.java">
+        <line nr="3" mi="0" ci="3" mb="0" cb="0"/>
+        <line nr="5" mi="2" ci="0" mb="1" cb="1"/>
+        <line nr="6" mi="0" ci="0" mb="0" cb="0"/>
+      </sourcefile>
+    </package>
+  </group>
+</report>`
+
+const testLCOVReport = `TN:
+SF:src/index.js
+DA:1,1
+DA:2,0
+DA:3,5
+BRDA:3,0,0,1
+BRDA:3,0,1,-
+end_of_record
+`
+
+func TestParse(t *testing.T) {
+	ast := require.New(t)
+
+	ast.Equal(FormatGo, Detect([]byte(testGoProfile)))
+	ast.Equal(FormatCobertura, Detect([]byte(testCoberturaReport)))
+	ast.Equal(FormatJaCoCo, Detect([]byte(testJaCoCoReport)))
+	ast.Equal(FormatLCOV, Detect([]byte(testLCOVReport)))
+	ast.Equal("", Detect([]byte(`<testsuite name="junit"/>`)))
+
+	cases := []struct {
+		format  string
+		report  string
+		summary Summary
+	}{
+		{FormatGo, testGoProfile, Summary{LinesCovered: 5, LinesTotal: 7, LineRate: 71.43}},
+		{FormatCobertura, testCoberturaReport, Summary{LinesCovered: 2, LinesTotal: 4, LineRate: 50, BranchesCovered: 1, BranchesTotal: 2, BranchRate: 50}},
+		{FormatJaCoCo, testJaCoCoReport, Summary{LinesCovered: 1, LinesTotal: 2, LineRate: 50, BranchesCovered: 1, BranchesTotal: 2, BranchRate: 50}},
+		{FormatLCOV, testLCOVReport, Summary{LinesCovered: 2, LinesTotal: 3, LineRate: 66.67, BranchesCovered: 1, BranchesTotal: 2, BranchRate: 50}},
+	}
+	for _, c := range cases {
+		profile := NewProfile()
+		ast.Nil(profile.Parse([]byte(c.report), ""), c.format)
+		report := NewReport(profile, nil)
+		ast.Equal([]string{c.format}, report.Formats)
+		ast.Equal(c.summary, *report.Summary, c.format)
+		ast.Nil(report.Diff)
+	}
+
+	ast.NotNil(NewProfile().Parse([]byte("mode: set\nbroken"), FormatGo))
+}
+
+func TestReportDiffAndCheck(t *testing.T) {
+	ast := require.New(t)
+
+	profile := NewProfile()
+	ast.Nil(profile.Parse([]byte(testGoProfile), FormatGo))
+	ast.Nil(profile.Parse([]byte(testJaCoCoReport), FormatJaCoCo))
+	ast.ElementsMatch([]string{FormatGo, FormatJaCoCo}, profile.Formats)
+
+	report := NewReport(profile, []string{"pkg/util/a.go", "src/main/java/com/example/Foo.java", "README.md"})
+	ast.Len(report.Files, 3)
+	ast.Len(report.DiffFiles, 2)
+	ast.Equal("com/example/Foo.java", report.DiffFiles[0].Path)
+	ast.Equal("github.com/koderover/zadig/pkg/util/a.go", report.DiffFiles[1].Path)
+	ast.Equal(Summary{LinesCovered: 4, LinesTotal: 7, LineRate: 57.14, BranchesCovered: 1, BranchesTotal: 2, BranchRate: 50}, *report.Diff)
+
+	ast.Empty(report.Check(50, 50, 50))
+	ast.Len(report.Check(80, 0, 60), 2)
+	ast.Len(report.Check(0, 60, 0), 1)
+
+	noDiff := NewReport(profile, []string{"README.md"})
+	ast.Equal(0, noDiff.Diff.LinesTotal)
+	ast.Empty(noDiff.Check(0, 0, 90))
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseGo parses the profile written by go test -coverprofile, every line is a block in the form of
+// name.go:line.column,line.column statements count
+func parseGo(p *Profile, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		sep := strings.LastIndex(line, ":")
+		if sep < 0 {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		fields := strings.Fields(line[sep+1:])
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		positions := strings.Split(fields[0], ",")
+		if len(positions) != 2 {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		start, err := strconv.Atoi(strings.Split(positions[0], ".")[0])
+		if err != nil {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		end, err := strconv.Atoi(strings.Split(positions[1], ".")[0])
+		if err != nil {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid go coverage block: %s", line)
+		}
+		f := p.file(line[:sep])
+		for i := start; i <= end; i++ {
+			f.addLine(i, count)
+		}
+	}
+	return scanner.Err()
+}
+
+type coberturaReport struct {
+	Packages []struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number            int    `xml:"number,attr"`
+				Hits              int64  `xml:"hits,attr"`
+				Branch            bool   `xml:"branch,attr"`
+				ConditionCoverage string `xml:"condition-coverage,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// conditionCoverage matches the condition coverage of cobertura lines like 50% (1/2)
+var conditionCoverage = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+func parseCobertura(p *Profile, data []byte) error {
+	report := &coberturaReport{}
+	if err := xml.Unmarshal(data, report); err != nil {
+		return err
+	}
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			f := p.file(class.Filename)
+			for _, line := range class.Lines {
+				f.addLine(line.Number, line.Hits)
+				if !line.Branch {
+					continue
+				}
+				if match := conditionCoverage.FindStringSubmatch(line.ConditionCoverage); match != nil {
+					covered, _ := strconv.Atoi(match[1])
+					total, _ := strconv.Atoi(match[2])
+					f.addBranches(line.Number, covered, total)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type jacocoGroup struct {
+	Groups   []*jacocoGroup `xml:"group"`
+	Packages []struct {
+		Name        string `xml:"name,attr"`
+		SourceFiles []struct {
+			Name  string `xml:"name,attr"`
+			Lines []struct {
+				Number             int `xml:"nr,attr"`
+				MissedInstruction  int `xml:"mi,attr"`
+				CoveredInstruction int `xml:"ci,attr"`
+				MissedBranches     int `xml:"mb,attr"`
+				CoveredBranches    int `xml:"cb,attr"`
+			} `xml:"line"`
+		} `xml:"sourcefile"`
+	} `xml:"package"`
+}
+
+func parseJaCoCo(p *Profile, data []byte) error {
+	report := &jacocoGroup{}
+	if err := xml.Unmarshal(data, report); err != nil {
+		return err
+	}
+	addJaCoCoGroup(p, report)
+	return nil
+}
+
+func addJaCoCoGroup(p *Profile, group *jacocoGroup) {
+	for _, subGroup := range group.Groups {
+		addJaCoCoGroup(p, subGroup)
+	}
+	for _, pkg := range group.Packages {
+		for _, source := range pkg.SourceFiles {
+			f := p.file(pkg.Name + "/" + source.Name)
+			for _, line := range source.Lines {
+				if line.MissedInstruction+line.CoveredInstruction == 0 {
+					continue
+				}
+				f.addLine(line.Number, int64(line.CoveredInstruction))
+				f.addBranches(line.Number, line.CoveredBranches, line.MissedBranches+line.CoveredBranches)
+			}
+		}
+	}
+}
+
+// parseLCOV parses the tracefile of lcov, only the records of the source files, lines and branches are used
+func parseLCOV(p *Profile, data []byte) error {
+	var f *FileProfile
+	branches := make(map[int][2]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "SF":
+			f = p.file(value)
+			branches = make(map[int][2]int)
+		case "DA":
+			if f == nil {
+				return fmt.Errorf("lcov record without source file: %s", line)
+			}
+			fields := strings.Split(value, ",")
+			if len(fields) < 2 {
+				return fmt.Errorf("invalid lcov line record: %s", line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return fmt.Errorf("invalid lcov line record: %s", line)
+			}
+			hits, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid lcov line record: %s", line)
+			}
+			f.addLine(number, hits)
+		case "BRDA":
+			if f == nil {
+				return fmt.Errorf("lcov record without source file: %s", line)
+			}
+			fields := strings.Split(value, ",")
+			if len(fields) != 4 {
+				return fmt.Errorf("invalid lcov branch record: %s", line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return fmt.Errorf("invalid lcov branch record: %s", line)
+			}
+			count := branches[number]
+			count[1]++
+			if fields[3] != "-" && fields[3] != "0" {
+				count[0]++
+			}
+			branches[number] = count
+		case "end_of_record":
+			if f != nil {
+				for number, count := range branches {
+					f.addBranches(number, count[0], count[1])
+				}
+			}
+			f = nil
+		}
+	}
+	return scanner.Err()
+}
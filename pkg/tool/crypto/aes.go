@@ -22,41 +22,99 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"path"
 	"strings"
 	"sync"
 
 	fsutil "github.com/koderover/zadig/pkg/util/fs"
 )
 
-const aesKeyFile = "etc/encryption/aes"
+const (
+	aesKeyDir = "etc/encryption"
+	// aesKeyFile is the legacy key, ciphertexts encrypted by it have no key ID
+	aesKeyFile = "etc/encryption/aes"
+	// versioned keys are mounted as aes.<key id> next to the legacy key, and aes.primary
+	// contains the ID of the key used for encryption. The legacy key is used if it is not set.
+	aesVersionedKeyPrefix = "aes."
+	aesPrimaryKeyFile     = "etc/encryption/aes.primary"
+	// keyIDSeparator separates the key ID and the hex encoded ciphertext
+	keyIDSeparator = ":"
+)
 
 type Aes struct {
 	block cipher.Block
 }
 
-var aesKey string
+type keyring struct {
+	primaryID string
+	// keys is indexed by key ID, the legacy key has an empty ID
+	keys map[string]string
+}
+
+var aesKeys *keyring
 var once sync.Once
 
-func getAESKey() string {
+func loadKeyring(fsys fs.FS) (*keyring, error) {
+	kr := &keyring{keys: make(map[string]string)}
+
+	if keyByte, err := fs.ReadFile(fsys, aesKeyFile); err == nil {
+		kr.keys[""] = strings.TrimSpace(string(keyByte))
+	}
+	// the key directory is missing if no key is mounted, the error below tells that the key is not found
+	entries, err := fs.ReadDir(fsys, aesKeyDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, aesVersionedKeyPrefix) || name == path.Base(aesPrimaryKeyFile) {
+			continue
+		}
+		keyID := strings.TrimPrefix(name, aesVersionedKeyPrefix)
+		if keyID == "" || strings.Contains(keyID, keyIDSeparator) {
+			return nil, fmt.Errorf("invalid aes key id %q", keyID)
+		}
+		keyByte, err := fs.ReadFile(fsys, path.Join(aesKeyDir, name))
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[keyID] = strings.TrimSpace(string(keyByte))
+	}
+	if primaryByte, err := fs.ReadFile(fsys, aesPrimaryKeyFile); err == nil {
+		kr.primaryID = strings.TrimSpace(string(primaryByte))
+	}
+
+	if _, ok := kr.keys[kr.primaryID]; !ok {
+		return nil, fmt.Errorf("primary aes key %q not found", kr.primaryID)
+	}
+	return kr, nil
+}
+
+func getKeyring() *keyring {
 	once.Do(func() {
-		keyByte, err := fs.ReadFile(fsutil.Root(), aesKeyFile)
+		kr, err := loadKeyring(fsutil.Root())
 		if err != nil {
-			panic("Failed to read aes key from secret")
+			panic(fmt.Sprintf("Failed to read aes key from secret: %s", err))
 		}
-		aesKey = strings.TrimSpace(string(keyByte))
+		aesKeys = kr
 	})
 
-	return aesKey
+	return aesKeys
 }
 
-func GetAesKey() string {
-	return getAESKey()
+// splitKeyID splits a ciphertext into the key ID and the hex encoded data
+func splitKeyID(src string) (string, string) {
+	if i := strings.Index(src, keyIDSeparator); i >= 0 {
+		return src[:i], src[i+1:]
+	}
+	return "", src
 }
 
-func AesEncrypt(src string) (string, error) {
-	client, err := NewAes(getAESKey())
+func (k *keyring) encrypt(src string) (string, error) {
+	client, err := NewAes(k.keys[k.primaryID])
 	if err != nil {
 		return "", err
 	}
@@ -64,7 +122,70 @@ func AesEncrypt(src string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return dest, nil
+	if k.primaryID == "" {
+		return dest, nil
+	}
+	return k.primaryID + keyIDSeparator + dest, nil
+}
+
+func (k *keyring) decrypt(src string) (string, error) {
+	keyID, data := splitKeyID(src)
+	key, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("aes key %q not found", keyID)
+	}
+	client, err := NewAes(key)
+	if err != nil {
+		return "", err
+	}
+	return client.Decrypt(data)
+}
+
+func (k *keyring) reEncrypt(src string) (string, bool, error) {
+	if keyID, _ := splitKeyID(src); keyID == k.primaryID {
+		return src, false, nil
+	}
+	plaintext, err := k.decrypt(src)
+	if err != nil {
+		return "", false, err
+	}
+	dest, err := k.encrypt(plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return dest, true, nil
+}
+
+func getAESKey() string {
+	kr := getKeyring()
+	return kr.keys[kr.primaryID]
+}
+
+// GetAesKey returns the primary key
+func GetAesKey() string {
+	return getAESKey()
+}
+
+// PrimaryAesKeyID returns the ID of the key used for encryption, it is empty for the legacy key
+func PrimaryAesKeyID() string {
+	return getKeyring().primaryID
+}
+
+// AesKeyID returns the ID of the key which the ciphertext is encrypted by
+func AesKeyID(src string) string {
+	keyID, _ := splitKeyID(src)
+	return keyID
+}
+
+// AesEncrypt encrypts the src with the primary key, the key ID is prefixed to the ciphertext
+func AesEncrypt(src string) (string, error) {
+	return getKeyring().encrypt(src)
+}
+
+// AesReEncrypt re-encrypts the ciphertext with the primary key, the ciphertext is returned as is
+// if it is already encrypted by the primary key. The bool result reports whether it is changed.
+func AesReEncrypt(src string) (string, bool, error) {
+	return getKeyring().reEncrypt(src)
 }
 
 func AesEncryptByKey(src, aesKey string) (string, error) {
@@ -79,20 +200,24 @@ func AesEncryptByKey(src, aesKey string) (string, error) {
 	return dest, nil
 }
 
-func AesDecrypt(src string, aesKey ...string) (string, error) {
-	var (
-		err    error
-		client *Aes
-	)
-	if len(aesKey) > 0 {
-		client, err = NewAes(aesKey[0])
-	} else {
-		client, err = NewAes(getAESKey())
+// AesDecrypt decrypts the src with the key that the key ID of the src refers to
+func AesDecrypt(src string) (string, error) {
+	return getKeyring().decrypt(src)
+}
+
+// AesDecryptByKey decrypts the src with the given key, keyID is the ID of the key as returned by PrimaryAesKeyID.
+// It fails if the src is encrypted by another key, since the decryption would return garbage instead of an error.
+func AesDecryptByKey(src, keyID, aesKey string) (string, error) {
+	srcKeyID, data := splitKeyID(src)
+	if srcKeyID != keyID {
+		return "", fmt.Errorf("the ciphertext is encrypted by aes key %q instead of %q", srcKeyID, keyID)
 	}
+
+	client, err := NewAes(aesKey)
 	if err != nil {
 		return "", err
 	}
-	dest, err := client.Decrypt(src)
+	dest, err := client.Decrypt(data)
 	if err != nil {
 		return "", err
 	}
@@ -17,7 +17,9 @@ limitations under the License.
 package crypto
 
 import (
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -35,3 +37,80 @@ func TestAes_Crypt(t *testing.T) {
 	ast.Nil(err)
 	ast.Equal("hello", decrypted)
 }
+
+func TestKeyring_Rotate(t *testing.T) {
+	ast := require.New(t)
+
+	legacy := &fstest.MapFS{
+		"etc/encryption/aes": {Data: []byte("0123456789abcdef\n")},
+	}
+	kr, err := loadKeyring(legacy)
+	ast.Nil(err)
+
+	legacyEncrypted, err := kr.encrypt("hello")
+	ast.Nil(err)
+	ast.False(strings.Contains(legacyEncrypted, keyIDSeparator))
+
+	rotated := &fstest.MapFS{
+		"etc/encryption/aes":         {Data: []byte("0123456789abcdef\n")},
+		"etc/encryption/aes.v2":      {Data: []byte("fedcba9876543210")},
+		"etc/encryption/aes.primary": {Data: []byte("v2\n")},
+	}
+	kr, err = loadKeyring(rotated)
+	ast.Nil(err)
+	ast.Equal("v2", kr.primaryID)
+
+	decrypted, err := kr.decrypt(legacyEncrypted)
+	ast.Nil(err)
+	ast.Equal("hello", decrypted)
+
+	reEncrypted, changed, err := kr.reEncrypt(legacyEncrypted)
+	ast.Nil(err)
+	ast.True(changed)
+	ast.True(strings.HasPrefix(reEncrypted, "v2:"))
+
+	again, changed, err := kr.reEncrypt(reEncrypted)
+	ast.Nil(err)
+	ast.False(changed)
+	ast.Equal(reEncrypted, again)
+
+	decrypted, err = kr.decrypt(reEncrypted)
+	ast.Nil(err)
+	ast.Equal("hello", decrypted)
+
+	decrypted, err = AesDecryptByKey(reEncrypted, "v2", "fedcba9876543210")
+	ast.Nil(err)
+	ast.Equal("hello", decrypted)
+
+	// the key ID of the ciphertext must match the given key
+	_, err = AesDecryptByKey(reEncrypted, "", "0123456789abcdef")
+	ast.NotNil(err)
+	_, err = AesDecryptByKey(legacyEncrypted, "v2", "fedcba9876543210")
+	ast.NotNil(err)
+	decrypted, err = AesDecryptByKey(legacyEncrypted, "", "0123456789abcdef")
+	ast.Nil(err)
+	ast.Equal("hello", decrypted)
+
+	_, err = kr.decrypt("v3:" + strings.TrimPrefix(reEncrypted, "v2:"))
+	ast.NotNil(err)
+
+	_, err = loadKeyring(&fstest.MapFS{
+		"etc/encryption/aes":         {Data: []byte("0123456789abcdef")},
+		"etc/encryption/aes.primary": {Data: []byte("v2")},
+	})
+	ast.NotNil(err)
+}
+
+func TestLoadKeyring_MissingDir(t *testing.T) {
+	ast := require.New(t)
+
+	_, err := loadKeyring(&fstest.MapFS{})
+	ast.NotNil(err)
+	ast.Contains(err.Error(), "not found")
+
+	_, err = loadKeyring(&fstest.MapFS{
+		"etc/other": {Data: []byte("other")},
+	})
+	ast.NotNil(err)
+	ast.Contains(err.Error(), "not found")
+}
@@ -46,6 +46,12 @@ var (
 	ErrFindUser = NewHTTPError(6002, "获取用户信息失败")
 	// ErrCallBackUser ...
 	ErrCallBackUser = NewHTTPError(6003, "dex回调用户失败")
+	// ErrCreateAccessToken ...
+	ErrCreateAccessToken = NewHTTPError(6004, "创建访问令牌失败")
+	// ErrListAccessTokens ...
+	ErrListAccessTokens = NewHTTPError(6005, "列出访问令牌失败")
+	// ErrDeleteAccessToken ...
+	ErrDeleteAccessToken = NewHTTPError(6006, "撤销访问令牌失败")
 	//-----------------------------------------------------------------------------------------------
 	// Team APIs Range: 6020 - 6039
 	//-----------------------------------------------------------------------------------------------
@@ -903,4 +909,42 @@ var (
 	ErrGetBizDirServiceDetail  = NewHTTPError(7042, "获取业务目录服务详情失败")
 	ErrSearchBizDirByProject   = NewHTTPError(7043, "根据项目搜索业务目录失败")
 	ErrSearchBizDirByService   = NewHTTPError(7044, "根据服务搜索业务目录失败")
+
+	//-----------------------------------------------------------------------------------------------
+	// deploy freeze Error Range: 7050 - 7059
+	//-----------------------------------------------------------------------------------------------
+	ErrCreateDeployFreeze = NewHTTPError(7050, "创建封网日历失败")
+	ErrListDeployFreeze   = NewHTTPError(7051, "获取封网日历列表失败")
+	ErrUpdateDeployFreeze = NewHTTPError(7052, "更新封网日历失败")
+	ErrDeleteDeployFreeze = NewHTTPError(7053, "删除封网日历失败")
+	ErrGetDeployFreeze    = NewHTTPError(7054, "获取封网日历详情失败")
+
+	//-----------------------------------------------------------------------------------------------
+	// workflow git sync Error Range: 7060 - 7069
+	//-----------------------------------------------------------------------------------------------
+	ErrGetWorkflowGitSync    = NewHTTPError(7060, "获取工作流代码库同步配置失败")
+	ErrUpsertWorkflowGitSync = NewHTTPError(7061, "保存工作流代码库同步配置失败")
+	ErrDeleteWorkflowGitSync = NewHTTPError(7062, "删除工作流代码库同步配置失败")
+	ErrSyncWorkflowFromGit   = NewHTTPError(7063, "从代码库同步工作流失败")
+	ErrWorkflowGitSyncDrift  = NewHTTPError(7064, "工作流由代码库管理，不允许直接修改")
+
+	//-----------------------------------------------------------------------------------------------
+	// scanning findings Error Range: 7070 - 7079
+	//-----------------------------------------------------------------------------------------------
+	ErrListScanningFindings = NewHTTPError(7070, "获取代码扫描结果失败")
+
+	//-----------------------------------------------------------------------------------------------
+	// test case history Error Range: 7080 - 7089
+	//-----------------------------------------------------------------------------------------------
+	ErrListTestCaseResults      = NewHTTPError(7080, "获取测试用例历史失败")
+	ErrListFlakyTestCases       = NewHTTPError(7081, "获取不稳定测试用例失败")
+	ErrListTestCaseQuarantine   = NewHTTPError(7082, "获取隔离测试用例失败")
+	ErrCreateTestCaseQuarantine = NewHTTPError(7083, "隔离测试用例失败")
+	ErrDeleteTestCaseQuarantine = NewHTTPError(7084, "取消隔离测试用例失败")
+
+	//-----------------------------------------------------------------------------------------------
+	// coverage Error Range: 7090 - 7099
+	//-----------------------------------------------------------------------------------------------
+	ErrListCoverageRecords = NewHTTPError(7090, "获取覆盖率记录失败")
+	ErrGetCoverageTrend    = NewHTTPError(7091, "获取覆盖率趋势失败")
 )
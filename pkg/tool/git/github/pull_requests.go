@@ -18,6 +18,7 @@ package github
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/go-github/v35/github"
 
@@ -125,3 +126,54 @@ func (c *Client) ListFiles(ctx context.Context, owner string, repo string, numbe
 
 	return res, err
 }
+
+// CreatePullRequestWithFile commits the content to the path on a new head branch created from the base branch,
+// then opens a pull request from the head branch to the base branch. The file is deleted if the content is nil.
+func (c *Client) CreatePullRequestWithFile(ctx context.Context, owner, repo, base, head, path string, content []byte, title, body string) (*github.PullRequest, error) {
+	baseRef, err := wrap(c.Git.GetRef(ctx, owner, repo, "refs/heads/"+base))
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := baseRef.(*github.Reference)
+	if !ok {
+		return nil, fmt.Errorf("failed to get the ref of branch %s", base)
+	}
+	if _, err := wrap(c.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + head),
+		Object: &github.GitObject{SHA: ref.Object.SHA},
+	})); err != nil {
+		return nil, err
+	}
+
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String(title),
+		Content: content,
+		Branch:  github.String(head),
+	}
+	// the sha of the existing file is required to update or delete it
+	if file, _, _, err := c.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: head}); err == nil && file != nil {
+		opts.SHA = file.SHA
+	}
+	if content == nil {
+		if opts.SHA == nil {
+			return nil, fmt.Errorf("file %s not found in branch %s", path, base)
+		}
+		if _, err := wrap(c.Repositories.DeleteFile(ctx, owner, repo, path, opts)); err != nil {
+			return nil, err
+		}
+	} else if _, err := wrap(c.Repositories.UpdateFile(ctx, owner, repo, path, opts)); err != nil {
+		return nil, err
+	}
+
+	pr, err := wrap(c.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	}))
+	if p, ok := pr.(*github.PullRequest); ok {
+		return p, err
+	}
+
+	return nil, err
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package gitlab
 
-import "github.com/xanzy/go-gitlab"
+import (
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
 
 func (c *Client) ListOpenedProjectMergeRequests(owner, repo, targetBranch, key string, opts *ListOptions) ([]*gitlab.MergeRequest, error) {
 	mergeRequests, err := wrap(paginated(func(o *gitlab.ListOptions) ([]interface{}, *gitlab.Response, error) {
@@ -77,3 +81,43 @@ func (c *Client) ListChangedFiles(event *gitlab.MergeEvent) ([]string, error) {
 //	_, err := wrap(c.Discussions.CreateCommitDiscussion(generateProjectName(owner, repo), commitHash, args))
 //	return err
 //}
+
+// CreateMergeRequestWithFile commits the content to the path on a new source branch created from the target branch,
+// then opens a merge request from the source branch to the target branch. The file is deleted if the content is nil.
+func (c *Client) CreateMergeRequestWithFile(owner, repo, target, source, path string, content []byte, title, description string) (*gitlab.MergeRequest, error) {
+	action := gitlab.FileCreate
+	if _, err := c.GetFileContent(owner, repo, path, target); err == nil {
+		action = gitlab.FileUpdate
+		if content == nil {
+			action = gitlab.FileDelete
+		}
+	} else if content == nil {
+		return nil, fmt.Errorf("file %s not found in branch %s", path, target)
+	}
+	if _, err := wrap(c.Commits.CreateCommit(generateProjectName(owner, repo), &gitlab.CreateCommitOptions{
+		Branch:        gitlab.String(source),
+		StartBranch:   gitlab.String(target),
+		CommitMessage: gitlab.String(title),
+		Actions: []*gitlab.CommitActionOptions{
+			{
+				Action:   gitlab.FileAction(action),
+				FilePath: gitlab.String(path),
+				Content:  gitlab.String(string(content)),
+			},
+		},
+	})); err != nil {
+		return nil, err
+	}
+
+	mr, err := wrap(c.MergeRequests.CreateMergeRequest(generateProjectName(owner, repo), &gitlab.CreateMergeRequestOptions{
+		Title:        gitlab.String(title),
+		Description:  gitlab.String(description),
+		SourceBranch: gitlab.String(source),
+		TargetBranch: gitlab.String(target),
+	}))
+	if m, ok := mr.(*gitlab.MergeRequest); ok {
+		return m, err
+	}
+
+	return nil, err
+}
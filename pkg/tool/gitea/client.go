@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+// defaultPageSize is the max page size of the gitea api by default
+const defaultPageSize = 50
+
+type Client struct {
+	*httpclient.Client
+	Address string
+}
+
+// NewClient creates a client of the gitea api, the oauth token of the codehost is refreshed if it is about to expire.
+func NewClient(id int, address, accessToken, proxyAddr string, enableProxy bool) (*Client, error) {
+	token, err := UpdateGiteaToken(id, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh gitea token, err: %s", err)
+	}
+
+	opts := []httpclient.ClientFunc{
+		httpclient.SetHostURL(address),
+		httpclient.SetBaseURI("/api/v1"),
+	}
+	if token != "" {
+		opts = append(opts, httpclient.SetAuthToken(token))
+	}
+	if enableProxy {
+		opts = append(opts, httpclient.SetProxy(proxyAddr))
+	}
+
+	return &Client{
+		Client:  httpclient.New(opts...),
+		Address: address,
+	}, nil
+}
+
+// ListOptions specifies the page of the list apis, all the pages are fetched if Page is 0
+type ListOptions struct {
+	Page     int
+	PageSize int
+}
+
+// paginated fetches the pages by f until the last page, f returns the number of items in the page
+func paginated(opts *ListOptions, f func(page, limit int) (int, error)) error {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	limit := opts.PageSize
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if opts.Page > 0 {
+		_, err := f(opts.Page, limit)
+		return err
+	}
+
+	for page := 1; ; page++ {
+		count, err := f(page, limit)
+		if err != nil {
+			return err
+		}
+		if count < limit {
+			return nil
+		}
+	}
+}
+
+func pageParams(page, limit int) map[string]string {
+	return map[string]string{
+		"page":  strconv.Itoa(page),
+		"limit": strconv.Itoa(limit),
+	}
+}
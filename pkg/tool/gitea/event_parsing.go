@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EventType represents a gitea event type.
+type EventType string
+
+// List of available event types.
+const (
+	EventTypePush        EventType = "push"
+	EventTypePullRequest EventType = "pull_request"
+)
+
+// List of the pull request actions.
+const (
+	PullRequestActionOpened       = "opened"
+	PullRequestActionReopened     = "reopened"
+	PullRequestActionSynchronized = "synchronized"
+)
+
+const (
+	eventTypeHeader = "X-Gitea-Event"
+	signatureHeader = "X-Gitea-Signature"
+	tagRefPrefix    = "refs/tags/"
+)
+
+// HookEventType returns the event type for the given request.
+func HookEventType(r *http.Request) EventType {
+	return EventType(r.Header.Get(eventTypeHeader))
+}
+
+// ValidatePayload checks the hmac-sha256 signature of the payload signed by the secret of the webhook.
+func ValidatePayload(r *http.Request, payload []byte, secret string) error {
+	if secret == "" {
+		return nil
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(signatureHeader))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// ParseHook parses the payload of the event, a push event of tags is returned as a TagPushEvent.
+func ParseHook(eventType EventType, payload []byte) (interface{}, error) {
+	switch eventType {
+	case EventTypePush:
+		event := &PushEvent{}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(event.Ref, tagRefPrefix) {
+			return &TagPushEvent{PushEvent: *event}, nil
+		}
+		return event, nil
+	case EventTypePullRequest:
+		event := &PullRequestEvent{}
+		if err := json.Unmarshal(payload, event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	default:
+		return nil, fmt.Errorf("unexpected event type: %s", eventType)
+	}
+}
+
+type PushEvent struct {
+	Ref        string           `json:"ref"`
+	Before     string           `json:"before"`
+	After      string           `json:"after"`
+	CompareURL string           `json:"compare_url"`
+	Commits    []*PayloadCommit `json:"commits"`
+	HeadCommit *PayloadCommit   `json:"head_commit"`
+	Repo       *Repository      `json:"repository"`
+	Pusher     *User            `json:"pusher"`
+	Sender     *User            `json:"sender"`
+}
+
+type TagPushEvent struct {
+	PushEvent
+}
+
+// Tag returns the name of the pushed tag
+func (e *TagPushEvent) Tag() string {
+	return strings.TrimPrefix(e.Ref, tagRefPrefix)
+}
+
+type PullRequestEvent struct {
+	Action      string       `json:"action"`
+	Number      int          `json:"number"`
+	PullRequest *PullRequest `json:"pull_request"`
+	Repository  *Repository  `json:"repository"`
+	Sender      *User        `json:"sender"`
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePayload(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+
+	assert.NoError(t, ValidatePayload(req, payload, "secret"))
+	assert.Error(t, ValidatePayload(req, payload, "another"))
+	assert.NoError(t, ValidatePayload(req, payload, ""))
+}
+
+func TestParseHook(t *testing.T) {
+	event, err := ParseHook(EventTypePush, []byte(`{"ref":"refs/heads/main","after":"abc","repository":{"full_name":"org/repo"}}`))
+	require.NoError(t, err)
+	push, ok := event.(*PushEvent)
+	require.True(t, ok)
+	assert.Equal(t, "org/repo", push.Repo.FullName)
+
+	event, err = ParseHook(EventTypePush, []byte(`{"ref":"refs/tags/v1.0.0","repository":{"full_name":"org/repo"}}`))
+	require.NoError(t, err)
+	tag, ok := event.(*TagPushEvent)
+	require.True(t, ok)
+	assert.Equal(t, "v1.0.0", tag.Tag())
+
+	event, err = ParseHook(EventTypePullRequest, []byte(`{"action":"opened","number":3,"pull_request":{"number":3,"head":{"sha":"abc"},"base":{"ref":"main"}}}`))
+	require.NoError(t, err)
+	pr, ok := event.(*PullRequestEvent)
+	require.True(t, ok)
+	assert.Equal(t, PullRequestActionOpened, pr.Action)
+	assert.Equal(t, "main", pr.PullRequest.Base.Ref)
+
+	_, err = ParseHook("issues", []byte(`{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/koderover/zadig/pkg/tool/git"
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type createHookOption struct {
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+type hookResponse struct {
+	ID int64 `json:"id"`
+}
+
+// CreateHook creates a gitea type webhook for push and pull request events, the id of the hook is returned
+func (c *Client) CreateHook(owner, repo string, hook *git.Hook) (string, error) {
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{git.PushEvent, git.PullRequestEvent}
+	}
+	opt := &createHookOption{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          hook.URL,
+			"content_type": "json",
+			"secret":       hook.Secret,
+		},
+		Events: events,
+		Active: true,
+	}
+
+	resp := &hookResponse{}
+	_, err := c.Post(fmt.Sprintf("/repos/%s/%s/hooks", url.PathEscape(owner), url.PathEscape(repo)), httpclient.SetBody(opt), httpclient.SetResult(resp))
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+func (c *Client) DeleteHook(owner, repo, hookID string) error {
+	_, err := c.Delete(fmt.Sprintf("/repos/%s/%s/hooks/%s", url.PathEscape(owner), url.PathEscape(repo), hookID))
+	if httpclient.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
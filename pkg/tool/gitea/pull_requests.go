@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type PullRequest struct {
+	ID        int64      `json:"id"`
+	Number    int        `json:"number"`
+	User      *User      `json:"user"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	HTMLURL   string     `json:"html_url"`
+	Merged    bool       `json:"merged"`
+	Head      *PRBranch  `json:"head"`
+	Base      *PRBranch  `json:"base"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type PRBranch struct {
+	Label      string      `json:"label"`
+	Ref        string      `json:"ref"`
+	Sha        string      `json:"sha"`
+	RepoID     int64       `json:"repo_id"`
+	Repository *Repository `json:"repo"`
+}
+
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+}
+
+type Comment struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+}
+
+// CommitStatusState is the state of a commit status
+type CommitStatusState string
+
+const (
+	CommitStatusPending CommitStatusState = "pending"
+	CommitStatusSuccess CommitStatusState = "success"
+	CommitStatusError   CommitStatusState = "error"
+	CommitStatusFailure CommitStatusState = "failure"
+	CommitStatusWarning CommitStatusState = "warning"
+)
+
+type CommitStatus struct {
+	State       CommitStatusState `json:"state"`
+	TargetURL   string            `json:"target_url"`
+	Description string            `json:"description"`
+	Context     string            `json:"context"`
+}
+
+// ListPullRequests lists the pull requests of the repository, state can be open, closed or all
+func (c *Client) ListPullRequests(owner, repo, state string, opts *ListOptions) ([]*PullRequest, error) {
+	var res []*PullRequest
+	err := paginated(opts, func(page, limit int) (int, error) {
+		params := pageParams(page, limit)
+		if state != "" {
+			params["state"] = state
+		}
+		var prs []*PullRequest
+		_, err := c.Get(fmt.Sprintf("/repos/%s/%s/pulls", url.PathEscape(owner), url.PathEscape(repo)), httpclient.SetQueryParams(params), httpclient.SetResult(&prs))
+		res = append(res, prs...)
+		return len(prs), err
+	})
+	return res, err
+}
+
+func (c *Client) ListPullRequestFiles(owner, repo string, index int) ([]*ChangedFile, error) {
+	var res []*ChangedFile
+	err := paginated(nil, func(page, limit int) (int, error) {
+		var files []*ChangedFile
+		_, err := c.Get(fmt.Sprintf("/repos/%s/%s/pulls/%d/files", url.PathEscape(owner), url.PathEscape(repo), index), httpclient.SetQueryParams(pageParams(page, limit)), httpclient.SetResult(&files))
+		res = append(res, files...)
+		return len(files), err
+	})
+	return res, err
+}
+
+// CreateIssueComment comments on the issue or pull request
+func (c *Client) CreateIssueComment(owner, repo string, index int, body string) (*Comment, error) {
+	comment := &Comment{}
+	_, err := c.Post(fmt.Sprintf("/repos/%s/%s/issues/%d/comments", url.PathEscape(owner), url.PathEscape(repo), index), httpclient.SetBody(map[string]string{"body": body}), httpclient.SetResult(comment))
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (c *Client) EditIssueComment(owner, repo string, commentID int64, body string) error {
+	_, err := c.Patch(fmt.Sprintf("/repos/%s/%s/issues/comments/%d", url.PathEscape(owner), url.PathEscape(repo), commentID), httpclient.SetBody(map[string]string{"body": body}))
+	return err
+}
+
+func (c *Client) CreateCommitStatus(owner, repo, sha string, status *CommitStatus) error {
+	_, err := c.Post(fmt.Sprintf("/repos/%s/%s/statuses/%s", url.PathEscape(owner), url.PathEscape(repo), sha), httpclient.SetBody(status))
+	return err
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+)
+
+type User struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	FullName  string `json:"full_name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+	UserName  string `json:"username"`
+}
+
+type Organization struct {
+	ID        int64  `json:"id"`
+	UserName  string `json:"username"`
+	FullName  string `json:"full_name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type Repository struct {
+	ID            int64  `json:"id"`
+	Owner         *User  `json:"owner"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	HTMLURL       string `json:"html_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type PayloadCommit struct {
+	ID        string             `json:"id"`
+	Message   string             `json:"message"`
+	URL       string             `json:"url"`
+	Author    *PayloadUser       `json:"author"`
+	Committer *PayloadUser       `json:"committer"`
+	Timestamp time.Time          `json:"timestamp"`
+	Added     []string           `json:"added"`
+	Removed   []string           `json:"removed"`
+	Modified  []string           `json:"modified"`
+	Verify    *PayloadCommitInfo `json:"verification,omitempty"`
+}
+
+type PayloadUser struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	UserName string `json:"username"`
+}
+
+type PayloadCommitInfo struct {
+	Verified bool `json:"verified"`
+}
+
+type Branch struct {
+	Name      string         `json:"name"`
+	Commit    *PayloadCommit `json:"commit"`
+	Protected bool           `json:"protected"`
+}
+
+type Tag struct {
+	Name       string     `json:"name"`
+	Message    string     `json:"message"`
+	ID         string     `json:"id"`
+	Commit     *CommitRef `json:"commit"`
+	ZipballURL string     `json:"zipball_url"`
+	TarballURL string     `json:"tarball_url"`
+}
+
+type CommitRef struct {
+	SHA     string    `json:"sha"`
+	URL     string    `json:"url"`
+	Created time.Time `json:"created"`
+}
+
+type Commit struct {
+	SHA     string      `json:"sha"`
+	HTMLURL string      `json:"html_url"`
+	Commit  *RepoCommit `json:"commit"`
+	Author  *User       `json:"author"`
+}
+
+type RepoCommit struct {
+	Message   string      `json:"message"`
+	Author    *CommitUser `json:"author"`
+	Committer *CommitUser `json:"committer"`
+}
+
+type CommitUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+}
+
+type ContentsResponse struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	SHA      string `json:"sha"`
+	Type     string `json:"type"`
+	Size     int64  `json:"size"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+type searchRepoResponse struct {
+	OK   bool          `json:"ok"`
+	Data []*Repository `json:"data"`
+}
+
+func (c *Client) GetAuthenticatedUser() (*User, error) {
+	user := &User{}
+	if _, err := c.Get("/user", httpclient.SetResult(user)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListOrganizations lists the organizations of the authenticated user
+func (c *Client) ListOrganizations(opts *ListOptions) ([]*Organization, error) {
+	var res []*Organization
+	err := paginated(opts, func(page, limit int) (int, error) {
+		var orgs []*Organization
+		_, err := c.Get("/user/orgs", httpclient.SetQueryParams(pageParams(page, limit)), httpclient.SetResult(&orgs))
+		res = append(res, orgs...)
+		return len(orgs), err
+	})
+	return res, err
+}
+
+// ListUserRepositories lists the repositories owned by the authenticated user
+func (c *Client) ListUserRepositories(keyword string, opts *ListOptions) ([]*Repository, error) {
+	user, err := c.GetAuthenticatedUser()
+	if err != nil {
+		return nil, err
+	}
+	return c.searchRepositories(user.ID, keyword, opts)
+}
+
+// ListOrgRepositories lists the repositories of the organization
+func (c *Client) ListOrgRepositories(org, keyword string, opts *ListOptions) ([]*Repository, error) {
+	if keyword != "" {
+		o := &Organization{}
+		if _, err := c.Get(fmt.Sprintf("/orgs/%s", url.PathEscape(org)), httpclient.SetResult(o)); err != nil {
+			return nil, err
+		}
+		return c.searchRepositories(o.ID, keyword, opts)
+	}
+
+	var res []*Repository
+	err := paginated(opts, func(page, limit int) (int, error) {
+		var repos []*Repository
+		_, err := c.Get(fmt.Sprintf("/orgs/%s/repos", url.PathEscape(org)), httpclient.SetQueryParams(pageParams(page, limit)), httpclient.SetResult(&repos))
+		res = append(res, repos...)
+		return len(repos), err
+	})
+	return res, err
+}
+
+func (c *Client) searchRepositories(ownerID int64, keyword string, opts *ListOptions) ([]*Repository, error) {
+	var res []*Repository
+	err := paginated(opts, func(page, limit int) (int, error) {
+		params := pageParams(page, limit)
+		params["uid"] = fmt.Sprintf("%d", ownerID)
+		params["exclusive"] = "true"
+		if keyword != "" {
+			params["q"] = keyword
+		}
+		resp := &searchRepoResponse{}
+		_, err := c.Get("/repos/search", httpclient.SetQueryParams(params), httpclient.SetResult(resp))
+		res = append(res, resp.Data...)
+		return len(resp.Data), err
+	})
+	return res, err
+}
+
+func (c *Client) ListBranches(owner, repo string, opts *ListOptions) ([]*Branch, error) {
+	var res []*Branch
+	err := paginated(opts, func(page, limit int) (int, error) {
+		var branches []*Branch
+		_, err := c.Get(fmt.Sprintf("/repos/%s/%s/branches", url.PathEscape(owner), url.PathEscape(repo)), httpclient.SetQueryParams(pageParams(page, limit)), httpclient.SetResult(&branches))
+		res = append(res, branches...)
+		return len(branches), err
+	})
+	return res, err
+}
+
+func (c *Client) GetBranch(owner, repo, branch string) (*Branch, error) {
+	b := &Branch{}
+	if _, err := c.Get(fmt.Sprintf("/repos/%s/%s/branches/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(branch)), httpclient.SetResult(b)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *Client) ListTags(owner, repo string, opts *ListOptions) ([]*Tag, error) {
+	var res []*Tag
+	err := paginated(opts, func(page, limit int) (int, error) {
+		var tags []*Tag
+		_, err := c.Get(fmt.Sprintf("/repos/%s/%s/tags", url.PathEscape(owner), url.PathEscape(repo)), httpclient.SetQueryParams(pageParams(page, limit)), httpclient.SetResult(&tags))
+		res = append(res, tags...)
+		return len(tags), err
+	})
+	return res, err
+}
+
+// ListCommits lists the commits of the branch, the latest commit comes first
+func (c *Client) ListCommits(owner, repo, branch string, opts *ListOptions) ([]*Commit, error) {
+	var res []*Commit
+	err := paginated(opts, func(page, limit int) (int, error) {
+		params := pageParams(page, limit)
+		params["sha"] = branch
+		var commits []*Commit
+		_, err := c.Get(fmt.Sprintf("/repos/%s/%s/commits", url.PathEscape(owner), url.PathEscape(repo)), httpclient.SetQueryParams(params), httpclient.SetResult(&commits))
+		res = append(res, commits...)
+		return len(commits), err
+	})
+	return res, err
+}
+
+// GetContents returns the file of the path, or the entries of the directory if the path is a directory
+func (c *Client) GetContents(owner, repo, path, ref string) ([]*ContentsResponse, error) {
+	resp, err := c.Get(fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(owner), url.PathEscape(repo), path), httpclient.SetQueryParam("ref", ref))
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []*ContentsResponse
+	if len(resp.Body()) > 0 && resp.Body()[0] == '[' {
+		err = json.Unmarshal(resp.Body(), &contents)
+	} else {
+		content := &ContentsResponse{}
+		err = json.Unmarshal(resp.Body(), content)
+		contents = append(contents, content)
+	}
+	return contents, err
+}
+
+// GetRawFile returns the raw content of the file at the ref
+func (c *Client) GetRawFile(owner, repo, path, ref string) ([]byte, error) {
+	resp, err := c.Get(fmt.Sprintf("/repos/%s/%s/raw/%s", url.PathEscape(owner), url.PathEscape(repo), path), httpclient.SetQueryParam("ref", ref))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body(), nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitea
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koderover/zadig/pkg/shared/client/systemconfig"
+	"github.com/koderover/zadig/pkg/tool/httpclient"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+type AccessToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenExpirationThreshold is the seconds after which the token is refreshed, the oauth token of gitea expires in an hour by default
+const TokenExpirationThreshold int64 = 3000
+
+var mu = &sync.Mutex{}
+
+// UpdateGiteaToken refreshes the oauth token of the codehost if it is about to expire, and returns the valid token
+func UpdateGiteaToken(id int, accessToken string) (string, error) {
+	// if accessToken is empty, then it is either of ssh token type or username/password, we return empty
+	if accessToken == "" {
+		return "", nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ch, err := systemconfig.New().GetRawCodeHost(id)
+	if err != nil {
+		return "", fmt.Errorf("get codehost info error: [%s]", err)
+	}
+
+	// the codehost with a refresh token is registered by oauth, otherwise the token never expires
+	if ch.RefreshToken == "" || time.Now().Unix()-ch.UpdatedAt <= TokenExpirationThreshold {
+		return ch.AccessToken, nil
+	}
+
+	log.Infof("Starting to refresh gitea token, old token issued time: %d", ch.UpdatedAt)
+
+	token, err := refreshAccessToken(ch.Address, ch.AccessKey, ch.SecretKey, ch.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	ch.AccessToken = token.AccessToken
+	ch.RefreshToken = token.RefreshToken
+	ch.UpdatedAt = time.Now().Unix()
+
+	// Since the new token is valid, we simply log the error
+	if err = systemconfig.New().UpdateCodeHost(ch.ID, ch); err != nil {
+		log.Errorf("failed to update codehost, err: %s", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+func refreshAccessToken(address, clientID, clientSecret, refreshToken string) (*AccessToken, error) {
+	httpClient := httpclient.New(
+		httpclient.SetHostURL(address),
+	)
+
+	var accessToken *AccessToken
+	_, err := httpClient.Post("/login/oauth/access_token", httpclient.SetBody(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+	}), httpclient.SetResult(&accessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return accessToken, nil
+}
@@ -610,9 +610,13 @@ func (hClient *HelmClient) UpdateChartRepo(repoEntry *repo.Entry) (string, error
 
 // FetchIndexYaml fetch index.yaml from remote chart repo
 // `helm repo add` and `helm repo update` will be executed
+// for OCI registries, the index is built from the catalog and tags of the registry
 func (hClient *HelmClient) FetchIndexYaml(repoEntry *repo.Entry) (*repo.IndexFile, error) {
 	hClient.lock.Lock()
 	defer hClient.lock.Unlock()
+	if IsOCIRepo(repoEntry.URL) {
+		return hClient.fetchOCIIndex(repoEntry)
+	}
 	indexFilePath, err := hClient.UpdateChartRepo(repoEntry)
 	if err != nil {
 		return nil, err
@@ -623,21 +627,23 @@ func (hClient *HelmClient) FetchIndexYaml(repoEntry *repo.Entry) (*repo.IndexFil
 }
 
 // DownloadChart works like executing `helm pull repoName/chartName --version=version'
-// since pulling from OCI Registry is still considered as an EXPERIMENTAL feature
-// we DO NOT support pulling charts by pulling OCI Artifacts from OCI Registry
+// for OCI registries, it works like executing `helm pull oci://registry/path/chartName --version=version'
 // NOTE consider using os.execCommand('helm pull') to reduce code complexity of offering compatibility since third-party plugins CANNOT be used as SDK
 // if unTar is true, no need to mkdir for destDir
 // if unTar is no, your need to mkdir for destDir yourself
 func (hClient *HelmClient) DownloadChart(repoEntry *repo.Entry, chartRef string, chartVersion string, destDir string, unTar bool) error {
 	hClient.lock.Lock()
 	defer hClient.lock.Unlock()
+	if IsOCIRepo(repoEntry.URL) {
+		return hClient.downloadOCIChart(repoEntry, chartRef, chartVersion, destDir, unTar)
+	}
 	_, err := hClient.UpdateChartRepo(repoEntry)
 	if err != nil {
-		return nil
+		return err
 	}
 	pull := action.NewPullWithOpts(action.WithConfig(&action.Configuration{}))
-	pull.Password = repoEntry.Username
-	pull.Username = repoEntry.Password
+	pull.Username = repoEntry.Username
+	pull.Password = repoEntry.Password
 	pull.Version = chartVersion
 	pull.Settings = generalSettings
 	pull.DestDir = destDir
@@ -713,9 +719,12 @@ func handlePushResponse(resp *http.Response) error {
 func (hClient *HelmClient) PushChart(repoEntry *repo.Entry, chartPath string) error {
 	hClient.lock.Lock()
 	defer hClient.lock.Unlock()
+	if IsOCIRepo(repoEntry.URL) {
+		return hClient.pushOCIChart(repoEntry, chartPath)
+	}
 	_, err := hClient.UpdateChartRepo(repoEntry)
 	if err != nil {
-		return nil
+		return err
 	}
 	repoUrl, err := url.Parse(repoEntry.URL)
 	if err != nil {
@@ -0,0 +1,334 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	"oras.land/oras-go/pkg/registry/remote/auth"
+
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+// IsOCIRepo returns true if the chart repo is an OCI registry, e.g. oci://harbor.example.com/project
+func IsOCIRepo(repoURL string) bool {
+	return registry.IsOCI(repoURL)
+}
+
+// ociRepoPath returns the registry host and the path of the chart repo in the registry
+func ociRepoPath(repoURL string) (host, repoPath string, err error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse repo url: %s, err: %w", repoURL, err)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("invalid oci repo url: %s", repoURL)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// ociChartURL returns the url of the chart in the OCI registry, e.g. oci://harbor.example.com/project/chart
+func ociChartURL(repoURL, chartName string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(repoURL, "/"), chartName)
+}
+
+// ociChartURLs returns the candidate urls of the chart in the OCI registry, the repo url itself is the
+// repository of the chart if the catalog api is not supported and the chart is named after the last segment of it,
+// e.g. oci://ghcr.io/koderover/nginx for the chart nginx.
+func ociChartURLs(repoURL, chartName string) []string {
+	urls := []string{ociChartURL(repoURL, chartName)}
+	if _, repoPath, err := ociRepoPath(repoURL); err == nil && repoPath != "" && path.Base(repoPath) == chartName {
+		urls = append(urls, strings.TrimSuffix(repoURL, "/"))
+	}
+	return urls
+}
+
+// ociChartName returns the chart name of a chart ref in the format of `repoName/chartName`
+func ociChartName(chartRef string) string {
+	return chartRef[strings.LastIndex(chartRef, "/")+1:]
+}
+
+// newRegistryClient returns a registry client logged in to the OCI registry of the chart repo to push the charts,
+// the credentials of every repo are stored in a separate file to avoid being overwritten by other repos in the same registry.
+func (hClient *HelmClient) newRegistryClient(repoEntry *repo.Entry) (*registry.Client, error) {
+	host, _, err := ociRepoPath(repoEntry.URL)
+	if err != nil {
+		return nil, err
+	}
+	credentialsDir := filepath.Join(filepath.Dir(hClient.Settings.RegistryConfig), "repos")
+	if err := os.MkdirAll(credentialsDir, 0o755); err != nil {
+		return nil, err
+	}
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(filepath.Join(credentialsDir, fmt.Sprintf("%s.json", repoEntry.Name))),
+		registry.ClientOptWriter(os.Stdout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+	if repoEntry.Username != "" {
+		err = registryClient.Login(host,
+			registry.LoginOptBasicAuth(repoEntry.Username, repoEntry.Password),
+			registry.LoginOptInsecure(repoEntry.InsecureSkipTLSverify),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to login to oci registry %s: %w", host, err)
+		}
+	}
+	return registryClient, nil
+}
+
+// ListOCIChartVersions lists the versions of the chart in the OCI registry, which are sorted in descending order
+func (hClient *HelmClient) ListOCIChartVersions(repoEntry *repo.Entry, chartName string) ([]string, error) {
+	remote, err := newOCIRemote(repoEntry)
+	if err != nil {
+		return nil, err
+	}
+	_, versions, err := listOCIChartTags(remote, repoEntry.URL, chartName)
+	return versions, err
+}
+
+// listOCIChartTags returns the url of the chart and its tags, the candidate urls of the chart are tried in order
+func listOCIChartTags(remote *ociRemote, repoURL, chartName string) (string, []string, error) {
+	var err error
+	for _, chartURL := range ociChartURLs(repoURL, chartName) {
+		var tags []string
+		tags, err = remote.tags(chartURL)
+		if err == nil {
+			return chartURL, tags, nil
+		}
+	}
+	return "", nil, err
+}
+
+// fetchOCIIndex builds the index of the charts in the OCI registry, the charts are found by the catalog api of the registry.
+// Some registries like GHCR and ECR do not support the catalog api, the repo url is used as the repository of a single chart
+// named after its last segment for them, and the chart is listed by the tags of the repository.
+func (hClient *HelmClient) fetchOCIIndex(repoEntry *repo.Entry) (*repo.IndexFile, error) {
+	host, repoPath, err := ociRepoPath(repoEntry.URL)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := newOCIRemote(repoEntry)
+	if err != nil {
+		return nil, err
+	}
+	repositories, err := listOCIRepositories(remote, host)
+	if err != nil {
+		if repoPath == "" {
+			return nil, fmt.Errorf("failed to list charts of oci registry %s, the catalog api may not be supported by the registry: %w", host, err)
+		}
+		log.Warnf("failed to list charts of oci registry %s by the catalog api, listing the tags of %s instead, err: %s", host, repoPath, err)
+		return fetchOCIRepositoryIndex(remote, repoEntry, path.Base(repoPath))
+	}
+
+	prefix := ""
+	if repoPath != "" {
+		prefix = repoPath + "/"
+	}
+	chartNames := make([]string, 0)
+	for _, repository := range repositories {
+		if !strings.HasPrefix(repository, prefix) || strings.Contains(strings.TrimPrefix(repository, prefix), "/") {
+			continue
+		}
+		chartNames = append(chartNames, strings.TrimPrefix(repository, prefix))
+	}
+	return buildOCIIndex(remote, repoEntry, chartNames), nil
+}
+
+// fetchOCIRepositoryIndex builds the index of the single chart stored in the repository of the repo url
+func fetchOCIRepositoryIndex(remote *ociRemote, repoEntry *repo.Entry, chartName string) (*repo.IndexFile, error) {
+	chartURL := strings.TrimSuffix(repoEntry.URL, "/")
+	versions, err := remote.tags(chartURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of oci repository %s: %w", chartURL, err)
+	}
+	return newOCIIndex(chartName, chartURL, versions), nil
+}
+
+// newOCIIndex builds the index of a chart from its tags
+func newOCIIndex(chartName, chartURL string, versions []string) *repo.IndexFile {
+	index := repo.NewIndexFile()
+	addOCIIndexEntries(index, chartName, chartURL, versions)
+	index.SortEntries()
+	return index
+}
+
+func addOCIIndexEntries(index *repo.IndexFile, chartName, chartURL string, versions []string) {
+	for _, version := range versions {
+		index.Entries[chartName] = append(index.Entries[chartName], &repo.ChartVersion{
+			Metadata: &chart.Metadata{Name: chartName, Version: version, APIVersion: chart.APIVersionV2},
+			URLs:     []string{fmt.Sprintf("%s:%s", chartURL, version)},
+		})
+	}
+}
+
+// buildOCIIndex builds the index of the given charts in the OCI registry from the tags of the charts
+func buildOCIIndex(remote *ociRemote, repoEntry *repo.Entry, chartNames []string) *repo.IndexFile {
+	index := repo.NewIndexFile()
+	for _, chartName := range chartNames {
+		chartURL, versions, err := listOCIChartTags(remote, repoEntry.URL, chartName)
+		if err != nil {
+			// the chart may not have been pushed to the registry yet
+			log.Warnf("failed to list versions of chart %s in oci registry %s, err: %s", chartName, repoEntry.URL, err)
+			continue
+		}
+		addOCIIndexEntries(index, chartName, chartURL, versions)
+	}
+	index.SortEntries()
+	return index
+}
+
+// listOCIRepositories lists all the repositories in the registry by the catalog api
+func listOCIRepositories(remote *ociRemote, host string) ([]string, error) {
+	ctx := auth.WithScopes(context.Background(), "registry:catalog:*")
+
+	var repositories []string
+	next := "/v2/_catalog?n=1000"
+	for next != "" {
+		resp, err := remote.get(ctx, host, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		page := struct {
+			Repositories []string `json:"repositories"`
+		}{}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status code %d of catalog api", resp.StatusCode)
+			}
+			return json.NewDecoder(resp.Body).Decode(&page)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, page.Repositories...)
+		nextURL, err := nextCatalogPage(resp.Request.URL, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+		next = ""
+		if nextURL != "" {
+			u, err := url.Parse(nextURL)
+			if err != nil {
+				return nil, err
+			}
+			next = u.RequestURI()
+		}
+	}
+	return repositories, nil
+}
+
+// nextCatalogPage returns the url of the next page from the link header, e.g. </v2/_catalog?last=b&n=1000>; rel="next"
+func nextCatalogPage(current *url.URL, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end <= start {
+		return "", fmt.Errorf("invalid link header: %s", link)
+	}
+	next, err := current.Parse(link[start+1 : end])
+	if err != nil {
+		return "", fmt.Errorf("invalid link header: %s, err: %w", link, err)
+	}
+	return next.String(), nil
+}
+
+// downloadOCIChart pulls the chart from the OCI registry, chartRef is in the format of `repoName/chartName`
+func (hClient *HelmClient) downloadOCIChart(repoEntry *repo.Entry, chartRef, chartVersion, destDir string, unTar bool) error {
+	remote, err := newOCIRemote(repoEntry)
+	if err != nil {
+		return err
+	}
+	chartURL, tags, err := listOCIChartTags(remote, repoEntry.URL, ociChartName(chartRef))
+	if err != nil {
+		return fmt.Errorf("failed to find chart %s in oci registry %s: %w", chartRef, repoEntry.URL, err)
+	}
+	version, err := registry.GetTagMatchingVersionOrConstraint(tags, chartVersion)
+	if err != nil {
+		return err
+	}
+	data, err := remote.pullChart(chartURL, version)
+	if err != nil {
+		return fmt.Errorf("failed to pull chart %s: %w", chartRef, err)
+	}
+	return saveChart(data, ociChartName(chartRef), version, destDir, unTar)
+}
+
+// pushOCIChart pushes the chart package to the OCI registry, the chart is stored as repoURL/chartName:chartVersion
+func (hClient *HelmClient) pushOCIChart(repoEntry *repo.Entry, chartPath string) error {
+	registryClient, err := hClient.newRegistryClient(repoEntry)
+	if err != nil {
+		return err
+	}
+	push := action.NewPushWithOpts(action.WithPushConfig(&action.Configuration{RegistryClient: registryClient}))
+	push.Settings = hClient.Settings
+	if _, err := push.Run(chartPath, strings.TrimSuffix(repoEntry.URL, "/")); err != nil {
+		return fmt.Errorf("failed to push chart: %s, error: %w", chartPath, err)
+	}
+	log.Info("push chart to oci registry done")
+	return nil
+}
+
+// FetchChartsIndex works like FetchIndexYaml, but only the given charts are listed for OCI registries,
+// which does not rely on the catalog api of the registry
+func (hClient *HelmClient) FetchChartsIndex(repoEntry *repo.Entry, chartNames []string) (*repo.IndexFile, error) {
+	if !IsOCIRepo(repoEntry.URL) {
+		return hClient.FetchIndexYaml(repoEntry)
+	}
+	remote, err := newOCIRemote(repoEntry)
+	if err != nil {
+		return nil, err
+	}
+	return buildOCIIndex(remote, repoEntry, chartNames), nil
+}
+
+// GetChartVersion returns the latest version of the chart matching the version or constraint, e.g. 1.2.3, ~1.2, >=1.0.0 <2.0.0,
+// the latest version of the chart is returned if versionOrConstraint is empty.
+func (hClient *HelmClient) GetChartVersion(repoEntry *repo.Entry, chartName, versionOrConstraint string) (string, error) {
+	if IsOCIRepo(repoEntry.URL) {
+		versions, err := hClient.ListOCIChartVersions(repoEntry, chartName)
+		if err != nil {
+			return "", err
+		}
+		return registry.GetTagMatchingVersionOrConstraint(versions, versionOrConstraint)
+	}
+
+	index, err := hClient.FetchIndexYaml(repoEntry)
+	if err != nil {
+		return "", err
+	}
+	chartVersion, err := index.Get(chartName, versionOrConstraint)
+	if err != nil {
+		return "", fmt.Errorf("failed to find chart %s matching version %s: %w", chartName, versionOrConstraint, err)
+	}
+	return chartVersion.Version, nil
+}
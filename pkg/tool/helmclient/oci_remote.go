@@ -0,0 +1,221 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	orasregistry "oras.land/oras-go/pkg/registry"
+	"oras.land/oras-go/pkg/registry/remote"
+	"oras.land/oras-go/pkg/registry/remote/auth"
+)
+
+const ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+// ociRemote reads the charts in the OCI registry of a chart repo with the tls settings of the repo,
+// the registry client of helm always verifies the certificate of the registry.
+// The registry is accessed by plain HTTP if it does not serve HTTPS.
+type ociRemote struct {
+	client    *auth.Client
+	plainHTTP bool
+}
+
+func newOCIRemote(repoEntry *repo.Entry) (*ociRemote, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: repoEntry.InsecureSkipTLSverify}
+	if repoEntry.CAFile != "" {
+		ca, err := os.ReadFile(repoEntry.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file of repo %s: %w", repoEntry.Name, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid ca file of repo %s", repoEntry.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &ociRemote{
+		client: &auth.Client{
+			Client: &http.Client{Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			}},
+			Cache: auth.NewCache(),
+			Credential: func(ctx context.Context, reg string) (auth.Credential, error) {
+				if repoEntry.Username == "" {
+					return auth.EmptyCredential, nil
+				}
+				return auth.Credential{Username: repoEntry.Username, Password: repoEntry.Password}, nil
+			},
+		},
+	}, nil
+}
+
+// isHTTPResponseError returns true if a HTTPS request is answered by a plain HTTP server
+func isHTTPResponseError(err error) bool {
+	return strings.Contains(err.Error(), "server gave HTTP response")
+}
+
+// get sends a GET request to the registry, requestURI is the path and the query of the request
+func (r *ociRemote) get(ctx context.Context, host, requestURI string, header http.Header) (*http.Response, error) {
+	for {
+		scheme := "https"
+		if r.plainHTTP {
+			scheme = "http"
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, host, requestURI), nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			if !r.plainHTTP && isHTTPResponseError(err) {
+				r.plainHTTP = true
+				continue
+			}
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// tags lists the semver tags of the chart in descending order like the registry client of helm
+func (r *ociRemote) tags(chartURL string) ([]string, error) {
+	ref, err := orasregistry.ParseReference(strings.TrimPrefix(chartURL, fmt.Sprintf("%s://", registry.OCIScheme)))
+	if err != nil {
+		return nil, err
+	}
+	var registryTags []string
+	for {
+		repository := &remote.Repository{Reference: ref, Client: r.client, PlainHTTP: r.plainHTTP}
+		registryTags, err = orasregistry.Tags(context.Background(), repository)
+		if err != nil {
+			if !r.plainHTTP && isHTTPResponseError(err) {
+				r.plainHTTP = true
+				continue
+			}
+			return nil, err
+		}
+		break
+	}
+
+	versions := make([]*semver.Version, 0)
+	for _, tag := range registryTags {
+		// the plus of the chart versions is stored as underscore in the tags
+		if version, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+")); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	tags := make([]string, 0, len(versions))
+	for _, version := range versions {
+		tags = append(tags, version.String())
+	}
+	return tags, nil
+}
+
+// pullChart downloads the chart package of the version from the repository of the chart
+func (r *ociRemote) pullChart(chartURL, version string) ([]byte, error) {
+	ref, err := orasregistry.ParseReference(strings.TrimPrefix(chartURL, fmt.Sprintf("%s://", registry.OCIScheme)))
+	if err != nil {
+		return nil, err
+	}
+	ctx := auth.WithScopes(context.Background(), auth.ScopeRepository(ref.Repository, auth.ActionPull))
+
+	manifest := struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}{}
+	manifestURI := fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, strings.ReplaceAll(version, "+", "_"))
+	if err := r.getJSON(ctx, ref.Registry, manifestURI, http.Header{"Accept": {ociManifestMediaType}}, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to get manifest of chart %s:%s: %w", chartURL, version, err)
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != registry.ChartLayerMediaType && layer.MediaType != registry.LegacyChartLayerMediaType {
+			continue
+		}
+		return r.getBlob(ctx, ref.Registry, ref.Repository, layer.Digest)
+	}
+	return nil, fmt.Errorf("manifest of chart %s:%s does not contain a layer with mediatype %s", chartURL, version, registry.ChartLayerMediaType)
+}
+
+func (r *ociRemote) getJSON(ctx context.Context, host, requestURI string, header http.Header, v interface{}) error {
+	resp, err := r.get(ctx, host, requestURI, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d of %s", resp.StatusCode, requestURI)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// getBlob downloads the blob and verifies its digest
+func (r *ociRemote) getBlob(ctx context.Context, host, repository, blobDigest string) ([]byte, error) {
+	dgst, err := digest.Parse(blobDigest)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.get(ctx, host, fmt.Sprintf("/v2/%s/blobs/%s", repository, dgst), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d of blob %s", resp.StatusCode, dgst)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if dgst.Algorithm().FromBytes(data) != dgst {
+		return nil, fmt.Errorf("digest of blob %s does not match", dgst)
+	}
+	return data, nil
+}
+
+// saveChart saves the chart package to destDir as chartName-version.tgz like helm pull, or extracts it to destDir
+func saveChart(data []byte, chartName, version, destDir string, unTar bool) error {
+	if unTar {
+		return chartutil.Expand(destDir, bytes.NewReader(data))
+	}
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(destDir, fmt.Sprintf("%s-%s.tgz", chartName, version)), data, 0o644)
+}
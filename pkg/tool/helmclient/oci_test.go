@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestOCIRef(t *testing.T) {
+	assert.True(t, IsOCIRepo("oci://harbor.example.com/charts"))
+	assert.False(t, IsOCIRepo("https://charts.example.com"))
+
+	host, repoPath, err := ociRepoPath("oci://harbor.example.com/library/charts/")
+	assert.NoError(t, err)
+	assert.Equal(t, "harbor.example.com", host)
+	assert.Equal(t, "library/charts", repoPath)
+
+	_, _, err = ociRepoPath("oci:///charts")
+	assert.Error(t, err)
+
+	assert.Equal(t, "oci://ghcr.io/koderover/nginx", ociChartURL("oci://ghcr.io/koderover/", ociChartName("myrepo/nginx")))
+}
+
+func TestNextCatalogPage(t *testing.T) {
+	current, _ := url.Parse("https://harbor.example.com/v2/_catalog?n=1000")
+
+	next, err := nextCatalogPage(current, "")
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+
+	next, err = nextCatalogPage(current, `</v2/_catalog?last=nginx&n=1000>; rel="next"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://harbor.example.com/v2/_catalog?last=nginx&n=1000", next)
+
+	_, err = nextCatalogPage(current, "invalid")
+	assert.Error(t, err)
+}
+
+func TestOCIChartURLs(t *testing.T) {
+	assert.Equal(t, []string{"oci://harbor.example.com/library/nginx"}, ociChartURLs("oci://harbor.example.com/library", "nginx"))
+	// the repo url may be the repository of the chart for the registries without the catalog api
+	assert.Equal(t, []string{"oci://ghcr.io/koderover/nginx/nginx", "oci://ghcr.io/koderover/nginx"}, ociChartURLs("oci://ghcr.io/koderover/nginx/", "nginx"))
+	assert.Equal(t, []string{"oci://harbor.example.com/nginx"}, ociChartURLs("oci://harbor.example.com", "nginx"))
+}
+
+func TestNewOCIIndex(t *testing.T) {
+	index := newOCIIndex("nginx", "oci://ghcr.io/koderover/nginx", []string{"1.0.0", "1.2.0", "1.1.0"})
+
+	versions := index.Entries["nginx"]
+	assert.Len(t, versions, 3)
+	assert.Equal(t, "1.2.0", versions[0].Version)
+	assert.Equal(t, []string{"oci://ghcr.io/koderover/nginx:1.2.0"}, versions[0].URLs)
+
+	latest, err := index.Get("nginx", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", latest.Version)
+}
+
+func newFakeOCIRegistry(tls bool) *httptest.Server {
+	chart := []byte("chart")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/charts/nginx/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"charts/nginx","tags":["1.0.0","latest","1.2.0_build.1","1.1.0"]}`)
+	})
+	mux.HandleFunc("/v2/charts/nginx/manifests/1.1.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"layers":[{"mediaType":"%s","digest":"%s"}]}`, registry.ChartLayerMediaType, digest.FromBytes(chart))
+	})
+	mux.HandleFunc("/v2/charts/nginx/blobs/"+digest.FromBytes(chart).String(), func(w http.ResponseWriter, r *http.Request) {
+		w.Write(chart)
+	})
+	if tls {
+		return httptest.NewTLSServer(mux)
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestOCIRemote(t *testing.T) {
+	for _, tls := range []bool{true, false} {
+		server := newFakeOCIRegistry(tls)
+		chartURL := fmt.Sprintf("oci://%s/charts/nginx", server.Listener.Addr())
+
+		// the self signed certificate is not trusted unless the tls verification is skipped
+		if tls {
+			remote, err := newOCIRemote(&repo.Entry{Name: "charts"})
+			assert.NoError(t, err)
+			_, err = remote.tags(chartURL)
+			assert.Error(t, err)
+		}
+
+		remote, err := newOCIRemote(&repo.Entry{Name: "charts", InsecureSkipTLSverify: true})
+		assert.NoError(t, err)
+		tags, err := remote.tags(chartURL)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1.2.0+build.1", "1.1.0", "1.0.0"}, tags)
+		assert.Equal(t, !tls, remote.plainHTTP)
+
+		data, err := remote.pullChart(chartURL, "1.1.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("chart"), data)
+		server.Close()
+	}
+}
+
+func TestSaveChart(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, saveChart([]byte("chart"), "nginx", "1.1.0", dir, false))
+	data, err := os.ReadFile(filepath.Join(dir, "nginx-1.1.0.tgz"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("chart"), data)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// IsKustomizationDir returns true if there is a kustomization file in the directory
+func IsKustomizationDir(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && !fi.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// Build works like executing `kustomize build dir`, and returns the manifests in yaml format
+func Build(dir string) (string, error) {
+	if !IsKustomizationDir(dir) {
+		return "", fmt.Errorf("no kustomization file is found in %s", dir)
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to build kustomization %s: %w", dir, err)
+	}
+	yamlBytes, err := resMap.AsYaml()
+	if err != nil {
+		return "", fmt.Errorf("failed to convert resources of kustomization %s to yaml: %w", dir, err)
+	}
+	return string(yamlBytes), nil
+}
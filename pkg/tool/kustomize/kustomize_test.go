@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "base", "kustomization.yaml"), `resources:
+- deployment.yaml
+`)
+	writeFile(t, filepath.Join(dir, "base", "deployment.yaml"), `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.23
+`)
+	writeFile(t, filepath.Join(dir, "overlays", "dev", "kustomization.yml"), `resources:
+- ../../base
+namePrefix: dev-
+images:
+- name: nginx
+  newTag: "1.24"
+`)
+
+	assert.True(t, IsKustomizationDir(filepath.Join(dir, "base")))
+	assert.False(t, IsKustomizationDir(dir))
+
+	manifests, err := Build(filepath.Join(dir, "base"))
+	assert.NoError(t, err)
+	assert.Contains(t, manifests, "name: nginx\n")
+	assert.Contains(t, manifests, "image: nginx:1.23")
+
+	manifests, err = Build(filepath.Join(dir, "overlays", "dev"))
+	assert.NoError(t, err)
+	assert.Contains(t, manifests, "name: dev-nginx")
+	assert.Contains(t, manifests, "image: nginx:1.24")
+
+	_, err = Build(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/koderover/zadig/pkg/tool/cache"
+)
+
+const (
+	DefaultAnthropicBaseURL    = "https://api.anthropic.com"
+	DefaultAnthropicModel      = "claude-2.1"
+	DefaultAnthropicTokenLimit = 100000
+	DefaultAnthropicMaxTokens  = 4096
+	anthropicAPIVersion        = "2023-06-01"
+)
+
+// AnthropicClient calls the messages api of Anthropic, or the services provide the same api
+type AnthropicClient struct {
+	name       string
+	model      string
+	baseURL    string
+	token      string
+	tokenLimit int
+	client     *http.Client
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicMessagesRequest struct {
+	Model         string             `json:"model"`
+	Messages      []anthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   float32            `json:"temperature,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicMessagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *anthropicError `json:"error"`
+}
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *anthropicError `json:"error"`
+}
+
+func (c *AnthropicClient) Configure(config LLMConfig) error {
+	httpClient, err := newHTTPClient(config.GetProxy())
+	if err != nil {
+		return err
+	}
+
+	c.client = httpClient
+	c.name = config.GetName()
+	c.model = config.GetModel()
+	c.token = config.GetToken()
+	c.tokenLimit = config.GetTokenLimit()
+	c.baseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+	if c.baseURL == "" {
+		c.baseURL = DefaultAnthropicBaseURL
+	}
+	return nil
+}
+
+func (c *AnthropicClient) messages(ctx context.Context, prompt string, stream bool, options ...ParamOption) (*http.Response, error) {
+	opts := ParamOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	opts = ValidOptions(opts)
+
+	// the model in options is usually an OpenAI model, only the configured model can be used
+	model := c.model
+	if model == "" {
+		model = DefaultAnthropicModel
+	}
+	// max tokens is required by the messages api
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultAnthropicMaxTokens
+	}
+	body, err := json.Marshal(&anthropicMessagesRequest{
+		Model:         model,
+		Messages:      []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens:     maxTokens,
+		Temperature:   opts.Temperature,
+		StopSequences: opts.StopWords,
+		Stream:        stream,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.token)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readErrorBody(resp)
+	}
+	return resp, nil
+}
+
+func (c *AnthropicClient) GetCompletion(ctx context.Context, prompt string, options ...ParamOption) (string, error) {
+	resp, err := c.messages(ctx, prompt, false, options...)
+	if err != nil {
+		return "", fmt.Errorf("create anthropic message failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result := &anthropicMessagesResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return "", fmt.Errorf("decode anthropic message response failed: %v", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("create anthropic message failed: %s", result.Error.Message)
+	}
+
+	answer := ""
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			answer += content.Text
+		}
+	}
+	return answer, nil
+}
+
+func (c *AnthropicClient) GetCompletionStream(ctx context.Context, prompt string, handler StreamHandler, options ...ParamOption) error {
+	resp, err := c.messages(ctx, prompt, true, options...)
+	if err != nil {
+		return fmt.Errorf("create anthropic message stream failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// the stream is server-sent events, only the data lines are needed
+	return readLines(resp.Body, func(line string) (bool, error) {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return false, nil
+		}
+		event := &anthropicStreamEvent{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), event); err != nil {
+			return false, fmt.Errorf("decode anthropic message stream failed: %v", err)
+		}
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Text != "" {
+				return false, handler(event.Delta.Text)
+			}
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("receive anthropic message stream failed: %s", event.Error.Message)
+			}
+			return false, fmt.Errorf("receive anthropic message stream failed")
+		case "message_stop":
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+func (c *AnthropicClient) Parse(ctx context.Context, prompt string, cache cache.ICache, options ...ParamOption) (string, error) {
+	return parseWithCache(ctx, c, prompt, cache, options...)
+}
+
+func (c *AnthropicClient) GetName() string {
+	if c.name == "" {
+		return ProviderAnthropic
+	}
+	return c.name
+}
+
+func (c *AnthropicClient) GetTokenLimit() int {
+	if c.tokenLimit > 0 {
+		return c.tokenLimit
+	}
+	return DefaultAnthropicTokenLimit
+}
+
+func (c *AnthropicClient) CountTokens(prompt string) (int, error) {
+	return estimateTokens(prompt), nil
+}
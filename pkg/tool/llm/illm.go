@@ -24,25 +24,46 @@ import (
 	"github.com/koderover/zadig/pkg/tool/cache"
 )
 
+const (
+	ProviderOpenAI           = "openai"
+	ProviderAzureOpenAI      = "azureopenai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderOllama           = "ollama"
+	ProviderAnthropic        = "anthropic"
+)
+
 var (
-	clients = map[string]ILLM{
-		"openai":      &OpenAIClient{},
-		"azureopenai": &OpenAIClient{},
+	// clients creates a new client for every call, since the client keeps the configuration of the integration
+	clients = map[string]func() ILLM{
+		ProviderOpenAI:           func() ILLM { return &OpenAIClient{} },
+		ProviderAzureOpenAI:      func() ILLM { return &OpenAIClient{} },
+		ProviderOpenAICompatible: func() ILLM { return &OpenAIClient{} },
+		ProviderOllama:           func() ILLM { return &OllamaClient{} },
+		ProviderAnthropic:        func() ILLM { return &AnthropicClient{} },
 	}
 )
 
+// StreamHandler is called with every piece of the answer when the completion is streamed
+type StreamHandler func(delta string) error
+
 type ILLM interface {
 	Configure(config LLMConfig) error
 	GetCompletion(ctx context.Context, prompt string, options ...ParamOption) (string, error)
+	// GetCompletionStream returns the answer piece by piece to the handler as soon as it is generated
+	GetCompletionStream(ctx context.Context, prompt string, handler StreamHandler, options ...ParamOption) error
 	Parse(ctx context.Context, prompt string, cache cache.ICache, options ...ParamOption) (string, error)
 	GetName() string
+	// GetTokenLimit returns the max number of tokens of the prompt and the answer the model accepts
+	GetTokenLimit() int
+	// CountTokens returns the number of tokens of the prompt for the model
+	CountTokens(prompt string) (int, error)
 }
 
 func NewClient(provider string) (ILLM, error) {
-	if c, ok := clients[provider]; !ok {
+	if newClient, ok := clients[provider]; !ok {
 		return nil, fmt.Errorf("provider %s not supported", provider)
 	} else {
-		return c, nil
+		return newClient(), nil
 	}
 }
 
@@ -53,6 +74,8 @@ type LLMConfig struct {
 	BaseURL string
 	Proxy   string
 	APIType string
+	// TokenLimit overrides the default token limit of the model
+	TokenLimit int
 }
 
 func (p *LLMConfig) GetName() string {
@@ -79,6 +102,10 @@ func (p *LLMConfig) GetAPIType() string {
 	return p.APIType
 }
 
+func (p *LLMConfig) GetTokenLimit() int {
+	return p.TokenLimit
+}
+
 func GetCacheKey(provider string, sEnc string) string {
 	data := fmt.Sprintf("%s-%s", provider, sEnc)
 
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/koderover/zadig/pkg/tool/cache"
+)
+
+const (
+	DefaultOllamaBaseURL    = "http://localhost:11434"
+	DefaultOllamaModel      = "llama2"
+	DefaultOllamaTokenLimit = 4096
+)
+
+// OllamaClient calls the chat api of a local model served by Ollama
+type OllamaClient struct {
+	name       string
+	model      string
+	baseURL    string
+	token      string
+	tokenLimit int
+	client     *http.Client
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaOptions struct {
+	Temperature float32  `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	NumCtx      int      `json:"num_ctx,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+}
+
+func (c *OllamaClient) Configure(config LLMConfig) error {
+	httpClient, err := newHTTPClient(config.GetProxy())
+	if err != nil {
+		return err
+	}
+
+	c.client = httpClient
+	c.name = config.GetName()
+	c.model = config.GetModel()
+	c.token = config.GetToken()
+	c.tokenLimit = config.GetTokenLimit()
+	c.baseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+	if c.baseURL == "" {
+		c.baseURL = DefaultOllamaBaseURL
+	}
+	return nil
+}
+
+func (c *OllamaClient) chat(ctx context.Context, prompt string, stream bool, options ...ParamOption) (*http.Response, error) {
+	opts := ParamOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	opts = ValidOptions(opts)
+
+	// the model in options is usually an OpenAI model, only the configured model can be served by Ollama
+	model := c.model
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	body, err := json.Marshal(&ollamaChatRequest{
+		Model:    model,
+		Messages: []ollamaMessage{{Role: "user", Content: prompt}},
+		Stream:   stream,
+		Options: ollamaOptions{
+			Temperature: opts.Temperature,
+			NumPredict:  opts.MaxTokens,
+			NumCtx:      c.GetTokenLimit(),
+			Stop:        opts.StopWords,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Ollama has no authentication, the token is used by the reverse proxy in front of it if there is one
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readErrorBody(resp)
+	}
+	return resp, nil
+}
+
+func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string, options ...ParamOption) (string, error) {
+	resp, err := c.chat(ctx, prompt, false, options...)
+	if err != nil {
+		return "", fmt.Errorf("create ollama chat failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result := &ollamaChatResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return "", fmt.Errorf("decode ollama chat response failed: %v", err)
+	}
+	if result.Error != "" {
+		return "", fmt.Errorf("create ollama chat failed: %s", result.Error)
+	}
+	return result.Message.Content, nil
+}
+
+func (c *OllamaClient) GetCompletionStream(ctx context.Context, prompt string, handler StreamHandler, options ...ParamOption) error {
+	resp, err := c.chat(ctx, prompt, true, options...)
+	if err != nil {
+		return fmt.Errorf("create ollama chat stream failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// the stream is a json object per line
+	return readLines(resp.Body, func(line string) (bool, error) {
+		result := &ollamaChatResponse{}
+		if err := json.Unmarshal([]byte(line), result); err != nil {
+			return false, fmt.Errorf("decode ollama chat stream failed: %v", err)
+		}
+		if result.Error != "" {
+			return false, fmt.Errorf("receive ollama chat stream failed: %s", result.Error)
+		}
+		if result.Message.Content != "" {
+			if err := handler(result.Message.Content); err != nil {
+				return false, err
+			}
+		}
+		return result.Done, nil
+	})
+}
+
+func (c *OllamaClient) Parse(ctx context.Context, prompt string, cache cache.ICache, options ...ParamOption) (string, error) {
+	return parseWithCache(ctx, c, prompt, cache, options...)
+}
+
+func (c *OllamaClient) GetName() string {
+	if c.name == "" {
+		return ProviderOllama
+	}
+	return c.name
+}
+
+func (c *OllamaClient) GetTokenLimit() int {
+	if c.tokenLimit > 0 {
+		return c.tokenLimit
+	}
+	return DefaultOllamaTokenLimit
+}
+
+func (c *OllamaClient) CountTokens(prompt string) (int, error) {
+	return estimateTokens(prompt), nil
+}
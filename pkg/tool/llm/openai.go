@@ -17,11 +17,10 @@ package llm
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
+	"io"
+	"strings"
 
 	"github.com/pkoukk/tiktoken-go"
 	"github.com/sashabaranov/go-openai"
@@ -35,11 +34,20 @@ const (
 	DefaultOpenAIModelTokenLimit = "4096"
 )
 
+var openAIModelTokenLimits = map[string]int{
+	openai.GPT3Dot5Turbo:    4096,
+	openai.GPT3Dot5Turbo16K: 16384,
+	openai.GPT4:             8192,
+	openai.GPT432K:          32768,
+}
+
+// OpenAIClient is used for OpenAI, Azure OpenAI and the services provide OpenAI compatible API, like vLLM
 type OpenAIClient struct {
-	name    string
-	model   string
-	client  *openai.Client
-	apiType string
+	name       string
+	model      string
+	client     *openai.Client
+	apiType    string
+	tokenLimit int
 }
 
 func (c *OpenAIClient) Configure(config LLMConfig) error {
@@ -55,22 +63,24 @@ func (c *OpenAIClient) Configure(config LLMConfig) error {
 			defaultConfig.APIType = openai.APITypeAzureAD
 		}
 	} else {
-		// config.GetAPIType() == "OPEN_AI"
+		// config.GetAPIType() == "OPEN_AI" or "OPEN_AI_COMPATIBLE"
 		c.apiType = "OPEN_AI"
 		defaultConfig = openai.DefaultConfig(token)
+		if config.GetAPIType() == "OPEN_AI_COMPATIBLE" {
+			c.apiType = "OPEN_AI_COMPATIBLE"
+			if config.GetBaseURL() == "" {
+				return errors.New("base url is required for openai compatible api")
+			}
+			defaultConfig.BaseURL = strings.TrimSuffix(config.GetBaseURL(), "/")
+		}
 	}
 
 	if config.GetProxy() != "" {
-		proxyUrl, err := url.Parse(config.GetProxy())
+		httpClient, err := newHTTPClient(config.GetProxy())
 		if err != nil {
-			return fmt.Errorf("invalid proxy url %s", config.GetProxy())
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-		defaultConfig.HTTPClient = &http.Client{
-			Transport: transport,
+			return err
 		}
+		defaultConfig.HTTPClient = httpClient
 	}
 
 	client := openai.NewClientWithConfig(defaultConfig)
@@ -81,11 +91,11 @@ func (c *OpenAIClient) Configure(config LLMConfig) error {
 	c.client = client
 	c.name = config.GetName()
 	c.model = config.GetModel()
+	c.tokenLimit = config.GetTokenLimit()
 	return nil
 }
 
-// @todo add ability to supply multiple messages
-func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string, options ...ParamOption) (string, error) {
+func (c *OpenAIClient) chatCompletionRequest(prompt string, options ...ParamOption) openai.ChatCompletionRequest {
 	opts := ParamOptions{}
 	for _, opt := range options {
 		opt(&opts)
@@ -101,86 +111,102 @@ func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string, options
 		}
 	}
 
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    "user",
-			Content: prompt,
+	// @todo add ability to supply multiple messages
+	return openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
 		},
+		MaxTokens:   opts.MaxTokens,
+		Temperature: opts.Temperature,
+		Stop:        opts.StopWords,
+		LogitBias:   opts.LogitBias,
 	}
+}
 
-	var resp openai.ChatCompletionResponse
-	var err error
-	if opts.MaxTokens == 0 {
-		resp, err = c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:       model,
-			Messages:    messages,
-			Temperature: opts.Temperature,
-			Stop:        opts.StopWords,
-			LogitBias:   opts.LogitBias,
-		})
-	} else {
-		resp, err = c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:       model,
-			Messages:    messages,
-			MaxTokens:   opts.MaxTokens,
-			Temperature: opts.Temperature,
-			Stop:        opts.StopWords,
-			LogitBias:   opts.LogitBias,
-		})
-	}
-
+func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string, options ...ParamOption) (string, error) {
+	resp, err := c.client.CreateChatCompletion(ctx, c.chatCompletionRequest(prompt, options...))
 	if err != nil {
 		return "", fmt.Errorf("create chat completion failed: %v", err)
 	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("create chat completion failed: no choice returned")
+	}
 
 	return resp.Choices[0].Message.Content, nil
 }
 
-func (a *OpenAIClient) Parse(ctx context.Context, prompt string, cache cache.ICache, options ...ParamOption) (string, error) {
-	// Check for cached data
-	cacheKey := GetCacheKey(a.GetName(), prompt)
+func (c *OpenAIClient) GetCompletionStream(ctx context.Context, prompt string, handler StreamHandler, options ...ParamOption) error {
+	req := c.chatCompletionRequest(prompt, options...)
+	req.Stream = true
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return fmt.Errorf("create chat completion stream failed: %v", err)
+	}
+	defer stream.Close()
 
-	if !cache.IsCacheDisabled() && cache.Exists(cacheKey) {
-		response, err := cache.Load(cacheKey)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
-			return "", err
+			return fmt.Errorf("receive chat completion stream failed: %v", err)
 		}
-
-		if response != "" {
-			output, err := base64.StdEncoding.DecodeString(response)
-			if err != nil {
-				log.Errorf("error decoding cached data: %v", err)
-				return "", nil
-			}
-			return string(output), nil
+		if len(resp.Choices) == 0 || resp.Choices[0].Delta.Content == "" {
+			continue
+		}
+		if err := handler(resp.Choices[0].Delta.Content); err != nil {
+			return err
 		}
 	}
+}
 
-	response, err := a.GetCompletion(ctx, prompt, options...)
-	if err != nil {
-		return "", err
-	}
-
-	err = cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response)))
-
-	if err != nil {
-		log.Errorf("error storing value to cache: %v", err)
-		return "", nil
-	}
-
-	return response, nil
+func (a *OpenAIClient) Parse(ctx context.Context, prompt string, cache cache.ICache, options ...ParamOption) (string, error) {
+	return parseWithCache(ctx, a, prompt, cache, options...)
 }
 
 func (a *OpenAIClient) GetName() string {
 	if a.name == "" {
 		if a.apiType == "AZURE" || a.apiType == "AZURE_AD" {
-			return "azureopenai"
+			return ProviderAzureOpenAI
 		}
-		return "openai"
+		if a.apiType == "OPEN_AI_COMPATIBLE" {
+			return ProviderOpenAICompatible
+		}
+		return ProviderOpenAI
 	}
 	return a.name
 }
 
+func (a *OpenAIClient) getModel() string {
+	if a.model == "" {
+		return DefaultOpenAIModel
+	}
+	return a.model
+}
+
+func (a *OpenAIClient) GetTokenLimit() int {
+	if a.tokenLimit > 0 {
+		return a.tokenLimit
+	}
+	if limit, ok := openAIModelTokenLimits[a.getModel()]; ok {
+		return limit
+	}
+	return 4096
+}
+
+func (a *OpenAIClient) CountTokens(prompt string) (int, error) {
+	if a.apiType == "OPEN_AI_COMPATIBLE" {
+		// the models served by compatible api usually have their own tokenizers
+		return estimateTokens(prompt), nil
+	}
+	return NumTokensFromPrompt(prompt, a.getModel())
+}
+
 func NumTokensFromMessages(messages []openai.ChatCompletionMessage, model string) (num_tokens int, err error) {
 	tkm, err := tiktoken.EncodingForModel(model)
 	if err != nil {
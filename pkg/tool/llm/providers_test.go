@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		req := &ollamaChatRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		assert.Equal(t, "qwen", req.Model)
+		assert.Equal(t, 8192, req.Options.NumCtx)
+
+		if !req.Stream {
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"hello world"},"done":true}`)
+			return
+		}
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"hello"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":" world"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderOllama)
+	require.NoError(t, err)
+	require.NoError(t, client.Configure(LLMConfig{BaseURL: server.URL + "/", Model: "qwen", TokenLimit: 8192}))
+
+	answer, err := client.GetCompletion(context.Background(), "hi", WithModel("gpt-3.5-turbo"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", answer)
+
+	streamed := ""
+	err = client.GetCompletionStream(context.Background(), "hi", func(delta string) error {
+		streamed += delta
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", streamed)
+	assert.Equal(t, ProviderOllama, client.GetName())
+	assert.Equal(t, 8192, client.GetTokenLimit())
+}
+
+func TestAnthropicClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+		req := &anthropicMessagesRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		assert.Equal(t, DefaultAnthropicModel, req.Model)
+		assert.Equal(t, DefaultAnthropicMaxTokens, req.MaxTokens)
+
+		if !req.Stream {
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"hello world"}]}`)
+			return
+		}
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hello\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\" world\"}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderAnthropic)
+	require.NoError(t, err)
+	require.NoError(t, client.Configure(LLMConfig{BaseURL: server.URL, Token: "token"}))
+
+	answer, err := client.GetCompletion(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", answer)
+
+	streamed := ""
+	err = client.GetCompletionStream(context.Background(), "hi", func(delta string) error {
+		streamed += delta
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", streamed)
+	assert.Equal(t, DefaultAnthropicTokenLimit, client.GetTokenLimit())
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model not found"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ProviderOllama)
+	require.NoError(t, err)
+	require.NoError(t, client.Configure(LLMConfig{BaseURL: server.URL}))
+
+	_, err = client.GetCompletion(context.Background(), "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not found")
+
+	_, err = NewClient("unknown")
+	require.Error(t, err)
+}
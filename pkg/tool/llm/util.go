@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koderover/zadig/pkg/tool/cache"
+	"github.com/koderover/zadig/pkg/tool/log"
+)
+
+// parseWithCache returns the cached answer of the prompt if there is one, otherwise asks the client and caches the answer
+func parseWithCache(ctx context.Context, client ILLM, prompt string, cache cache.ICache, options ...ParamOption) (string, error) {
+	cacheKey := GetCacheKey(client.GetName(), prompt)
+
+	if !cache.IsCacheDisabled() && cache.Exists(cacheKey) {
+		response, err := cache.Load(cacheKey)
+		if err != nil {
+			return "", err
+		}
+
+		if response != "" {
+			output, err := base64.StdEncoding.DecodeString(response)
+			if err != nil {
+				log.Errorf("error decoding cached data: %v", err)
+				return "", nil
+			}
+			return string(output), nil
+		}
+	}
+
+	response, err := client.GetCompletion(ctx, prompt, options...)
+	if err != nil {
+		return "", err
+	}
+
+	err = cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response)))
+	if err != nil {
+		log.Errorf("error storing value to cache: %v", err)
+		return "", nil
+	}
+
+	return response, nil
+}
+
+func newHTTPClient(proxy string) (*http.Client, error) {
+	if proxy == "" {
+		return &http.Client{}, nil
+	}
+	proxyUrl, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url %s", proxy)
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyURL(proxyUrl),
+		},
+	}, nil
+}
+
+// estimateTokens estimates the number of tokens for models without a public tokenizer,
+// about 4 bytes per token for English and 1 character per token for CJK text.
+func estimateTokens(prompt string) int {
+	runes := utf8.RuneCountInString(prompt)
+	ascii := 0
+	for i := 0; i < len(prompt); i++ {
+		if prompt[i] < utf8.RuneSelf {
+			ascii++
+		}
+	}
+	return ascii/4 + (runes - ascii) + 1
+}
+
+func readErrorBody(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("status code: %d, body: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// readLines calls f with every non-empty line of the streamed response body
+func readLines(body io.Reader, f func(line string) (bool, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		done, err := f(line)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
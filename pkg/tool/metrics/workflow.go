@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	WorkflowTaskQueueWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "workflow_task_queue_wait_seconds",
+			Help:    "Time a workflow task waits in the queue before it starts running, in seconds",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{"project", "workflow"},
+	)
+
+	WorkflowTaskDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "workflow_task_duration_seconds",
+			Help:    "Total running time of a workflow task, in seconds",
+			Buckets: prometheus.ExponentialBuckets(10, 2, 12),
+		},
+		[]string{"project", "workflow", "status"},
+	)
+
+	WorkflowJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "workflow_job_duration_seconds",
+			Help:    "Running time of a workflow job, in seconds",
+			Buckets: prometheus.ExponentialBuckets(5, 2, 12),
+		},
+		[]string{"project", "workflow", "job_type", "status"},
+	)
+
+	WorkflowJobRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workflow_job_retries_total",
+			Help: "Number of workflow jobs run again by retrying the workflow task, labelled by the status of the previous run",
+		},
+		[]string{"project", "workflow", "job_type", "status"},
+	)
+
+	WorkflowApprovalWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "workflow_approval_wait_seconds",
+			Help:    "Time a workflow stage waits for approval, in seconds",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+		},
+		[]string{"project", "workflow", "approval_type", "status"},
+	)
+
+	WorkflowJobPodScheduling = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "workflow_job_pod_scheduling_seconds",
+			Help:    "Time from the creation of a workflow job executor pod to it being scheduled, in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+		},
+		[]string{"project", "workflow", "job_type"},
+	)
+)
+
+func ObserveWorkflowTaskQueueWait(project, workflow string, seconds int64) {
+	WorkflowTaskQueueWait.WithLabelValues(project, workflow).Observe(float64(seconds))
+}
+
+func ObserveWorkflowTaskDuration(project, workflow, status string, seconds int64) {
+	WorkflowTaskDuration.WithLabelValues(project, workflow, status).Observe(float64(seconds))
+}
+
+func ObserveWorkflowJobDuration(project, workflow, jobType, status string, seconds int64) {
+	WorkflowJobDuration.WithLabelValues(project, workflow, jobType, status).Observe(float64(seconds))
+}
+
+func IncWorkflowJobRetries(project, workflow, jobType, status string) {
+	WorkflowJobRetries.WithLabelValues(project, workflow, jobType, status).Inc()
+}
+
+func ObserveWorkflowApprovalWait(project, workflow, approvalType, status string, seconds int64) {
+	WorkflowApprovalWait.WithLabelValues(project, workflow, approvalType, status).Observe(float64(seconds))
+}
+
+func ObserveWorkflowJobPodScheduling(project, workflow, jobType string, seconds float64) {
+	WorkflowJobPodScheduling.WithLabelValues(project, workflow, jobType).Observe(seconds)
+}
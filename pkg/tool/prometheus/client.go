@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"github.com/pkg/errors"
+)
+
+// Client queries the HTTP API of Prometheus or any compatible endpoint like Thanos Querier
+type Client struct {
+	*req.Client
+	BaseURL string
+}
+
+func NewClient(url, token string) *Client {
+	client := req.C().
+		OnAfterResponse(func(client *req.Client, resp *req.Response) error {
+			if resp.Err != nil {
+				resp.Err = errors.Wrapf(resp.Err, "body: %s", resp.String())
+				return nil
+			}
+			if !resp.IsSuccessState() {
+				resp.Err = errors.Errorf("unexpected status code %d, body: %s", resp.GetStatusCode(), resp.String())
+				return nil
+			}
+			return nil
+		})
+	if token != "" {
+		client.SetCommonBearerAuthToken(token)
+	}
+	return &Client{
+		Client:  client,
+		BaseURL: url,
+	}
+}
+
+type QueryResponse struct {
+	Status    string     `json:"status"`
+	Data      *QueryData `json:"data"`
+	ErrorType string     `json:"errorType"`
+	Error     string     `json:"error"`
+}
+
+type QueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+// QueryValue runs an instant query and returns its value, the query must be evaluated to a scalar
+// or a vector with exactly one sample, e.g. an aggregation like sum(rate(...)).
+func (c *Client) QueryValue(query string, t time.Time) (float64, error) {
+	resp := new(QueryResponse)
+	_, err := c.R().
+		SetFormData(map[string]string{
+			"query": query,
+			"time":  strconv.FormatInt(t.Unix(), 10),
+		}).
+		SetSuccessResult(resp).
+		Post(c.BaseURL + "/api/v1/query")
+	if err != nil {
+		return 0, err
+	}
+	if resp.Status != "success" {
+		return 0, errors.Errorf("query failed, %s: %s", resp.ErrorType, resp.Error)
+	}
+	if resp.Data == nil {
+		return 0, errors.New("query returns no data")
+	}
+
+	switch resp.Data.ResultType {
+	case "scalar":
+		return parseSampleValue(resp.Data.Result)
+	case "vector":
+		samples, ok := resp.Data.Result.([]interface{})
+		if !ok {
+			return 0, errors.Errorf("invalid vector result %v", resp.Data.Result)
+		}
+		if len(samples) == 0 {
+			return 0, errors.New("query returns no data")
+		}
+		if len(samples) > 1 {
+			return 0, errors.Errorf("query returns %d series, it should be aggregated to one series", len(samples))
+		}
+		sample, ok := samples[0].(map[string]interface{})
+		if !ok {
+			return 0, errors.Errorf("invalid sample %v", samples[0])
+		}
+		return parseSampleValue(sample["value"])
+	default:
+		return 0, errors.Errorf("unsupported result type %s", resp.Data.ResultType)
+	}
+}
+
+// parseSampleValue parses the value of a sample in the format of [<unix_time>, "<sample_value>"]
+func parseSampleValue(value interface{}) (float64, error) {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return 0, errors.Errorf("invalid sample value %v", value)
+	}
+	s, ok := pair[1].(string)
+	if !ok {
+		return 0, errors.Errorf("invalid sample value %v", pair[1])
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid sample value %s", s)
+	}
+	if math.IsNaN(v) {
+		return 0, errors.New("query returns NaN")
+	}
+	return v, nil
+}
+
+type Operator string
+
+const (
+	OperatorLessThan       Operator = "<"
+	OperatorLessOrEqual    Operator = "<="
+	OperatorGreaterThan    Operator = ">"
+	OperatorGreaterOrEqual Operator = ">="
+)
+
+// Compare returns whether "value operator target" holds
+func Compare(value float64, operator Operator, target float64) (bool, error) {
+	switch operator {
+	case OperatorLessThan:
+		return value < target, nil
+	case OperatorLessOrEqual:
+		return value <= target, nil
+	case OperatorGreaterThan:
+		return value > target, nil
+	case OperatorGreaterOrEqual:
+		return value >= target, nil
+	default:
+		return false, fmt.Errorf("invalid operator %s", operator)
+	}
+}
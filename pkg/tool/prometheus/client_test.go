@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStubServer(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, r.ParseForm())
+		data, ok := results[r.PostForm.Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":%s}`, data)
+	}))
+}
+
+func TestQueryValue(t *testing.T) {
+	server := newStubServer(t, map[string]string{
+		"scalar(1)":      `{"resultType":"scalar","result":[1700000000,"1"]}`,
+		"error_rate":     `{"resultType":"vector","result":[{"metric":{"app":"a"},"value":[1700000000,"0.05"]}]}`,
+		"no_data":        `{"resultType":"vector","result":[]}`,
+		"multi_series":   `{"resultType":"vector","result":[{"metric":{"app":"a"},"value":[1700000000,"1"]},{"metric":{"app":"b"},"value":[1700000000,"2"]}]}`,
+		"nan":            `{"resultType":"scalar","result":[1700000000,"NaN"]}`,
+		"range_selector": `{"resultType":"matrix","result":[]}`,
+	})
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	now := time.Now()
+
+	v, err := client.QueryValue("scalar(1)", now)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), v)
+
+	v, err = client.QueryValue("error_rate", now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.05, v)
+
+	for _, query := range []string{"no_data", "multi_series", "nan", "range_selector", "invalid"} {
+		_, err = client.QueryValue(query, now)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		value    float64
+		operator Operator
+		target   float64
+		want     bool
+	}{
+		{0.01, OperatorLessThan, 0.05, true},
+		{0.05, OperatorLessThan, 0.05, false},
+		{0.05, OperatorLessOrEqual, 0.05, true},
+		{99.9, OperatorGreaterThan, 99, true},
+		{99, OperatorGreaterOrEqual, 99, true},
+		{98, OperatorGreaterOrEqual, 99, false},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.value, tt.operator, tt.target)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%v %s %v", tt.value, tt.operator, tt.target)
+	}
+
+	_, err := Compare(1, "==", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// Severities lists the normalised severities from the most to the least severe.
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+var severityRanks = map[string]int{
+	SeverityCritical: 5,
+	SeverityHigh:     4,
+	SeverityMedium:   3,
+	SeverityLow:      2,
+	SeverityInfo:     1,
+}
+
+// Log is the subset of a SARIF 2.1 log needed to extract findings.
+type Log struct {
+	Version string `json:"version"`
+	Runs    []*Run `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool      `json:"tool"`
+	Results []*Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name  string  `json:"name"`
+	Rules []*Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID                   string                 `json:"id"`
+	DefaultConfiguration *ReportingConfig       `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type ReportingConfig struct {
+	Level string `json:"level"`
+}
+
+type Result struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           *int                   `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             Message                `json:"message"`
+	Locations           []*Location            `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Properties          map[string]interface{} `json:"properties"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation *ArtifactLocation `json:"artifactLocation"`
+	Region           *Region           `json:"region"`
+}
+
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// Finding is a scanner result normalised across tools.
+type Finding struct {
+	Tool        string `bson:"tool"        json:"tool"`
+	RuleID      string `bson:"rule_id"     json:"rule_id"`
+	Severity    string `bson:"severity"    json:"severity"`
+	Message     string `bson:"message"     json:"message"`
+	File        string `bson:"file"        json:"file"`
+	Line        int    `bson:"line"        json:"line"`
+	Fingerprint string `bson:"fingerprint" json:"fingerprint"`
+	// New is true if the finding does not exist in the baseline
+	New bool `bson:"new"         json:"new"`
+}
+
+// Report is the normalised result of a sarif_report step, it is uploaded to the object storage
+// by the step and used as the baseline of the later runs.
+type Report struct {
+	Tools       []string       `bson:"tools"        json:"tools"`
+	Findings    []*Finding     `bson:"findings"     json:"findings"`
+	Summary     map[string]int `bson:"summary"      json:"summary"`
+	NewSummary  map[string]int `bson:"new_summary"  json:"new_summary"`
+	HasBaseline bool           `bson:"has_baseline" json:"has_baseline"`
+}
+
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+	return ok
+}
+
+// AtLeast returns whether the severity is equal to or more severe than the threshold.
+func AtLeast(severity, threshold string) bool {
+	return severityRanks[severity] >= severityRanks[threshold]
+}
+
+// Parse extracts the findings from a SARIF 2.1 log.
+func Parse(data []byte) ([]*Finding, error) {
+	sarifLog := &Log{}
+	if err := json.Unmarshal(data, sarifLog); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sarif log: %s", err)
+	}
+	if !strings.HasPrefix(sarifLog.Version, "2.1") {
+		return nil, fmt.Errorf("unsupported sarif version: %s", sarifLog.Version)
+	}
+
+	findings := make([]*Finding, 0)
+	for _, run := range sarifLog.Runs {
+		rules := make(map[string]*Rule)
+		for _, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = rule
+		}
+		for _, result := range run.Results {
+			var rule *Rule
+			if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(run.Tool.Driver.Rules) {
+				rule = run.Tool.Driver.Rules[*result.RuleIndex]
+			} else {
+				rule = rules[result.RuleID]
+			}
+			ruleID := result.RuleID
+			if ruleID == "" && rule != nil {
+				ruleID = rule.ID
+			}
+
+			finding := &Finding{
+				Tool:     run.Tool.Driver.Name,
+				RuleID:   ruleID,
+				Severity: resultSeverity(result, rule),
+				Message:  result.Message.Text,
+			}
+			if len(result.Locations) > 0 && result.Locations[0].PhysicalLocation != nil {
+				location := result.Locations[0].PhysicalLocation
+				if location.ArtifactLocation != nil {
+					finding.File = strings.TrimPrefix(location.ArtifactLocation.URI, "file://")
+				}
+				if location.Region != nil {
+					finding.Line = location.Region.StartLine
+				}
+			}
+			finding.Fingerprint = fingerprint(finding, result.PartialFingerprints)
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// Collect parses the sarif file at path, or all the .sarif and .sarif.json files under it if it is a directory.
+func Collect(path string) ([]*Finding, []string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = []string{}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (strings.HasSuffix(d.Name(), ".sarif") || strings.HasSuffix(d.Name(), ".sarif.json")) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no sarif file found in %s", path)
+	}
+
+	findings := make([]*Finding, 0)
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileFindings, err := Parse(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %s", file, err)
+		}
+		for _, finding := range fileFindings {
+			if seen[finding.Fingerprint] {
+				continue
+			}
+			seen[finding.Fingerprint] = true
+			findings = append(findings, finding)
+		}
+	}
+	return findings, files, nil
+}
+
+// NewReport builds a report from the findings, findings which are not in the baseline are marked as new.
+// All the findings are new if there is no baseline.
+func NewReport(findings []*Finding, baseline *Report) *Report {
+	baselineFingerprints := make(map[string]bool)
+	if baseline != nil {
+		for _, finding := range baseline.Findings {
+			baselineFingerprints[finding.Fingerprint] = true
+		}
+	}
+
+	report := &Report{
+		Tools:       []string{},
+		Findings:    findings,
+		Summary:     make(map[string]int),
+		NewSummary:  make(map[string]int),
+		HasBaseline: baseline != nil,
+	}
+	tools := make(map[string]bool)
+	for _, finding := range findings {
+		finding.New = !baselineFingerprints[finding.Fingerprint]
+		report.Summary[finding.Severity]++
+		if finding.New {
+			report.NewSummary[finding.Severity]++
+		}
+		if !tools[finding.Tool] {
+			tools[finding.Tool] = true
+			report.Tools = append(report.Tools, finding.Tool)
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityRanks[report.Findings[i].Severity] > severityRanks[report.Findings[j].Severity]
+	})
+	return report
+}
+
+// Exceeding returns the findings at or above the severity threshold, only the new findings are
+// returned if newOnly is set.
+func (r *Report) Exceeding(threshold string, newOnly bool) []*Finding {
+	resp := make([]*Finding, 0)
+	if !ValidSeverity(threshold) {
+		return resp
+	}
+	for _, finding := range r.Findings {
+		if newOnly && !finding.New {
+			continue
+		}
+		if AtLeast(finding.Severity, threshold) {
+			resp = append(resp, finding)
+		}
+	}
+	return resp
+}
+
+// resultSeverity uses the security-severity score set by the security scanners like CodeQL and Trivy,
+// and falls back to the sarif level of the result or its rule.
+func resultSeverity(result *Result, rule *Rule) string {
+	if score, ok := securitySeverity(result.Properties); ok {
+		return scoreSeverity(score)
+	}
+	if rule != nil {
+		if score, ok := securitySeverity(rule.Properties); ok {
+			return scoreSeverity(score)
+		}
+	}
+
+	level := result.Level
+	if level == "" && rule != nil && rule.DefaultConfiguration != nil {
+		level = rule.DefaultConfiguration.Level
+	}
+	switch level {
+	case "error":
+		return SeverityHigh
+	case "note":
+		return SeverityLow
+	case "none":
+		return SeverityInfo
+	default:
+		// warning is the default level of sarif
+		return SeverityMedium
+	}
+}
+
+func securitySeverity(properties map[string]interface{}) (float64, bool) {
+	value, ok := properties["security-severity"]
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		score, err := strconv.ParseFloat(v, 64)
+		return score, err == nil
+	}
+	return 0, false
+}
+
+func scoreSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// fingerprint identifies a finding across runs, the line is not part of it so that
+// findings are not reported as new when the code above them changes.
+func fingerprint(finding *Finding, partialFingerprints map[string]string) string {
+	parts := []string{finding.Tool, finding.RuleID, finding.File}
+	if len(partialFingerprints) > 0 {
+		keys := make([]string, 0, len(partialFingerprints))
+		for k := range partialFingerprints {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			parts = append(parts, k+"="+partialFingerprints[k])
+		}
+	} else {
+		parts = append(parts, finding.Message)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testLog = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "gosec", "rules": [
+      {"id": "G101", "defaultConfiguration": {"level": "error"}},
+      {"id": "G104", "properties": {"security-severity": "9.1"}}
+    ]}},
+    "results": [
+      {"ruleId": "G101", "message": {"text": "hardcoded credentials"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 12}}}]},
+      {"ruleId": "G104", "ruleIndex": 1, "message": {"text": "errors unhandled"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file://pkg/a.go"}, "region": {"startLine": 3}}}]},
+      {"ruleId": "G999", "level": "note", "message": {"text": "style"}}
+    ]
+  }]
+}`
+
+func TestParse(t *testing.T) {
+	ast := require.New(t)
+
+	findings, err := Parse([]byte(testLog))
+	ast.Nil(err)
+	ast.Len(findings, 3)
+
+	ast.Equal("gosec", findings[0].Tool)
+	ast.Equal(SeverityHigh, findings[0].Severity)
+	ast.Equal("main.go", findings[0].File)
+	ast.Equal(12, findings[0].Line)
+
+	ast.Equal(SeverityCritical, findings[1].Severity)
+	ast.Equal("pkg/a.go", findings[1].File)
+
+	ast.Equal(SeverityLow, findings[2].Severity)
+
+	_, err = Parse([]byte(`{"version": "2.0.0"}`))
+	ast.NotNil(err)
+}
+
+func TestNewReport(t *testing.T) {
+	ast := require.New(t)
+
+	baselineFindings, err := Parse([]byte(testLog))
+	ast.Nil(err)
+	baseline := NewReport(baselineFindings[:2], nil)
+	ast.False(baseline.HasBaseline)
+	ast.Len(baseline.Exceeding(SeverityHigh, true), 2)
+
+	findings, err := Parse([]byte(testLog))
+	ast.Nil(err)
+	// moving a finding to another line keeps it the same finding
+	findings[0].Line = 20
+	report := NewReport(findings, baseline)
+	ast.True(report.HasBaseline)
+	ast.Equal(SeverityCritical, report.Findings[0].Severity)
+	ast.Equal(1, report.NewSummary[SeverityLow])
+	ast.Equal(0, report.NewSummary[SeverityHigh])
+
+	ast.Len(report.Exceeding(SeverityLow, true), 1)
+	ast.Len(report.Exceeding(SeverityHigh, false), 2)
+	ast.Len(report.Exceeding(SeverityHigh, true), 0)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Allowlist is the paths each provider is allowed to read, a path allows itself and the paths under it,
+// e.g. a namespace for the kubernetes provider or a mount and its sub path for the vault provider.
+type Allowlist map[string][]string
+
+func (a Allowlist) Allows(ref *Reference) bool {
+	refPath := strings.Trim(ref.Path, "/")
+	// reject the relative segments instead of resolving them, they could escape the allowed path
+	if refPath == "" || refPath != strings.Trim(path.Clean("/"+refPath), "/") {
+		return false
+	}
+	for _, allowed := range a[ref.Provider] {
+		allowed = strings.Trim(path.Clean("/"+allowed), "/")
+		if allowed == "" {
+			continue
+		}
+		if refPath == allowed || strings.HasPrefix(refPath, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures the providers resolving the secret references of a job. It is sent to the job executor
+// with the job context, so it holds no credential: the executor reads the vault token from its own environment,
+// or logs in vault with the kubernetes auth method.
+type Config struct {
+	VaultAddr         string    `yaml:"vault_addr,omitempty"`
+	VaultK8sRole      string    `yaml:"vault_k8s_role,omitempty"`
+	VaultK8sAuthPath  string    `yaml:"vault_k8s_auth_path,omitempty"`
+	FileRoot          string    `yaml:"file_root,omitempty"`
+	KubernetesEnabled bool      `yaml:"kubernetes_enabled,omitempty"`
+	Allowlist         Allowlist `yaml:"allowlist,omitempty"`
+}
+
+func (c *Config) providerEnabled(provider string) bool {
+	switch provider {
+	case ProviderVault:
+		return c.VaultAddr != ""
+	case ProviderKubernetes:
+		return c.KubernetesEnabled
+	case ProviderFile:
+		return c.FileRoot != ""
+	default:
+		return false
+	}
+}
+
+// Check returns an error if the value is a reference to a provider not configured or not in the allowlist
+func (c *Config) Check(value string) error {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return err
+	}
+	if !c.providerEnabled(ref.Provider) {
+		return fmt.Errorf("secret provider %s is not configured", ref.Provider)
+	}
+	if !c.Allowlist.Allows(ref) {
+		return fmt.Errorf("secret reference %s is not in the allowlist", ref)
+	}
+	return nil
+}
+
+// CheckVMReferences returns an error if any of the values refers to a kubernetes secret, the jobs running on vms
+// resolve the references on the vm, which has no access to the clusters
+func CheckVMReferences(values ...string) error {
+	for _, value := range values {
+		if !IsReference(value) {
+			continue
+		}
+		ref, err := ParseReference(value)
+		if err != nil {
+			return err
+		}
+		if ref.Provider == ProviderKubernetes {
+			return fmt.Errorf("secret reference %s is not supported by the jobs running on vms, the %s provider is only available in the clusters", ref, ProviderKubernetes)
+		}
+	}
+	return nil
+}
+
+// NewResolver registers the configured providers, the kubernetes provider is only registered if a clientset is given
+func (c *Config) NewResolver(vaultToken string, clientset kubernetes.Interface) *Resolver {
+	resolver := NewResolver(c.Allowlist)
+	if c.VaultAddr != "" {
+		resolver.Register(ProviderVault, NewVaultProvider(c.VaultAddr, vaultToken, c.VaultK8sRole, c.VaultK8sAuthPath))
+	}
+	if c.KubernetesEnabled && clientset != nil {
+		resolver.Register(ProviderKubernetes, NewKubernetesProvider(clientset))
+	}
+	if c.FileRoot != "" {
+		resolver.Register(ProviderFile, NewFileProvider(c.FileRoot))
+	}
+	return resolver
+}
+
+// ResolveEnvs resolves the secret references in the values of the envs in the form of key=value
+func (r *Resolver) ResolveEnvs(ctx context.Context, envs []string) ([]string, error) {
+	resp := make([]string, 0, len(envs))
+	for _, env := range envs {
+		key, value, found := strings.Cut(env, "=")
+		if !found || !IsReference(value) {
+			resp = append(resp, env)
+			continue
+		}
+		secret, err := r.Resolve(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env %s: %s", key, err)
+		}
+		resp = append(resp, key+"="+secret)
+	}
+	return resp, nil
+}
+
+// ResolveObject replaces the secret references in the string values of the object, e.g. an unstructured manifest
+// or the values of a helm chart, with the secrets. Only the values which are references as a whole are resolved.
+func (r *Resolver) ResolveObject(ctx context.Context, obj interface{}) (interface{}, error) {
+	switch value := obj.(type) {
+	case string:
+		return r.Resolve(ctx, value)
+	case map[string]interface{}:
+		for key, item := range value {
+			resolved, err := r.ResolveObject(ctx, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", key, err)
+			}
+			value[key] = resolved
+		}
+	case []interface{}:
+		for i, item := range value {
+			resolved, err := r.ResolveObject(ctx, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", i, err)
+			}
+			value[i] = resolved
+		}
+	}
+	return obj, nil
+}
+
+// ResolveYaml resolves the secret references in the values of a yaml document, e.g. the values of a helm chart,
+// the content is returned as is if there is no reference
+func (r *Resolver) ResolveYaml(ctx context.Context, content string) (string, error) {
+	if !ContainsReference(content) {
+		return content, nil
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(content), &values); err != nil {
+		return "", err
+	}
+	if _, err := r.ResolveObject(ctx, values); err != nil {
+		return "", err
+	}
+	resp, err := yaml.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(resp), nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FileProvider reads secrets from the files under a root directory, e.g. the files mounted by
+// the secrets store CSI driver or the vault agent. The path of a reference is relative to the root,
+// the whole file is the secret if no key is given, otherwise the file is parsed as a YAML or JSON map.
+type FileProvider struct {
+	root string
+}
+
+func NewFileProvider(root string) *FileProvider {
+	return &FileProvider{root: root}
+}
+
+func (p *FileProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	fullPath := filepath.Join(p.root, filepath.Clean("/"+path))
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return "", fmt.Errorf("failed to parse secret file %s: %s", path, err)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret file %s", key, path)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubernetesProvider reads secrets from the Secret objects of a cluster.
+// The path of a reference is <namespace>/<secret name>.
+type KubernetesProvider struct {
+	clientset kubernetes.Interface
+}
+
+func NewKubernetesProvider(clientset kubernetes.Interface) *KubernetesProvider {
+	return &KubernetesProvider{clientset: clientset}
+}
+
+func (p *KubernetesProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("key is required for kubernetes secrets")
+	}
+	namespace, name, found := strings.Cut(strings.Trim(path, "/"), "/")
+	if !found || name == "" {
+		return "", fmt.Errorf("invalid kubernetes secret path %s, expected <namespace>/<name>", path)
+	}
+	secret, err := p.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", key, path)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReferencePrefix marks a value as a reference to a secret in an external provider.
+// A reference is in the form of secret://<provider>/<path>#<key>, e.g. secret://vault/kv/app/db#password.
+const ReferencePrefix = "secret://"
+
+const (
+	ProviderVault      = "vault"
+	ProviderKubernetes = "kubernetes"
+	ProviderFile       = "file"
+)
+
+// Provider fetches a secret from an external secret store
+type Provider interface {
+	GetSecret(ctx context.Context, path, key string) (string, error)
+}
+
+type Reference struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+func IsReference(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), ReferencePrefix)
+}
+
+// ContainsReference reports whether the content, e.g. a values yaml, contains a secret reference
+func ContainsReference(content string) bool {
+	return strings.Contains(content, ReferencePrefix)
+}
+
+func ParseReference(value string) (*Reference, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, ReferencePrefix) {
+		return nil, fmt.Errorf("%q is not a secret reference", value)
+	}
+	ref := &Reference{}
+	location := strings.TrimPrefix(value, ReferencePrefix)
+	if i := strings.LastIndex(location, "#"); i >= 0 {
+		location, ref.Key = location[:i], location[i+1:]
+	}
+	i := strings.Index(location, "/")
+	if i <= 0 || i == len(location)-1 {
+		return nil, fmt.Errorf("invalid secret reference %q, expected %s<provider>/<path>#<key>", value, ReferencePrefix)
+	}
+	ref.Provider, ref.Path = location[:i], location[i+1:]
+	return ref, nil
+}
+
+func (r *Reference) String() string {
+	s := ReferencePrefix + r.Provider + "/" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Resolver resolves the secret references in the allowlist with the registered providers
+type Resolver struct {
+	providers map[string]Provider
+	allowlist Allowlist
+}
+
+func NewResolver(allowlist Allowlist) *Resolver {
+	return &Resolver{
+		providers: make(map[string]Provider),
+		allowlist: allowlist,
+	}
+}
+
+func (r *Resolver) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+// Resolve returns the secret the value refers to, values which are not references are returned as is
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+	if !r.allowlist.Allows(ref) {
+		return "", fmt.Errorf("secret reference %s is not in the allowlist", ref)
+	}
+	provider, ok := r.providers[ref.Provider]
+	if !ok {
+		return "", fmt.Errorf("secret provider %s is not configured", ref.Provider)
+	}
+	secret, err := provider.GetSecret(ctx, ref.Path, ref.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %s", ref, err)
+	}
+	return secret, nil
+}
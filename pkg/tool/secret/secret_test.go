@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	ref, err := ParseReference("secret://vault/kv/app/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, &Reference{Provider: ProviderVault, Path: "kv/app/db", Key: "password"}, ref)
+	assert.Equal(t, "secret://vault/kv/app/db#password", ref.String())
+
+	ref, err = ParseReference("secret://file/token")
+	require.NoError(t, err)
+	assert.Equal(t, &Reference{Provider: ProviderFile, Path: "token"}, ref)
+
+	for _, value := range []string{"plain", "secret://vault", "secret://vault/", "secret:///path#key"} {
+		_, err := ParseReference(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "token"), []byte("s3cr3t=\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "db.yaml"), []byte("user: admin\npassword: p@ss\n"), 0600))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "other"), []byte("other"), 0600))
+
+	resolver := NewResolver(Allowlist{ProviderFile: {"token", "db.yaml"}, ProviderVault: {"kv/app"}})
+	resolver.Register(ProviderFile, NewFileProvider(root))
+	ctx := context.Background()
+
+	value, err := resolver.Resolve(ctx, "plain value")
+	require.NoError(t, err)
+	assert.Equal(t, "plain value", value)
+
+	value, err = resolver.Resolve(ctx, "secret://file/token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t=", value)
+
+	value, err = resolver.Resolve(ctx, "secret://file/db.yaml#password")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss", value)
+
+	_, err = resolver.Resolve(ctx, "secret://file/db.yaml#missing")
+	assert.Error(t, err)
+	_, err = resolver.Resolve(ctx, "secret://file/../../etc/passwd")
+	assert.Error(t, err)
+	_, err = resolver.Resolve(ctx, "secret://file/other")
+	assert.Error(t, err)
+	_, err = resolver.Resolve(ctx, "secret://vault/kv/app#key")
+	assert.Error(t, err)
+}
+
+func TestAllowlist(t *testing.T) {
+	allowlist := Allowlist{
+		ProviderKubernetes: {"team-a"},
+		ProviderVault:      {"/kv/app/"},
+	}
+	tests := []struct {
+		value   string
+		allowed bool
+	}{
+		{"secret://kubernetes/team-a/db#password", true},
+		{"secret://kubernetes/team-a#password", true},
+		{"secret://kubernetes/team-ab/db#password", false},
+		{"secret://kubernetes/team-b/db#password", false},
+		{"secret://kubernetes/team-a/../team-b/db#password", false},
+		{"secret://vault/kv/app/db#password", true},
+		{"secret://vault/kv/other#password", false},
+		{"secret://file/kv/app/db", false},
+	}
+	for _, tt := range tests {
+		ref, err := ParseReference(tt.value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.allowed, allowlist.Allows(ref), tt.value)
+	}
+	ref, err := ParseReference("secret://kubernetes/team-a/db#password")
+	require.NoError(t, err)
+	assert.False(t, Allowlist(nil).Allows(ref))
+}
+
+func TestConfig(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "token"), []byte("s3cr3t"), 0600))
+	cfg := &Config{
+		FileRoot:  root,
+		Allowlist: Allowlist{ProviderFile: {"token"}, ProviderKubernetes: {"team-a"}},
+	}
+
+	assert.NoError(t, cfg.Check("secret://file/token"))
+	assert.Error(t, cfg.Check("secret://file/other"))
+	// the kubernetes provider is not enabled even if it is in the allowlist
+	assert.Error(t, cfg.Check("secret://kubernetes/team-a/db#password"))
+	assert.Error(t, cfg.Check("secret://vault"))
+
+	envs, err := cfg.NewResolver("", nil).ResolveEnvs(context.Background(), []string{"TOKEN=secret://file/token", "PLAIN=a=b", "EMPTY"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN=s3cr3t", "PLAIN=a=b", "EMPTY"}, envs)
+
+	_, err = cfg.NewResolver("", nil).ResolveEnvs(context.Background(), []string{"OTHER=secret://file/other"})
+	assert.Error(t, err)
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/app/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{"password": "p@ss"},
+			},
+		})
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	value, err := NewVaultProvider(server.URL, "root", "", "").GetSecret(ctx, "kv/app/db", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss", value)
+
+	_, err = NewVaultProvider(server.URL, "root", "", "").GetSecret(ctx, "kv/app/db", "missing")
+	assert.Error(t, err)
+	_, err = NewVaultProvider(server.URL, "wrong", "", "").GetSecret(ctx, "kv/app/db", "password")
+	assert.Error(t, err)
+}
+
+func TestResolveObject(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "db.yaml"), []byte("user: admin\npassword: p@ss\n"), 0600))
+
+	resolver := NewResolver(Allowlist{ProviderFile: {"db.yaml"}})
+	resolver.Register(ProviderFile, NewFileProvider(root))
+	ctx := context.Background()
+
+	manifest := map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"env": []interface{}{
+				map[string]interface{}{"name": "DB_PASSWORD", "value": "secret://file/db.yaml#password"},
+				map[string]interface{}{"name": "DB_URL", "value": "mysql://secret://file/db.yaml#password"},
+			},
+		},
+	}
+	_, err := resolver.ResolveObject(ctx, manifest)
+	require.NoError(t, err)
+	env := manifest["spec"].(map[string]interface{})["env"].([]interface{})
+	assert.Equal(t, "p@ss", env[0].(map[string]interface{})["value"])
+	// only the values which are references as a whole are resolved
+	assert.Equal(t, "mysql://secret://file/db.yaml#password", env[1].(map[string]interface{})["value"])
+	assert.Equal(t, int64(2), manifest["spec"].(map[string]interface{})["replicas"])
+
+	_, err = resolver.ResolveObject(ctx, map[string]interface{}{"a": []interface{}{"secret://file/other"}})
+	assert.EqualError(t, err, "a: [0]: secret reference secret://file/other is not in the allowlist")
+}
+
+func TestResolveYaml(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "db.yaml"), []byte("user: admin\npassword: p@ss\n"), 0600))
+
+	resolver := NewResolver(Allowlist{ProviderFile: {"db.yaml"}})
+	resolver.Register(ProviderFile, NewFileProvider(root))
+	ctx := context.Background()
+
+	values := "# no reference\nimage: nginx\n"
+	resolved, err := resolver.ResolveYaml(ctx, values)
+	require.NoError(t, err)
+	assert.Equal(t, values, resolved)
+
+	resolved, err = resolver.ResolveYaml(ctx, "db:\n  user: secret://file/db.yaml#user\n  password: 'secret://file/db.yaml#password'\nreplicas: 2\n")
+	require.NoError(t, err)
+	assert.Equal(t, "db:\n  password: p@ss\n  user: admin\nreplicas: 2\n", resolved)
+
+	_, err = resolver.ResolveYaml(ctx, "db: secret://vault/kv/app#password\n")
+	assert.Error(t, err)
+}
+
+func TestCheckVMReferences(t *testing.T) {
+	assert.NoError(t, CheckVMReferences("plain", "secret://vault/kv/app#password", "secret://file/token"))
+	assert.Error(t, CheckVMReferences("plain", "secret://kubernetes/zadig/db#password"))
+	assert.Error(t, CheckVMReferences("secret://vault"))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imroc/req/v3"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultVaultKubernetesAuthPath = "kubernetes"
+	serviceAccountTokenPath        = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// VaultProvider reads secrets from the KV version 2 secrets engine of HashiCorp Vault.
+// The path of a reference is <mount>/<secret path>, e.g. kv/app/db.
+type VaultProvider struct {
+	client *req.Client
+	addr   string
+	token  string
+	// role and authPath are used to login with the kubernetes auth method if no token is given
+	role     string
+	authPath string
+}
+
+func NewVaultProvider(addr, token, role, authPath string) *VaultProvider {
+	client := req.C().
+		OnAfterResponse(func(client *req.Client, resp *req.Response) error {
+			if resp.Err != nil {
+				return nil
+			}
+			if !resp.IsSuccessState() {
+				resp.Err = errors.Errorf("unexpected status code %d, body: %s", resp.GetStatusCode(), resp.String())
+			}
+			return nil
+		})
+	if authPath == "" {
+		authPath = defaultVaultKubernetesAuthPath
+	}
+	return &VaultProvider{
+		client:   client,
+		addr:     strings.TrimSuffix(addr, "/"),
+		token:    token,
+		role:     role,
+		authPath: strings.Trim(authPath, "/"),
+	}
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+type vaultLoginResponse struct {
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, path, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("key is required for vault secrets")
+	}
+	mount, secretPath, found := strings.Cut(strings.Trim(path, "/"), "/")
+	if !found || secretPath == "" {
+		return "", fmt.Errorf("invalid vault path %s, expected <mount>/<secret path>", path)
+	}
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	resp := new(vaultKVResponse)
+	_, err = p.client.R().
+		SetContext(ctx).
+		SetHeader("X-Vault-Token", token).
+		SetSuccessResult(resp).
+		Get(fmt.Sprintf("%s/v1/%s/data/%s", p.addr, mount, secretPath))
+	if err != nil {
+		return "", err
+	}
+	value, ok := resp.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (p *VaultProvider) getToken(ctx context.Context) (string, error) {
+	if p.token != "" {
+		return p.token, nil
+	}
+	if p.role == "" {
+		return "", fmt.Errorf("neither token nor kubernetes auth role is configured for vault")
+	}
+	jwt, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %s", err)
+	}
+
+	resp := new(vaultLoginResponse)
+	_, err = p.client.R().
+		SetContext(ctx).
+		SetBodyJsonMarshal(map[string]string{"role": p.role, "jwt": string(jwt)}).
+		SetSuccessResult(resp).
+		Post(fmt.Sprintf("%s/v1/auth/%s/login", p.addr, p.authPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to login vault with kubernetes auth: %s", err)
+	}
+	p.token = resp.Auth.ClientToken
+	return p.token, nil
+}
@@ -178,11 +178,18 @@ func getKeyValue(content, inputKey string) string {
 }
 
 func PrintSonarConditionTables(conditions []Condition) {
-	fmt.Printf("%-40s|%-10s|%-10s|%-10s|%-20s|\n", "Metric", "Status", "Operator", "Threshold", "Actualvalue")
+	fmt.Print(FormatSonarConditionTables(conditions))
+}
+
+// FormatSonarConditionTables returns the quality gate conditions as a table, it is used where stdout is not the job log
+func FormatSonarConditionTables(conditions []Condition) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-40s|%-10s|%-10s|%-10s|%-20s|\n", "Metric", "Status", "Operator", "Threshold", "Actualvalue")
 	for _, condition := range conditions {
-		fmt.Printf("%-40s|%-10s|%-10s|%-10s|%-20s|\n", condition.MetricKey, condition.Status, condition.Comparator, condition.ErrorThreshold, condition.ActualValue)
+		fmt.Fprintf(&b, "%-40s|%-10s|%-10s|%-10s|%-20s|\n", condition.MetricKey, condition.Status, condition.Comparator, condition.ErrorThreshold, condition.ActualValue)
 	}
-	fmt.Printf("\n")
+	b.WriteString("\n")
+	return b.String()
 }
 
 func GetSonarProjectKeyFromConfig(config string) string {
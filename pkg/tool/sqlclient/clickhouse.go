@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var clickHouseSplitOptions = splitOptions{backslashEscape: true, backtick: true}
+
+// clickHouseClient executes statements by the ClickHouse HTTP interface, ClickHouse has no transaction
+// for general statements, so the statements can only be executed one by one.
+type clickHouseClient struct {
+	cfg    *Config
+	client *http.Client
+}
+
+func newClickHouseClient(cfg *Config) *clickHouseClient {
+	return &clickHouseClient{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (c *clickHouseClient) endpoint(path, sessionID string) string {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(c.cfg.Host, c.cfg.Port),
+		Path:   path,
+	}
+	query := url.Values{}
+	if c.cfg.Database != "" {
+		query.Set("database", c.cfg.Database)
+	}
+	if sessionID != "" {
+		query.Set("session_id", sessionID)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (c *clickHouseClient) do(ctx context.Context, method, path, sessionID string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(path, sessionID), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-ClickHouse-User", c.cfg.Username)
+	req.Header.Set("X-ClickHouse-Key", c.cfg.Password)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status code: %d, message: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (c *clickHouseClient) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/ping", "", nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *clickHouseClient) SupportTransaction() bool {
+	return false
+}
+
+func (c *clickHouseClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+func (c *clickHouseClient) Exec(ctx context.Context, statements []string, transaction bool) ([]*StatementResult, error) {
+	results := newStatementResults(statements)
+	if transaction {
+		return results, errors.New("transaction is not supported by clickhouse")
+	}
+	// run all statements in one session, settings changed by SET or USE are kept by the session
+	sessionID := fmt.Sprintf("zadig-sql-%d", time.Now().UnixNano())
+	for i, result := range results {
+		start := time.Now()
+		rows, err := c.exec(ctx, sessionID, result.SQL)
+		result.Duration = sinceMilliseconds(start)
+		if err != nil {
+			result.Status = StatementFailed
+			result.Error = err.Error()
+			return results, errors.Errorf("exec statement %d error: %v", i+1, err)
+		}
+		result.Status = StatementPassed
+		result.RowsAffected = rows
+	}
+	return results, nil
+}
+
+// exec executes the statement and returns the written rows in the query summary
+func (c *clickHouseClient) exec(ctx context.Context, sessionID, statement string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/", sessionID, strings.NewReader(statement))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	summary := struct {
+		WrittenRows string `json:"written_rows"`
+	}{}
+	if err := json.Unmarshal([]byte(resp.Header.Get("X-ClickHouse-Summary")), &summary); err != nil {
+		return 0, nil
+	}
+	rows, _ := strconv.ParseInt(summary.WrittenRows, 10, 64)
+	return rows, nil
+}
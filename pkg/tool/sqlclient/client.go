@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeMySQL      = "mysql"
+	TypeMariaDB    = "mariadb"
+	TypePostgreSQL = "postgresql"
+	TypeSQLServer  = "sqlserver"
+	TypeClickHouse = "clickhouse"
+)
+
+type StatementStatus string
+
+const (
+	StatementPassed      StatementStatus = "passed"
+	StatementFailed      StatementStatus = "failed"
+	StatementRolledBack  StatementStatus = "rolled_back"
+	StatementNotExecuted StatementStatus = "not_executed"
+)
+
+type Config struct {
+	Type     string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// Database is the default database to connect, it is required by some databases like PostgreSQL
+	Database string
+}
+
+type StatementResult struct {
+	SQL          string
+	Status       StatementStatus
+	RowsAffected int64
+	Error        string
+	// Duration of the statement in milliseconds
+	Duration int64
+}
+
+// Client executes SQL statements on a database instance
+type Client interface {
+	Ping(ctx context.Context) error
+	// Exec executes the statements one by one and stops at the first failed one. If transaction is true,
+	// all statements are executed in a transaction which is rolled back if any of them failed.
+	// The results contain all statements, including the ones not executed.
+	Exec(ctx context.Context, statements []string, transaction bool) ([]*StatementResult, error)
+	SupportTransaction() bool
+	Close() error
+}
+
+func NewClient(cfg *Config) (Client, error) {
+	switch cfg.Type {
+	case TypeMySQL, TypeMariaDB:
+		return newDatabaseClient(mysqlDialect, cfg)
+	case TypePostgreSQL:
+		return newDatabaseClient(postgresDialect, cfg)
+	case TypeSQLServer:
+		return newDatabaseClient(sqlServerDialect, cfg)
+	case TypeClickHouse:
+		return newClickHouseClient(cfg), nil
+	default:
+		return nil, fmt.Errorf("not supported db type: %s", cfg.Type)
+	}
+}
+
+// SplitScript splits the script into statements in the dialect of the database
+func SplitScript(dbType, script string) ([]string, error) {
+	switch dbType {
+	case TypeMySQL, TypeMariaDB:
+		return splitStatements(script, mysqlDialect.split), nil
+	case TypePostgreSQL:
+		return splitStatements(script, postgresDialect.split), nil
+	case TypeSQLServer:
+		return splitStatements(script, sqlServerDialect.split), nil
+	case TypeClickHouse:
+		return splitStatements(script, clickHouseSplitOptions), nil
+	default:
+		return nil, fmt.Errorf("not supported db type: %s", dbType)
+	}
+}
+
+// ExecScript splits the script and executes it by the client
+func ExecScript(ctx context.Context, cfg *Config, script string, transaction bool) ([]*StatementResult, error) {
+	statements, err := SplitScript(cfg.Type, script)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	if transaction && !client.SupportTransaction() {
+		return nil, fmt.Errorf("transaction is not supported by %s", cfg.Type)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no sql statement found")
+	}
+	return client.Exec(ctx, statements, transaction)
+}
+
+func newStatementResults(statements []string) []*StatementResult {
+	resp := make([]*StatementResult, 0, len(statements))
+	for _, statement := range statements {
+		resp = append(resp, &StatementResult{
+			SQL:    statement,
+			Status: StatementNotExecuted,
+		})
+	}
+	return resp
+}
+
+func sinceMilliseconds(start time.Time) int64 {
+	return time.Since(start).Milliseconds()
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// dialect describes how to connect to a database by database/sql and how to split its scripts
+type dialect struct {
+	driverName string
+	dsn        func(cfg *Config) string
+	split      splitOptions
+}
+
+var mysqlDialect = &dialect{
+	driverName: "mysql",
+	dsn: func(cfg *Config) string {
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8", cfg.Username, url.QueryEscape(cfg.Password), net.JoinHostPort(cfg.Host, cfg.Port), cfg.Database)
+	},
+	split: splitOptions{hashComment: true, backslashEscape: true, backtick: true},
+}
+
+var postgresDialect = &dialect{
+	driverName: "postgres",
+	dsn: func(cfg *Config) string {
+		database := cfg.Database
+		if database == "" {
+			database = "postgres"
+		}
+		u := &url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.Username, cfg.Password),
+			Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+			Path:     database,
+			RawQuery: "sslmode=disable",
+		}
+		return u.String()
+	},
+	split: splitOptions{dollarQuote: true},
+}
+
+var sqlServerDialect = &dialect{
+	driverName: "sqlserver",
+	dsn: func(cfg *Config) string {
+		u := &url.URL{
+			Scheme: "sqlserver",
+			User:   url.UserPassword(cfg.Username, cfg.Password),
+			Host:   net.JoinHostPort(cfg.Host, cfg.Port),
+		}
+		if cfg.Database != "" {
+			u.RawQuery = url.Values{"database": []string{cfg.Database}}.Encode()
+		}
+		return u.String()
+	},
+	split: splitOptions{bracket: true, batchSeparator: "GO"},
+}
+
+type databaseClient struct {
+	dialect *dialect
+	db      *sql.DB
+}
+
+func newDatabaseClient(d *dialect, cfg *Config) (*databaseClient, error) {
+	db, err := sql.Open(d.driverName, d.dsn(cfg))
+	if err != nil {
+		return nil, errors.Errorf("connect db error: %v", err)
+	}
+	return &databaseClient{dialect: d, db: db}, nil
+}
+
+func (c *databaseClient) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+func (c *databaseClient) SupportTransaction() bool {
+	return true
+}
+
+func (c *databaseClient) Close() error {
+	return c.db.Close()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (c *databaseClient) Exec(ctx context.Context, statements []string, transaction bool) ([]*StatementResult, error) {
+	results := newStatementResults(statements)
+	// run all statements on one connection, session state like USE or SET is kept by the connection
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return results, errors.Errorf("get db connection error: %v", err)
+	}
+	defer conn.Close()
+	if !transaction {
+		return results, execStatements(ctx, conn, results)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return results, errors.Errorf("begin transaction error: %v", err)
+	}
+	if err := execStatements(ctx, tx, results); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return results, errors.Errorf("%v, rollback error: %v", err, rollbackErr)
+		}
+		for _, result := range results {
+			if result.Status == StatementPassed {
+				result.Status = StatementRolledBack
+			}
+		}
+		return results, errors.Errorf("%v, transaction rolled back", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return results, errors.Errorf("commit transaction error: %v", err)
+	}
+	return results, nil
+}
+
+func execStatements(ctx context.Context, e execer, results []*StatementResult) error {
+	for i, result := range results {
+		start := time.Now()
+		res, err := e.ExecContext(ctx, result.SQL)
+		result.Duration = sinceMilliseconds(start)
+		if err != nil {
+			result.Status = StatementFailed
+			result.Error = err.Error()
+			return errors.Errorf("exec statement %d error: %v", i+1, err)
+		}
+		result.Status = StatementPassed
+		// some drivers do not support affected rows for statements like DDL
+		if rows, err := res.RowsAffected(); err == nil {
+			result.RowsAffected = rows
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordDriver is a database/sql driver which records the connection every statement is executed on.
+type recordDriver struct {
+	mu     sync.Mutex
+	conns  int
+	execOn map[string]int
+}
+
+func (d *recordDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns++
+	return &recordConn{driver: d, id: d.conns}, nil
+}
+
+type recordConn struct {
+	driver *recordDriver
+	id     int
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *recordConn) Close() error { return nil }
+
+func (c *recordConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *recordConn) Commit() error { return nil }
+
+func (c *recordConn) Rollback() error { return nil }
+
+func (c *recordConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.execOn[query] = c.id
+	if query == "FAIL" {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+var testDriver = &recordDriver{execOn: map[string]int{}}
+
+func init() {
+	sql.Register("sqlclient-record", testDriver)
+}
+
+func TestDatabaseClientExecOnOneConnection(t *testing.T) {
+	for _, transaction := range []bool{false, true} {
+		db, err := sql.Open("sqlclient-record", "")
+		assert.NoError(t, err)
+		// the pool would open a new connection for every statement without pinning one
+		db.SetMaxIdleConns(0)
+		client := &databaseClient{dialect: mysqlDialect, db: db}
+
+		statements := []string{"USE demo", "SET NAMES utf8mb4", "INSERT INTO t VALUES (1)"}
+		results, err := client.Exec(context.Background(), statements, transaction)
+		assert.NoError(t, err)
+		for _, result := range results {
+			assert.Equal(t, StatementPassed, result.Status)
+			assert.Equal(t, int64(1), result.RowsAffected)
+		}
+		testDriver.mu.Lock()
+		conn := testDriver.execOn[statements[0]]
+		for _, statement := range statements {
+			assert.Equal(t, conn, testDriver.execOn[statement], statement)
+		}
+		testDriver.mu.Unlock()
+		assert.NoError(t, client.Close())
+	}
+}
+
+func TestDatabaseClientExecFailed(t *testing.T) {
+	db, err := sql.Open("sqlclient-record", "")
+	assert.NoError(t, err)
+	client := &databaseClient{dialect: mysqlDialect, db: db}
+	defer client.Close()
+
+	results, err := client.Exec(context.Background(), []string{"CREATE TABLE t1 (id int)", "FAIL", "DROP TABLE t1"}, true)
+	assert.Error(t, err)
+	assert.Equal(t, StatementRolledBack, results[0].Status)
+	assert.Equal(t, StatementFailed, results[1].Status)
+	assert.Equal(t, StatementNotExecuted, results[2].Status)
+}
+
+func TestClickHouseClientExecInOneSession(t *testing.T) {
+	sessions := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessions = append(sessions, r.URL.Query().Get("session_id"))
+		w.Header().Set("X-ClickHouse-Summary", `{"written_rows":"2"}`)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	client := newClickHouseClient(&Config{Host: host, Port: port, Database: "demo"})
+
+	results, err := client.Exec(context.Background(), []string{"SET max_threads = 1", "INSERT INTO t SELECT 1"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), results[1].RowsAffected)
+	assert.Len(t, sessions, 2)
+	assert.NotEmpty(t, sessions[0])
+	assert.Equal(t, sessions[0], sessions[1])
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"strings"
+	"unicode"
+)
+
+type splitOptions struct {
+	// hashComment means # starts a line comment, like MySQL
+	hashComment bool
+	// backslashEscape means backslash escapes quotes in strings, like MySQL
+	backslashEscape bool
+	// backtick means `name` is a quoted identifier, like MySQL and ClickHouse
+	backtick bool
+	// bracket means [name] is a quoted identifier, like SQL Server
+	bracket bool
+	// dollarQuote means $tag$...$tag$ is a quoted string, like PostgreSQL function bodies
+	dollarQuote bool
+	// batchSeparator splits the script by lines which only contain it instead of semicolons,
+	// like GO in SQL Server scripts.
+	batchSeparator string
+}
+
+// splitStatements splits the script into statements, the separators in quotes and comments are ignored.
+// Statements which only contain comments are dropped.
+func splitStatements(script string, opt splitOptions) []string {
+	resp := make([]string, 0)
+	runes := []rune(script)
+	start := 0
+	hasCode := false
+
+	appendStatement := func(end int) {
+		statement := strings.TrimSpace(string(runes[start:end]))
+		if hasCode && statement != "" {
+			resp = append(resp, statement)
+		}
+		hasCode = false
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(runes, i, c, opt.backslashEscape)
+			hasCode = true
+		case c == '`' && opt.backtick:
+			i = skipQuoted(runes, i, '`', false)
+			hasCode = true
+		case c == '[' && opt.bracket:
+			i = skipUntil(runes, i+1, "]")
+			hasCode = true
+		case c == '-' && peek(runes, i+1) == '-', c == '#' && opt.hashComment:
+			// keep the line break, it may be followed by a batch separator
+			i = skipUntil(runes, i, "\n") - 1
+		case c == '/' && peek(runes, i+1) == '*':
+			i = skipUntil(runes, i+2, "*/")
+		case c == '$' && opt.dollarQuote && dollarTag(runes, i) != "":
+			tag := dollarTag(runes, i)
+			i = skipUntil(runes, i+len([]rune(tag)), tag)
+			hasCode = true
+		case c == ';' && opt.batchSeparator == "":
+			appendStatement(i)
+			start = i + 1
+		case c == '\n' && opt.batchSeparator != "":
+			lineEnd := i + 1
+			for lineEnd < len(runes) && runes[lineEnd] != '\n' {
+				lineEnd++
+			}
+			if strings.EqualFold(strings.TrimSpace(string(runes[i+1:lineEnd])), opt.batchSeparator) {
+				appendStatement(i)
+				start = lineEnd
+				i = lineEnd - 1
+			}
+		case !unicode.IsSpace(c):
+			hasCode = true
+		}
+	}
+	appendStatement(len(runes))
+
+	// the separator may be the first line of the script
+	if opt.batchSeparator != "" && len(resp) > 0 {
+		if firstLine, rest, found := strings.Cut(resp[0], "\n"); found && strings.EqualFold(strings.TrimSpace(firstLine), opt.batchSeparator) {
+			resp[0] = strings.TrimSpace(rest)
+		} else if strings.EqualFold(resp[0], opt.batchSeparator) {
+			resp = resp[1:]
+		}
+	}
+	return resp
+}
+
+func peek(runes []rune, i int) rune {
+	if i < len(runes) {
+		return runes[i]
+	}
+	return 0
+}
+
+// skipQuoted returns the index of the closing quote, doubled quotes and backslash escapes are skipped.
+func skipQuoted(runes []rune, i int, quote rune, backslashEscape bool) int {
+	for i++; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if backslashEscape {
+				i++
+			}
+		case quote:
+			if peek(runes, i+1) != quote {
+				return i
+			}
+			i++
+		}
+	}
+	return len(runes)
+}
+
+// skipUntil returns the index of the last rune of the first end found from i.
+func skipUntil(runes []rune, i int, end string) int {
+	if i > len(runes) {
+		return len(runes)
+	}
+	idx := strings.Index(string(runes[i:]), end)
+	if idx < 0 {
+		return len(runes)
+	}
+	return i + len([]rune(string(runes[i:])[:idx])) + len([]rune(end)) - 1
+}
+
+// dollarTag returns the dollar quote tag starts at i, like $$ or $body$.
+func dollarTag(runes []rune, i int) string {
+	// $1 is a positional parameter instead of a tag
+	if i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1]) || runes[i-1] == '_') {
+		return ""
+	}
+	for j := i + 1; j < len(runes); j++ {
+		switch {
+		case runes[j] == '$':
+			return string(runes[i : j+1])
+		case unicode.IsLetter(runes[j]) || runes[j] == '_' || (j > i+1 && unicode.IsDigit(runes[j])):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		opt    splitOptions
+		want   []string
+	}{
+		{
+			name:   "semicolons",
+			script: "create table t (id int);\ninsert into t values (1);  \n\n",
+			opt:    mysqlDialect.split,
+			want:   []string{"create table t (id int)", "insert into t values (1)"},
+		},
+		{
+			name:   "separators in quotes and comments",
+			script: "insert into t values ('a;b', \"c;d\", 'it''s;', 'e\\';f');\n-- comment;\n# comment;\n/* comment; */ select `a;b` from t;",
+			opt:    mysqlDialect.split,
+			want:   []string{"insert into t values ('a;b', \"c;d\", 'it''s;', 'e\\';f')", "-- comment;\n# comment;\n/* comment; */ select `a;b` from t"},
+		},
+		{
+			name:   "comment only statements are dropped",
+			script: "select 1; -- the end",
+			opt:    mysqlDialect.split,
+			want:   []string{"select 1"},
+		},
+		{
+			name:   "postgresql dollar quotes",
+			script: "create function f() returns int as $body$ begin return 1; end; $body$ language plpgsql;\nselect $$a;b$$, $1, 'c:\\';",
+			opt:    postgresDialect.split,
+			want:   []string{"create function f() returns int as $body$ begin return 1; end; $body$ language plpgsql", "select $$a;b$$, $1, 'c:\\'"},
+		},
+		{
+			name:   "sql server batches",
+			script: "GO\ncreate procedure p as\nbegin\n  select 1;\n  select 2;\nend\ngo\n-- comment\nselect [a;b] from t;\nGO",
+			opt:    sqlServerDialect.split,
+			want:   []string{"create procedure p as\nbegin\n  select 1;\n  select 2;\nend", "-- comment\nselect [a;b] from t;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.script, tt.opt))
+		})
+	}
+}
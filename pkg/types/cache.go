@@ -16,6 +16,13 @@ limitations under the License.
 
 package types
 
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
 type MediumType string
 
 const (
@@ -65,3 +72,85 @@ type StorageClassType string
 const (
 	StorageClassAll StorageClassType = "all"
 )
+
+type BuildKitCacheType string
+
+const (
+	BuildKitRegistryCache BuildKitCacheType = "registry"
+	BuildKitLocalCache    BuildKitCacheType = "local"
+)
+
+// BuildKitCache is the cache imported and exported by a BuildKit image build.
+// Ref is the image reference of a registry cache, Path is the directory of a local cache.
+type BuildKitCache struct {
+	Type BuildKitCacheType `json:"type" bson:"type" yaml:"type"`
+	Ref  string            `json:"ref"  bson:"ref"  yaml:"ref"`
+	Path string            `json:"path" bson:"path" yaml:"path"`
+}
+
+func (c *BuildKitCache) Validate() error {
+	switch c.Type {
+	case BuildKitRegistryCache:
+		if c.Ref == "" {
+			return fmt.Errorf("registry cache ref is required")
+		}
+	case BuildKitLocalCache:
+		if c.Path == "" {
+			return fmt.Errorf("local cache path is required")
+		}
+	default:
+		return fmt.Errorf("unsupported buildkit cache type: %s", c.Type)
+	}
+	return nil
+}
+
+// CacheFrom returns the value of the --cache-from flag of docker buildx build.
+func (c *BuildKitCache) CacheFrom() string {
+	if c.Type == BuildKitLocalCache {
+		return fmt.Sprintf("type=local,src=%s", c.Path)
+	}
+	return fmt.Sprintf("type=registry,ref=%s", c.Ref)
+}
+
+// CacheTo returns the value of the --cache-to flag of docker buildx build.
+func (c *BuildKitCache) CacheTo() string {
+	if c.Type == BuildKitLocalCache {
+		return fmt.Sprintf("type=local,dest=%s,mode=max", c.Path)
+	}
+	return fmt.Sprintf("type=registry,ref=%s,mode=max", c.Ref)
+}
+
+// invalidTagChars are the characters not allowed in an image tag
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// ForServiceModule returns the cache of the image build of a service module. Every service module has its own cache
+// so that the builds running in parallel do not overwrite the cache of each other: the tag of a registry cache ref is
+// suffixed with <service>-<module>, or is <service>-<module> if the ref has no tag, and a local cache is in the
+// <service>/<module> sub directory of the path.
+func (c *BuildKitCache) ForServiceModule(serviceName, serviceModule string) *BuildKitCache {
+	if c == nil {
+		return nil
+	}
+	resp := *c
+	if c.Type == BuildKitLocalCache {
+		resp.Path = path.Join(c.Path, serviceName, serviceModule)
+		return &resp
+	}
+
+	suffix := invalidTagChars.ReplaceAllString(serviceName+"-"+serviceModule, "-")
+	repo, tag := c.Ref, ""
+	if i := strings.LastIndex(c.Ref, ":"); i > strings.LastIndex(c.Ref, "/") {
+		repo, tag = c.Ref[:i], c.Ref[i+1:]
+	}
+	if tag == "" {
+		tag = suffix
+	} else {
+		tag = tag + "-" + suffix
+	}
+	// an image tag has at most 128 characters
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	resp.Ref = repo + ":" + tag
+	return &resp
+}
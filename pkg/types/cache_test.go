@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildKitCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		cache     *BuildKitCache
+		valid     bool
+		cacheFrom string
+		cacheTo   string
+	}{
+		{
+			name:      "registry cache",
+			cache:     &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache"},
+			valid:     true,
+			cacheFrom: "type=registry,ref=koderover.io/cache/zadig:buildcache",
+			cacheTo:   "type=registry,ref=koderover.io/cache/zadig:buildcache,mode=max",
+		},
+		{
+			name:      "local cache",
+			cache:     &BuildKitCache{Type: BuildKitLocalCache, Path: "/workspace/.buildcache"},
+			valid:     true,
+			cacheFrom: "type=local,src=/workspace/.buildcache",
+			cacheTo:   "type=local,dest=/workspace/.buildcache,mode=max",
+		},
+		{
+			name:  "registry cache without ref",
+			cache: &BuildKitCache{Type: BuildKitRegistryCache, Path: "/workspace/.buildcache"},
+		},
+		{
+			name:  "local cache without path",
+			cache: &BuildKitCache{Type: BuildKitLocalCache, Ref: "koderover.io/cache/zadig:buildcache"},
+		},
+		{
+			name:  "unsupported type",
+			cache: &BuildKitCache{Type: "s3", Ref: "koderover.io/cache/zadig:buildcache"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cache.Validate()
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.cacheFrom, tt.cache.CacheFrom())
+			assert.Equal(t, tt.cacheTo, tt.cache.CacheTo())
+		})
+	}
+}
+
+func TestBuildKitCacheForServiceModule(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache *BuildKitCache
+		want  *BuildKitCache
+	}{
+		{
+			name: "no cache",
+		},
+		{
+			name:  "registry cache with a tag",
+			cache: &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache"},
+			want:  &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache-aslan-aslan"},
+		},
+		{
+			name:  "registry cache without a tag on a registry with a port",
+			cache: &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io:5000/cache/zadig"},
+			want:  &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io:5000/cache/zadig:aslan-aslan"},
+		},
+		{
+			name:  "local cache",
+			cache: &BuildKitCache{Type: BuildKitLocalCache, Path: "/workspace/.buildcache"},
+			want:  &BuildKitCache{Type: BuildKitLocalCache, Path: "/workspace/.buildcache/aslan/aslan"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cache.ForServiceModule("aslan", "aslan"))
+		})
+	}
+
+	t.Run("service modules have their own caches", func(t *testing.T) {
+		cache := &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache"}
+		aslan := cache.ForServiceModule("zadig", "aslan")
+		cron := cache.ForServiceModule("zadig", "cron")
+		assert.NotEqual(t, aslan.Ref, cron.Ref)
+		assert.Equal(t, "koderover.io/cache/zadig:buildcache", cache.Ref)
+	})
+
+	t.Run("invalid tag characters and long names", func(t *testing.T) {
+		cache := &BuildKitCache{Type: BuildKitRegistryCache, Ref: "koderover.io/cache/zadig:buildcache"}
+		assert.Equal(t, "koderover.io/cache/zadig:buildcache-my-svc-mod-1", cache.ForServiceModule("my svc", "mod/1").Ref)
+
+		long := cache.ForServiceModule(strings.Repeat("s", 100), strings.Repeat("m", 100)).Ref
+		assert.Len(t, strings.TrimPrefix(long, "koderover.io/cache/zadig:"), 128)
+	})
+}
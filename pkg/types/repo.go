@@ -38,6 +38,7 @@ type Repository struct {
 	EnableCommit  bool   `bson:"enable_commit"          json:"enable_commit"         yaml:"enable_commit"`
 	CommitID      string `bson:"commit_id,omitempty"       json:"commit_id,omitempty"      yaml:"commit_id,omitempty"`
 	CommitMessage string `bson:"commit_message,omitempty"  json:"commit_message,omitempty" yaml:"commit_message,omitempty"`
+	CommitTime    int64  `bson:"commit_time,omitempty"     json:"commit_time,omitempty"    yaml:"commit_time,omitempty"`
 	CheckoutPath  string `bson:"checkout_path,omitempty"   json:"checkout_path,omitempty"  yaml:"checkout_path,omitempty"`
 	SubModules    bool   `bson:"submodules,omitempty"      json:"submodules,omitempty"     yaml:"submodules,omitempty"`
 	// Hidden defines whether the frontend needs to hide this repo
@@ -162,6 +163,12 @@ const (
 	// ProviderGiteeEE
 	ProviderGiteeEE = "gitee-enterprise"
 
+	// ProviderGitea
+	ProviderGitea = "gitea"
+
+	// ProviderBitbucketServer
+	ProviderBitbucketServer = "bitbucket-server"
+
 	// ProviderOther
 	ProviderOther = "other"
 )
@@ -171,11 +178,14 @@ const (
 //
 // e.g. github returns refs/pull/1/head
 // e.g. gitlab returns merge-requests/1/head
+// e.g. bitbucket server returns refs/pull-requests/1/from
 func (r *Repository) PRRef() string {
 	if strings.ToLower(r.Source) == ProviderGitlab {
 		return fmt.Sprintf("merge-requests/%d/head", r.PR)
 	} else if strings.ToLower(r.Source) == ProviderGerrit {
 		return r.CheckoutRef
+	} else if strings.ToLower(r.Source) == ProviderBitbucketServer {
+		return fmt.Sprintf("refs/pull-requests/%d/from", r.PR)
 	}
 	return fmt.Sprintf("refs/pull/%d/head", r.PR)
 }
@@ -185,6 +195,8 @@ func (r *Repository) PRRefByPRID(pr int) string {
 		return fmt.Sprintf("merge-requests/%d/head", pr)
 	} else if strings.ToLower(r.Source) == ProviderGerrit {
 		return r.CheckoutRef
+	} else if strings.ToLower(r.Source) == ProviderBitbucketServer {
+		return fmt.Sprintf("refs/pull-requests/%d/from", pr)
 	}
 	return fmt.Sprintf("refs/pull/%d/head", pr)
 }
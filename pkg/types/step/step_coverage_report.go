@@ -0,0 +1,40 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+type StepCoverageReportSpec struct {
+	// ReportPath is a coverage report or a directory of coverage reports relative to the workspace
+	ReportPath string `bson:"report_path"                json:"report_path"                       yaml:"report_path"`
+	// Format is one of go, cobertura, jacoco and lcov, it is detected from the reports if empty
+	Format    string `bson:"format"                     json:"format"                            yaml:"format"`
+	DestDir   string `bson:"dest_dir"                   json:"dest_dir"                          yaml:"dest_dir"`
+	S3DestDir string `bson:"s3_dest_dir"                json:"s3_dest_dir"                       yaml:"s3_dest_dir"`
+	FileName  string `bson:"file_name"                  json:"file_name"                         yaml:"file_name"`
+	S3Storage *S3    `bson:"s3_storage"                 json:"s3_storage"                        yaml:"s3_storage"`
+	// minimum coverages in percent which fail the step if not reached, zero means no gate
+	MinLineCoverage   float64 `bson:"min_line_coverage"          json:"min_line_coverage"                 yaml:"min_line_coverage"`
+	MinBranchCoverage float64 `bson:"min_branch_coverage"        json:"min_branch_coverage"               yaml:"min_branch_coverage"`
+	MinDiffCoverage   float64 `bson:"min_diff_coverage"          json:"min_diff_coverage"                 yaml:"min_diff_coverage"`
+	// ChangedFiles are the files changed by the pull request which triggered the task, used for diff coverage
+	ChangedFiles  []string `bson:"changed_files"              json:"changed_files"                     yaml:"changed_files"`
+	ProjectName   string   `bson:"project_name"               json:"project_name"                      yaml:"project_name"`
+	TestName      string   `bson:"test_name"                  json:"test_name"                         yaml:"test_name"`
+	ServiceName   string   `bson:"service_name"               json:"service_name"                      yaml:"service_name"`
+	ServiceModule string   `bson:"service_module"             json:"service_module"                    yaml:"service_module"`
+	Branch        string   `bson:"branch"                     json:"branch"                            yaml:"branch"`
+	CommitID      string   `bson:"commit_id"                  json:"commit_id"                         yaml:"commit_id"`
+}
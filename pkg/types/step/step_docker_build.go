@@ -18,8 +18,11 @@ package step
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/koderover/zadig/pkg/setting"
+	"github.com/koderover/zadig/pkg/types"
 )
 
 type StepDockerBuildSpec struct {
@@ -34,6 +37,56 @@ type StepDockerBuildSpec struct {
 	Proxy                 *Proxy          `bson:"proxy"                               json:"proxy"                                  yaml:"proxy"`
 	IgnoreCache           bool            `bson:"ignore_cache"                        json:"ignore_cache"                           yaml:"ignore_cache"`
 	DockerRegistry        *DockerRegistry `bson:"docker_registry"                     json:"docker_registry"                        yaml:"docker_registry"`
+	// Platforms and Cache are only supported by BuildKit, the image is built with docker buildx if either is set
+	Platforms []string             `bson:"platforms"                           json:"platforms"                              yaml:"platforms"`
+	Cache     *types.BuildKitCache `bson:"cache"                               json:"cache"                                  yaml:"cache"`
+	// Builder is the backend building the image, empty means the docker daemon
+	Builder DockerBuilder `bson:"builder"                             json:"builder"                                yaml:"builder"`
+	// EmulatorImage is the digest pinned binfmt image registering the QEMU emulators of the foreign platforms in the
+	// kernel of the docker daemon before a buildx build, the emulators are not installed if it is empty
+	EmulatorImage string `bson:"emulator_image"                      json:"emulator_image"                         yaml:"emulator_image"`
+	// Registries are the TLS settings of all the registries, the BuildKit builders trust them as the dind daemon does
+	Registries []*RegistryTLS `bson:"registries"                          json:"registries"                             yaml:"registries"`
+}
+
+type DockerBuilder string
+
+const (
+	DockerBuilderDocker DockerBuilder = "docker"
+	// DockerBuilderBuildKit builds with the rootless buildkitd container of the job pod, no docker daemon is needed,
+	// the container runs unconfined so the namespace of the job must allow the privileged pod security level
+	DockerBuilderBuildKit DockerBuilder = "buildkit"
+	// DockerBuilderKaniko builds with the kaniko container of the job pod, no docker daemon is needed,
+	// it is the builder for the namespaces enforcing the baseline pod security level
+	DockerBuilderKaniko DockerBuilder = "kaniko"
+)
+
+// The daemonless builders run in their own containers of the job pod, the job container drives them
+// with the files in DaemonlessBuilderDir, which is shared by the containers.
+const (
+	DaemonlessBuilderDir = "/zadig/builder"
+	// DaemonlessDockerConfigDir has the registry credentials used by the daemonless builders
+	DaemonlessDockerConfigDir = DaemonlessBuilderDir + "/.docker"
+	// BuildKitAddr is the address buildkitd listens on in the job pod
+	BuildKitAddr = "tcp://127.0.0.1:1234"
+	// BuildKitdConfigFile is written by the job container, buildkitd starts once it exists
+	BuildKitdConfigFile = DaemonlessBuilderDir + "/buildkitd.toml"
+	// BuildCtlFile is the buildctl client copied from the buildkit image
+	BuildCtlFile = DaemonlessBuilderDir + "/buildctl"
+	// KanikoScriptFile is written by the job container, the kaniko container runs it and writes
+	// the output to KanikoLogFile and then the exit code to KanikoExitCodeFile
+	KanikoScriptFile   = DaemonlessBuilderDir + "/kaniko.sh"
+	KanikoLogFile      = DaemonlessBuilderDir + "/kaniko.log"
+	KanikoExitCodeFile = DaemonlessBuilderDir + "/kaniko.exitcode"
+)
+
+func (b DockerBuilder) Validate() error {
+	switch b {
+	case "", DockerBuilderDocker, DockerBuilderBuildKit, DockerBuilderKaniko:
+		return nil
+	default:
+		return fmt.Errorf("unsupported docker builder: %s", b)
+	}
 }
 
 type DockerRegistry struct {
@@ -44,6 +97,45 @@ type DockerRegistry struct {
 	Password         string `bson:"password"                          json:"password"                             yaml:"password"`
 }
 
+type RegistryTLS struct {
+	RegAddr    string `bson:"reg_addr"                          json:"reg_addr"                             yaml:"reg_addr"`
+	TLSEnabled bool   `bson:"tls_enabled"                       json:"tls_enabled"                          yaml:"tls_enabled"`
+	TLSCert    string `bson:"tls_cert"                          json:"tls_cert"                             yaml:"tls_cert"`
+}
+
+// Host returns the registry address without the scheme
+func (r *RegistryTLS) Host() string {
+	return strings.TrimPrefix(strings.TrimPrefix(r.RegAddr, "http://"), "https://")
+}
+
+// CertPath returns the path of the CA certificate of the registry in certDir, which has the layout of /etc/docker/certs.d
+func (r *RegistryTLS) CertPath(certDir string) string {
+	return filepath.Join(certDir, r.Host(), "ca.crt")
+}
+
+// BuildKitdConfig returns the content of buildkitd.toml, a registry without TLS is insecure and
+// a registry with a TLS certificate uses the certificate in certDir as its CA.
+func BuildKitdConfig(registries []*RegistryTLS, certDir string) string {
+	var config strings.Builder
+	for _, reg := range registries {
+		lines := make([]string, 0)
+		if strings.HasPrefix(reg.RegAddr, "http://") {
+			lines = append(lines, "  http = true")
+		}
+		if !reg.TLSEnabled {
+			lines = append(lines, "  insecure = true")
+		} else if reg.TLSCert != "" {
+			lines = append(lines, fmt.Sprintf("  ca = [%q]", reg.CertPath(certDir)))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		config.WriteString(fmt.Sprintf("[registry.%q]\n", reg.Host()))
+		config.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+	return config.String()
+}
+
 func (s *StepDockerBuildSpec) GetDockerFile() string {
 	// if the source of the dockerfile is from template, we write our own dockerfile
 	if s.Source == setting.DockerfileSourceTemplate {
@@ -54,3 +146,64 @@ func (s *StepDockerBuildSpec) GetDockerFile() string {
 	}
 	return s.DockerFile
 }
+
+func (s *StepDockerBuildSpec) GetBuilder() DockerBuilder {
+	if s.Builder == "" {
+		return DockerBuilderDocker
+	}
+	return s.Builder
+}
+
+func (s *StepDockerBuildSpec) UseBuildx() bool {
+	return s.GetBuilder() == DockerBuilderDocker && (len(s.Platforms) > 0 || s.Cache != nil)
+}
+
+// ValidateEmulatorImage returns an error if the binfmt image is not pinned by digest, the image runs privileged on
+// the docker daemon and changes the binfmt_misc of its node
+func ValidateEmulatorImage(image string) error {
+	if image == "" {
+		return fmt.Errorf("no binfmt image is configured to install the emulators")
+	}
+	if !strings.Contains(image, "@sha256:") {
+		return fmt.Errorf("binfmt image %s is not pinned by digest", image)
+	}
+	return nil
+}
+
+// NormalizeArch converts the architecture reported by the docker daemon, which is the machine hardware name
+// of its kernel, to the arch of an image platform
+func NormalizeArch(arch string) string {
+	switch arch = strings.TrimSpace(arch); arch {
+	case "x86_64", "x86-64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	case "i386", "i686":
+		return "386"
+	case "armv7l", "armv6l":
+		return "arm"
+	default:
+		return arch
+	}
+}
+
+// EmulatedArchs returns the archs of the platforms which are different from the native arch of the docker daemon
+func (s *StepDockerBuildSpec) EmulatedArchs(nativeArch string) []string {
+	archs := make([]string, 0)
+	for _, platform := range s.Platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) < 2 || parts[1] == nativeArch {
+			continue
+		}
+		found := false
+		for _, arch := range archs {
+			if arch == parts[1] {
+				found = true
+			}
+		}
+		if !found {
+			archs = append(archs, parts[1])
+		}
+	}
+	return archs
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildKitdConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		registries []*RegistryTLS
+		want       string
+	}{
+		{
+			name:       "no registries",
+			registries: []*RegistryTLS{},
+			want:       "",
+		},
+		{
+			name: "secure registry without certificate",
+			registries: []*RegistryTLS{
+				{RegAddr: "https://registry.koderover.io", TLSEnabled: true},
+			},
+			want: "",
+		},
+		{
+			name: "insecure, http and self signed registries",
+			registries: []*RegistryTLS{
+				{RegAddr: "https://registry.koderover.io", TLSEnabled: true},
+				{RegAddr: "https://insecure.koderover.io:5000"},
+				{RegAddr: "http://http.koderover.io"},
+				{RegAddr: "https://harbor.koderover.io", TLSEnabled: true, TLSCert: "cert"},
+			},
+			want: `[registry."insecure.koderover.io:5000"]
+  insecure = true
+[registry."http.koderover.io"]
+  http = true
+  insecure = true
+[registry."harbor.koderover.io"]
+  ca = ["/tmp/certs.d/harbor.koderover.io/ca.crt"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, BuildKitdConfig(tt.registries, "/tmp/certs.d"))
+		})
+	}
+}
+
+func TestEmulatedArchs(t *testing.T) {
+	tests := []struct {
+		name       string
+		platforms  []string
+		nativeArch string
+		want       []string
+	}{
+		{
+			name:       "native platform only",
+			platforms:  []string{"linux/amd64"},
+			nativeArch: NormalizeArch("x86_64\n"),
+			want:       []string{},
+		},
+		{
+			name:       "foreign platforms of an arm64 daemon",
+			platforms:  []string{"linux/amd64", "linux/arm64", "linux/arm/v7", "linux/arm/v6"},
+			nativeArch: NormalizeArch("aarch64"),
+			want:       []string{"amd64", "arm"},
+		},
+		{
+			name:       "invalid platform",
+			platforms:  []string{"linux"},
+			nativeArch: "amd64",
+			want:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &StepDockerBuildSpec{Platforms: tt.platforms}
+			assert.Equal(t, tt.want, spec.EmulatedArchs(tt.nativeArch))
+		})
+	}
+}
+
+func TestValidateEmulatorImage(t *testing.T) {
+	assert.Error(t, ValidateEmulatorImage(""))
+	assert.Error(t, ValidateEmulatorImage("tonistiigi/binfmt:latest"))
+	assert.NoError(t, ValidateEmulatorImage("tonistiigi/binfmt@sha256:"+strings.Repeat("0", 64)))
+}
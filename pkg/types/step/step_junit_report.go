@@ -23,4 +23,8 @@ type StepJunitReportSpec struct {
 	FileName  string `bson:"file_name"                  json:"file_name"                         yaml:"file_name"`
 	TestName  string `bson:"test_name"                  json:"test_name"                         yaml:"test_name"`
 	S3Storage *S3    `bson:"s3_storage"                 json:"s3_storage"                        yaml:"s3_storage"`
+	CommitID  string `bson:"commit_id"                  json:"commit_id"                         yaml:"commit_id"`
+	Branch    string `bson:"branch"                     json:"branch"                            yaml:"branch"`
+	// Quarantined failed cases are reported but do not fail the step
+	Quarantined []string `bson:"quarantined"            json:"quarantined"                       yaml:"quarantined"`
 }
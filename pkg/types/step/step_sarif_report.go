@@ -0,0 +1,37 @@
+/*
+Copyright 2023 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package step
+
+type StepSarifReportSpec struct {
+	// ReportDir is a sarif file or a directory of sarif files relative to the workspace
+	ReportDir string `bson:"report_dir"                 json:"report_dir"                        yaml:"report_dir"`
+	DestDir   string `bson:"dest_dir"                   json:"dest_dir"                          yaml:"dest_dir"`
+	S3DestDir string `bson:"s3_dest_dir"                json:"s3_dest_dir"                       yaml:"s3_dest_dir"`
+	FileName  string `bson:"file_name"                  json:"file_name"                         yaml:"file_name"`
+	S3Storage *S3    `bson:"s3_storage"                 json:"s3_storage"                        yaml:"s3_storage"`
+	// SeverityThreshold fails the step if there are findings at or above it, empty means no gate
+	SeverityThreshold string `bson:"severity_threshold"         json:"severity_threshold"                yaml:"severity_threshold"`
+	// GateNewOnly only counts the findings which are not in the baseline for the gate
+	GateNewOnly bool `bson:"gate_new_only"              json:"gate_new_only"                     yaml:"gate_new_only"`
+	// BaselinePath is the object key of the report of the last default branch run, it is set before the job runs
+	BaselinePath string `bson:"baseline_path"              json:"baseline_path"                     yaml:"baseline_path"`
+	ProjectName  string `bson:"project_name"               json:"project_name"                      yaml:"project_name"`
+	ScanningName string `bson:"scanning_name"              json:"scanning_name"                     yaml:"scanning_name"`
+	Branch       string `bson:"branch"                     json:"branch"                            yaml:"branch"`
+	// DefaultBranch is true if the scanned code is the default branch, its report becomes the new baseline
+	DefaultBranch bool `bson:"default_branch"             json:"default_branch"                    yaml:"default_branch"`
+}
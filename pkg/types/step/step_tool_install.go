@@ -3,6 +3,8 @@ package step
 type StepToolInstallSpec struct {
 	Installs  []*Tool `bson:"installs"                     json:"installs"                        yaml:"installs"`
 	S3Storage *S3     `bson:"s3_storage"                   json:"s3_storage"                      yaml:"s3_storage"`
+	// Shell runs the installation scripts on the zadig-agent, sh is used if it is empty
+	Shell string `bson:"shell"                        json:"shell"                           yaml:"shell"`
 }
 
 type Tool struct {